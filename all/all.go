@@ -9,33 +9,45 @@
 //
 //	// Now all ecosystems are available
 //	ecosystems := registries.SupportedEcosystems()
-//	// ["brew", "cargo", "clojars", "cocoapods", "composer", "conda", "cpan", "cran", "deno", "dub", "elm", "gem", "golang", "hackage", "haxelib", "hex", "julia", "luarocks", "maven", "nimble", "npm", "nuget", "pub", "pypi", "terraform"]
+//	// ["arduino", "bitbucket", "brew", "buildpack", "cargo", "clojars", "cocoapods", "composer", "conda", "cpan", "cran", "deno", "drupal", "dub", "elm", "gem", "generic", "github", "github-release", "gitlab", "gittags", "golang", "hackage", "haxelib", "hex", "jsr", "julia", "luarocks", "maven", "nimble", "npm", "nuget", "platformio", "pub", "pypi", "racket", "static", "terraform", "vim", "wasm", "wordpress"]
 package all
 
 import (
-	_ "github.com/git-pkgs/registries/internal/cargo"
-	_ "github.com/git-pkgs/registries/internal/clojars"
-	_ "github.com/git-pkgs/registries/internal/cocoapods"
-	_ "github.com/git-pkgs/registries/internal/conda"
-	_ "github.com/git-pkgs/registries/internal/cpan"
-	_ "github.com/git-pkgs/registries/internal/cran"
-	_ "github.com/git-pkgs/registries/internal/deno"
-	_ "github.com/git-pkgs/registries/internal/dub"
-	_ "github.com/git-pkgs/registries/internal/elm"
-	_ "github.com/git-pkgs/registries/internal/golang"
-	_ "github.com/git-pkgs/registries/internal/hackage"
-	_ "github.com/git-pkgs/registries/internal/haxelib"
-	_ "github.com/git-pkgs/registries/internal/hex"
-	_ "github.com/git-pkgs/registries/internal/homebrew"
-	_ "github.com/git-pkgs/registries/internal/julia"
-	_ "github.com/git-pkgs/registries/internal/luarocks"
-	_ "github.com/git-pkgs/registries/internal/maven"
-	_ "github.com/git-pkgs/registries/internal/nimble"
-	_ "github.com/git-pkgs/registries/internal/npm"
-	_ "github.com/git-pkgs/registries/internal/nuget"
-	_ "github.com/git-pkgs/registries/internal/packagist"
-	_ "github.com/git-pkgs/registries/internal/pub"
-	_ "github.com/git-pkgs/registries/internal/pypi"
-	_ "github.com/git-pkgs/registries/internal/rubygems"
-	_ "github.com/git-pkgs/registries/internal/terraform"
+	_ "github.com/git-pkgs/registries/arduino"
+	_ "github.com/git-pkgs/registries/buildpack"
+	_ "github.com/git-pkgs/registries/cargo"
+	_ "github.com/git-pkgs/registries/clojars"
+	_ "github.com/git-pkgs/registries/cocoapods"
+	_ "github.com/git-pkgs/registries/conda"
+	_ "github.com/git-pkgs/registries/cpan"
+	_ "github.com/git-pkgs/registries/cran"
+	_ "github.com/git-pkgs/registries/deno"
+	_ "github.com/git-pkgs/registries/drupal"
+	_ "github.com/git-pkgs/registries/dub"
+	_ "github.com/git-pkgs/registries/elm"
+	_ "github.com/git-pkgs/registries/githubrelease"
+	_ "github.com/git-pkgs/registries/gittags"
+	_ "github.com/git-pkgs/registries/golang"
+	_ "github.com/git-pkgs/registries/hackage"
+	_ "github.com/git-pkgs/registries/haxelib"
+	_ "github.com/git-pkgs/registries/hex"
+	_ "github.com/git-pkgs/registries/homebrew"
+	_ "github.com/git-pkgs/registries/jsr"
+	_ "github.com/git-pkgs/registries/julia"
+	_ "github.com/git-pkgs/registries/luarocks"
+	_ "github.com/git-pkgs/registries/maven"
+	_ "github.com/git-pkgs/registries/nimble"
+	_ "github.com/git-pkgs/registries/npm"
+	_ "github.com/git-pkgs/registries/nuget"
+	_ "github.com/git-pkgs/registries/packagist"
+	_ "github.com/git-pkgs/registries/platformio"
+	_ "github.com/git-pkgs/registries/pub"
+	_ "github.com/git-pkgs/registries/pypi"
+	_ "github.com/git-pkgs/registries/racket"
+	_ "github.com/git-pkgs/registries/rubygems"
+	_ "github.com/git-pkgs/registries/static"
+	_ "github.com/git-pkgs/registries/terraform"
+	_ "github.com/git-pkgs/registries/vim"
+	_ "github.com/git-pkgs/registries/wasm"
+	_ "github.com/git-pkgs/registries/wordpress"
 )
@@ -102,7 +102,7 @@ func BenchmarkFetchVersions_Cargo(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_, _ = reg.FetchVersions(ctx, "serde")
+		_, _ = registries.FetchVersions(ctx, reg, "serde")
 	}
 }
 
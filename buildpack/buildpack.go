@@ -0,0 +1,27 @@
+// Package buildpack constructs clients for the Cloud Native Buildpacks
+// registry.
+//
+//	reg, err := buildpack.New("", nil) // https://registry.buildpacks.io
+//	pkg, err := reg.FetchPackage(ctx, name)
+package buildpack
+
+import (
+	"github.com/git-pkgs/registries/client"
+	impl "github.com/git-pkgs/registries/internal/buildpack"
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+// DefaultURL is the registry New uses when given no base URL.
+const DefaultURL = impl.DefaultURL
+
+// Registry is the client New returns. It implements registries.Registry and
+// the optional interfaces the ecosystem supports.
+type Registry = impl.Registry
+
+// New returns a client for the registry at baseURL, or DefaultURL if
+// baseURL is empty. A nil client uses client.DefaultClient. A baseURL that
+// fails registries.ValidateURL returns an error wrapping
+// registries.ErrInvalidURL.
+func New(baseURL string, c *client.Client) (*Registry, error) {
+	return core.Construct("buildpack", baseURL, c, impl.New)
+}
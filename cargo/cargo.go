@@ -0,0 +1,44 @@
+// Package cargo constructs clients for crates.io and other Cargo registries,
+// including sparse and git indexes.
+//
+//	reg, err := cargo.New("", nil) // https://crates.io
+//	pkg, err := reg.FetchPackage(ctx, name)
+package cargo
+
+import (
+	"github.com/git-pkgs/registries/client"
+	impl "github.com/git-pkgs/registries/internal/cargo"
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+// DefaultURL is the registry New uses when given no base URL.
+const DefaultURL = impl.DefaultURL
+
+// Registry is the client New returns. It implements registries.Registry and
+// the optional interfaces the ecosystem supports.
+type Registry = impl.Registry
+
+// Features is a crate version's feature table, as FetchFeatures returns
+// it. Its Resolve method works out which optional dependencies and
+// dependency features a build with a given set of features compiles.
+type Features = impl.Features
+
+// Feature is one entry of a Features table.
+type Feature = impl.Feature
+
+// Resolution is what Features.Resolve returns.
+type Resolution = impl.Resolution
+
+// ParseFeatures reads a [features] table, such as a version's "features"
+// metadata, given the names of the version's optional dependencies.
+func ParseFeatures(table map[string][]string, optional []string) Features {
+	return impl.ParseFeatures(table, optional)
+}
+
+// New returns a client for the registry at baseURL, or DefaultURL if
+// baseURL is empty. A nil client uses client.DefaultClient. A baseURL that
+// fails registries.ValidateURL returns an error wrapping
+// registries.ErrInvalidURL.
+func New(baseURL string, c *client.Client) (*Registry, error) {
+	return core.Construct("cargo", baseURL, c, impl.New)
+}
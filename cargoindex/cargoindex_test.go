@@ -0,0 +1,276 @@
+package cargoindex
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/git-pkgs/registries/client"
+)
+
+func TestPath(t *testing.T) {
+	tests := map[string]string{
+		"a":         "1/a",
+		"cc":        "2/cc",
+		"syn":       "3/s/syn",
+		"serde":     "se/rd/serde",
+		"Inflector": "in/fl/inflector",
+	}
+	for name, want := range tests {
+		if got := Path(name); got != want {
+			t.Errorf("Path(%q) = %q, want %q", name, got, want)
+		}
+		if got := NameFromPath(want); !strings.EqualFold(got, name) {
+			t.Errorf("NameFromPath(%q) = %q, want %q", want, got, name)
+		}
+	}
+	for _, path := range []string{"config.json", ".github/workflows/ci.yml", "se/xx/serde"} {
+		if got := NameFromPath(path); got != "" {
+			t.Errorf("NameFromPath(%q) = %q, want empty", path, got)
+		}
+	}
+}
+
+func TestParseEntries(t *testing.T) {
+	data := `{"name":"serde","vers":"1.0.0","deps":[],"cksum":"abc","features":{},"yanked":false}
+
+{"name":"serde","vers":"1.0.1","deps":[{"name":"serde_derive","req":"=1.0.1","features":[],"optional":true,"default_features":true,"kind":"normal"}],"cksum":"def","features":{"derive":["serde_derive"]},"yanked":true,"rust_version":"1.31"}
+`
+	entries, err := ParseEntries([]byte(data))
+	if err != nil {
+		t.Fatalf("ParseEntries failed: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+	e := entries[1]
+	if e.Vers != "1.0.1" || !e.Yanked || e.RustVersion != "1.31" || len(e.Deps) != 1 || !e.Deps[0].Optional {
+		t.Errorf("unexpected entry: %+v", e)
+	}
+
+	if _, err := ParseEntries([]byte("{not json")); err == nil {
+		t.Error("expected error for invalid line")
+	}
+}
+
+// gitRepo is a throwaway upstream index repository.
+type gitRepo struct {
+	t   *testing.T
+	dir string
+}
+
+func newGitRepo(t *testing.T) *gitRepo {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	r := &gitRepo{t: t, dir: t.TempDir()}
+	r.git("init", "--quiet", "--initial-branch=master")
+	r.write("config.json", `{"dl":"https://crates.io/api/v1/crates"}`)
+	return r
+}
+
+func (r *gitRepo) git(args ...string) string {
+	r.t.Helper()
+	cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+	cmd.Dir = r.dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		r.t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func (r *gitRepo) write(path, content string) {
+	r.t.Helper()
+	full := filepath.Join(r.dir, path)
+	if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+		r.t.Fatal(err)
+	}
+	if err := os.WriteFile(full, []byte(content+"\n"), 0o644); err != nil {
+		r.t.Fatal(err)
+	}
+}
+
+func (r *gitRepo) commit() string {
+	r.git("add", "-A")
+	r.git("commit", "--quiet", "-m", "update")
+	return r.git("rev-parse", "HEAD")
+}
+
+func names(changes []Change) []string {
+	var out []string
+	for _, c := range changes {
+		name := c.Name
+		if c.Deleted {
+			name = "-" + name
+		}
+		out = append(out, name)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func TestConfigDownloadURL(t *testing.T) {
+	tests := []struct {
+		dl   string
+		want string
+	}{
+		{"https://static.crates.io/crates", "https://static.crates.io/crates/Inflector/0.11.4/download"},
+		{"https://dl.example.com/api/v1/crates/", "https://dl.example.com/api/v1/crates/Inflector/0.11.4/download"},
+		{"https://dl.example.com/{prefix}/{crate}-{version}.crate", "https://dl.example.com/In/fl/Inflector-0.11.4.crate"},
+		{"https://dl.example.com/{lowerprefix}/{crate}/{sha256-checksum}", "https://dl.example.com/in/fl/Inflector/abc123"},
+	}
+	for _, tt := range tests {
+		c := &Config{DL: tt.dl}
+		if got := c.DownloadURL("Inflector", "0.11.4", "abc123"); got != tt.want {
+			t.Errorf("DownloadURL with %q = %q, want %q", tt.dl, got, tt.want)
+		}
+	}
+
+	if _, err := ParseConfig([]byte(`{"api": "https://example.com"}`)); err == nil {
+		t.Error("expected error for config without dl")
+	}
+	c, err := ParseConfig([]byte(`{"dl": "https://example.com/dl", "api": "https://example.com", "auth-required": true}`))
+	if err != nil || !c.AuthRequired || c.API != "https://example.com" {
+		t.Errorf("ParseConfig = %+v, %v", c, err)
+	}
+}
+
+func TestGitIndexSync(t *testing.T) {
+	upstream := newGitRepo(t)
+	upstream.write(Path("serde"), `{"name":"serde","vers":"1.0.0","deps":[],"cksum":"a","features":{},"yanked":false}`)
+	upstream.write(Path("syn"), `{"name":"syn","vers":"2.0.0","deps":[],"cksum":"b","features":{},"yanked":false}`)
+	upstream.write("config.json", `{"dl": "https://static.crates.io/crates", "api": "https://crates.io"}`)
+	upstream.commit()
+
+	ctx := context.Background()
+	idx := NewGitIndex(filepath.Join(t.TempDir(), "index"), upstream.dir)
+
+	changes, head, err := idx.Sync(ctx, "")
+	if err != nil {
+		t.Fatalf("initial Sync failed: %v", err)
+	}
+	if got := strings.Join(names(changes), ","); got != "serde,syn" {
+		t.Errorf("initial changes = %s", got)
+	}
+	if cfg, err := idx.Config(ctx, ""); err != nil || cfg.API != "https://crates.io" {
+		t.Errorf("Config = %+v, %v", cfg, err)
+	}
+
+	upstream.write(Path("serde"), `{"name":"serde","vers":"1.0.0","deps":[],"cksum":"a","features":{},"yanked":false}
+{"name":"serde","vers":"1.0.1","deps":[],"cksum":"c","features":{},"yanked":false}`)
+	upstream.write(Path("a"), `{"name":"a","vers":"0.1.0","deps":[],"cksum":"d","features":{},"yanked":false}`)
+	upstream.git("rm", "--quiet", Path("syn"))
+	upstream.commit()
+
+	changes, next, err := idx.Sync(ctx, head)
+	if err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if got := strings.Join(names(changes), ","); got != "-syn,a,serde" {
+		t.Errorf("changes = %s", got)
+	}
+
+	entries, err := idx.Entries(ctx, next, "serde")
+	if err != nil {
+		t.Fatalf("Entries failed: %v", err)
+	}
+	if len(entries) != 2 || entries[1].Vers != "1.0.1" {
+		t.Errorf("unexpected entries: %+v", entries)
+	}
+
+	// Nothing new
+	changes, again, err := idx.Sync(ctx, next)
+	if err != nil || len(changes) != 0 || again != next {
+		t.Errorf("no-op Sync = %v, %q, %v", changes, again, err)
+	}
+
+	// Squash upstream history; the old head disappears
+	upstream.git("checkout", "--quiet", "--orphan", "squashed")
+	squashed := upstream.commit()
+	upstream.git("branch", "--quiet", "-M", "master")
+
+	changes, head, err = idx.Sync(ctx, next)
+	if !errors.Is(err, ErrHistoryRewritten) {
+		t.Fatalf("expected ErrHistoryRewritten, got %v", err)
+	}
+	if head != squashed || strings.Join(names(changes), ",") != "a,serde" {
+		t.Errorf("after squash: head=%s changes=%v", head, names(changes))
+	}
+}
+
+func TestGitIndexRejectsOptions(t *testing.T) {
+	upstream := newGitRepo(t)
+	upstream.write(Path("serde"), `{"name":"serde","vers":"1.0.0","deps":[],"cksum":"a","features":{},"yanked":false}`)
+	upstream.commit()
+
+	ctx := context.Background()
+	out := filepath.Join(t.TempDir(), "out")
+	idx := NewGitIndex(filepath.Join(t.TempDir(), "index"), upstream.dir)
+	for _, since := range []string{"--output=" + out, "HEAD~1", "master"} {
+		if _, _, err := idx.Sync(ctx, since); err == nil {
+			t.Errorf("Sync(%q) should fail", since)
+		}
+	}
+	if err := idx.Fetch(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := idx.Entries(ctx, "--output="+out, "serde"); err == nil {
+		t.Error("Entries with an option as rev should fail")
+	}
+	if _, err := os.Stat(out); !os.IsNotExist(err) {
+		t.Errorf("git wrote %s", out)
+	}
+}
+
+func TestSparseChanged(t *testing.T) {
+	body := `{"name":"serde","vers":"1.0.0","deps":[],"cksum":"a","features":{},"yanked":false}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/se/rd/serde":
+			_, _ = w.Write([]byte(body))
+		case "/3/s/syn":
+			_, _ = w.Write([]byte(`{"name":"syn","vers":"2.0.0","deps":[],"cksum":"b","features":{},"yanked":false}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	s := NewSparse(client.DefaultClient(), server.URL)
+
+	entries, err := s.Entries(ctx, "serde")
+	if err != nil || len(entries) != 1 || entries[0].Vers != "1.0.0" {
+		t.Fatalf("Entries = %+v, %v", entries, err)
+	}
+
+	state := map[string]string{"gone": "old"}
+	changes, err := s.Changed(ctx, []string{"serde", "syn", "gone", "never"}, state)
+	if err != nil {
+		t.Fatalf("Changed failed: %v", err)
+	}
+	if got := strings.Join(names(changes), ","); got != "-gone,serde,syn" {
+		t.Errorf("changes = %s", got)
+	}
+	if _, ok := state["gone"]; ok {
+		t.Error("deleted crate left in state")
+	}
+
+	body += "\n" + `{"name":"serde","vers":"1.0.1","deps":[],"cksum":"c","features":{},"yanked":false}`
+	changes, err = s.Changed(ctx, []string{"serde", "syn"}, state)
+	if err != nil {
+		t.Fatalf("Changed failed: %v", err)
+	}
+	if got := strings.Join(names(changes), ","); got != "serde" {
+		t.Errorf("changes = %s", got)
+	}
+}
@@ -0,0 +1,102 @@
+package cargoindex
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Config is an index's config.json, which tells Cargo where to download
+// crates from and where the registry's web API is.
+type Config struct {
+	// DL is the download URL, either a prefix to which
+	// "/{crate}/{version}/download" is appended or a template using the
+	// markers {crate}, {version}, {prefix}, {lowerprefix} and
+	// {sha256-checksum}.
+	DL string `json:"dl"`
+	// API is the base URL of the web API, if the registry has one.
+	API string `json:"api,omitempty"`
+	// AuthRequired means every request, including index and download
+	// requests, needs a token.
+	AuthRequired bool `json:"auth-required,omitempty"`
+}
+
+// ParseConfig parses an index's config.json.
+func ParseConfig(data []byte) (*Config, error) {
+	var c Config
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("cargoindex: config.json: %w", err)
+	}
+	if c.DL == "" {
+		return nil, fmt.Errorf("cargoindex: config.json has no dl")
+	}
+	return &c, nil
+}
+
+var dlMarkers = []string{"{crate}", "{version}", "{prefix}", "{lowerprefix}", "{sha256-checksum}"}
+
+// DownloadURL returns the URL of a crate file. checksum is the version's
+// cksum from the index and is only needed by templates using
+// {sha256-checksum}.
+func (c *Config) DownloadURL(name, version, checksum string) string {
+	if !c.Templated() {
+		return strings.TrimSuffix(c.DL, "/") + "/" + name + "/" + version + "/download"
+	}
+	return strings.NewReplacer(
+		"{crate}", name,
+		"{version}", version,
+		"{prefix}", prefixOf(name),
+		"{lowerprefix}", strings.ToLower(prefixOf(name)),
+		"{sha256-checksum}", checksum,
+	).Replace(c.DL)
+}
+
+// Templated reports whether DL uses any template markers.
+func (c *Config) Templated() bool {
+	for _, m := range dlMarkers {
+		if strings.Contains(c.DL, m) {
+			return true
+		}
+	}
+	return false
+}
+
+// prefixOf returns the index directory of a crate without lowercasing it,
+// as the {prefix} marker wants.
+func prefixOf(name string) string {
+	switch len(name) {
+	case 0:
+		return ""
+	case 1:
+		return "1"
+	case 2:
+		return "2"
+	case 3:
+		return "3/" + name[:1]
+	default:
+		return name[:2] + "/" + name[2:4]
+	}
+}
+
+// Config reads the index's config.json.
+func (s *Sparse) Config(ctx context.Context) (*Config, error) {
+	body, err := s.client.GetBody(ctx, s.baseURL+"/config.json")
+	if err != nil {
+		return nil, err
+	}
+	return ParseConfig(body)
+}
+
+// Config reads config.json at commit rev. An empty rev reads the tracked
+// branch.
+func (g *GitIndex) Config(ctx context.Context, rev string) (*Config, error) {
+	if rev == "" {
+		rev = g.ref()
+	}
+	out, err := g.run(ctx, "show", "--end-of-options", rev+":config.json")
+	if err != nil {
+		return nil, err
+	}
+	return ParseConfig([]byte(out))
+}
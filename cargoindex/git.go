@@ -0,0 +1,182 @@
+package cargoindex
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// CratesIOGitURL is the git index of crates.io.
+const CratesIOGitURL = "https://github.com/rust-lang/crates.io-index"
+
+// ErrHistoryRewritten is returned by Sync when the last-known commit is no
+// longer in the index history. crates.io squashes its index periodically;
+// callers should rescan every crate and continue from the returned head.
+var ErrHistoryRewritten = errors.New("cargoindex: last-known commit is not in index history")
+
+// commitID matches a full SHA-1 or SHA-256 commit ID, as Sync returns.
+var commitID = regexp.MustCompile(`^([0-9a-f]{40}|[0-9a-f]{64})$`)
+
+// Change is a crate whose index file changed between two commits.
+type Change struct {
+	Name    string
+	Path    string
+	Deleted bool
+}
+
+// GitIndex is a local bare clone of a git-based Cargo index. It shells out
+// to the git command, which must be on PATH.
+type GitIndex struct {
+	dir    string
+	url    string
+	branch string
+	git    string
+}
+
+// NewGitIndex returns an index cloned into dir from url, or CratesIOGitURL
+// if url is empty. Nothing is cloned until Sync is called.
+func NewGitIndex(dir, url string) *GitIndex {
+	if url == "" {
+		url = CratesIOGitURL
+	}
+	return &GitIndex{dir: dir, url: url, branch: "master", git: "git"}
+}
+
+// WithBranch returns a copy that tracks branch instead of master.
+func (g *GitIndex) WithBranch(branch string) *GitIndex {
+	copy := *g
+	copy.branch = branch
+	return &copy
+}
+
+// Dir returns the directory of the local clone.
+func (g *GitIndex) Dir() string {
+	return g.dir
+}
+
+// Sync fetches the index and returns the crates changed since the commit
+// since, along with the new head commit to pass as since next time. An empty
+// since lists every crate in the index. If since is no longer reachable,
+// Sync returns every crate along with ErrHistoryRewritten. since must be a
+// full commit ID.
+func (g *GitIndex) Sync(ctx context.Context, since string) ([]Change, string, error) {
+	if since != "" && !commitID.MatchString(since) {
+		return nil, "", fmt.Errorf("cargoindex: %q is not a commit ID", since)
+	}
+	if err := g.update(ctx); err != nil {
+		return nil, "", err
+	}
+	head, err := g.run(ctx, "rev-parse", g.ref())
+	if err != nil {
+		return nil, "", err
+	}
+	head = strings.TrimSpace(head)
+
+	if since == "" {
+		changes, err := g.all(ctx, head)
+		return changes, head, err
+	}
+	// The old commit may linger in the clone after a squash, so check it
+	// is still an ancestor rather than merely present
+	if _, err := g.run(ctx, "merge-base", "--is-ancestor", "--end-of-options", since, head); err != nil {
+		changes, err := g.all(ctx, head)
+		if err != nil {
+			return nil, "", err
+		}
+		return changes, head, ErrHistoryRewritten
+	}
+	if since == head {
+		return nil, head, nil
+	}
+
+	out, err := g.run(ctx, "diff", "--name-status", "--no-renames", "--end-of-options", since, head)
+	if err != nil {
+		return nil, "", err
+	}
+	var changes []Change
+	for _, line := range strings.Split(out, "\n") {
+		status, path, ok := strings.Cut(line, "\t")
+		if !ok {
+			continue
+		}
+		name := NameFromPath(path)
+		if name == "" {
+			continue
+		}
+		changes = append(changes, Change{Name: name, Path: path, Deleted: status == "D"})
+	}
+	return changes, head, nil
+}
+
+// Fetch clones the index, or fetches new commits into an existing clone,
+// without working out what changed. Use it before reading with Entries when
+// changes aren't needed.
+func (g *GitIndex) Fetch(ctx context.Context) error {
+	return g.update(ctx)
+}
+
+// Entries returns the index entries of a crate at commit rev. An empty rev
+// reads the tracked branch.
+func (g *GitIndex) Entries(ctx context.Context, rev, name string) ([]Entry, error) {
+	if rev == "" {
+		rev = g.ref()
+	}
+	out, err := g.run(ctx, "show", "--end-of-options", rev+":"+Path(name))
+	if err != nil {
+		return nil, err
+	}
+	return ParseEntries([]byte(out))
+}
+
+func (g *GitIndex) ref() string {
+	return "refs/heads/" + g.branch
+}
+
+func (g *GitIndex) update(ctx context.Context) error {
+	if _, err := os.Stat(filepath.Join(g.dir, "HEAD")); os.IsNotExist(err) {
+		if err := os.MkdirAll(filepath.Dir(g.dir), 0o755); err != nil {
+			return err
+		}
+		_, err := g.exec(ctx, "", "clone", "--bare", "--single-branch", "--branch", g.branch, "--", g.url, g.dir)
+		return err
+	}
+	// Force-update so a squashed upstream history replaces ours
+	_, err := g.run(ctx, "fetch", "--quiet", "--", g.url, "+"+g.ref()+":"+g.ref())
+	return err
+}
+
+func (g *GitIndex) all(ctx context.Context, rev string) ([]Change, error) {
+	out, err := g.run(ctx, "ls-tree", "-r", "--name-only", "--end-of-options", rev)
+	if err != nil {
+		return nil, err
+	}
+	var changes []Change
+	for _, path := range strings.Split(out, "\n") {
+		if name := NameFromPath(path); name != "" {
+			changes = append(changes, Change{Name: name, Path: path})
+		}
+	}
+	return changes, nil
+}
+
+func (g *GitIndex) run(ctx context.Context, args ...string) (string, error) {
+	return g.exec(ctx, g.dir, args...)
+}
+
+func (g *GitIndex) exec(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, g.git, args...)
+	cmd.Dir = dir
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("cargoindex: git %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
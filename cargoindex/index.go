@@ -0,0 +1,109 @@
+// Package cargoindex reads and syncs the Cargo registry index, the
+// newline-delimited JSON files that describe every published crate version.
+//
+// GitIndex keeps a local clone of a git index such as crates.io-index and
+// reports which crates changed between two commits, which is the basis for
+// incremental mirrors and scanners:
+//
+//	idx := cargoindex.NewGitIndex("/var/lib/crates-index", "")
+//	changes, head, err := idx.Sync(ctx, lastHead)
+//	for _, c := range changes {
+//		entries, err := idx.Entries(ctx, head, c.Name)
+//		...
+//	}
+//
+// Sparse reads single crates from the HTTP sparse index and detects changes
+// to a known set of crates by content hash, since the sparse protocol has no
+// changelog.
+package cargoindex
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Dependency is a dependency of one crate version as recorded in the index.
+type Dependency struct {
+	Name            string   `json:"name"`
+	Req             string   `json:"req"`
+	Features        []string `json:"features"`
+	Optional        bool     `json:"optional"`
+	DefaultFeatures bool     `json:"default_features"`
+	Target          string   `json:"target,omitempty"`
+	Kind            string   `json:"kind,omitempty"`
+	Registry        string   `json:"registry,omitempty"`
+	Package         string   `json:"package,omitempty"`
+}
+
+// Entry is one line of an index file: a single published version.
+type Entry struct {
+	Name        string              `json:"name"`
+	Vers        string              `json:"vers"`
+	Deps        []Dependency        `json:"deps"`
+	Cksum       string              `json:"cksum"`
+	Features    map[string][]string `json:"features"`
+	Features2   map[string][]string `json:"features2,omitempty"`
+	Yanked      bool                `json:"yanked"`
+	Links       string              `json:"links,omitempty"`
+	V           int                 `json:"v,omitempty"`
+	RustVersion string              `json:"rust_version,omitempty"`
+}
+
+// ParseEntries parses an index file. Blank lines are ignored.
+func ParseEntries(data []byte) ([]Entry, error) {
+	var entries []Entry
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+		var e Entry
+		if err := json.Unmarshal(text, &e); err != nil {
+			return nil, fmt.Errorf("cargoindex: line %d: %w", line, err)
+		}
+		entries = append(entries, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// Path returns the path of a crate's file within the index, e.g.
+// "se/rd/serde", "3/s/syn" or "1/a".
+func Path(name string) string {
+	name = strings.ToLower(name)
+	switch len(name) {
+	case 0:
+		return ""
+	case 1:
+		return "1/" + name
+	case 2:
+		return "2/" + name
+	case 3:
+		return "3/" + name[:1] + "/" + name
+	default:
+		return name[:2] + "/" + name[2:4] + "/" + name
+	}
+}
+
+// NameFromPath returns the crate name for an index file path, or "" if the
+// path is not a crate file (config.json, dotfiles or a mismatched layout).
+func NameFromPath(path string) string {
+	i := strings.LastIndexByte(path, '/')
+	if i < 0 {
+		return ""
+	}
+	name := path[i+1:]
+	if name == "" || strings.HasPrefix(name, ".") || Path(name) != strings.ToLower(path) {
+		return ""
+	}
+	return name
+}
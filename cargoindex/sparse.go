@@ -0,0 +1,70 @@
+package cargoindex
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"github.com/git-pkgs/registries/client"
+)
+
+// CratesIOSparseURL is the sparse HTTP index of crates.io.
+const CratesIOSparseURL = "https://index.crates.io"
+
+// Sparse reads crates from a sparse HTTP index.
+type Sparse struct {
+	baseURL string
+	client  *client.Client
+}
+
+// NewSparse returns a reader for the sparse index at baseURL, or
+// CratesIOSparseURL if baseURL is empty. If c is nil, client.DefaultClient()
+// is used. Give the client a Cache so unchanged files are revalidated with
+// ETags rather than downloaded again.
+func NewSparse(c *client.Client, baseURL string) *Sparse {
+	if baseURL == "" {
+		baseURL = CratesIOSparseURL
+	}
+	if c == nil {
+		c = client.DefaultClient()
+	}
+	return &Sparse{baseURL: strings.TrimSuffix(baseURL, "/"), client: c}
+}
+
+// Entries returns the index entries of a crate.
+func (s *Sparse) Entries(ctx context.Context, name string) ([]Entry, error) {
+	body, err := s.client.GetBody(ctx, s.baseURL+"/"+Path(name))
+	if err != nil {
+		return nil, err
+	}
+	return ParseEntries(body)
+}
+
+// Changed fetches each crate in names and returns those whose index file
+// differs from the hash recorded in state, updating state in place. Crates
+// missing from state are reported as changed. A crate that no longer exists
+// is reported as deleted and removed from state.
+func (s *Sparse) Changed(ctx context.Context, names []string, state map[string]string) ([]Change, error) {
+	var changes []Change
+	for _, name := range names {
+		body, err := s.client.GetBody(ctx, s.baseURL+"/"+Path(name))
+		if err != nil {
+			if httpErr, ok := err.(*client.HTTPError); ok && httpErr.IsNotFound() {
+				if _, known := state[name]; known {
+					delete(state, name)
+					changes = append(changes, Change{Name: name, Path: Path(name), Deleted: true})
+				}
+				continue
+			}
+			return changes, err
+		}
+		sum := sha256.Sum256(body)
+		hash := hex.EncodeToString(sum[:])
+		if state[name] != hash {
+			state[name] = hash
+			changes = append(changes, Change{Name: name, Path: Path(name)})
+		}
+	}
+	return changes, nil
+}
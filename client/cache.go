@@ -0,0 +1,201 @@
+package client
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// ErrCacheMiss is returned in offline mode when a response isn't cached.
+var ErrCacheMiss = errors.New("offline: response not in cache")
+
+// CacheMissError reports which URL was missing from the cache.
+type CacheMissError struct {
+	URL string
+}
+
+func (e *CacheMissError) Error() string {
+	return fmt.Sprintf("offline: %s not in cache", e.URL)
+}
+
+func (e *CacheMissError) Unwrap() error {
+	return ErrCacheMiss
+}
+
+// CachedResponse is a successful response body stored with the validators
+// needed to revalidate it.
+type CachedResponse struct {
+	URL          string
+	ETag         string
+	LastModified string
+	StoredAt     time.Time
+	Body         []byte
+}
+
+// Cache stores successful GET responses. Keys are opaque strings derived
+// from the request URL and credentials.
+type Cache interface {
+	// Get returns the cached response for key, or nil if there is none.
+	Get(key string) (*CachedResponse, error)
+	// Put stores a response under key, replacing any previous entry.
+	Put(key string, resp *CachedResponse) error
+}
+
+// DiskCache is a Cache persisted under a directory. Bodies are stored as
+// content-addressed blobs named by the hash of the key and validators, with
+// a small index file per key pointing at the current blob.
+type DiskCache struct {
+	dir string
+}
+
+type diskIndex struct {
+	URL          string    `json:"url"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	StoredAt     time.Time `json:"stored_at"`
+	Blob         string    `json:"blob"`
+}
+
+// NewDiskCache returns a cache rooted at dir, creating it if necessary.
+func NewDiskCache(dir string) (*DiskCache, error) {
+	for _, sub := range []string{"index", "blobs"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o755); err != nil {
+			return nil, err
+		}
+	}
+	return &DiskCache{dir: dir}, nil
+}
+
+func hashKey(parts ...string) string {
+	h := sha256.New()
+	for _, p := range parts {
+		h.Write([]byte(p))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (d *DiskCache) indexPath(key string) string {
+	return filepath.Join(d.dir, "index", hashKey(key)+".json")
+}
+
+func (d *DiskCache) blobPath(blob string) string {
+	return filepath.Join(d.dir, "blobs", blob)
+}
+
+// Get implements Cache.
+func (d *DiskCache) Get(key string) (*CachedResponse, error) {
+	data, err := os.ReadFile(d.indexPath(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var idx diskIndex
+	if err := json.Unmarshal(data, &idx); err != nil {
+		// A corrupt index entry is treated as a miss and overwritten later
+		return nil, nil
+	}
+
+	body, err := os.ReadFile(d.blobPath(idx.Blob))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &CachedResponse{
+		URL:          idx.URL,
+		ETag:         idx.ETag,
+		LastModified: idx.LastModified,
+		StoredAt:     idx.StoredAt,
+		Body:         body,
+	}, nil
+}
+
+// Put implements Cache.
+func (d *DiskCache) Put(key string, resp *CachedResponse) error {
+	idx := diskIndex{
+		URL:          resp.URL,
+		ETag:         resp.ETag,
+		LastModified: resp.LastModified,
+		StoredAt:     resp.StoredAt,
+		Blob:         hashKey(key, resp.ETag, resp.LastModified),
+	}
+
+	var previous diskIndex
+	if data, err := os.ReadFile(d.indexPath(key)); err == nil {
+		_ = json.Unmarshal(data, &previous)
+	}
+
+	if err := writeFileAtomic(d.blobPath(idx.Blob), resp.Body); err != nil {
+		return err
+	}
+	data, err := json.Marshal(idx)
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(d.indexPath(key), data); err != nil {
+		return err
+	}
+
+	if previous.Blob != "" && previous.Blob != idx.Blob {
+		_ = os.Remove(d.blobPath(previous.Blob))
+	}
+	return nil
+}
+
+// writeFileAtomic writes via a temp file and rename so concurrent readers
+// never see a partially written file.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// MemoryCache is a Cache held in memory for the life of the process. It is
+// useful for pollers that want conditional requests without touching disk.
+type MemoryCache struct {
+	mu      sync.RWMutex
+	entries map[string]*CachedResponse
+}
+
+// NewMemoryCache returns an empty in-memory cache.
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{entries: make(map[string]*CachedResponse)}
+}
+
+// Get implements Cache.
+func (m *MemoryCache) Get(key string) (*CachedResponse, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.entries[key], nil
+}
+
+// Put implements Cache.
+func (m *MemoryCache) Put(key string, resp *CachedResponse) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries[key] = resp
+	return nil
+}
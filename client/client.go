@@ -2,11 +2,9 @@
 package client
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
-	"io"
-	"math"
-	"math/rand"
 	"net/http"
 	"strconv"
 	"time"
@@ -24,6 +22,46 @@ type Client struct {
 	MaxRetries  int
 	BaseDelay   time.Duration
 	RateLimiter RateLimiter
+
+	// AuthFunc returns an auth header to send with a request to url.
+	// Return empty strings to send the request unauthenticated.
+	AuthFunc func(url string) (headerName, headerValue string)
+
+	// Cache stores successful GET responses and revalidates them with
+	// If-None-Match / If-Modified-Since. Nil disables caching.
+	Cache Cache
+
+	// CacheTTL is how long a cached response is used without revalidating.
+	// Zero revalidates on every request.
+	CacheTTL time.Duration
+
+	// Offline serves GETs only from Cache and never touches the network.
+	// Requests for uncached URLs fail with an error wrapping ErrCacheMiss.
+	Offline bool
+
+	// MaxBodySize limits the decoded size of metadata responses, and
+	// MaxArtifactSize the size of archives fetched with GetArtifact. Zero
+	// means no limit.
+	MaxBodySize     int64
+	MaxArtifactSize int64
+
+	// Clock times retry backoff and cache TTLs. Nil uses SystemClock.
+	Clock Clock
+
+	// Retry, if set, decides which failed requests are retried and how,
+	// in place of MaxRetries and BaseDelay.
+	Retry *RetryPolicy
+
+	// dial holds the settings behind WithDialTimeout and WithDNSCache.
+	dial *dialSettings
+
+	// inflight coalesces concurrent GETs of the same URL. Copies made with
+	// the With* methods share it. Nil disables deduplication.
+	inflight *inflightGroup
+
+	// stats counts responses and retries for ClientStats. Copies made with
+	// the With* methods share it. Nil records nothing.
+	stats *StatsRecorder
 }
 
 // DefaultClient returns a client with sensible defaults.
@@ -35,6 +73,8 @@ func DefaultClient() *Client {
 		UserAgent:  "registries",
 		MaxRetries: 5,
 		BaseDelay:  50 * time.Millisecond,
+		inflight:   newInflightGroup(),
+		stats:      NewStatsRecorder(),
 	}
 }
 
@@ -48,89 +88,298 @@ func (c *Client) GetJSON(ctx context.Context, url string, v any) error {
 }
 
 // GetBody fetches a URL and returns the response body.
+// Concurrent calls for the same URL and credentials share one request.
 func (c *Client) GetBody(ctx context.Context, url string) ([]byte, error) {
-	var lastErr error
-
-	for attempt := 0; attempt <= c.MaxRetries; attempt++ {
-		if attempt > 0 {
-			delay := c.BaseDelay * time.Duration(math.Pow(2, float64(attempt-1)))
-			jitter := time.Duration(float64(delay) * (rand.Float64() * 0.1))
-			delay += jitter
-
-			select {
-			case <-ctx.Done():
-				return nil, ctx.Err()
-			case <-time.After(delay):
-			}
+	return c.get(ctx, url, c.MaxBodySize, "", "")
+}
+
+// GetBodyAccept is GetBody for APIs that choose a response format from the
+// Accept header, such as OCI registries serving image manifests. Responses
+// are cached and shared separately for each accept value.
+func (c *Client) GetBodyAccept(ctx context.Context, url, accept string) ([]byte, error) {
+	return c.get(ctx, url, c.MaxBodySize, "", accept)
+}
+
+// GetArtifact fetches a package archive or other release file whole, as
+// GetBody does, but limited by MaxArtifactSize rather than MaxBodySize.
+// Registries use it where they read metadata out of an archive.
+func (c *Client) GetArtifact(ctx context.Context, url string) ([]byte, error) {
+	return c.get(ctx, url, c.MaxArtifactSize, "\x00artifact", "")
+}
+
+func (c *Client) get(ctx context.Context, url string, limit int64, kind, accept string) ([]byte, error) {
+	var body []byte
+	var err error
+	if c.inflight == nil {
+		body, err = c.getBody(ctx, url, limit, accept)
+	} else {
+		// Requests with different limits must not share results
+		key := c.cacheKey(url, accept) + kind
+		if c.Offline {
+			// Offline and online copies of a client must not share results
+			key += "\x00offline"
 		}
+		body, err = c.inflight.do(ctx, key, func(ctx context.Context) ([]byte, error) {
+			return c.getBody(ctx, url, limit, accept)
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+	c.recordSource(ctx, url)
+	return body, nil
+}
+
+// requestKey identifies requests that can share a response. Credentials are
+// part of the key so clients with different auth never see each other's data.
+func (c *Client) requestKey(url string) string {
+	if c.AuthFunc == nil {
+		return url
+	}
+	name, value := c.AuthFunc(url)
+	return url + "\x00" + name + "\x00" + value
+}
+
+// cacheKey is requestKey for a response in the format accept asks for, or
+// JSON if accept is empty.
+func (c *Client) cacheKey(url, accept string) string {
+	if accept == "" {
+		return c.requestKey(url)
+	}
+	return c.requestKey(url) + "\x00accept\x00" + accept
+}
+
+func (c *Client) getBody(ctx context.Context, url string, limit int64, accept string) ([]byte, error) {
+	var cached *CachedResponse
+	if c.Cache != nil {
+		cached, _ = c.Cache.Get(c.cacheKey(url, accept))
+	}
+	if c.Offline {
+		if cached == nil {
+			return nil, &CacheMissError{URL: url}
+		}
+		return cached.Body, nil
+	}
+	if cached != nil && c.CacheTTL > 0 && c.clock().Now().Sub(cached.StoredAt) < c.CacheTTL {
+		return cached.Body, nil
+	}
 
+	return c.withRetries(ctx, url, func() ([]byte, error) {
+		return c.doRequest(ctx, url, accept, cached, limit)
+	})
+}
+
+// withRetries calls do under the client's retry policy. The rate limiter is
+// consulted before every attempt.
+func (c *Client) withRetries(ctx context.Context, url string, do func() ([]byte, error)) ([]byte, error) {
+	var body []byte
+	err := c.retryPolicy().Do(ctx, url, func() Attempt {
 		if c.RateLimiter != nil {
 			if err := c.RateLimiter.Wait(ctx); err != nil {
-				return nil, err
+				return Attempt{Err: err}
 			}
 		}
 
-		body, err := c.doRequest(ctx, url)
-		if err == nil {
-			return body, nil
+		var err error
+		body, err = do()
+		switch e := err.(type) {
+		case nil:
+			return Attempt{}
+		case *HTTPError:
+			return Attempt{Status: e.StatusCode, RetryAfter: e.RetryAfter, Err: err}
+		case *RateLimitError:
+			return Attempt{Status: http.StatusTooManyRequests, RetryAfter: time.Duration(e.RetryAfter) * time.Second, Err: err}
+		case *ResponseTooLargeError:
+			// The server answered; asking again gets the same answer
+			return Attempt{Status: http.StatusOK, Err: err}
+		default:
+			return Attempt{Err: err}
 		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return body, nil
+}
 
-		lastErr = err
+// retryPolicy returns Retry, or a policy built from MaxRetries and BaseDelay
+// when that is unset.
+func (c *Client) retryPolicy() *RetryPolicy {
+	p := &RetryPolicy{
+		MaxRetries: c.MaxRetries,
+		BaseDelay:  c.BaseDelay,
+		Jitter:     0.1,
+		Clock:      c.Clock,
+	}
+	if c.Retry != nil {
+		p = c.Retry.WithClock(c.Clock)
+	}
+	if c.stats != nil {
+		p = c.stats.Observe(p)
+	}
+	return p
+}
 
-		var httpErr *HTTPError
-		if ok := isHTTPError(err, &httpErr); ok {
-			if httpErr.StatusCode == 404 {
-				return nil, err
-			}
-			if httpErr.StatusCode == 429 || httpErr.StatusCode >= 500 {
-				continue
-			}
-			return nil, err
-		}
+// record counts a response, or a request that got none, for ClientStats.
+func (c *Client) record(url string, resp *http.Response) {
+	if c.stats == nil {
+		return
+	}
+	if resp == nil {
+		c.stats.Record(url, 0, nil, c.clock().Now())
+		return
 	}
+	c.stats.Record(url, resp.StatusCode, resp.Header, c.clock().Now())
+}
 
-	return nil, lastErr
+// ClientStats returns a snapshot of the responses and retries the client
+// and its copies have seen, the backoff before retries still waiting, and
+// each host's rate limit as its last response reported it. A Client not
+// made by DefaultClient or NewClient has no stats unless given a recorder
+// with WithStatsRecorder.
+func (c *Client) ClientStats() Stats {
+	return c.stats.SnapshotAt(c.clock().Now())
 }
 
-func (c *Client) doRequest(ctx context.Context, url string) ([]byte, error) {
+func (c *Client) doRequest(ctx context.Context, url, accept string, cached *CachedResponse, limit int64) ([]byte, error) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return nil, err
 	}
 
 	req.Header.Set("User-Agent", c.UserAgent)
-	req.Header.Set("Accept", "application/json")
+	if accept == "" {
+		req.Header.Set("Accept", "application/json")
+	} else {
+		req.Header.Set("Accept", accept)
+	}
+	req.Header.Set("Accept-Encoding", acceptEncoding)
+	c.setAuth(req, url)
+	if cached != nil {
+		if cached.ETag != "" {
+			req.Header.Set("If-None-Match", cached.ETag)
+		}
+		if cached.LastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.LastModified)
+		}
+	}
 
 	resp, err := c.HTTPClient.Do(req)
+	c.record(url, resp)
 	if err != nil {
 		return nil, err
 	}
 	defer func() { _ = resp.Body.Close() }()
 
-	body, err := io.ReadAll(resp.Body)
+	// A body that fails to decode doesn't hide the status it came with
+	body, err := readBody(resp, url, limit)
+	if resp.StatusCode >= 400 {
+		return nil, responseError(resp, url, body)
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	if resp.StatusCode >= 400 {
-		httpErr := &HTTPError{
-			StatusCode: resp.StatusCode,
-			URL:        url,
-			Body:       string(body),
+	if c.Cache != nil {
+		if resp.StatusCode == http.StatusNotModified && cached != nil {
+			body = cached.Body
 		}
-		if resp.StatusCode == 429 {
-			if retryAfter := resp.Header.Get("Retry-After"); retryAfter != "" {
-				if seconds, err := strconv.Atoi(retryAfter); err == nil {
-					return nil, &RateLimitError{RetryAfter: seconds}
-				}
+		if resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusNotModified {
+			etag, lastModified := resp.Header.Get("ETag"), resp.Header.Get("Last-Modified")
+			if resp.StatusCode == http.StatusNotModified {
+				etag, lastModified = cached.ETag, cached.LastModified
 			}
+			_ = c.Cache.Put(c.cacheKey(url, accept), &CachedResponse{
+				URL:          url,
+				ETag:         etag,
+				LastModified: lastModified,
+				StoredAt:     c.clock().Now(),
+				Body:         body,
+			})
 		}
-		return nil, httpErr
 	}
 
 	return body, nil
 }
 
+// Post sends body to url with the given content type and returns the
+// response body. Posts are retried like GETs but never cached or
+// deduplicated, and fail with ErrCacheMiss in offline mode.
+func (c *Client) Post(ctx context.Context, url, contentType string, body []byte) ([]byte, error) {
+	if c.Offline {
+		return nil, &CacheMissError{URL: url}
+	}
+	resp, err := c.withRetries(ctx, url, func() ([]byte, error) {
+		return c.doPost(ctx, url, contentType, body)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if log := sourceLogFrom(ctx); log != nil {
+		log.note(url, c.clock().Now(), nil)
+	}
+	return resp, nil
+}
+
+func (c *Client) doPost(ctx context.Context, url, contentType string, body []byte) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("User-Agent", c.UserAgent)
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Accept-Encoding", acceptEncoding)
+	c.setAuth(req, url)
+
+	resp, err := c.HTTPClient.Do(req)
+	c.record(url, resp)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	respBody, err := readBody(resp, url, c.MaxBodySize)
+	if resp.StatusCode >= 400 {
+		return nil, responseError(resp, url, respBody)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return respBody, nil
+}
+
+// responseError describes a failed response. A 429 with a Retry-After
+// header becomes a RateLimitError; other statuses become an HTTPError, which
+// also carries any Retry-After, as 503 responses often send one.
+func responseError(resp *http.Response, url string, body []byte) error {
+	header := resp.Header.Get("Retry-After")
+	retryAfter := ParseRetryAfter(header)
+	if resp.StatusCode == http.StatusTooManyRequests && header != "" {
+		if seconds, err := strconv.Atoi(header); err == nil {
+			return &RateLimitError{RetryAfter: seconds}
+		}
+		if retryAfter > 0 {
+			return &RateLimitError{RetryAfter: int((retryAfter + time.Second - 1) / time.Second)}
+		}
+	}
+	return &HTTPError{
+		StatusCode: resp.StatusCode,
+		URL:        url,
+		Body:       string(body),
+		RetryAfter: retryAfter,
+	}
+}
+
+func (c *Client) setAuth(req *http.Request, url string) {
+	if c.AuthFunc == nil {
+		return
+	}
+	if name, value := c.AuthFunc(url); name != "" && value != "" {
+		req.Header.Set(name, value)
+	}
+}
+
 func isHTTPError(err error, target **HTTPError) bool {
 	if httpErr, ok := err.(*HTTPError); ok {
 		*target = httpErr
@@ -149,21 +398,41 @@ func (c *Client) GetText(ctx context.Context, url string) (string, error) {
 }
 
 // Head sends a HEAD request and returns the status code.
+// In offline mode it reports 200 for cached URLs and ErrCacheMiss otherwise.
 func (c *Client) Head(ctx context.Context, url string) (int, error) {
+	status, _, err := c.HeadHeader(ctx, url)
+	return status, err
+}
+
+// HeadHeader sends a HEAD request and returns the status code and response
+// headers, for callers that need Last-Modified or Content-Length. In offline
+// mode it behaves as Head and returns no headers.
+func (c *Client) HeadHeader(ctx context.Context, url string) (int, http.Header, error) {
+	if c.Offline {
+		if c.Cache != nil {
+			if cached, _ := c.Cache.Get(c.requestKey(url)); cached != nil {
+				return http.StatusOK, nil, nil
+			}
+		}
+		return 0, nil, &CacheMissError{URL: url}
+	}
+
 	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
 	if err != nil {
-		return 0, err
+		return 0, nil, err
 	}
 
 	req.Header.Set("User-Agent", c.UserAgent)
+	c.setAuth(req, url)
 
 	resp, err := c.HTTPClient.Do(req)
+	c.record(url, resp)
 	if err != nil {
-		return 0, err
+		return 0, nil, err
 	}
 	_ = resp.Body.Close()
 
-	return resp.StatusCode, nil
+	return resp.StatusCode, resp.Header, nil
 }
 
 // WithRateLimiter returns a copy of the client with the given rate limiter.
@@ -180,6 +449,60 @@ func (c *Client) WithUserAgent(ua string) *Client {
 	return &copy
 }
 
+// WithAuthFunc returns a copy of the client that adds the header returned by
+// fn to each request.
+func (c *Client) WithAuthFunc(fn func(url string) (headerName, headerValue string)) *Client {
+	copy := *c
+	copy.AuthFunc = fn
+	return &copy
+}
+
+// WithCache returns a copy of the client that caches GET responses in cache.
+func (c *Client) WithCache(cache Cache) *Client {
+	copy := *c
+	copy.Cache = cache
+	return &copy
+}
+
+// WithCacheTTL returns a copy of the client that serves cached responses
+// younger than ttl without revalidating them.
+func (c *Client) WithCacheTTL(ttl time.Duration) *Client {
+	copy := *c
+	copy.CacheTTL = ttl
+	return &copy
+}
+
+// WithRetryPolicy returns a copy of the client that retries failed requests
+// according to p.
+func (c *Client) WithRetryPolicy(p *RetryPolicy) *Client {
+	copy := *c
+	copy.Retry = p
+	return &copy
+}
+
+// WithOffline returns a copy of the client that only serves cached responses.
+func (c *Client) WithOffline(offline bool) *Client {
+	copy := *c
+	copy.Offline = offline
+	return &copy
+}
+
+// WithStatsRecorder returns a copy of the client that counts its requests
+// in r, such as one shared with a fetch.Fetcher. Nil records nothing.
+func (c *Client) WithStatsRecorder(r *StatsRecorder) *Client {
+	copy := *c
+	copy.stats = r
+	return &copy
+}
+
+// WithoutDeduplication returns a copy of the client that sends every GET
+// upstream, even when an identical request is already in flight.
+func (c *Client) WithoutDeduplication() *Client {
+	copy := *c
+	copy.inflight = nil
+	return &copy
+}
+
 // Option configures a Client.
 type Option func(*Client)
 
@@ -197,6 +520,21 @@ func WithMaxRetries(n int) Option {
 	}
 }
 
+// WithRetryPolicy sets the retry policy, replacing MaxRetries and BaseDelay.
+func WithRetryPolicy(p *RetryPolicy) Option {
+	return func(c *Client) {
+		c.Retry = p
+	}
+}
+
+// WithStatsRecorder counts the client's requests in r instead of a
+// recorder of its own.
+func WithStatsRecorder(r *StatsRecorder) Option {
+	return func(c *Client) {
+		c.stats = r
+	}
+}
+
 // NewClient creates a new client with the given options.
 func NewClient(opts ...Option) *Client {
 	c := DefaultClient()
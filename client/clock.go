@@ -0,0 +1,103 @@
+package client
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Sleeper waits between retries.
+type Sleeper interface {
+	// Sleep waits for d, returning ctx.Err() if ctx is done first.
+	Sleep(ctx context.Context, d time.Duration) error
+}
+
+// Clock is the time source behind retry backoff, retry budgets and cache
+// TTLs. Tests can replace it with a FakeClock to exercise retries without
+// waiting.
+type Clock interface {
+	Now() time.Time
+	Sleeper
+}
+
+// SystemClock is the real clock, used when none is set.
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+func (systemClock) Sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// FakeClock is a Clock whose Sleep returns immediately, moving the clock
+// forward by the time slept. It records each sleep so tests can check the
+// backoff a client used. It is safe for concurrent use.
+type FakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	sleeps []time.Duration
+}
+
+// NewFakeClock returns a FakeClock reading start.
+func NewFakeClock(start time.Time) *FakeClock {
+	return &FakeClock{now: start}
+}
+
+// Now returns the clock's current time.
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Sleep advances the clock by d without waiting. It returns ctx.Err() if
+// ctx is already done.
+func (c *FakeClock) Sleep(ctx context.Context, d time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	c.sleeps = append(c.sleeps, d)
+	return nil
+}
+
+// Advance moves the clock forward by d.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// Sleeps returns the durations passed to Sleep, in order.
+func (c *FakeClock) Sleeps() []time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]time.Duration(nil), c.sleeps...)
+}
+
+// WithClock sets the clock used for retry backoff, retry budgets and cache
+// TTLs.
+func WithClock(clock Clock) Option {
+	return func(c *Client) {
+		c.Clock = clock
+	}
+}
+
+// clock returns Clock, or SystemClock when it is unset.
+func (c *Client) clock() Clock {
+	if c.Clock != nil {
+		return c.Clock
+	}
+	return SystemClock
+}
@@ -0,0 +1,109 @@
+package client
+
+import (
+	"compress/bzip2"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+)
+
+// acceptEncoding lists the content encodings the client asks for and
+// decodes. Setting it ourselves turns off the transport's own gzip handling.
+const acceptEncoding = "gzip, br, zstd"
+
+// Decompress returns a reader that decodes r, compressed in format: "gzip",
+// "br", "zstd", "xz" or "bzip2". The empty format and "identity" return r
+// as is. Closing the result releases the decoder but doesn't close r.
+func Decompress(r io.Reader, format string) (io.ReadCloser, error) {
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case "", "identity":
+		return io.NopCloser(r), nil
+	case "gzip", "x-gzip":
+		return gzip.NewReader(r)
+	case "br":
+		return io.NopCloser(brotli.NewReader(r)), nil
+	case "zstd":
+		d, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, err
+		}
+		return d.IOReadCloser(), nil
+	case "xz":
+		xr, err := xz.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return io.NopCloser(xr), nil
+	case "bzip2":
+		return io.NopCloser(bzip2.NewReader(r)), nil
+	default:
+		return nil, fmt.Errorf("unsupported compression %q", format)
+	}
+}
+
+// CompressionFromPath returns the compression format a file's extension
+// implies, such as "zstd" for repodata.json.zst or "xz" for Packages.xz,
+// and "" for anything else.
+func CompressionFromPath(p string) string {
+	if i := strings.IndexAny(p, "?#"); i >= 0 {
+		p = p[:i]
+	}
+	switch path.Ext(p) {
+	case ".gz", ".gzip":
+		return "gzip"
+	case ".br":
+		return "br"
+	case ".zst", ".zstd":
+		return "zstd"
+	case ".xz":
+		return "xz"
+	case ".bz2":
+		return "bzip2"
+	}
+	return ""
+}
+
+// decodeContent decodes a response body according to its Content-Encoding
+// header. Encodings listed as "gzip, br" were applied in that order, so
+// they are undone last first. A positive limit stops reading the decoded
+// body after that many bytes.
+func decodeContent(body io.Reader, contentEncoding string, limit int64) ([]byte, error) {
+	if contentEncoding == "" {
+		return readAll(body, limit)
+	}
+	encodings := strings.Split(contentEncoding, ",")
+	var closers []io.Closer
+	defer func() {
+		for _, c := range closers {
+			_ = c.Close()
+		}
+	}()
+	r := body
+	for i := len(encodings) - 1; i >= 0; i-- {
+		rc, err := Decompress(r, encodings[i])
+		if errors.Is(err, io.EOF) {
+			// gzip reports an empty body as EOF before reading a header
+			return []byte{}, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		closers = append(closers, rc)
+		r = rc
+	}
+	return readAll(r, limit)
+}
+
+func readAll(r io.Reader, limit int64) ([]byte, error) {
+	if limit > 0 {
+		r = io.LimitReader(r, limit)
+	}
+	return io.ReadAll(r)
+}
@@ -3,6 +3,7 @@ package client
 import (
 	"errors"
 	"fmt"
+	"time"
 )
 
 // ErrNotFound is returned when a package or version is not found.
@@ -13,6 +14,7 @@ type HTTPError struct {
 	StatusCode int
 	URL        string
 	Body       string
+	RetryAfter time.Duration // from the Retry-After header, if any
 }
 
 func (e *HTTPError) Error() string {
@@ -50,3 +52,25 @@ type RateLimitError struct {
 func (e *RateLimitError) Error() string {
 	return fmt.Sprintf("rate limited, retry after %d seconds", e.RetryAfter)
 }
+
+// ErrNotSupported is returned when a registry doesn't offer the requested data.
+var ErrNotSupported = errors.New("not supported by registry")
+
+// ErrResponseTooLarge is returned when a response body is bigger than the
+// client's MaxBodySize.
+var ErrResponseTooLarge = errors.New("response exceeds size limit")
+
+// ResponseTooLargeError reports a response body over the size limit. It
+// wraps ErrResponseTooLarge.
+type ResponseTooLargeError struct {
+	URL   string
+	Limit int64
+}
+
+func (e *ResponseTooLargeError) Error() string {
+	return fmt.Sprintf("%s: response exceeds %d byte limit", e.URL, e.Limit)
+}
+
+func (e *ResponseTooLargeError) Unwrap() error {
+	return ErrResponseTooLarge
+}
@@ -0,0 +1,79 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// GraphQLError reports the errors in a GraphQL response. GraphQL servers
+// answer 200 OK to queries that fail, listing what went wrong instead.
+type GraphQLError struct {
+	URL    string
+	Errors []GraphQLErrorDetail
+}
+
+// GraphQLErrorDetail is one entry of a GraphQL response's errors.
+type GraphQLErrorDetail struct {
+	Message string `json:"message"`
+	// Type is the error's category where the server gives one, such as
+	// GitHub's NOT_FOUND or FORBIDDEN.
+	Type string `json:"type,omitempty"`
+	Path []any  `json:"path,omitempty"`
+}
+
+func (e *GraphQLError) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i, d := range e.Errors {
+		messages[i] = d.Message
+	}
+	return fmt.Sprintf("GraphQL %s: %s", e.URL, strings.Join(messages, "; "))
+}
+
+// IsNotFound reports whether every error says something queried doesn't
+// exist.
+func (e *GraphQLError) IsNotFound() bool {
+	for _, d := range e.Errors {
+		if d.Type != "NOT_FOUND" {
+			return false
+		}
+	}
+	return len(e.Errors) > 0
+}
+
+// GraphQL posts query with variables to a GraphQL endpoint and decodes the
+// response's data into data. Authentication comes from AuthFunc, as for
+// other requests. If the response lists errors, what data it has is still
+// decoded and a *GraphQLError is returned. Queries are retried like other
+// requests, but never cached.
+func (c *Client) GraphQL(ctx context.Context, url, query string, variables map[string]any, data any) error {
+	body, err := json.Marshal(struct {
+		Query     string         `json:"query"`
+		Variables map[string]any `json:"variables,omitempty"`
+	}{query, variables})
+	if err != nil {
+		return err
+	}
+	respBody, err := c.Post(ctx, url, "application/json", body)
+	if err != nil {
+		return err
+	}
+
+	var resp struct {
+		Data   json.RawMessage      `json:"data"`
+		Errors []GraphQLErrorDetail `json:"errors"`
+	}
+	if err := json.Unmarshal(respBody, &resp); err != nil {
+		return err
+	}
+	if len(resp.Data) > 0 && string(resp.Data) != "null" && data != nil {
+		if err := json.Unmarshal(resp.Data, data); err != nil {
+			return err
+		}
+	}
+	if len(resp.Errors) > 0 {
+		return &GraphQLError{URL: url, Errors: resp.Errors}
+	}
+	return nil
+}
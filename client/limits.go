@@ -0,0 +1,76 @@
+package client
+
+import (
+	"net/http"
+	"time"
+)
+
+// WithMaxBodySize limits the size of metadata responses the client reads, after
+// decompression, so a misbehaving registry can't exhaust memory with a huge
+// or highly compressed document. Larger responses fail with a
+// ResponseTooLargeError. Zero, the default, means no limit.
+func WithMaxBodySize(n int64) Option {
+	return func(c *Client) {
+		c.MaxBodySize = n
+	}
+}
+
+// WithMaxArtifactSize limits the size of archives fetched with GetArtifact,
+// which registries download to read metadata out of, such as PyPI sdists.
+// Zero, the default, means no limit.
+func WithMaxArtifactSize(n int64) Option {
+	return func(c *Client) {
+		c.MaxArtifactSize = n
+	}
+}
+
+// WithMaxBodySize returns a copy of the client with a different response
+// size limit, for requests known to return larger documents than the rest,
+// such as a conda channel's repodata.json.
+func (c *Client) WithMaxBodySize(n int64) *Client {
+	copy := *c
+	copy.MaxBodySize = n
+	return &copy
+}
+
+// WithResponseHeaderTimeout sets how long to wait for a server to start
+// responding once a request has been sent, separately from the dial timeout
+// and the timeout for the whole request. It catches servers that accept
+// connections and then hang.
+func WithResponseHeaderTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		t := c.transport()
+		if t == nil {
+			return
+		}
+		t.ResponseHeaderTimeout = d
+	}
+}
+
+// readBody reads and decodes a response body, failing once it passes limit
+// unless limit is zero. A Content-Length over the limit fails without
+// reading anything.
+func readBody(resp *http.Response, url string, limit int64) ([]byte, error) {
+	// Responses without a body may still echo the Content-Encoding header
+	if resp.StatusCode == http.StatusNotModified || resp.StatusCode == http.StatusNoContent ||
+		resp.ContentLength == 0 || (resp.Request != nil && resp.Request.Method == http.MethodHead) {
+		return []byte{}, nil
+	}
+	encoding := resp.Header.Get("Content-Encoding")
+	if limit <= 0 {
+		return decodeContent(resp.Body, encoding, 0)
+	}
+	if encoding == "" && resp.ContentLength > limit {
+		return nil, &ResponseTooLargeError{URL: url, Limit: limit}
+	}
+	// Reading one byte past the limit tells a body of exactly the limit
+	// from a longer one
+	body, err := decodeContent(resp.Body, encoding, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(body)) > limit {
+		return nil, &ResponseTooLargeError{URL: url, Limit: limit}
+	}
+	return body, nil
+}
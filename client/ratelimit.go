@@ -0,0 +1,52 @@
+package client
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// IntervalLimiter is a RateLimiter that spaces requests evenly so that no
+// more than a fixed number are started per second.
+type IntervalLimiter struct {
+	interval time.Duration
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+// NewIntervalLimiter returns a limiter allowing perSecond requests per second.
+// A non-positive rate never waits.
+func NewIntervalLimiter(perSecond float64) *IntervalLimiter {
+	l := &IntervalLimiter{}
+	if perSecond > 0 {
+		l.interval = time.Duration(float64(time.Second) / perSecond)
+	}
+	return l
+}
+
+// Wait blocks until the next request slot is available or ctx is done.
+func (l *IntervalLimiter) Wait(ctx context.Context) error {
+	l.mu.Lock()
+	now := time.Now()
+	slot := l.next
+	if slot.Before(now) {
+		slot = now
+	}
+	l.next = slot.Add(l.interval)
+	l.mu.Unlock()
+
+	delay := time.Until(slot)
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
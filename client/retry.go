@@ -0,0 +1,217 @@
+package client
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RetryPolicy decides which failed requests are retried and how long to wait
+// between attempts. The zero value never retries. A policy can be shared by
+// any number of clients and fetchers.
+type RetryPolicy struct {
+	// MaxRetries is the number of retries after the first attempt.
+	MaxRetries int
+
+	// BaseDelay is the wait before the first retry. It doubles with each
+	// further retry, up to MaxDelay if that is set.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+
+	// Jitter adds up to this fraction of the delay at random, so that many
+	// clients failing together don't retry together. 0.1 adds up to 10%.
+	Jitter float64
+
+	// MaxElapsed bounds the time from the first attempt to the start of the
+	// last. A retry that would start later is not made. Zero means no limit.
+	MaxElapsed time.Duration
+
+	// Retryable reports whether a failure with the given HTTP status is
+	// retried. Status 0 means no response was received, as with a refused
+	// connection. Nil uses DefaultRetryable.
+	Retryable func(status int) bool
+
+	// IgnoreRetryAfter waits the policy's own delay even when the response
+	// carries a Retry-After header. Otherwise the longer of the two is used,
+	// and a Retry-After beyond MaxElapsed or the context's deadline ends the
+	// retries straight away rather than waiting for nothing.
+	IgnoreRetryAfter bool
+
+	// Budget, if set, limits the retries sent to each host across everything
+	// that shares it.
+	Budget *RetryBudget
+
+	// Clock times the waits between retries. Nil uses SystemClock. A
+	// client or fetcher with its own clock uses that instead.
+	Clock Clock
+
+	// OnRetry, if set, is called before each wait for a retry with the
+	// host being retried, the retry's number counting from 1, and the
+	// wait.
+	OnRetry func(host string, retry int, delay time.Duration)
+}
+
+// DefaultRetryable retries rate limits (429), server errors (5xx) and
+// requests that got no response.
+func DefaultRetryable(status int) bool {
+	return status == 0 || status == http.StatusTooManyRequests || status >= 500
+}
+
+// Attempt is the outcome of one try of a request, as reported to
+// RetryPolicy.Do.
+type Attempt struct {
+	Status     int           // HTTP status of a failed response, 0 if none was received
+	RetryAfter time.Duration // from the response's Retry-After header, if any
+	Err        error         // nil on success
+}
+
+// Do calls try until it succeeds, fails in a way the policy doesn't retry,
+// or the retries run out, and returns the last error. Canceling ctx while
+// waiting to retry returns ctx.Err().
+func (p *RetryPolicy) Do(ctx context.Context, rawURL string, try func() Attempt) error {
+	clock := p.clock()
+	start := clock.Now()
+	host := hostOf(rawURL)
+
+	for attempt := 0; ; attempt++ {
+		a := try()
+		if a.Err == nil {
+			return nil
+		}
+		if attempt >= p.MaxRetries || !p.retryable(a.Status) {
+			return a.Err
+		}
+
+		delay := p.Delay(attempt + 1)
+		if a.RetryAfter > delay && !p.IgnoreRetryAfter {
+			delay = a.RetryAfter
+		}
+		wake := clock.Now().Add(delay)
+		if p.MaxElapsed > 0 && wake.Sub(start) > p.MaxElapsed {
+			return a.Err
+		}
+		if deadline, ok := ctx.Deadline(); ok && wake.After(deadline) {
+			return a.Err
+		}
+		if p.Budget != nil && !p.Budget.take(host, clock.Now()) {
+			return a.Err
+		}
+		if p.OnRetry != nil {
+			p.OnRetry(host, attempt+1, delay)
+		}
+
+		if err := clock.Sleep(ctx, delay); err != nil {
+			return err
+		}
+	}
+}
+
+func (p *RetryPolicy) clock() Clock {
+	if p.Clock != nil {
+		return p.Clock
+	}
+	return SystemClock
+}
+
+// WithClock returns a copy of p timed by clock, or p itself if clock is nil
+// or p already has a clock.
+func (p *RetryPolicy) WithClock(clock Clock) *RetryPolicy {
+	if clock == nil || p.Clock != nil {
+		return p
+	}
+	copy := *p
+	copy.Clock = clock
+	return &copy
+}
+
+// Delay returns the backoff before the given retry, counting from 1, with
+// jitter applied.
+func (p *RetryPolicy) Delay(retry int) time.Duration {
+	if retry < 1 {
+		return 0
+	}
+	delay := time.Duration(float64(p.BaseDelay) * math.Pow(2, float64(retry-1)))
+	if p.MaxDelay > 0 && (delay > p.MaxDelay || delay < 0) {
+		delay = p.MaxDelay
+	}
+	if p.Jitter > 0 {
+		delay += time.Duration(float64(delay) * p.Jitter * rand.Float64())
+	}
+	return delay
+}
+
+func (p *RetryPolicy) retryable(status int) bool {
+	if p.Retryable != nil {
+		return p.Retryable(status)
+	}
+	return DefaultRetryable(status)
+}
+
+// ParseRetryAfter reads a Retry-After header, which is either a number of
+// seconds or an HTTP date. It returns 0 for a missing or invalid value.
+func ParseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+// RetryBudget limits how many retries are sent to each host within a sliding
+// window. When a registry is struggling every request to it fails, and
+// without a budget each one brings its full quota of retries; with one, the
+// retries stop once the host has had its share and requests fail fast until
+// the window moves on. It is safe for concurrent use.
+type RetryBudget struct {
+	max    int
+	window time.Duration
+
+	mu    sync.Mutex
+	hosts map[string][]time.Time
+}
+
+// NewRetryBudget allows up to max retries to each host per window.
+func NewRetryBudget(max int, window time.Duration) *RetryBudget {
+	return &RetryBudget{max: max, window: window, hosts: make(map[string][]time.Time)}
+}
+
+// take spends one retry for host at now, reporting false if none are left.
+func (b *RetryBudget) take(host string, now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	recent := b.hosts[host]
+	i := 0
+	for i < len(recent) && now.Sub(recent[i]) >= b.window {
+		i++
+	}
+	recent = recent[i:]
+	if len(recent) >= b.max {
+		b.hosts[host] = recent
+		return false
+	}
+	b.hosts[host] = append(recent, now)
+	return true
+}
+
+func hostOf(rawURL string) string {
+	if u, err := url.Parse(rawURL); err == nil {
+		return u.Host
+	}
+	return rawURL
+}
@@ -0,0 +1,114 @@
+package client
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Source is a response that went into a result, for showing where metadata
+// came from.
+type Source struct {
+	URL string `json:"url"`
+
+	// FetchedAt is when the body was fetched from the registry. For a
+	// response served from Cache it is when the cached copy was stored or
+	// last revalidated, not when it was read.
+	FetchedAt time.Time `json:"fetched_at,omitzero"`
+
+	// Fields names the result fields known to come from this response,
+	// such as "Licenses" for a Maven parent POM. It is empty where the
+	// registry doesn't attribute fields.
+	Fields []string `json:"fields,omitempty"`
+}
+
+// SourceLog collects the Sources of the requests made with a context from
+// RecordSources. It is safe for concurrent use.
+type SourceLog struct {
+	mu      sync.Mutex
+	sources []Source
+	index   map[string]int
+}
+
+type sourceLogKey struct{}
+
+// RecordSources returns a context that records every successful GET and
+// POST made with it, and the log they are recorded in. A URL fetched more
+// than once is recorded once, with the first fetch time.
+func RecordSources(ctx context.Context) (context.Context, *SourceLog) {
+	log := &SourceLog{index: make(map[string]int)}
+	return context.WithValue(ctx, sourceLogKey{}, log), log
+}
+
+// NoteFields attributes result fields to the response from url in the log
+// ctx records to, if any. Registries call it where a field comes from a
+// different response than the rest of the result.
+func NoteFields(ctx context.Context, url string, fields ...string) {
+	if log := sourceLogFrom(ctx); log != nil {
+		log.note(url, time.Time{}, fields)
+	}
+}
+
+// NoteSource records a response from url fetched at fetchedAt in the log
+// ctx records to, if any. Registries that keep responses in memory call it
+// when they reuse one, so it is listed like a response from Cache.
+func NoteSource(ctx context.Context, url string, fetchedAt time.Time) {
+	if log := sourceLogFrom(ctx); log != nil {
+		log.note(url, fetchedAt, nil)
+	}
+}
+
+// Sources returns what has been recorded so far, in the order the URLs
+// were first fetched.
+func (l *SourceLog) Sources() []Source {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	sources := make([]Source, len(l.sources))
+	for i, s := range l.sources {
+		s.Fields = slices.Clone(s.Fields)
+		sources[i] = s
+	}
+	return sources
+}
+
+func (l *SourceLog) note(url string, fetchedAt time.Time, fields []string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	i, ok := l.index[url]
+	if !ok {
+		i = len(l.sources)
+		l.index[url] = i
+		l.sources = append(l.sources, Source{URL: url})
+	}
+	s := &l.sources[i]
+	if s.FetchedAt.IsZero() {
+		s.FetchedAt = fetchedAt
+	}
+	for _, f := range fields {
+		if !slices.Contains(s.Fields, f) {
+			s.Fields = append(s.Fields, f)
+		}
+	}
+}
+
+func sourceLogFrom(ctx context.Context) *SourceLog {
+	log, _ := ctx.Value(sourceLogKey{}).(*SourceLog)
+	return log
+}
+
+// recordSource adds a successful request for url to the log ctx records
+// to, if any.
+func (c *Client) recordSource(ctx context.Context, url string) {
+	log := sourceLogFrom(ctx)
+	if log == nil {
+		return
+	}
+	fetchedAt := c.clock().Now()
+	if c.Cache != nil {
+		if cached, _ := c.Cache.Get(c.requestKey(url)); cached != nil {
+			fetchedAt = cached.StoredAt
+		}
+	}
+	log.note(url, fetchedAt, nil)
+}
@@ -0,0 +1,234 @@
+package client
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Stats is a snapshot of the requests a Client or fetch.Fetcher has made,
+// for services that embed the library and expose health or metrics
+// endpoints. See WritePrometheus for the Prometheus text format.
+type Stats struct {
+	// Requests counts responses by HTTP status across all hosts. Status 0
+	// counts requests that got no response, such as refused connections.
+	Requests map[int]int64 `json:"requests"`
+	// Retries counts retries scheduled after failed attempts.
+	Retries int64 `json:"retries"`
+	// Hosts breaks the counts down by host.
+	Hosts map[string]HostStats `json:"hosts"`
+	// OpenCircuits lists the hosts whose circuit breaker is open, for a
+	// fetch.CircuitBreakerFetcher.
+	OpenCircuits []string `json:"open_circuits,omitempty"`
+}
+
+// HostStats is the part of Stats for one host.
+type HostStats struct {
+	Requests map[int]int64 `json:"requests"`
+	Retries  int64         `json:"retries"`
+	// Backoff is how long until the latest retry scheduled for the host is
+	// sent, or zero if none is waiting.
+	Backoff time.Duration `json:"backoff,omitempty"`
+	// RateLimit is what the host's last response said about its rate
+	// limit, or nil if it sent no rate limit headers.
+	RateLimit *RateLimitStatus `json:"rate_limit,omitempty"`
+}
+
+// RateLimitStatus is a host's rate limit as reported by the X-RateLimit-*
+// headers GitHub and others send, or the RateLimit-* headers of the IETF
+// draft.
+type RateLimitStatus struct {
+	Limit     int64     `json:"limit"`     // requests allowed per window, -1 if not sent
+	Remaining int64     `json:"remaining"` // requests left in the window
+	Reset     time.Time `json:"reset,omitzero"`
+	UpdatedAt time.Time `json:"updated_at"` // when the headers were received
+}
+
+// StatsRecorder counts the responses and retries behind Stats. Clients
+// made by DefaultClient and NewClient, and fetchers made by
+// fetch.NewFetcher, have one each; copies made with the With* methods share
+// their original's. Give a client and fetcher the same recorder with
+// WithStatsRecorder and fetch.WithStatsRecorder to report them together.
+// It is safe for concurrent use.
+type StatsRecorder struct {
+	mu    sync.Mutex
+	hosts map[string]*hostRecord
+}
+
+type hostRecord struct {
+	requests  map[int]int64
+	retries   int64
+	retryAt   time.Time
+	rateLimit *RateLimitStatus
+}
+
+// NewStatsRecorder returns a recorder with nothing counted.
+func NewStatsRecorder() *StatsRecorder {
+	return &StatsRecorder{hosts: make(map[string]*hostRecord)}
+}
+
+func (r *StatsRecorder) host(host string) *hostRecord {
+	h := r.hosts[host]
+	if h == nil {
+		h = &hostRecord{requests: make(map[int]int64)}
+		r.hosts[host] = h
+	}
+	return h
+}
+
+// Record counts a response to rawURL with the given status, or 0 if the
+// request got none, and notes any rate limit headers at now.
+func (r *StatsRecorder) Record(rawURL string, status int, header http.Header, now time.Time) {
+	rateLimit := parseRateLimit(header, now)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	h := r.host(hostOf(rawURL))
+	h.requests[status]++
+	if rateLimit != nil {
+		h.rateLimit = rateLimit
+	}
+}
+
+// Observe returns a copy of p that counts its retries, and when each is
+// due, against the host retried.
+func (r *StatsRecorder) Observe(p *RetryPolicy) *RetryPolicy {
+	copy := *p
+	clock := p.clock()
+	onRetry := p.OnRetry
+	copy.OnRetry = func(host string, retry int, delay time.Duration) {
+		at := clock.Now().Add(delay)
+		r.mu.Lock()
+		h := r.host(host)
+		h.retries++
+		if at.After(h.retryAt) {
+			h.retryAt = at
+		}
+		r.mu.Unlock()
+		if onRetry != nil {
+			onRetry(host, retry, delay)
+		}
+	}
+	return &copy
+}
+
+// Snapshot returns the counts so far, with backoffs measured from the
+// system clock.
+func (r *StatsRecorder) Snapshot() Stats {
+	return r.SnapshotAt(SystemClock.Now())
+}
+
+// SnapshotAt returns the counts so far, with backoffs measured from now.
+func (r *StatsRecorder) SnapshotAt(now time.Time) Stats {
+	s := Stats{Requests: make(map[int]int64), Hosts: make(map[string]HostStats)}
+	if r == nil {
+		return s
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for name, h := range r.hosts {
+		hs := HostStats{Requests: make(map[int]int64, len(h.requests)), Retries: h.retries}
+		for status, n := range h.requests {
+			hs.Requests[status] = n
+			s.Requests[status] += n
+		}
+		if h.retryAt.After(now) {
+			hs.Backoff = h.retryAt.Sub(now)
+		}
+		if h.rateLimit != nil {
+			rl := *h.rateLimit
+			hs.RateLimit = &rl
+		}
+		s.Retries += h.retries
+		s.Hosts[name] = hs
+	}
+	return s
+}
+
+// parseRateLimit reads rate limit headers, returning nil if there are
+// none. Reset is seconds from now in the IETF draft and a Unix time
+// elsewhere, so values too large to be a wait are read as times.
+func parseRateLimit(header http.Header, now time.Time) *RateLimitStatus {
+	if header == nil {
+		return nil
+	}
+	get := func(name string) string {
+		if v := header.Get("X-RateLimit-" + name); v != "" {
+			return v
+		}
+		return header.Get("RateLimit-" + name)
+	}
+	remaining, err := strconv.ParseInt(strings.TrimSpace(get("Remaining")), 10, 64)
+	if err != nil {
+		return nil
+	}
+	rl := &RateLimitStatus{Limit: -1, Remaining: remaining, UpdatedAt: now}
+	if limit, err := strconv.ParseInt(strings.TrimSpace(get("Limit")), 10, 64); err == nil {
+		rl.Limit = limit
+	}
+	if reset, err := strconv.ParseInt(strings.TrimSpace(get("Reset")), 10, 64); err == nil && reset >= 0 {
+		if reset > 1_000_000_000 {
+			rl.Reset = time.Unix(reset, 0).UTC()
+		} else {
+			rl.Reset = now.Add(time.Duration(reset) * time.Second)
+		}
+	}
+	return rl
+}
+
+// WritePrometheus writes the stats in the Prometheus text exposition
+// format, with metric names starting registries_, for serving from a
+// /metrics endpoint.
+func (s Stats) WritePrometheus(w io.Writer) error {
+	hosts := make([]string, 0, len(s.Hosts))
+	for host := range s.Hosts {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+
+	var b strings.Builder
+	metric := func(name, kind, help string, each func(host string, h HostStats)) {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+		for _, host := range hosts {
+			each(host, s.Hosts[host])
+		}
+	}
+	metric("registries_http_responses_total", "counter", "Responses received, by host and HTTP status (0 for no response).", func(host string, h HostStats) {
+		statuses := make([]int, 0, len(h.Requests))
+		for status := range h.Requests {
+			statuses = append(statuses, status)
+		}
+		sort.Ints(statuses)
+		for _, status := range statuses {
+			fmt.Fprintf(&b, "registries_http_responses_total{host=%q,status=\"%d\"} %d\n", host, status, h.Requests[status])
+		}
+	})
+	metric("registries_http_retries_total", "counter", "Retries scheduled after failed attempts, by host.", func(host string, h HostStats) {
+		fmt.Fprintf(&b, "registries_http_retries_total{host=%q} %d\n", host, h.Retries)
+	})
+	metric("registries_http_backoff_seconds", "gauge", "Time until the latest scheduled retry to a host is sent.", func(host string, h HostStats) {
+		fmt.Fprintf(&b, "registries_http_backoff_seconds{host=%q} %g\n", host, h.Backoff.Seconds())
+	})
+	metric("registries_rate_limit_remaining", "gauge", "Requests left in the host's rate limit window, from its last response.", func(host string, h HostStats) {
+		if h.RateLimit != nil {
+			fmt.Fprintf(&b, "registries_rate_limit_remaining{host=%q} %d\n", host, h.RateLimit.Remaining)
+		}
+	})
+	metric("registries_rate_limit_limit", "gauge", "Requests allowed in the host's rate limit window, from its last response.", func(host string, h HostStats) {
+		if h.RateLimit != nil && h.RateLimit.Limit >= 0 {
+			fmt.Fprintf(&b, "registries_rate_limit_limit{host=%q} %d\n", host, h.RateLimit.Limit)
+		}
+	})
+	if s.OpenCircuits != nil {
+		fmt.Fprintf(&b, "# HELP registries_circuit_open Whether the circuit breaker for a host is open.\n# TYPE registries_circuit_open gauge\n")
+		for _, host := range s.OpenCircuits {
+			fmt.Fprintf(&b, "registries_circuit_open{host=%q} 1\n", host)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,168 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/rs/dnscache"
+)
+
+// The options in this file tune the client's HTTP transport. Bulk workloads
+// that send thousands of requests to one registry host are otherwise limited
+// by Go's default pool, which keeps only two idle connections per host. They
+// have no effect on a client whose HTTPClient uses a custom RoundTripper.
+
+// WithMaxIdleConnsPerHost sets how many idle connections are kept open to
+// each host for reuse. It should be at least the number of requests made to
+// one host concurrently, so connections aren't closed and redialed.
+func WithMaxIdleConnsPerHost(n int) Option {
+	return func(c *Client) {
+		t := c.transport()
+		if t == nil {
+			return
+		}
+		t.MaxIdleConnsPerHost = n
+		if t.MaxIdleConns != 0 && t.MaxIdleConns < n {
+			t.MaxIdleConns = n
+		}
+	}
+}
+
+// WithHTTP2 enables or disables HTTP/2 for TLS connections. It is enabled by
+// default; with it, concurrent requests to a host share one connection.
+// Some registry proxies behave better over HTTP/1.1.
+func WithHTTP2(enabled bool) Option {
+	return func(c *Client) {
+		t := c.transport()
+		if t == nil {
+			return
+		}
+		var protocols http.Protocols
+		protocols.SetHTTP1(true)
+		protocols.SetHTTP2(enabled)
+		t.Protocols = &protocols
+		t.ForceAttemptHTTP2 = enabled
+	}
+}
+
+// WithDialTimeout sets how long establishing a TCP connection may take,
+// separately from the timeout for the whole request.
+func WithDialTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		t := c.transport()
+		if t == nil {
+			return
+		}
+		c.dialer().Timeout = d
+		t.DialContext = c.dialContext()
+	}
+}
+
+// WithDNSCache caches DNS lookups, refreshing them every refresh interval,
+// so bulk workloads don't resolve the registry host for every connection.
+func WithDNSCache(refresh time.Duration) Option {
+	return func(c *Client) {
+		t := c.transport()
+		if t == nil {
+			return
+		}
+		c.dial.dns = &dnsCache{refresh: refresh}
+		t.DialContext = c.dialContext()
+	}
+}
+
+// dialSettings holds the dialer the transport options build on, so that
+// they can be applied in any order.
+type dialSettings struct {
+	dialer *net.Dialer
+	dns    *dnsCache
+}
+
+// transport returns the client's *http.Transport, replacing a nil transport
+// with a copy of http.DefaultTransport so that tuning it doesn't affect
+// other clients. It returns nil for a custom RoundTripper.
+func (c *Client) transport() *http.Transport {
+	if c.HTTPClient == nil {
+		c.HTTPClient = &http.Client{}
+	}
+	switch t := c.HTTPClient.Transport.(type) {
+	case nil:
+		clone := http.DefaultTransport.(*http.Transport).Clone()
+		c.HTTPClient.Transport = clone
+		return clone
+	case *http.Transport:
+		return t
+	default:
+		return nil
+	}
+}
+
+func (c *Client) dialer() *net.Dialer {
+	if c.dial == nil {
+		c.dial = &dialSettings{}
+	}
+	if c.dial.dialer == nil {
+		// Matches the dialer behind http.DefaultTransport
+		c.dial.dialer = &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	}
+	return c.dial.dialer
+}
+
+func (c *Client) dialContext() func(ctx context.Context, network, addr string) (net.Conn, error) {
+	dialer := c.dialer()
+	dns := c.dial.dns
+	if dns == nil {
+		return dialer.DialContext
+	}
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+		ips, err := dns.lookup(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		var lastErr error
+		for _, ip := range ips {
+			conn, err := dialer.DialContext(ctx, network, net.JoinHostPort(ip, port))
+			if err == nil {
+				return conn, nil
+			}
+			lastErr = err
+		}
+		if lastErr == nil {
+			lastErr = fmt.Errorf("no addresses for %s", host)
+		}
+		return nil, lastErr
+	}
+}
+
+// dnsCache refreshes its entries when a lookup finds them older than the
+// refresh interval, rather than from a background goroutine that would
+// outlive the client.
+type dnsCache struct {
+	resolver dnscache.Resolver
+	refresh  time.Duration
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+func (d *dnsCache) lookup(ctx context.Context, host string) ([]string, error) {
+	d.mu.Lock()
+	now := time.Now()
+	if d.next.IsZero() {
+		d.next = now.Add(d.refresh)
+	} else if now.After(d.next) {
+		d.next = now.Add(d.refresh)
+		go d.resolver.Refresh(true)
+	}
+	d.mu.Unlock()
+
+	return d.resolver.LookupHost(ctx, host)
+}
@@ -0,0 +1,26 @@
+// Package clojars constructs clients for Clojars.
+//
+//	reg, err := clojars.New("", nil) // https://clojars.org
+//	pkg, err := reg.FetchPackage(ctx, name)
+package clojars
+
+import (
+	"github.com/git-pkgs/registries/client"
+	impl "github.com/git-pkgs/registries/internal/clojars"
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+// DefaultURL is the registry New uses when given no base URL.
+const DefaultURL = impl.DefaultURL
+
+// Registry is the client New returns. It implements registries.Registry and
+// the optional interfaces the ecosystem supports.
+type Registry = impl.Registry
+
+// New returns a client for the registry at baseURL, or DefaultURL if
+// baseURL is empty. A nil client uses client.DefaultClient. A baseURL that
+// fails registries.ValidateURL returns an error wrapping
+// registries.ErrInvalidURL.
+func New(baseURL string, c *client.Client) (*Registry, error) {
+	return core.Construct("clojars", baseURL, c, impl.New)
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/git-pkgs/registries"
+	"github.com/git-pkgs/vers"
+)
+
+// node is one package in a dependency tree.
+type node struct {
+	Name         string           `json:"name"`
+	Version      string           `json:"version,omitempty"`
+	Requirements string           `json:"requirements,omitempty"`
+	Scope        registries.Scope `json:"scope,omitempty"`
+	Error        string           `json:"error,omitempty"`
+	Cycle        bool             `json:"cycle,omitempty"`
+	Dependencies []*node          `json:"dependencies,omitempty"`
+}
+
+func cmdDeps(ctx context.Context, r *resolver, purl string, stdout, stderr io.Writer, opts options) error {
+	reg, name, version, err := r.lookup(purl)
+	if err != nil {
+		return err
+	}
+
+	if version == "" {
+		latest, err := registries.FetchLatestVersion(ctx, reg, name)
+		if err != nil {
+			return err
+		}
+		if latest == nil {
+			return fmt.Errorf("%s has no published versions", name)
+		}
+		version = latest.Number
+	}
+
+	deps, err := registries.FetchDependencies(ctx, reg, name, version)
+	if err != nil {
+		return err
+	}
+	deps = filterScopes(deps, opts.all)
+
+	if !opts.tree {
+		return write(stdout, stderr, opts, deps, func(w *tabwriter.Writer) {
+			_, _ = fmt.Fprintln(w, "NAME\tREQUIREMENTS\tSCOPE\tTARGET")
+			for _, d := range deps {
+				scope := string(d.Scope)
+				if d.Relation != "" {
+					scope = string(d.Relation)
+				}
+				_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", d.Name, d.Requirements, scope, d.Target)
+			}
+		})
+	}
+
+	t := &treeBuilder{reg: reg, all: opts.all, maxDepth: opts.depth}
+	root := &node{Name: name, Version: version}
+	root.Dependencies = t.children(ctx, deps, 1, map[string]bool{name + "@" + version: true})
+
+	err = write(stdout, stderr, opts, root, func(w *tabwriter.Writer) {
+		printTree(w, root, "")
+	})
+	if err == nil && t.truncated {
+		// The tree printed so far is still useful; say why it stops.
+		err = &registries.BudgetExceededError{
+			Op:        "dependency tree",
+			Completed: t.resolved,
+			Planned:   t.seen,
+			Partial:   root,
+			Err:       ctx.Err(),
+		}
+	}
+	return err
+}
+
+func filterScopes(deps []registries.Dependency, all bool) []registries.Dependency {
+	if all {
+		return deps
+	}
+	filtered := deps[:0:0]
+	for _, d := range deps {
+		if d.Relation != "" {
+			continue
+		}
+		if d.Scope == registries.Runtime || d.Scope == "" {
+			if !d.Optional {
+				filtered = append(filtered, d)
+			}
+		}
+	}
+	return filtered
+}
+
+// treeBuilder resolves transitive dependencies within one registry, picking
+// the newest non-yanked version that satisfies each requirement. Once the
+// context's deadline passes it stops expanding the tree, rather than marking
+// every remaining node with the same timeout error, and sets truncated.
+type treeBuilder struct {
+	reg      registries.Registry
+	all      bool
+	maxDepth int
+
+	seen      int
+	resolved  int
+	truncated bool
+}
+
+func (t *treeBuilder) children(ctx context.Context, deps []registries.Dependency, depth int, path map[string]bool) []*node {
+	nodes := make([]*node, 0, len(deps))
+	for _, d := range deps {
+		if ctx.Err() != nil {
+			t.truncated = true
+			return nodes
+		}
+		// Conflicts and the like aren't installed, so there is nothing
+		// to resolve
+		if d.Relation != "" {
+			continue
+		}
+		t.seen++
+
+		n := &node{Name: d.Name, Requirements: d.Requirements, Scope: d.Scope}
+		nodes = append(nodes, n)
+
+		version, err := t.resolve(ctx, d.Name, d.Requirements)
+		if err != nil {
+			if ctx.Err() != nil {
+				t.truncated = true
+				return nodes
+			}
+			n.Error = err.Error()
+			continue
+		}
+		n.Version = version
+		t.resolved++
+
+		key := d.Name + "@" + version
+		if path[key] {
+			n.Cycle = true
+			continue
+		}
+		if depth >= t.maxDepth {
+			continue
+		}
+
+		childDeps, err := registries.FetchDependencies(ctx, t.reg, d.Name, version)
+		if err != nil {
+			if ctx.Err() != nil {
+				t.truncated = true
+				return nodes
+			}
+			n.Error = err.Error()
+			continue
+		}
+		path[key] = true
+		n.Dependencies = t.children(ctx, filterScopes(childDeps, t.all), depth+1, path)
+		delete(path, key)
+	}
+	return nodes
+}
+
+func (t *treeBuilder) resolve(ctx context.Context, name, requirements string) (string, error) {
+	versions, err := registries.FetchVersions(ctx, t.reg, name)
+	if err != nil {
+		return "", err
+	}
+
+	scheme := t.reg.Ecosystem()
+	requirements = strings.TrimSpace(requirements)
+	constrained := requirements != "" && requirements != "*"
+
+	best := ""
+	for _, v := range versions {
+		if v.Status != registries.StatusNone {
+			continue
+		}
+		if constrained {
+			// Unparseable ranges fall back to the newest version
+			if ok, err := vers.Satisfies(v.Number, requirements, scheme); err == nil && !ok {
+				continue
+			}
+		}
+		if best == "" || vers.Compare(v.Number, best) > 0 {
+			best = v.Number
+		}
+	}
+
+	if best == "" {
+		return "", fmt.Errorf("no version of %s satisfies %q", name, requirements)
+	}
+	return best, nil
+}
+
+func printTree(w io.Writer, n *node, indent string) {
+	line := n.Name
+	if n.Version != "" {
+		line += "@" + n.Version
+	}
+	if n.Requirements != "" && indent != "" {
+		line += " (" + n.Requirements + ")"
+	}
+	if n.Cycle {
+		line += " [cycle]"
+	}
+	if n.Error != "" {
+		line += " [error: " + n.Error + "]"
+	}
+	_, _ = fmt.Fprintln(w, indent+line)
+	for _, child := range n.Dependencies {
+		printTree(w, child, indent+"  ")
+	}
+}
@@ -0,0 +1,414 @@
+// Command registries queries package registries from the command line.
+//
+// Usage:
+//
+//	registries info pkg:npm/react
+//	registries versions pkg:cargo/serde
+//	registries deps pkg:pypi/requests@2.31.0
+//	registries deps --tree --depth 2 pkg:pypi/requests@2.31.0 --json
+//	registries maintainers pkg:gem/rails
+//	registries urls pkg:npm/react@18.2.0
+//	registries readme pkg:pypi/requests
+//	registries changelog pkg:gem/rails@7.1.0
+//	registries status pkg:npm/request
+//
+// Output is a table by default, or JSON with --json. Use --config to load
+// registry URLs and credentials from a config file (see package config).
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/git-pkgs/registries"
+	_ "github.com/git-pkgs/registries/all"
+	"github.com/git-pkgs/registries/client"
+	"github.com/git-pkgs/registries/config"
+)
+
+const usage = `Usage: registries <command> [flags] <purl>
+
+Commands:
+  info         package metadata
+  versions     all published versions
+  deps         dependencies of a version (latest if the PURL has none)
+  maintainers  package maintainers
+  urls         registry, download, documentation and PURL URLs
+  readme       README of a version (latest if the PURL has none)
+  changelog    release notes of a version (latest if the PURL has none)
+  status       whether the package is deprecated, yanked, removed or archived
+  ecosystems   list supported ecosystems
+
+Flags:
+  --json          print JSON instead of a table
+  --timeout DUR   request timeout (default 30s)
+  --config FILE   registry configuration file
+  --tree          (deps) resolve transitive dependencies
+  --depth N       (deps --tree) maximum depth (default 3)
+  --all           (deps) include development, test and optional dependencies
+`
+
+func main() {
+	os.Exit(run(context.Background(), os.Args[1:], os.Stdout, os.Stderr, nil))
+}
+
+type options struct {
+	json    bool
+	timeout time.Duration
+	config  string
+	tree    bool
+	depth   int
+	all     bool
+}
+
+// run executes a command and returns the process exit code. A nil client
+// means one is built from the flags.
+func run(ctx context.Context, args []string, stdout, stderr io.Writer, c *client.Client) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" || args[0] == "help" {
+		_, _ = fmt.Fprint(stderr, usage)
+		return 2
+	}
+	command := args[0]
+
+	var opts options
+	fs := newFlagSet(command, &opts, stderr)
+	positional, err := parseInterspersed(fs, args[1:])
+	if err != nil {
+		return 2
+	}
+
+	if command == "ecosystems" {
+		return output(stdout, stderr, opts, registries.SupportedEcosystems(), func(w *tabwriter.Writer) {
+			for _, e := range registries.SupportedEcosystems() {
+				_, _ = fmt.Fprintln(w, e)
+			}
+		})
+	}
+
+	if len(positional) != 1 {
+		_, _ = fmt.Fprintf(stderr, "registries %s: expected exactly one PURL\n", command)
+		return 2
+	}
+
+	r, err := newResolver(opts, c)
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "registries: %v\n", err)
+		return 1
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, opts.timeout)
+	defer cancel()
+
+	switch command {
+	case "info":
+		err = cmdInfo(ctx, r, positional[0], stdout, stderr, opts)
+	case "versions":
+		err = cmdVersions(ctx, r, positional[0], stdout, stderr, opts)
+	case "deps":
+		err = cmdDeps(ctx, r, positional[0], stdout, stderr, opts)
+	case "maintainers":
+		err = cmdMaintainers(ctx, r, positional[0], stdout, stderr, opts)
+	case "urls":
+		err = cmdURLs(r, positional[0], stdout, stderr, opts)
+	case "readme":
+		err = cmdReadme(ctx, r, positional[0], stdout, stderr, opts)
+	case "changelog":
+		err = cmdChangelog(ctx, r, positional[0], stdout, stderr, opts)
+	case "status":
+		err = cmdStatus(ctx, r, positional[0], stdout, stderr, opts)
+	default:
+		_, _ = fmt.Fprintf(stderr, "registries: unknown command %q\n\n%s", command, usage)
+		return 2
+	}
+
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "registries %s: %v\n", command, err)
+		if errors.Is(err, registries.ErrNotFound) {
+			return 3
+		}
+		return 1
+	}
+	return 0
+}
+
+func newFlagSet(command string, opts *options, stderr io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet(command, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() { _, _ = fmt.Fprint(stderr, usage) }
+	fs.BoolVar(&opts.json, "json", false, "")
+	fs.DurationVar(&opts.timeout, "timeout", 30*time.Second, "")
+	fs.StringVar(&opts.config, "config", "", "")
+	fs.BoolVar(&opts.tree, "tree", false, "")
+	fs.IntVar(&opts.depth, "depth", 3, "")
+	fs.BoolVar(&opts.all, "all", false, "")
+	return fs
+}
+
+// parseInterspersed parses flags appearing before or after positional
+// arguments, so `deps pkg:npm/react --json` works as users expect.
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// resolver turns PURLs into registry clients, honouring --config.
+type resolver struct {
+	client *client.Client
+	set    *config.Set
+}
+
+func newResolver(opts options, c *client.Client) (*resolver, error) {
+	if c == nil {
+		c = client.DefaultClient().WithUserAgent("registries-cli")
+		c.HTTPClient.Timeout = opts.timeout
+	}
+	r := &resolver{client: c}
+
+	if opts.config != "" {
+		cfg, err := config.Load(opts.config)
+		if err != nil {
+			return nil, err
+		}
+		if r.set, err = config.NewSet(cfg, c); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// lookup returns the registry, package name and version for a PURL.
+func (r *resolver) lookup(purl string) (registries.Registry, string, string, error) {
+	p, err := registries.ParsePURL(purl)
+	if err != nil {
+		return nil, "", "", err
+	}
+	if r.set != nil && p.RepositoryURL() == "" {
+		reg, err := r.set.Get(p.Type)
+		return reg, p.FullName(), p.Version, err
+	}
+	return registries.NewFromPURL(purl, r.client)
+}
+
+func (r *resolver) registry(ecosystem string) (registries.Registry, error) {
+	if r.set != nil {
+		return r.set.Get(ecosystem)
+	}
+	return registries.New(ecosystem, "", r.client)
+}
+
+// output writes v as JSON, or calls table with a tabwriter.
+func output(stdout, stderr io.Writer, opts options, v any, table func(w *tabwriter.Writer)) int {
+	if opts.json {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(v); err != nil {
+			_, _ = fmt.Fprintf(stderr, "registries: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+	w := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+	table(w)
+	_ = w.Flush()
+	return 0
+}
+
+func write(stdout, stderr io.Writer, opts options, v any, table func(w *tabwriter.Writer)) error {
+	if output(stdout, stderr, opts, v, table) != 0 {
+		return errors.New("writing output failed")
+	}
+	return nil
+}
+
+func cmdInfo(ctx context.Context, r *resolver, purl string, stdout, stderr io.Writer, opts options) error {
+	reg, name, _, err := r.lookup(purl)
+	if err != nil {
+		return err
+	}
+	pkg, err := reg.FetchPackage(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	return write(stdout, stderr, opts, pkg, func(w *tabwriter.Writer) {
+		rows := [][2]string{
+			{"Name", pkg.Name},
+			{"Namespace", pkg.Namespace},
+			{"Description", pkg.Description},
+			{"Latest", pkg.LatestVersion},
+			{"Licenses", pkg.Licenses},
+			{"Homepage", pkg.Homepage},
+			{"Repository", pkg.Repository},
+			{"Keywords", strings.Join(pkg.Keywords, ", ")},
+			{"Categories", strings.Join(pkg.Categories, ", ")},
+			{"Created", formatDate(pkg.CreatedAt)},
+			{"Updated", formatDate(pkg.UpdatedAt)},
+			{"Last release", formatDate(pkg.LatestReleasedAt)},
+		}
+		for _, row := range rows {
+			if row[1] != "" {
+				_, _ = fmt.Fprintf(w, "%s:\t%s\n", row[0], row[1])
+			}
+		}
+	})
+}
+
+// formatDate returns t as a date, or "" for the zero time.
+func formatDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format("2006-01-02")
+}
+
+func cmdVersions(ctx context.Context, r *resolver, purl string, stdout, stderr io.Writer, opts options) error {
+	reg, name, _, err := r.lookup(purl)
+	if err != nil {
+		return err
+	}
+	versions, err := registries.FetchVersions(ctx, reg, name)
+	if err != nil {
+		return err
+	}
+
+	return write(stdout, stderr, opts, versions, func(w *tabwriter.Writer) {
+		_, _ = fmt.Fprintln(w, "VERSION\tPUBLISHED\tSTATUS\tLICENSES")
+		for _, v := range versions {
+			published := ""
+			if !v.PublishedAt.IsZero() {
+				published = v.PublishedAt.Format("2006-01-02")
+			}
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", v.Number, published, v.Status, v.Licenses)
+		}
+	})
+}
+
+func cmdMaintainers(ctx context.Context, r *resolver, purl string, stdout, stderr io.Writer, opts options) error {
+	reg, name, _, err := r.lookup(purl)
+	if err != nil {
+		return err
+	}
+	maintainers, err := registries.FetchMaintainers(ctx, reg, name)
+	if err != nil {
+		return err
+	}
+
+	return write(stdout, stderr, opts, maintainers, func(w *tabwriter.Writer) {
+		_, _ = fmt.Fprintln(w, "LOGIN\tNAME\tEMAIL\tROLE")
+		for _, m := range maintainers {
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", m.Login, m.Name, m.Email, m.Role)
+		}
+	})
+}
+
+func cmdURLs(r *resolver, purl string, stdout, stderr io.Writer, opts options) error {
+	reg, name, version, err := r.lookup(purl)
+	if err != nil {
+		return err
+	}
+	urls := registries.BuildURLs(reg.URLs(), name, version)
+
+	return write(stdout, stderr, opts, urls, func(w *tabwriter.Writer) {
+		for _, key := range []string{"registry", "download", "docs", "purl"} {
+			if u, ok := urls[key]; ok {
+				_, _ = fmt.Fprintf(w, "%s:\t%s\n", key, u)
+			}
+		}
+	})
+}
+
+func cmdReadme(ctx context.Context, r *resolver, purl string, stdout, stderr io.Writer, opts options) error {
+	reg, name, version, err := r.lookup(purl)
+	if err != nil {
+		return err
+	}
+	doc, err := registries.FetchReadme(ctx, reg, name, version)
+	if err != nil {
+		return err
+	}
+
+	if opts.json {
+		return write(stdout, stderr, opts, doc, nil)
+	}
+	// Print the document as-is; a table would mangle its tabs
+	_, err = fmt.Fprintln(stdout, strings.TrimRight(doc.Content, "\n"))
+	return err
+}
+
+// cmdChangelog prints a version's release notes, reading them from the
+// package's repository when the registry has none of its own.
+func cmdChangelog(ctx context.Context, r *resolver, purl string, stdout, stderr io.Writer, opts options) error {
+	reg, name, version, err := r.lookup(purl)
+	if err != nil {
+		return err
+	}
+	doc, err := registries.FetchChangelog(ctx, reg, name, version)
+	if errors.Is(err, registries.ErrNotSupported) {
+		pkg, perr := reg.FetchPackage(ctx, name)
+		if perr != nil {
+			return perr
+		}
+		doc, err = registries.RepositoryChangelog(ctx, r.client, pkg.Repository, version)
+	}
+	if err != nil {
+		return err
+	}
+
+	if opts.json {
+		return write(stdout, stderr, opts, doc, nil)
+	}
+	_, err = fmt.Fprintln(stdout, strings.TrimRight(doc.Content, "\n"))
+	return err
+}
+
+func cmdStatus(ctx context.Context, r *resolver, purl string, stdout, stderr io.Writer, opts options) error {
+	reg, name, _, err := r.lookup(purl)
+	if err != nil {
+		return err
+	}
+	status, err := registries.FetchStatus(ctx, reg, name)
+	if err != nil {
+		return err
+	}
+	if status.Repository != "" {
+		status.Archived, _ = registries.RepositoryArchived(ctx, r.client, status.Repository)
+	}
+
+	return write(stdout, stderr, opts, status, func(w *tabwriter.Writer) {
+		archived := ""
+		if status.Archived {
+			archived = "yes"
+		}
+		rows := [][2]string{
+			{"State", string(status.State)},
+			{"Message", status.Message},
+			{"Replacement", status.Replacement},
+			{"Latest", status.LatestVersion},
+			{"Repository", status.Repository},
+			{"Archived", archived},
+		}
+		for _, row := range rows {
+			if row[1] != "" {
+				_, _ = fmt.Fprintf(w, "%s:\t%s\n", row[0], row[1])
+			}
+		}
+	})
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/git-pkgs/registries"
+	"github.com/git-pkgs/registries/registrytest"
+)
+
+func runCLI(t *testing.T, ecosystem string, args ...string) (int, string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), args, &stdout, &stderr, registrytest.NewClient(t, ecosystem))
+	return code, stdout.String(), stderr.String()
+}
+
+func TestInfo(t *testing.T) {
+	code, out, errOut := runCLI(t, "npm", "info", "pkg:npm/lodash")
+	if code != 0 {
+		t.Fatalf("exit %d: %s", code, errOut)
+	}
+	if !strings.Contains(out, "Name:") || !strings.Contains(out, "lodash") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+}
+
+func TestVersionsJSON(t *testing.T) {
+	code, out, errOut := runCLI(t, "cargo", "versions", "pkg:cargo/serde", "--json")
+	if code != 0 {
+		t.Fatalf("exit %d: %s", code, errOut)
+	}
+
+	var versions []map[string]any
+	if err := json.Unmarshal([]byte(out), &versions); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if len(versions) == 0 {
+		t.Error("expected at least one version")
+	}
+}
+
+func TestDeps(t *testing.T) {
+	code, out, errOut := runCLI(t, "pypi", "deps", "pkg:pypi/requests@2.31.0")
+	if code != 0 {
+		t.Fatalf("exit %d: %s", code, errOut)
+	}
+	if !strings.HasPrefix(out, "NAME") || !strings.Contains(out, "urllib3") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+	if strings.Contains(out, "PySocks") {
+		t.Errorf("optional dependencies should be hidden without --all:\n%s", out)
+	}
+}
+
+func TestDepsTree(t *testing.T) {
+	code, out, errOut := runCLI(t, "pypi", "deps", "--tree", "pkg:pypi/requests@2.31.0", "--json")
+	if code != 0 {
+		t.Fatalf("exit %d: %s", code, errOut)
+	}
+
+	var root node
+	if err := json.Unmarshal([]byte(out), &root); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if root.Name != "requests" || root.Version != "2.31.0" {
+		t.Errorf("unexpected root %s@%s", root.Name, root.Version)
+	}
+	if len(root.Dependencies) == 0 {
+		t.Fatal("expected dependencies")
+	}
+	// The fixture doesn't include transitive packages, which is reported per node
+	for _, child := range root.Dependencies {
+		if child.Error == "" && child.Version == "" {
+			t.Errorf("child %s has neither a version nor an error", child.Name)
+		}
+	}
+}
+
+func TestExitCodes(t *testing.T) {
+	if code, _, _ := runCLI(t, "npm", "frobnicate", "pkg:npm/lodash"); code != 2 {
+		t.Errorf("unknown command: exit %d, want 2", code)
+	}
+	if code, _, _ := runCLI(t, "npm", "info"); code != 2 {
+		t.Errorf("missing PURL: exit %d, want 2", code)
+	}
+	if code, _, _ := runCLI(t, "npm", "info", "pkg:npm/not-recorded"); code != 3 {
+		t.Errorf("not found: exit %d, want 3", code)
+	}
+}
+
+func TestParseInterspersed(t *testing.T) {
+	var opts options
+	fs := newFlagSet("deps", &opts, io.Discard)
+	positional, err := parseInterspersed(fs, []string{"--tree", "pkg:npm/react", "--json", "--depth", "2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(positional) != 1 || positional[0] != "pkg:npm/react" {
+		t.Errorf("unexpected positional args %v", positional)
+	}
+	if !opts.tree || !opts.json || opts.depth != 2 {
+		t.Errorf("flags not parsed: %+v", opts)
+	}
+}
+
+func TestReadmeErrors(t *testing.T) {
+	// The recorded release has no long description
+	code, _, errOut := runCLI(t, "pypi", "readme", "pkg:pypi/requests@2.31.0")
+	if code != 3 {
+		t.Errorf("expected exit 3 for missing readme, got %d: %s", code, errOut)
+	}
+
+	code, _, errOut = runCLI(t, "gem", "readme", "pkg:gem/rails")
+	if code != 1 || !strings.Contains(errOut, "not supported") {
+		t.Errorf("expected unsupported error, got %d: %s", code, errOut)
+	}
+}
+
+func TestStatus(t *testing.T) {
+	code, out, errOut := runCLI(t, "cargo", "status", "pkg:cargo/serde", "--json")
+	if code != 0 {
+		t.Fatalf("exit %d: %s", code, errOut)
+	}
+	var status map[string]any
+	if err := json.Unmarshal([]byte(out), &status); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if status["State"] != "active" {
+		t.Errorf("unexpected status: %v", status)
+	}
+}
+
+func TestDepsTreeDeadline(t *testing.T) {
+	reg, err := registries.New("pypi", "", registrytest.NewClient(t, "pypi"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tb := &treeBuilder{reg: reg, maxDepth: 3}
+	nodes := tb.children(ctx, []registries.Dependency{{Name: "urllib3"}, {Name: "idna"}}, 1, map[string]bool{})
+	if len(nodes) != 0 {
+		t.Errorf("expected no nodes after the deadline, got %d", len(nodes))
+	}
+	if !tb.truncated {
+		t.Error("expected the tree to be marked truncated")
+	}
+}
@@ -0,0 +1,26 @@
+// Package cocoapods constructs clients for the CocoaPods trunk.
+//
+//	reg, err := cocoapods.New("", nil) // https://trunk.cocoapods.org
+//	pkg, err := reg.FetchPackage(ctx, name)
+package cocoapods
+
+import (
+	"github.com/git-pkgs/registries/client"
+	impl "github.com/git-pkgs/registries/internal/cocoapods"
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+// DefaultURL is the registry New uses when given no base URL.
+const DefaultURL = impl.DefaultURL
+
+// Registry is the client New returns. It implements registries.Registry and
+// the optional interfaces the ecosystem supports.
+type Registry = impl.Registry
+
+// New returns a client for the registry at baseURL, or DefaultURL if
+// baseURL is empty. A nil client uses client.DefaultClient. A baseURL that
+// fails registries.ValidateURL returns an error wrapping
+// registries.ErrInvalidURL.
+func New(baseURL string, c *client.Client) (*Registry, error) {
+	return core.Construct("cocoapods", baseURL, c, impl.New)
+}
@@ -0,0 +1,188 @@
+// Package composite combines registries into one that looks packages up in
+// order, such as a private mirror before the public registry.
+//
+//	mirror, _ := npm.New("https://npm.corp.example.com", c)
+//	public, _ := npm.New(npm.DefaultURL, c)
+//	reg := composite.New(mirror, public)
+//	pkg, err := reg.FetchPackage(ctx, "@acme/widgets")
+//
+// A lookup moves on to the next registry only when a registry doesn't have
+// the package or doesn't support the call. Any other error is returned as
+// is, so an unreachable mirror doesn't quietly send names to the public
+// registry. By default every answer comes from the first registry that has
+// one; WithStrategy merges versions across registries instead.
+package composite
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/git-pkgs/registries"
+)
+
+// Strategy decides how answers from several registries are combined.
+type Strategy int
+
+const (
+	// FirstFound takes each answer from the first registry that has the
+	// package, without asking the others.
+	FirstFound Strategy = iota
+
+	// PreferFirst lists the versions of every registry that has the
+	// package. A version in more than one is taken from the earliest, as
+	// are its dependencies.
+	PreferFirst
+
+	// PreferLast lists the versions of every registry that has the
+	// package, like PreferFirst, but a version in more than one is taken
+	// from the latest. Use it to trust the public registry's metadata over
+	// a mirror's copy while still seeing versions only the mirror has.
+	PreferLast
+)
+
+// Registry looks packages up in a list of registries. It reports the
+// ecosystem and URLs of the first.
+type Registry struct {
+	registries []registries.Registry
+	strategy   Strategy
+}
+
+// New returns a Registry that asks primary first and then each fallback in
+// turn.
+func New(primary registries.Registry, fallbacks ...registries.Registry) *Registry {
+	return &Registry{registries: append([]registries.Registry{primary}, fallbacks...)}
+}
+
+// WithStrategy returns a new Registry that combines answers with s.
+func (r *Registry) WithStrategy(s Strategy) *Registry {
+	copy := *r
+	copy.strategy = s
+	return &copy
+}
+
+func (r *Registry) Ecosystem() string {
+	return r.registries[0].Ecosystem()
+}
+
+// URLs returns the primary registry's URLs, whichever registry a package
+// was found in.
+func (r *Registry) URLs() registries.URLBuilder {
+	return r.registries[0].URLs()
+}
+
+// FetchPackage returns the package from the first registry that has it.
+// When merging versions, LatestVersion and LatestStableVersion are the
+// latest of any registry's.
+func (r *Registry) FetchPackage(ctx context.Context, name string) (*registries.Package, error) {
+	found, err := lookup(r, r.strategy != FirstFound, name, "", func(reg registries.Registry) (*registries.Package, error) {
+		return reg.FetchPackage(ctx, name)
+	})
+	if err != nil {
+		return nil, err
+	}
+	pkg := found[0]
+	for _, other := range found[1:] {
+		pkg.LatestVersion = r.later(pkg.LatestVersion, other.LatestVersion)
+		pkg.LatestStableVersion = r.later(pkg.LatestStableVersion, other.LatestStableVersion)
+	}
+	return pkg, nil
+}
+
+// FetchVersions lists the versions of the first registry that has the
+// package, or of every registry when merging, in the order they were
+// first seen.
+func (r *Registry) FetchVersions(ctx context.Context, name string) ([]registries.Version, error) {
+	found, err := lookup(r, r.strategy != FirstFound, name, "", func(reg registries.Registry) ([]registries.Version, error) {
+		return registries.FetchVersions(ctx, reg, name)
+	})
+	if err != nil {
+		return nil, err
+	}
+	versions := found[0]
+	for _, more := range found[1:] {
+		for _, v := range more {
+			if !slices.ContainsFunc(versions, func(w registries.Version) bool { return w.Number == v.Number }) {
+				versions = append(versions, v)
+			}
+		}
+	}
+	return versions, nil
+}
+
+// FetchDependencies returns the dependencies of a version from the first
+// registry that has the version, so they match the version FetchVersions
+// chose.
+func (r *Registry) FetchDependencies(ctx context.Context, name, version string) ([]registries.Dependency, error) {
+	found, err := lookup(r, false, name, version, func(reg registries.Registry) ([]registries.Dependency, error) {
+		return registries.FetchDependencies(ctx, reg, name, version)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return found[0], nil
+}
+
+// FetchMaintainers returns the maintainers from the first registry that
+// lists them.
+func (r *Registry) FetchMaintainers(ctx context.Context, name string) ([]registries.Maintainer, error) {
+	found, err := lookup(r, false, name, "", func(reg registries.Registry) ([]registries.Maintainer, error) {
+		return registries.FetchMaintainers(ctx, reg, name)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return found[0], nil
+}
+
+// ordered returns the registries in the order answers are preferred.
+func (r *Registry) ordered() []registries.Registry {
+	if r.strategy == PreferLast {
+		regs := slices.Clone(r.registries)
+		slices.Reverse(regs)
+		return regs
+	}
+	return r.registries
+}
+
+func (r *Registry) later(a, b string) string {
+	if a == "" || (b != "" && registries.CompareVersions(r.Ecosystem(), b, a) > 0) {
+		return b
+	}
+	return a
+}
+
+// lookup calls fetch with each registry in preference order, skipping
+// those that don't have the package or don't support the call, and
+// returns the answers. It stops at the first answer unless all is set.
+// When no registry answers, the error is NotFoundError if any registry
+// lacked the package and ErrNotSupported otherwise.
+func lookup[T any](r *Registry, all bool, name, version string, fetch func(registries.Registry) (T, error)) ([]T, error) {
+	var answers []T
+	var unsupported error
+	notFound := false
+	for _, reg := range r.ordered() {
+		answer, err := fetch(reg)
+		switch {
+		case errors.Is(err, registries.ErrNotFound):
+			notFound = true
+			continue
+		case errors.Is(err, registries.ErrNotSupported):
+			unsupported = err
+			continue
+		case err != nil:
+			return nil, err
+		}
+		answers = append(answers, answer)
+		if !all {
+			break
+		}
+	}
+	if len(answers) > 0 {
+		return answers, nil
+	}
+	if notFound || unsupported == nil {
+		return nil, &registries.NotFoundError{Ecosystem: r.Ecosystem(), Name: name, Version: version}
+	}
+	return nil, unsupported
+}
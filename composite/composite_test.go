@@ -0,0 +1,192 @@
+package composite
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/git-pkgs/registries"
+	"github.com/git-pkgs/registries/static"
+)
+
+// staticRegistry serves the given package files from a temporary directory.
+func staticRegistry(t *testing.T, files map[string]string) registries.Registry {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	reg, err := static.New(dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return reg
+}
+
+// failing is a registry whose every lookup fails.
+type failing struct{ err error }
+
+func (f failing) Ecosystem() string { return "static" }
+
+func (f failing) FetchPackage(ctx context.Context, name string) (*registries.Package, error) {
+	return nil, f.err
+}
+
+func (f failing) URLs() registries.URLBuilder { return nil }
+
+func newTestRegistries(t *testing.T) (mirror, public registries.Registry) {
+	mirror = staticRegistry(t, map[string]string{
+		"widgets.json": `{"description": "mirror copy", "versions": [
+			{"number": "1.0.0", "licenses": "MIT", "dependencies": [{"name": "mirror-dep"}]},
+			{"number": "1.0.1-internal"}
+		]}`,
+		"internal.json": `{"versions": [{"number": "0.1.0"}]}`,
+	})
+	public = staticRegistry(t, map[string]string{
+		"widgets.json": `{"description": "public", "maintainers": [{"login": "alice"}], "versions": [
+			{"number": "2.0.0"},
+			{"number": "1.0.0", "licenses": "Apache-2.0", "dependencies": [{"name": "public-dep"}]}
+		]}`,
+	})
+	return mirror, public
+}
+
+func numbers(versions []registries.Version) []string {
+	var n []string
+	for _, v := range versions {
+		n = append(n, v.Number)
+	}
+	return n
+}
+
+func TestFirstFound(t *testing.T) {
+	mirror, public := newTestRegistries(t)
+	reg := New(mirror, public)
+	ctx := context.Background()
+
+	pkg, err := reg.FetchPackage(ctx, "widgets")
+	if err != nil {
+		t.Fatalf("FetchPackage failed: %v", err)
+	}
+	if pkg.Description != "mirror copy" || pkg.LatestStableVersion != "1.0.0" {
+		t.Errorf("unexpected package: %+v", pkg)
+	}
+
+	versions, err := reg.FetchVersions(ctx, "widgets")
+	if err != nil {
+		t.Fatalf("FetchVersions failed: %v", err)
+	}
+	if got := numbers(versions); len(got) != 2 || got[0] != "1.0.0" || got[1] != "1.0.1-internal" {
+		t.Errorf("versions = %v", got)
+	}
+
+	// An empty list is still an answer, so the public registry isn't asked.
+	maintainers, err := reg.FetchMaintainers(ctx, "widgets")
+	if err != nil {
+		t.Fatalf("FetchMaintainers failed: %v", err)
+	}
+	if len(maintainers) != 0 {
+		t.Errorf("maintainers = %+v, want the mirror's empty list", maintainers)
+	}
+
+	// Only the public registry has 2.0.0.
+	deps, err := reg.FetchDependencies(ctx, "widgets", "2.0.0")
+	if err != nil {
+		t.Fatalf("FetchDependencies failed: %v", err)
+	}
+	if len(deps) != 0 {
+		t.Errorf("deps = %+v", deps)
+	}
+
+	if _, err := reg.FetchPackage(ctx, "internal"); err != nil {
+		t.Errorf("FetchPackage(internal) failed: %v", err)
+	}
+
+	_, err = reg.FetchPackage(ctx, "missing")
+	var notFound *registries.NotFoundError
+	if !errors.As(err, &notFound) || notFound.Name != "missing" {
+		t.Errorf("err = %v, want NotFoundError", err)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	mirror, public := newTestRegistries(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		strategy Strategy
+		versions []string
+		license  string
+		dep      string
+	}{
+		{PreferFirst, []string{"1.0.0", "1.0.1-internal", "2.0.0"}, "MIT", "mirror-dep"},
+		{PreferLast, []string{"2.0.0", "1.0.0", "1.0.1-internal"}, "Apache-2.0", "public-dep"},
+	}
+	for _, tt := range tests {
+		reg := New(mirror, public).WithStrategy(tt.strategy)
+
+		pkg, err := reg.FetchPackage(ctx, "widgets")
+		if err != nil {
+			t.Fatalf("FetchPackage failed: %v", err)
+		}
+		if pkg.LatestVersion != "2.0.0" || pkg.LatestStableVersion != "2.0.0" {
+			t.Errorf("strategy %d: latest = %q, stable = %q", tt.strategy, pkg.LatestVersion, pkg.LatestStableVersion)
+		}
+
+		versions, err := reg.FetchVersions(ctx, "widgets")
+		if err != nil {
+			t.Fatalf("FetchVersions failed: %v", err)
+		}
+		got := numbers(versions)
+		if len(got) != len(tt.versions) {
+			t.Fatalf("strategy %d: versions = %v, want %v", tt.strategy, got, tt.versions)
+		}
+		for i := range got {
+			if got[i] != tt.versions[i] {
+				t.Errorf("strategy %d: versions = %v, want %v", tt.strategy, got, tt.versions)
+				break
+			}
+		}
+		for _, v := range versions {
+			if v.Number == "1.0.0" && v.Licenses != tt.license {
+				t.Errorf("strategy %d: 1.0.0 licenses = %q, want %q", tt.strategy, v.Licenses, tt.license)
+			}
+		}
+
+		deps, err := reg.FetchDependencies(ctx, "widgets", "1.0.0")
+		if err != nil {
+			t.Fatalf("FetchDependencies failed: %v", err)
+		}
+		if len(deps) != 1 || deps[0].Name != tt.dep {
+			t.Errorf("strategy %d: deps = %+v, want %s", tt.strategy, deps, tt.dep)
+		}
+	}
+}
+
+func TestErrors(t *testing.T) {
+	_, public := newTestRegistries(t)
+	ctx := context.Background()
+
+	// An outage of the primary is reported, not skipped.
+	outage := errors.New("connection refused")
+	if _, err := New(failing{outage}, public).FetchPackage(ctx, "widgets"); !errors.Is(err, outage) {
+		t.Errorf("err = %v, want the primary's error", err)
+	}
+
+	// Registries without a capability are passed over.
+	reg := New(failing{registries.ErrNotFound}, public)
+	versions, err := reg.FetchVersions(ctx, "widgets")
+	if err != nil || len(versions) != 2 {
+		t.Errorf("versions = %v, err = %v", numbers(versions), err)
+	}
+
+	// With no registry supporting the call, ErrNotSupported is returned.
+	reg = New(failing{registries.ErrNotFound})
+	if _, err := reg.FetchVersions(ctx, "widgets"); !errors.Is(err, registries.ErrNotSupported) {
+		t.Errorf("err = %v, want ErrNotSupported", err)
+	}
+}
@@ -0,0 +1,121 @@
+package composite
+
+import (
+	"context"
+	"strings"
+
+	"github.com/git-pkgs/registries"
+)
+
+// Renamed is a registry that knows packages under other names than its
+// callers use, such as Maven Central answering for Clojars coordinates.
+// Names are translated on the way in, and package and dependency names in
+// its answers back to the callers' form.
+type Renamed struct {
+	reg  registries.Registry
+	to   func(name string) string
+	from func(name string) string
+}
+
+// Rename returns reg asked for packages by to(name), with the names in its
+// answers mapped back by from.
+func Rename(reg registries.Registry, to, from func(name string) string) *Renamed {
+	return &Renamed{reg: reg, to: to, from: from}
+}
+
+func (r *Renamed) Ecosystem() string {
+	return r.reg.Ecosystem()
+}
+
+// URLs returns reg's URLs for names in the callers' form.
+func (r *Renamed) URLs() registries.URLBuilder {
+	return renamedURLs{urls: r.reg.URLs(), to: r.to}
+}
+
+func (r *Renamed) FetchPackage(ctx context.Context, name string) (*registries.Package, error) {
+	pkg, err := r.reg.FetchPackage(ctx, r.to(name))
+	if err != nil {
+		return nil, err
+	}
+	pkg.Name = r.from(pkg.Name)
+	return pkg, nil
+}
+
+func (r *Renamed) FetchVersions(ctx context.Context, name string) ([]registries.Version, error) {
+	return registries.FetchVersions(ctx, r.reg, r.to(name))
+}
+
+func (r *Renamed) FetchDependencies(ctx context.Context, name, version string) ([]registries.Dependency, error) {
+	deps, err := registries.FetchDependencies(ctx, r.reg, r.to(name), version)
+	if err != nil {
+		return nil, err
+	}
+	for i := range deps {
+		deps[i].Name = r.from(deps[i].Name)
+	}
+	return deps, nil
+}
+
+func (r *Renamed) FetchMaintainers(ctx context.Context, name string) ([]registries.Maintainer, error) {
+	return registries.FetchMaintainers(ctx, r.reg, r.to(name))
+}
+
+type renamedURLs struct {
+	urls registries.URLBuilder
+	to   func(string) string
+}
+
+func (u renamedURLs) Registry(name, version string) string {
+	return u.urls.Registry(u.to(name), version)
+}
+
+func (u renamedURLs) Download(name, version string) string {
+	return u.urls.Download(u.to(name), version)
+}
+
+func (u renamedURLs) Documentation(name, version string) string {
+	return u.urls.Documentation(u.to(name), version)
+}
+
+func (u renamedURLs) PURL(name, version string) string {
+	return u.urls.PURL(u.to(name), version)
+}
+
+// Clojure returns a Registry for the dependencies of Leiningen and deps.edn
+// projects, which resolve from Clojars and then Maven Central. Names are
+// Clojure coordinates, "group/artifact" or a bare "artifact" whose group is
+// the same, and Central's answers use them too, so the dependencies of any
+// package can be looked up again through the same Registry.
+//
+//	clojars, _ := clojars.New("", c)
+//	central, _ := maven.New("", c)
+//	reg := composite.Clojure(clojars, central)
+//	pkg, err := reg.FetchPackage(ctx, "org.clojure/core.async")
+func Clojure(clojars, central registries.Registry) *Registry {
+	return New(clojars, Rename(central, MavenName, ClojureName))
+}
+
+// MavenName returns the Maven "group:artifact" name of a Clojure
+// coordinate. Names that already have a ":" are returned as they are.
+func MavenName(name string) string {
+	if strings.Contains(name, ":") {
+		return name
+	}
+	if group, artifact, ok := strings.Cut(name, "/"); ok {
+		return group + ":" + artifact
+	}
+	return name + ":" + name
+}
+
+// ClojureName returns the Clojure coordinate of a Maven "group:artifact"
+// name, leaving out the group when it is the same as the artifact.
+func ClojureName(name string) string {
+	group, artifact, ok := strings.Cut(name, ":")
+	if !ok {
+		return name
+	}
+	if group == artifact {
+		return artifact
+	}
+	return group + "/" + artifact
+}
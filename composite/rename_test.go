@@ -0,0 +1,102 @@
+package composite
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/git-pkgs/registries"
+)
+
+// named is a registry holding packages under fixed names, each depending
+// on deps.
+type named struct {
+	ecosystem string
+	packages  map[string][]string
+}
+
+func (n named) Ecosystem() string { return n.ecosystem }
+
+func (n named) URLs() registries.URLBuilder { return nil }
+
+func (n named) FetchPackage(ctx context.Context, name string) (*registries.Package, error) {
+	if _, ok := n.packages[name]; !ok {
+		return nil, &registries.NotFoundError{Ecosystem: n.ecosystem, Name: name}
+	}
+	return &registries.Package{Name: name, Description: n.ecosystem}, nil
+}
+
+func (n named) FetchDependencies(ctx context.Context, name, version string) ([]registries.Dependency, error) {
+	deps, ok := n.packages[name]
+	if !ok {
+		return nil, &registries.NotFoundError{Ecosystem: n.ecosystem, Name: name, Version: version}
+	}
+	var out []registries.Dependency
+	for _, d := range deps {
+		out = append(out, registries.Dependency{Name: d})
+	}
+	return out, nil
+}
+
+func TestClojure(t *testing.T) {
+	clojars := named{"clojars", map[string][]string{
+		"ring/ring-core": {"org.clojure/clojure", "crypto-random"},
+		"crypto-random":  nil,
+	}}
+	central := named{"maven", map[string][]string{
+		"org.clojure:clojure":         {"org.clojure:spec.alpha"},
+		"org.clojure:spec.alpha":      nil,
+		"crypto-random:crypto-random": nil,
+	}}
+	reg := Clojure(clojars, central)
+	ctx := context.Background()
+
+	tests := []struct{ name, from string }{
+		{"ring/ring-core", "clojars"},
+		{"crypto-random", "clojars"},
+		{"org.clojure/clojure", "maven"},
+		{"org.clojure:spec.alpha", "maven"},
+	}
+	for _, tt := range tests {
+		pkg, err := reg.FetchPackage(ctx, tt.name)
+		if err != nil {
+			t.Errorf("FetchPackage(%q) failed: %v", tt.name, err)
+			continue
+		}
+		if pkg.Description != tt.from {
+			t.Errorf("%s came from %s, want %s", tt.name, pkg.Description, tt.from)
+		}
+	}
+
+	pkg, _ := reg.FetchPackage(ctx, "org.clojure/clojure")
+	if pkg.Name != "org.clojure/clojure" {
+		t.Errorf("expected Central's package under its Clojure name, got %q", pkg.Name)
+	}
+	deps, err := reg.FetchDependencies(ctx, "org.clojure/clojure", "1.12.0")
+	if err != nil || len(deps) != 1 || deps[0].Name != "org.clojure/spec.alpha" {
+		t.Errorf("FetchDependencies = %+v, %v", deps, err)
+	}
+
+	var notFound *registries.NotFoundError
+	if _, err := reg.FetchPackage(ctx, "nope/nope"); !errors.As(err, &notFound) {
+		t.Errorf("expected NotFoundError, got %v", err)
+	}
+}
+
+func TestClojureNames(t *testing.T) {
+	for clojure, maven := range map[string]string{
+		"ring/ring-core":      "ring:ring-core",
+		"hiccup":              "hiccup:hiccup",
+		"org.clojure/clojure": "org.clojure:clojure",
+	} {
+		if got := MavenName(clojure); got != maven {
+			t.Errorf("MavenName(%q) = %q, want %q", clojure, got, maven)
+		}
+		if got := ClojureName(maven); got != clojure {
+			t.Errorf("ClojureName(%q) = %q, want %q", maven, got, clojure)
+		}
+	}
+	if got := MavenName("org.clojure:clojure"); got != "org.clojure:clojure" {
+		t.Errorf("MavenName changed a Maven name to %q", got)
+	}
+}
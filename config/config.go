@@ -0,0 +1,315 @@
+// Package config builds registry clients from a declarative configuration
+// file, so services that talk to many ecosystems don't have to wire up base
+// URLs, credentials and rate limits by hand.
+//
+// A configuration looks like:
+//
+//	user_agent: my-service/1.0
+//	timeout: 20s
+//	max_retries: 3
+//	max_body_size: 104857600
+//	cache_dir: /var/cache/registries
+//	registries:
+//	  npm:
+//	    url: https://npm.internal.example.com
+//	    mirrors: [https://registry.npmjs.org]
+//	    auth:
+//	      token: ${NPM_TOKEN}
+//	    rate_limit: 10
+//	    ttl: 5m
+//	    scopes:
+//	      "@myorg":
+//	        url: https://npm.pkg.github.com
+//	        auth:
+//	          token: ${GITHUB_TOKEN}
+//	  pypi:
+//	    rate_limit: 2
+//
+// JSON with the same keys is also accepted. Ecosystems may be given by an
+// alias such as "rubygems", and are stored under the name the alias
+// stands for. Ecosystems must be registered
+// (for example by importing github.com/git-pkgs/registries/all) before a
+// Set is built.
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/git-pkgs/registries"
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the top-level configuration file.
+type Config struct {
+	UserAgent  string              `yaml:"user_agent"`
+	Timeout    Duration            `yaml:"timeout"`
+	MaxRetries *int                `yaml:"max_retries"`
+	Registries map[string]Registry `yaml:"registries"`
+
+	// CacheDir enables an on-disk response cache shared by all registries.
+	CacheDir string `yaml:"cache_dir"`
+
+	// Offline serves every request from CacheDir without network access.
+	Offline bool `yaml:"offline"`
+
+	// DialTimeout bounds establishing a connection and
+	// ResponseHeaderTimeout waiting for a server to start responding,
+	// separately from Timeout for the whole request.
+	DialTimeout           Duration `yaml:"dial_timeout"`
+	ResponseHeaderTimeout Duration `yaml:"response_header_timeout"`
+
+	// MaxBodySize limits metadata responses, and MaxArtifactSize the
+	// archives some registries download to read metadata from, in bytes.
+	// Zero means no limit.
+	MaxBodySize     int64 `yaml:"max_body_size"`
+	MaxArtifactSize int64 `yaml:"max_artifact_size"`
+}
+
+// Registry configures a single ecosystem.
+type Registry struct {
+	// URL overrides the ecosystem's default base URL.
+	URL string `yaml:"url"`
+
+	// Mirrors are alternative base URLs serving the same registry API.
+	Mirrors []string `yaml:"mirrors"`
+
+	// Auth holds credentials sent to URL. They are never sent to mirrors.
+	Auth *Auth `yaml:"auth"`
+
+	// RateLimit caps requests per second to URL. Zero means unlimited.
+	RateLimit float64 `yaml:"rate_limit"`
+
+	// TTL is how long cached responses from this registry are used without
+	// revalidating. It only has an effect when cache_dir is set.
+	TTL Duration `yaml:"ttl"`
+
+	// Scopes sends npm packages in a scope to their own registry, as
+	// "@scope:registry" lines in .npmrc do. Keys are scopes like "@myorg".
+	Scopes map[string]Scope `yaml:"scopes"`
+
+	// CompatibilityMode fills in npm publish times that Verdaccio and
+	// Nexus leave out, from the tarballs' Last-Modified headers.
+	CompatibilityMode bool `yaml:"compatibility_mode"`
+
+	// Direct resolves Go modules the proxy doesn't have from their
+	// repositories, and Private lists GOPRIVATE-style patterns of modules
+	// that are resolved directly without asking the proxy. Private implies
+	// Direct.
+	Direct  bool     `yaml:"direct"`
+	Private []string `yaml:"private"`
+
+	// DirectAuth holds credentials for the git hosts Go modules are
+	// resolved from directly, keyed by host name ("github.com"). They are
+	// never sent to the proxy.
+	DirectAuth map[string]*Auth `yaml:"direct_auth"`
+
+	// GoProxy is a GOPROXY-style list of Go module proxies asked in turn
+	// in place of URL, such as "https://proxy.corp.example|https://proxy.golang.org,direct".
+	GoProxy string `yaml:"goproxy"`
+
+	// Checksums sets Maven version integrity from each version's checksum
+	// file, at the cost of a request per version.
+	Checksums bool `yaml:"checksums"`
+
+	// FlatContainer lists NuGet versions from the package content
+	// resource: version numbers only, in one small request.
+	FlatContainer bool `yaml:"flat_container"`
+
+	// InstallSignals records NuGet install scripts and native runtimes
+	// in version metadata from each version's catalog leaf, at the cost
+	// of a request per version.
+	InstallSignals bool `yaml:"install_signals"`
+
+	// Channels lists the conda channels to search, highest priority
+	// first, such as [conda-forge, bioconda, defaults].
+	Channels []string `yaml:"channels"`
+
+	// ExcludeBackPAN leaves CPAN releases deleted to BackPAN out of
+	// version lists.
+	ExcludeBackPAN bool `yaml:"exclude_backpan"`
+
+	// Organization looks hex names without an organization up in that
+	// hex.pm organization's private repository.
+	Organization string `yaml:"organization"`
+}
+
+// Scope configures the registry serving one npm scope.
+type Scope struct {
+	URL  string `yaml:"url"`
+	Auth *Auth  `yaml:"auth"`
+}
+
+// Auth holds registry credentials. Values may reference environment
+// variables as $VAR or ${VAR}.
+type Auth struct {
+	// Token is sent as "Authorization: Bearer <token>", or as the raw value
+	// of Header when Header is set.
+	Token  string `yaml:"token"`
+	Header string `yaml:"header"`
+
+	// Username and Password are sent as HTTP basic auth.
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+}
+
+// Duration is a time.Duration written as a Go duration string ("30s", "5m").
+type Duration time.Duration
+
+// UnmarshalText parses a duration string.
+func (d *Duration) UnmarshalText(text []byte) error {
+	parsed, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// MarshalText formats the duration as a Go duration string.
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+// Load reads and parses a YAML or JSON configuration file.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// Parse parses a YAML or JSON configuration. Unknown keys are rejected so
+// that typos don't silently fall back to defaults.
+func Parse(data []byte) (*Config, error) {
+	var cfg Config
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&cfg); err != nil {
+		return nil, err
+	}
+
+	cfg.CacheDir = os.ExpandEnv(cfg.CacheDir)
+	if cfg.Offline && cfg.CacheDir == "" {
+		return nil, fmt.Errorf("offline requires cache_dir")
+	}
+
+	// Aliases such as "rubygems" are stored under the registered name
+	byEcosystem := make(map[string]Registry, len(cfg.Registries))
+	for name, reg := range cfg.Registries {
+		ecosystem := registries.CanonicalEcosystem(name)
+		if _, ok := byEcosystem[ecosystem]; ok {
+			return nil, fmt.Errorf("registries.%s: %s is configured more than once", name, ecosystem)
+		}
+		// Expand before validating, so a variable can't smuggle in a
+		// URL that wouldn't be accepted written out
+		reg.URL = os.ExpandEnv(reg.URL)
+		for i, mirror := range reg.Mirrors {
+			reg.Mirrors[i] = os.ExpandEnv(mirror)
+		}
+		if reg.Auth != nil {
+			reg.Auth.expand()
+		}
+		if err := reg.validate(ecosystem); err != nil {
+			return nil, fmt.Errorf("registries.%s: %w", name, err)
+		}
+		if len(reg.Scopes) > 0 && ecosystem != "npm" {
+			return nil, fmt.Errorf("registries.%s: scopes are only supported for npm", name)
+		}
+		if reg.CompatibilityMode && ecosystem != "npm" {
+			return nil, fmt.Errorf("registries.%s: compatibility_mode is only supported for npm", name)
+		}
+		if (reg.Direct || len(reg.Private) > 0) && ecosystem != "golang" {
+			return nil, fmt.Errorf("registries.%s: direct and private are only supported for golang", name)
+		}
+		if len(reg.DirectAuth) > 0 && ecosystem != "golang" {
+			return nil, fmt.Errorf("registries.%s: direct_auth is only supported for golang", name)
+		}
+		for host, auth := range reg.DirectAuth {
+			if auth == nil {
+				continue
+			}
+			auth.expand()
+			if err := (Registry{Auth: auth}).validate(ecosystem); err != nil {
+				return nil, fmt.Errorf("registries.%s.direct_auth.%s: %w", name, host, err)
+			}
+		}
+		if reg.GoProxy != "" && ecosystem != "golang" {
+			return nil, fmt.Errorf("registries.%s: goproxy is only supported for golang", name)
+		}
+		if reg.Checksums && ecosystem != "maven" {
+			return nil, fmt.Errorf("registries.%s: checksums is only supported for maven", name)
+		}
+		if reg.FlatContainer && ecosystem != "nuget" {
+			return nil, fmt.Errorf("registries.%s: flat_container is only supported for nuget", name)
+		}
+		if reg.InstallSignals && ecosystem != "nuget" {
+			return nil, fmt.Errorf("registries.%s: install_signals is only supported for nuget", name)
+		}
+		if len(reg.Channels) > 0 && ecosystem != "conda" {
+			return nil, fmt.Errorf("registries.%s: channels is only supported for conda", name)
+		}
+		if reg.ExcludeBackPAN && ecosystem != "cpan" {
+			return nil, fmt.Errorf("registries.%s: exclude_backpan is only supported for cpan", name)
+		}
+		if reg.Organization != "" && ecosystem != "hex" {
+			return nil, fmt.Errorf("registries.%s: organization is only supported for hex", name)
+		}
+		for scopeName, scope := range reg.Scopes {
+			scope.URL = os.ExpandEnv(scope.URL)
+			if scope.Auth != nil {
+				scope.Auth.expand()
+			}
+			if err := scope.validate(ecosystem); err != nil {
+				return nil, fmt.Errorf("registries.%s.scopes.%s: %w", name, scopeName, err)
+			}
+			reg.Scopes[scopeName] = scope
+		}
+		byEcosystem[ecosystem] = reg
+	}
+	if cfg.Registries != nil {
+		cfg.Registries = byEcosystem
+	}
+
+	return &cfg, nil
+}
+
+// validate checks URLs as registries.New would, so static registries take
+// directories and Cargo index URLs their protocol prefix.
+func (r Registry) validate(ecosystem string) error {
+	for _, u := range append([]string{r.URL}, r.Mirrors...) {
+		if u == "" {
+			continue
+		}
+		if err := registries.ValidateURL(ecosystem, u); err != nil {
+			return err
+		}
+	}
+	if r.RateLimit < 0 {
+		return fmt.Errorf("rate_limit must not be negative")
+	}
+	if r.Auth != nil && r.Auth.Token != "" && r.Auth.Username != "" {
+		return fmt.Errorf("auth: set either token or username, not both")
+	}
+	return nil
+}
+
+func (s Scope) validate(ecosystem string) error {
+	if s.URL == "" {
+		return fmt.Errorf("url is required")
+	}
+	return Registry{URL: s.URL, Auth: s.Auth}.validate(ecosystem)
+}
+
+func (a *Auth) expand() {
+	a.Token = os.ExpandEnv(a.Token)
+	a.Username = os.ExpandEnv(a.Username)
+	a.Password = os.ExpandEnv(a.Password)
+}
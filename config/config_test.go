@@ -0,0 +1,484 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/git-pkgs/registries"
+	"github.com/git-pkgs/registries/client"
+	_ "github.com/git-pkgs/registries/internal/cargo"
+	"github.com/git-pkgs/registries/internal/conda"
+	_ "github.com/git-pkgs/registries/internal/npm"
+	_ "github.com/git-pkgs/registries/internal/static"
+)
+
+func TestParseYAML(t *testing.T) {
+	t.Setenv("TEST_NPM_TOKEN", "s3cret")
+
+	cfg, err := Parse([]byte(`
+user_agent: my-service/1.0
+timeout: 20s
+max_retries: 2
+registries:
+  npm:
+    url: https://npm.example.com
+    mirrors: [https://registry.npmjs.org]
+    auth:
+      token: ${TEST_NPM_TOKEN}
+    rate_limit: 10
+    ttl: 5m
+`))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	if cfg.UserAgent != "my-service/1.0" {
+		t.Errorf("unexpected user agent %q", cfg.UserAgent)
+	}
+	if time.Duration(cfg.Timeout) != 20*time.Second {
+		t.Errorf("unexpected timeout %v", time.Duration(cfg.Timeout))
+	}
+	if cfg.MaxRetries == nil || *cfg.MaxRetries != 2 {
+		t.Errorf("unexpected max retries %v", cfg.MaxRetries)
+	}
+
+	npm := cfg.Registries["npm"]
+	if npm.URL != "https://npm.example.com" {
+		t.Errorf("unexpected url %q", npm.URL)
+	}
+	if len(npm.Mirrors) != 1 {
+		t.Errorf("expected 1 mirror, got %d", len(npm.Mirrors))
+	}
+	if npm.Auth == nil || npm.Auth.Token != "s3cret" {
+		t.Errorf("expected token to be expanded from the environment, got %+v", npm.Auth)
+	}
+	if npm.RateLimit != 10 {
+		t.Errorf("unexpected rate limit %v", npm.RateLimit)
+	}
+	if time.Duration(npm.TTL) != 5*time.Minute {
+		t.Errorf("unexpected ttl %v", time.Duration(npm.TTL))
+	}
+}
+
+func TestParseJSON(t *testing.T) {
+	cfg, err := Parse([]byte(`{"registries": {"cargo": {"url": "https://crates.example.com", "ttl": "1h"}}}`))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if cfg.Registries["cargo"].URL != "https://crates.example.com" {
+		t.Errorf("unexpected url %q", cfg.Registries["cargo"].URL)
+	}
+	if time.Duration(cfg.Registries["cargo"].TTL) != time.Hour {
+		t.Errorf("unexpected ttl %v", time.Duration(cfg.Registries["cargo"].TTL))
+	}
+}
+
+func TestParseAliases(t *testing.T) {
+	cfg, err := Parse([]byte("registries:\n  crates.io:\n    url: https://crates.example.com\n"))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if _, ok := cfg.Registries["crates.io"]; ok || cfg.Registries["cargo"].URL != "https://crates.example.com" {
+		t.Errorf("registries = %+v, want the alias stored as cargo", cfg.Registries)
+	}
+
+	set, err := NewSet(cfg, client.DefaultClient())
+	if err != nil {
+		t.Fatalf("NewSet failed: %v", err)
+	}
+	reg, err := set.Get("crates")
+	if err != nil || reg.Ecosystem() != "cargo" {
+		t.Errorf("Get(crates) = %v, %v", reg, err)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := map[string]string{
+		"unknown key":        "registries:\n  npm:\n    urll: https://example.com\n",
+		"bad scheme":         "registries:\n  npm:\n    url: ftp://example.com\n",
+		"bad duration":       "timeout: soon\n",
+		"negative rate":      "registries:\n  npm:\n    rate_limit: -1\n",
+		"token and basic":    "registries:\n  npm:\n    auth:\n      token: a\n      username: b\n",
+		"offline no cache":   "offline: true\n",
+		"scope without url":  "registries:\n  npm:\n    scopes:\n      \"@myorg\": {}\n",
+		"compat on cargo":    "registries:\n  cargo:\n    compatibility_mode: true\n",
+		"scopes on cargo":    "registries:\n  cargo:\n    scopes:\n      \"@myorg\":\n        url: https://example.com\n",
+		"private on npm":     "registries:\n  npm:\n    private: [example.com]\n",
+		"checksums on npm":   "registries:\n  npm:\n    checksums: true\n",
+		"flat on npm":        "registries:\n  npm:\n    flat_container: true\n",
+		"install on npm":     "registries:\n  npm:\n    install_signals: true\n",
+		"channels on npm":    "registries:\n  npm:\n    channels: [conda-forge]\n",
+		"org on npm":         "registries:\n  npm:\n    organization: acme\n",
+		"backpan on npm":     "registries:\n  npm:\n    exclude_backpan: true\n",
+		"goproxy on npm":     "registries:\n  npm:\n    goproxy: direct\n",
+		"direct_auth on npm": "registries:\n  npm:\n    direct_auth:\n      github.com:\n        token: a\n",
+		"direct_auth both":   "registries:\n  golang:\n    direct_auth:\n      github.com:\n        token: a\n        username: b\n",
+		"alias twice":        "registries:\n  npm:\n    rate_limit: 1\n  npmjs:\n    rate_limit: 2\n",
+	}
+
+	for name, input := range tests {
+		if _, err := Parse([]byte(input)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestParseValidatesExpandedURLs(t *testing.T) {
+	t.Setenv("TEST_REGISTRY_URL", "ftp://registry.example.com")
+	t.Setenv("TEST_MIRROR_URL", "https://mirror.example.com")
+
+	for name, input := range map[string]string{
+		"url":    "registries:\n  npm:\n    url: ${TEST_REGISTRY_URL}\n",
+		"mirror": "registries:\n  npm:\n    mirrors: [\"${TEST_REGISTRY_URL}\"]\n",
+		"scope":  "registries:\n  npm:\n    scopes:\n      \"@myorg\":\n        url: $TEST_REGISTRY_URL\n",
+	} {
+		if _, err := Parse([]byte(input)); err == nil {
+			t.Errorf("%s: expected an error for an expanded ftp URL", name)
+		}
+	}
+
+	cfg, err := Parse([]byte("registries:\n  npm:\n    mirrors: [\"${TEST_MIRROR_URL}\"]\n"))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if got := cfg.Registries["npm"].Mirrors; len(got) != 1 || got[0] != "https://mirror.example.com" {
+		t.Errorf("mirrors = %v", got)
+	}
+}
+
+func TestParseStaticDirectories(t *testing.T) {
+	dir := t.TempDir()
+	for _, u := range []string{dir, "file://" + dir} {
+		cfg, err := Parse([]byte("registries:\n  static:\n    url: " + u + "\n"))
+		if err != nil {
+			t.Errorf("%s: %v", u, err)
+			continue
+		}
+		set, err := NewSet(cfg, nil)
+		if err != nil {
+			t.Errorf("%s: NewSet failed: %v", u, err)
+			continue
+		}
+		if reg, err := set.Get("static"); err != nil || reg.Ecosystem() != "static" {
+			t.Errorf("%s: Get = %v, %v", u, reg, err)
+		}
+	}
+
+	for _, input := range []string{
+		"registries:\n  static:\n    url: https://example.com/packages\n",
+		"registries:\n  npm:\n    url: " + dir + "\n",
+		"registries:\n  npm:\n    url: sparse+https://index.example.com/\n",
+	} {
+		if _, err := Parse([]byte(input)); !errors.Is(err, registries.ErrInvalidURL) {
+			t.Errorf("%q: expected ErrInvalidURL, got %v", input, err)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "registries.yaml")
+	if err := os.WriteFile(path, []byte("registries:\n  npm:\n    ttl: 10m\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if time.Duration(cfg.Registries["npm"].TTL) != 10*time.Minute {
+		t.Errorf("unexpected ttl %v", time.Duration(cfg.Registries["npm"].TTL))
+	}
+}
+
+func TestSetGet(t *testing.T) {
+	var gotAuth, gotUA string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		gotUA = r.Header.Get("User-Agent")
+		_, _ = w.Write([]byte(`{"name": "lodash", "dist-tags": {"latest": "4.17.21"}, "versions": {}}`))
+	}))
+	defer server.Close()
+
+	cfg := &Config{
+		UserAgent: "config-test/1.0",
+		Registries: map[string]Registry{
+			"npm": {
+				URL:     server.URL,
+				Mirrors: []string{"https://registry.npmjs.org"},
+				Auth:    &Auth{Username: "user", Password: "pass"},
+				TTL:     Duration(time.Minute),
+			},
+		},
+	}
+
+	set, err := NewSet(cfg, nil)
+	if err != nil {
+		t.Fatalf("NewSet failed: %v", err)
+	}
+
+	reg, err := set.Get("npm")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if _, err := reg.FetchPackage(context.Background(), "lodash"); err != nil {
+		t.Fatalf("FetchPackage failed: %v", err)
+	}
+
+	if gotAuth != "Basic dXNlcjpwYXNz" {
+		t.Errorf("unexpected Authorization header %q", gotAuth)
+	}
+	if gotUA != "config-test/1.0" {
+		t.Errorf("unexpected User-Agent %q", gotUA)
+	}
+
+	mirrors, err := set.Mirrors("npm")
+	if err != nil || len(mirrors) != 1 {
+		t.Fatalf("expected 1 mirror, got %d (%v)", len(mirrors), err)
+	}
+	if set.TTL("npm") != time.Minute {
+		t.Errorf("unexpected TTL %v", set.TTL("npm"))
+	}
+	if got := strings.Join(set.Ecosystems(), ","); got != "npm" {
+		t.Errorf("unexpected ecosystems %q", got)
+	}
+
+	// Unconfigured ecosystems fall back to their defaults
+	cargo, err := set.Get("cargo")
+	if err != nil {
+		t.Fatalf("Get(cargo) failed: %v", err)
+	}
+	if cargo.Ecosystem() != "cargo" {
+		t.Errorf("unexpected ecosystem %q", cargo.Ecosystem())
+	}
+}
+
+func TestSetScopes(t *testing.T) {
+	var gotAuth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		_, _ = w.Write([]byte(`{"name": "@myorg/widget", "dist-tags": {"latest": "1.0.0"}, "versions": {}}`))
+	}))
+	defer server.Close()
+
+	t.Setenv("TEST_SCOPE_TOKEN", "s3cret")
+	cfg, err := Parse([]byte("registries:\n  npm:\n    scopes:\n      \"@myorg\":\n        url: " + server.URL + "\n        auth:\n          token: ${TEST_SCOPE_TOKEN}\n"))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	set, err := NewSet(cfg, nil)
+	if err != nil {
+		t.Fatalf("NewSet failed: %v", err)
+	}
+	reg, err := set.Get("npm")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+
+	pkg, err := reg.FetchPackage(context.Background(), "@myorg/widget")
+	if err != nil {
+		t.Fatalf("FetchPackage failed: %v", err)
+	}
+	if pkg.LatestVersion != "1.0.0" || gotAuth != "Bearer s3cret" {
+		t.Errorf("scope not routed with credentials: %q %q", pkg.LatestVersion, gotAuth)
+	}
+}
+
+func TestSetCargoToken(t *testing.T) {
+	var gotAuth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		_, _ = w.Write([]byte(`{"name":"my-crate","vers":"0.1.0","deps":[],"cksum":"aa","features":{},"yanked":false}`))
+	}))
+	defer server.Close()
+
+	cfg, err := Parse([]byte("registries:\n  cargo:\n    url: sparse+" + server.URL + "/\n    auth:\n      token: s3cret\n"))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	set, err := NewSet(cfg, nil)
+	if err != nil {
+		t.Fatalf("NewSet failed: %v", err)
+	}
+	reg, _ := set.Get("cargo")
+	if _, err := registries.FetchVersions(context.Background(), reg, "my-crate"); err != nil {
+		t.Fatalf("FetchVersions failed: %v", err)
+	}
+	if gotAuth != "s3cret" {
+		t.Errorf("expected the bare token, got %q", gotAuth)
+	}
+}
+
+func TestSetGoPrivate(t *testing.T) {
+	var proxyHits, goGetHits int
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("go-get") == "1" {
+			goGetHits++
+			if r.Header.Get("Authorization") != "Bearer git-token" {
+				t.Errorf("go-get request sent Authorization %q", r.Header.Get("Authorization"))
+			}
+		} else {
+			proxyHits++
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	host := strings.TrimPrefix(server.URL, "https://")
+	t.Setenv("GIT_TOKEN", "git-token")
+	cfg, err := Parse([]byte("registries:\n  golang:\n    url: " + server.URL + "\n    private: [" + host + "/myorg]\n" +
+		"    direct_auth:\n      \"" + host + "\":\n        token: ${GIT_TOKEN}\n"))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	c := client.DefaultClient()
+	c.HTTPClient = server.Client()
+	set, err := NewSet(cfg, c)
+	if err != nil {
+		t.Fatalf("NewSet failed: %v", err)
+	}
+	reg, _ := set.Get("golang")
+	_, _ = registries.FetchVersions(context.Background(), reg, host+"/myorg/repo")
+	if proxyHits != 0 || goGetHits != 1 {
+		t.Errorf("private module not resolved directly: %d proxy requests, %d go-get requests", proxyHits, goGetHits)
+	}
+}
+
+func TestSetCondaChannels(t *testing.T) {
+	cfg, err := Parse([]byte("registries:\n  conda:\n    channels: [bioconda, defaults]\n"))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	set, err := NewSet(cfg, client.DefaultClient())
+	if err != nil {
+		t.Fatalf("NewSet failed: %v", err)
+	}
+	reg, _ := set.Get("conda")
+	condaReg, ok := reg.(*conda.Registry)
+	if !ok {
+		t.Fatalf("expected a conda registry, got %T", reg)
+	}
+	if got := condaReg.Channels(); !reflect.DeepEqual(got, []string{"bioconda", "anaconda"}) {
+		t.Errorf("unexpected channels %v", got)
+	}
+}
+
+func TestSetHexOrganization(t *testing.T) {
+	var auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		if r.URL.Path != "/api/repos/acme/packages/billing" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(`{"name": "billing"}`))
+	}))
+	defer server.Close()
+
+	cfg, err := Parse([]byte("registries:\n  hex:\n    url: " + server.URL + "\n    organization: acme\n    auth:\n      token: k3y\n"))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	set, err := NewSet(cfg, client.DefaultClient())
+	if err != nil {
+		t.Fatalf("NewSet failed: %v", err)
+	}
+	reg, _ := set.Get("hex")
+	pkg, err := reg.FetchPackage(context.Background(), "billing")
+	if err != nil {
+		t.Fatalf("FetchPackage failed: %v", err)
+	}
+	if pkg.Name != "acme/billing" || auth != "k3y" {
+		t.Errorf("got package %q with Authorization %q", pkg.Name, auth)
+	}
+}
+
+func TestSetUnknownEcosystem(t *testing.T) {
+	cfg := &Config{Registries: map[string]Registry{"nope": {}}}
+	if _, err := NewSet(cfg, nil); err == nil {
+		t.Error("expected an error for an unregistered ecosystem")
+	}
+}
+
+func TestAuthFuncScopedToBaseURL(t *testing.T) {
+	fn := authFunc("https://npm.example.com", &Auth{Token: "abc", Header: "X-Api-Key"})
+
+	if name, value := fn("https://npm.example.com/lodash"); name != "X-Api-Key" || value != "abc" {
+		t.Errorf("expected token header, got %q=%q", name, value)
+	}
+	if name, _ := fn("https://npm.example.com.evil.net/lodash"); name != "" {
+		t.Errorf("credentials sent to another host: %q", name)
+	}
+	if name, _ := fn("https://registry.npmjs.org/lodash"); name != "" {
+		t.Errorf("credentials sent to mirror: %q", name)
+	}
+}
+
+func TestSetOffline(t *testing.T) {
+	var hits int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		_, _ = w.Write([]byte(`{"name": "lodash", "dist-tags": {"latest": "4.17.21"}, "versions": {}}`))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	online, err := NewSet(&Config{CacheDir: dir, Registries: map[string]Registry{"npm": {URL: server.URL}}}, nil)
+	if err != nil {
+		t.Fatalf("NewSet failed: %v", err)
+	}
+	reg, _ := online.Get("npm")
+	if _, err := reg.FetchPackage(context.Background(), "lodash"); err != nil {
+		t.Fatalf("FetchPackage failed: %v", err)
+	}
+
+	offline, err := NewSet(&Config{CacheDir: dir, Offline: true, Registries: map[string]Registry{"npm": {URL: server.URL}}}, nil)
+	if err != nil {
+		t.Fatalf("NewSet failed: %v", err)
+	}
+	reg, _ = offline.Get("npm")
+	if _, err := reg.FetchPackage(context.Background(), "lodash"); err != nil {
+		t.Errorf("expected cached FetchPackage to succeed offline: %v", err)
+	}
+	if _, err := reg.FetchPackage(context.Background(), "react"); !errors.Is(err, client.ErrCacheMiss) {
+		t.Errorf("expected ErrCacheMiss, got %v", err)
+	}
+	if hits != 1 {
+		t.Errorf("expected 1 upstream request, got %d", hits)
+	}
+}
+
+func TestSetLimits(t *testing.T) {
+	cfg, err := Parse([]byte(`
+dial_timeout: 2s
+response_header_timeout: 10s
+max_body_size: 1048576
+max_artifact_size: 52428800
+`))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	shared := client.DefaultClient()
+	s, err := NewSet(cfg, shared)
+	if err != nil {
+		t.Fatalf("NewSet failed: %v", err)
+	}
+	c := s.Client()
+	if c.MaxBodySize != 1<<20 || c.MaxArtifactSize != 50<<20 {
+		t.Errorf("limits = %d, %d", c.MaxBodySize, c.MaxArtifactSize)
+	}
+	if tr := c.HTTPClient.Transport.(*http.Transport); tr.ResponseHeaderTimeout != 10*time.Second {
+		t.Errorf("ResponseHeaderTimeout = %v", tr.ResponseHeaderTimeout)
+	}
+	if shared.HTTPClient.Transport != nil {
+		t.Error("NewSet modified the caller's transport")
+	}
+}
@@ -0,0 +1,271 @@
+package config
+
+import (
+	"encoding/base64"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/git-pkgs/registries"
+	"github.com/git-pkgs/registries/client"
+	"github.com/git-pkgs/registries/internal/cargo"
+	"github.com/git-pkgs/registries/internal/conda"
+	"github.com/git-pkgs/registries/internal/cpan"
+	"github.com/git-pkgs/registries/internal/golang"
+	"github.com/git-pkgs/registries/internal/hex"
+	"github.com/git-pkgs/registries/internal/maven"
+	"github.com/git-pkgs/registries/internal/npm"
+	"github.com/git-pkgs/registries/internal/nuget"
+	"github.com/git-pkgs/registries/internal/terraform"
+)
+
+// Set holds ready-to-use registry clients built from a Config.
+type Set struct {
+	client  *client.Client
+	entries map[string]Registry
+
+	mu         sync.Mutex
+	registries map[string]registries.Registry
+}
+
+// NewSet builds a registry client for every configured ecosystem. Requests
+// share c, or client.DefaultClient() if c is nil, with the configuration's
+// user agent, timeout, size limit, retry and cache settings applied on top.
+func NewSet(cfg *Config, c *client.Client) (*Set, error) {
+	if c == nil {
+		c = client.DefaultClient()
+	}
+	base := *c
+	if cfg.UserAgent != "" {
+		base.UserAgent = cfg.UserAgent
+	}
+	if cfg.Timeout > 0 {
+		httpClient := http.Client{}
+		if base.HTTPClient != nil {
+			httpClient = *base.HTTPClient
+		}
+		httpClient.Timeout = time.Duration(cfg.Timeout)
+		base.HTTPClient = &httpClient
+	}
+	if cfg.DialTimeout > 0 || cfg.ResponseHeaderTimeout > 0 {
+		// The transport options modify the transport in place, so give
+		// them a copy of c's
+		httpClient := http.Client{}
+		if base.HTTPClient != nil {
+			httpClient = *base.HTTPClient
+		}
+		if t, ok := httpClient.Transport.(*http.Transport); ok {
+			httpClient.Transport = t.Clone()
+		}
+		base.HTTPClient = &httpClient
+		if cfg.DialTimeout > 0 {
+			client.WithDialTimeout(time.Duration(cfg.DialTimeout))(&base)
+		}
+		if cfg.ResponseHeaderTimeout > 0 {
+			client.WithResponseHeaderTimeout(time.Duration(cfg.ResponseHeaderTimeout))(&base)
+		}
+	}
+	if cfg.MaxRetries != nil {
+		base.MaxRetries = *cfg.MaxRetries
+	}
+	if cfg.MaxBodySize > 0 {
+		base.MaxBodySize = cfg.MaxBodySize
+	}
+	if cfg.MaxArtifactSize > 0 {
+		base.MaxArtifactSize = cfg.MaxArtifactSize
+	}
+	if cfg.CacheDir != "" {
+		cache, err := client.NewDiskCache(cfg.CacheDir)
+		if err != nil {
+			return nil, err
+		}
+		base.Cache = cache
+	}
+	base.Offline = base.Offline || cfg.Offline
+
+	s := &Set{
+		client:     &base,
+		entries:    make(map[string]Registry, len(cfg.Registries)),
+		registries: make(map[string]registries.Registry, len(cfg.Registries)),
+	}
+
+	for ecosystem, entry := range cfg.Registries {
+		reg, err := registries.New(ecosystem, entry.URL, s.clientFor(ecosystem, entry))
+		if err != nil {
+			return nil, err
+		}
+		if npmReg, ok := reg.(*npm.Registry); ok {
+			if entry.CompatibilityMode {
+				npmReg = npmReg.WithCompatibilityMode()
+			}
+			for scope, sc := range entry.Scopes {
+				var auth func(string) (string, string)
+				if sc.Auth != nil {
+					auth = authFunc(strings.TrimSuffix(sc.URL, "/"), sc.Auth)
+				}
+				npmReg = npmReg.WithScopeRegistry(scope, sc.URL, auth)
+			}
+			reg = npmReg
+		}
+		if goReg, ok := reg.(*golang.Registry); ok && entry.GoProxy != "" {
+			reg = goReg.WithProxyList(entry.GoProxy)
+		}
+		if goReg, ok := reg.(*golang.Registry); ok && (entry.Direct || len(entry.Private) > 0) {
+			reg = goReg.WithDirectFallback(entry.Private...)
+		}
+		if goReg, ok := reg.(*golang.Registry); ok && len(entry.DirectAuth) > 0 {
+			for host, auth := range entry.DirectAuth {
+				if auth != nil {
+					name, value := authHeader(auth)
+					goReg = goReg.WithDirectAuth(host, name, value)
+				}
+			}
+			reg = goReg
+		}
+		if mavenReg, ok := reg.(*maven.Registry); ok && entry.Checksums {
+			reg = mavenReg.WithChecksums()
+		}
+		if nugetReg, ok := reg.(*nuget.Registry); ok && entry.FlatContainer {
+			reg = nugetReg.WithFlatContainer()
+		}
+		if nugetReg, ok := reg.(*nuget.Registry); ok && entry.InstallSignals {
+			reg = nugetReg.WithInstallSignals()
+		}
+		if cpanReg, ok := reg.(*cpan.Registry); ok && entry.ExcludeBackPAN {
+			reg = cpanReg.WithoutBackPAN()
+		}
+		if condaReg, ok := reg.(*conda.Registry); ok && len(entry.Channels) > 0 {
+			reg = condaReg.WithChannels(entry.Channels...)
+		}
+		// Cargo sends the bare token to the index, API and download hosts,
+		// which can all differ
+		if cargoReg, ok := reg.(*cargo.Registry); ok && entry.Auth != nil && entry.Auth.Token != "" && entry.Auth.Header == "" {
+			reg = cargoReg.WithToken(entry.Auth.Token)
+		}
+		// anaconda.org expects "Authorization: token <token>"
+		if condaReg, ok := reg.(*conda.Registry); ok && entry.Auth != nil && entry.Auth.Token != "" && entry.Auth.Header == "" {
+			reg = condaReg.WithToken(entry.Auth.Token)
+		}
+		// Terraform's module API may be on another host than the one
+		// discovery ran on
+		if tfReg, ok := reg.(*terraform.Registry); ok && entry.Auth != nil && entry.Auth.Token != "" && entry.Auth.Header == "" {
+			reg = tfReg.WithToken(entry.Auth.Token)
+		}
+		// hex.pm takes the bare API key, and repo.hex.pm needs it too for
+		// organization tarballs
+		if hexReg, ok := reg.(*hex.Registry); ok {
+			if entry.Auth != nil && entry.Auth.Token != "" && entry.Auth.Header == "" {
+				hexReg = hexReg.WithAPIKey(entry.Auth.Token)
+			}
+			if entry.Organization != "" {
+				hexReg = hexReg.WithOrganization(entry.Organization)
+			}
+			reg = hexReg
+		}
+		s.entries[ecosystem] = entry
+		s.registries[ecosystem] = reg
+	}
+
+	return s, nil
+}
+
+// clientFor applies an entry's rate limit and credentials to the shared client.
+func (s *Set) clientFor(ecosystem string, entry Registry) *client.Client {
+	c := s.client
+	if entry.TTL > 0 {
+		c = c.WithCacheTTL(time.Duration(entry.TTL))
+	}
+	if entry.RateLimit > 0 {
+		c = c.WithRateLimiter(client.NewIntervalLimiter(entry.RateLimit))
+	}
+	if entry.Auth != nil {
+		baseURL := entry.URL
+		if baseURL == "" {
+			baseURL = registries.DefaultURL(ecosystem)
+		}
+		c = c.WithAuthFunc(authFunc(strings.TrimSuffix(baseURL, "/"), entry.Auth))
+	}
+	return c
+}
+
+// authFunc only sends credentials to URLs under baseURL, so they don't leak
+// to mirrors or to CDNs a registry redirects downloads to.
+func authFunc(baseURL string, auth *Auth) func(string) (string, string) {
+	name, value := authHeader(auth)
+	return func(url string) (string, string) {
+		if url == baseURL || strings.HasPrefix(url, baseURL+"/") {
+			return name, value
+		}
+		return "", ""
+	}
+}
+
+// authHeader returns the header credentials are sent in.
+func authHeader(auth *Auth) (name, value string) {
+	switch {
+	case auth.Token != "" && auth.Header != "":
+		return auth.Header, auth.Token
+	case auth.Token != "":
+		return "Authorization", "Bearer " + auth.Token
+	case auth.Username != "":
+		return "Authorization", "Basic " + base64.StdEncoding.EncodeToString([]byte(auth.Username+":"+auth.Password))
+	}
+	return "", ""
+}
+
+// Get returns the registry client for an ecosystem. Ecosystems missing from
+// the configuration get a client for their default URL using the shared
+// client settings.
+func (s *Set) Get(ecosystem string) (registries.Registry, error) {
+	ecosystem = registries.CanonicalEcosystem(ecosystem)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if reg, ok := s.registries[ecosystem]; ok {
+		return reg, nil
+	}
+
+	reg, err := registries.New(ecosystem, "", s.client)
+	if err != nil {
+		return nil, err
+	}
+	s.registries[ecosystem] = reg
+	return reg, nil
+}
+
+// Mirrors returns a client for each configured mirror of an ecosystem, in
+// configuration order. Mirrors use the shared client without credentials.
+func (s *Set) Mirrors(ecosystem string) ([]registries.Registry, error) {
+	entry := s.entries[registries.CanonicalEcosystem(ecosystem)]
+	mirrors := make([]registries.Registry, 0, len(entry.Mirrors))
+	for _, u := range entry.Mirrors {
+		reg, err := registries.New(ecosystem, u, s.client)
+		if err != nil {
+			return nil, err
+		}
+		mirrors = append(mirrors, reg)
+	}
+	return mirrors, nil
+}
+
+// TTL returns the configured cache lifetime for an ecosystem, or zero.
+func (s *Set) TTL(ecosystem string) time.Duration {
+	return time.Duration(s.entries[registries.CanonicalEcosystem(ecosystem)].TTL)
+}
+
+// Ecosystems returns the configured ecosystems, sorted.
+func (s *Set) Ecosystems() []string {
+	ecosystems := make([]string, 0, len(s.entries))
+	for ecosystem := range s.entries {
+		ecosystems = append(ecosystems, ecosystem)
+	}
+	sort.Strings(ecosystems)
+	return ecosystems
+}
+
+// Client returns the shared client the set was built with.
+func (s *Set) Client() *client.Client {
+	return s.client
+}
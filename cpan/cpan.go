@@ -0,0 +1,30 @@
+// Package cpan constructs clients for CPAN through the MetaCPAN API.
+//
+//	reg, err := cpan.New("", nil) // https://fastapi.metacpan.org
+//	pkg, err := reg.FetchPackage(ctx, name)
+package cpan
+
+import (
+	"github.com/git-pkgs/registries/client"
+	"github.com/git-pkgs/registries/internal/core"
+	impl "github.com/git-pkgs/registries/internal/cpan"
+)
+
+// DefaultURL is the registry New uses when given no base URL.
+const DefaultURL = impl.DefaultURL
+
+// BackPANURL is the archive of releases deleted from CPAN, which
+// FetchVersions points their download URLs at.
+const BackPANURL = impl.BackPANURL
+
+// Registry is the client New returns. It implements registries.Registry and
+// the optional interfaces the ecosystem supports.
+type Registry = impl.Registry
+
+// New returns a client for the registry at baseURL, or DefaultURL if
+// baseURL is empty. A nil client uses client.DefaultClient. A baseURL that
+// fails registries.ValidateURL returns an error wrapping
+// registries.ErrInvalidURL.
+func New(baseURL string, c *client.Client) (*Registry, error) {
+	return core.Construct("cpan", baseURL, c, impl.New)
+}
@@ -0,0 +1,26 @@
+// Package cran constructs clients for CRAN.
+//
+//	reg, err := cran.New("", nil) // https://cran.r-project.org
+//	pkg, err := reg.FetchPackage(ctx, name)
+package cran
+
+import (
+	"github.com/git-pkgs/registries/client"
+	"github.com/git-pkgs/registries/internal/core"
+	impl "github.com/git-pkgs/registries/internal/cran"
+)
+
+// DefaultURL is the registry New uses when given no base URL.
+const DefaultURL = impl.DefaultURL
+
+// Registry is the client New returns. It implements registries.Registry and
+// the optional interfaces the ecosystem supports.
+type Registry = impl.Registry
+
+// New returns a client for the registry at baseURL, or DefaultURL if
+// baseURL is empty. A nil client uses client.DefaultClient. A baseURL that
+// fails registries.ValidateURL returns an error wrapping
+// registries.ErrInvalidURL.
+func New(baseURL string, c *client.Client) (*Registry, error) {
+	return core.Construct("cran", baseURL, c, impl.New)
+}
@@ -0,0 +1,264 @@
+// Package feeds reads the release feeds some registries publish, to discover
+// recently published versions without polling every package.
+//
+//	releases, err := feeds.Fetch(ctx, nil, "pypi")
+//	for _, r := range releases {
+//		fmt.Println(r.PURL(), r.PublishedAt)
+//	}
+//
+// Pass the releases to watch.Watcher.PollPackages to re-check only the
+// watched packages that changed.
+package feeds
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/git-pkgs/registries/client"
+)
+
+// Release is a version announced by a feed.
+type Release struct {
+	Ecosystem   string
+	Name        string
+	Version     string
+	PublishedAt time.Time
+	URL         string
+}
+
+// PURL returns the versioned Package URL of the release.
+func (r Release) PURL() string {
+	return fmt.Sprintf("pkg:%s/%s@%s", r.Ecosystem, r.Name, url.PathEscape(r.Version))
+}
+
+type source struct {
+	url   string
+	parse func(data []byte) ([]Release, error)
+}
+
+var sources = map[string]source{
+	"pypi":     {"https://pypi.org/rss/updates.xml", parseItems("pypi", pypiRelease)},
+	"hackage":  {"https://hackage.haskell.org/packages/recent.rss", parseItems("hackage", hackageRelease)},
+	"cpan":     {"https://metacpan.org/feed/recent", parseItems("cpan", cpanRelease)},
+	"composer": {"https://packagist.org/feeds/releases.rss", parseItems("composer", packagistRelease)},
+	"cran":     {"https://dirk.eddelbuettel.com/cranberries/index.rss", parseItems("cran", cranRelease)},
+	"gem":      {"https://rubygems.org/api/v1/activity/just_updated.json", parseRubyGems},
+	"hex":      {"https://hex.pm/api/packages?sort=updated_at", parseHex},
+}
+
+// Ecosystems returns the ecosystems with a known release feed, sorted.
+func Ecosystems() []string {
+	ecosystems := make([]string, 0, len(sources))
+	for e := range sources {
+		ecosystems = append(ecosystems, e)
+	}
+	sort.Strings(ecosystems)
+	return ecosystems
+}
+
+// DefaultURL returns the feed URL used for an ecosystem, or "" if it has none.
+func DefaultURL(ecosystem string) string {
+	return sources[ecosystem].url
+}
+
+// Fetch reads the ecosystem's release feed. If c is nil,
+// client.DefaultClient() is used.
+func Fetch(ctx context.Context, c *client.Client, ecosystem string) ([]Release, error) {
+	return FetchURL(ctx, c, ecosystem, DefaultURL(ecosystem))
+}
+
+// FetchURL reads a release feed for ecosystem from feedURL, e.g. a mirror.
+func FetchURL(ctx context.Context, c *client.Client, ecosystem, feedURL string) ([]Release, error) {
+	if _, ok := sources[ecosystem]; !ok {
+		return nil, fmt.Errorf("feeds: no release feed for ecosystem %q", ecosystem)
+	}
+	if c == nil {
+		c = client.DefaultClient()
+	}
+	body, err := c.GetBody(ctx, feedURL)
+	if err != nil {
+		return nil, err
+	}
+	return ParseReleases(ecosystem, body)
+}
+
+// ParseReleases extracts releases from a feed document for ecosystem.
+// Items that don't describe a release are skipped.
+func ParseReleases(ecosystem string, data []byte) ([]Release, error) {
+	src, ok := sources[ecosystem]
+	if !ok {
+		return nil, fmt.Errorf("feeds: no release feed for ecosystem %q", ecosystem)
+	}
+	return src.parse(data)
+}
+
+// parseItems adapts a per-item extractor to an RSS/Atom source.
+func parseItems(ecosystem string, extract func(Item) (name, version string)) func([]byte) ([]Release, error) {
+	return func(data []byte) ([]Release, error) {
+		items, err := Parse(data)
+		if err != nil {
+			return nil, err
+		}
+		releases := make([]Release, 0, len(items))
+		for _, item := range items {
+			name, version := extract(item)
+			if name == "" || version == "" {
+				continue
+			}
+			releases = append(releases, Release{
+				Ecosystem:   ecosystem,
+				Name:        name,
+				Version:     version,
+				PublishedAt: item.Published,
+				URL:         item.Link,
+			})
+		}
+		return releases, nil
+	}
+}
+
+// pypiRelease reads titles like "requests 2.31.0".
+func pypiRelease(item Item) (string, string) {
+	return splitLast(item.Title, " ")
+}
+
+// hackageRelease reads titles like "aeson-2.2.0.0" or "aeson 2.2.0.0".
+func hackageRelease(item Item) (string, string) {
+	if strings.Contains(item.Title, " ") {
+		return splitLast(item.Title, " ")
+	}
+	return splitDashVersion(item.Title)
+}
+
+// cpanRelease reads the distribution from release links such as
+// https://metacpan.org/release/ETHER/Moose-2.2201, falling back to the title.
+func cpanRelease(item Item) (string, string) {
+	if item.Link != "" {
+		if name, version := splitDashVersion(path.Base(strings.TrimSuffix(item.Link, "/"))); name != "" {
+			return name, version
+		}
+	}
+	fields := strings.Fields(item.Title)
+	if len(fields) == 0 {
+		return "", ""
+	}
+	return splitDashVersion(fields[len(fields)-1])
+}
+
+var packagistTitle = regexp.MustCompile(`^(\S+/\S+)\s+\(([^)]+)\)`)
+
+// packagistRelease reads titles like "monolog/monolog (3.5.0)".
+func packagistRelease(item Item) (string, string) {
+	m := packagistTitle.FindStringSubmatch(item.Title)
+	if m == nil {
+		return "", ""
+	}
+	return m[1], m[2]
+}
+
+var cranberriesTitle = regexp.MustCompile(`^(?:New package|Package) (\S+) (?:with initial version|updated to version) (\S+)`)
+
+// cranRelease reads CRANberries titles like "New package foo with initial
+// version 0.1.0" and "Package foo updated to version 1.2 with previous
+// version 1.1". Removals are skipped.
+func cranRelease(item Item) (string, string) {
+	m := cranberriesTitle.FindStringSubmatch(item.Title)
+	if m == nil {
+		return "", ""
+	}
+	return m[1], m[2]
+}
+
+func splitLast(s, sep string) (string, string) {
+	i := strings.LastIndex(s, sep)
+	if i <= 0 || i == len(s)-len(sep) {
+		return "", ""
+	}
+	return strings.TrimSpace(s[:i]), strings.TrimSpace(s[i+len(sep):])
+}
+
+// splitDashVersion splits "name-with-dashes-1.2.3" at the last dash that is
+// followed by a version number (a digit, or "v" and a digit).
+func splitDashVersion(s string) (string, string) {
+	for i := len(s) - 1; i > 0; i-- {
+		if s[i] != '-' || i == len(s)-1 {
+			continue
+		}
+		rest := s[i+1:]
+		if isDigit(rest[0]) || (rest[0] == 'v' && len(rest) > 1 && isDigit(rest[1])) {
+			return s[:i], rest
+		}
+	}
+	return "", ""
+}
+
+func isDigit(b byte) bool {
+	return b >= '0' && b <= '9'
+}
+
+func parseRubyGems(data []byte) ([]Release, error) {
+	var gems []struct {
+		Name             string `json:"name"`
+		Version          string `json:"version"`
+		VersionCreatedAt string `json:"version_created_at"`
+		ProjectURI       string `json:"project_uri"`
+	}
+	if err := json.Unmarshal(data, &gems); err != nil {
+		return nil, err
+	}
+
+	releases := make([]Release, 0, len(gems))
+	for _, g := range gems {
+		releases = append(releases, Release{
+			Ecosystem:   "gem",
+			Name:        g.Name,
+			Version:     g.Version,
+			PublishedAt: parseTime(g.VersionCreatedAt),
+			URL:         g.ProjectURI,
+		})
+	}
+	return releases, nil
+}
+
+func parseHex(data []byte) ([]Release, error) {
+	var packages []struct {
+		Name          string `json:"name"`
+		LatestVersion string `json:"latest_version"`
+		UpdatedAt     string `json:"updated_at"`
+		HTMLURL       string `json:"html_url"`
+	}
+	if err := json.Unmarshal(data, &packages); err != nil {
+		return nil, err
+	}
+
+	releases := make([]Release, 0, len(packages))
+	for _, p := range packages {
+		if p.LatestVersion == "" {
+			continue
+		}
+		releases = append(releases, Release{
+			Ecosystem:   "hex",
+			Name:        p.Name,
+			Version:     p.LatestVersion,
+			PublishedAt: parseTime(p.UpdatedAt),
+			URL:         p.HTMLURL,
+		})
+	}
+	return releases, nil
+}
+
+// PURLs returns the versioned PURL of each release.
+func PURLs(releases []Release) []string {
+	purls := make([]string, len(releases))
+	for i, r := range releases {
+		purls[i] = r.PURL()
+	}
+	return purls
+}
@@ -0,0 +1,164 @@
+package feeds
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/git-pkgs/registries/client"
+)
+
+const pypiRSS = `<?xml version="1.0" encoding="UTF-8"?>
+<rss xmlns:dc="http://purl.org/dc/elements/1.1/" version="2.0">
+  <channel>
+    <title>PyPI recent updates</title>
+    <item>
+      <title>requests 2.31.0</title>
+      <link>https://pypi.org/project/requests/2.31.0/</link>
+      <description>Python HTTP for Humans.</description>
+      <pubDate>Mon, 22 May 2023 15:12:44 GMT</pubDate>
+    </item>
+    <item>
+      <title>zope.interface 6.0</title>
+      <link>https://pypi.org/project/zope.interface/6.0/</link>
+      <pubDate>Mon, 22 May 2023 15:10:00 GMT</pubDate>
+    </item>
+  </channel>
+</rss>`
+
+const cpanRDF = `<?xml version="1.0" encoding="UTF-8"?>
+<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#" xmlns="http://purl.org/rss/1.0/" xmlns:dc="http://purl.org/dc/elements/1.1/">
+  <channel><title>Recent CPAN uploads</title></channel>
+  <item>
+    <title>Moose-2.2201</title>
+    <link>https://metacpan.org/release/ETHER/Moose-2.2201</link>
+    <dc:date>2022-03-25T12:00:00Z</dc:date>
+  </item>
+  <item>
+    <title>Data-Dumper-Concise-v2.23</title>
+    <link>https://metacpan.org/release/ETHER/Data-Dumper-Concise-v2.23</link>
+  </item>
+</rdf:RDF>`
+
+const atomFeed = `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <title>Releases</title>
+  <entry>
+    <title>aeson-2.2.0.0</title>
+    <id>urn:aeson-2.2.0.0</id>
+    <link rel="alternate" href="https://hackage.haskell.org/package/aeson-2.2.0.0"/>
+    <updated>2023-06-20T10:00:00Z</updated>
+    <summary>Fast JSON parsing</summary>
+  </entry>
+</feed>`
+
+func TestParse(t *testing.T) {
+	items, err := Parse([]byte(pypiRSS))
+	if err != nil {
+		t.Fatalf("Parse RSS failed: %v", err)
+	}
+	if len(items) != 2 || items[0].Title != "requests 2.31.0" {
+		t.Fatalf("unexpected RSS items: %+v", items)
+	}
+	if !items[0].Published.Equal(time.Date(2023, 5, 22, 15, 12, 44, 0, time.UTC)) {
+		t.Errorf("unexpected pubDate %v", items[0].Published)
+	}
+
+	items, err = Parse([]byte(cpanRDF))
+	if err != nil {
+		t.Fatalf("Parse RDF failed: %v", err)
+	}
+	if len(items) != 2 || items[0].Published.Year() != 2022 {
+		t.Errorf("unexpected RDF items: %+v", items)
+	}
+
+	items, err = Parse([]byte(atomFeed))
+	if err != nil {
+		t.Fatalf("Parse Atom failed: %v", err)
+	}
+	if len(items) != 1 || items[0].Link != "https://hackage.haskell.org/package/aeson-2.2.0.0" || items[0].Description != "Fast JSON parsing" {
+		t.Errorf("unexpected Atom items: %+v", items)
+	}
+}
+
+func TestParseReleases(t *testing.T) {
+	tests := []struct {
+		ecosystem string
+		data      string
+		want      []string
+	}{
+		{"pypi", pypiRSS, []string{"requests@2.31.0", "zope.interface@6.0"}},
+		{"cpan", cpanRDF, []string{"Moose@2.2201", "Data-Dumper-Concise@v2.23"}},
+		{"hackage", atomFeed, []string{"aeson@2.2.0.0"}},
+		{"composer", `<rss><channel>
+			<item><title>monolog/monolog (3.5.0)</title></item>
+			<item><title>not a release</title></item>
+		</channel></rss>`, []string{"monolog/monolog@3.5.0"}},
+		{"cran", `<rss><channel>
+			<item><title>New package tidyfoo with initial version 0.1.0</title></item>
+			<item><title>Package ggplot2 updated to version 3.5.0 with previous version 3.4.4 dated 2023-10-12</title></item>
+			<item><title>Package oldpkg (with last version 1.0) was removed from CRAN</title></item>
+		</channel></rss>`, []string{"tidyfoo@0.1.0", "ggplot2@3.5.0"}},
+		{"gem", `[{"name":"rails","version":"7.1.2","version_created_at":"2023-11-10T21:50:00.000Z","project_uri":"https://rubygems.org/gems/rails"}]`, []string{"rails@7.1.2"}},
+		{"hex", `[{"name":"phoenix","latest_version":"1.7.10","updated_at":"2023-11-03T18:00:00Z"},{"name":"empty"}]`, []string{"phoenix@1.7.10"}},
+	}
+
+	for _, tt := range tests {
+		releases, err := ParseReleases(tt.ecosystem, []byte(tt.data))
+		if err != nil {
+			t.Errorf("%s: ParseReleases failed: %v", tt.ecosystem, err)
+			continue
+		}
+		if len(releases) != len(tt.want) {
+			t.Errorf("%s: got %d releases, want %d: %+v", tt.ecosystem, len(releases), len(tt.want), releases)
+			continue
+		}
+		for i, r := range releases {
+			if got := r.Name + "@" + r.Version; got != tt.want[i] {
+				t.Errorf("%s: release %d = %s, want %s", tt.ecosystem, i, got, tt.want[i])
+			}
+			if r.Ecosystem != tt.ecosystem {
+				t.Errorf("%s: release has ecosystem %q", tt.ecosystem, r.Ecosystem)
+			}
+		}
+	}
+}
+
+func TestSplitDashVersion(t *testing.T) {
+	tests := []struct{ in, name, version string }{
+		{"aeson-2.2.0.0", "aeson", "2.2.0.0"},
+		{"http-client-tls-0.3.6", "http-client-tls", "0.3.6"},
+		{"Foo-Bar-v1.2", "Foo-Bar", "v1.2"},
+		{"no-version-here", "", ""},
+	}
+	for _, tt := range tests {
+		name, version := splitDashVersion(tt.in)
+		if name != tt.name || version != tt.version {
+			t.Errorf("splitDashVersion(%q) = %q, %q; want %q, %q", tt.in, name, version, tt.name, tt.version)
+		}
+	}
+}
+
+func TestFetchURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(pypiRSS))
+	}))
+	defer server.Close()
+
+	releases, err := FetchURL(context.Background(), client.DefaultClient(), "pypi", server.URL)
+	if err != nil {
+		t.Fatalf("FetchURL failed: %v", err)
+	}
+	if len(releases) != 2 {
+		t.Fatalf("expected 2 releases, got %d", len(releases))
+	}
+	if releases[0].PURL() != "pkg:pypi/requests@2.31.0" {
+		t.Errorf("unexpected PURL %q", releases[0].PURL())
+	}
+
+	if _, err := Fetch(context.Background(), nil, "npm"); err == nil {
+		t.Error("expected an error for an ecosystem without a feed")
+	}
+}
@@ -0,0 +1,213 @@
+package feeds
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/git-pkgs/registries/client"
+)
+
+// NPMReplicateURL is npm's public CouchDB replication endpoint.
+const NPMReplicateURL = "https://replicate.npmjs.com/registry"
+
+// NPMChange is one entry of the npm registry's _changes feed.
+type NPMChange struct {
+	Seq     string   // sequence to resume after this change
+	Name    string   // package name, e.g. "@babel/core"
+	Deleted bool     // the package document was removed
+	Revs    []string // document revisions introduced by the change
+}
+
+// PURL returns the unversioned Package URL of the changed package.
+func (c NPMChange) PURL() string {
+	return "pkg:npm/" + strings.Replace(c.Name, "@", "%40", 1)
+}
+
+// NPMChanges reads the CouchDB _changes feed of an npm registry replica,
+// for mirrors and monitors that need every package update rather than a
+// watched subset. Sequences are opaque strings; store the last one returned
+// and pass it back to resume.
+type NPMChanges struct {
+	baseURL   string
+	client    *client.Client
+	batchSize int
+}
+
+// NewNPMChanges returns a changes reader for the replica at baseURL, or
+// NPMReplicateURL if baseURL is empty. If c is nil, client.DefaultClient()
+// is used.
+func NewNPMChanges(c *client.Client, baseURL string) *NPMChanges {
+	if baseURL == "" {
+		baseURL = NPMReplicateURL
+	}
+	if c == nil {
+		c = client.DefaultClient()
+	}
+	return &NPMChanges{
+		baseURL:   strings.TrimSuffix(baseURL, "/"),
+		client:    c,
+		batchSize: 1000,
+	}
+}
+
+// WithBatchSize returns a copy that requests up to n changes per page.
+func (n *NPMChanges) WithBatchSize(size int) *NPMChanges {
+	copy := *n
+	if size > 0 {
+		copy.batchSize = size
+	}
+	return &copy
+}
+
+type changesResponse struct {
+	Results []struct {
+		Seq     json.RawMessage `json:"seq"`
+		ID      string          `json:"id"`
+		Deleted bool            `json:"deleted"`
+		Changes []struct {
+			Rev string `json:"rev"`
+		} `json:"changes"`
+	} `json:"results"`
+	LastSeq json.RawMessage `json:"last_seq"`
+}
+
+// Since returns up to one batch of changes after since ("0" for the start
+// of the registry, "now" for only future changes) and the sequence to pass
+// as since on the next call.
+func (n *NPMChanges) Since(ctx context.Context, since string) ([]NPMChange, string, error) {
+	if since == "" {
+		since = "0"
+	}
+	u := fmt.Sprintf("%s/_changes?since=%s&limit=%d", n.baseURL, url.QueryEscape(since), n.batchSize)
+
+	var resp changesResponse
+	if err := n.client.GetJSON(ctx, u, &resp); err != nil {
+		return nil, since, err
+	}
+
+	changes := make([]NPMChange, 0, len(resp.Results))
+	for _, r := range resp.Results {
+		// Design documents are CouchDB internals, not packages
+		if strings.HasPrefix(r.ID, "_design/") {
+			continue
+		}
+		revs := make([]string, len(r.Changes))
+		for i, c := range r.Changes {
+			revs[i] = c.Rev
+		}
+		changes = append(changes, NPMChange{
+			Seq:     rawSeq(r.Seq),
+			Name:    r.ID,
+			Deleted: r.Deleted,
+			Revs:    revs,
+		})
+	}
+
+	last := rawSeq(resp.LastSeq)
+	if last == "" {
+		last = since
+	}
+	return changes, last, nil
+}
+
+// rawSeq normalises a CouchDB sequence, which is a number in CouchDB 1.x and
+// an opaque string in later versions.
+func rawSeq(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+	var n json.Number
+	if err := json.Unmarshal(raw, &n); err == nil {
+		return n.String()
+	}
+	return string(raw)
+}
+
+// Stream pages through changes after since until the feed is caught up,
+// calling fn with each non-empty batch and the sequence to checkpoint once
+// the batch is handled. It returns the last sequence reached. If fn returns
+// an error, streaming stops and the sequence before that batch is returned.
+func (n *NPMChanges) Stream(ctx context.Context, since string, fn func(batch []NPMChange, seq string) error) (string, error) {
+	for {
+		changes, next, err := n.Since(ctx, since)
+		if err != nil {
+			return since, err
+		}
+		if len(changes) > 0 {
+			if err := fn(changes, next); err != nil {
+				return since, err
+			}
+		}
+		if next == since || len(changes) == 0 {
+			return next, nil
+		}
+		since = next
+	}
+}
+
+// Follow streams changes like Stream, then keeps checking for new changes
+// every interval until ctx is cancelled. Transient errors are retried on the
+// next interval; errors returned by fn stop following.
+func (n *NPMChanges) Follow(ctx context.Context, since string, interval time.Duration, fn func(batch []NPMChange, seq string) error) error {
+	handlerErr := func(batch []NPMChange, seq string) error {
+		if err := fn(batch, seq); err != nil {
+			return &handlerError{err}
+		}
+		return nil
+	}
+
+	for {
+		next, err := n.Stream(ctx, since, handlerErr)
+		if h, ok := err.(*handlerError); ok {
+			return h.err
+		}
+		since = next
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
+type handlerError struct{ err error }
+
+func (h *handlerError) Error() string { return h.err.Error() }
+
+// parseSeqNumber reports the numeric value of a CouchDB 2+ sequence such as
+// "1234-g1AAAA...", or of a plain numeric sequence.
+func parseSeqNumber(seq string) (int64, bool) {
+	if i := strings.IndexByte(seq, '-'); i > 0 {
+		seq = seq[:i]
+	}
+	n, err := strconv.ParseInt(seq, 10, 64)
+	return n, err == nil
+}
+
+// Lag estimates how many changes the replica has that come after seq, by
+// comparing the numeric prefix of seq with the replica's update_seq. It
+// returns false if either sequence has no numeric prefix.
+func (n *NPMChanges) Lag(ctx context.Context, seq string) (int64, bool, error) {
+	var info struct {
+		UpdateSeq json.RawMessage `json:"update_seq"`
+	}
+	if err := n.client.GetJSON(ctx, n.baseURL+"/", &info); err != nil {
+		return 0, false, err
+	}
+	current, ok1 := parseSeqNumber(rawSeq(info.UpdateSeq))
+	at, ok2 := parseSeqNumber(seq)
+	if !ok1 || !ok2 {
+		return 0, false, nil
+	}
+	return current - at, true, nil
+}
@@ -0,0 +1,150 @@
+package feeds
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/git-pkgs/registries/client"
+)
+
+// changesServer serves a _changes feed of five packages, two per page, with
+// CouchDB 2-style string sequences.
+func changesServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	names := []string{"left-pad", "_design/app", "@babel/core", "lodash", "express"}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/" {
+			_, _ = fmt.Fprint(w, `{"db_name":"registry","update_seq":"12-g1AAAA"}`)
+			return
+		}
+		if r.URL.Path != "/_changes" {
+			http.NotFound(w, r)
+			return
+		}
+		var since int
+		_, _ = fmt.Sscanf(r.URL.Query().Get("since"), "%d", &since)
+		var limit int
+		_, _ = fmt.Sscanf(r.URL.Query().Get("limit"), "%d", &limit)
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprint(w, `{"results":[`)
+		last := since
+		for i := since; i < len(names) && i < since+limit; i++ {
+			if i > since {
+				_, _ = fmt.Fprint(w, ",")
+			}
+			deleted := ""
+			if names[i] == "lodash" {
+				deleted = `,"deleted":true`
+			}
+			_, _ = fmt.Fprintf(w, `{"seq":"%d-g1AAAA","id":%q,"changes":[{"rev":"%d-abc"}]%s}`, i+1, names[i], i+1, deleted)
+			last = i + 1
+		}
+		_, _ = fmt.Fprintf(w, `],"last_seq":"%d-g1AAAA"}`, last)
+	}))
+}
+
+func TestNPMChangesSince(t *testing.T) {
+	server := changesServer(t)
+	defer server.Close()
+
+	n := NewNPMChanges(client.DefaultClient(), server.URL).WithBatchSize(3)
+	changes, next, err := n.Since(context.Background(), "0")
+	if err != nil {
+		t.Fatalf("Since failed: %v", err)
+	}
+
+	// The design document is skipped
+	if len(changes) != 2 {
+		t.Fatalf("expected 2 changes, got %d: %+v", len(changes), changes)
+	}
+	if changes[1].Name != "@babel/core" || changes[1].Seq != "3-g1AAAA" || changes[1].Revs[0] != "3-abc" {
+		t.Errorf("unexpected change: %+v", changes[1])
+	}
+	if got := changes[1].PURL(); got != "pkg:npm/%40babel/core" {
+		t.Errorf("PURL = %q", got)
+	}
+	if next != "3-g1AAAA" {
+		t.Errorf("next = %q, want 3-g1AAAA", next)
+	}
+}
+
+func TestNPMChangesStream(t *testing.T) {
+	server := changesServer(t)
+	defer server.Close()
+
+	n := NewNPMChanges(client.DefaultClient(), server.URL).WithBatchSize(2)
+
+	var names []string
+	var checkpoints []string
+	last, err := n.Stream(context.Background(), "", func(batch []NPMChange, seq string) error {
+		for _, c := range batch {
+			names = append(names, c.Name)
+			if c.Name == "lodash" && !c.Deleted {
+				t.Error("expected lodash to be deleted")
+			}
+		}
+		checkpoints = append(checkpoints, seq)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Stream failed: %v", err)
+	}
+
+	want := []string{"left-pad", "@babel/core", "lodash", "express"}
+	if fmt.Sprint(names) != fmt.Sprint(want) {
+		t.Errorf("names = %v, want %v", names, want)
+	}
+	if last != "5-g1AAAA" {
+		t.Errorf("last = %q, want 5-g1AAAA", last)
+	}
+	if len(checkpoints) == 0 || checkpoints[len(checkpoints)-1] != "5-g1AAAA" {
+		t.Errorf("checkpoints = %v", checkpoints)
+	}
+
+	// Resuming from the checkpoint yields nothing new
+	called := false
+	again, err := n.Stream(context.Background(), last, func([]NPMChange, string) error {
+		called = true
+		return nil
+	})
+	if err != nil || called || again != last {
+		t.Errorf("resume: called=%v seq=%q err=%v", called, again, err)
+	}
+
+	lag, ok, err := n.Lag(context.Background(), "3-g1AAAA")
+	if err != nil || !ok || lag != 9 {
+		t.Errorf("Lag = %d, %v, %v; want 9", lag, ok, err)
+	}
+}
+
+func TestNPMChangesHandlerError(t *testing.T) {
+	server := changesServer(t)
+	defer server.Close()
+
+	n := NewNPMChanges(client.DefaultClient(), server.URL).WithBatchSize(2)
+	stop := fmt.Errorf("stop")
+	calls := 0
+	seq, err := n.Stream(context.Background(), "0", func([]NPMChange, string) error {
+		calls++
+		if calls == 2 {
+			return stop
+		}
+		return nil
+	})
+	if err != stop {
+		t.Fatalf("err = %v, want stop", err)
+	}
+	// The failed batch must be redelivered on resume
+	if seq != "2-g1AAAA" {
+		t.Errorf("seq = %q, want 2-g1AAAA", seq)
+	}
+
+	err = n.Follow(context.Background(), "0", 0, func([]NPMChange, string) error { return stop })
+	if err != stop {
+		t.Errorf("Follow err = %v, want stop", err)
+	}
+}
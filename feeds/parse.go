@@ -0,0 +1,119 @@
+package feeds
+
+import (
+	"encoding/xml"
+	"strings"
+	"time"
+)
+
+// Item is one entry of an RSS or Atom feed.
+type Item struct {
+	Title       string
+	Link        string
+	ID          string
+	Description string
+	Published   time.Time
+}
+
+// xmlFeed decodes RSS 2.0 (<rss><channel><item>), RSS 1.0 (<rdf:RDF><item>)
+// and Atom (<feed><entry>) documents.
+type xmlFeed struct {
+	Channel struct {
+		Items []rssItem `xml:"item"`
+	} `xml:"channel"`
+	Items   []rssItem   `xml:"item"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type rssItem struct {
+	Title       string `xml:"title"`
+	Link        string `xml:"link"`
+	GUID        string `xml:"guid"`
+	Description string `xml:"description"`
+	PubDate     string `xml:"pubDate"`
+	Date        string `xml:"http://purl.org/dc/elements/1.1/ date"`
+}
+
+type atomEntry struct {
+	Title string `xml:"title"`
+	ID    string `xml:"id"`
+	Links []struct {
+		Href string `xml:"href,attr"`
+		Rel  string `xml:"rel,attr"`
+	} `xml:"link"`
+	Summary   string `xml:"summary"`
+	Content   string `xml:"content"`
+	Published string `xml:"published"`
+	Updated   string `xml:"updated"`
+}
+
+// Parse decodes an RSS 2.0, RSS 1.0 or Atom document into items, in
+// document order.
+func Parse(data []byte) ([]Item, error) {
+	var feed xmlFeed
+	if err := xml.Unmarshal(data, &feed); err != nil {
+		return nil, err
+	}
+
+	var items []Item
+	for _, i := range append(feed.Channel.Items, feed.Items...) {
+		date := i.PubDate
+		if date == "" {
+			date = i.Date
+		}
+		items = append(items, Item{
+			Title:       strings.TrimSpace(i.Title),
+			Link:        strings.TrimSpace(i.Link),
+			ID:          strings.TrimSpace(i.GUID),
+			Description: strings.TrimSpace(i.Description),
+			Published:   parseTime(date),
+		})
+	}
+
+	for _, e := range feed.Entries {
+		var link string
+		for _, l := range e.Links {
+			if l.Rel == "" || l.Rel == "alternate" {
+				link = l.Href
+				break
+			}
+		}
+		date := e.Published
+		if date == "" {
+			date = e.Updated
+		}
+		description := e.Summary
+		if description == "" {
+			description = e.Content
+		}
+		items = append(items, Item{
+			Title:       strings.TrimSpace(e.Title),
+			Link:        strings.TrimSpace(link),
+			ID:          strings.TrimSpace(e.ID),
+			Description: strings.TrimSpace(description),
+			Published:   parseTime(date),
+		})
+	}
+
+	return items, nil
+}
+
+var timeLayouts = []string{
+	time.RFC1123Z,
+	time.RFC1123,
+	time.RFC3339,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+}
+
+func parseTime(s string) time.Time {
+	s = strings.TrimSpace(s)
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
@@ -0,0 +1,267 @@
+package feeds
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/git-pkgs/registries/client"
+)
+
+// PyPIXMLRPCURL is PyPI's XML-RPC endpoint.
+const PyPIXMLRPCURL = "https://pypi.org/pypi"
+
+// PyPIAction classifies a PyPI changelog entry.
+type PyPIAction string
+
+const (
+	// ProjectCreated is a newly registered project.
+	ProjectCreated PyPIAction = "project_created"
+	// ProjectRemoved is a deleted project.
+	ProjectRemoved PyPIAction = "project_removed"
+	// ReleaseCreated is a newly published version.
+	ReleaseCreated PyPIAction = "release_created"
+	// ReleaseRemoved is a deleted version.
+	ReleaseRemoved PyPIAction = "release_removed"
+	// ReleaseYanked is a version that was yanked.
+	ReleaseYanked PyPIAction = "release_yanked"
+	// ReleaseUnyanked is a version whose yank was undone.
+	ReleaseUnyanked PyPIAction = "release_unyanked"
+	// FileAdded is a distribution file uploaded to an existing version.
+	FileAdded PyPIAction = "file_added"
+	// FileRemoved is a distribution file that was deleted.
+	FileRemoved PyPIAction = "file_removed"
+	// OtherAction is any other change, such as a role or metadata update.
+	OtherAction PyPIAction = "other"
+)
+
+// PyPIChange is one entry of PyPI's changelog.
+type PyPIChange struct {
+	Name    string
+	Version string // empty for project-level changes
+	Action  PyPIAction
+	Detail  string // PyPI's free-text description, e.g. "add py3 file requests-2.31.0-py3-none-any.whl"
+	Time    time.Time
+	Serial  int64
+}
+
+// PURL returns the Package URL of the changed project, including the version
+// if the change concerns one.
+func (c PyPIChange) PURL() string {
+	p := "pkg:pypi/" + normalizePyPIName(c.Name)
+	if c.Version != "" {
+		p += "@" + c.Version
+	}
+	return p
+}
+
+func normalizePyPIName(name string) string {
+	name = strings.ToLower(name)
+	return strings.NewReplacer("_", "-", ".", "-").Replace(name)
+}
+
+// classifyPyPIAction maps PyPI's free-text action to a PyPIAction.
+func classifyPyPIAction(action string) PyPIAction {
+	switch {
+	case action == "create":
+		return ProjectCreated
+	case action == "remove project":
+		return ProjectRemoved
+	case action == "new release":
+		return ReleaseCreated
+	case action == "remove release":
+		return ReleaseRemoved
+	case action == "yank release":
+		return ReleaseYanked
+	case action == "unyank release":
+		return ReleaseUnyanked
+	case strings.HasPrefix(action, "add ") && strings.Contains(action, " file "):
+		return FileAdded
+	case strings.HasPrefix(action, "remove file "):
+		return FileRemoved
+	default:
+		return OtherAction
+	}
+}
+
+// PyPIChangelog reads PyPI's changelog through the XML-RPC
+// changelog_since_serial method. Every change to the index has a
+// monotonically increasing serial; store the last one seen and pass it back
+// to resume.
+type PyPIChangelog struct {
+	url    string
+	client *client.Client
+}
+
+// NewPyPIChangelog returns a changelog reader for the XML-RPC endpoint at
+// url, or PyPIXMLRPCURL if url is empty. If c is nil,
+// client.DefaultClient() is used. PyPI rate limits XML-RPC heavily, so
+// callers following the changelog should poll no more than once a minute.
+func NewPyPIChangelog(c *client.Client, url string) *PyPIChangelog {
+	if url == "" {
+		url = PyPIXMLRPCURL
+	}
+	if c == nil {
+		c = client.DefaultClient()
+	}
+	return &PyPIChangelog{url: url, client: c}
+}
+
+// LastSerial returns the serial of the most recent change on the index.
+// Use it to start following from now without replaying history.
+func (p *PyPIChangelog) LastSerial(ctx context.Context) (int64, error) {
+	v, err := p.call(ctx, "changelog_last_serial")
+	if err != nil {
+		return 0, err
+	}
+	return v.int()
+}
+
+// Since returns every change after serial, oldest first, and the serial to
+// pass on the next call.
+func (p *PyPIChangelog) Since(ctx context.Context, serial int64) ([]PyPIChange, int64, error) {
+	v, err := p.call(ctx, "changelog_since_serial", serial)
+	if err != nil {
+		return nil, serial, err
+	}
+	if v.Array == nil {
+		return nil, serial, fmt.Errorf("pypi changelog: expected array, got %q", v.text())
+	}
+
+	last := serial
+	changes := make([]PyPIChange, 0, len(v.Array.Values))
+	for _, row := range v.Array.Values {
+		if row.Array == nil || len(row.Array.Values) < 5 {
+			continue
+		}
+		fields := row.Array.Values
+		ts, _ := fields[2].int()
+		s, err := fields[4].int()
+		if err != nil {
+			continue
+		}
+		detail := fields[3].text()
+		changes = append(changes, PyPIChange{
+			Name:    fields[0].text(),
+			Version: fields[1].text(),
+			Action:  classifyPyPIAction(detail),
+			Detail:  detail,
+			Time:    time.Unix(ts, 0).UTC(),
+			Serial:  s,
+		})
+		if s > last {
+			last = s
+		}
+	}
+	return changes, last, nil
+}
+
+// Follow calls fn with each non-empty batch of changes after serial and the
+// serial to checkpoint once it is handled, checking again every interval
+// until ctx is cancelled. Request errors are retried on the next interval;
+// errors returned by fn stop following.
+func (p *PyPIChangelog) Follow(ctx context.Context, serial int64, interval time.Duration, fn func(batch []PyPIChange, serial int64) error) error {
+	for {
+		changes, next, err := p.Since(ctx, serial)
+		if err == nil && len(changes) > 0 {
+			if err := fn(changes, next); err != nil {
+				return err
+			}
+			serial = next
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
+func (p *PyPIChangelog) call(ctx context.Context, method string, params ...int64) (*xmlrpcValue, error) {
+	var buf bytes.Buffer
+	buf.WriteString(`<?xml version="1.0"?><methodCall><methodName>`)
+	_ = xml.EscapeText(&buf, []byte(method))
+	buf.WriteString(`</methodName><params>`)
+	for _, param := range params {
+		fmt.Fprintf(&buf, `<param><value><int>%d</int></value></param>`, param)
+	}
+	buf.WriteString(`</params></methodCall>`)
+
+	body, err := p.client.Post(ctx, p.url, "text/xml", buf.Bytes())
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Params []xmlrpcValue `xml:"params>param>value"`
+		Fault  *xmlrpcValue  `xml:"fault>value"`
+	}
+	if err := xml.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("pypi changelog: decoding response: %w", err)
+	}
+	if resp.Fault != nil {
+		return nil, fmt.Errorf("pypi changelog: %s fault: %s", method, resp.Fault.member("faultString").text())
+	}
+	if len(resp.Params) == 0 {
+		return nil, fmt.Errorf("pypi changelog: %s returned no value", method)
+	}
+	return &resp.Params[0], nil
+}
+
+// xmlrpcValue is the subset of XML-RPC values PyPI returns.
+type xmlrpcValue struct {
+	Int    *string   `xml:"int"`
+	I4     *string   `xml:"i4"`
+	I8     *string   `xml:"i8"`
+	String *string   `xml:"string"`
+	Nil    *struct{} `xml:"nil"`
+	Array  *struct {
+		Values []xmlrpcValue `xml:"data>value"`
+	} `xml:"array"`
+	Struct *struct {
+		Members []struct {
+			Name  string      `xml:"name"`
+			Value xmlrpcValue `xml:"value"`
+		} `xml:"member"`
+	} `xml:"struct"`
+	Chardata string `xml:",chardata"`
+}
+
+// text returns a scalar value as a string. Untyped values are strings.
+func (v *xmlrpcValue) text() string {
+	switch {
+	case v == nil || v.Nil != nil:
+		return ""
+	case v.String != nil:
+		return *v.String
+	case v.Int != nil:
+		return strings.TrimSpace(*v.Int)
+	case v.I4 != nil:
+		return strings.TrimSpace(*v.I4)
+	case v.I8 != nil:
+		return strings.TrimSpace(*v.I8)
+	default:
+		return v.Chardata
+	}
+}
+
+func (v *xmlrpcValue) int() (int64, error) {
+	return strconv.ParseInt(v.text(), 10, 64)
+}
+
+func (v *xmlrpcValue) member(name string) *xmlrpcValue {
+	if v.Struct == nil {
+		return nil
+	}
+	for i := range v.Struct.Members {
+		if v.Struct.Members[i].Name == name {
+			return &v.Struct.Members[i].Value
+		}
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package feeds
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/git-pkgs/registries/client"
+)
+
+const changelogResponse = `<?xml version='1.0'?>
+<methodResponse>
+<params>
+<param>
+<value><array><data>
+<value><array><data>
+<value><string>Flask_Login</string></value>
+<value><string>0.6.3</string></value>
+<value><int>1700000000</int></value>
+<value><string>new release</string></value>
+<value><int>20000001</int></value>
+</data></array></value>
+<value><array><data>
+<value><string>flask-login</string></value>
+<value><string>0.6.3</string></value>
+<value><int>1700000001</int></value>
+<value><string>add py3 file Flask_Login-0.6.3-py3-none-any.whl</string></value>
+<value><int>20000002</int></value>
+</data></array></value>
+<value><array><data>
+<value><string>leftpad</string></value>
+<value><nil/></value>
+<value><int>1700000002</int></value>
+<value><string>remove project</string></value>
+<value><int>20000004</int></value>
+</data></array></value>
+<value><array><data>
+<value><string>requests</string></value>
+<value><string>2.31.0</string></value>
+<value><int>1700000003</int></value>
+<value>yank release</value>
+<value><int>20000003</int></value>
+</data></array></value>
+</data></array></value>
+</param>
+</params>
+</methodResponse>`
+
+func TestPyPIChangelogSince(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		switch {
+		case strings.Contains(string(body), "changelog_since_serial"):
+			if !strings.Contains(string(body), "<int>20000000</int>") {
+				t.Errorf("serial not sent: %s", body)
+			}
+			_, _ = io.WriteString(w, changelogResponse)
+		case strings.Contains(string(body), "changelog_last_serial"):
+			_, _ = io.WriteString(w, `<?xml version='1.0'?><methodResponse><params><param><value><int>20000004</int></value></param></params></methodResponse>`)
+		default:
+			_, _ = io.WriteString(w, `<?xml version='1.0'?><methodResponse><fault><value><struct>
+<member><name>faultCode</name><value><int>-32601</int></value></member>
+<member><name>faultString</name><value><string>method not found</string></value></member>
+</struct></value></fault></methodResponse>`)
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	p := NewPyPIChangelog(client.DefaultClient(), server.URL)
+
+	last, err := p.LastSerial(ctx)
+	if err != nil || last != 20000004 {
+		t.Fatalf("LastSerial = %d, %v", last, err)
+	}
+
+	changes, next, err := p.Since(ctx, 20000000)
+	if err != nil {
+		t.Fatalf("Since failed: %v", err)
+	}
+	if next != 20000004 {
+		t.Errorf("next = %d, want 20000004", next)
+	}
+	if len(changes) != 4 {
+		t.Fatalf("expected 4 changes, got %d", len(changes))
+	}
+
+	want := []struct {
+		action PyPIAction
+		purl   string
+	}{
+		{ReleaseCreated, "pkg:pypi/flask-login@0.6.3"},
+		{FileAdded, "pkg:pypi/flask-login@0.6.3"},
+		{ProjectRemoved, "pkg:pypi/leftpad"},
+		{ReleaseYanked, "pkg:pypi/requests@2.31.0"},
+	}
+	for i, w := range want {
+		if changes[i].Action != w.action || changes[i].PURL() != w.purl {
+			t.Errorf("change %d = %s %s, want %s %s", i, changes[i].Action, changes[i].PURL(), w.action, w.purl)
+		}
+	}
+	if changes[0].Time.Unix() != 1700000000 {
+		t.Errorf("time = %v", changes[0].Time)
+	}
+
+	if _, err := p.call(ctx, "bogus"); err == nil || !strings.Contains(err.Error(), "method not found") {
+		t.Errorf("expected fault error, got %v", err)
+	}
+}
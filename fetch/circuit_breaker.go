@@ -4,10 +4,12 @@ import (
 	"context"
 	"fmt"
 	"net/url"
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/cenk/backoff"
+	"github.com/git-pkgs/registries/client"
 	circuit "github.com/rubyist/circuitbreaker"
 )
 
@@ -120,6 +122,20 @@ func extractRegistry(rawURL string) string {
 	return parsed.Host
 }
 
+// ClientStats returns the underlying fetcher's stats, with the hosts whose
+// circuit breaker is open.
+func (cbf *CircuitBreakerFetcher) ClientStats() client.Stats {
+	stats := cbf.fetcher.ClientStats()
+	stats.OpenCircuits = []string{}
+	for registry, state := range cbf.GetBreakerState() {
+		if state == "open" {
+			stats.OpenCircuits = append(stats.OpenCircuits, registry)
+		}
+	}
+	sort.Strings(stats.OpenCircuits)
+	return stats
+}
+
 // GetBreakerState returns the current state of circuit breakers (for health checks).
 func (cbf *CircuitBreakerFetcher) GetBreakerState() map[string]string {
 	cbf.mu.RLock()
@@ -207,3 +207,24 @@ func TestCircuitBreakerOpensOnFailures(t *testing.T) {
 		t.Logf("Warning: Circuit breaker may not have opened (got %d requests)", failCount)
 	}
 }
+
+func TestCircuitBreakerClientStats(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	cbFetcher := NewCircuitBreakerFetcher(NewFetcher(WithMaxRetries(0)))
+	for range 10 {
+		_, _ = cbFetcher.Fetch(context.Background(), server.URL+"/test")
+	}
+
+	stats := cbFetcher.ClientStats()
+	host := extractRegistry(server.URL)
+	if len(stats.OpenCircuits) != 1 || stats.OpenCircuits[0] != host {
+		t.Errorf("OpenCircuits = %v, want [%s]", stats.OpenCircuits, host)
+	}
+	if n := stats.Requests[http.StatusServiceUnavailable]; n < 5 || n >= 10 {
+		t.Errorf("503s = %d, want the breaker to stop requests after 5", n)
+	}
+}
@@ -0,0 +1,91 @@
+package fetch
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/git-pkgs/registries/client"
+)
+
+// FetchDecompressed downloads a compressed index file, such as conda's
+// repodata.json.zst or Debian's Packages.xz, and returns its decompressed
+// contents as a stream, so large indexes never sit in memory whole.
+//
+// The format is recognised from the data's magic bytes, which also copes
+// with servers that mark .gz files as Content-Encoding: gzip and so have
+// them decoded in transit. Brotli has no magic bytes and is recognised by
+// a .br extension. Anything else is returned unchanged. The returned
+// Artifact's Size is -1 when the body is decompressed.
+func (f *Fetcher) FetchDecompressed(ctx context.Context, url string) (*Artifact, error) {
+	artifact, err := f.Fetch(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	decompressed, err := Decompress(artifact, url)
+	if err != nil {
+		_ = artifact.Body.Close()
+		return nil, fmt.Errorf("decompressing %s: %w", url, err)
+	}
+	return decompressed, nil
+}
+
+// magicBytes identifies compressed formats by their leading bytes.
+var magicBytes = []struct {
+	format string
+	magic  []byte
+}{
+	{"gzip", []byte{0x1f, 0x8b}},
+	{"zstd", []byte{0x28, 0xb5, 0x2f, 0xfd}},
+	{"xz", []byte{0xfd, '7', 'z', 'X', 'Z', 0x00}},
+	{"bzip2", []byte("BZh")},
+}
+
+// Decompress wraps a fetched artifact's body in a streaming decoder, as
+// FetchDecompressed does, for artifacts fetched some other way such as
+// through a CircuitBreakerFetcher. url is only used for its extension.
+func Decompress(artifact *Artifact, url string) (*Artifact, error) {
+	buffered := bufio.NewReader(artifact.Body)
+	head, _ := buffered.Peek(6)
+
+	format := ""
+	for _, m := range magicBytes {
+		if bytes.HasPrefix(head, m.magic) {
+			format = m.format
+			break
+		}
+	}
+	if format == "" && client.CompressionFromPath(url) == "br" {
+		format = "br"
+	}
+	if format == "" {
+		artifact.Body = readCloser{buffered, artifact.Body.Close}
+		return artifact, nil
+	}
+
+	decoder, err := client.Decompress(buffered, format)
+	if err != nil {
+		return nil, err
+	}
+	body := artifact.Body
+	return &Artifact{
+		Body: readCloser{decoder, func() error {
+			_ = decoder.Close()
+			return body.Close()
+		}},
+		Size:        -1,
+		ContentType: artifact.ContentType,
+		ETag:        artifact.ETag,
+	}, nil
+}
+
+type readCloser struct {
+	io.Reader
+	close func() error
+}
+
+func (r readCloser) Close() error {
+	return r.close()
+}
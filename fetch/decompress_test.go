@@ -0,0 +1,61 @@
+package fetch
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+)
+
+func TestFetchDecompressed(t *testing.T) {
+	const index = `{"packages":{}}`
+
+	var zst, xzData, gz bytes.Buffer
+	zw, _ := zstd.NewWriter(&zst)
+	_, _ = zw.Write([]byte(index))
+	_ = zw.Close()
+	xw, _ := xz.NewWriter(&xzData)
+	_, _ = xw.Write([]byte(index))
+	_ = xw.Close()
+	gw := gzip.NewWriter(&gz)
+	_, _ = gw.Write([]byte(index))
+	_ = gw.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repodata.json.zst":
+			_, _ = w.Write(zst.Bytes())
+		case "/Packages.xz":
+			_, _ = w.Write(xzData.Bytes())
+		case "/Packages.gz":
+			// Served as an encoded response, so the transport decodes it
+			w.Header().Set("Content-Encoding", "gzip")
+			_, _ = w.Write(gz.Bytes())
+		case "/repodata.json":
+			_, _ = w.Write([]byte(index))
+		}
+	}))
+	defer server.Close()
+
+	f := NewFetcher()
+	for _, path := range []string{"/repodata.json.zst", "/Packages.xz", "/Packages.gz", "/repodata.json"} {
+		artifact, err := f.FetchDecompressed(context.Background(), server.URL+path)
+		if err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		body, err := io.ReadAll(artifact.Body)
+		_ = artifact.Body.Close()
+		if err != nil {
+			t.Fatalf("%s: reading: %v", path, err)
+		}
+		if string(body) != index {
+			t.Errorf("%s: got %q", path, body)
+		}
+	}
+}
@@ -7,13 +7,12 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"math"
-	"math/rand"
 	"net"
 	"net/http"
 	"strconv"
 	"time"
 
+	"github.com/git-pkgs/registries/client"
 	"github.com/rs/dnscache"
 )
 
@@ -44,6 +43,13 @@ type Fetcher struct {
 	maxRetries int
 	baseDelay  time.Duration
 	authFn     func(url string) (headerName, headerValue string)
+	retry      *client.RetryPolicy
+	clock      client.Clock
+	maxSize    int64
+	stats      *client.StatsRecorder
+
+	connectTimeout time.Duration
+	headerTimeout  time.Duration
 }
 
 // Option configures a Fetcher.
@@ -77,6 +83,23 @@ func WithBaseDelay(d time.Duration) Option {
 	}
 }
 
+// WithRetryPolicy sets the policy for retrying failed downloads, replacing
+// WithMaxRetries and WithBaseDelay. A policy shared with a client.Client
+// shares its per-host retry budget too.
+func WithRetryPolicy(p *client.RetryPolicy) Option {
+	return func(f *Fetcher) {
+		f.retry = p
+	}
+}
+
+// WithClock sets the clock that times the waits between retries, so tests
+// can use a client.FakeClock instead of sleeping.
+func WithClock(clock client.Clock) Option {
+	return func(f *Fetcher) {
+		f.clock = clock
+	}
+}
+
 // WithAuthFunc sets a function that returns auth headers for a given URL.
 // The function receives the request URL and returns a header name and value.
 // Return empty strings to skip authentication for that URL.
@@ -86,6 +109,43 @@ func WithAuthFunc(fn func(url string) (headerName, headerValue string)) Option {
 	}
 }
 
+// WithMaxArtifactSize fails downloads bigger than n bytes with ErrTooLarge:
+// straight away when the Content-Length says so, and otherwise from the
+// Body's Read once n bytes have been read. FetchToFile's WithMaxSize sets a
+// limit for one download.
+func WithMaxArtifactSize(n int64) Option {
+	return func(f *Fetcher) {
+		f.maxSize = n
+	}
+}
+
+// WithConnectTimeout sets how long establishing a connection may take,
+// separately from the timeout for the whole download. It has no effect
+// with WithHTTPClient.
+func WithConnectTimeout(d time.Duration) Option {
+	return func(f *Fetcher) {
+		f.connectTimeout = d
+	}
+}
+
+// WithResponseHeaderTimeout sets how long to wait for the server to start
+// responding once a request is sent, so a stalled upstream fails in seconds
+// rather than at the end of the long download timeout. It has no effect
+// with WithHTTPClient.
+func WithResponseHeaderTimeout(d time.Duration) Option {
+	return func(f *Fetcher) {
+		f.headerTimeout = d
+	}
+}
+
+// WithStatsRecorder counts the fetcher's requests in r, such as one shared
+// with a client.Client, instead of a recorder of its own.
+func WithStatsRecorder(r *client.StatsRecorder) Option {
+	return func(f *Fetcher) {
+		f.stats = r
+	}
+}
+
 // NewFetcher creates a new Fetcher with the given options.
 func NewFetcher(opts ...Option) *Fetcher {
 	// Create DNS cache with 5 minute refresh interval
@@ -135,60 +195,89 @@ func NewFetcher(opts ...Option) *Fetcher {
 		userAgent:  "git-pkgs-proxy/1.0",
 		maxRetries: 3,
 		baseDelay:  500 * time.Millisecond,
+		stats:      client.NewStatsRecorder(),
 	}
+	own := f.client
 	for _, opt := range opts {
 		opt(f)
 	}
+	if f.client == own {
+		if f.connectTimeout > 0 {
+			dialer.Timeout = f.connectTimeout
+		}
+		own.Transport.(*http.Transport).ResponseHeaderTimeout = f.headerTimeout
+	}
 	return f
 }
 
 // Fetch downloads an artifact from the given URL.
 // The caller must close the returned Artifact.Body when done.
 func (f *Fetcher) Fetch(ctx context.Context, url string) (*Artifact, error) {
-	var lastErr error
-
-	for attempt := 0; attempt <= f.maxRetries; attempt++ {
-		if attempt > 0 {
-			// Exponential backoff with 10% jitter to prevent thundering herd
-			delay := f.baseDelay * time.Duration(math.Pow(2, float64(attempt-1)))
-			jitter := time.Duration(float64(delay) * (rand.Float64() * 0.1))
-			delay += jitter
-
-			select {
-			case <-ctx.Done():
-				return nil, ctx.Err()
-			case <-time.After(delay):
-			}
-		}
-
-		artifact, err := f.doFetch(ctx, url)
-		if err == nil {
-			return artifact, nil
-		}
-
-		lastErr = err
+	var artifact *Artifact
+	err := f.retryPolicy().Do(ctx, url, func() client.Attempt {
+		var attempt client.Attempt
+		artifact, attempt = f.doFetch(ctx, url)
+		return attempt
+	})
+	if err != nil {
+		return nil, err
+	}
+	return artifact, nil
+}
 
-		// Don't retry on not found or client errors
-		if errors.Is(err, ErrNotFound) {
-			return nil, err
-		}
+// retryPolicy returns the configured policy, or one built from maxRetries
+// and baseDelay. Unlike client.DefaultRetryable, the default retries only
+// rate limits and server errors; network errors are returned straight away.
+func (f *Fetcher) retryPolicy() *client.RetryPolicy {
+	p := &client.RetryPolicy{
+		MaxRetries: f.maxRetries,
+		BaseDelay:  f.baseDelay,
+		Jitter:     0.1, // prevents a thundering herd
+		Clock:      f.clock,
+		Retryable: func(status int) bool {
+			return status == http.StatusTooManyRequests || status >= 500
+		},
+	}
+	if f.retry != nil {
+		p = f.retry.WithClock(f.clock)
+	}
+	if f.stats != nil {
+		p = f.stats.Observe(p)
+	}
+	return p
+}
 
-		// Retry on rate limit and server errors
-		if errors.Is(err, ErrRateLimited) || errors.Is(err, ErrUpstreamDown) {
-			continue
-		}
+// record counts a response, or a request that got none, for ClientStats.
+func (f *Fetcher) record(url string, resp *http.Response) {
+	if f.stats == nil {
+		return
+	}
+	now := f.now()
+	if resp == nil {
+		f.stats.Record(url, 0, nil, now)
+		return
+	}
+	f.stats.Record(url, resp.StatusCode, resp.Header, now)
+}
 
-		// Don't retry on other errors (network issues will be wrapped)
-		return nil, err
+func (f *Fetcher) now() time.Time {
+	if f.clock != nil {
+		return f.clock.Now()
 	}
+	return client.SystemClock.Now()
+}
 
-	return nil, lastErr
+// ClientStats returns a snapshot of the responses and retries the fetcher
+// has seen, the backoff before retries still waiting, and each host's rate
+// limit as its last response reported it.
+func (f *Fetcher) ClientStats() client.Stats {
+	return f.stats.SnapshotAt(f.now())
 }
 
-func (f *Fetcher) doFetch(ctx context.Context, url string) (*Artifact, error) {
+func (f *Fetcher) doFetch(ctx context.Context, url string) (*Artifact, client.Attempt) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
-		return nil, fmt.Errorf("creating request: %w", err)
+		return nil, client.Attempt{Err: fmt.Errorf("creating request: %w", err)}
 	}
 
 	req.Header.Set("User-Agent", f.userAgent)
@@ -202,8 +291,14 @@ func (f *Fetcher) doFetch(ctx context.Context, url string) (*Artifact, error) {
 	}
 
 	resp, err := f.client.Do(req)
+	f.record(url, resp)
 	if err != nil {
-		return nil, fmt.Errorf("fetching artifact: %w", err)
+		return nil, client.Attempt{Err: fmt.Errorf("fetching artifact: %w", err)}
+	}
+
+	failed := client.Attempt{
+		Status:     resp.StatusCode,
+		RetryAfter: client.ParseRetryAfter(resp.Header.Get("Retry-After")),
 	}
 
 	switch {
@@ -215,30 +310,42 @@ func (f *Fetcher) doFetch(ctx context.Context, url string) (*Artifact, error) {
 			}
 		}
 
+		if f.maxSize > 0 && size > f.maxSize {
+			_ = resp.Body.Close()
+			// Not retried: the artifact won't be any smaller next time
+			failed.Err = fmt.Errorf("%s is %d bytes: %w (limit %d)", url, size, ErrTooLarge, f.maxSize)
+			return nil, failed
+		}
+		var body io.ReadCloser = resp.Body
+		if f.maxSize > 0 {
+			body = &limitedBody{ReadCloser: resp.Body, left: f.maxSize}
+		}
+
 		return &Artifact{
-			Body:        resp.Body,
+			Body:        body,
 			Size:        size,
 			ContentType: resp.Header.Get("Content-Type"),
 			ETag:        resp.Header.Get("ETag"),
-		}, nil
+		}, client.Attempt{}
 
 	case resp.StatusCode == http.StatusNotFound:
 		_ = resp.Body.Close()
-		return nil, ErrNotFound
+		failed.Err = ErrNotFound
 
 	case resp.StatusCode == http.StatusTooManyRequests:
 		_ = resp.Body.Close()
-		return nil, ErrRateLimited
+		failed.Err = ErrRateLimited
 
 	case resp.StatusCode >= 500:
 		_ = resp.Body.Close()
-		return nil, ErrUpstreamDown
+		failed.Err = ErrUpstreamDown
 
 	default:
 		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
 		_ = resp.Body.Close()
-		return nil, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, string(body))
+		failed.Err = fmt.Errorf("unexpected status %d: %s", resp.StatusCode, string(body))
 	}
+	return nil, failed
 }
 
 // Head checks if an artifact exists and returns its metadata without downloading.
@@ -258,6 +365,7 @@ func (f *Fetcher) Head(ctx context.Context, url string) (size int64, contentType
 	}
 
 	resp, err := f.client.Do(req)
+	f.record(url, resp)
 	if err != nil {
 		return 0, "", fmt.Errorf("head request: %w", err)
 	}
@@ -279,3 +387,19 @@ func (f *Fetcher) Head(ctx context.Context, url string) (size int64, contentType
 
 	return size, resp.Header.Get("Content-Type"), nil
 }
+
+// limitedBody fails with ErrTooLarge once more than left bytes have been
+// read from it.
+type limitedBody struct {
+	io.ReadCloser
+	left int64
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.left -= int64(n)
+	if b.left < 0 {
+		return n + int(b.left), ErrTooLarge
+	}
+	return n, err
+}
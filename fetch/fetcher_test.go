@@ -9,6 +9,8 @@ import (
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/git-pkgs/registries/client"
 )
 
 func TestFetchSuccess(t *testing.T) {
@@ -116,7 +118,8 @@ func TestFetchMaxRetries(t *testing.T) {
 	}))
 	defer server.Close()
 
-	f := NewFetcher(WithMaxRetries(2), WithBaseDelay(10*time.Millisecond))
+	clock := client.NewFakeClock(time.Unix(0, 0))
+	f := NewFetcher(WithMaxRetries(2), WithBaseDelay(time.Second), WithClock(clock))
 	_, err := f.Fetch(context.Background(), server.URL+"/test.tgz")
 	if err == nil {
 		t.Error("expected error after max retries")
@@ -129,6 +132,11 @@ func TestFetchMaxRetries(t *testing.T) {
 	if attempts != 3 {
 		t.Errorf("attempts = %d, want 3", attempts)
 	}
+	// Exponential backoff, with up to 10% jitter
+	sleeps := clock.Sleeps()
+	if len(sleeps) != 2 || sleeps[0] < time.Second || sleeps[1] < 2*time.Second || sleeps[1] > 2200*time.Millisecond {
+		t.Errorf("slept %v, want about 1s then 2s", sleeps)
+	}
 }
 
 func TestFetchContextCancellation(t *testing.T) {
@@ -309,3 +317,118 @@ func TestFetchDNSCaching(t *testing.T) {
 		t.Errorf("requestCount = %d, want 3", requestCount)
 	}
 }
+
+func TestFetchRetryPolicy(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		_, _ = w.Write([]byte("success"))
+	}))
+	defer server.Close()
+
+	// Some mirrors answer 403 while an artifact is still replicating
+	f := NewFetcher(WithRetryPolicy(&client.RetryPolicy{
+		MaxRetries: 1,
+		BaseDelay:  time.Millisecond,
+		Retryable:  func(status int) bool { return status == http.StatusForbidden },
+	}))
+	artifact, err := f.Fetch(context.Background(), server.URL+"/test.tgz")
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	defer func() { _ = artifact.Body.Close() }()
+
+	if attempts != 2 {
+		t.Errorf("attempts = %d, want 2", attempts)
+	}
+}
+
+func TestFetchMaxArtifactSize(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path == "/chunked.tgz" {
+			// Flushing before writing everything leaves out Content-Length
+			_, _ = w.Write([]byte("12345"))
+			w.(http.Flusher).Flush()
+		}
+		_, _ = w.Write([]byte("6789012345"))
+	}))
+	defer server.Close()
+
+	f := NewFetcher(WithMaxArtifactSize(8), WithMaxRetries(3), WithBaseDelay(time.Millisecond))
+
+	_, err := f.Fetch(context.Background(), server.URL+"/big.tgz")
+	if !errors.Is(err, ErrTooLarge) {
+		t.Errorf("expected ErrTooLarge from Content-Length, got %v", err)
+	}
+	if requests != 1 {
+		t.Errorf("expected no retries, got %d requests", requests)
+	}
+
+	artifact, err := f.Fetch(context.Background(), server.URL+"/chunked.tgz")
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	defer func() { _ = artifact.Body.Close() }()
+	body, err := io.ReadAll(artifact.Body)
+	if !errors.Is(err, ErrTooLarge) || len(body) != 8 {
+		t.Errorf("expected ErrTooLarge after 8 bytes, got %q, %v", body, err)
+	}
+}
+
+func TestFetchResponseHeaderTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	f := NewFetcher(WithConnectTimeout(time.Second), WithResponseHeaderTimeout(20*time.Millisecond))
+	start := time.Now()
+	if _, err := f.Fetch(context.Background(), server.URL+"/slow.tgz"); err == nil {
+		t.Error("expected a stalled server to fail")
+	}
+	if elapsed := time.Since(start); elapsed > 150*time.Millisecond {
+		t.Errorf("timed out after %v", elapsed)
+	}
+}
+
+func TestFetcherClientStats(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.Header().Set("RateLimit-Remaining", "9")
+		w.Header().Set("RateLimit-Reset", "30")
+		if attempts == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		_, _ = w.Write([]byte("data"))
+	}))
+	defer server.Close()
+
+	clock := client.NewFakeClock(time.Unix(0, 0))
+	recorder := client.NewStatsRecorder()
+	f := NewFetcher(WithBaseDelay(time.Second), WithClock(clock), WithStatsRecorder(recorder))
+	artifact, err := f.Fetch(context.Background(), server.URL+"/test.tgz")
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	_ = artifact.Body.Close()
+
+	stats := f.ClientStats()
+	if stats.Requests[http.StatusTooManyRequests] != 1 || stats.Requests[http.StatusOK] != 1 || stats.Retries != 1 {
+		t.Errorf("stats = %+v", stats)
+	}
+	host := strings.TrimPrefix(server.URL, "http://")
+	if rl := stats.Hosts[host].RateLimit; rl == nil || rl.Remaining != 9 || rl.Limit != -1 || !rl.Reset.Equal(clock.Now().Add(30*time.Second)) {
+		t.Errorf("rate limit = %+v", rl)
+	}
+	if shared := recorder.Snapshot(); shared.Retries != 1 {
+		t.Errorf("shared recorder = %+v", shared)
+	}
+}
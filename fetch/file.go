@@ -0,0 +1,170 @@
+package fetch
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// ErrTooLarge is returned when an artifact is bigger than the limit set with
+// WithMaxSize.
+var ErrTooLarge = errors.New("artifact exceeds size limit")
+
+// FileResult describes an artifact written to disk by FetchToFile.
+type FileResult struct {
+	Path        string
+	Size        int64
+	SHA256      string // hex digest of the written file
+	ContentType string
+	ETag        string
+}
+
+// FileOption configures FetchToFile.
+type FileOption func(*fileOptions)
+
+type fileOptions struct {
+	maxSize  int64
+	progress func(written, total int64)
+	mode     os.FileMode
+}
+
+// WithMaxSize fails the download with ErrTooLarge once it passes n bytes.
+// An artifact whose Content-Length is already too big isn't downloaded.
+func WithMaxSize(n int64) FileOption {
+	return func(o *fileOptions) {
+		o.maxSize = n
+	}
+}
+
+// WithProgress calls fn as data is written, with the bytes written so far
+// and the expected total, which is -1 when the server didn't send one.
+func WithProgress(fn func(written, total int64)) FileOption {
+	return func(o *fileOptions) {
+		o.progress = fn
+	}
+}
+
+// WithFileMode sets the permissions of the written file. The default is
+// 0644.
+func WithFileMode(mode os.FileMode) FileOption {
+	return func(o *fileOptions) {
+		o.mode = mode
+	}
+}
+
+// FetchToFile downloads an artifact to dst, creating its directory if
+// needed. The data is streamed to a temporary file beside dst, synced, and
+// renamed into place only once complete, so dst never holds a partial
+// download and an existing dst is replaced atomically. On failure the
+// temporary file is removed and dst is left as it was.
+func (f *Fetcher) FetchToFile(ctx context.Context, url, dst string, opts ...FileOption) (*FileResult, error) {
+	return fetchToFile(ctx, f, url, dst, opts)
+}
+
+// FetchToFile downloads an artifact to dst through the circuit breaker, as
+// Fetcher.FetchToFile does.
+func (cbf *CircuitBreakerFetcher) FetchToFile(ctx context.Context, url, dst string, opts ...FileOption) (*FileResult, error) {
+	return fetchToFile(ctx, cbf, url, dst, opts)
+}
+
+func fetchToFile(ctx context.Context, f FetcherInterface, url, dst string, opts []FileOption) (*FileResult, error) {
+	o := fileOptions{mode: 0o644}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	artifact, err := f.Fetch(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = artifact.Body.Close() }()
+
+	if o.maxSize > 0 && artifact.Size > o.maxSize {
+		return nil, fmt.Errorf("%s is %d bytes: %w (limit %d)", url, artifact.Size, ErrTooLarge, o.maxSize)
+	}
+
+	dir := filepath.Dir(dst)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(dst)+".*.tmp")
+	if err != nil {
+		return nil, err
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			_ = tmp.Close()
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+
+	w := &progressWriter{
+		w:        tmp,
+		hash:     sha256.New(),
+		total:    artifact.Size,
+		max:      o.maxSize,
+		progress: o.progress,
+	}
+	if _, err := io.Copy(w, artifact.Body); err != nil {
+		if errors.Is(err, ErrTooLarge) {
+			return nil, fmt.Errorf("%s: %w (limit %d)", url, ErrTooLarge, o.maxSize)
+		}
+		return nil, fmt.Errorf("downloading %s: %w", url, err)
+	}
+	if artifact.Size >= 0 && w.written != artifact.Size {
+		return nil, fmt.Errorf("downloading %s: got %d of %d bytes", url, w.written, artifact.Size)
+	}
+
+	if err := tmp.Sync(); err != nil {
+		return nil, err
+	}
+	if err := tmp.Chmod(o.mode); err != nil {
+		return nil, err
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(tmp.Name(), dst); err != nil {
+		return nil, err
+	}
+	committed = true
+
+	return &FileResult{
+		Path:        dst,
+		Size:        w.written,
+		SHA256:      hex.EncodeToString(w.hash.Sum(nil)),
+		ContentType: artifact.ContentType,
+		ETag:        artifact.ETag,
+	}, nil
+}
+
+// progressWriter counts and hashes what passes through it, enforcing the
+// size limit and reporting progress.
+type progressWriter struct {
+	w        io.Writer
+	hash     hash.Hash
+	written  int64
+	total    int64
+	max      int64
+	progress func(written, total int64)
+}
+
+func (p *progressWriter) Write(b []byte) (int, error) {
+	if p.max > 0 && p.written+int64(len(b)) > p.max {
+		return 0, ErrTooLarge
+	}
+	n, err := p.w.Write(b)
+	p.hash.Write(b[:n])
+	p.written += int64(n)
+	if p.progress != nil && n > 0 {
+		p.progress(p.written, p.total)
+	}
+	return n, err
+}
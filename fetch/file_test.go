@@ -0,0 +1,65 @@
+package fetch
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestFetchToFile(t *testing.T) {
+	content := strings.Repeat("artifact", 1000)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Without a Content-Length the limit is only found while streaming
+		if r.URL.Path != "/chunked.tgz" {
+			w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+		}
+		_, _ = w.Write([]byte(content))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	dst := filepath.Join(dir, "cache", "pkg.tgz")
+	var lastWritten, lastTotal int64
+	f := NewFetcher()
+	result, err := f.FetchToFile(context.Background(), server.URL+"/pkg.tgz", dst,
+		WithProgress(func(written, total int64) { lastWritten, lastTotal = written, total }))
+	if err != nil {
+		t.Fatalf("FetchToFile failed: %v", err)
+	}
+
+	data, err := os.ReadFile(dst)
+	if err != nil || string(data) != content {
+		t.Fatalf("file content wrong: %v", err)
+	}
+	sum := sha256.Sum256([]byte(content))
+	if result.SHA256 != hex.EncodeToString(sum[:]) || result.Size != int64(len(content)) {
+		t.Errorf("result = %+v", result)
+	}
+	if lastWritten != int64(len(content)) || lastTotal != int64(len(content)) {
+		t.Errorf("progress ended at %d/%d", lastWritten, lastTotal)
+	}
+
+	// Too large by Content-Length or while streaming: dst is untouched and
+	// no temporary files remain
+	for _, path := range []string{"/pkg.tgz", "/chunked.tgz"} {
+		_, err = f.FetchToFile(context.Background(), server.URL+path, dst, WithMaxSize(100))
+		if !errors.Is(err, ErrTooLarge) {
+			t.Errorf("%s: err = %v, want ErrTooLarge", path, err)
+		}
+	}
+	if data, _ := os.ReadFile(dst); string(data) != content {
+		t.Error("failed download replaced the existing file")
+	}
+	entries, _ := os.ReadDir(filepath.Dir(dst))
+	if len(entries) != 1 {
+		t.Errorf("expected only the completed file, found %d entries", len(entries))
+	}
+}
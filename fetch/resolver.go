@@ -44,7 +44,7 @@ func (r *Resolver) RegisterRegistry(reg Registry) {
 type ArtifactInfo struct {
 	URL       string
 	Filename  string
-	Integrity string // sha256-... or sha512-...
+	Integrity string // SRI, e.g. sha256-<base64>
 }
 
 // Resolve returns the download URL and filename for a package artifact.
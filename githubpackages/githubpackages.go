@@ -0,0 +1,161 @@
+// Package githubpackages configures registry clients for GitHub Packages,
+// which serves npm, Maven, NuGet and RubyGems packages from per-type hosts
+// and requires authentication even for public packages.
+//
+//	gh := githubpackages.New(nil, "octo-org", os.Getenv("GITHUB_TOKEN"),
+//		githubpackages.WithRepository("octo-repo"))
+//	reg, err := gh.Registry("npm")
+//	pkg, err := reg.FetchPackage(ctx, "@octo-org/widgets")
+//
+// The authenticated client from Client also works with PURLs that name a
+// GitHub Packages host in their repository_url qualifier.
+package githubpackages
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/git-pkgs/registries"
+	"github.com/git-pkgs/registries/client"
+)
+
+// Base URLs of the GitHub Packages registries on github.com.
+const (
+	NPMURL      = "https://npm.pkg.github.com"
+	MavenURL    = "https://maven.pkg.github.com"
+	NuGetURL    = "https://nuget.pkg.github.com"
+	RubyGemsURL = "https://rubygems.pkg.github.com"
+)
+
+// ErrNoToken is returned when no token was given and GITHUB_TOKEN is unset.
+// GitHub Packages rejects anonymous requests, even for public packages.
+var ErrNoToken = errors.New("githubpackages: a token is required")
+
+// Ecosystems lists the package types GitHub Packages serves that this
+// module has clients for. Container images (ghcr.io) are not supported.
+func Ecosystems() []string {
+	return []string{"gem", "maven", "npm", "nuget"}
+}
+
+// Packages builds authenticated registry clients for one GitHub owner.
+type Packages struct {
+	owner      string
+	token      string
+	username   string
+	repository string
+	graphqlURL string
+	client     *client.Client
+}
+
+// Option configures Packages.
+type Option func(*Packages)
+
+// WithRepository sets the repository for Maven, whose GitHub Packages URLs
+// are per repository ("https://maven.pkg.github.com/OWNER/REPOSITORY").
+func WithRepository(repo string) Option {
+	return func(p *Packages) {
+		p.repository = repo
+	}
+}
+
+// WithUsername sets the user name sent with the token as HTTP Basic
+// credentials to the Maven, NuGet and RubyGems registries. The default is
+// the owner.
+func WithUsername(username string) Option {
+	return func(p *Packages) {
+		p.username = username
+	}
+}
+
+// New returns Packages for the user or organisation owner. An empty token
+// falls back to the GITHUB_TOKEN environment variable. If c is nil,
+// client.DefaultClient() is used; its AuthFunc still applies to requests
+// to other hosts.
+func New(c *client.Client, owner, token string, opts ...Option) *Packages {
+	if c == nil {
+		c = client.DefaultClient()
+	}
+	if token == "" {
+		token = os.Getenv("GITHUB_TOKEN")
+	}
+	p := &Packages{
+		owner:      owner,
+		token:      token,
+		username:   owner,
+		graphqlURL: GraphQLURL,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	p.client = c.WithAuthFunc(p.authFunc(c.AuthFunc))
+	return p
+}
+
+// URL returns the registry base URL for an ecosystem.
+func (p *Packages) URL(ecosystem string) (string, error) {
+	switch ecosystem {
+	case "npm":
+		return NPMURL, nil
+	case "maven":
+		if p.repository == "" {
+			return "", errors.New("githubpackages: maven needs a repository, see WithRepository")
+		}
+		return fmt.Sprintf("%s/%s/%s", MavenURL, p.owner, p.repository), nil
+	case "nuget":
+		// GitHub's NuGet layout differs from nuget.org's, so the client
+		// discovers it from the service index
+		return fmt.Sprintf("%s/%s/index.json", NuGetURL, p.owner), nil
+	case "gem":
+		return fmt.Sprintf("%s/%s", RubyGemsURL, p.owner), nil
+	default:
+		return "", fmt.Errorf("githubpackages: unsupported ecosystem %q", ecosystem)
+	}
+}
+
+// Registry returns an authenticated client for an ecosystem's GitHub
+// Packages registry.
+func (p *Packages) Registry(ecosystem string) (registries.Registry, error) {
+	if p.token == "" {
+		return nil, ErrNoToken
+	}
+	baseURL, err := p.URL(ecosystem)
+	if err != nil {
+		return nil, err
+	}
+	return registries.New(ecosystem, baseURL, p.client)
+}
+
+// Client returns the client Registry uses, which sends the token to GitHub
+// Packages hosts and the GraphQL API and nowhere else.
+func (p *Packages) Client() *client.Client {
+	return p.client
+}
+
+// authFunc sends the token to GitHub Packages hosts and defers to next for
+// any other URL. npm and the GraphQL API take it as a bearer token; the
+// other registries expect HTTP Basic credentials, as their package managers
+// send them.
+func (p *Packages) authFunc(next func(string) (string, string)) func(string) (string, string) {
+	basic := "Basic " + base64.StdEncoding.EncodeToString([]byte(p.username+":"+p.token))
+	return func(url string) (string, string) {
+		if p.token != "" {
+			switch {
+			case underURL(url, NPMURL), url == p.graphqlURL:
+				return "Authorization", "Bearer " + p.token
+			case underURL(url, MavenURL), underURL(url, NuGetURL), underURL(url, RubyGemsURL):
+				return "Authorization", basic
+			}
+		}
+		if next != nil {
+			return next(url)
+		}
+		return "", ""
+	}
+}
+
+func underURL(url, base string) bool {
+	return url == base || strings.HasPrefix(url, base+"/")
+}
@@ -0,0 +1,236 @@
+package githubpackages
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/git-pkgs/registries"
+	_ "github.com/git-pkgs/registries/all"
+	"github.com/git-pkgs/registries/client"
+)
+
+// fakeGitHub answers requests for every host in-process and records the
+// Authorization header each request carried.
+type fakeGitHub struct {
+	mu      sync.Mutex
+	auth    map[string]string // host+path -> Authorization
+	handler http.Handler
+}
+
+func (f *fakeGitHub) RoundTrip(req *http.Request) (*http.Response, error) {
+	f.mu.Lock()
+	f.auth[req.URL.Host+req.URL.Path] = req.Header.Get("Authorization")
+	f.mu.Unlock()
+	rec := httptest.NewRecorder()
+	f.handler.ServeHTTP(rec, req)
+	return rec.Result(), nil
+}
+
+func newFake(handler http.HandlerFunc) (*fakeGitHub, *client.Client) {
+	f := &fakeGitHub{auth: make(map[string]string), handler: handler}
+	c := client.DefaultClient()
+	c.HTTPClient = &http.Client{Transport: f}
+	c.MaxRetries = 0
+	return f, c
+}
+
+func TestURL(t *testing.T) {
+	gh := New(nil, "octo-org", "token", WithRepository("octo-repo"))
+
+	tests := map[string]string{
+		"npm":   "https://npm.pkg.github.com",
+		"maven": "https://maven.pkg.github.com/octo-org/octo-repo",
+		"nuget": "https://nuget.pkg.github.com/octo-org/index.json",
+		"gem":   "https://rubygems.pkg.github.com/octo-org",
+	}
+	for ecosystem, want := range tests {
+		got, err := gh.URL(ecosystem)
+		if err != nil || got != want {
+			t.Errorf("URL(%q) = %q, %v; want %q", ecosystem, got, err, want)
+		}
+	}
+
+	if _, err := New(nil, "octo-org", "token").URL("maven"); err == nil {
+		t.Error("expected an error for maven without a repository")
+	}
+	if _, err := gh.URL("pypi"); err == nil {
+		t.Error("expected an error for an unsupported ecosystem")
+	}
+}
+
+func TestRegistryRequiresToken(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "")
+	if _, err := New(nil, "octo-org", "").Registry("npm"); !errors.Is(err, ErrNoToken) {
+		t.Errorf("expected ErrNoToken, got %v", err)
+	}
+
+	t.Setenv("GITHUB_TOKEN", "from-env")
+	if _, err := New(nil, "octo-org", "").Registry("npm"); err != nil {
+		t.Errorf("expected GITHUB_TOKEN to be used, got %v", err)
+	}
+}
+
+func TestNPM(t *testing.T) {
+	fake, c := newFake(func(w http.ResponseWriter, r *http.Request) {
+		if r.Host != "npm.pkg.github.com" {
+			w.WriteHeader(404)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"name":"@octo-org/widgets","description":"Widgets","dist-tags":{"latest":"1.0.0"},"versions":{"1.0.0":{"name":"@octo-org/widgets","version":"1.0.0"}}}`))
+	})
+	c.AuthFunc = func(url string) (string, string) { return "X-Other", "kept" }
+
+	reg, err := New(c, "octo-org", "secret").Registry("npm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg, err := reg.FetchPackage(context.Background(), "@octo-org/widgets")
+	if err != nil {
+		t.Fatalf("FetchPackage failed: %v", err)
+	}
+	if pkg.Description != "Widgets" {
+		t.Errorf("unexpected package: %+v", pkg)
+	}
+
+	for url, auth := range fake.auth {
+		if auth != "Bearer secret" {
+			t.Errorf("%s: expected bearer token, got %q", url, auth)
+		}
+	}
+
+	gh := New(c, "octo-org", "secret")
+	if name, value := gh.Client().AuthFunc("https://registry.npmjs.org/left-pad"); name != "X-Other" || value != "kept" {
+		t.Errorf("expected other hosts to use the original AuthFunc, got %q %q", name, value)
+	}
+}
+
+func TestMaven(t *testing.T) {
+	fake, c := newFake(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/octo-org/octo-repo/com/octo/lib/maven-metadata.xml":
+			_, _ = w.Write([]byte(`<metadata><groupId>com.octo</groupId><artifactId>lib</artifactId><versioning><latest>1.1.0</latest><versions><version>1.0.0</version><version>1.1.0</version></versions></versioning></metadata>`))
+		default:
+			w.WriteHeader(404)
+		}
+	})
+
+	reg, err := New(c, "octo-org", "secret", WithRepository("octo-repo"), WithUsername("octocat")).Registry("maven")
+	if err != nil {
+		t.Fatal(err)
+	}
+	versions, err := registries.FetchVersions(context.Background(), reg, "com.octo:lib")
+	if err != nil {
+		t.Fatalf("FetchVersions failed: %v", err)
+	}
+	if len(versions) != 2 {
+		t.Errorf("expected 2 versions, got %d", len(versions))
+	}
+
+	want := "Basic " + base64.StdEncoding.EncodeToString([]byte("octocat:secret"))
+	for url, auth := range fake.auth {
+		if strings.HasPrefix(url, "search.maven.org") {
+			t.Errorf("unexpected request to Central's search: %s", url)
+		}
+		if auth != want {
+			t.Errorf("%s: expected basic credentials, got %q", url, auth)
+		}
+	}
+}
+
+func TestNuGet(t *testing.T) {
+	_, c := newFake(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/octo-org/index.json":
+			_, _ = w.Write([]byte(`{"resources":[{"@id":"https://nuget.pkg.github.com/octo-org/query","@type":"RegistrationsBaseUrl"}]}`))
+		case "/octo-org/query/octo.lib/index.json":
+			_, _ = w.Write([]byte(`{"items":[{"items":[{"catalogEntry":{"id":"Octo.Lib","version":"2.0.0","listed":true}}]}]}`))
+		default:
+			w.WriteHeader(404)
+		}
+	})
+
+	reg, err := New(c, "octo-org", "secret").Registry("nuget")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg, err := reg.FetchPackage(context.Background(), "Octo.Lib")
+	if err != nil {
+		t.Fatalf("FetchPackage failed: %v", err)
+	}
+	if pkg.Name != "Octo.Lib" {
+		t.Errorf("unexpected package: %+v", pkg)
+	}
+}
+
+func TestFetchPackageVersions(t *testing.T) {
+	var variables []map[string]any
+	f, c := newFake(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Variables map[string]any `json:"variables"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		variables = append(variables, req.Variables)
+		switch {
+		case req.Variables["name"] == "missing":
+			_, _ = w.Write([]byte(`{"data": {"repositoryOwner": {"packages": {"nodes": []}}}}`))
+		case req.Variables["after"] == nil:
+			_, _ = w.Write([]byte(`{"data": {"repositoryOwner": {"packages": {"nodes": [{"versions": {
+				"pageInfo": {"hasNextPage": true, "endCursor": "c1"},
+				"nodes": [{"id": "PV_2", "version": "1.1.0", "statistics": {"downloadsTotalCount": 7},
+					"files": {"nodes": [{"name": "widgets-1.1.0.tgz", "size": 1024, "sha256": "abc", "updatedAt": "2024-05-01T12:00:00Z"}]}}]}}]}}}}`))
+		default:
+			_, _ = w.Write([]byte(`{"data": {"repositoryOwner": {"packages": {"nodes": [{"versions": {
+				"pageInfo": {"hasNextPage": false},
+				"nodes": [{"id": "PV_1", "version": "1.0.0-beta", "preRelease": true, "statistics": {"downloadsTotalCount": 2}, "files": {"nodes": []}}]}}]}}}}`))
+		}
+	})
+	gh := New(c, "octo-org", "ghp_token")
+
+	versions, err := gh.FetchPackageVersions(context.Background(), "npm", "@octo-org/widgets")
+	if err != nil {
+		t.Fatalf("FetchPackageVersions failed: %v", err)
+	}
+	if len(versions) != 2 || versions[0].Downloads != 7 || len(versions[0].Files) != 1 || versions[0].Files[0].Size != 1024 || !versions[1].PreRelease {
+		t.Errorf("versions = %+v", versions)
+	}
+	if v := variables[0]; v["owner"] != "octo-org" || v["name"] != "widgets" || v["type"] != "NPM" {
+		t.Errorf("variables = %v", v)
+	}
+	if got := f.auth["api.github.com/graphql"]; got != "Bearer ghp_token" {
+		t.Errorf("GraphQL Authorization = %q", got)
+	}
+
+	_, err = gh.FetchPackageVersions(context.Background(), "maven", "missing")
+	if !errors.Is(err, registries.ErrNotFound) {
+		t.Errorf("missing package err = %v, want ErrNotFound", err)
+	}
+	t.Setenv("GITHUB_TOKEN", "")
+	if _, err := New(c, "octo-org", "").FetchPackageVersions(context.Background(), "npm", "widgets"); !errors.Is(err, ErrNoToken) {
+		t.Errorf("no token err = %v, want ErrNoToken", err)
+	}
+	if _, err := gh.FetchPackageVersions(context.Background(), "pypi", "widgets"); err == nil {
+		t.Error("expected an error for an ecosystem GitHub Packages lacks")
+	}
+}
+
+func TestGraphQLName(t *testing.T) {
+	tests := []struct{ ecosystem, name, want string }{
+		{"npm", "@octo-org/widgets", "widgets"},
+		{"maven", "com.octo:lib", "com.octo.lib"},
+		{"gem", "octo_gem", "octo_gem"},
+	}
+	for _, tt := range tests {
+		if got := graphqlName(tt.ecosystem, tt.name); got != tt.want {
+			t.Errorf("graphqlName(%q, %q) = %q, want %q", tt.ecosystem, tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package githubpackages
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/git-pkgs/registries"
+)
+
+// GraphQLURL is GitHub's GraphQL API. GitHub Enterprise Server serves it at
+// https://HOSTNAME/api/graphql; see WithGraphQLURL.
+const GraphQLURL = "https://api.github.com/graphql"
+
+// The package registries themselves only speak each package manager's
+// protocol, which has no download counts and no record of the files
+// GitHub holds for a version. GitHub's GraphQL API has both. It doesn't
+// list packages that use granular permissions, such as those published
+// after GitHub moved npm to them, so those are reported as not found.
+// https://docs.github.com/en/graphql/reference/objects#package
+
+// WithGraphQLURL sets the GraphQL endpoint FetchPackageVersions queries,
+// for GitHub Enterprise Server.
+func WithGraphQLURL(url string) Option {
+	return func(p *Packages) {
+		p.graphqlURL = url
+	}
+}
+
+// PackageVersion is a version of a package as GitHub's GraphQL API
+// describes it.
+type PackageVersion struct {
+	ID         string        `json:"id"`
+	Version    string        `json:"version"`
+	PreRelease bool          `json:"pre_release,omitempty"`
+	Platform   string        `json:"platform,omitempty"`
+	Summary    string        `json:"summary,omitempty"`
+	Downloads  int64         `json:"downloads"`
+	Files      []PackageFile `json:"files,omitempty"`
+}
+
+// PackageFile is a file stored for a package version, such as a tarball,
+// POM or nupkg.
+type PackageFile struct {
+	Name      string    `json:"name"`
+	Size      int64     `json:"size,omitempty"`
+	SHA256    string    `json:"sha256,omitempty"`
+	URL       string    `json:"url,omitempty"`
+	UpdatedAt time.Time `json:"updated_at,omitzero"`
+}
+
+// graphqlTypes maps ecosystems to GitHub's PackageType enum.
+var graphqlTypes = map[string]string{
+	"npm":   "NPM",
+	"maven": "MAVEN",
+	"nuget": "NUGET",
+	"gem":   "RUBYGEMS",
+}
+
+const versionsQuery = `query($owner: String!, $name: String!, $type: PackageType!, $after: String) {
+  repositoryOwner(login: $owner) {
+    ... on PackageOwner {
+      packages(first: 1, names: [$name], packageType: $type) {
+        nodes {
+          versions(first: 100, after: $after) {
+            pageInfo { hasNextPage endCursor }
+            nodes {
+              id
+              version
+              preRelease
+              platform
+              summary
+              statistics { downloadsTotalCount }
+              files(first: 100) { nodes { name size sha256 url updatedAt } }
+            }
+          }
+        }
+      }
+    }
+  }
+}`
+
+type versionsResponse struct {
+	RepositoryOwner *struct {
+		Packages struct {
+			Nodes []struct {
+				Versions struct {
+					PageInfo struct {
+						HasNextPage bool   `json:"hasNextPage"`
+						EndCursor   string `json:"endCursor"`
+					} `json:"pageInfo"`
+					Nodes []struct {
+						ID         string `json:"id"`
+						Version    string `json:"version"`
+						PreRelease bool   `json:"preRelease"`
+						Platform   string `json:"platform"`
+						Summary    string `json:"summary"`
+						Statistics *struct {
+							DownloadsTotalCount int64 `json:"downloadsTotalCount"`
+						} `json:"statistics"`
+						Files struct {
+							Nodes []struct {
+								Name      string    `json:"name"`
+								Size      int64     `json:"size"`
+								SHA256    string    `json:"sha256"`
+								URL       string    `json:"url"`
+								UpdatedAt time.Time `json:"updatedAt"`
+							} `json:"nodes"`
+						} `json:"files"`
+					} `json:"nodes"`
+				} `json:"versions"`
+			} `json:"nodes"`
+		} `json:"packages"`
+	} `json:"repositoryOwner"`
+}
+
+// FetchPackageVersions lists a package's versions with their download
+// counts and files through GitHub's GraphQL API. name is as the registry
+// knows it, such as "@octo-org/widgets" for npm or "com.octo:lib" for
+// Maven. Packages GraphQL can't see return an error wrapping
+// registries.ErrNotFound.
+func (p *Packages) FetchPackageVersions(ctx context.Context, ecosystem, name string) ([]PackageVersion, error) {
+	if p.token == "" {
+		return nil, ErrNoToken
+	}
+	packageType, ok := graphqlTypes[ecosystem]
+	if !ok {
+		return nil, fmt.Errorf("githubpackages: unsupported ecosystem %q", ecosystem)
+	}
+	variables := map[string]any{
+		"owner": p.owner,
+		"name":  graphqlName(ecosystem, name),
+		"type":  packageType,
+	}
+
+	var versions []PackageVersion
+	for {
+		var resp versionsResponse
+		if err := p.client.GraphQL(ctx, p.graphqlURL, versionsQuery, variables, &resp); err != nil {
+			return nil, err
+		}
+		if resp.RepositoryOwner == nil || len(resp.RepositoryOwner.Packages.Nodes) == 0 {
+			return nil, &registries.NotFoundError{Ecosystem: ecosystem, Name: name}
+		}
+		page := resp.RepositoryOwner.Packages.Nodes[0].Versions
+		for _, n := range page.Nodes {
+			v := PackageVersion{
+				ID:         n.ID,
+				Version:    n.Version,
+				PreRelease: n.PreRelease,
+				Platform:   n.Platform,
+				Summary:    n.Summary,
+			}
+			if n.Statistics != nil {
+				v.Downloads = n.Statistics.DownloadsTotalCount
+			}
+			for _, f := range n.Files.Nodes {
+				v.Files = append(v.Files, PackageFile{Name: f.Name, Size: f.Size, SHA256: f.SHA256, URL: f.URL, UpdatedAt: f.UpdatedAt})
+			}
+			versions = append(versions, v)
+		}
+		if !page.PageInfo.HasNextPage {
+			return versions, nil
+		}
+		variables["after"] = page.PageInfo.EndCursor
+	}
+}
+
+// graphqlName converts a registry package name to the name GitHub gives
+// the package: npm packages drop their scope, which is always the owner,
+// and Maven packages are named groupId.artifactId.
+func graphqlName(ecosystem, name string) string {
+	switch ecosystem {
+	case "npm":
+		if strings.HasPrefix(name, "@") {
+			if _, rest, ok := strings.Cut(name, "/"); ok {
+				return rest
+			}
+		}
+	case "maven":
+		return strings.Replace(name, ":", ".", 1)
+	}
+	return name
+}
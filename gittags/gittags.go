@@ -0,0 +1,34 @@
+// Package gittags constructs clients for repository tags on GitHub, GitLab,
+// Gitea, Bitbucket or any git host.
+//
+//	reg, err := gittags.New("", nil) // https://github.com
+//	pkg, err := reg.FetchPackage(ctx, name)
+package gittags
+
+import (
+	"github.com/git-pkgs/registries/client"
+	"github.com/git-pkgs/registries/internal/core"
+	impl "github.com/git-pkgs/registries/internal/gittags"
+)
+
+// DefaultURL is the registry New uses when given no base URL.
+const DefaultURL = impl.DefaultURL
+
+// Base URLs of the other hosts New recognises. Self-hosted GitLab, Gitea
+// and Bitbucket instances are detected from their host names.
+const (
+	GitLabURL    = impl.GitLabURL
+	BitbucketURL = impl.BitbucketURL
+)
+
+// Registry is the client New returns. It implements registries.Registry and
+// the optional interfaces the ecosystem supports.
+type Registry = impl.Registry
+
+// New returns a client for the registry at baseURL, or DefaultURL if
+// baseURL is empty. A nil client uses client.DefaultClient. A baseURL that
+// fails registries.ValidateURL returns an error wrapping
+// registries.ErrInvalidURL.
+func New(baseURL string, c *client.Client) (*Registry, error) {
+	return core.Construct("gittags", baseURL, c, impl.New)
+}
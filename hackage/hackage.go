@@ -0,0 +1,31 @@
+// Package hackage constructs clients for Hackage.
+//
+//	reg, err := hackage.New("", nil) // https://hackage.haskell.org
+//	pkg, err := reg.FetchPackage(ctx, name)
+package hackage
+
+import (
+	"github.com/git-pkgs/registries/client"
+	"github.com/git-pkgs/registries/internal/core"
+	impl "github.com/git-pkgs/registries/internal/hackage"
+)
+
+// DefaultURL is the registry New uses when given no base URL.
+const DefaultURL = impl.DefaultURL
+
+// Registry is the client New returns. It implements registries.Registry and
+// the optional interfaces the ecosystem supports.
+type Registry = impl.Registry
+
+// Flag is a cabal flag, as listed in a package's "flags" metadata.
+// Dependencies under flag(name) conditions list them in their
+// "conditions" metadata.
+type Flag = impl.Flag
+
+// New returns a client for the registry at baseURL, or DefaultURL if
+// baseURL is empty. A nil client uses client.DefaultClient. A baseURL that
+// fails registries.ValidateURL returns an error wrapping
+// registries.ErrInvalidURL.
+func New(baseURL string, c *client.Client) (*Registry, error) {
+	return core.Construct("hackage", baseURL, c, impl.New)
+}
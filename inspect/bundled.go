@@ -0,0 +1,109 @@
+package inspect
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/git-pkgs/registries/fetch"
+)
+
+// BundledPackage is a package shipped inside an npm tarball's node_modules,
+// as a package with bundleDependencies is published.
+type BundledPackage struct {
+	Name    string
+	Version string
+	Path    string // directory in the package, e.g. "node_modules/a/node_modules/b"
+}
+
+// ReadBundled lists the packages under node_modules in a gzipped npm
+// tarball, from the package.json of each. Bundled dependencies of bundled
+// packages are included, with their nested Path. Unlike Read it reads the
+// whole archive. The packages are sorted by Path.
+func ReadBundled(r io.Reader) ([]BundledPackage, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = gz.Close() }()
+
+	var bundled []BundledPackage
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !hdr.FileInfo().Mode().IsRegular() {
+			continue
+		}
+		dir, ok := bundledDir(strings.TrimPrefix(hdr.Name, "./"))
+		if !ok {
+			continue
+		}
+		data, err := io.ReadAll(io.LimitReader(tr, maxManifestSize))
+		if err != nil {
+			return nil, err
+		}
+		var pkg struct {
+			Name    string `json:"name"`
+			Version string `json:"version"`
+		}
+		// A bundled package.json that doesn't parse is still listed, so
+		// the package isn't missed; its name comes from the directory.
+		_ = json.Unmarshal(data, &pkg)
+		if pkg.Name == "" {
+			pkg.Name = strings.TrimPrefix(dir[strings.LastIndex(dir, "node_modules/"):], "node_modules/")
+		}
+		bundled = append(bundled, BundledPackage{Name: pkg.Name, Version: pkg.Version, Path: dir})
+	}
+	sort.Slice(bundled, func(i, j int) bool { return bundled[i].Path < bundled[j].Path })
+	return bundled, nil
+}
+
+// FetchBundled downloads an npm tarball with f and lists its bundled
+// packages.
+func FetchBundled(ctx context.Context, f fetch.FetcherInterface, url string) ([]BundledPackage, error) {
+	artifact, err := f.Fetch(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = artifact.Body.Close() }()
+	return ReadBundled(artifact.Body)
+}
+
+// bundledDir returns the package directory of a node_modules package.json
+// in an npm tarball, without the tarball's top-level directory. Other
+// package.json files, such as one under a bundled package's lib/, don't
+// match.
+func bundledDir(name string) (string, bool) {
+	_, rest, ok := strings.Cut(name, "/")
+	if !ok || path.Base(rest) != "package.json" {
+		return "", false
+	}
+	parts := strings.Split(path.Dir(rest), "/")
+	if len(parts) < 2 {
+		return "", false
+	}
+	for i := 0; i < len(parts); {
+		if parts[i] != "node_modules" || i+1 >= len(parts) {
+			return "", false
+		}
+		i += 2
+		if strings.HasPrefix(parts[i-1], "@") {
+			if i >= len(parts) {
+				return "", false
+			}
+			i++
+		}
+	}
+	return path.Dir(rest), true
+}
@@ -0,0 +1,102 @@
+package inspect
+
+import (
+	"sort"
+
+	"github.com/BurntSushi/toml"
+	"github.com/git-pkgs/registries"
+)
+
+// parseCargo reads Cargo.toml. Crates hold the normalized manifest cargo
+// writes when publishing, with workspace inheritance already resolved.
+func parseCargo(data []byte) (*Manifest, error) {
+	var raw map[string]any
+	if err := toml.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	pkg, _ := raw["package"].(map[string]any)
+
+	m := &Manifest{
+		Ecosystem:   "cargo",
+		Name:        stringField(pkg, "name"),
+		Version:     stringField(pkg, "version"),
+		Description: stringField(pkg, "description"),
+		Homepage:    stringField(pkg, "homepage"),
+		Repository:  stringField(pkg, "repository"),
+		Licenses:    stringField(pkg, "license"),
+		Authors:     stringList(pkg["authors"]),
+		Keywords:    stringList(pkg["keywords"]),
+		Metadata:    make(map[string]any),
+	}
+	for _, key := range []string{"categories", "documentation", "edition", "rust-version", "license-file", "readme", "links"} {
+		if v, ok := pkg[key]; ok {
+			m.Metadata[key] = v
+		}
+	}
+	if features, ok := raw["features"]; ok {
+		m.Metadata["features"] = features
+	}
+
+	m.Dependencies = cargoDependencies(raw, "")
+	if targets, ok := raw["target"].(map[string]any); ok {
+		names := make([]string, 0, len(targets))
+		for name := range targets {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, target := range names {
+			if t, ok := targets[target].(map[string]any); ok {
+				m.Dependencies = append(m.Dependencies, cargoDependencies(t, target)...)
+			}
+		}
+	}
+	return m, nil
+}
+
+// cargoDependencies reads the dependency tables of a manifest or of one of
+// its [target.'cfg(...)'] sections.
+func cargoDependencies(tables map[string]any, target string) []registries.Dependency {
+	var deps []registries.Dependency
+	for _, section := range []struct {
+		key   string
+		scope registries.Scope
+	}{
+		{"dependencies", registries.Runtime},
+		{"dev-dependencies", registries.Development},
+		{"build-dependencies", registries.Build},
+	} {
+		table, _ := tables[section.key].(map[string]any)
+		names := make([]string, 0, len(table))
+		for name := range table {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			dep := registries.Dependency{Name: name, Scope: section.scope, Target: target}
+			switch spec := table[name].(type) {
+			case string:
+				dep.Requirements = spec
+			case map[string]any:
+				dep.Requirements = stringField(spec, "version")
+				dep.Optional, _ = spec["optional"].(bool)
+				meta := make(map[string]any)
+				if pkg := stringField(spec, "package"); pkg != "" {
+					// The table key is the name the crate is used under
+					meta["rename"] = name
+					dep.Name = pkg
+				}
+				for _, key := range []string{"features", "default-features", "registry", "git", "path"} {
+					if v, ok := spec[key]; ok {
+						meta[key] = v
+					}
+				}
+				if len(meta) > 0 {
+					dep.Metadata = meta
+				}
+			}
+			deps = append(deps, dep)
+		}
+	}
+	return deps
+}
@@ -0,0 +1,136 @@
+package inspect
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/git-pkgs/registries"
+	"gopkg.in/yaml.v3"
+)
+
+// gemspec is the YAML form of a Gem::Specification, as stored in a .gem's
+// metadata.gz. Its Ruby type tags are ignored.
+type gemspec struct {
+	Name         string            `yaml:"name"`
+	Version      gemVersion        `yaml:"version"`
+	Platform     string            `yaml:"platform"`
+	Summary      string            `yaml:"summary"`
+	Description  string            `yaml:"description"`
+	Homepage     string            `yaml:"homepage"`
+	Authors      []string          `yaml:"authors"`
+	Licenses     []string          `yaml:"licenses"`
+	Dependencies []gemDependency   `yaml:"dependencies"`
+	Metadata     map[string]string `yaml:"metadata"`
+	Date         string            `yaml:"date"`
+
+	RequiredRubyVersion     gemRequirement `yaml:"required_ruby_version"`
+	RequiredRubygemsVersion gemRequirement `yaml:"required_rubygems_version"`
+	Executables             []string       `yaml:"executables"`
+	Extensions              []string       `yaml:"extensions"`
+}
+
+type gemDependency struct {
+	Name        string         `yaml:"name"`
+	Requirement gemRequirement `yaml:"requirement"`
+	Type        string         `yaml:"type"`
+}
+
+// gemVersion is a Gem::Version, a mapping with a version key, or a plain
+// string in hand-written specs.
+type gemVersion string
+
+func (v *gemVersion) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*v = gemVersion(node.Value)
+		return nil
+	}
+	var m struct {
+		Version string `yaml:"version"`
+	}
+	if err := node.Decode(&m); err != nil {
+		return err
+	}
+	*v = gemVersion(m.Version)
+	return nil
+}
+
+// gemRequirement is a Gem::Requirement, a list of [operator, version] pairs.
+type gemRequirement struct {
+	Requirements [][]yaml.Node `yaml:"requirements"`
+}
+
+// String formats the requirement as rubygems does, e.g. ">= 1.2, < 2".
+func (r gemRequirement) String() string {
+	var parts []string
+	for _, pair := range r.Requirements {
+		if len(pair) != 2 {
+			continue
+		}
+		var v gemVersion
+		if err := pair[1].Decode(&v); err != nil {
+			continue
+		}
+		parts = append(parts, pair[0].Value+" "+string(v))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// parseGem reads a gemspec.
+func parseGem(data []byte) (*Manifest, error) {
+	var spec gemspec
+	if err := yaml.Unmarshal(data, &spec); err != nil {
+		return nil, err
+	}
+	if spec.Name == "" {
+		return nil, fmt.Errorf("gemspec has no name")
+	}
+
+	m := &Manifest{
+		Ecosystem:   "gem",
+		Name:        spec.Name,
+		Version:     string(spec.Version),
+		Description: spec.Summary,
+		Homepage:    spec.Homepage,
+		Repository:  spec.Metadata["source_code_uri"],
+		Licenses:    strings.Join(spec.Licenses, ","),
+		Authors:     spec.Authors,
+		Metadata:    make(map[string]any),
+	}
+	for _, d := range spec.Dependencies {
+		scope := registries.Runtime
+		if strings.TrimPrefix(d.Type, ":") == "development" {
+			scope = registries.Development
+		}
+		m.Dependencies = append(m.Dependencies, registries.Dependency{
+			Name:         d.Name,
+			Requirements: d.Requirement.String(),
+			Scope:        scope,
+		})
+	}
+
+	if spec.Platform != "" && spec.Platform != "ruby" {
+		m.Metadata["platform"] = spec.Platform
+	}
+	if spec.Description != "" {
+		m.Metadata["description"] = spec.Description
+	}
+	if len(spec.Metadata) > 0 {
+		m.Metadata["metadata"] = spec.Metadata
+	}
+	if r := spec.RequiredRubyVersion.String(); r != "" {
+		m.Metadata["required_ruby_version"] = r
+	}
+	if r := spec.RequiredRubygemsVersion.String(); r != "" {
+		m.Metadata["required_rubygems_version"] = r
+	}
+	if len(spec.Executables) > 0 {
+		m.Metadata["executables"] = spec.Executables
+	}
+	if len(spec.Extensions) > 0 {
+		m.Metadata["extensions"] = spec.Extensions
+	}
+	if spec.Date != "" {
+		m.Metadata["date"] = spec.Date
+	}
+	return m, nil
+}
@@ -0,0 +1,263 @@
+// Package inspect reads the manifest a package carries inside its published
+// artifact: package.json in an npm tarball, Cargo.toml in a .crate, METADATA
+// in a wheel or PKG-INFO in an sdist, the .nuspec in a .nupkg, and the
+// gemspec in a .gem. Registry APIs sometimes leave out data the manifest has,
+// such as dependencies of old or yanked versions.
+//
+// Only the manifest is read. Tarballs are streamed and stop at the manifest;
+// zip files are read through their central directory.
+//
+//	artifact, err := fetcher.Fetch(ctx, "https://static.crates.io/crates/serde/serde-1.0.0.crate")
+//	if err != nil {
+//		return err
+//	}
+//	defer artifact.Body.Close()
+//	m, err := inspect.Read(artifact.Body, "serde-1.0.0.crate")
+//	// m.Name, m.Version, m.Dependencies ...
+package inspect
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/git-pkgs/registries"
+	"github.com/git-pkgs/registries/fetch"
+)
+
+// ErrNoManifest is returned when an artifact doesn't contain a manifest in
+// a recognised place.
+var ErrNoManifest = errors.New("no manifest found in artifact")
+
+// maxManifestSize caps how much of a manifest is read, so a hostile archive
+// can't claim a manifest of many gigabytes.
+const maxManifestSize = 16 << 20
+
+// maxZipSize caps how much of a zip file is copied to a temporary file, so
+// a hostile or oversized wheel or nupkg can't fill the disk. It allows the
+// largest wheels on PyPI, such as GPU builds of machine learning libraries.
+var maxZipSize int64 = 4 << 30
+
+// Manifest is the metadata read from a package's own manifest file.
+type Manifest struct {
+	Ecosystem    string // "npm", "cargo", "pypi", "nuget" or "gem"
+	Path         string // path of the manifest inside the artifact
+	Name         string
+	Version      string
+	Description  string
+	Homepage     string
+	Repository   string
+	Licenses     string // as declared, not normalized
+	Authors      []string
+	Keywords     []string
+	Dependencies []registries.Dependency
+	Metadata     map[string]any // other fields, by manifest key
+	Raw          []byte         // the manifest as stored, decompressed
+}
+
+type parser func(data []byte) (*Manifest, error)
+
+// ReadFile reads the manifest from the artifact at path. The format is
+// worked out from the file name.
+func ReadFile(filename string) (*Manifest, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+	return Read(f, filename)
+}
+
+// Read reads the manifest from an artifact. filename is the artifact's file
+// name or URL, from which the archive format is worked out: .tgz, .tar.gz
+// and .crate are gzipped tarballs, .gem is a plain tarball, and .whl,
+// .nupkg and .zip are zip files. Zip files need random access, so unless r
+// is an *os.File they are first copied to a temporary file; one over 4 GiB
+// fails with fetch.ErrTooLarge.
+func Read(r io.Reader, filename string) (*Manifest, error) {
+	name := strings.ToLower(filename)
+	if i := strings.IndexAny(name, "?#"); i >= 0 {
+		name = name[:i]
+	}
+
+	switch {
+	case strings.HasSuffix(name, ".gem"):
+		return readTar(r, gemManifest)
+	case strings.HasSuffix(name, ".tgz"), strings.HasSuffix(name, ".tar.gz"), strings.HasSuffix(name, ".crate"):
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		defer func() { _ = gz.Close() }()
+		return readTar(gz, tarballManifest)
+	case strings.HasSuffix(name, ".whl"), strings.HasSuffix(name, ".nupkg"), strings.HasSuffix(name, ".zip"):
+		return readZip(r)
+	default:
+		return nil, fmt.Errorf("unrecognised artifact format: %s", path.Base(name))
+	}
+}
+
+// Fetch downloads an artifact with f and reads its manifest.
+func Fetch(ctx context.Context, f fetch.FetcherInterface, url string) (*Manifest, error) {
+	artifact, err := f.Fetch(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = artifact.Body.Close() }()
+	return Read(artifact.Body, url)
+}
+
+// tarballManifest recognises the manifests of npm tarballs, crates and
+// sdists, which all sit in a single top-level directory.
+func tarballManifest(name string) parser {
+	dir, file := path.Split(name)
+	if strings.Count(strings.Trim(dir, "/"), "/") != 0 || dir == "" {
+		return nil
+	}
+	switch file {
+	case "package.json":
+		return parseNpm
+	case "Cargo.toml":
+		return parseCargo
+	case "PKG-INFO":
+		return parsePyPI
+	}
+	return nil
+}
+
+// gemManifest recognises the gemspec in a .gem, which holds it as
+// metadata.gz beside the data.tar.gz of files.
+func gemManifest(name string) parser {
+	switch name {
+	case "metadata.gz":
+		return func(data []byte) (*Manifest, error) {
+			gz, err := gzip.NewReader(bytes.NewReader(data))
+			if err != nil {
+				return nil, err
+			}
+			defer func() { _ = gz.Close() }()
+			spec, err := io.ReadAll(io.LimitReader(gz, maxManifestSize))
+			if err != nil {
+				return nil, err
+			}
+			m, err := parseGem(spec)
+			if err != nil {
+				return nil, err
+			}
+			m.Raw = spec
+			return m, nil
+		}
+	case "metadata":
+		return parseGem
+	}
+	return nil
+}
+
+// zipManifest recognises a wheel's METADATA, a .nupkg's .nuspec and a
+// zipped sdist's PKG-INFO.
+func zipManifest(name string) parser {
+	dir, file := path.Split(name)
+	switch {
+	case dir == "" && strings.HasSuffix(strings.ToLower(file), ".nuspec"):
+		return parseNuspec
+	case file == "METADATA" && strings.HasSuffix(dir, ".dist-info/") && strings.Count(dir, "/") == 1:
+		return parsePyPI
+	case file == "PKG-INFO" && strings.Count(dir, "/") == 1:
+		return parsePyPI
+	}
+	return nil
+}
+
+func readTar(r io.Reader, match func(name string) parser) (*Manifest, error) {
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil, ErrNoManifest
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !hdr.FileInfo().Mode().IsRegular() {
+			continue
+		}
+		name := strings.TrimPrefix(hdr.Name, "./")
+		parse := match(name)
+		if parse == nil {
+			continue
+		}
+		data, err := io.ReadAll(io.LimitReader(tr, maxManifestSize))
+		if err != nil {
+			return nil, err
+		}
+		return finish(parse, name, data)
+	}
+}
+
+func readZip(r io.Reader) (*Manifest, error) {
+	f, ok := r.(*os.File)
+	if !ok {
+		tmp, err := os.CreateTemp("", "inspect-*.zip")
+		if err != nil {
+			return nil, err
+		}
+		defer func() {
+			_ = tmp.Close()
+			_ = os.Remove(tmp.Name())
+		}()
+		n, err := io.Copy(tmp, io.LimitReader(r, maxZipSize+1))
+		if err != nil {
+			return nil, err
+		}
+		if n > maxZipSize {
+			return nil, fmt.Errorf("zip file: %w (limit %d)", fetch.ErrTooLarge, maxZipSize)
+		}
+		f = tmp
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	zr, err := zip.NewReader(f, info.Size())
+	if err != nil {
+		return nil, err
+	}
+
+	for _, file := range zr.File {
+		parse := zipManifest(file.Name)
+		if parse == nil {
+			continue
+		}
+		rc, err := file.Open()
+		if err != nil {
+			return nil, err
+		}
+		data, err := io.ReadAll(io.LimitReader(rc, maxManifestSize))
+		_ = rc.Close()
+		if err != nil {
+			return nil, err
+		}
+		return finish(parse, file.Name, data)
+	}
+	return nil, ErrNoManifest
+}
+
+func finish(parse parser, name string, data []byte) (*Manifest, error) {
+	m, err := parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", name, err)
+	}
+	m.Path = name
+	if m.Raw == nil {
+		m.Raw = data
+	}
+	return m, nil
+}
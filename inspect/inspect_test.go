@@ -0,0 +1,385 @@
+package inspect
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/git-pkgs/registries"
+	"github.com/git-pkgs/registries/fetch"
+)
+
+type file struct {
+	name string
+	data string
+}
+
+func tarball(t *testing.T, gzipped bool, files ...file) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	var tw *tar.Writer
+	var gz *gzip.Writer
+	if gzipped {
+		gz = gzip.NewWriter(&buf)
+		tw = tar.NewWriter(gz)
+	} else {
+		tw = tar.NewWriter(&buf)
+	}
+	for _, f := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: f.name, Mode: 0o644, Size: int64(len(f.data)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		_, _ = tw.Write([]byte(f.data))
+	}
+	_ = tw.Close()
+	if gz != nil {
+		_ = gz.Close()
+	}
+	return buf.Bytes()
+}
+
+func zipped(t *testing.T, files ...file) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, f := range files {
+		w, err := zw.Create(f.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = w.Write([]byte(f.data))
+	}
+	_ = zw.Close()
+	return buf.Bytes()
+}
+
+func gzipData(s string) string {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	_, _ = gz.Write([]byte(s))
+	_ = gz.Close()
+	return buf.String()
+}
+
+func findDep(m *Manifest, name string) *registries.Dependency {
+	for i := range m.Dependencies {
+		if m.Dependencies[i].Name == name {
+			return &m.Dependencies[i]
+		}
+	}
+	return nil
+}
+
+func TestReadNpm(t *testing.T) {
+	data := tarball(t, true,
+		file{"package/README.md", "# left-pad"},
+		file{"package/node_modules/dep/package.json", `{"name":"dep"}`},
+		file{"package/package.json", `{
+			"name": "left-pad", "version": "1.3.0", "license": "WTFPL",
+			"author": {"name": "azer", "email": "azer@example.com"},
+			"repository": {"type": "git", "url": "git+https://github.com/stevemao/left-pad.git"},
+			"dependencies": {"b": "^2.0.0", "a": "~1.0.0"},
+			"devDependencies": {"tape": "*"},
+			"scripts": {"install": "node build.js"}
+		}`},
+	)
+	m, err := Read(bytes.NewReader(data), "left-pad-1.3.0.tgz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Ecosystem != "npm" || m.Name != "left-pad" || m.Version != "1.3.0" || m.Path != "package/package.json" {
+		t.Errorf("manifest = %+v", m)
+	}
+	if m.Licenses != "WTFPL" || m.Repository != "git+https://github.com/stevemao/left-pad.git" {
+		t.Errorf("license %q, repository %q", m.Licenses, m.Repository)
+	}
+	if len(m.Authors) != 1 || m.Authors[0] != "azer <azer@example.com>" {
+		t.Errorf("authors = %v", m.Authors)
+	}
+	if len(m.Dependencies) != 3 || m.Dependencies[0].Name != "a" || m.Dependencies[2].Scope != registries.Development {
+		t.Errorf("dependencies = %+v", m.Dependencies)
+	}
+	if _, ok := m.Metadata["scripts"]; !ok {
+		t.Error("expected scripts in metadata")
+	}
+}
+
+func TestReadCrate(t *testing.T) {
+	data := tarball(t, true, file{"serde-1.0.0/Cargo.toml", `
+[package]
+name = "serde"
+version = "1.0.0"
+authors = ["Erick Tryzelaar <erick.tryzelaar@gmail.com>", "David Tolnay <dtolnay@gmail.com>"]
+description = """
+A generic serialization/deserialization framework"""
+license = "MIT/Apache-2.0"
+repository = "https://github.com/serde-rs/serde"
+keywords = ["serde", "serialization", "no_std"]
+
+[dependencies.serde_derive]
+version = "1.0"
+optional = true
+
+[dependencies.core2]
+version = "0.3"
+package = "core-error"
+
+[dev-dependencies.serde_derive]
+version = "1.0"
+
+[target.'cfg(windows)'.dependencies]
+winapi = "0.3"
+
+[features]
+derive = ["serde_derive"]
+`})
+	m, err := Read(bytes.NewReader(data), "serde-1.0.0.crate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Ecosystem != "cargo" || m.Name != "serde" || m.Version != "1.0.0" || m.Licenses != "MIT/Apache-2.0" {
+		t.Errorf("manifest = %+v", m)
+	}
+	if m.Description != "A generic serialization/deserialization framework" || len(m.Authors) != 2 {
+		t.Errorf("description %q, authors %v", m.Description, m.Authors)
+	}
+	if d := findDep(m, "core-error"); d == nil || d.Metadata["rename"] != "core2" {
+		t.Errorf("renamed dependency = %+v", d)
+	}
+	if d := findDep(m, "winapi"); d == nil || d.Target != "cfg(windows)" || d.Requirements != "0.3" {
+		t.Errorf("target dependency = %+v", d)
+	}
+	if d := findDep(m, "serde_derive"); d == nil || !d.Optional || d.Scope != registries.Runtime {
+		t.Errorf("optional dependency = %+v", d)
+	}
+	if _, ok := m.Metadata["features"]; !ok {
+		t.Error("expected features in metadata")
+	}
+}
+
+const wheelMetadata = `Metadata-Version: 2.1
+Name: requests
+Version: 2.31.0
+Summary: Python HTTP for Humans.
+Home-page: https://requests.readthedocs.io
+Author: Kenneth Reitz
+Author-email: me@kennethreitz.org
+License: Apache 2.0
+Project-URL: Source, https://github.com/psf/requests
+Requires-Python: >=3.7
+Requires-Dist: charset-normalizer (<4,>=2)
+Requires-Dist: urllib3 (<3,>=1.21.1)
+Requires-Dist: PySocks (!=1.5.7,>=1.5.6) ; extra == 'socks'
+
+# Requests
+`
+
+func TestReadWheelAndSdist(t *testing.T) {
+	wheel := zipped(t,
+		file{"requests/__init__.py", ""},
+		file{"requests-2.31.0.dist-info/METADATA", wheelMetadata},
+	)
+	sdist := tarball(t, true,
+		file{"requests-2.31.0/setup.py", ""},
+		file{"requests-2.31.0/PKG-INFO", wheelMetadata},
+	)
+
+	for filename, data := range map[string][]byte{
+		"requests-2.31.0-py3-none-any.whl": wheel,
+		"requests-2.31.0.tar.gz":           sdist,
+	} {
+		m, err := Read(bytes.NewReader(data), filename)
+		if err != nil {
+			t.Fatalf("%s: %v", filename, err)
+		}
+		if m.Ecosystem != "pypi" || m.Name != "requests" || m.Version != "2.31.0" || m.Licenses != "Apache 2.0" {
+			t.Errorf("%s: manifest = %+v", filename, m)
+		}
+		if m.Repository != "https://github.com/psf/requests" || m.Homepage != "https://requests.readthedocs.io" {
+			t.Errorf("%s: repository %q homepage %q", filename, m.Repository, m.Homepage)
+		}
+		if len(m.Dependencies) != 3 {
+			t.Fatalf("%s: dependencies = %+v", filename, m.Dependencies)
+		}
+		if d := findDep(m, "PySocks"); d == nil || d.Extra != "socks" || !d.Optional {
+			t.Errorf("%s: extra dependency = %+v", filename, d)
+		}
+		if m.Metadata["long-description"] != "# Requests" {
+			t.Errorf("%s: long description %q", filename, m.Metadata["long-description"])
+		}
+	}
+}
+
+func TestReadNupkg(t *testing.T) {
+	data := zipped(t,
+		file{"lib/net45/Newtonsoft.Json.dll", ""},
+		file{"Newtonsoft.Json.nuspec", "\xef\xbb\xbf" + `<?xml version="1.0" encoding="utf-8"?>
+<package xmlns="http://schemas.microsoft.com/packaging/2013/05/nuspec.xsd">
+  <metadata minClientVersion="2.12">
+    <id>Newtonsoft.Json</id>
+    <version>13.0.1</version>
+    <authors>James Newton-King</authors>
+    <license type="expression">MIT</license>
+    <projectUrl>https://www.newtonsoft.com/json</projectUrl>
+    <description>Json.NET is a popular high-performance JSON framework for .NET</description>
+    <tags>json</tags>
+    <repository type="git" url="https://github.com/JamesNK/Newtonsoft.Json.git" commit="ae9fe44" />
+    <dependencies>
+      <group targetFramework=".NETStandard1.0">
+        <dependency id="System.Runtime.Serialization.Primitives" version="4.3.0" exclude="Build,Analyzers" />
+      </group>
+    </dependencies>
+  </metadata>
+</package>`},
+	)
+	m, err := Read(bytes.NewReader(data), "newtonsoft.json.13.0.1.nupkg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Ecosystem != "nuget" || m.Name != "Newtonsoft.Json" || m.Licenses != "MIT" || m.Repository != "https://github.com/JamesNK/Newtonsoft.Json.git" {
+		t.Errorf("manifest = %+v", m)
+	}
+	if len(m.Dependencies) != 1 || m.Dependencies[0].Target != ".NETStandard1.0" || m.Dependencies[0].Requirements != "4.3.0" {
+		t.Errorf("dependencies = %+v", m.Dependencies)
+	}
+}
+
+const rakeSpec = `--- !ruby/object:Gem::Specification
+name: rake
+version: !ruby/object:Gem::Version
+  version: 13.0.6
+platform: ruby
+authors:
+- Hiroshi SHIBATA
+- Eric Hodel
+autorequire:
+bindir: exe
+date: 2021-07-09 00:00:00.000000000 Z
+dependencies:
+- !ruby/object:Gem::Dependency
+  name: minitest
+  requirement: !ruby/object:Gem::Requirement
+    requirements:
+    - - ">="
+      - !ruby/object:Gem::Version
+        version: '5.0'
+    - - "<"
+      - !ruby/object:Gem::Version
+        version: '6'
+  type: :development
+  prerelease: false
+  version_requirements: !ruby/object:Gem::Requirement
+    requirements:
+    - - ">="
+      - !ruby/object:Gem::Version
+        version: '5.0'
+description: Rake is a Make-like program implemented in Ruby.
+homepage: https://github.com/ruby/rake
+licenses:
+- MIT
+metadata:
+  source_code_uri: https://github.com/ruby/rake/tree/v13.0.6
+required_ruby_version: !ruby/object:Gem::Requirement
+  requirements:
+  - - ">="
+    - !ruby/object:Gem::Version
+      version: '2.2'
+summary: Rake is a Make-like program implemented in Ruby
+`
+
+func TestReadGem(t *testing.T) {
+	data := tarball(t, false,
+		file{"metadata.gz", gzipData(rakeSpec)},
+		file{"data.tar.gz", "not read"},
+	)
+	m, err := Read(bytes.NewReader(data), "rake-13.0.6.gem")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Ecosystem != "gem" || m.Name != "rake" || m.Version != "13.0.6" || m.Licenses != "MIT" {
+		t.Errorf("manifest = %+v", m)
+	}
+	if m.Repository != "https://github.com/ruby/rake/tree/v13.0.6" || string(m.Raw) != rakeSpec {
+		t.Errorf("repository %q, raw spec not decompressed", m.Repository)
+	}
+	if len(m.Dependencies) != 1 || m.Dependencies[0].Requirements != ">= 5.0, < 6" || m.Dependencies[0].Scope != registries.Development {
+		t.Errorf("dependencies = %+v", m.Dependencies)
+	}
+	if m.Metadata["required_ruby_version"] != ">= 2.2" {
+		t.Errorf("required_ruby_version = %v", m.Metadata["required_ruby_version"])
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	data := tarball(t, true, file{"package/index.js", ""})
+	if _, err := Read(bytes.NewReader(data), "x-1.0.0.tgz"); !errors.Is(err, ErrNoManifest) {
+		t.Errorf("err = %v, want ErrNoManifest", err)
+	}
+	if _, err := Read(bytes.NewReader(nil), "x-1.0.0.rpm"); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
+
+func TestReadZipTooLarge(t *testing.T) {
+	data := zipped(t, file{"requests-2.31.0.dist-info/METADATA", wheelMetadata})
+	defer func(n int64) { maxZipSize = n }(maxZipSize)
+	maxZipSize = int64(len(data)) - 1
+
+	if _, err := Read(bytes.NewReader(data), "requests-2.31.0-py3-none-any.whl"); !errors.Is(err, fetch.ErrTooLarge) {
+		t.Errorf("err = %v, want ErrTooLarge", err)
+	}
+	maxZipSize = int64(len(data))
+	if _, err := Read(bytes.NewReader(data), "requests-2.31.0-py3-none-any.whl"); err != nil {
+		t.Errorf("a zip at the limit failed: %v", err)
+	}
+}
+
+func TestFetch(t *testing.T) {
+	data := tarball(t, true, file{"package/package.json", `{"name":"a","version":"1.0.0"}`})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(data)
+	}))
+	defer server.Close()
+
+	m, err := Fetch(context.Background(), fetch.NewFetcher(), server.URL+"/a/-/a-1.0.0.tgz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Name != "a" || m.Version != "1.0.0" {
+		t.Errorf("manifest = %+v", m)
+	}
+}
+
+func TestReadBundled(t *testing.T) {
+	data := tarball(t, true,
+		file{"package/package.json", `{"name": "app", "version": "1.0.0"}`},
+		file{"package/node_modules/b/package.json", `{"name": "b", "version": "2.0.0"}`},
+		file{"package/node_modules/b/test/fixtures/package.json", `{"name": "fixture"}`},
+		file{"package/node_modules/@scope/a/package.json", `{"name": "@scope/a", "version": "1.1.0"}`},
+		file{"package/node_modules/b/node_modules/c/package.json", `not json`},
+		file{"package/node_modules/package.json", `{}`},
+	)
+	bundled, err := ReadBundled(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []BundledPackage{
+		{"@scope/a", "1.1.0", "node_modules/@scope/a"},
+		{"b", "2.0.0", "node_modules/b"},
+		{"c", "", "node_modules/b/node_modules/c"},
+	}
+	if len(bundled) != len(want) {
+		t.Fatalf("bundled = %+v", bundled)
+	}
+	for i := range want {
+		if bundled[i] != want[i] {
+			t.Errorf("bundled[%d] = %+v, want %+v", i, bundled[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package inspect
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/git-pkgs/registries"
+)
+
+// parseNpm reads package.json.
+func parseNpm(data []byte) (*Manifest, error) {
+	var raw map[string]any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	m := &Manifest{
+		Ecosystem:   "npm",
+		Name:        stringField(raw, "name"),
+		Version:     stringField(raw, "version"),
+		Description: stringField(raw, "description"),
+		Homepage:    stringField(raw, "homepage"),
+		Repository:  npmRepository(raw["repository"]),
+		Licenses:    npmLicense(raw),
+		Keywords:    stringList(raw["keywords"]),
+		Metadata:    make(map[string]any),
+	}
+	if author := npmPerson(raw["author"]); author != "" {
+		m.Authors = append(m.Authors, author)
+	}
+	for _, c := range asList(raw["contributors"]) {
+		if person := npmPerson(c); person != "" {
+			m.Authors = append(m.Authors, person)
+		}
+	}
+
+	scopes := []struct {
+		key      string
+		scope    registries.Scope
+		optional bool
+	}{
+		{"dependencies", registries.Runtime, false},
+		{"devDependencies", registries.Development, false},
+		{"peerDependencies", registries.Runtime, false},
+		{"optionalDependencies", registries.Optional, true},
+	}
+	for _, s := range scopes {
+		deps, _ := raw[s.key].(map[string]any)
+		names := make([]string, 0, len(deps))
+		for name := range deps {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			req, _ := deps[name].(string)
+			dep := registries.Dependency{Name: name, Requirements: req, Scope: s.scope, Optional: s.optional}
+			if s.key == "peerDependencies" {
+				dep.Metadata = map[string]any{"peer": true}
+			}
+			m.Dependencies = append(m.Dependencies, dep)
+		}
+	}
+
+	for _, key := range []string{"main", "module", "types", "bin", "scripts", "engines", "os", "cpu", "bundleDependencies", "bundledDependencies", "funding", "type", "exports"} {
+		if v, ok := raw[key]; ok {
+			m.Metadata[key] = v
+		}
+	}
+	return m, nil
+}
+
+// npmPerson formats an author given as a string or as {name, email}.
+func npmPerson(v any) string {
+	switch p := v.(type) {
+	case string:
+		return strings.TrimSpace(p)
+	case map[string]any:
+		name, _ := p["name"].(string)
+		if email, _ := p["email"].(string); email != "" {
+			return strings.TrimSpace(name + " <" + email + ">")
+		}
+		return name
+	}
+	return ""
+}
+
+func npmRepository(v any) string {
+	switch r := v.(type) {
+	case string:
+		return r
+	case map[string]any:
+		url, _ := r["url"].(string)
+		return url
+	}
+	return ""
+}
+
+// npmLicense reads license, or the deprecated licenses list.
+func npmLicense(raw map[string]any) string {
+	switch l := raw["license"].(type) {
+	case string:
+		return l
+	case map[string]any:
+		t, _ := l["type"].(string)
+		return t
+	}
+	var licenses []string
+	for _, l := range asList(raw["licenses"]) {
+		switch l := l.(type) {
+		case string:
+			licenses = append(licenses, l)
+		case map[string]any:
+			if t, _ := l["type"].(string); t != "" {
+				licenses = append(licenses, t)
+			}
+		}
+	}
+	return strings.Join(licenses, " OR ")
+}
+
+func stringField(raw map[string]any, key string) string {
+	s, _ := raw[key].(string)
+	return s
+}
+
+func asList(v any) []any {
+	list, _ := v.([]any)
+	return list
+}
+
+func stringList(v any) []string {
+	var out []string
+	for _, item := range asList(v) {
+		if s, ok := item.(string); ok && s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
@@ -0,0 +1,109 @@
+package inspect
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+
+	"github.com/git-pkgs/registries"
+)
+
+// nuspec is a NuGet package's .nuspec. Element names are matched without
+// their namespace, which differs between nuspec schema versions.
+type nuspec struct {
+	Metadata struct {
+		ID          string `xml:"id"`
+		Version     string `xml:"version"`
+		Title       string `xml:"title"`
+		Authors     string `xml:"authors"`
+		Owners      string `xml:"owners"`
+		Description string `xml:"description"`
+		Summary     string `xml:"summary"`
+		ProjectURL  string `xml:"projectUrl"`
+		LicenseURL  string `xml:"licenseUrl"`
+		License     struct {
+			Type  string `xml:"type,attr"`
+			Value string `xml:",chardata"`
+		} `xml:"license"`
+		Tags       string `xml:"tags"`
+		Repository struct {
+			Type   string `xml:"type,attr"`
+			URL    string `xml:"url,attr"`
+			Commit string `xml:"commit,attr"`
+		} `xml:"repository"`
+		Dependencies struct {
+			Groups []struct {
+				TargetFramework string             `xml:"targetFramework,attr"`
+				Dependencies    []nuspecDependency `xml:"dependency"`
+			} `xml:"group"`
+			Dependencies []nuspecDependency `xml:"dependency"`
+		} `xml:"dependencies"`
+	} `xml:"metadata"`
+}
+
+type nuspecDependency struct {
+	ID      string `xml:"id,attr"`
+	Version string `xml:"version,attr"`
+	Exclude string `xml:"exclude,attr"`
+}
+
+// parseNuspec reads a .nuspec.
+func parseNuspec(data []byte) (*Manifest, error) {
+	var spec nuspec
+	if err := xml.Unmarshal(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")), &spec); err != nil {
+		return nil, err
+	}
+	meta := spec.Metadata
+
+	m := &Manifest{
+		Ecosystem:   "nuget",
+		Name:        meta.ID,
+		Version:     meta.Version,
+		Description: meta.Description,
+		Homepage:    meta.ProjectURL,
+		Repository:  meta.Repository.URL,
+		Keywords:    strings.Fields(strings.ReplaceAll(meta.Tags, ",", " ")),
+		Metadata:    make(map[string]any),
+	}
+	if meta.License.Type == "expression" {
+		m.Licenses = strings.TrimSpace(meta.License.Value)
+	} else if meta.LicenseURL != "" {
+		m.Metadata["licenseUrl"] = meta.LicenseURL
+	}
+	for _, a := range strings.Split(meta.Authors, ",") {
+		if a = strings.TrimSpace(a); a != "" {
+			m.Authors = append(m.Authors, a)
+		}
+	}
+	if meta.Title != "" {
+		m.Metadata["title"] = meta.Title
+	}
+	if meta.Owners != "" {
+		m.Metadata["owners"] = meta.Owners
+	}
+	if meta.Repository.Commit != "" {
+		m.Metadata["repositoryCommit"] = meta.Repository.Commit
+	}
+
+	add := func(d nuspecDependency, target string) {
+		dep := registries.Dependency{
+			Name:         d.ID,
+			Requirements: d.Version,
+			Scope:        registries.Runtime,
+			Target:       target,
+		}
+		if d.Exclude != "" {
+			dep.Metadata = map[string]any{"exclude": d.Exclude}
+		}
+		m.Dependencies = append(m.Dependencies, dep)
+	}
+	for _, d := range meta.Dependencies.Dependencies {
+		add(d, "")
+	}
+	for _, g := range meta.Dependencies.Groups {
+		for _, d := range g.Dependencies {
+			add(d, g.TargetFramework)
+		}
+	}
+	return m, nil
+}
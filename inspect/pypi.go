@@ -0,0 +1,114 @@
+package inspect
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+
+	"github.com/git-pkgs/registries/internal/pypi"
+)
+
+// parsePyPI reads the core metadata in a wheel's METADATA or an sdist's
+// PKG-INFO: email-style headers, some repeated, then an optional body
+// holding the long description.
+func parsePyPI(data []byte) (*Manifest, error) {
+	headers, body := parseCoreMetadata(data)
+	first := func(key string) string {
+		if v := headers[key]; len(v) > 0 {
+			return v[0]
+		}
+		return ""
+	}
+
+	m := &Manifest{
+		Ecosystem:    "pypi",
+		Name:         first("name"),
+		Version:      first("version"),
+		Description:  first("summary"),
+		Homepage:     first("home-page"),
+		Licenses:     first("license-expression"),
+		Dependencies: pypi.ParseRequiresDist(headers["requires-dist"]),
+		Metadata:     make(map[string]any),
+	}
+	if m.Licenses == "" {
+		m.Licenses = first("license")
+	}
+	for _, key := range []string{"author", "author-email", "maintainer", "maintainer-email"} {
+		if v := first(key); v != "" {
+			m.Authors = append(m.Authors, v)
+		}
+	}
+	if keywords := first("keywords"); keywords != "" {
+		sep := " "
+		if strings.Contains(keywords, ",") {
+			sep = ","
+		}
+		for _, k := range strings.Split(keywords, sep) {
+			if k = strings.TrimSpace(k); k != "" {
+				m.Keywords = append(m.Keywords, k)
+			}
+		}
+	}
+
+	// Project-URL values are "label, url"
+	for _, pu := range headers["project-url"] {
+		label, url, ok := strings.Cut(pu, ",")
+		if !ok {
+			continue
+		}
+		label, url = strings.ToLower(strings.TrimSpace(label)), strings.TrimSpace(url)
+		switch {
+		case m.Repository == "" && (label == "source" || label == "source code" || label == "repository" || label == "code"):
+			m.Repository = url
+		case m.Homepage == "" && label == "homepage":
+			m.Homepage = url
+		}
+	}
+
+	for _, key := range []string{"metadata-version", "requires-python", "classifier", "provides-extra", "project-url", "license-file", "description-content-type"} {
+		if v, ok := headers[key]; ok {
+			m.Metadata[key] = v
+		}
+	}
+	if description := first("description"); description != "" {
+		m.Metadata["long-description"] = description
+	} else if body != "" {
+		m.Metadata["long-description"] = body
+	}
+	return m, nil
+}
+
+// parseCoreMetadata splits core metadata into headers, keyed by lower-case
+// name, and the body after the first blank line. Indented lines continue the
+// previous header, as in long Description headers.
+func parseCoreMetadata(data []byte) (map[string][]string, string) {
+	headers := make(map[string][]string)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), maxManifestSize)
+
+	var key string
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == "" {
+			break
+		}
+		if (line[0] == ' ' || line[0] == '\t') && key != "" {
+			values := headers[key]
+			values[len(values)-1] += "\n" + strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "|"))
+			continue
+		}
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(name))
+		headers[key] = append(headers[key], strings.TrimSpace(value))
+	}
+
+	var body strings.Builder
+	for scanner.Scan() {
+		body.WriteString(scanner.Text())
+		body.WriteByte('\n')
+	}
+	return headers, strings.TrimSpace(body.String())
+}
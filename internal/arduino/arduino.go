@@ -0,0 +1,290 @@
+// Package arduino provides a registry client for the Arduino Library Manager.
+package arduino
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/git-pkgs/purl"
+	"github.com/git-pkgs/registries/internal/core"
+	"github.com/git-pkgs/registries/internal/urlparser"
+	"github.com/git-pkgs/vers"
+)
+
+const (
+	DefaultURL = "https://downloads.arduino.cc/libraries"
+	ecosystem  = "arduino"
+)
+
+func init() {
+	core.Register(ecosystem, DefaultURL, func(baseURL string, client *core.Client) core.Registry {
+		return New(baseURL, client)
+	})
+}
+
+// Registry reads the Library Manager index, a single document listing every
+// release of every library. It is downloaded on first use and kept for the
+// life of the Registry.
+type Registry struct {
+	baseURL string
+	client  *core.Client
+	urls    *URLs
+	index   *libraryIndex
+}
+
+func New(baseURL string, client *core.Client) *Registry {
+	if baseURL == "" {
+		baseURL = DefaultURL
+	}
+	r := &Registry{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client:  client,
+		index:   &libraryIndex{},
+	}
+	r.urls = &URLs{baseURL: r.baseURL}
+	return r
+}
+
+func (r *Registry) Ecosystem() string {
+	return ecosystem
+}
+
+func (r *Registry) URLs() core.URLBuilder {
+	return r.urls
+}
+
+type indexResponse struct {
+	Libraries []library `json:"libraries"`
+}
+
+// library is one release of a library in the index.
+type library struct {
+	Name            string   `json:"name"`
+	Version         string   `json:"version"`
+	Author          string   `json:"author"`
+	Maintainer      string   `json:"maintainer"`
+	Sentence        string   `json:"sentence"`
+	Paragraph       string   `json:"paragraph"`
+	Website         string   `json:"website"`
+	Category        string   `json:"category"`
+	Architectures   []string `json:"architectures"`
+	Types           []string `json:"types"`
+	Repository      string   `json:"repository"`
+	URL             string   `json:"url"`
+	ArchiveFileName string   `json:"archiveFileName"`
+	Size            int64    `json:"size"`
+	Checksum        string   `json:"checksum"`
+	License         string   `json:"license"`
+	Dependencies    []struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+	} `json:"dependencies"`
+	ProvidesIncludes []string `json:"providesIncludes"`
+}
+
+// libraryIndex holds the parsed index, grouped by lowercased library name
+// with releases sorted newest first.
+type libraryIndex struct {
+	mu        sync.Mutex
+	libraries map[string][]library
+}
+
+func (idx *libraryIndex) load(ctx context.Context, client *core.Client, indexURL string) (map[string][]library, error) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	if idx.libraries != nil {
+		return idx.libraries, nil
+	}
+
+	var resp indexResponse
+	if err := client.GetJSON(ctx, indexURL, &resp); err != nil {
+		return nil, fmt.Errorf("arduino: fetching library index: %w", err)
+	}
+
+	libraries := make(map[string][]library)
+	for _, lib := range resp.Libraries {
+		key := strings.ToLower(lib.Name)
+		libraries[key] = append(libraries[key], lib)
+	}
+	for _, releases := range libraries {
+		sort.SliceStable(releases, func(i, j int) bool {
+			return vers.Compare(releases[i].Version, releases[j].Version) > 0
+		})
+	}
+
+	idx.libraries = libraries
+	return libraries, nil
+}
+
+// releases returns every release of a library, newest first.
+func (r *Registry) releases(ctx context.Context, name string) ([]library, error) {
+	libraries, err := r.index.load(ctx, r.client, r.baseURL+"/library_index.json")
+	if err != nil {
+		return nil, err
+	}
+	releases := libraries[strings.ToLower(name)]
+	if len(releases) == 0 {
+		return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name}
+	}
+	return releases, nil
+}
+
+func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package, error) {
+	releases, err := r.releases(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	latest := releases[0]
+	numbers := make([]string, len(releases))
+	for i, rel := range releases {
+		numbers[i] = rel.Version
+	}
+
+	description := latest.Sentence
+	if latest.Paragraph != "" && !strings.HasPrefix(latest.Paragraph, latest.Sentence) {
+		description = strings.TrimSpace(latest.Sentence + " " + latest.Paragraph)
+	}
+
+	var categories []string
+	if latest.Category != "" && latest.Category != "Uncategorized" {
+		categories = []string{latest.Category}
+	}
+
+	repository := urlparser.Parse(latest.Repository)
+	if repository == "" {
+		repository = urlparser.Parse(latest.Website)
+	}
+
+	return &core.Package{
+		Name:          latest.Name,
+		Description:   description,
+		Homepage:      latest.Website,
+		Repository:    repository,
+		Licenses:      latest.License,
+		Categories:    categories,
+		LatestVersion: latest.Version,
+		Metadata: map[string]any{
+			"architectures":     latest.Architectures,
+			"types":             latest.Types,
+			"provides_includes": latest.ProvidesIncludes,
+		},
+		LatestStableVersion: core.LatestStableOf(ecosystem, numbers),
+	}, nil
+}
+
+func (r *Registry) FetchVersions(ctx context.Context, name string) ([]core.Version, error) {
+	releases, err := r.releases(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]core.Version, 0, len(releases))
+	for _, lib := range releases {
+		var integrity string
+		if sum, ok := strings.CutPrefix(lib.Checksum, "SHA-256:"); ok {
+			integrity = core.HexIntegrity("sha256", sum)
+		}
+		versions = append(versions, core.Version{
+			Number:    lib.Version,
+			Licenses:  lib.License,
+			Integrity: integrity,
+			Metadata: map[string]any{
+				"download_url":  lib.URL,
+				"size":          lib.Size,
+				"architectures": lib.Architectures,
+			},
+			Size: lib.Size,
+		})
+	}
+
+	return versions, nil
+}
+
+func (r *Registry) FetchDependencies(ctx context.Context, name, version string) ([]core.Dependency, error) {
+	releases, err := r.releases(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, lib := range releases {
+		if lib.Version != version {
+			continue
+		}
+		deps := make([]core.Dependency, 0, len(lib.Dependencies))
+		for _, d := range lib.Dependencies {
+			deps = append(deps, core.Dependency{
+				Name:         d.Name,
+				Requirements: d.Version,
+				Scope:        core.Runtime,
+			})
+		}
+		return deps, nil
+	}
+
+	return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name, Version: version}
+}
+
+func (r *Registry) FetchMaintainers(ctx context.Context, name string) ([]core.Maintainer, error) {
+	releases, err := r.releases(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	latest := releases[0]
+
+	var maintainers []core.Maintainer
+	seen := make(map[string]bool)
+	add := func(field, role string) {
+		for _, person := range strings.Split(field, ",") {
+			m := parsePerson(person)
+			if m.Name == "" && m.Email == "" {
+				continue
+			}
+			key := m.Name + "\x00" + m.Email
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			m.Role = role
+			maintainers = append(maintainers, m)
+		}
+	}
+	add(latest.Maintainer, "maintainer")
+	add(latest.Author, "author")
+
+	return maintainers, nil
+}
+
+// parsePerson reads "Name <email>" as used in library.properties.
+func parsePerson(s string) core.Maintainer {
+	s = strings.TrimSpace(s)
+	if addr, err := mail.ParseAddress(s); err == nil {
+		return core.Maintainer{Name: addr.Name, Email: addr.Address}
+	}
+	return core.Maintainer{Name: s}
+}
+
+type URLs struct {
+	baseURL string
+}
+
+func (u *URLs) Registry(name, version string) string {
+	slug := strings.ToLower(strings.ReplaceAll(name, " ", "-"))
+	return fmt.Sprintf("https://www.arduino.cc/reference/en/libraries/%s/", slug)
+}
+
+func (u *URLs) Download(name, version string) string {
+	// Archive URLs depend on the source repository; see Version.Metadata["download_url"]
+	return ""
+}
+
+func (u *URLs) Documentation(name, version string) string {
+	return u.Registry(name, version)
+}
+
+func (u *URLs) PURL(name, version string) string {
+	return purl.New("arduino", "", name, version, nil).String()
+}
@@ -0,0 +1,220 @@
+package arduino
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+const indexJSON = `{"libraries": [
+	{
+		"name": "Adafruit NeoPixel",
+		"version": "1.12.0",
+		"author": "Adafruit",
+		"maintainer": "Adafruit <info@adafruit.com>",
+		"sentence": "Arduino library for controlling single-wire-based LED pixels and strip.",
+		"paragraph": "Arduino library for controlling single-wire-based LED pixels and strip.",
+		"website": "https://github.com/adafruit/Adafruit_NeoPixel",
+		"category": "Display",
+		"architectures": ["*"],
+		"types": ["Recommended"],
+		"repository": "https://github.com/adafruit/Adafruit_NeoPixel.git",
+		"url": "https://downloads.arduino.cc/libraries/github.com/adafruit/Adafruit_NeoPixel-1.12.0.zip",
+		"archiveFileName": "Adafruit_NeoPixel-1.12.0.zip",
+		"size": 62314,
+		"checksum": "SHA-256:ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad",
+		"providesIncludes": ["Adafruit_NeoPixel.h"]
+	},
+	{
+		"name": "Adafruit NeoPixel",
+		"version": "1.9.0",
+		"author": "Adafruit",
+		"maintainer": "Adafruit <info@adafruit.com>",
+		"sentence": "Arduino library for controlling single-wire-based LED pixels and strip.",
+		"repository": "https://github.com/adafruit/Adafruit_NeoPixel.git",
+		"checksum": "SHA-256:aa11"
+	},
+	{
+		"name": "Adafruit SSD1306",
+		"version": "2.5.9",
+		"author": "Adafruit, Limor Fried <limor@adafruit.com>",
+		"maintainer": "Adafruit <info@adafruit.com>",
+		"sentence": "SSD1306 oled driver library for monochrome 128x64 and 128x32 displays",
+		"repository": "https://github.com/adafruit/Adafruit_SSD1306.git",
+		"dependencies": [{"name": "Adafruit GFX Library"}, {"name": "Adafruit BusIO", "version": ">=1.14.0"}]
+	}
+]}`
+
+func newServer(t *testing.T, requests *int32) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/library_index.json" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+			w.WriteHeader(404)
+			return
+		}
+		if requests != nil {
+			atomic.AddInt32(requests, 1)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(indexJSON))
+	}))
+}
+
+func TestFetchPackage(t *testing.T) {
+	server := newServer(t, nil)
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	pkg, err := reg.FetchPackage(context.Background(), "adafruit neopixel")
+	if err != nil {
+		t.Fatalf("FetchPackage failed: %v", err)
+	}
+
+	if pkg.Name != "Adafruit NeoPixel" {
+		t.Errorf("expected name 'Adafruit NeoPixel', got %q", pkg.Name)
+	}
+	if pkg.Description != "Arduino library for controlling single-wire-based LED pixels and strip." {
+		t.Errorf("unexpected description: %q", pkg.Description)
+	}
+	if pkg.Repository != "https://github.com/adafruit/Adafruit_NeoPixel" {
+		t.Errorf("unexpected repository: %q", pkg.Repository)
+	}
+	if pkg.LatestVersion != "1.12.0" {
+		t.Errorf("unexpected latest version: %q", pkg.LatestVersion)
+	}
+	if len(pkg.Categories) != 1 || pkg.Categories[0] != "Display" {
+		t.Errorf("unexpected categories: %v", pkg.Categories)
+	}
+}
+
+func TestFetchPackageNotFound(t *testing.T) {
+	server := newServer(t, nil)
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	if _, err := reg.FetchPackage(context.Background(), "Nonexistent"); !errors.Is(err, core.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestIndexIsFetchedOnce(t *testing.T) {
+	var requests int32
+	server := newServer(t, &requests)
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	ctx := context.Background()
+	if _, err := reg.FetchPackage(ctx, "Adafruit NeoPixel"); err != nil {
+		t.Fatalf("FetchPackage failed: %v", err)
+	}
+	if _, err := reg.FetchVersions(ctx, "Adafruit SSD1306"); err != nil {
+		t.Fatalf("FetchVersions failed: %v", err)
+	}
+
+	if requests != 1 {
+		t.Errorf("expected the index to be fetched once, got %d requests", requests)
+	}
+}
+
+func TestFetchVersions(t *testing.T) {
+	server := newServer(t, nil)
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	versions, err := reg.FetchVersions(context.Background(), "Adafruit NeoPixel")
+	if err != nil {
+		t.Fatalf("FetchVersions failed: %v", err)
+	}
+
+	if len(versions) != 2 {
+		t.Fatalf("expected 2 versions, got %d", len(versions))
+	}
+	if versions[0].Number != "1.12.0" || versions[1].Number != "1.9.0" {
+		t.Errorf("expected newest first, got %q, %q", versions[0].Number, versions[1].Number)
+	}
+	if versions[0].Integrity != "sha256-ungWv48Bz+pBQUDeXa4iI7ADYaOWF3qctBD/YfIAFa0=" {
+		t.Errorf("unexpected integrity: %q", versions[0].Integrity)
+	}
+}
+
+func TestFetchDependencies(t *testing.T) {
+	server := newServer(t, nil)
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	deps, err := reg.FetchDependencies(context.Background(), "Adafruit SSD1306", "2.5.9")
+	if err != nil {
+		t.Fatalf("FetchDependencies failed: %v", err)
+	}
+
+	if len(deps) != 2 {
+		t.Fatalf("expected 2 dependencies, got %d", len(deps))
+	}
+	if deps[0].Name != "Adafruit GFX Library" || deps[0].Requirements != "" {
+		t.Errorf("unexpected first dependency: %+v", deps[0])
+	}
+	if deps[1].Name != "Adafruit BusIO" || deps[1].Requirements != ">=1.14.0" {
+		t.Errorf("unexpected second dependency: %+v", deps[1])
+	}
+
+	if _, err := reg.FetchDependencies(context.Background(), "Adafruit SSD1306", "0.0.1"); !errors.Is(err, core.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for an unknown version, got %v", err)
+	}
+}
+
+func TestFetchMaintainers(t *testing.T) {
+	server := newServer(t, nil)
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	maintainers, err := reg.FetchMaintainers(context.Background(), "Adafruit SSD1306")
+	if err != nil {
+		t.Fatalf("FetchMaintainers failed: %v", err)
+	}
+
+	if len(maintainers) != 3 {
+		t.Fatalf("expected 3 maintainers, got %d: %+v", len(maintainers), maintainers)
+	}
+	if maintainers[0].Email != "info@adafruit.com" || maintainers[0].Role != "maintainer" {
+		t.Errorf("unexpected maintainer: %+v", maintainers[0])
+	}
+	if maintainers[2].Name != "Limor Fried" || maintainers[2].Email != "limor@adafruit.com" {
+		t.Errorf("unexpected author: %+v", maintainers[2])
+	}
+}
+
+func TestURLBuilder(t *testing.T) {
+	reg := New("", nil)
+	urls := reg.URLs()
+
+	tests := []struct {
+		name     string
+		fn       func() string
+		expected string
+	}{
+		{"registry", func() string { return urls.Registry("Adafruit NeoPixel", "") }, "https://www.arduino.cc/reference/en/libraries/adafruit-neopixel/"},
+		{"download", func() string { return urls.Download("Adafruit NeoPixel", "1.12.0") }, ""},
+		{"purl", func() string { return urls.PURL("Adafruit NeoPixel", "1.12.0") }, "pkg:arduino/Adafruit%20NeoPixel@1.12.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.fn(); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestEcosystem(t *testing.T) {
+	reg := New("", nil)
+	if reg.Ecosystem() != "arduino" {
+		t.Errorf("expected ecosystem 'arduino', got %q", reg.Ecosystem())
+	}
+}
@@ -0,0 +1,221 @@
+// Package buildpack provides a registry client for Cloud Native Buildpacks.
+package buildpack
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/git-pkgs/registries/internal/core"
+	"github.com/git-pkgs/registries/internal/urlparser"
+)
+
+const (
+	DefaultURL = "https://registry.buildpacks.io"
+	ecosystem  = "buildpack"
+
+	// versionConcurrency bounds the per-version requests made by FetchVersions.
+	versionConcurrency = 8
+)
+
+func init() {
+	core.Register(ecosystem, DefaultURL, func(baseURL string, client *core.Client) core.Registry {
+		return New(baseURL, client)
+	})
+}
+
+type Registry struct {
+	baseURL string
+	client  *core.Client
+	urls    *URLs
+}
+
+func New(baseURL string, client *core.Client) *Registry {
+	if baseURL == "" {
+		baseURL = DefaultURL
+	}
+	r := &Registry{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client:  client,
+	}
+	r.urls = &URLs{baseURL: r.baseURL}
+	return r
+}
+
+func (r *Registry) Ecosystem() string {
+	return ecosystem
+}
+
+func (r *Registry) URLs() core.URLBuilder {
+	return r.urls
+}
+
+type buildpackResponse struct {
+	Latest   versionResponse `json:"latest"`
+	Versions []struct {
+		Version string `json:"version"`
+	} `json:"versions"`
+}
+
+type versionResponse struct {
+	ID          string    `json:"id"`
+	Namespace   string    `json:"namespace"`
+	Name        string    `json:"name"`
+	Version     string    `json:"version"`
+	Description string    `json:"description"`
+	Homepage    string    `json:"homepage"`
+	Licenses    []string  `json:"licenses"`
+	Stacks      []string  `json:"stacks"`
+	Addr        string    `json:"addr"`
+	Yanked      bool      `json:"yanked"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// splitName splits "paketo-buildpacks/go" into its namespace and name.
+func splitName(name string) (string, string, error) {
+	namespace, bp, ok := strings.Cut(name, "/")
+	if !ok || namespace == "" || bp == "" {
+		return "", "", fmt.Errorf("buildpack name must be namespace/name: %q", name)
+	}
+	return namespace, bp, nil
+}
+
+func (r *Registry) fetchBuildpack(ctx context.Context, name string) (*buildpackResponse, error) {
+	namespace, bp, err := splitName(name)
+	if err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("%s/api/v1/buildpacks/%s/%s", r.baseURL, namespace, bp)
+
+	var resp buildpackResponse
+	if err := r.client.GetJSON(ctx, url, &resp); err != nil {
+		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
+			return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name}
+		}
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func (r *Registry) fetchVersion(ctx context.Context, name, version string) (*versionResponse, error) {
+	namespace, bp, err := splitName(name)
+	if err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("%s/api/v1/buildpacks/%s/%s/%s", r.baseURL, namespace, bp, version)
+
+	var resp versionResponse
+	if err := r.client.GetJSON(ctx, url, &resp); err != nil {
+		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
+			return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name, Version: version}
+		}
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package, error) {
+	resp, err := r.fetchBuildpack(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	latest := resp.Latest
+
+	metadata := map[string]any{
+		"stacks": latest.Stacks,
+	}
+	if latest.Addr != "" {
+		metadata["image"] = latest.Addr
+	}
+
+	return &core.Package{
+		Name:          latest.Namespace + "/" + latest.Name,
+		Description:   latest.Description,
+		Homepage:      latest.Homepage,
+		Repository:    urlparser.Parse(latest.Homepage),
+		Licenses:      strings.Join(latest.Licenses, ","),
+		Namespace:     latest.Namespace,
+		LatestVersion: latest.Version,
+		Metadata:      metadata,
+	}, nil
+}
+
+// FetchVersions lists every published version. The listing only carries
+// version numbers, so each version is fetched for its publish time, yanked
+// state and the OCI image it maps to.
+func (r *Registry) FetchVersions(ctx context.Context, name string) ([]core.Version, error) {
+	resp, err := r.fetchBuildpack(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	numbers := make([]string, 0, len(resp.Versions))
+	for _, v := range resp.Versions {
+		numbers = append(numbers, v.Version)
+	}
+
+	details := core.ParallelMap(ctx, numbers, versionConcurrency, func(ctx context.Context, version string) (*versionResponse, error) {
+		return r.fetchVersion(ctx, name, version)
+	})
+
+	versions := make([]core.Version, 0, len(numbers))
+	for _, number := range numbers {
+		v := core.Version{Number: number}
+		if d, ok := details[number]; ok {
+			v = versionFromResponse(d)
+		}
+		versions = append(versions, v)
+	}
+
+	return versions, nil
+}
+
+func versionFromResponse(resp *versionResponse) core.Version {
+	v := core.Version{
+		Number:      resp.Version,
+		PublishedAt: resp.CreatedAt,
+		Licenses:    strings.Join(resp.Licenses, ","),
+		Metadata: map[string]any{
+			"stacks": resp.Stacks,
+		},
+	}
+	if resp.Yanked {
+		v.Status = core.StatusYanked
+	}
+	if resp.Addr != "" {
+		v.Metadata["image"] = resp.Addr
+		// The image is pinned by digest, e.g. gcr.io/paketo-buildpacks/go@sha256:...
+		if _, digest, ok := strings.Cut(resp.Addr, "@sha256:"); ok {
+			v.Integrity = core.HexIntegrity("sha256", digest)
+		}
+	}
+	return v
+}
+
+type URLs struct {
+	baseURL string
+}
+
+func (u *URLs) Registry(name, version string) string {
+	if version != "" {
+		return fmt.Sprintf("%s/buildpacks/%s/%s", u.baseURL, name, version)
+	}
+	return fmt.Sprintf("%s/buildpacks/%s", u.baseURL, name)
+}
+
+func (u *URLs) Download(name, version string) string {
+	// Buildpacks are distributed as OCI images; see Version.Metadata["image"]
+	return ""
+}
+
+func (u *URLs) Documentation(name, version string) string {
+	return u.Registry(name, version)
+}
+
+func (u *URLs) PURL(name, version string) string {
+	if version != "" {
+		return fmt.Sprintf("pkg:buildpack/%s@%s", name, version)
+	}
+	return fmt.Sprintf("pkg:buildpack/%s", name)
+}
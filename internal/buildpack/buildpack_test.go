@@ -0,0 +1,160 @@
+package buildpack
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+func newServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v1/buildpacks/paketo-buildpacks/go":
+			_, _ = w.Write([]byte(`{
+				"latest": {
+					"id": "b6a5d7c2",
+					"namespace": "paketo-buildpacks",
+					"name": "go",
+					"version": "4.6.1",
+					"description": "A language family buildpack for building Go apps",
+					"homepage": "https://github.com/paketo-buildpacks/go",
+					"licenses": ["Apache-2.0"],
+					"stacks": ["io.buildpacks.stacks.bionic", "*"],
+					"addr": "gcr.io/paketo-buildpacks/go@sha256:1e6a4a9a3bd1d0c5d4c6b1f2a4e0d1c8c1c5a1d7e3b6f2a9e8d7c6b5a4f3e2d1",
+					"yanked": false,
+					"created_at": "2023-10-19T12:00:00Z"
+				},
+				"versions": [
+					{"version": "4.6.1", "_link": "https://registry.buildpacks.io/api/v1/buildpacks/paketo-buildpacks/go/4.6.1"},
+					{"version": "4.6.0", "_link": "https://registry.buildpacks.io/api/v1/buildpacks/paketo-buildpacks/go/4.6.0"}
+				]
+			}`))
+		case "/api/v1/buildpacks/paketo-buildpacks/go/4.6.1":
+			_, _ = w.Write([]byte(`{
+				"namespace": "paketo-buildpacks", "name": "go", "version": "4.6.1",
+				"licenses": ["Apache-2.0"], "stacks": ["*"],
+				"addr": "gcr.io/paketo-buildpacks/go@sha256:1e6a4a9a3bd1d0c5d4c6b1f2a4e0d1c8c1c5a1d7e3b6f2a9e8d7c6b5a4f3e2d1",
+				"yanked": false, "created_at": "2023-10-19T12:00:00Z"
+			}`))
+		case "/api/v1/buildpacks/paketo-buildpacks/go/4.6.0":
+			_, _ = w.Write([]byte(`{
+				"namespace": "paketo-buildpacks", "name": "go", "version": "4.6.0",
+				"licenses": ["Apache-2.0"], "stacks": ["*"],
+				"addr": "gcr.io/paketo-buildpacks/go@sha256:aa",
+				"yanked": true, "created_at": "2023-10-01T09:30:00Z"
+			}`))
+		default:
+			w.WriteHeader(404)
+		}
+	}))
+}
+
+func TestFetchPackage(t *testing.T) {
+	server := newServer(t)
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	pkg, err := reg.FetchPackage(context.Background(), "paketo-buildpacks/go")
+	if err != nil {
+		t.Fatalf("FetchPackage failed: %v", err)
+	}
+
+	if pkg.Name != "paketo-buildpacks/go" {
+		t.Errorf("expected name 'paketo-buildpacks/go', got %q", pkg.Name)
+	}
+	if pkg.Namespace != "paketo-buildpacks" {
+		t.Errorf("unexpected namespace: %q", pkg.Namespace)
+	}
+	if pkg.Repository != "https://github.com/paketo-buildpacks/go" {
+		t.Errorf("unexpected repository: %q", pkg.Repository)
+	}
+	if pkg.Licenses != "Apache-2.0" {
+		t.Errorf("unexpected licenses: %q", pkg.Licenses)
+	}
+	if pkg.LatestVersion != "4.6.1" {
+		t.Errorf("unexpected latest version: %q", pkg.LatestVersion)
+	}
+	if pkg.Metadata["image"] == nil {
+		t.Error("expected image in metadata")
+	}
+}
+
+func TestFetchPackageNotFound(t *testing.T) {
+	server := newServer(t)
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	if _, err := reg.FetchPackage(context.Background(), "paketo-buildpacks/missing"); !errors.Is(err, core.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	if _, err := reg.FetchPackage(context.Background(), "go"); err == nil {
+		t.Error("expected an error for a name without a namespace")
+	}
+}
+
+func TestFetchVersions(t *testing.T) {
+	server := newServer(t)
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	versions, err := reg.FetchVersions(context.Background(), "paketo-buildpacks/go")
+	if err != nil {
+		t.Fatalf("FetchVersions failed: %v", err)
+	}
+
+	if len(versions) != 2 {
+		t.Fatalf("expected 2 versions, got %d", len(versions))
+	}
+	if versions[0].Number != "4.6.1" || versions[0].Status != core.StatusNone {
+		t.Errorf("unexpected first version: %+v", versions[0])
+	}
+	if versions[0].Integrity != "sha256-HmpKmjvR0MXUxrHypODRyMHFodfjtvKp6NfGtaTz4tE=" {
+		t.Errorf("unexpected integrity: %q", versions[0].Integrity)
+	}
+	if versions[0].PublishedAt.IsZero() {
+		t.Error("expected published_at to be set")
+	}
+	if versions[1].Number != "4.6.0" || versions[1].Status != core.StatusYanked {
+		t.Errorf("unexpected second version: %+v", versions[1])
+	}
+	if versions[1].Metadata["image"] != "gcr.io/paketo-buildpacks/go@sha256:aa" {
+		t.Errorf("unexpected image: %v", versions[1].Metadata["image"])
+	}
+}
+
+func TestURLBuilder(t *testing.T) {
+	reg := New("", nil)
+	urls := reg.URLs()
+
+	tests := []struct {
+		name     string
+		fn       func() string
+		expected string
+	}{
+		{"registry", func() string { return urls.Registry("paketo-buildpacks/go", "") }, "https://registry.buildpacks.io/buildpacks/paketo-buildpacks/go"},
+		{"registry with version", func() string { return urls.Registry("paketo-buildpacks/go", "4.6.1") }, "https://registry.buildpacks.io/buildpacks/paketo-buildpacks/go/4.6.1"},
+		{"download", func() string { return urls.Download("paketo-buildpacks/go", "4.6.1") }, ""},
+		{"purl", func() string { return urls.PURL("paketo-buildpacks/go", "4.6.1") }, "pkg:buildpack/paketo-buildpacks/go@4.6.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.fn(); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestEcosystem(t *testing.T) {
+	reg := New("", nil)
+	if reg.Ecosystem() != "buildpack" {
+		t.Errorf("expected ecosystem 'buildpack', got %q", reg.Ecosystem())
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/git-pkgs/purl"
 	"github.com/git-pkgs/registries/internal/core"
 	"github.com/git-pkgs/registries/internal/urlparser"
 )
@@ -26,8 +27,14 @@ type Registry struct {
 	baseURL string
 	client  *core.Client
 	urls    *URLs
+	index   index       // set for alternative registries given by index URL
+	state   *indexState // the index's config.json
 }
 
+// New returns a client for crates.io, or another registry serving the
+// crates.io web API at baseURL. A baseURL of the form "sparse+https://..."
+// or "git+https://..." is an alternative registry's index instead, as
+// configured in .cargo/config.toml.
 func New(baseURL string, client *core.Client) *Registry {
 	if baseURL == "" {
 		baseURL = DefaultURL
@@ -35,8 +42,12 @@ func New(baseURL string, client *core.Client) *Registry {
 	r := &Registry{
 		baseURL: strings.TrimSuffix(baseURL, "/"),
 		client:  client,
+		state:   &indexState{},
 	}
-	r.urls = &URLs{baseURL: r.baseURL}
+	if isIndexURL(r.baseURL) {
+		r.index = newIndex(r.baseURL, client)
+	}
+	r.urls = &URLs{baseURL: r.baseURL, reg: r}
 	return r
 }
 
@@ -49,19 +60,25 @@ func (r *Registry) URLs() core.URLBuilder {
 }
 
 type crateResponse struct {
-	Crate    crateInfo        `json:"crate"`
-	Versions []versionInfo    `json:"versions"`
+	Crate    crateInfo     `json:"crate"`
+	Versions []versionInfo `json:"versions"`
 }
 
 type crateInfo struct {
-	ID          string   `json:"id"`
-	Name        string   `json:"name"`
-	Description string   `json:"description"`
-	Homepage    string   `json:"homepage"`
-	Repository  string   `json:"repository"`
-	Keywords    []string `json:"keywords"`
-	Categories  []string `json:"categories"`
-	Downloads   int      `json:"downloads"`
+	ID               string   `json:"id"`
+	Name             string   `json:"name"`
+	Description      string   `json:"description"`
+	Homepage         string   `json:"homepage"`
+	Repository       string   `json:"repository"`
+	Documentation    string   `json:"documentation"`
+	Keywords         []string `json:"keywords"`
+	Categories       []string `json:"categories"`
+	Downloads        int      `json:"downloads"`
+	RecentDownloads  int      `json:"recent_downloads"`
+	MaxVersion       string   `json:"max_version"`
+	MaxStableVersion string   `json:"max_stable_version"`
+	CreatedAt        string   `json:"created_at"`
+	UpdatedAt        string   `json:"updated_at"`
 }
 
 type versionInfo struct {
@@ -77,6 +94,7 @@ type versionInfo struct {
 	RustVersion string                 `json:"rust_version"`
 	CrateSize   int                    `json:"crate_size"`
 	PublishedBy map[string]interface{} `json:"published_by"`
+	LibLinks    string                 `json:"lib_links"`
 }
 
 type dependenciesResponse struct {
@@ -88,6 +106,10 @@ type dependencyInfo struct {
 	Req      string `json:"req"`
 	Kind     string `json:"kind"`
 	Optional bool   `json:"optional"`
+	Target   string `json:"target"`
+
+	DefaultFeatures *bool    `json:"default_features"` // absent means true
+	Features        []string `json:"features"`
 }
 
 type ownersResponse struct {
@@ -102,6 +124,18 @@ type ownerInfo struct {
 }
 
 func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package, error) {
+	if r.index != nil {
+		return r.indexPackage(ctx, name)
+	}
+	resp, err := r.fetchCrate(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return packageFromCrate(resp), nil
+}
+
+// fetchCrate fetches a crate with all its versions from the API.
+func (r *Registry) fetchCrate(ctx context.Context, name string) (*crateResponse, error) {
 	url := fmt.Sprintf("%s/api/v1/crates/%s", r.baseURL, name)
 
 	var resp crateResponse
@@ -111,37 +145,63 @@ func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package
 		}
 		return nil, err
 	}
+	return &resp, nil
+}
 
+func packageFromCrate(resp *crateResponse) *core.Package {
 	var licenses string
 	if len(resp.Versions) > 0 {
 		licenses = resp.Versions[0].License
 	}
 
+	var latestReleased time.Time
+	for _, v := range resp.Versions {
+		if t, err := time.Parse(time.RFC3339, v.CreatedAt); err == nil && t.After(latestReleased) {
+			latestReleased = t
+		}
+	}
+	createdAt, _ := time.Parse(time.RFC3339, resp.Crate.CreatedAt)
+	updatedAt, _ := time.Parse(time.RFC3339, resp.Crate.UpdatedAt)
+
 	return &core.Package{
-		Name:        resp.Crate.ID,
-		Description: resp.Crate.Description,
-		Homepage:    resp.Crate.Homepage,
-		Repository:  urlparser.Parse(resp.Crate.Repository),
-		Licenses:    licenses,
-		Keywords:    resp.Crate.Keywords,
+		Name:          resp.Crate.ID,
+		Description:   resp.Crate.Description,
+		Homepage:      resp.Crate.Homepage,
+		Repository:    urlparser.Parse(resp.Crate.Repository),
+		Documentation: resp.Crate.Documentation,
+		Licenses:      licenses,
+		Keywords:      resp.Crate.Keywords,
+		Categories:    resp.Crate.Categories,
+		// crates.io's max versions skip yanked versions
+		LatestVersion:       resp.Crate.MaxVersion,
+		LatestStableVersion: resp.Crate.MaxStableVersion,
 		Metadata: map[string]any{
 			"categories": resp.Crate.Categories,
 			"downloads":  resp.Crate.Downloads,
 		},
-	}, nil
+		Popularity: &core.Popularity{
+			DownloadsTotal:  int64(resp.Crate.Downloads),
+			DownloadsRecent: int64(resp.Crate.RecentDownloads),
+			RecentDays:      90,
+		},
+		CreatedAt:        createdAt,
+		UpdatedAt:        updatedAt,
+		LatestReleasedAt: latestReleased,
+	}
 }
 
 func (r *Registry) FetchVersions(ctx context.Context, name string) ([]core.Version, error) {
-	url := fmt.Sprintf("%s/api/v1/crates/%s", r.baseURL, name)
-
-	var resp crateResponse
-	if err := r.client.GetJSON(ctx, url, &resp); err != nil {
-		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
-			return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name}
-		}
+	if r.index != nil {
+		return r.indexVersions(ctx, name)
+	}
+	resp, err := r.fetchCrate(ctx, name)
+	if err != nil {
 		return nil, err
 	}
+	return versionsFromCrate(name, resp), nil
+}
 
+func versionsFromCrate(name string, resp *crateResponse) []core.Version {
 	versions := make([]core.Version, len(resp.Versions))
 	for i, v := range resp.Versions {
 		var publishedAt time.Time
@@ -156,8 +216,19 @@ func (r *Registry) FetchVersions(ctx context.Context, name string) ([]core.Versi
 
 		var integrity string
 		if v.Checksum != "" {
-			integrity = "sha256-" + v.Checksum
+			integrity = core.HexIntegrity("sha256", v.Checksum)
+		}
+
+		metadata := map[string]any{
+			"id":           v.ID,
+			"downloads":    v.Downloads,
+			"features":     v.Features,
+			"rust_version": v.RustVersion,
+			"crate_size":   v.CrateSize,
+			"published_by": v.PublishedBy,
+			"yank_message": v.YankMessage,
 		}
+		setInstallSignals(metadata, name, v.LibLinks)
 
 		versions[i] = core.Version{
 			Number:      v.Num,
@@ -165,22 +236,49 @@ func (r *Registry) FetchVersions(ctx context.Context, name string) ([]core.Versi
 			Licenses:    v.License,
 			Integrity:   integrity,
 			Status:      status,
-			Metadata: map[string]any{
-				"id":           v.ID,
-				"downloads":    v.Downloads,
-				"features":     v.Features,
-				"rust_version": v.RustVersion,
-				"crate_size":   v.CrateSize,
-				"published_by": v.PublishedBy,
-				"yank_message": v.YankMessage,
-			},
+			Publisher:   publisher(v.PublishedBy),
+			Metadata:    metadata,
+			Size:        int64(v.CrateSize),
 		}
 	}
 
-	return versions, nil
+	return versions
+}
+
+// setInstallSignals records what the registry reveals about a version's
+// build script. Neither crates.io nor the index says whether a crate has a
+// build.rs, but one that sets the links key must, to tell Cargo how to
+// link the native library it names. Crates named "-sys" bind to native
+// libraries by convention.
+func setInstallSignals(metadata map[string]any, name, links string) {
+	if links != "" {
+		metadata["links"] = links
+		core.SetInstallSignals(metadata, []string{"build.rs"}, true)
+		return
+	}
+	core.SetInstallSignals(metadata, nil, strings.HasSuffix(name, "-sys"))
+}
+
+// publisher converts the published_by user. It is null for versions
+// published before crates.io recorded it.
+func publisher(user map[string]interface{}) *core.Maintainer {
+	login, _ := user["login"].(string)
+	if login == "" {
+		return nil
+	}
+	m := &core.Maintainer{Login: login}
+	m.Name, _ = user["name"].(string)
+	m.URL, _ = user["url"].(string)
+	if id, ok := user["id"].(float64); ok {
+		m.UUID = fmt.Sprintf("%d", int64(id))
+	}
+	return m
 }
 
 func (r *Registry) FetchDependencies(ctx context.Context, name, version string) ([]core.Dependency, error) {
+	if r.index != nil {
+		return r.indexDependencies(ctx, name, version)
+	}
 	url := fmt.Sprintf("%s/api/v1/crates/%s/%s/dependencies", r.baseURL, name, version)
 
 	var resp dependenciesResponse
@@ -198,6 +296,8 @@ func (r *Registry) FetchDependencies(ctx context.Context, name, version string)
 			Requirements: d.Req,
 			Scope:        mapScope(d.Kind),
 			Optional:     d.Optional,
+			Target:       d.Target,
+			Metadata:     featureMetadata(nil, d.DefaultFeatures == nil || *d.DefaultFeatures, d.Features),
 		}
 	}
 
@@ -216,6 +316,9 @@ func mapScope(kind string) core.Scope {
 }
 
 func (r *Registry) FetchMaintainers(ctx context.Context, name string) ([]core.Maintainer, error) {
+	if r.index != nil {
+		return r.indexMaintainers(ctx, name)
+	}
 	url := fmt.Sprintf("%s/api/v1/crates/%s/owner_user", r.baseURL, name)
 
 	var resp ownersResponse
@@ -226,8 +329,12 @@ func (r *Registry) FetchMaintainers(ctx context.Context, name string) ([]core.Ma
 		return nil, err
 	}
 
-	maintainers := make([]core.Maintainer, len(resp.Users))
-	for i, u := range resp.Users {
+	return ownerMaintainers(resp.Users), nil
+}
+
+func ownerMaintainers(users []ownerInfo) []core.Maintainer {
+	maintainers := make([]core.Maintainer, len(users))
+	for i, u := range users {
 		maintainers[i] = core.Maintainer{
 			UUID:  fmt.Sprintf("%d", u.ID),
 			Login: u.Login,
@@ -235,29 +342,41 @@ func (r *Registry) FetchMaintainers(ctx context.Context, name string) ([]core.Ma
 			URL:   u.URL,
 		}
 	}
-
-	return maintainers, nil
+	return maintainers
 }
 
 type URLs struct {
 	baseURL string
+	reg     *Registry
 }
 
 func (u *URLs) Registry(name, version string) string {
+	if u.reg.index != nil {
+		return ""
+	}
 	if version != "" {
 		return fmt.Sprintf("%s/crates/%s/%s", u.baseURL, name, version)
 	}
 	return fmt.Sprintf("%s/crates/%s", u.baseURL, name)
 }
 
+// Download returns the crate file URL. For alternative registries it is
+// built from the index's dl template, reading config.json (and the crate's
+// index entry, for templates using the checksum) on first use.
 func (u *URLs) Download(name, version string) string {
 	if version == "" {
 		return ""
 	}
+	if u.reg.index != nil {
+		return u.reg.downloadURL(context.Background(), name, version)
+	}
 	return fmt.Sprintf("https://static.crates.io/crates/%s/%s-%s.crate", name, name, version)
 }
 
 func (u *URLs) Documentation(name, version string) string {
+	if u.reg.index != nil {
+		return ""
+	}
 	if version != "" {
 		return fmt.Sprintf("https://docs.rs/%s/%s", name, version)
 	}
@@ -265,6 +384,10 @@ func (u *URLs) Documentation(name, version string) string {
 }
 
 func (u *URLs) PURL(name, version string) string {
+	if u.reg.index != nil {
+		p := purl.New(ecosystem, "", name, version, map[string]string{"repository_url": u.baseURL})
+		return p.String()
+	}
 	if version != "" {
 		return fmt.Sprintf("pkg:cargo/%s@%s", name, version)
 	}
@@ -3,8 +3,10 @@ package cargo
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -21,13 +23,20 @@ func TestFetchPackage(t *testing.T) {
 
 		resp := crateResponse{
 			Crate: crateInfo{
-				ID:          "serde",
-				Name:        "serde",
-				Description: "A generic serialization/deserialization framework",
-				Homepage:    "https://serde.rs",
-				Repository:  "https://github.com/serde-rs/serde",
-				Keywords:    []string{"serialization", "no_std"},
-				Categories:  []string{"encoding"},
+				ID:               "serde",
+				Name:             "serde",
+				Description:      "A generic serialization/deserialization framework",
+				Homepage:         "https://serde.rs",
+				Repository:       "https://github.com/serde-rs/serde",
+				Documentation:    "https://docs.rs/serde/",
+				Keywords:         []string{"serialization", "no_std"},
+				Categories:       []string{"encoding"},
+				CreatedAt:        "2014-12-05T20:20:39.487502Z",
+				UpdatedAt:        "2025-09-27T16:51:35.012345Z",
+				MaxVersion:       "2.0.0-rc.1",
+				MaxStableVersion: "1.0.228",
+				Downloads:        700000000,
+				RecentDownloads:  90000000,
 			},
 			Versions: []versionInfo{
 				{
@@ -67,6 +76,24 @@ func TestFetchPackage(t *testing.T) {
 	if len(pkg.Keywords) != 2 {
 		t.Errorf("expected 2 keywords, got %d", len(pkg.Keywords))
 	}
+	if len(pkg.Categories) != 1 || pkg.Categories[0] != "encoding" {
+		t.Errorf("unexpected categories: %v", pkg.Categories)
+	}
+	if pkg.CreatedAt.Year() != 2014 || pkg.UpdatedAt.Year() != 2025 {
+		t.Errorf("unexpected created/updated times: %v, %v", pkg.CreatedAt, pkg.UpdatedAt)
+	}
+	if want := time.Date(2025, 9, 27, 16, 51, 35, 0, time.UTC); !pkg.LatestReleasedAt.Equal(want) {
+		t.Errorf("LatestReleasedAt = %v, want %v", pkg.LatestReleasedAt, want)
+	}
+	if pkg.Documentation != "https://docs.rs/serde/" {
+		t.Errorf("unexpected documentation: %q", pkg.Documentation)
+	}
+	if pkg.LatestVersion != "2.0.0-rc.1" || pkg.LatestStableVersion != "1.0.228" {
+		t.Errorf("latest = %q, stable = %q", pkg.LatestVersion, pkg.LatestStableVersion)
+	}
+	if want := (core.Popularity{DownloadsTotal: 700000000, DownloadsRecent: 90000000, RecentDays: 90}); pkg.Popularity == nil || *pkg.Popularity != want {
+		t.Errorf("Popularity = %+v, want %+v", pkg.Popularity, want)
+	}
 }
 
 func TestFetchPackageNotFound(t *testing.T) {
@@ -96,9 +123,13 @@ func TestFetchVersions(t *testing.T) {
 				{
 					Num:       "1.0.228",
 					License:   "MIT OR Apache-2.0",
-					Checksum:  "abc123",
+					Checksum:  "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad",
 					Yanked:    false,
 					CreatedAt: "2025-09-27T16:51:35Z",
+					CrateSize: 83528,
+					PublishedBy: map[string]interface{}{
+						"id": 3618, "login": "dtolnay", "name": "David Tolnay", "url": "https://github.com/dtolnay",
+					},
 				},
 				{
 					Num:       "1.0.227",
@@ -106,6 +137,7 @@ func TestFetchVersions(t *testing.T) {
 					Checksum:  "def456",
 					Yanked:    true,
 					CreatedAt: "2025-09-25T23:43:08Z",
+					LibLinks:  "serde_native",
 				},
 			},
 		}
@@ -131,14 +163,31 @@ func TestFetchVersions(t *testing.T) {
 	if versions[0].Status != core.StatusNone {
 		t.Errorf("expected no status for first version, got %q", versions[0].Status)
 	}
-	if versions[0].Integrity != "sha256-abc123" {
+	if versions[0].Integrity != "sha256-ungWv48Bz+pBQUDeXa4iI7ADYaOWF3qctBD/YfIAFa0=" {
 		t.Errorf("unexpected integrity: %q", versions[0].Integrity)
 	}
+	if versions[0].Size != 83528 {
+		t.Errorf("expected crate_size as Size, got %d", versions[0].Size)
+	}
+
+	if p := versions[0].Publisher; p == nil || p.Login != "dtolnay" || p.UUID != "3618" {
+		t.Errorf("unexpected publisher: %+v", p)
+	}
+	if versions[1].Publisher != nil {
+		t.Errorf("expected no publisher for second version, got %+v", versions[1].Publisher)
+	}
 
 	if versions[1].Status != core.StatusYanked {
 		t.Errorf("expected yanked status for second version, got %q", versions[1].Status)
 	}
 
+	if core.HasInstallScripts(versions[0]) || core.HasNativeCode(versions[0]) {
+		t.Errorf("expected no install signals for first version, got %v", versions[0].Metadata)
+	}
+	if !core.HasNativeCode(versions[1]) || core.InstallScripts(versions[1])[0] != "build.rs" {
+		t.Errorf("expected links to imply a build script, got %v", versions[1].Metadata)
+	}
+
 	expectedTime, _ := time.Parse(time.RFC3339, "2025-09-27T16:51:35Z")
 	if !versions[0].PublishedAt.Equal(expectedTime) {
 		t.Errorf("unexpected published_at: %v", versions[0].PublishedAt)
@@ -156,7 +205,7 @@ func TestFetchDependencies(t *testing.T) {
 		resp := dependenciesResponse{
 			Dependencies: []dependencyInfo{
 				{CrateID: "bytes", Req: "^1.0", Kind: "normal", Optional: false},
-				{CrateID: "libc", Req: "^0.2", Kind: "normal", Optional: true},
+				{CrateID: "libc", Req: "^0.2", Kind: "normal", Optional: true, Target: "cfg(unix)"},
 				{CrateID: "tokio-test", Req: "^0.4", Kind: "dev", Optional: false},
 				{CrateID: "cc", Req: "^1.0", Kind: "build", Optional: false},
 			},
@@ -190,6 +239,12 @@ func TestFetchDependencies(t *testing.T) {
 	if deps[1].Optional != true {
 		t.Error("expected libc to be optional")
 	}
+	if deps[1].Target != "cfg(unix)" {
+		t.Errorf("expected target 'cfg(unix)', got %q", deps[1].Target)
+	}
+	if deps[0].Target != "" {
+		t.Errorf("expected no target for bytes, got %q", deps[0].Target)
+	}
 
 	if deps[2].Scope != core.Development {
 		t.Errorf("expected development scope, got %q", deps[2].Scope)
@@ -271,3 +326,142 @@ func TestEcosystem(t *testing.T) {
 		t.Errorf("expected ecosystem 'cargo', got %q", reg.Ecosystem())
 	}
 }
+
+func TestFetchReadme(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/crates/serde":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"crate": map[string]interface{}{"id": "serde", "max_version": "2.0.0-rc.1", "max_stable_version": "1.0.197"},
+			})
+		case "/api/v1/crates/serde/1.0.197/readme":
+			_ = json.NewEncoder(w).Encode(map[string]string{"url": server.URL + "/readmes/serde/serde-1.0.197.html"})
+		case "/readmes/serde/serde-1.0.197.html":
+			_, _ = w.Write([]byte("<h1>Serde</h1>"))
+		case "/api/v1/crates/serde/0.1.0/readme":
+			_ = json.NewEncoder(w).Encode(map[string]string{"url": server.URL + "/readmes/serde/serde-0.1.0.html"})
+		default:
+			w.WriteHeader(http.StatusForbidden)
+		}
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	ctx := context.Background()
+
+	doc, err := reg.FetchReadme(ctx, "serde", "")
+	if err != nil {
+		t.Fatalf("FetchReadme failed: %v", err)
+	}
+	if doc.ContentType != core.HTML || doc.Content != "<h1>Serde</h1>" {
+		t.Errorf("unexpected document: %+v", doc)
+	}
+
+	if _, err := reg.FetchReadme(ctx, "serde", "0.1.0"); !errors.Is(err, core.ErrNotFound) {
+		t.Errorf("expected not found for missing readme, got %v", err)
+	}
+}
+
+func TestAlternativeRegistry(t *testing.T) {
+	var server *httptest.Server
+	var unauthorized []string
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "s3cret" {
+			unauthorized = append(unauthorized, r.URL.Path)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/index/config.json":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"dl":            server.URL + "/dl/{lowerprefix}/{crate}/{crate}-{version}.crate",
+				"api":           server.URL + "/api-host",
+				"auth-required": true,
+			})
+		case "/index/my/-c/my-crate":
+			_, _ = w.Write([]byte(`{"name":"my-crate","vers":"0.1.0","deps":[],"cksum":"aa","features":{},"yanked":false}
+{"name":"my-crate","vers":"0.2.0","deps":[{"name":"json","package":"serde_json","req":"^1","features":["raw_value"],"optional":false,"default_features":false,"kind":"normal"},{"name":"internal-util","req":"^0.3","features":[],"optional":true,"default_features":true,"kind":"dev","registry":"https://other.example.com/index"}],"cksum":"ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad","features":{"std":[]},"features2":{"serde":["dep:serde"]},"yanked":false,"rust_version":"1.70"}
+{"name":"my-crate","vers":"0.3.0","deps":[],"cksum":"cc","features":{},"yanked":true}
+`))
+		case "/api-host/api/v1/crates/my-crate/owners":
+			_ = json.NewEncoder(w).Encode(ownersResponse{Users: []ownerInfo{{ID: 1, Login: "alice"}}})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	reg := New("sparse+"+server.URL+"/index/", core.DefaultClient()).WithToken("s3cret")
+	ctx := context.Background()
+
+	pkg, err := reg.FetchPackage(ctx, "my-crate")
+	if err != nil {
+		t.Fatalf("FetchPackage failed: %v", err)
+	}
+	if pkg.Name != "my-crate" || pkg.LatestVersion != "0.2.0" {
+		t.Errorf("expected latest unyanked version 0.2.0, got %+v", pkg)
+	}
+
+	versions, err := reg.FetchVersions(ctx, "my-crate")
+	if err != nil {
+		t.Fatalf("FetchVersions failed: %v", err)
+	}
+	if len(versions) != 3 || versions[0].Number != "0.3.0" || versions[0].Status != core.StatusYanked || versions[1].Integrity != "sha256-ungWv48Bz+pBQUDeXa4iI7ADYaOWF3qctBD/YfIAFa0=" {
+		t.Errorf("unexpected versions %+v", versions)
+	}
+	if features, _ := versions[1].Metadata["features"].(map[string][]string); len(features) != 2 {
+		t.Errorf("expected features and features2 merged, got %v", versions[1].Metadata["features"])
+	}
+
+	deps, err := reg.FetchDependencies(ctx, "my-crate", "0.2.0")
+	if err != nil {
+		t.Fatalf("FetchDependencies failed: %v", err)
+	}
+	if len(deps) != 2 || deps[0].Name != "serde_json" || deps[0].Metadata["rename"] != "json" {
+		t.Errorf("unexpected renamed dependency %+v", deps)
+	}
+	if deps[0].Metadata["default_features"] != false || len(deps[0].Metadata["features"].([]string)) != 1 {
+		t.Errorf("expected default features off and raw_value on, got %v", deps[0].Metadata)
+	}
+	features, err := reg.FetchFeatures(ctx, "my-crate", "0.2.0")
+	if err != nil {
+		t.Fatalf("FetchFeatures failed: %v", err)
+	}
+	if by := features.EnabledBy("internal-util"); len(by) != 1 || by[0] != "internal-util" {
+		t.Errorf("expected internal-util's implicit feature, got %v", by)
+	}
+	if deps[1].Scope != core.Development || deps[1].Metadata["registry"] != "https://other.example.com/index" {
+		t.Errorf("unexpected dependency %+v", deps[1])
+	}
+
+	maintainers, err := reg.FetchMaintainers(ctx, "my-crate")
+	if err != nil {
+		t.Fatalf("FetchMaintainers failed: %v", err)
+	}
+	if len(maintainers) != 1 || maintainers[0].Login != "alice" {
+		t.Errorf("unexpected maintainers %+v", maintainers)
+	}
+
+	urls := reg.URLs()
+	if got := urls.Download("my-crate", "0.2.0"); got != server.URL+"/dl/my/-c/my-crate/my-crate-0.2.0.crate" {
+		t.Errorf("unexpected download URL %q", got)
+	}
+	if got := urls.PURL("my-crate", "0.2.0"); got != "pkg:cargo/my-crate@0.2.0?repository_url=sparse%2Bhttp:%2F%2F"+strings.TrimPrefix(server.URL, "http://")+"%2Findex" {
+		t.Errorf("unexpected PURL %q", got)
+	}
+	fromPURL, name, _, err := core.NewFromPURL(urls.PURL("my-crate", "0.2.0"), nil)
+	if err != nil || name != "my-crate" || fromPURL.(*Registry).baseURL != "sparse+"+server.URL+"/index" {
+		t.Errorf("PURL didn't round-trip: %v %q %v", fromPURL, name, err)
+	}
+
+	if _, err := reg.FetchVersions(ctx, "missing"); !errors.Is(err, core.ErrNotFound) {
+		t.Errorf("expected not found, got %v", err)
+	}
+	if _, err := reg.FetchReadme(ctx, "my-crate", "0.2.0"); !errors.Is(err, core.ErrNotSupported) {
+		t.Errorf("expected not supported, got %v", err)
+	}
+	if len(unauthorized) > 0 {
+		t.Errorf("requests sent without the token: %v", unauthorized)
+	}
+}
@@ -0,0 +1,226 @@
+package cargo
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+// Feature is what turning on one feature of a crate turns on in turn, read
+// from its entry in the [features] table.
+type Feature struct {
+	Features     []string // other features of the same crate
+	Dependencies []string // optional dependencies it enables, by the name features use for them
+
+	// DependencyFeatures are features it enables on dependencies ("serde/std"),
+	// which also enables the dependency if it's optional.
+	DependencyFeatures map[string][]string
+
+	// WeakDependencyFeatures are features it enables on dependencies only if
+	// something else enables the dependency ("serde?/std").
+	WeakDependencyFeatures map[string][]string
+}
+
+// Features is a crate version's feature table. It includes the implicit
+// feature Cargo creates for each optional dependency that no feature
+// refers to with "dep:".
+type Features map[string]Feature
+
+// ParseFeatures reads a [features] table as the registry returns it.
+// optional names the version's optional dependencies, as features refer to
+// them: by their rename, if they have one.
+func ParseFeatures(table map[string][]string, optional []string) Features {
+	features := make(Features, len(table)+len(optional))
+	explicit := make(map[string]bool)
+	for name, values := range table {
+		var f Feature
+		for _, value := range values {
+			switch dep, feature, ok := strings.Cut(value, "/"); {
+			case strings.HasPrefix(value, "dep:"):
+				f.Dependencies = append(f.Dependencies, value[len("dep:"):])
+				explicit[value[len("dep:"):]] = true
+			case ok && strings.HasSuffix(dep, "?"):
+				f.WeakDependencyFeatures = appendFeature(f.WeakDependencyFeatures, strings.TrimSuffix(dep, "?"), feature)
+			case ok:
+				f.DependencyFeatures = appendFeature(f.DependencyFeatures, dep, feature)
+			default:
+				f.Features = append(f.Features, value)
+			}
+		}
+		features[name] = f
+	}
+	// Without "dep:" anywhere, an optional dependency is also a feature of
+	// the same name that only enables it.
+	for _, dep := range optional {
+		if _, ok := features[dep]; !ok && !explicit[dep] {
+			features[dep] = Feature{Dependencies: []string{dep}}
+		}
+	}
+	return features
+}
+
+func appendFeature(m map[string][]string, dep, feature string) map[string][]string {
+	if m == nil {
+		m = make(map[string][]string)
+	}
+	m[dep] = append(m[dep], feature)
+	return m
+}
+
+// Resolution is what a build of a crate version compiles for a given set
+// of features.
+type Resolution struct {
+	Features []string // the crate's own features that end up enabled, sorted
+
+	// Dependencies are the optional dependencies enabled, and any dependency
+	// a feature enables features on, with those features sorted. Dependencies
+	// that aren't optional are compiled whether or not they appear here.
+	Dependencies map[string][]string
+}
+
+// Resolve works out what turning on the requested features enables,
+// following features through one another as Cargo does. With
+// defaultFeatures the "default" feature is turned on as well, as it is
+// unless a dependent sets default-features = false. Features the table
+// doesn't have are ignored.
+func (f Features) Resolve(requested []string, defaultFeatures bool) Resolution {
+	enabled := make(map[string]bool)
+	deps := make(map[string]map[string]bool)
+	enableDep := func(dep string) {
+		if deps[dep] == nil {
+			deps[dep] = make(map[string]bool)
+		}
+	}
+
+	queue := slices.Clone(requested)
+	if defaultFeatures {
+		queue = append(queue, "default")
+	}
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		feature, ok := f[name]
+		if !ok || enabled[name] {
+			continue
+		}
+		enabled[name] = true
+		queue = append(queue, feature.Features...)
+		for _, dep := range feature.Dependencies {
+			enableDep(dep)
+		}
+		for dep, features := range feature.DependencyFeatures {
+			enableDep(dep)
+			for _, df := range features {
+				deps[dep][df] = true
+			}
+			// Before "dep:" existed, "serde/std" also turned on the
+			// optional dependency's implicit feature.
+			if implicit, ok := f[dep]; ok && slices.Equal(implicit.Dependencies, []string{dep}) {
+				queue = append(queue, dep)
+			}
+		}
+	}
+
+	// Weak features only apply to dependencies something else enabled, so
+	// they're added once everything else is known.
+	for name := range enabled {
+		for dep, features := range f[name].WeakDependencyFeatures {
+			if deps[dep] == nil {
+				continue
+			}
+			for _, df := range features {
+				deps[dep][df] = true
+			}
+		}
+	}
+
+	r := Resolution{
+		Features:     slices.Sorted(maps.Keys(enabled)),
+		Dependencies: make(map[string][]string, len(deps)),
+	}
+	for dep, features := range deps {
+		r.Dependencies[dep] = slices.Sorted(maps.Keys(features))
+	}
+	return r
+}
+
+// EnabledBy returns the features that enable dep, an optional dependency
+// named as features refer to it, sorted. Weak dependency features don't
+// enable a dependency and aren't counted.
+func (f Features) EnabledBy(dep string) []string {
+	var by []string
+	for name, feature := range f {
+		_, viaFeature := feature.DependencyFeatures[dep]
+		if viaFeature || slices.Contains(feature.Dependencies, dep) {
+			by = append(by, name)
+		}
+	}
+	slices.Sort(by)
+	return by
+}
+
+// FetchFeatures returns the feature table of a crate version. From the
+// crates.io API this takes two requests, one for the version's features and
+// one for its optional dependencies; from an index it takes one.
+func (r *Registry) FetchFeatures(ctx context.Context, name, version string) (Features, error) {
+	if r.index != nil {
+		entries, err := r.entries(ctx, name, version)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			if e.Vers != version {
+				continue
+			}
+			var optional []string
+			for _, d := range e.Deps {
+				if d.Optional {
+					optional = append(optional, d.Name)
+				}
+			}
+			return ParseFeatures(indexFeatures(e), optional), nil
+		}
+		return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name, Version: version}
+	}
+
+	resp, err := r.fetchCrate(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	i := slices.IndexFunc(resp.Versions, func(v versionInfo) bool { return v.Num == version })
+	if i < 0 {
+		return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name, Version: version}
+	}
+	deps, err := r.FetchDependencies(ctx, name, version)
+	if err != nil {
+		return nil, err
+	}
+	var optional []string
+	for _, d := range deps {
+		if d.Optional {
+			optional = append(optional, d.Name)
+		}
+	}
+	return ParseFeatures(resp.Versions[i].Features, optional), nil
+}
+
+// featureMetadata records how a dependent configures a dependency's
+// features: default-features = false, and the features it turns on.
+func featureMetadata(metadata map[string]any, defaultFeatures bool, features []string) map[string]any {
+	if defaultFeatures && len(features) == 0 {
+		return metadata
+	}
+	if metadata == nil {
+		metadata = make(map[string]any)
+	}
+	if !defaultFeatures {
+		metadata["default_features"] = false
+	}
+	if len(features) > 0 {
+		metadata["features"] = features
+	}
+	return metadata
+}
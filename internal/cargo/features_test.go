@@ -0,0 +1,100 @@
+package cargo
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+func TestResolveFeatures(t *testing.T) {
+	features := ParseFeatures(map[string][]string{
+		"default": {"std"},
+		"std":     {"serde?/std", "memchr/std"},
+		"derive":  {"dep:serde_derive", "serde/derive"},
+		"full":    {"derive", "regex"},
+	}, []string{"serde", "serde_derive", "memchr", "regex"})
+
+	// serde_derive has a "dep:" feature, so it gets no implicit one
+	if _, ok := features["serde_derive"]; ok {
+		t.Error("serde_derive should have no implicit feature")
+	}
+	if !reflect.DeepEqual(features["regex"], Feature{Dependencies: []string{"regex"}}) {
+		t.Errorf("regex = %+v", features["regex"])
+	}
+
+	tests := []struct {
+		requested []string
+		defaults  bool
+		want      Resolution
+	}{
+		{nil, true, Resolution{
+			Features:     []string{"default", "memchr", "std"},
+			Dependencies: map[string][]string{"memchr": {"std"}},
+		}},
+		{nil, false, Resolution{Dependencies: map[string][]string{}}},
+		{[]string{"full"}, true, Resolution{
+			Features: []string{"default", "derive", "full", "memchr", "regex", "serde", "std"},
+			Dependencies: map[string][]string{
+				"memchr":       {"std"},
+				"regex":        nil,
+				"serde":        {"derive", "std"},
+				"serde_derive": nil,
+			},
+		}},
+	}
+	for _, tt := range tests {
+		got := features.Resolve(tt.requested, tt.defaults)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Resolve(%v, %v) = %+v, want %+v", tt.requested, tt.defaults, got, tt.want)
+		}
+	}
+
+	if by := features.EnabledBy("serde"); !reflect.DeepEqual(by, []string{"derive", "serde"}) {
+		t.Errorf("EnabledBy(serde) = %v", by)
+	}
+}
+
+func TestFetchFeatures(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/crates/tokio", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"crate": {"name": "tokio"}, "versions": [
+			{"num": "1.0.0", "features": {"default": [], "net": ["libc/extra_traits"]}}
+		]}`))
+	})
+	mux.HandleFunc("/api/v1/crates/tokio/1.0.0/dependencies", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"dependencies": [
+			{"crate_id": "bytes", "req": "^1.0", "kind": "normal", "default_features": false, "features": ["std"]},
+			{"crate_id": "libc", "req": "^0.2", "kind": "normal", "optional": true, "default_features": true, "features": []}
+		]}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	ctx := context.Background()
+
+	reg := New(server.URL, core.DefaultClient())
+	deps, err := reg.FetchDependencies(ctx, "tokio", "1.0.0")
+	if err != nil {
+		t.Fatalf("FetchDependencies failed: %v", err)
+	}
+	if want := map[string]any{"default_features": false, "features": []string{"std"}}; !reflect.DeepEqual(deps[0].Metadata, want) {
+		t.Errorf("bytes metadata = %v, want %v", deps[0].Metadata, want)
+	}
+	if deps[1].Metadata != nil {
+		t.Errorf("libc metadata = %v, want none", deps[1].Metadata)
+	}
+
+	features, err := reg.FetchFeatures(ctx, "tokio", "1.0.0")
+	if err != nil {
+		t.Fatalf("FetchFeatures failed: %v", err)
+	}
+	if by := features.EnabledBy("libc"); !reflect.DeepEqual(by, []string{"libc", "net"}) {
+		t.Errorf("EnabledBy(libc) = %v", by)
+	}
+	if _, err := reg.FetchFeatures(ctx, "tokio", "9.9.9"); err == nil {
+		t.Error("expected an error for a missing version")
+	}
+}
@@ -0,0 +1,24 @@
+package cargo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+// FetchPackageAt reconstructs a crate as of at from its versions'
+// created_at times. The crate's description and links are as they are now.
+// Index-backed registries don't record publish times, so history isn't
+// supported for them.
+func (r *Registry) FetchPackageAt(ctx context.Context, name string, at time.Time) (*core.PackageSnapshot, error) {
+	if r.index != nil {
+		return nil, fmt.Errorf("%s package history from an index: %w", ecosystem, core.ErrNotSupported)
+	}
+	resp, err := r.fetchCrate(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return core.SnapshotAt(ecosystem, *packageFromCrate(resp), versionsFromCrate(name, resp), at)
+}
@@ -0,0 +1,344 @@
+package cargo
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/git-pkgs/registries/cargoindex"
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+// Alternative registries are given by their index URL as Cargo writes it in
+// .cargo/config.toml: "sparse+https://..." for a sparse index, or
+// "git+https://..." for a git index. Versions and dependencies then come
+// from the index, owners from the web API named in the index's config.json,
+// and download URLs from its dl template.
+const (
+	sparsePrefix = "sparse+"
+	gitPrefix    = "git+"
+)
+
+// index is a Cargo registry index, sparse or git.
+type index interface {
+	Entries(ctx context.Context, name string) ([]cargoindex.Entry, error)
+	Config(ctx context.Context) (*cargoindex.Config, error)
+}
+
+// indexState caches an index's config.json, which is read once per
+// Registry. mu serialises reading it.
+type indexState struct {
+	mu  sync.Mutex
+	cfg atomic.Pointer[cargoindex.Config]
+}
+
+// isIndexURL reports whether baseURL names an index rather than a
+// crates.io-style web API.
+func isIndexURL(baseURL string) bool {
+	return strings.HasPrefix(baseURL, sparsePrefix) || strings.HasPrefix(baseURL, gitPrefix)
+}
+
+func newIndex(indexURL string, client *core.Client) index {
+	if strings.HasPrefix(indexURL, gitPrefix) {
+		url := strings.TrimPrefix(indexURL, gitPrefix)
+		return &gitIndex{idx: cargoindex.NewGitIndex(gitIndexDir(url), url)}
+	}
+	return cargoindex.NewSparse(client, strings.TrimPrefix(indexURL, sparsePrefix))
+}
+
+// gitIndexDir returns where a git index is cloned: a directory per index
+// URL under the user's cache directory.
+func gitIndexDir(url string) string {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		base = os.TempDir()
+	}
+	sum := sha256.Sum256([]byte(url))
+	return filepath.Join(base, "git-pkgs-registries", "cargo-index", hex.EncodeToString(sum[:8]))
+}
+
+// gitIndex fetches a git index once, the first time it is read, and reads
+// the tracked branch after that.
+type gitIndex struct {
+	idx  *cargoindex.GitIndex
+	once sync.Once
+	err  error
+}
+
+func (g *gitIndex) fetch(ctx context.Context) error {
+	g.once.Do(func() {
+		g.err = g.idx.Fetch(ctx)
+	})
+	return g.err
+}
+
+func (g *gitIndex) Entries(ctx context.Context, name string) ([]cargoindex.Entry, error) {
+	if err := g.fetch(ctx); err != nil {
+		return nil, err
+	}
+	entries, err := g.idx.Entries(ctx, "", name)
+	if err != nil {
+		if strings.Contains(err.Error(), "does not exist") {
+			return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name}
+		}
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (g *gitIndex) Config(ctx context.Context) (*cargoindex.Config, error) {
+	if err := g.fetch(ctx); err != nil {
+		return nil, err
+	}
+	return g.idx.Config(ctx, "")
+}
+
+// indexConfig returns the index's config.json, reading it on first use.
+func (r *Registry) indexConfig(ctx context.Context) (*cargoindex.Config, error) {
+	if cfg := r.state.cfg.Load(); cfg != nil {
+		return cfg, nil
+	}
+	r.state.mu.Lock()
+	defer r.state.mu.Unlock()
+	if cfg := r.state.cfg.Load(); cfg != nil {
+		return cfg, nil
+	}
+	cfg, err := r.index.Config(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s config.json: %w", r.baseURL, err)
+	}
+	r.state.cfg.Store(cfg)
+	return cfg, nil
+}
+
+// entries returns a crate's index entries, mapping a missing file to
+// NotFoundError.
+func (r *Registry) entries(ctx context.Context, name, version string) ([]cargoindex.Entry, error) {
+	entries, err := r.index.Entries(ctx, name)
+	if err != nil {
+		if httpErr, ok := err.(*core.HTTPError); ok && (httpErr.IsNotFound() || httpErr.StatusCode == http.StatusGone) {
+			return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name, Version: version}
+		}
+		if _, ok := err.(*core.NotFoundError); ok {
+			return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name, Version: version}
+		}
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name, Version: version}
+	}
+	return entries, nil
+}
+
+func (r *Registry) indexPackage(ctx context.Context, name string) (*core.Package, error) {
+	entries, err := r.entries(ctx, name, "")
+	if err != nil {
+		return nil, err
+	}
+	latest := entries[len(entries)-1]
+	for i := len(entries) - 1; i >= 0; i-- {
+		if !entries[i].Yanked {
+			latest = entries[i]
+			break
+		}
+	}
+	var published []string
+	for _, e := range entries {
+		if !e.Yanked {
+			published = append(published, e.Vers)
+		}
+	}
+	return &core.Package{
+		Name:                latest.Name,
+		LatestVersion:       latest.Vers,
+		LatestStableVersion: core.LatestStableOf(ecosystem, published),
+		Metadata: map[string]any{
+			"features": indexFeatures(latest),
+		},
+	}, nil
+}
+
+// indexVersions returns versions newest first, as the crates.io API does.
+// The index records no publish times.
+func (r *Registry) indexVersions(ctx context.Context, name string) ([]core.Version, error) {
+	entries, err := r.entries(ctx, name, "")
+	if err != nil {
+		return nil, err
+	}
+	versions := make([]core.Version, 0, len(entries))
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+		var status core.VersionStatus
+		if e.Yanked {
+			status = core.StatusYanked
+		}
+		var integrity string
+		if e.Cksum != "" {
+			integrity = core.HexIntegrity("sha256", e.Cksum)
+		}
+		metadata := map[string]any{
+			"features":     indexFeatures(e),
+			"rust_version": e.RustVersion,
+		}
+		setInstallSignals(metadata, name, e.Links)
+		versions = append(versions, core.Version{
+			Number:    e.Vers,
+			Integrity: integrity,
+			Status:    status,
+			Metadata:  metadata,
+		})
+	}
+	return versions, nil
+}
+
+func (r *Registry) indexDependencies(ctx context.Context, name, version string) ([]core.Dependency, error) {
+	entries, err := r.entries(ctx, name, version)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		if e.Vers != version {
+			continue
+		}
+		deps := make([]core.Dependency, len(e.Deps))
+		for i, d := range e.Deps {
+			deps[i] = core.Dependency{
+				Name:         d.Name,
+				Requirements: d.Req,
+				Scope:        mapScope(d.Kind),
+				Optional:     d.Optional,
+				Target:       d.Target,
+			}
+			// A renamed dependency is listed under its new name, with the
+			// real crate in package
+			if d.Package != "" {
+				deps[i].Name = d.Package
+				deps[i].Metadata = map[string]any{"rename": d.Name}
+			}
+			if d.Registry != "" {
+				if deps[i].Metadata == nil {
+					deps[i].Metadata = map[string]any{}
+				}
+				deps[i].Metadata["registry"] = d.Registry
+			}
+			deps[i].Metadata = featureMetadata(deps[i].Metadata, d.DefaultFeatures, d.Features)
+		}
+		return deps, nil
+	}
+	return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name, Version: version}
+}
+
+// indexMaintainers reads owners from the registry's web API, which is
+// optional for alternative registries.
+func (r *Registry) indexMaintainers(ctx context.Context, name string) ([]core.Maintainer, error) {
+	cfg, err := r.indexConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.API == "" {
+		return nil, fmt.Errorf("%s has no web API for owners: %w", r.baseURL, core.ErrNotSupported)
+	}
+
+	url := fmt.Sprintf("%s/api/v1/crates/%s/owners", strings.TrimSuffix(cfg.API, "/"), name)
+	var resp ownersResponse
+	if err := r.client.GetJSON(ctx, url, &resp); err != nil {
+		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
+			return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name}
+		}
+		return nil, err
+	}
+	return ownerMaintainers(resp.Users), nil
+}
+
+// indexFeatures merges an entry's features and features2, which holds the
+// features using newer syntax that older Cargo versions can't parse.
+func indexFeatures(e cargoindex.Entry) map[string][]string {
+	if len(e.Features2) == 0 {
+		return e.Features
+	}
+	features := make(map[string][]string, len(e.Features)+len(e.Features2))
+	for k, v := range e.Features {
+		features[k] = v
+	}
+	for k, v := range e.Features2 {
+		features[k] = v
+	}
+	return features
+}
+
+// WithToken returns a new Registry that sends token, as Cargo does, in the
+// Authorization header of every request to the registry: the index, its
+// web API and its downloads. Requests to other hosts use the client's own
+// AuthFunc.
+func (r *Registry) WithToken(token string) *Registry {
+	client := r.client
+	if client == nil {
+		client = core.DefaultClient()
+	}
+	copy := *r
+	copy.state = &indexState{}
+	fallback := client.AuthFunc
+	copy.client = client.WithAuthFunc(func(url string) (string, string) {
+		if copy.ownsURL(url) {
+			return "Authorization", token
+		}
+		if fallback != nil {
+			return fallback(url)
+		}
+		return "", ""
+	})
+	if r.index != nil {
+		copy.index = newIndex(r.baseURL, copy.client)
+	}
+	copy.urls = &URLs{baseURL: copy.baseURL, reg: &copy}
+	return &copy
+}
+
+// ownsURL reports whether url belongs to the registry: its API, its index,
+// or, once config.json has been read, its web API and download host.
+func (r *Registry) ownsURL(url string) bool {
+	under := func(base string) bool {
+		base = strings.TrimSuffix(base, "/")
+		return base != "" && (url == base || strings.HasPrefix(url, base+"/"))
+	}
+	base := strings.TrimPrefix(strings.TrimPrefix(r.baseURL, sparsePrefix), gitPrefix)
+	if under(base) {
+		return true
+	}
+	cfg := r.state.cfg.Load()
+	if cfg == nil {
+		return false
+	}
+	dl := cfg.DL
+	if i := strings.Index(dl, "{"); i >= 0 {
+		dl = dl[:strings.LastIndex(dl[:i], "/")+1]
+	}
+	return under(cfg.API) || under(dl)
+}
+
+func (r *Registry) downloadURL(ctx context.Context, name, version string) string {
+	cfg, err := r.indexConfig(ctx)
+	if err != nil {
+		return ""
+	}
+	var checksum string
+	if strings.Contains(cfg.DL, "{sha256-checksum}") {
+		entries, err := r.entries(ctx, name, version)
+		if err != nil {
+			return ""
+		}
+		for _, e := range entries {
+			if e.Vers == version {
+				checksum = e.Cksum
+			}
+		}
+	}
+	return cfg.DownloadURL(name, version, checksum)
+}
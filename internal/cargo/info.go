@@ -0,0 +1,63 @@
+package cargo
+
+import (
+	"context"
+	"strings"
+
+	"github.com/git-pkgs/registries/cargoindex"
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+// summaryResponse is crates.io's /api/v1/summary, which backs its front
+// page.
+type summaryResponse struct {
+	NumDownloads int64 `json:"num_downloads"`
+	NumCrates    int64 `json:"num_crates"`
+}
+
+// RegistryInfo returns crates.io's crate and download totals. For an
+// alternative registry it returns the index's config.json instead, whose
+// download template and API URL are in Metadata.
+func (r *Registry) RegistryInfo(ctx context.Context) (*core.RegistryInfo, error) {
+	if r.index != nil {
+		cfg, err := r.indexConfig(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return &core.RegistryInfo{
+			Ecosystem: ecosystem,
+			URL:       r.baseURL,
+			Metadata: map[string]any{
+				"dl":            cfg.DL,
+				"api":           cfg.API,
+				"auth_required": cfg.AuthRequired,
+			},
+		}, nil
+	}
+
+	var resp summaryResponse
+	if err := r.client.GetJSON(ctx, r.baseURL+"/api/v1/summary", &resp); err != nil {
+		return nil, err
+	}
+	return &core.RegistryInfo{
+		Ecosystem: ecosystem,
+		URL:       r.baseURL,
+		Packages:  resp.NumCrates,
+		Downloads: resp.NumDownloads,
+	}, nil
+}
+
+// Ping reads the summary from crates.io, or config.json from an
+// alternative registry's index, which is the first thing Cargo reads and
+// needs the token where the registry requires one. Git indexes are fetched.
+func (r *Registry) Ping(ctx context.Context) error {
+	switch idx := r.index.(type) {
+	case nil:
+		return core.PingURL(ctx, r.client, r.baseURL+"/api/v1/summary")
+	case *gitIndex:
+		return idx.idx.Fetch(ctx)
+	}
+	url := strings.TrimSuffix(strings.TrimPrefix(r.baseURL, sparsePrefix), "/")
+	_, err := cargoindex.NewSparse(r.client.WithCache(nil).WithoutDeduplication(), url).Config(ctx)
+	return err
+}
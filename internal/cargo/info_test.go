@@ -0,0 +1,67 @@
+package cargo
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+func TestRegistryInfo(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/summary" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(`{"num_downloads":123456789,"num_crates":150000,"new_crates":[]}`))
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	info, err := reg.RegistryInfo(context.Background())
+	if err != nil {
+		t.Fatalf("RegistryInfo failed: %v", err)
+	}
+	if info.Packages != 150000 || info.Downloads != 123456789 {
+		t.Errorf("unexpected info %+v", info)
+	}
+	if err := reg.Ping(context.Background()); err != nil {
+		t.Errorf("Ping failed: %v", err)
+	}
+}
+
+func TestRegistryInfoAlternative(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "s3cret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path != "/index/config.json" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(`{"dl":"https://dl.example.com","api":"https://api.example.com","auth-required":true}`))
+	}))
+	defer server.Close()
+
+	reg := New("sparse+"+server.URL+"/index/", core.DefaultClient())
+	ctx := context.Background()
+
+	if err := reg.Ping(ctx); err == nil {
+		t.Error("expected Ping without a token to fail")
+	}
+
+	reg = reg.WithToken("s3cret")
+	if err := reg.Ping(ctx); err != nil {
+		t.Fatalf("Ping failed: %v", err)
+	}
+	info, err := reg.RegistryInfo(ctx)
+	if err != nil {
+		t.Fatalf("RegistryInfo failed: %v", err)
+	}
+	if info.Metadata["api"] != "https://api.example.com" || info.Metadata["auth_required"] != true {
+		t.Errorf("unexpected info %+v", info)
+	}
+}
@@ -0,0 +1,75 @@
+package cargo
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+type ownerIDResponse struct {
+	User *struct {
+		ID int `json:"id"`
+	} `json:"user"`
+	Team *struct {
+		ID int `json:"id"`
+	} `json:"team"`
+}
+
+type crateListResponse struct {
+	Crates []struct {
+		Name string `json:"name"`
+	} `json:"crates"`
+	Meta struct {
+		NextPage string `json:"next_page"`
+	} `json:"meta"`
+}
+
+// ListPackagesByNamespace returns the crates owned by a crates.io user,
+// given by GitHub login, or by a team, given as "github:org:team". The
+// crate list is paged, so large owners take a request per hundred crates.
+// Alternative registries read from an index can't list owners' crates.
+func (r *Registry) ListPackagesByNamespace(ctx context.Context, namespace string) ([]string, error) {
+	if r.index != nil {
+		return nil, fmt.Errorf("%s namespace listing from an index: %w", ecosystem, core.ErrNotSupported)
+	}
+
+	kind, param := "users", "user_id"
+	if strings.Contains(namespace, ":") {
+		kind, param = "teams", "team_id"
+	}
+	var owner ownerIDResponse
+	if err := r.client.GetJSON(ctx, fmt.Sprintf("%s/api/v1/%s/%s", r.baseURL, kind, url.PathEscape(namespace)), &owner); err != nil {
+		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
+			return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: namespace}
+		}
+		return nil, err
+	}
+	var id int
+	switch {
+	case owner.User != nil:
+		id = owner.User.ID
+	case owner.Team != nil:
+		id = owner.Team.ID
+	default:
+		return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: namespace}
+	}
+
+	var names []string
+	query := fmt.Sprintf("?%s=%d&per_page=100", param, id)
+	for query != "" {
+		var page crateListResponse
+		if err := r.client.GetJSON(ctx, r.baseURL+"/api/v1/crates"+query, &page); err != nil {
+			return nil, err
+		}
+		for _, c := range page.Crates {
+			names = append(names, c.Name)
+		}
+		query = page.Meta.NextPage
+	}
+	sort.Strings(names)
+	return names, nil
+}
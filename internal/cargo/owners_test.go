@@ -0,0 +1,52 @@
+package cargo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+func TestListPackagesByNamespace(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path + "?" + r.URL.RawQuery {
+		case "/api/v1/users/dtolnay?":
+			_, _ = w.Write([]byte(`{"user":{"id":3618,"login":"dtolnay"}}`))
+		case "/api/v1/crates?user_id=3618&per_page=100":
+			_, _ = w.Write([]byte(`{"crates":[{"name":"syn"},{"name":"anyhow"}],"meta":{"total":3,"next_page":"?user_id=3618&per_page=100&page=2"}}`))
+		case "/api/v1/crates?user_id=3618&per_page=100&page=2":
+			_, _ = w.Write([]byte(`{"crates":[{"name":"serde_json"}],"meta":{"total":3,"next_page":null}}`))
+		case "/api/v1/teams/github:serde-rs:publish?":
+			_, _ = w.Write([]byte(`{"team":{"id":77,"login":"github:serde-rs:publish"}}`))
+		case "/api/v1/crates?team_id=77&per_page=100":
+			_, _ = w.Write([]byte(`{"crates":[{"name":"serde"}],"meta":{"total":1,"next_page":null}}`))
+		default:
+			w.WriteHeader(404)
+		}
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	ctx := context.Background()
+
+	names, err := reg.ListPackagesByNamespace(ctx, "dtolnay")
+	if err != nil {
+		t.Fatalf("ListPackagesByNamespace failed: %v", err)
+	}
+	if len(names) != 3 || names[0] != "anyhow" || names[2] != "syn" {
+		t.Errorf("unexpected names %v", names)
+	}
+
+	names, err = reg.ListPackagesByNamespace(ctx, "github:serde-rs:publish")
+	if err != nil || len(names) != 1 || names[0] != "serde" {
+		t.Errorf("team: names = %v, err = %v", names, err)
+	}
+
+	if _, err := reg.ListPackagesByNamespace(ctx, "nobody"); !errors.Is(err, core.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
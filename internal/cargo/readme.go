@@ -0,0 +1,64 @@
+package cargo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+// FetchReadme returns the README crates.io rendered to HTML when the version
+// was published. If version is empty, the highest stable version is used.
+func (r *Registry) FetchReadme(ctx context.Context, name, version string) (*core.Document, error) {
+	if r.index != nil {
+		return nil, fmt.Errorf("cargo readme from %s: %w", r.baseURL, core.ErrNotSupported)
+	}
+	if version == "" {
+		var resp crateResponse
+		if err := r.client.GetJSON(ctx, fmt.Sprintf("%s/api/v1/crates/%s", r.baseURL, name), &resp); err != nil {
+			if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
+				return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name}
+			}
+			return nil, err
+		}
+		version = resp.Crate.MaxStableVersion
+		if version == "" {
+			version = resp.Crate.MaxVersion
+		}
+	}
+
+	url := fmt.Sprintf("%s/api/v1/crates/%s/%s/readme", r.baseURL, name, version)
+	body, err := r.client.GetBody(ctx, url)
+	if err != nil {
+		return nil, readmeError(err, name, version)
+	}
+
+	// Asked for JSON, crates.io answers with the location of the rendered
+	// file instead of redirecting to it
+	var redirect struct {
+		URL string `json:"url"`
+	}
+	if json.Unmarshal(body, &redirect) == nil && redirect.URL != "" {
+		url = redirect.URL
+		if body, err = r.client.GetBody(ctx, url); err != nil {
+			return nil, readmeError(err, name, version)
+		}
+	}
+
+	return &core.Document{
+		Content:     string(body),
+		ContentType: core.HTML,
+		URL:         url,
+	}, nil
+}
+
+// readmeError maps a missing README to NotFoundError. The static file host
+// answers 403 rather than 404 for files that don't exist.
+func readmeError(err error, name, version string) error {
+	if httpErr, ok := err.(*core.HTTPError); ok && (httpErr.IsNotFound() || httpErr.StatusCode == http.StatusForbidden) {
+		return &core.NotFoundError{Ecosystem: ecosystem, Name: name, Version: version}
+	}
+	return err
+}
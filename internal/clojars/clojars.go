@@ -52,7 +52,10 @@ type artifactResponse struct {
 	JarName      string        `json:"jar_name"`
 	Description  string        `json:"description"`
 	Homepage     string        `json:"homepage"`
+	Downloads    int           `json:"downloads"`
 	RecentVersions []versionInfo `json:"recent_versions"`
+	LatestVersion  string        `json:"latest_version"`
+	LatestRelease  string        `json:"latest_release"`
 }
 
 type versionInfo struct {
@@ -122,6 +125,21 @@ func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package
 			"group_name": resp.GroupName,
 			"jar_name":   resp.JarName,
 		},
+		Popularity: &core.Popularity{DownloadsTotal: int64(resp.Downloads)},
+	}
+
+	var recent []string
+	for _, v := range resp.RecentVersions {
+		recent = append(recent, v.Version)
+	}
+	pkg.LatestVersion = resp.LatestVersion
+	if pkg.LatestVersion == "" && len(recent) > 0 {
+		pkg.LatestVersion = recent[0]
+	}
+	// latest_release leaves out snapshots but not alphas or RCs
+	pkg.LatestStableVersion = resp.LatestRelease
+	if pkg.LatestStableVersion == "" || core.IsPrerelease(ecosystem, pkg.LatestStableVersion) {
+		pkg.LatestStableVersion = core.LatestStableOf(ecosystem, recent)
 	}
 
 	// Try to get more details from the latest version
@@ -212,18 +230,13 @@ func mapScope(scope string) core.Scope {
 		return core.Runtime
 	case "test":
 		return core.Test
-	case "provided":
+	case "provided", "system", "import":
 		return core.Build
 	default:
 		return core.Runtime
 	}
 }
 
-func (r *Registry) FetchMaintainers(ctx context.Context, name string) ([]core.Maintainer, error) {
-	// Clojars API doesn't expose maintainers directly
-	return nil, nil
-}
-
 type URLs struct {
 	baseURL string
 }
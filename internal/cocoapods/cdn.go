@@ -0,0 +1,169 @@
+package cocoapods
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/git-pkgs/registries/internal/core"
+	"github.com/git-pkgs/vers"
+)
+
+// CDNURL is the spec source the CocoaPods CLI reads from by default.
+const CDNURL = "https://cdn.cocoapods.org"
+
+// isSpecSource reports whether baseURL names a spec source in the CDN layout
+// rather than a trunk API. Trunk is always served from a host root, while
+// spec sources mirrored elsewhere (an Artifactory remote, a private repo
+// served over HTTP) live under a path.
+func isSpecSource(baseURL string) bool {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return false
+	}
+	return u.Host == "cdn.cocoapods.org" || strings.Trim(u.Path, "/") != ""
+}
+
+// WithSpecSource returns a copy of the registry that reads podspecs from a
+// spec source in the CDN layout at its base URL instead of the trunk API.
+func (r *Registry) WithSpecSource() *Registry {
+	copy := *r
+	copy.specSource = true
+	return &copy
+}
+
+// shard returns the directory prefix for a pod. Spec sources shard pods by
+// the first three hex digits of the MD5 of the pod name.
+func shard(name string) []string {
+	sum := md5.Sum([]byte(name))
+	h := hex.EncodeToString(sum[:])
+	return []string{h[0:1], h[1:2], h[2:3]}
+}
+
+// sourceVersions reads a pod's versions from its all_pods_versions shard,
+// in which each line is "Name/1.0.0/1.0.1/...".
+func (r *Registry) sourceVersions(ctx context.Context, name string) ([]string, error) {
+	indexURL := fmt.Sprintf("%s/all_pods_versions_%s.txt", r.baseURL, strings.Join(shard(name), "_"))
+
+	body, err := r.client.GetText(ctx, indexURL)
+	if err != nil {
+		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
+			return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name}
+		}
+		return nil, err
+	}
+
+	for _, line := range strings.Split(body, "\n") {
+		fields := strings.Split(strings.TrimSpace(line), "/")
+		if fields[0] == name && len(fields) > 1 {
+			return fields[1:], nil
+		}
+	}
+	return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name}
+}
+
+func (r *Registry) sourceSpec(ctx context.Context, name, version string) (*podSpec, error) {
+	specURL := fmt.Sprintf("%s/Specs/%s/%s/%s/%s.podspec.json",
+		r.baseURL, strings.Join(shard(name), "/"), name, version, name)
+
+	var spec podSpec
+	if err := r.client.GetJSON(ctx, specURL, &spec); err != nil {
+		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
+			return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name, Version: version}
+		}
+		return nil, err
+	}
+	return &spec, nil
+}
+
+func latestVersion(versions []string) string {
+	sorted := append([]string(nil), versions...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return vers.Compare(sorted[i], sorted[j]) > 0
+	})
+	return sorted[0]
+}
+
+func (r *Registry) fetchPackageFromSource(ctx context.Context, name string) (*core.Package, error) {
+	versions, err := r.sourceVersions(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	latest := latestVersion(versions)
+
+	spec, err := r.sourceSpec(ctx, name, latest)
+	if err != nil {
+		return nil, err
+	}
+
+	pkg := packageFromSpec(name, spec)
+	pkg.LatestVersion = latest
+	return pkg, nil
+}
+
+func (r *Registry) fetchVersionsFromSource(ctx context.Context, name string) ([]core.Version, error) {
+	numbers, err := r.sourceVersions(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	// The shard lists versions only; publish times and licenses would take
+	// a podspec request per version.
+	versions := make([]core.Version, len(numbers))
+	for i, number := range numbers {
+		versions[i] = core.Version{Number: number}
+	}
+	return versions, nil
+}
+
+func (r *Registry) fetchDependenciesFromSource(ctx context.Context, name, version string) ([]core.Dependency, error) {
+	spec, err := r.sourceSpec(ctx, name, version)
+	if err != nil {
+		return nil, err
+	}
+	return specDependencies(spec), nil
+}
+
+func (r *Registry) fetchMaintainersFromSource(ctx context.Context, name string) ([]core.Maintainer, error) {
+	versions, err := r.sourceVersions(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	spec, err := r.sourceSpec(ctx, name, latestVersion(versions))
+	if err != nil {
+		return nil, err
+	}
+	return specAuthors(spec.Authors), nil
+}
+
+// specAuthors reads the authors attribute, which is a name, a list of names,
+// or a map of name to email.
+func specAuthors(authors any) []core.Maintainer {
+	var maintainers []core.Maintainer
+	switch v := authors.(type) {
+	case string:
+		maintainers = append(maintainers, core.Maintainer{Name: v})
+	case []any:
+		for _, a := range v {
+			if s, ok := a.(string); ok {
+				maintainers = append(maintainers, core.Maintainer{Name: s})
+			}
+		}
+	case map[string]any:
+		names := make([]string, 0, len(v))
+		for n := range v {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		for _, n := range names {
+			email, _ := v[n].(string)
+			maintainers = append(maintainers, core.Maintainer{Name: n, Email: email})
+		}
+	}
+	return maintainers
+}
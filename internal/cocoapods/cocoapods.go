@@ -22,9 +22,10 @@ func init() {
 }
 
 type Registry struct {
-	baseURL string
-	client  *core.Client
-	urls    *URLs
+	baseURL    string
+	client     *core.Client
+	urls       *URLs
+	specSource bool
 }
 
 func New(baseURL string, client *core.Client) *Registry {
@@ -35,6 +36,7 @@ func New(baseURL string, client *core.Client) *Registry {
 		baseURL: strings.TrimSuffix(baseURL, "/"),
 		client:  client,
 	}
+	r.specSource = isSpecSource(r.baseURL)
 	r.urls = &URLs{baseURL: r.baseURL}
 	return r
 }
@@ -79,6 +81,10 @@ type ownerInfo struct {
 }
 
 func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package, error) {
+	if r.specSource {
+		return r.fetchPackageFromSource(ctx, name)
+	}
+
 	url := fmt.Sprintf("%s/api/v1/pods/%s", r.baseURL, name)
 
 	var resp podResponse
@@ -102,24 +108,47 @@ func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package
 		latestSpec = &resp.Versions[len(resp.Versions)-1].Spec
 	}
 
-	pkg := &core.Package{
-		Name: resp.Name,
+	if latestSpec == nil {
+		return &core.Package{Name: resp.Name}, nil
 	}
 
-	if latestSpec != nil {
-		pkg.Description = latestSpec.Summary
-		if pkg.Description == "" {
-			pkg.Description = latestSpec.Description
-		}
-		pkg.Homepage = latestSpec.Homepage
-		pkg.Repository = core.ExtractRepoURL(latestSpec.Source)
-		pkg.Licenses = core.ExtractLicense(latestSpec.License)
+	var numbers []string
+	for _, v := range resp.Versions {
+		numbers = append(numbers, v.Name)
 	}
 
+	pkg := packageFromSpec(resp.Name, latestSpec)
+	pkg.LatestVersion = latestSpec.Version
+	pkg.LatestStableVersion = core.LatestStableOf(ecosystem, numbers)
 	return pkg, nil
 }
 
+// packageFromSpec builds package metadata from a podspec, including the
+// platforms it supports and their minimum deployment targets.
+func packageFromSpec(name string, spec *podSpec) *core.Package {
+	pkg := &core.Package{
+		Name:        name,
+		Description: spec.Summary,
+		Homepage:    spec.Homepage,
+		Repository:  core.ExtractRepoURL(spec.Source),
+		Licenses:    core.ExtractLicense(spec.License),
+	}
+	if pkg.Description == "" {
+		pkg.Description = spec.Description
+	}
+	if len(spec.Platforms) > 0 {
+		pkg.Metadata = map[string]any{
+			"platforms": spec.Platforms,
+		}
+	}
+	return pkg
+}
+
 func (r *Registry) FetchVersions(ctx context.Context, name string) ([]core.Version, error) {
+	if r.specSource {
+		return r.fetchVersionsFromSource(ctx, name)
+	}
+
 	url := fmt.Sprintf("%s/api/v1/pods/%s", r.baseURL, name)
 
 	var resp podResponse
@@ -137,12 +166,21 @@ func (r *Registry) FetchVersions(ctx context.Context, name string) ([]core.Versi
 			PublishedAt: v.CreatedAt,
 			Licenses:    core.ExtractLicense(v.Spec.License),
 		}
+		if len(v.Spec.Platforms) > 0 {
+			versions[i].Metadata = map[string]any{
+				"platforms": v.Spec.Platforms,
+			}
+		}
 	}
 
 	return versions, nil
 }
 
 func (r *Registry) FetchDependencies(ctx context.Context, name, version string) ([]core.Dependency, error) {
+	if r.specSource {
+		return r.fetchDependenciesFromSource(ctx, name, version)
+	}
+
 	url := fmt.Sprintf("%s/api/v1/pods/%s", r.baseURL, name)
 
 	var resp podResponse
@@ -166,6 +204,10 @@ func (r *Registry) FetchDependencies(ctx context.Context, name, version string)
 		return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name, Version: version}
 	}
 
+	return specDependencies(spec), nil
+}
+
+func specDependencies(spec *podSpec) []core.Dependency {
 	var deps []core.Dependency
 	for depName, req := range spec.Dependencies {
 		deps = append(deps, core.Dependency{
@@ -174,8 +216,7 @@ func (r *Registry) FetchDependencies(ctx context.Context, name, version string)
 			Scope:        core.Runtime,
 		})
 	}
-
-	return deps, nil
+	return deps
 }
 
 func formatRequirement(req interface{}) string {
@@ -195,6 +236,10 @@ func formatRequirement(req interface{}) string {
 }
 
 func (r *Registry) FetchMaintainers(ctx context.Context, name string) ([]core.Maintainer, error) {
+	if r.specSource {
+		return r.fetchMaintainersFromSource(ctx, name)
+	}
+
 	url := fmt.Sprintf("%s/api/v1/pods/%s", r.baseURL, name)
 
 	var resp podResponse
@@ -3,8 +3,10 @@ package cocoapods
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -233,3 +235,97 @@ func TestEcosystem(t *testing.T) {
 		t.Errorf("expected ecosystem 'cocoapods', got %q", reg.Ecosystem())
 	}
 }
+
+func TestShard(t *testing.T) {
+	if got := strings.Join(shard("Alamofire"), "/"); got != "d/a/2" {
+		t.Errorf("expected Alamofire to be sharded under d/a/2, got %q", got)
+	}
+}
+
+func TestIsSpecSource(t *testing.T) {
+	tests := map[string]bool{
+		"https://trunk.cocoapods.org":                                    false,
+		"https://cdn.cocoapods.org":                                      true,
+		"https://artifactory.example.com/artifactory/api/pods/cocoapods": true,
+		"http://127.0.0.1:8080":                                          false,
+	}
+	for baseURL, want := range tests {
+		if got := isSpecSource(baseURL); got != want {
+			t.Errorf("isSpecSource(%q) = %v, want %v", baseURL, got, want)
+		}
+	}
+}
+
+func TestSpecSource(t *testing.T) {
+	spec := `{
+		"name": "Alamofire",
+		"version": "5.10.0",
+		"summary": "Elegant HTTP Networking in Swift",
+		"homepage": "https://github.com/Alamofire/Alamofire",
+		"license": "MIT",
+		"authors": {"Alamofire Software Foundation": "info@alamofire.org"},
+		"source": {"git": "https://github.com/Alamofire/Alamofire.git", "tag": "5.10.0"},
+		"platforms": {"ios": "12.0", "osx": "10.13", "tvos": "12.0", "watchos": "4.0"},
+		"dependencies": {"SwiftyJSON": ["~> 5.0"]}
+	}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/all_pods_versions_d_a_2.txt":
+			_, _ = w.Write([]byte("AlamofireImage/4.3.0\nAlamofire/5.9.1/5.10.0/5.8.0\n"))
+		case "/Specs/d/a/2/Alamofire/5.10.0/Alamofire.podspec.json":
+			_, _ = w.Write([]byte(spec))
+		case "/all_pods_versions_4_a_e.txt":
+			_, _ = w.Write([]byte("Other/1.0.0\n"))
+		default:
+			t.Errorf("unexpected path: %s", r.URL.Path)
+			w.WriteHeader(404)
+		}
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient()).WithSpecSource()
+	ctx := context.Background()
+
+	pkg, err := reg.FetchPackage(ctx, "Alamofire")
+	if err != nil {
+		t.Fatalf("FetchPackage failed: %v", err)
+	}
+	if pkg.LatestVersion != "5.10.0" {
+		t.Errorf("unexpected latest version: %q", pkg.LatestVersion)
+	}
+	if pkg.Repository != "https://github.com/Alamofire/Alamofire" {
+		t.Errorf("unexpected repository: %q", pkg.Repository)
+	}
+	platforms, _ := pkg.Metadata["platforms"].(map[string]string)
+	if platforms["ios"] != "12.0" || platforms["watchos"] != "4.0" {
+		t.Errorf("unexpected platforms: %v", pkg.Metadata["platforms"])
+	}
+
+	versions, err := reg.FetchVersions(ctx, "Alamofire")
+	if err != nil {
+		t.Fatalf("FetchVersions failed: %v", err)
+	}
+	if len(versions) != 3 || versions[0].Number != "5.9.1" {
+		t.Errorf("unexpected versions: %+v", versions)
+	}
+
+	deps, err := reg.FetchDependencies(ctx, "Alamofire", "5.10.0")
+	if err != nil {
+		t.Fatalf("FetchDependencies failed: %v", err)
+	}
+	if len(deps) != 1 || deps[0].Name != "SwiftyJSON" || deps[0].Requirements != "~> 5.0" {
+		t.Errorf("unexpected dependencies: %+v", deps)
+	}
+
+	maintainers, err := reg.FetchMaintainers(ctx, "Alamofire")
+	if err != nil {
+		t.Fatalf("FetchMaintainers failed: %v", err)
+	}
+	if len(maintainers) != 1 || maintainers[0].Email != "info@alamofire.org" {
+		t.Errorf("unexpected maintainers: %+v", maintainers)
+	}
+
+	if _, err := reg.FetchPackage(ctx, "Nonexistent"); !errors.Is(err, core.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
@@ -4,11 +4,15 @@ package conda
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/git-pkgs/purl"
 	"github.com/git-pkgs/registries/internal/core"
 	"github.com/git-pkgs/registries/internal/urlparser"
+	"github.com/git-pkgs/registries/metadata"
 )
 
 const (
@@ -24,10 +28,10 @@ func init() {
 }
 
 type Registry struct {
-	baseURL string
-	channel string
-	client  *core.Client
-	urls    *URLs
+	baseURL  string
+	channels []string // in priority order
+	client   *core.Client
+	urls     *URLs
 }
 
 func New(baseURL string, client *core.Client) *Registry {
@@ -36,21 +40,87 @@ func New(baseURL string, client *core.Client) *Registry {
 	}
 	r := &Registry{
 		baseURL: strings.TrimSuffix(baseURL, "/"),
-		channel: DefaultChannel,
 		client:  client,
 	}
-	r.urls = &URLs{baseURL: r.baseURL, channel: r.channel}
-	return r
+	return r.WithChannels(DefaultChannel)
 }
 
 // WithChannel returns a new Registry configured to use the specified channel
 func (r *Registry) WithChannel(channel string) *Registry {
+	return r.WithChannels(channel)
+}
+
+// WithChannels returns a new Registry that looks packages up in several
+// channels in priority order, as conda does with strict channel priority:
+// a package comes entirely from the first channel that has it, even if a
+// later channel has newer versions. The channel that satisfied a lookup is
+// the package's Namespace and is in the "channel" metadata of the package
+// and its versions. "defaults" names the anaconda channel, anaconda.org's
+// copy of the default channels. Names of the form "channel/name" are only
+// looked up in the channel they name.
+func (r *Registry) WithChannels(channels ...string) *Registry {
+	list := make([]string, 0, len(channels))
+	for _, c := range channels {
+		if c = channelAlias(strings.TrimSpace(c)); c != "" {
+			list = append(list, c)
+		}
+	}
+	if len(list) == 0 {
+		list = []string{DefaultChannel}
+	}
 	return &Registry{
-		baseURL: r.baseURL,
-		channel: channel,
-		client:  r.client,
-		urls:    &URLs{baseURL: r.baseURL, channel: channel},
+		baseURL:  r.baseURL,
+		channels: list,
+		client:   r.client,
+		urls:     &URLs{baseURL: r.baseURL, channel: list[0], resolved: &sync.Map{}},
+	}
+}
+
+// WithToken returns a new Registry that sends an anaconda.org API token
+// with every request to the registry, as anaconda-client does, so private
+// packages and channels of an organization resolve. Requests to other
+// hosts use the client's own AuthFunc.
+func (r *Registry) WithToken(token string) *Registry {
+	client := r.client
+	if client == nil {
+		client = core.DefaultClient()
+	}
+	copy := *r
+	fallback := client.AuthFunc
+	copy.client = client.WithAuthFunc(func(url string) (string, string) {
+		if url == r.baseURL || strings.HasPrefix(url, r.baseURL+"/") {
+			return "Authorization", "token " + token
+		}
+		if fallback != nil {
+			return fallback(url)
+		}
+		return "", ""
+	})
+	return &copy
+}
+
+// Channels returns the channels the registry searches, highest priority
+// first.
+func (r *Registry) Channels() []string {
+	return append([]string(nil), r.channels...)
+}
+
+// channelAlias maps conda's "defaults" to the anaconda.org channel holding
+// the same packages.
+func channelAlias(channel string) string {
+	if channel == "defaults" {
+		return "anaconda"
+	}
+	return channel
+}
+
+// WithQualifiers applies the channel qualifier of a PURL, which may list
+// several channels in priority order separated by commas.
+func (r *Registry) WithQualifiers(qualifiers map[string]string) core.Registry {
+	if channel := qualifiers["channel"]; channel != "" {
+		return r.WithChannels(strings.Split(channel, ",")...)
 	}
+	return r
 }
 
 func (r *Registry) Ecosystem() string {
@@ -81,44 +151,123 @@ type packageResponse struct {
 type fileInfo struct {
 	Version   string            `json:"version"`
 	Basename  string            `json:"basename"`
+	DownloadURL string          `json:"download_url"`
 	Attrs     fileAttrs         `json:"attrs"`
 	UploadTime int64            `json:"upload_time"`
 	MD5       string            `json:"md5"`
 	SHA256    string            `json:"sha256"`
 	Size      int64             `json:"size"`
 	Ndownloads int64            `json:"ndownloads"`
+	Labels    []string          `json:"labels"`
 }
 
 type fileAttrs struct {
 	Depends  []string `json:"depends"`
 	Arch     string   `json:"arch"`
 	Platform string   `json:"platform"`
+	Subdir   string   `json:"subdir"`
+	Build    string   `json:"build"`
 	BuildNumber int   `json:"build_number"`
 }
 
 // parsePackageName parses a package name that may include a channel prefix
-// Format: "channel/name" or just "name" (uses default channel)
+// Format: "channel/name", "channel/label/<label>/name" or just "name" (uses
+// default channel)
 func parsePackageName(name string) (channel, pkgName string) {
-	parts := strings.SplitN(name, "/", 2)
-	if len(parts) == 2 {
-		return parts[0], parts[1]
+	parts := strings.Split(name, "/")
+	switch {
+	case len(parts) == 4 && parts[1] == "label":
+		return strings.Join(parts[:3], "/"), parts[3]
+	case len(parts) >= 2:
+		return parts[0], strings.Join(parts[1:], "/")
 	}
 	return "", name
 }
 
-func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package, error) {
-	channel, pkgName := parsePackageName(name)
-	if channel == "" {
-		channel = r.channel
+// splitChannel splits a channel such as "conda-forge/label/broken" into the
+// anaconda.org owner and the label its files must carry. A channel without
+// a label has every file of the owner's package, whatever its labels.
+func splitChannel(channel string) (owner, label string) {
+	owner, rest, ok := strings.Cut(channel, "/label/")
+	if !ok {
+		return channel, ""
+	}
+	return owner, rest
+}
+
+// lookup fetches a package from the first channel that has it, returning
+// the channel that satisfied the lookup. A channel that doesn't have the
+// package, or has no files under the channel's label, moves the search on
+// to the next one; any other failure ends it.
+func (r *Registry) lookup(ctx context.Context, name, version string) (*packageResponse, string, error) {
+	named, pkgName := parsePackageName(name)
+	channels := r.channels
+	if named != "" {
+		channels = []string{channelAlias(named)}
 	}
 
-	url := fmt.Sprintf("%s/package/%s/%s", r.baseURL, channel, pkgName)
+	for _, channel := range channels {
+		owner, label := splitChannel(channel)
+		url := fmt.Sprintf("%s/package/%s/%s", r.baseURL, owner, pkgName)
 
-	var resp packageResponse
-	if err := r.client.GetJSON(ctx, url, &resp); err != nil {
-		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
-			return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name}
+		var resp packageResponse
+		if err := r.client.GetJSON(ctx, url, &resp); err != nil {
+			if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
+				continue
+			}
+			return nil, "", err
+		}
+		if label != "" && !resp.keepLabel(label) {
+			continue
+		}
+		if named == "" {
+			r.urls.resolved.Store(pkgName, channel)
 		}
+		return &resp, channel, nil
+	}
+	return nil, "", &core.NotFoundError{Ecosystem: ecosystem, Name: name, Version: version}
+}
+
+// keepLabel drops the files and versions not published under label,
+// reporting whether any are left. The latest version becomes the one with
+// the most recently uploaded file under the label.
+func (resp *packageResponse) keepLabel(label string) bool {
+	var files []fileInfo
+	versions := make(map[string]bool)
+	var latest fileInfo
+	for _, f := range resp.Files {
+		for _, l := range f.Labels {
+			if l == label {
+				files = append(files, f)
+				versions[f.Version] = true
+				if f.UploadTime >= latest.UploadTime {
+					latest = f
+				}
+				break
+			}
+		}
+	}
+	if len(files) == 0 {
+		return false
+	}
+
+	var kept []string
+	for _, v := range resp.Versions {
+		if versions[v] {
+			kept = append(kept, v)
+		}
+	}
+	resp.Files = files
+	resp.Versions = kept
+	if !versions[resp.LatestVersion] {
+		resp.LatestVersion = latest.Version
+	}
+	return true
+}
+
+func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package, error) {
+	resp, channel, err := r.lookup(ctx, name, "")
+	if err != nil {
 		return nil, err
 	}
 
@@ -137,6 +286,7 @@ func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package
 		Description:   description,
 		Homepage:      resp.HomeURL,
 		Repository:    repository,
+		Documentation: resp.DocURL,
 		Licenses:      resp.License,
 		Namespace:     channel,
 		LatestVersion: resp.LatestVersion,
@@ -150,18 +300,8 @@ func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package
 }
 
 func (r *Registry) FetchVersions(ctx context.Context, name string) ([]core.Version, error) {
-	channel, pkgName := parsePackageName(name)
-	if channel == "" {
-		channel = r.channel
-	}
-
-	url := fmt.Sprintf("%s/package/%s/%s", r.baseURL, channel, pkgName)
-
-	var resp packageResponse
-	if err := r.client.GetJSON(ctx, url, &resp); err != nil {
-		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
-			return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name}
-		}
+	resp, channel, err := r.lookup(ctx, name, "")
+	if err != nil {
 		return nil, err
 	}
 
@@ -176,9 +316,9 @@ func (r *Registry) FetchVersions(ctx context.Context, name string) ([]core.Versi
 
 			var integrity string
 			if f.SHA256 != "" {
-				integrity = "sha256-" + f.SHA256
+				integrity = core.HexIntegrity("sha256", f.SHA256)
 			} else if f.MD5 != "" {
-				integrity = "md5-" + f.MD5
+				integrity = core.HexIntegrity("md5", f.MD5)
 			}
 
 			versionMap[f.Version] = &core.Version{
@@ -188,18 +328,28 @@ func (r *Registry) FetchVersions(ctx context.Context, name string) ([]core.Versi
 				Licenses:    resp.License,
 				Metadata: map[string]any{
 					"downloads": f.Ndownloads,
+					"channel":   channel,
 				},
+				// The first build's; every build has its own in "builds"
+				Size: f.Size,
 			}
 		}
 	}
 
+	// Keep every build of a version, since each platform and Python
+	// variant is a separate artifact with its own hash
+	builds := buildsByVersion(resp.Files)
+	for number, v := range versionMap {
+		v.Metadata["builds"] = builds[number]
+	}
+
 	// Convert map to slice, ordered by Versions list
 	versions := make([]core.Version, 0, len(resp.Versions))
 	for _, v := range resp.Versions {
 		if ver, ok := versionMap[v]; ok {
 			versions = append(versions, *ver)
 		} else {
-			versions = append(versions, core.Version{Number: v})
+			versions = append(versions, core.Version{Number: v, Metadata: map[string]any{"channel": channel}})
 		}
 	}
 
@@ -207,47 +357,112 @@ func (r *Registry) FetchVersions(ctx context.Context, name string) ([]core.Versi
 }
 
 func (r *Registry) FetchDependencies(ctx context.Context, name, version string) ([]core.Dependency, error) {
-	channel, pkgName := parsePackageName(name)
-	if channel == "" {
-		channel = r.channel
-	}
-
-	url := fmt.Sprintf("%s/package/%s/%s", r.baseURL, channel, pkgName)
-
-	var resp packageResponse
-	if err := r.client.GetJSON(ctx, url, &resp); err != nil {
-		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
-			return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name, Version: version}
-		}
+	resp, _, err := r.lookup(ctx, name, version)
+	if err != nil {
 		return nil, err
 	}
 
-	// Find dependencies for the specific version
+	// Collect dependencies across every platform build of the version.
+	// Builds for different subdirs can depend on different packages, and
+	// builds for one subdir on different versions, such as one build per
+	// Python version, so each distinct requirement is kept with the builds
+	// that have it in Metadata["builds"].
 	var deps []core.Dependency
-	seen := make(map[string]bool)
+	index := make(map[string]int)
 
 	for _, f := range resp.Files {
-		if f.Version == version {
-			for _, d := range f.Attrs.Depends {
-				depName, requirements := parseDependency(d)
-				if depName == "" || seen[depName] {
-					continue
-				}
-				seen[depName] = true
-
-				deps = append(deps, core.Dependency{
-					Name:         depName,
-					Requirements: requirements,
-					Scope:        core.Runtime,
-				})
+		if f.Version != version {
+			continue
+		}
+		target := fileTarget(f.Attrs)
+		for _, d := range f.Attrs.Depends {
+			depName, requirements := parseDependency(d)
+			if depName == "" {
+				continue
+			}
+			key := target + "\x00" + depName + "\x00" + requirements
+			if i, ok := index[key]; ok {
+				deps[i].Metadata["builds"] = append(deps[i].Metadata["builds"].([]string), f.Attrs.Build)
+				continue
 			}
-			break
+			index[key] = len(deps)
+
+			deps = append(deps, core.Dependency{
+				Name:         depName,
+				Requirements: requirements,
+				Scope:        core.Runtime,
+				Target:       target,
+				Metadata:     map[string]any{"builds": []string{f.Attrs.Build}},
+			})
 		}
 	}
 
 	return deps, nil
 }
 
+// FetchBuilds returns every build of a version, sorted by subdir and then
+// build number, so lockfile tooling can choose the artifact matching its
+// platform and Python version. FetchVersions also puts them in each
+// version's "builds" metadata.
+func (r *Registry) FetchBuilds(ctx context.Context, name, version string) ([]metadata.CondaBuild, error) {
+	resp, _, err := r.lookup(ctx, name, version)
+	if err != nil {
+		return nil, err
+	}
+
+	builds, ok := buildsByVersion(resp.Files)[version]
+	if !ok {
+		return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name, Version: version}
+	}
+	return builds, nil
+}
+
+func buildsByVersion(files []fileInfo) map[string][]metadata.CondaBuild {
+	builds := make(map[string][]metadata.CondaBuild)
+	for _, f := range files {
+		b := metadata.CondaBuild{
+			Subdir:      fileTarget(f.Attrs),
+			Build:       f.Attrs.Build,
+			BuildNumber: f.Attrs.BuildNumber,
+			Filename:    f.Basename,
+			URL:         f.DownloadURL,
+			SHA256:      f.SHA256,
+			MD5:         f.MD5,
+			Size:        f.Size,
+			Depends:     f.Attrs.Depends,
+			Downloads:   f.Ndownloads,
+			Labels:      f.Labels,
+		}
+		if strings.HasPrefix(b.URL, "//") {
+			b.URL = "https:" + b.URL
+		}
+		if f.UploadTime > 0 {
+			b.UploadedAt = time.Unix(f.UploadTime, 0)
+		}
+		builds[f.Version] = append(builds[f.Version], b)
+	}
+	for _, list := range builds {
+		sort.SliceStable(list, func(i, j int) bool {
+			if list[i].Subdir != list[j].Subdir {
+				return list[i].Subdir < list[j].Subdir
+			}
+			if list[i].BuildNumber != list[j].BuildNumber {
+				return list[i].BuildNumber < list[j].BuildNumber
+			}
+			return list[i].Build < list[j].Build
+		})
+	}
+	return builds
+}
+
+// fileTarget returns the conda subdir (e.g. "linux-64", "noarch") a build targets.
+func fileTarget(attrs fileAttrs) string {
+	if attrs.Subdir != "" {
+		return attrs.Subdir
+	}
+	return attrs.Platform
+}
+
 func parseDependency(dep string) (name, requirements string) {
 	// Conda dependency format: "name version_constraint" or just "name"
 	// Examples: "python >=3.8", "numpy", "pandas >=1.0,<2.0"
@@ -261,18 +476,8 @@ func parseDependency(dep string) (name, requirements string) {
 }
 
 func (r *Registry) FetchMaintainers(ctx context.Context, name string) ([]core.Maintainer, error) {
-	channel, pkgName := parsePackageName(name)
-	if channel == "" {
-		channel = r.channel
-	}
-
-	url := fmt.Sprintf("%s/package/%s/%s", r.baseURL, channel, pkgName)
-
-	var resp packageResponse
-	if err := r.client.GetJSON(ctx, url, &resp); err != nil {
-		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
-			return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name}
-		}
+	resp, _, err := r.lookup(ctx, name, "")
+	if err != nil {
 		return nil, err
 	}
 
@@ -288,13 +493,32 @@ func (r *Registry) FetchMaintainers(ctx context.Context, name string) ([]core.Ma
 type URLs struct {
 	baseURL string
 	channel string
+
+	// resolved remembers the channel that satisfied each lookup, so URLs
+	// point at the channel a package came from once it has been fetched
+	resolved *sync.Map
 }
 
-func (u *URLs) Registry(name, version string) string {
-	channel, pkgName := parsePackageName(name)
-	if channel == "" {
-		channel = u.channel
+// channelFor returns the channel of a name: the one it names, the one it
+// was found in, or the highest priority channel.
+func (u *URLs) channelFor(name string) (channel, pkgName string) {
+	channel, pkgName = parsePackageName(name)
+	if channel != "" {
+		return channelAlias(channel), pkgName
+	}
+	if u.resolved != nil {
+		if c, ok := u.resolved.Load(pkgName); ok {
+			return c.(string), pkgName
+		}
 	}
+	return u.channel, pkgName
+}
+
+// Registry links to the package's page on anaconda.org, which shows every
+// label.
+func (u *URLs) Registry(name, version string) string {
+	channel, pkgName := u.channelFor(name)
+	channel, _ = splitChannel(channel)
 	if version != "" {
 		return fmt.Sprintf("https://anaconda.org/%s/%s/%s", channel, pkgName, version)
 	}
@@ -308,20 +532,15 @@ func (u *URLs) Download(name, version string) string {
 }
 
 func (u *URLs) Documentation(name, version string) string {
-	channel, pkgName := parsePackageName(name)
-	if channel == "" {
-		channel = u.channel
-	}
+	channel, pkgName := u.channelFor(name)
+	channel, _ = splitChannel(channel)
 	return fmt.Sprintf("https://anaconda.org/%s/%s", channel, pkgName)
 }
 
+// PURL puts the channel in a qualifier as the PURL spec requires. Names of
+// the form "channel/name", from older PURLs that used the channel as the
+// namespace, are still accepted.
 func (u *URLs) PURL(name, version string) string {
-	channel, pkgName := parsePackageName(name)
-	if channel == "" {
-		channel = u.channel
-	}
-	if version != "" {
-		return fmt.Sprintf("pkg:conda/%s/%s@%s", channel, pkgName, version)
-	}
-	return fmt.Sprintf("pkg:conda/%s/%s", channel, pkgName)
+	channel, pkgName := u.channelFor(name)
+	return purl.New(ecosystem, "", pkgName, version, map[string]string{"channel": channel}).String()
 }
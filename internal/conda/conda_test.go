@@ -3,11 +3,14 @@ package conda
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"testing"
 
 	"github.com/git-pkgs/registries/internal/core"
+	"github.com/git-pkgs/registries/metadata"
 )
 
 func TestParsePackageName(t *testing.T) {
@@ -19,6 +22,7 @@ func TestParsePackageName(t *testing.T) {
 		{"numpy", "", "numpy"},
 		{"conda-forge/numpy", "conda-forge", "numpy"},
 		{"bioconda/samtools", "bioconda", "samtools"},
+		{"conda-forge/label/broken/numpy", "conda-forge/label/broken", "numpy"},
 	}
 
 	for _, tt := range tests {
@@ -114,9 +118,9 @@ func TestFetchVersions(t *testing.T) {
 			Name:     "pandas",
 			Versions: []string{"2.1.0", "2.0.3", "1.5.3"},
 			Files: []fileInfo{
-				{Version: "2.1.0", UploadTime: 1699900000, SHA256: "abc123"},
+				{Version: "2.1.0", UploadTime: 1699900000, SHA256: "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"},
 				{Version: "2.0.3", UploadTime: 1689100000, SHA256: "def456"},
-				{Version: "1.5.3", UploadTime: 1678300000, MD5: "ghi789"},
+				{Version: "1.5.3", UploadTime: 1678300000, MD5: "900150983cd24fb0d6963f7d28e17f72"},
 			},
 		}
 		_ = json.NewEncoder(w).Encode(resp)
@@ -136,7 +140,7 @@ func TestFetchVersions(t *testing.T) {
 	if versions[0].Number != "2.1.0" {
 		t.Errorf("expected version '2.1.0', got %q", versions[0].Number)
 	}
-	if versions[0].Integrity != "sha256-abc123" {
+	if versions[0].Integrity != "sha256-ungWv48Bz+pBQUDeXa4iI7ADYaOWF3qctBD/YfIAFa0=" {
 		t.Errorf("unexpected integrity: %q", versions[0].Integrity)
 	}
 	if versions[0].PublishedAt.IsZero() {
@@ -144,7 +148,7 @@ func TestFetchVersions(t *testing.T) {
 	}
 
 	// Check MD5 fallback
-	if versions[2].Integrity != "md5-ghi789" {
+	if versions[2].Integrity != "md5-kAFQmDzST7DWlj99KOF/cg==" {
 		t.Errorf("expected md5 integrity, got %q", versions[2].Integrity)
 	}
 }
@@ -194,6 +198,130 @@ func TestFetchDependencies(t *testing.T) {
 	}
 }
 
+func TestFetchDependenciesPerPlatform(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resp := packageResponse{
+			Name: "psutil",
+			Files: []fileInfo{
+				{
+					Version: "5.9.0",
+					Attrs:   fileAttrs{Subdir: "linux-64", Build: "py39h0", Depends: []string{"python >=3.9", "libgcc-ng >=12"}},
+				},
+				{
+					Version: "5.9.0",
+					Attrs:   fileAttrs{Subdir: "linux-64", Build: "py310h0", Depends: []string{"python >=3.10", "libgcc-ng >=12"}},
+				},
+				{
+					Version: "5.9.0",
+					Attrs:   fileAttrs{Subdir: "win-64", Build: "py39h1", Depends: []string{"python >=3.9", "vc >=14"}},
+				},
+			},
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	deps, err := reg.FetchDependencies(context.Background(), "psutil", "5.9.0")
+	if err != nil {
+		t.Fatalf("FetchDependencies failed: %v", err)
+	}
+
+	// Both linux-64 builds' python requirements are kept
+	if len(deps) != 5 {
+		t.Fatalf("expected 5 dependencies, got %+v", deps)
+	}
+
+	targets := make(map[string]string)
+	builds := make(map[string][]string)
+	for _, d := range deps {
+		targets[d.Name+"@"+d.Target] = d.Requirements
+		builds[d.Name+" "+d.Requirements+"@"+d.Target], _ = d.Metadata["builds"].([]string)
+	}
+	if got := builds["python >=3.10@linux-64"]; len(got) != 1 || got[0] != "py310h0" {
+		t.Errorf("python >=3.10 builds = %v", got)
+	}
+	if got := builds["libgcc-ng >=12@linux-64"]; len(got) != 2 {
+		t.Errorf("libgcc-ng builds = %v, want both linux-64 builds", got)
+	}
+
+	if targets["libgcc-ng@linux-64"] != ">=12" {
+		t.Errorf("expected libgcc-ng for linux-64, got %v", targets)
+	}
+	if targets["vc@win-64"] != ">=14" {
+		t.Errorf("expected vc for win-64, got %v", targets)
+	}
+	if targets["python@win-64"] != ">=3.9" {
+		t.Errorf("expected python for win-64, got %v", targets)
+	}
+}
+
+func TestFetchBuilds(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resp := packageResponse{
+			Name:     "numpy",
+			Versions: []string{"1.26.0"},
+			Files: []fileInfo{
+				{
+					Version:     "1.26.0",
+					Basename:    "win-64/numpy-1.26.0-py312h8753938_0.conda",
+					DownloadURL: "//api.anaconda.org/download/conda-forge/numpy/1.26.0/win-64/numpy-1.26.0-py312h8753938_0.conda",
+					SHA256:      "ccc",
+					Attrs:       fileAttrs{Subdir: "win-64", Build: "py312h8753938_0", Depends: []string{"python >=3.12,<3.13.0a0"}},
+				},
+				{
+					Version:  "1.26.0",
+					Basename: "linux-64/numpy-1.26.0-py312heda63a1_1.conda",
+					SHA256:   "bbb",
+					Attrs:    fileAttrs{Subdir: "linux-64", Build: "py312heda63a1_1", BuildNumber: 1},
+				},
+				{
+					Version:  "1.26.0",
+					Basename: "linux-64/numpy-1.26.0-py311h64a7726_0.conda",
+					SHA256:   "aaa",
+					Attrs:    fileAttrs{Subdir: "linux-64", Build: "py311h64a7726_0"},
+				},
+			},
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	builds, err := reg.FetchBuilds(context.Background(), "numpy", "1.26.0")
+	if err != nil {
+		t.Fatalf("FetchBuilds failed: %v", err)
+	}
+
+	want := []string{"linux-64/py311h64a7726_0", "linux-64/py312heda63a1_1", "win-64/py312h8753938_0"}
+	if len(builds) != len(want) {
+		t.Fatalf("expected %d builds, got %d", len(want), len(builds))
+	}
+	for i, b := range builds {
+		if got := b.Subdir + "/" + b.Build; got != want[i] {
+			t.Errorf("build %d = %q, want %q", i, got, want[i])
+		}
+	}
+	if builds[2].URL != "https://api.anaconda.org/download/conda-forge/numpy/1.26.0/win-64/numpy-1.26.0-py312h8753938_0.conda" {
+		t.Errorf("unexpected URL %q", builds[2].URL)
+	}
+	if builds[2].SHA256 != "ccc" || len(builds[2].Depends) != 1 {
+		t.Errorf("unexpected build %+v", builds[2])
+	}
+
+	versions, err := reg.FetchVersions(context.Background(), "numpy")
+	if err != nil {
+		t.Fatalf("FetchVersions failed: %v", err)
+	}
+	if got, ok := versions[0].Metadata["builds"].([]metadata.CondaBuild); !ok || len(got) != 3 {
+		t.Errorf("expected 3 builds in version metadata, got %v", versions[0].Metadata["builds"])
+	}
+
+	if _, err := reg.FetchBuilds(context.Background(), "numpy", "9.9.9"); err == nil {
+		t.Error("expected error for unknown version")
+	}
+}
+
 func TestFetchMaintainers(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		resp := packageResponse{
@@ -250,8 +378,8 @@ func TestURLBuilder(t *testing.T) {
 	}{
 		{"registry", func() string { return urls.Registry("numpy", "1.26.0") }, "https://anaconda.org/conda-forge/numpy/1.26.0"},
 		{"registry_with_channel", func() string { return urls.Registry("bioconda/samtools", "1.18") }, "https://anaconda.org/bioconda/samtools/1.18"},
-		{"purl", func() string { return urls.PURL("numpy", "1.26.0") }, "pkg:conda/conda-forge/numpy@1.26.0"},
-		{"purl_with_channel", func() string { return urls.PURL("bioconda/samtools", "1.18") }, "pkg:conda/bioconda/samtools@1.18"},
+		{"purl", func() string { return urls.PURL("numpy", "1.26.0") }, "pkg:conda/numpy@1.26.0?channel=conda-forge"},
+		{"purl_with_channel", func() string { return urls.PURL("bioconda/samtools", "1.18") }, "pkg:conda/samtools@1.18?channel=bioconda"},
 	}
 
 	for _, tt := range tests {
@@ -264,9 +392,144 @@ func TestURLBuilder(t *testing.T) {
 	}
 }
 
+func TestWithQualifiers(t *testing.T) {
+	reg := New("", nil).WithQualifiers(map[string]string{"channel": "bioconda"})
+	if got := reg.URLs().Registry("samtools", ""); got != "https://anaconda.org/bioconda/samtools" {
+		t.Errorf("unexpected registry URL: %q", got)
+	}
+	if got := reg.URLs().PURL("samtools", "1.18"); got != "pkg:conda/samtools@1.18?channel=bioconda" {
+		t.Errorf("unexpected PURL: %q", got)
+	}
+
+	list := New("", nil).WithQualifiers(map[string]string{"channel": "conda-forge,bioconda"}).(*Registry)
+	if got := list.Channels(); !reflect.DeepEqual(got, []string{"conda-forge", "bioconda"}) {
+		t.Errorf("unexpected channels: %v", got)
+	}
+}
+
 func TestEcosystem(t *testing.T) {
 	reg := New("", nil)
 	if reg.Ecosystem() != "conda" {
 		t.Errorf("expected ecosystem 'conda', got %q", reg.Ecosystem())
 	}
 }
+
+func TestWithChannels(t *testing.T) {
+	var requested []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, r.URL.Path)
+		switch r.URL.Path {
+		case "/package/bioconda/samtools":
+			_ = json.NewEncoder(w).Encode(packageResponse{
+				Name:     "samtools",
+				Versions: []string{"1.18"},
+				Files:    []fileInfo{{Version: "1.18", SHA256: "abc"}},
+			})
+		case "/package/anaconda/samtools":
+			_ = json.NewEncoder(w).Encode(packageResponse{Name: "samtools"})
+		default:
+			w.WriteHeader(404)
+		}
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient()).WithChannels("conda-forge", "bioconda", "defaults")
+	ctx := context.Background()
+
+	pkg, err := reg.FetchPackage(ctx, "samtools")
+	if err != nil {
+		t.Fatalf("FetchPackage failed: %v", err)
+	}
+	if pkg.Namespace != "bioconda" || pkg.Metadata["channel"] != "bioconda" {
+		t.Errorf("expected the package from bioconda, got %q", pkg.Namespace)
+	}
+	want := []string{"/package/conda-forge/samtools", "/package/bioconda/samtools"}
+	if !reflect.DeepEqual(requested, want) {
+		t.Errorf("expected channels searched in order and stopping at the first match, got %v", requested)
+	}
+	if got := reg.URLs().Registry("samtools", ""); got != "https://anaconda.org/bioconda/samtools" {
+		t.Errorf("expected the URL of the channel the package came from, got %q", got)
+	}
+
+	versions, err := reg.FetchVersions(ctx, "samtools")
+	if err != nil {
+		t.Fatalf("FetchVersions failed: %v", err)
+	}
+	if len(versions) != 1 || versions[0].Metadata["channel"] != "bioconda" {
+		t.Errorf("unexpected versions %+v", versions)
+	}
+
+	if pkg, err := reg.FetchPackage(ctx, "defaults/samtools"); err != nil || pkg.Namespace != "anaconda" {
+		t.Errorf("expected defaults to name the anaconda channel, got %v, %v", pkg, err)
+	}
+
+	if _, err := reg.FetchDependencies(ctx, "missing", "1.0"); !errors.Is(err, core.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestLabelChannel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/package/conda-forge/numpy" {
+			w.WriteHeader(404)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(packageResponse{
+			Name:          "numpy",
+			Versions:      []string{"1.26.0", "2.0.0"},
+			LatestVersion: "2.0.0",
+			Files: []fileInfo{
+				{Version: "1.26.0", Basename: "linux-64/numpy-1.26.0-0.conda", UploadTime: 100, Labels: []string{"main", "broken"}},
+				{Version: "2.0.0", Basename: "linux-64/numpy-2.0.0-0.conda", UploadTime: 200, Labels: []string{"main"}},
+			},
+		})
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	ctx := context.Background()
+
+	pkg, err := reg.FetchPackage(ctx, "conda-forge/label/broken/numpy")
+	if err != nil {
+		t.Fatalf("FetchPackage failed: %v", err)
+	}
+	if pkg.Namespace != "conda-forge/label/broken" || pkg.LatestVersion != "1.26.0" {
+		t.Errorf("unexpected package %+v", pkg)
+	}
+
+	versions, err := reg.FetchVersions(ctx, "conda-forge/label/broken/numpy")
+	if err != nil {
+		t.Fatalf("FetchVersions failed: %v", err)
+	}
+	if len(versions) != 1 || versions[0].Number != "1.26.0" {
+		t.Fatalf("expected only the broken version, got %+v", versions)
+	}
+	builds := versions[0].Metadata["builds"].([]metadata.CondaBuild)
+	if !reflect.DeepEqual(builds[0].Labels, []string{"main", "broken"}) {
+		t.Errorf("expected file labels in the builds, got %v", builds[0].Labels)
+	}
+
+	if _, err := reg.FetchPackage(ctx, "conda-forge/label/dev/numpy"); !errors.Is(err, core.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for a label with no files, got %v", err)
+	}
+	if got := reg.URLs().Registry("conda-forge/label/broken/numpy", ""); got != "https://anaconda.org/conda-forge/numpy" {
+		t.Errorf("unexpected registry URL %q", got)
+	}
+}
+
+func TestWithToken(t *testing.T) {
+	var gotAuth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		_ = json.NewEncoder(w).Encode(packageResponse{Name: "internal-tool"})
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient()).WithToken("s3cret").WithChannel("myorg")
+	if _, err := reg.FetchPackage(context.Background(), "internal-tool"); err != nil {
+		t.Fatalf("FetchPackage failed: %v", err)
+	}
+	if gotAuth != "token s3cret" {
+		t.Errorf("unexpected Authorization header %q", gotAuth)
+	}
+}
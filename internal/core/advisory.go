@@ -0,0 +1,40 @@
+package core
+
+import (
+	"context"
+	"fmt"
+)
+
+// Advisory is a security advisory a registry publishes against a range of a
+// package's versions.
+type Advisory struct {
+	ID                 string // registry identifier, e.g. npm's numeric advisory ID
+	GHSA               string // GitHub advisory ID, if the advisory links to one
+	Name               string // affected package
+	Title              string
+	Severity           string // low, moderate, high or critical
+	VulnerableVersions string // affected range in the ecosystem's syntax
+	URL                string
+	CWEs               []string // e.g. CWE-1321
+	CVSSScore          float64
+	CVSSVector         string
+}
+
+// AdvisoryFetcher is implemented by registries that serve security advisories
+// directly, as npm does for `npm audit`.
+type AdvisoryFetcher interface {
+	// FetchAdvisories returns the advisories affecting any of the given
+	// versions, which are keyed by package name.
+	FetchAdvisories(ctx context.Context, versions map[string][]string) ([]Advisory, error)
+}
+
+// FetchAdvisories returns the advisories reg publishes for the given
+// package versions. It returns an error wrapping ErrNotSupported if the
+// registry doesn't serve advisories.
+func FetchAdvisories(ctx context.Context, reg Registry, versions map[string][]string) ([]Advisory, error) {
+	af, ok := reg.(AdvisoryFetcher)
+	if !ok {
+		return nil, fmt.Errorf("%s advisories: %w", reg.Ecosystem(), ErrNotSupported)
+	}
+	return af.FetchAdvisories(ctx, versions)
+}
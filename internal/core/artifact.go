@@ -0,0 +1,38 @@
+package core
+
+import (
+	"context"
+	"fmt"
+)
+
+// Artifact is one file published for a package version, such as a Maven
+// sources jar.
+type Artifact struct {
+	URL        string
+	Filename   string
+	Classifier string // "sources", "javadoc", "tests", or "" for the main file
+	Extension  string // "jar", "aar", "war", "pom", ...
+	// Integrity is the file's digest in SRI form ("sha256-<base64>"), or
+	// "" if the registry publishes none.
+	Integrity string
+}
+
+// ArtifactFetcher is implemented by registries that publish several files
+// per version, told apart by a classifier and extension.
+type ArtifactFetcher interface {
+	// FetchArtifact returns the file of a version with the given classifier
+	// and extension. An empty classifier is the main file, and an empty
+	// extension the one its packaging implies.
+	FetchArtifact(ctx context.Context, name, version, classifier, extension string) (*Artifact, error)
+}
+
+// FetchArtifact returns a file of a package version using reg. It returns an
+// error wrapping ErrNotSupported if the registry publishes one file per
+// version.
+func FetchArtifact(ctx context.Context, reg Registry, name, version, classifier, extension string) (*Artifact, error) {
+	af, ok := reg.(ArtifactFetcher)
+	if !ok {
+		return nil, fmt.Errorf("%s artifacts: %w", reg.Ecosystem(), ErrNotSupported)
+	}
+	return af.FetchArtifact(ctx, name, version, classifier, extension)
+}
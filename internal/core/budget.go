@@ -0,0 +1,112 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// BudgetExceededError is returned when an operation made of several requests
+// reaches its context's deadline before finishing. Partial holds what the
+// operation gathered before then, such as the []Version fetched so far, so
+// callers can use it rather than retrying from scratch.
+type BudgetExceededError struct {
+	Op        string // e.g. "hex versions"
+	Completed int    // sub-requests that finished
+	Planned   int    // sub-requests the operation expected to make
+	Partial   any
+	Err       error // the context error, usually context.DeadlineExceeded
+}
+
+func (e *BudgetExceededError) Error() string {
+	return fmt.Sprintf("%s: deadline reached after %d of %d requests: %v", e.Op, e.Completed, e.Planned, e.Err)
+}
+
+func (e *BudgetExceededError) Unwrap() error {
+	return e.Err
+}
+
+// Budget splits the time left before a context's deadline across the
+// sub-requests an operation plans to make, so that one slow request can't
+// use up the time the rest need. Each call to Next gets an equal share of
+// what remains; time a request doesn't use passes on to the ones after it.
+// Without a deadline on the context, sub-requests are not limited.
+type Budget struct {
+	mu        sync.Mutex
+	ctx       context.Context
+	op        string
+	planned   int
+	started   int
+	completed int
+}
+
+// NewBudget plans planned sub-requests for op within ctx's deadline.
+func NewBudget(ctx context.Context, op string, planned int) *Budget {
+	return &Budget{ctx: ctx, op: op, planned: planned}
+}
+
+// Add plans n more sub-requests, for work discovered along the way such as
+// a parent POM.
+func (b *Budget) Add(n int) {
+	b.mu.Lock()
+	b.planned += n
+	b.mu.Unlock()
+}
+
+// Next returns the context for the next sub-request, with its share of the
+// remaining time. Once the operation's deadline has passed it starts nothing
+// and returns the error from Exceeded, carrying no partial results; callers
+// with some should call Exceeded themselves.
+func (b *Budget) Next() (context.Context, context.CancelFunc, error) {
+	if b.Expired() {
+		return nil, nil, b.Exceeded(nil)
+	}
+
+	b.mu.Lock()
+	left := b.planned - b.started
+	b.started++
+	b.mu.Unlock()
+
+	deadline, ok := b.ctx.Deadline()
+	if !ok {
+		ctx, cancel := context.WithCancel(b.ctx)
+		return ctx, cancel, nil
+	}
+	if left < 1 {
+		left = 1
+	}
+	ctx, cancel := context.WithTimeout(b.ctx, time.Until(deadline)/time.Duration(left))
+	return ctx, cancel, nil
+}
+
+// Done records that a sub-request finished.
+func (b *Budget) Done() {
+	b.mu.Lock()
+	b.completed++
+	b.mu.Unlock()
+}
+
+// Expired reports whether the operation's own deadline has passed, as
+// opposed to one sub-request running out of its share.
+func (b *Budget) Expired() bool {
+	return b.ctx.Err() != nil
+}
+
+// Exceeded returns a BudgetExceededError for the operation carrying partial
+// results.
+func (b *Budget) Exceeded(partial any) *BudgetExceededError {
+	err := b.ctx.Err()
+	if err == nil {
+		err = context.DeadlineExceeded
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return &BudgetExceededError{
+		Op:        b.op,
+		Completed: b.completed,
+		Planned:   b.planned,
+		Partial:   partial,
+		Err:       err,
+	}
+}
@@ -0,0 +1,71 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBudgetSharesRemainingTime(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	budget := NewBudget(ctx, "test", 4)
+	sub, subCancel, err := budget.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer subCancel()
+
+	deadline, ok := sub.Deadline()
+	if !ok {
+		t.Fatal("expected the sub-request to have a deadline")
+	}
+	if share := time.Until(deadline); share > 260*time.Millisecond || share < 200*time.Millisecond {
+		t.Errorf("expected about a quarter of the time, got %v", share)
+	}
+	budget.Done()
+
+	// The last planned request gets everything that's left.
+	budget.Add(-2)
+	sub, subCancel, _ = budget.Next()
+	defer subCancel()
+	if deadline, _ := sub.Deadline(); time.Until(deadline) < 700*time.Millisecond {
+		t.Errorf("expected the remaining time, got %v", time.Until(deadline))
+	}
+}
+
+func TestBudgetWithoutDeadline(t *testing.T) {
+	budget := NewBudget(context.Background(), "test", 2)
+	sub, cancel, err := budget.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cancel()
+	if _, ok := sub.Deadline(); ok {
+		t.Error("expected no deadline without one on the parent context")
+	}
+}
+
+func TestBudgetExceeded(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	budget := NewBudget(ctx, "test versions", 3)
+	budget.Done()
+	cancel()
+
+	_, _, err := budget.Next()
+	var budgetErr *BudgetExceededError
+	if !errors.As(err, &budgetErr) {
+		t.Fatalf("expected BudgetExceededError, got %v", err)
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the context error to be wrapped, got %v", budgetErr.Err)
+	}
+	if budgetErr.Completed != 1 || budgetErr.Planned != 3 {
+		t.Errorf("unexpected progress %d of %d", budgetErr.Completed, budgetErr.Planned)
+	}
+	if got := budgetErr.Error(); got != "test versions: deadline reached after 1 of 3 requests: context canceled" {
+		t.Errorf("unexpected message %q", got)
+	}
+}
@@ -0,0 +1,163 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/git-pkgs/purl"
+)
+
+// PackageVersion names one version of a package.
+type PackageVersion struct {
+	Name    string
+	Version string
+}
+
+// BulkDependencyFetcher is implemented by registries that can return the
+// dependencies of many versions in fewer requests than one per version,
+// from a batch endpoint or from a document covering every version of a
+// package.
+type BulkDependencyFetcher interface {
+	// BulkDependencyBatchSize is how many distinct packages one
+	// FetchDependenciesBulk call can cover: 1 for registries serving a
+	// document per package, more for batch endpoints.
+	BulkDependencyBatchSize() int
+
+	// FetchDependenciesBulk returns dependencies keyed by the versions they
+	// were found for. Versions that don't exist are left out rather than
+	// failing the batch.
+	FetchDependenciesBulk(ctx context.Context, versions []PackageVersion) (map[PackageVersion][]Dependency, error)
+}
+
+// BulkFetchDependencies fetches dependencies for multiple versioned PURLs.
+// PURLs for the same registry are batched through BulkDependencyFetcher
+// where the registry implements it; others are fetched one at a time in
+// parallel. PURLs without versions are skipped and individual errors are
+// ignored - those PURLs are omitted from results.
+// Returns a map of PURL to its dependencies.
+func BulkFetchDependencies(ctx context.Context, purls []string, client *Client) map[string][]Dependency {
+	return BulkFetchDependenciesWithConcurrency(ctx, purls, client, defaultConcurrency)
+}
+
+// BulkFetchDependenciesWithConcurrency fetches dependencies with a custom
+// concurrency limit, which bounds the batches and single fetches in flight.
+func BulkFetchDependenciesWithConcurrency(ctx context.Context, purls []string, client *Client, concurrency int) map[string][]Dependency {
+	type group struct {
+		reg   Registry
+		size  int
+		purls map[PackageVersion][]string
+	}
+	type batch struct {
+		group    *group
+		versions []PackageVersion
+	}
+
+	// One registry per repository, so caches such as Maven's parent POMs
+	// are shared by every PURL in the call
+	pool := NewPool(client)
+	groups := make(map[string]*group)
+	var keys, single []string
+	for _, s := range purls {
+		p, err := purl.Parse(s)
+		if err != nil || p.Version == "" {
+			continue
+		}
+		reg, name, version, err := pool.FromPURL(s)
+		if err != nil {
+			continue
+		}
+		bulk, ok := reg.(BulkDependencyFetcher)
+		if !ok {
+			single = append(single, s)
+			continue
+		}
+
+		// PURLs share a batch when they'd build the same registry
+		key := fmt.Sprint(p.Type, " ", registryURL(p), " ", p.Qualifiers.Map())
+		g := groups[key]
+		if g == nil {
+			g = &group{reg: reg, size: bulk.BulkDependencyBatchSize(), purls: make(map[PackageVersion][]string)}
+			groups[key] = g
+			keys = append(keys, key)
+		}
+		pv := PackageVersion{Name: name, Version: version}
+		g.purls[pv] = append(g.purls[pv], s)
+	}
+
+	// Split each group into batches of at most the registry's batch size
+	// in distinct packages
+	var batches []*batch
+	for _, key := range keys {
+		g := groups[key]
+		size := g.size
+		if size < 1 {
+			size = 1
+		}
+		byName := make(map[string][]PackageVersion)
+		var names []string
+		for pv := range g.purls {
+			if _, ok := byName[pv.Name]; !ok {
+				names = append(names, pv.Name)
+			}
+			byName[pv.Name] = append(byName[pv.Name], pv)
+		}
+		sort.Strings(names)
+		for i := 0; i < len(names); i += size {
+			b := &batch{group: g}
+			for _, name := range names[i:min(i+size, len(names))] {
+				b.versions = append(b.versions, byName[name]...)
+			}
+			batches = append(batches, b)
+		}
+	}
+
+	results := make(map[string][]Dependency)
+
+	found := ParallelMap(ctx, batches, concurrency, func(ctx context.Context, b *batch) (*map[PackageVersion][]Dependency, error) {
+		reg := b.group.reg
+		deps, err := reg.(BulkDependencyFetcher).FetchDependenciesBulk(ctx, b.versions)
+		var notFound *NotFoundError
+		if err == nil || errors.As(err, &notFound) {
+			return &deps, err
+		}
+		// The batch request failed, so fall back to one request per version
+		fetched := ParallelMap(ctx, b.versions, concurrency, func(ctx context.Context, pv PackageVersion) (*[]Dependency, error) {
+			d, err := FetchDependencies(ctx, reg, pv.Name, pv.Version)
+			if err != nil {
+				return nil, err
+			}
+			return &d, nil
+		})
+		deps = make(map[PackageVersion][]Dependency, len(fetched))
+		for pv, d := range fetched {
+			deps[pv] = *d
+		}
+		return &deps, nil
+	})
+	for b, deps := range found {
+		for pv, d := range *deps {
+			for _, s := range b.group.purls[pv] {
+				results[s] = d
+			}
+		}
+	}
+
+	singles := ParallelMap(ctx, single, concurrency, func(ctx context.Context, s string) (*[]Dependency, error) {
+		reg, name, version, err := pool.FromPURL(s)
+		if err != nil {
+			return nil, err
+		}
+		deps, err := FetchDependencies(ctx, reg, name, version)
+		if err != nil {
+			return nil, err
+		}
+		return &deps, nil
+	})
+	for s, deps := range singles {
+		results[s] = *deps
+	}
+
+	return results
+}
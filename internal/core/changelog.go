@@ -0,0 +1,268 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// ChangelogFetcher is implemented by registries that can say what changed
+// in a version, from release notes they store or a changelog the package
+// links to.
+type ChangelogFetcher interface {
+	// FetchChangelog returns the release notes of a version, or of the
+	// latest version if version is empty. A version without notes returns
+	// an error wrapping ErrNotFound.
+	FetchChangelog(ctx context.Context, name, version string) (*Document, error)
+}
+
+// FetchChangelog returns the release notes of a package version using reg.
+// It returns an error wrapping ErrNotSupported if the registry has no
+// source of changelogs; FetchChangelogFromPURL and RepositoryChangelog fall
+// back to the package's repository.
+func FetchChangelog(ctx context.Context, reg Registry, name, version string) (*Document, error) {
+	cf, ok := reg.(ChangelogFetcher)
+	if !ok {
+		return nil, fmt.Errorf("%s changelog: %w", reg.Ecosystem(), ErrNotSupported)
+	}
+	return cf.FetchChangelog(ctx, name, version)
+}
+
+// FetchChangelogFromPURL returns the release notes for a PURL, using the
+// latest version if the PURL has none. Registries without changelogs fall
+// back to the package's repository, as RepositoryChangelog reads it.
+func FetchChangelogFromPURL(ctx context.Context, purlStr string, client *Client) (*Document, error) {
+	reg, name, version, err := NewFromPURL(purlStr, client)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := FetchChangelog(ctx, reg, name, version)
+	if !errors.Is(err, ErrNotSupported) {
+		return doc, err
+	}
+	pkg, err := reg.FetchPackage(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if client == nil {
+		client = DefaultClient()
+	}
+	return RepositoryChangelog(ctx, client, pkg.Repository, version)
+}
+
+// changelogFiles are the names a repository's changelog is looked for
+// under, in order.
+var changelogFiles = []string{"CHANGELOG.md", "CHANGES.md", "HISTORY.md", "NEWS.md", "CHANGELOG.rst", "CHANGES.rst", "CHANGELOG"}
+
+// RepositoryChangelog returns the release notes of a version from its
+// repository on GitHub or GitLab: the description of the release tagged
+// v<version> or <version>, or else the version's section of a changelog
+// file such as CHANGELOG.md on the default branch. The whole file is
+// returned when no section mentions the version, or version is empty. An
+// error wrapping ErrNotFound is returned if there are neither.
+func RepositoryChangelog(ctx context.Context, client *Client, repoURL, version string) (*Document, error) {
+	if repoURL == "" {
+		return nil, fmt.Errorf("no repository to read a changelog from: %w", ErrNotFound)
+	}
+	u, err := url.Parse(repoURL)
+	if err != nil {
+		return nil, err
+	}
+	repoPath := strings.TrimSuffix(strings.Trim(u.Path, "/"), ".git")
+	if strings.Count(repoPath, "/") < 1 {
+		return nil, fmt.Errorf("%s: no owner/repo in URL", repoURL)
+	}
+
+	var releaseURL, webURL string
+	var rawURL func(file string) string
+	switch host := strings.ToLower(u.Host); host {
+	case "github.com":
+		ownerRepo := strings.Join(strings.SplitN(repoPath, "/", 3)[:2], "/")
+		releaseURL = "https://api.github.com/repos/" + ownerRepo + "/releases/tags/"
+		webURL = "https://github.com/" + ownerRepo + "/releases/tag/"
+		rawURL = func(file string) string {
+			return "https://raw.githubusercontent.com/" + ownerRepo + "/HEAD/" + file
+		}
+	case "gitlab.com":
+		releaseURL = "https://gitlab.com/api/v4/projects/" + url.PathEscape(repoPath) + "/releases/"
+		webURL = "https://gitlab.com/" + repoPath + "/-/releases/"
+		rawURL = func(file string) string {
+			return "https://gitlab.com/" + repoPath + "/-/raw/HEAD/" + file
+		}
+	default:
+		return nil, fmt.Errorf("%s: %w", repoURL, errUnknownHost)
+	}
+
+	if version != "" {
+		for _, tag := range []string{"v" + version, version} {
+			var release struct {
+				Body        string `json:"body"`        // GitHub
+				Description string `json:"description"` // GitLab
+			}
+			err := client.GetJSON(ctx, releaseURL+url.PathEscape(tag), &release)
+			if err == nil {
+				if notes := strings.TrimSpace(release.Body + release.Description); notes != "" {
+					return &Document{Content: notes, ContentType: Markdown, URL: webURL + tag}, nil
+				}
+				break
+			}
+			// Rate limits and the like shouldn't stop the changelog
+			// file being tried
+			if httpErr, ok := err.(*HTTPError); !ok || !httpErr.IsNotFound() {
+				break
+			}
+		}
+	}
+
+	for _, file := range changelogFiles {
+		doc, err := changelogFile(ctx, client, rawURL(file), version)
+		if err == nil {
+			return doc, nil
+		}
+		if httpErr, ok := err.(*HTTPError); !ok || !httpErr.IsNotFound() {
+			return nil, err
+		}
+	}
+	return nil, fmt.Errorf("%s: no release notes or changelog: %w", repoURL, ErrNotFound)
+}
+
+// ChangelogFromURL returns the release notes of a version from a URL a
+// package declares as its changelog, such as RubyGems' changelog_uri. Links
+// to a file on GitHub are read raw, and links to a GitHub repository or its
+// releases go through RepositoryChangelog. Other links are read as a
+// changelog file if their name says they are one; for others an error
+// wrapping ErrNotFound is returned.
+func ChangelogFromURL(ctx context.Context, client *Client, changelogURL, version string) (*Document, error) {
+	u, err := url.Parse(changelogURL)
+	if err != nil {
+		return nil, err
+	}
+	if strings.EqualFold(u.Host, "github.com") || strings.EqualFold(u.Host, "www.github.com") {
+		parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+		if len(parts) >= 5 && parts[2] == "blob" {
+			raw := "https://raw.githubusercontent.com/" + parts[0] + "/" + parts[1] + "/" + strings.Join(parts[3:], "/")
+			doc, err := changelogFile(ctx, client, raw, version)
+			if err != nil {
+				return nil, changelogNotFound(err)
+			}
+			doc.URL = changelogURL
+			return doc, nil
+		}
+		if len(parts) >= 2 {
+			return RepositoryChangelog(ctx, client, "https://github.com/"+parts[0]+"/"+parts[1], version)
+		}
+	}
+	if !isChangelogFile(path.Base(u.Path)) {
+		return nil, fmt.Errorf("%s: not a changelog file: %w", changelogURL, ErrNotFound)
+	}
+	doc, err := changelogFile(ctx, client, changelogURL, version)
+	if err != nil {
+		return nil, changelogNotFound(err)
+	}
+	return doc, nil
+}
+
+// changelogNotFound turns a 404 for a linked changelog into an error
+// wrapping ErrNotFound, so that callers fall back to the repository.
+func changelogNotFound(err error) error {
+	if httpErr, ok := err.(*HTTPError); ok && httpErr.IsNotFound() {
+		return fmt.Errorf("%s: %w", httpErr.URL, ErrNotFound)
+	}
+	return err
+}
+
+// isChangelogFile reports whether a file name looks like a changelog
+// rather than, say, a web page about releases.
+func isChangelogFile(filename string) bool {
+	base := strings.ToUpper(strings.TrimSuffix(filename, path.Ext(filename)))
+	switch strings.ToLower(path.Ext(filename)) {
+	case "", ".md", ".markdown", ".rst", ".txt":
+	default:
+		return false
+	}
+	for _, prefix := range []string{"CHANGELOG", "CHANGES", "HISTORY", "NEWS", "RELEASE"} {
+		if strings.HasPrefix(base, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// changelogFile fetches a changelog and cuts it down to the section for
+// version, if it has one.
+func changelogFile(ctx context.Context, client *Client, fileURL, version string) (*Document, error) {
+	content, err := client.GetText(ctx, fileURL)
+	if err != nil {
+		return nil, err
+	}
+	filename := path.Base(fileURL)
+	return &Document{
+		Content:     ChangelogSection(content, version),
+		ContentType: ContentTypeFromFilename(filename),
+		Filename:    filename,
+		URL:         fileURL,
+	}, nil
+}
+
+// changelogUnderline matches the line under a setext or reStructuredText
+// heading.
+var changelogUnderline = regexp.MustCompile(`^(=+|-+|~+|\^+|\*+|\++)\s*$`)
+
+// ChangelogSection returns the part of a changelog under the first heading
+// that mentions version, such as "## [1.2.0] - 2024-01-01", "v1.2.0" over a
+// line of dashes, or "1.2.0 / 2024-01-01" in reStructuredText, up to the
+// next heading of the same or a higher level. It returns the whole
+// changelog if version is empty or no heading mentions it.
+func ChangelogSection(content, version string) string {
+	if version == "" {
+		return content
+	}
+	mentions := regexp.MustCompile(`(^|[^0-9A-Za-z.])v?` + regexp.QuoteMeta(version) + `($|[^0-9A-Za-z.+-]|\.($|\s))`)
+	lines := strings.Split(content, "\n")
+
+	// underlines records the underline characters in the order they
+	// appear, which is how setext and reStructuredText headings rank
+	var underlines []byte
+	level := func(i int) int {
+		line := strings.TrimSpace(lines[i])
+		if strings.HasPrefix(line, "#") {
+			return len(line) - len(strings.TrimLeft(line, "#"))
+		}
+		if line != "" && i+1 < len(lines) && changelogUnderline.MatchString(strings.TrimSpace(lines[i+1])) && !changelogUnderline.MatchString(line) {
+			c := strings.TrimSpace(lines[i+1])[0]
+			for n, u := range underlines {
+				if u == c {
+					return 100 + n
+				}
+			}
+			underlines = append(underlines, c)
+			return 100 + len(underlines) - 1
+		}
+		return 0
+	}
+
+	start, startLevel := -1, 0
+	for i := range lines {
+		l := level(i)
+		if l == 0 {
+			continue
+		}
+		if start < 0 {
+			if mentions.MatchString(lines[i]) {
+				start, startLevel = i, l
+			}
+			continue
+		}
+		if l <= startLevel {
+			return strings.TrimSpace(strings.Join(lines[start:i], "\n"))
+		}
+	}
+	if start < 0 {
+		return content
+	}
+	return strings.TrimSpace(strings.Join(lines[start:], "\n"))
+}
@@ -0,0 +1,93 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+const sampleChangelog = `# Changelog
+
+## [Unreleased]
+
+## [1.2.0] - 2024-03-01
+
+### Added
+- Streaming API
+
+## [1.1.0] - 2024-01-15
+
+- Fix 1.2.0-rc1 regression
+`
+
+func TestChangelogSection(t *testing.T) {
+	tests := []struct {
+		name, content, version, want string
+	}{
+		{"markdown", sampleChangelog, "1.2.0", "## [1.2.0] - 2024-03-01\n\n### Added\n- Streaming API"},
+		{"last section", sampleChangelog, "1.1.0", "## [1.1.0] - 2024-01-15\n\n- Fix 1.2.0-rc1 regression"},
+		{"prefix isn't a match", "# 1.2.0.1\nx\n# 11.2.0\ny\n", "1.2.0", "# 1.2.0.1\nx\n# 11.2.0\ny\n"},
+		{"prerelease isn't a match", "## v2.0.0-beta\nb\n## v2.0.0\nstable\n", "2.0.0", "## v2.0.0\nstable"},
+		{"setext", "v2.0.0\n======\n\nBreaking\n\nFixes\n-----\n\n* one\n\nv1.9.0\n======\n\nOld\n", "2.0.0", "v2.0.0\n======\n\nBreaking\n\nFixes\n-----\n\n* one"},
+		{"no version", sampleChangelog, "", sampleChangelog},
+		{"unknown version", sampleChangelog, "9.9.9", sampleChangelog},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ChangelogSection(tt.content, tt.version); got != tt.want {
+				t.Errorf("ChangelogSection(%q) = %q, want %q", tt.version, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRepositoryChangelog(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Host + r.URL.EscapedPath() {
+		case "api.github.com/repos/acme/widget/releases/tags/v1.2.0":
+			_, _ = w.Write([]byte(`{"body": "Adds streaming"}`))
+		case "raw.githubusercontent.com/acme/widget/HEAD/CHANGELOG.md":
+			_, _ = w.Write([]byte(sampleChangelog))
+		case "gitlab.com/api/v4/projects/group%2Fproject/releases/1.0.0":
+			_, _ = w.Write([]byte(`{"description": "First release"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	target, _ := url.Parse(server.URL)
+	c := DefaultClient()
+	c.HTTPClient = &http.Client{Transport: &rewriteTransport{target: target}}
+	ctx := context.Background()
+
+	doc, err := RepositoryChangelog(ctx, c, "https://github.com/acme/widget", "1.2.0")
+	if err != nil || doc.Content != "Adds streaming" || doc.URL != "https://github.com/acme/widget/releases/tag/v1.2.0" {
+		t.Errorf("release notes = %+v, %v", doc, err)
+	}
+
+	doc, err = RepositoryChangelog(ctx, c, "https://github.com/acme/widget.git", "1.1.0")
+	if err != nil || doc.Filename != "CHANGELOG.md" || doc.Content != "## [1.1.0] - 2024-01-15\n\n- Fix 1.2.0-rc1 regression" {
+		t.Errorf("changelog section = %+v, %v", doc, err)
+	}
+
+	doc, err = RepositoryChangelog(ctx, c, "https://gitlab.com/group/project", "1.0.0")
+	if err != nil || doc.Content != "First release" {
+		t.Errorf("GitLab release notes = %+v, %v", doc, err)
+	}
+
+	if _, err := RepositoryChangelog(ctx, c, "https://gitlab.com/group/project", "2.0.0"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+
+	doc, err = ChangelogFromURL(ctx, c, "https://github.com/acme/widget/blob/HEAD/CHANGELOG.md", "1.2.0")
+	if err != nil || doc.URL != "https://github.com/acme/widget/blob/HEAD/CHANGELOG.md" || doc.Content != "## [1.2.0] - 2024-03-01\n\n### Added\n- Streaming API" {
+		t.Errorf("ChangelogFromURL = %+v, %v", doc, err)
+	}
+	if _, err := ChangelogFromURL(ctx, c, "https://hexdocs.pm/widget/changelog.html", "1.2.0"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for a web page, got %v", err)
+	}
+}
@@ -0,0 +1,89 @@
+package core
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ArtifactMatch is a published package version whose files include one with
+// a given digest.
+type ArtifactMatch struct {
+	Name        string // package name as FetchPackage accepts it
+	Version     string
+	PURL        string
+	PublishedAt time.Time
+	Metadata    map[string]any
+}
+
+// ChecksumLookup is implemented by registries that can identify a package
+// version from the digest of one of its files, such as an unknown JAR found
+// on disk.
+type ChecksumLookup interface {
+	// LookupByChecksum returns the versions with a file matching digest,
+	// which is hex, optionally prefixed with its algorithm ("sha1-...",
+	// "sha256:..."). An unrecognised file returns no matches and no error.
+	LookupByChecksum(ctx context.Context, digest string) ([]ArtifactMatch, error)
+}
+
+// LookupByChecksum identifies package versions from a file digest using reg.
+// It returns an error wrapping ErrNotSupported if the registry can't search
+// by checksum.
+func LookupByChecksum(ctx context.Context, reg Registry, digest string) ([]ArtifactMatch, error) {
+	cl, ok := reg.(ChecksumLookup)
+	if !ok {
+		return nil, fmt.Errorf("%s checksum lookup: %w", reg.Ecosystem(), ErrNotSupported)
+	}
+	return cl.LookupByChecksum(ctx, digest)
+}
+
+// ParseDigest splits a hex digest into its algorithm and lower-case hex. The
+// algorithm comes from a "sha1-" or "sha256:" style prefix, or else is
+// inferred from the length: 40 hex digits for SHA-1, 64 for SHA-256 and 128
+// for SHA-512. SRI values such as Version.Integrity are accepted too.
+func ParseDigest(digest string) (algorithm, sum string, err error) {
+	digest = strings.TrimSpace(digest)
+	if i := strings.IndexAny(digest, "-:"); i > 0 {
+		algorithm, sum = strings.ToLower(digest[:i]), digest[i+1:]
+	} else {
+		sum = digest
+	}
+	if _, err := hex.DecodeString(strings.ToLower(sum)); err != nil || sum == "" {
+		in, err := ParseIntegrity(digest)
+		if err != nil || algorithm == "" {
+			return "", "", fmt.Errorf("invalid digest %q: not hex", digest)
+		}
+		algorithm, sum = in.Algorithm, in.Hex()
+	}
+	sum = strings.ToLower(sum)
+
+	var size int
+	switch algorithm {
+	case "sha1":
+		size = 40
+	case "sha256":
+		size = 64
+	case "sha512":
+		size = 128
+	case "":
+		switch len(sum) {
+		case 40:
+			algorithm = "sha1"
+		case 64:
+			algorithm = "sha256"
+		case 128:
+			algorithm = "sha512"
+		default:
+			return "", "", fmt.Errorf("invalid digest %q: unknown length %d", digest, len(sum))
+		}
+		return algorithm, sum, nil
+	default:
+		return "", "", fmt.Errorf("invalid digest %q: unsupported algorithm %q", digest, algorithm)
+	}
+	if len(sum) != size {
+		return "", "", fmt.Errorf("invalid digest %q: %s needs %d hex digits", digest, algorithm, size)
+	}
+	return algorithm, sum, nil
+}
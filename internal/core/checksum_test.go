@@ -0,0 +1,33 @@
+package core
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseDigest(t *testing.T) {
+	sha1 := strings.Repeat("a1", 20)
+	sha256 := strings.Repeat("b2", 32)
+	tests := []struct {
+		in, algorithm, sum string
+	}{
+		{sha1, "sha1", sha1},
+		{strings.ToUpper(sha1), "sha1", sha1},
+		{"sha1-" + sha1, "sha1", sha1},
+		{"SHA256:" + sha256, "sha256", sha256},
+		{sha256, "sha256", sha256},
+		{HexIntegrity("sha256", sha256), "sha256", sha256},
+	}
+	for _, tt := range tests {
+		algorithm, sum, err := ParseDigest(tt.in)
+		if err != nil || algorithm != tt.algorithm || sum != tt.sum {
+			t.Errorf("ParseDigest(%q) = %q, %q, %v", tt.in, algorithm, sum, err)
+		}
+	}
+
+	for _, in := range []string{"", "xyz", "abc", "md5-" + sha1, "sha1-" + sha256} {
+		if _, _, err := ParseDigest(in); err == nil {
+			t.Errorf("ParseDigest(%q) expected an error", in)
+		}
+	}
+}
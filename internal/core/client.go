@@ -11,6 +11,8 @@ type (
 	Option      = client.Option
 	URLBuilder  = client.URLBuilder
 	BaseURLs    = client.BaseURLs
+	Source      = client.Source
+	SourceLog   = client.SourceLog
 )
 
 // Function aliases for backward compatibility.
@@ -20,4 +22,7 @@ var (
 	WithTimeout    = client.WithTimeout
 	WithMaxRetries = client.WithMaxRetries
 	BuildURLs      = client.BuildURLs
+	RecordSources  = client.RecordSources
+	NoteFields     = client.NoteFields
+	NoteSource     = client.NoteSource
 )
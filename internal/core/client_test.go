@@ -1,10 +1,24 @@
 package core
 
 import (
+	"compress/gzip"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
+
+	"github.com/andybalholm/brotli"
+	"github.com/git-pkgs/registries/client"
+	"github.com/klauspost/compress/zstd"
 )
 
 func TestBuildURLs(t *testing.T) {
@@ -107,3 +121,761 @@ func TestClient_Head_UserAgent(t *testing.T) {
 		t.Errorf("Head User-Agent = %q, want %q", gotUA, "head-test/1.0")
 	}
 }
+
+func TestClient_WithAuthFunc(t *testing.T) {
+	var gotAuth, gotHeadAuth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			gotHeadAuth = r.Header.Get("Authorization")
+		} else {
+			gotAuth = r.Header.Get("Authorization")
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := DefaultClient().WithAuthFunc(func(url string) (string, string) {
+		return "Authorization", "Bearer secret"
+	})
+	_, _ = client.GetBody(context.Background(), server.URL)
+	_, _ = client.Head(context.Background(), server.URL)
+
+	if gotAuth != "Bearer secret" {
+		t.Errorf("Authorization = %q, want %q", gotAuth, "Bearer secret")
+	}
+	if gotHeadAuth != "Bearer secret" {
+		t.Errorf("Head Authorization = %q, want %q", gotHeadAuth, "Bearer secret")
+	}
+}
+
+func TestIntervalLimiter(t *testing.T) {
+	limiter := client.NewIntervalLimiter(100)
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := limiter.Wait(context.Background()); err != nil {
+			t.Fatalf("Wait returned error: %v", err)
+		}
+	}
+
+	// First call is immediate, the next two wait 10ms each
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Errorf("3 waits at 100/s took %v, want at least 20ms", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	slow := client.NewIntervalLimiter(0.001)
+	_ = slow.Wait(ctx)
+	if err := slow.Wait(ctx); err == nil {
+		t.Error("expected Wait to return an error for a cancelled context")
+	}
+}
+
+func TestClient_DeduplicatesConcurrentGets(t *testing.T) {
+	var hits atomic.Int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		<-release
+		_, _ = w.Write([]byte(`{"name":"lodash"}`))
+	}))
+	defer server.Close()
+
+	c := DefaultClient()
+
+	const callers = 5
+	var wg sync.WaitGroup
+	results := make(chan string, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			body, err := c.GetBody(context.Background(), server.URL+"/lodash")
+			if err != nil {
+				t.Errorf("GetBody failed: %v", err)
+				return
+			}
+			results <- string(body)
+		}()
+	}
+
+	// Give every caller time to join the in-flight request
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(results)
+
+	if got := hits.Load(); got != 1 {
+		t.Errorf("expected 1 upstream request, got %d", got)
+	}
+	for body := range results {
+		if body != `{"name":"lodash"}` {
+			t.Errorf("unexpected body %q", body)
+		}
+	}
+}
+
+func TestClient_DeduplicationCallerCancel(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		_, _ = w.Write([]byte(`ok`))
+	}))
+	defer server.Close()
+
+	c := DefaultClient()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	cancelled := make(chan error, 1)
+	go func() {
+		_, err := c.GetBody(ctx, server.URL)
+		cancelled <- err
+	}()
+
+	done := make(chan []byte, 1)
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		body, _ := c.GetBody(context.Background(), server.URL)
+		done <- body
+	}()
+
+	time.Sleep(30 * time.Millisecond)
+	cancel()
+	if err := <-cancelled; err != context.Canceled {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+
+	// The other caller's request must survive the first caller cancelling
+	close(release)
+	if body := <-done; string(body) != "ok" {
+		t.Errorf("expected remaining caller to get the body, got %q", body)
+	}
+}
+
+func TestClient_WithoutDeduplication(t *testing.T) {
+	var hits atomic.Int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		<-release
+	}))
+	defer server.Close()
+
+	c := DefaultClient().WithoutDeduplication()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = c.GetBody(context.Background(), server.URL)
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if got := hits.Load(); got != 3 {
+		t.Errorf("expected 3 upstream requests, got %d", got)
+	}
+}
+
+func TestClient_DiskCacheRevalidates(t *testing.T) {
+	var hits, notModified atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte(`{"version":"1"}`))
+	}))
+	defer server.Close()
+
+	cache, err := client.NewDiskCache(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := DefaultClient().WithCache(cache)
+
+	for i := 0; i < 2; i++ {
+		body, err := c.GetBody(context.Background(), server.URL)
+		if err != nil {
+			t.Fatalf("GetBody failed: %v", err)
+		}
+		if string(body) != `{"version":"1"}` {
+			t.Errorf("unexpected body %q", body)
+		}
+	}
+
+	if hits.Load() != 2 || notModified.Load() != 1 {
+		t.Errorf("expected a 200 then a 304, got %d hits and %d not modified", hits.Load(), notModified.Load())
+	}
+
+	// Within the TTL the cached body is used without a request
+	_, _ = c.WithCacheTTL(time.Hour).GetBody(context.Background(), server.URL)
+	if hits.Load() != 2 {
+		t.Errorf("expected no request within TTL, got %d hits", hits.Load())
+	}
+}
+
+func TestClient_Offline(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		_, _ = w.Write([]byte(`cached`))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	cache, _ := client.NewDiskCache(dir)
+	_, _ = DefaultClient().WithCache(cache).GetBody(context.Background(), server.URL+"/a")
+
+	// A fresh cache on the same directory sees the persisted entry
+	reopened, _ := client.NewDiskCache(dir)
+	offline := DefaultClient().WithCache(reopened).WithOffline(true)
+
+	body, err := offline.GetBody(context.Background(), server.URL+"/a")
+	if err != nil || string(body) != "cached" {
+		t.Errorf("expected cached body, got %q (%v)", body, err)
+	}
+
+	_, err = offline.GetBody(context.Background(), server.URL+"/b")
+	if !errors.Is(err, client.ErrCacheMiss) {
+		t.Errorf("expected ErrCacheMiss, got %v", err)
+	}
+	if _, err := offline.Head(context.Background(), server.URL+"/b"); !errors.Is(err, client.ErrCacheMiss) {
+		t.Errorf("expected ErrCacheMiss from Head, got %v", err)
+	}
+
+	if hits.Load() != 1 {
+		t.Errorf("expected offline client not to touch the network, got %d hits", hits.Load())
+	}
+}
+
+func TestClient_CacheKeyedByCredentials(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.Header.Get("Authorization")))
+	}))
+	defer server.Close()
+
+	cache, _ := client.NewDiskCache(t.TempDir())
+	alice := DefaultClient().WithCache(cache).WithAuthFunc(func(string) (string, string) { return "Authorization", "alice" })
+	_, _ = alice.GetBody(context.Background(), server.URL)
+
+	bob := DefaultClient().WithCache(cache).WithOffline(true).WithAuthFunc(func(string) (string, string) { return "Authorization", "bob" })
+	if _, err := bob.GetBody(context.Background(), server.URL); !errors.Is(err, client.ErrCacheMiss) {
+		t.Errorf("expected a different credential to miss the cache, got %v", err)
+	}
+}
+
+func TestClient_CacheKeyedByAccept(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.Header.Get("Accept")))
+	}))
+	defer server.Close()
+
+	cache, _ := client.NewDiskCache(t.TempDir())
+	c := DefaultClient().WithCache(cache).WithCacheTTL(time.Hour)
+	body, err := c.GetBodyAccept(context.Background(), server.URL, "application/vnd.oci.image.manifest.v1+json")
+	if err != nil || string(body) != "application/vnd.oci.image.manifest.v1+json" {
+		t.Errorf("expected the Accept header to be sent, got %q (%v)", body, err)
+	}
+
+	body, err = c.GetBody(context.Background(), server.URL)
+	if err != nil || string(body) != "application/json" {
+		t.Errorf("expected a JSON request not to reuse the manifest response, got %q (%v)", body, err)
+	}
+}
+
+func TestClient_Post(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "text/xml" {
+			t.Errorf("unexpected request %s %s", r.Method, r.Header.Get("Content-Type"))
+		}
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		_, _ = w.Write(append([]byte("echo:"), body...))
+	}))
+	defer server.Close()
+
+	c := client.DefaultClient()
+	c.BaseDelay = time.Millisecond
+
+	body, err := c.Post(context.Background(), server.URL, "text/xml", []byte("<x/>"))
+	if err != nil {
+		t.Fatalf("Post failed: %v", err)
+	}
+	// The retried request must resend the full body
+	if string(body) != "echo:<x/>" {
+		t.Errorf("body = %q", body)
+	}
+
+	_, err = c.WithOffline(true).Post(context.Background(), server.URL, "text/xml", nil)
+	if !errors.Is(err, client.ErrCacheMiss) {
+		t.Errorf("offline Post err = %v, want ErrCacheMiss", err)
+	}
+}
+
+func TestClient_GraphQL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Query     string         `json:"query"`
+			Variables map[string]any `json:"variables"`
+		}
+		if r.Header.Get("Content-Type") != "application/json" || json.NewDecoder(r.Body).Decode(&req) != nil {
+			t.Errorf("unexpected request %s", r.Header.Get("Content-Type"))
+		}
+		if req.Variables["name"] == "missing" {
+			_, _ = w.Write([]byte(`{"data": {"crate": null}, "errors": [{"type": "NOT_FOUND", "message": "Could not resolve to a Crate", "path": ["crate"]}]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"data": {"crate": {"name": "` + req.Variables["name"].(string) + `"}}}`))
+	}))
+	defer server.Close()
+
+	c := client.DefaultClient()
+	var data struct {
+		Crate *struct {
+			Name string `json:"name"`
+		} `json:"crate"`
+	}
+	query := `query($name: String!) { crate(name: $name) { name } }`
+	if err := c.GraphQL(context.Background(), server.URL, query, map[string]any{"name": "serde"}, &data); err != nil {
+		t.Fatalf("GraphQL failed: %v", err)
+	}
+	if data.Crate == nil || data.Crate.Name != "serde" {
+		t.Errorf("data = %+v", data)
+	}
+
+	err := c.GraphQL(context.Background(), server.URL, query, map[string]any{"name": "missing"}, &data)
+	var gqlErr *client.GraphQLError
+	if !errors.As(err, &gqlErr) || !gqlErr.IsNotFound() || !strings.Contains(err.Error(), "Could not resolve") {
+		t.Errorf("err = %v, want a not found GraphQLError", err)
+	}
+}
+
+func TestClient_RetryPolicyRetryAfter(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	clock := client.NewFakeClock(time.Unix(0, 0))
+	c := client.NewClient(client.WithClock(clock)).WithRetryPolicy(&client.RetryPolicy{
+		MaxRetries: 3,
+		BaseDelay:  time.Millisecond,
+	})
+	if _, err := c.GetBody(context.Background(), server.URL); err != nil {
+		t.Fatalf("GetBody failed: %v", err)
+	}
+	if sleeps := clock.Sleeps(); len(sleeps) != 1 || sleeps[0] != time.Second {
+		t.Errorf("slept %v, want the 1s Retry-After honored", sleeps)
+	}
+
+	// A Retry-After beyond MaxElapsed gives up without waiting
+	atomic.StoreInt32(&calls, 0)
+	c = c.WithRetryPolicy(&client.RetryPolicy{MaxRetries: 3, MaxElapsed: 100 * time.Millisecond})
+	_, err := c.GetBody(context.Background(), server.URL)
+	var httpErr *client.HTTPError
+	if !errors.As(err, &httpErr) || httpErr.RetryAfter != time.Second {
+		t.Fatalf("err = %v, want a 503 HTTPError with RetryAfter", err)
+	}
+	if sleeps := clock.Sleeps(); len(sleeps) != 1 {
+		t.Errorf("slept %v, want to give up immediately", sleeps[1:])
+	}
+}
+
+func TestClient_RetryPolicyStatusesAndBudget(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	policy := &client.RetryPolicy{
+		MaxRetries: 2,
+		BaseDelay:  time.Millisecond,
+		Retryable:  func(status int) bool { return status == http.StatusServiceUnavailable },
+	}
+	c := client.DefaultClient().WithoutDeduplication().WithRetryPolicy(policy)
+	if _, err := c.GetBody(context.Background(), server.URL); err == nil {
+		t.Fatal("expected an error")
+	}
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Errorf("calls = %d, want 1 for a status the policy doesn't retry", n)
+	}
+
+	// Two retries per host per minute, shared by both requests
+	policy.Retryable = nil
+	policy.Budget = client.NewRetryBudget(2, time.Minute)
+	atomic.StoreInt32(&calls, 0)
+	_, _ = c.GetBody(context.Background(), server.URL+"/a")
+	_, _ = c.GetBody(context.Background(), server.URL+"/b")
+	if n := atomic.LoadInt32(&calls); n != 4 {
+		t.Errorf("calls = %d, want 4 (3 for the first request, 1 once the budget ran out)", n)
+	}
+}
+
+func TestClient_ClockTimesCacheTTL(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	clock := client.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	cache, err := client.NewDiskCache(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := client.NewClient(client.WithClock(clock)).WithCache(cache).WithCacheTTL(time.Hour)
+
+	for _, advance := range []time.Duration{0, 59 * time.Minute, 2 * time.Minute} {
+		clock.Advance(advance)
+		if _, err := c.GetBody(context.Background(), server.URL); err != nil {
+			t.Fatalf("GetBody failed: %v", err)
+		}
+	}
+	if n := atomic.LoadInt32(&calls); n != 2 {
+		t.Errorf("calls = %d, want 2: cached within the hour, refetched after", n)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	if d := client.ParseRetryAfter("120"); d != 2*time.Minute {
+		t.Errorf("seconds: got %v", d)
+	}
+	date := time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)
+	if d := client.ParseRetryAfter(date); d < 59*time.Minute || d > time.Hour {
+		t.Errorf("date: got %v", d)
+	}
+	for _, v := range []string{"", "soon", "-5", "Mon, 01 Jan 2001 00:00:00 GMT"} {
+		if d := client.ParseRetryAfter(v); d != 0 {
+			t.Errorf("ParseRetryAfter(%q) = %v, want 0", v, d)
+		}
+	}
+}
+
+func TestClient_TransportOptions(t *testing.T) {
+	var protos sync.Map
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		protos.Store(r.Proto, true)
+		_, _ = w.Write([]byte("ok"))
+	}))
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	defer server.Close()
+
+	for _, http2 := range []bool{true, false} {
+		c := client.NewClient(
+			client.WithMaxIdleConnsPerHost(64),
+			client.WithHTTP2(http2),
+			client.WithDialTimeout(5*time.Second),
+			client.WithDNSCache(time.Minute),
+		)
+		tr := c.HTTPClient.Transport.(*http.Transport)
+		if tr == http.DefaultTransport {
+			t.Fatal("options must not modify http.DefaultTransport")
+		}
+		if tr.MaxIdleConnsPerHost != 64 {
+			t.Errorf("MaxIdleConnsPerHost = %d", tr.MaxIdleConnsPerHost)
+		}
+		roots := x509.NewCertPool()
+		roots.AddCert(server.Certificate())
+		tr.TLSClientConfig = &tls.Config{RootCAs: roots}
+
+		protos.Clear()
+		if _, err := c.GetBody(context.Background(), server.URL); err != nil {
+			t.Fatalf("http2=%v: %v", http2, err)
+		}
+		want := "HTTP/1.1"
+		if http2 {
+			want = "HTTP/2.0"
+		}
+		if _, ok := protos.Load(want); !ok {
+			t.Errorf("http2=%v: server didn't see %s", http2, want)
+		}
+	}
+}
+
+func TestClient_DecodesContentEncoding(t *testing.T) {
+	const payload = `{"name":"lodash"}`
+	encoders := map[string]func(io.Writer) io.WriteCloser{
+		"gzip": func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) },
+		"br":   func(w io.Writer) io.WriteCloser { return brotli.NewWriter(w) },
+		"zstd": func(w io.Writer) io.WriteCloser {
+			zw, _ := zstd.NewWriter(w)
+			return zw
+		},
+	}
+
+	for encoding, newWriter := range encoders {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !strings.Contains(r.Header.Get("Accept-Encoding"), encoding) {
+				t.Errorf("Accept-Encoding = %q, want %s offered", r.Header.Get("Accept-Encoding"), encoding)
+			}
+			w.Header().Set("Content-Encoding", encoding)
+			zw := newWriter(w)
+			_, _ = zw.Write([]byte(payload))
+			_ = zw.Close()
+		}))
+
+		var got map[string]string
+		err := client.DefaultClient().GetJSON(context.Background(), server.URL, &got)
+		server.Close()
+		if err != nil {
+			t.Errorf("%s: %v", encoding, err)
+			continue
+		}
+		if got["name"] != "lodash" {
+			t.Errorf("%s: decoded %v", encoding, got)
+		}
+	}
+}
+
+func TestClient_EmptyEncodedBodies(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Content-Encoding", "gzip")
+		switch {
+		case r.URL.Path == "/missing":
+			w.WriteHeader(http.StatusNotFound)
+		case r.URL.Path == "/chunked":
+			// No Content-Length, so the client only finds the body empty
+			// when it reads it
+			w.WriteHeader(http.StatusNotFound)
+			w.(http.Flusher).Flush()
+		case r.Header.Get("If-None-Match") == `"v1"`:
+			w.WriteHeader(http.StatusNotModified)
+		default:
+			w.Header().Set("ETag", `"v1"`)
+			zw := gzip.NewWriter(w)
+			_, _ = zw.Write([]byte(`{"version":"1"}`))
+			_ = zw.Close()
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	for _, path := range []string{"/missing", "/chunked"} {
+		requests.Store(0)
+		_, err := DefaultClient().GetBody(ctx, server.URL+path)
+		var httpErr *HTTPError
+		if !errors.As(err, &httpErr) || !httpErr.IsNotFound() {
+			t.Errorf("%s: expected a 404 HTTPError, got %v", path, err)
+		}
+		if requests.Load() != 1 {
+			t.Errorf("%s: expected one request, got %d", path, requests.Load())
+		}
+	}
+
+	cache, err := client.NewDiskCache(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := DefaultClient().WithCache(cache)
+	for i := 0; i < 2; i++ {
+		requests.Store(0)
+		body, err := c.GetBody(ctx, server.URL+"/doc")
+		if err != nil || string(body) != `{"version":"1"}` {
+			t.Errorf("request %d: %q, %v", i+1, body, err)
+		}
+		if requests.Load() != 1 {
+			t.Errorf("request %d: expected one request, got %d", i+1, requests.Load())
+		}
+	}
+}
+
+func TestClient_MaxBodySize(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		switch r.URL.Path {
+		case "/exact":
+			_, _ = w.Write([]byte(strings.Repeat("a", 64)))
+		case "/big":
+			_, _ = w.Write([]byte(strings.Repeat("a", 65)))
+		case "/bomb":
+			// A few KB on the wire, a megabyte decoded
+			w.Header().Set("Content-Encoding", "gzip")
+			zw := gzip.NewWriter(w)
+			_, _ = zw.Write(make([]byte, 1<<20))
+			_ = zw.Close()
+		}
+	}))
+	defer server.Close()
+
+	c := client.NewClient(client.WithMaxBodySize(64), client.WithMaxArtifactSize(2<<20))
+	ctx := context.Background()
+
+	if body, err := c.GetBody(ctx, server.URL+"/exact"); err != nil || len(body) != 64 {
+		t.Errorf("body at the limit: %d bytes, %v", len(body), err)
+	}
+	for _, path := range []string{"/big", "/bomb"} {
+		requests.Store(0)
+		_, err := c.GetBody(ctx, server.URL+path)
+		var tooLarge *client.ResponseTooLargeError
+		if !errors.As(err, &tooLarge) || tooLarge.Limit != 64 || !errors.Is(err, client.ErrResponseTooLarge) {
+			t.Errorf("%s: expected ResponseTooLargeError, got %v", path, err)
+		}
+		if requests.Load() != 1 {
+			t.Errorf("%s: oversized response was retried %d times", path, requests.Load()-1)
+		}
+	}
+
+	if body, err := c.GetArtifact(ctx, server.URL+"/bomb"); err != nil || len(body) != 1<<20 {
+		t.Errorf("artifact under its own limit: %d bytes, %v", len(body), err)
+	}
+	if body, err := c.WithMaxBodySize(0).GetBody(ctx, server.URL+"/bomb"); err != nil || len(body) != 1<<20 {
+		t.Errorf("unlimited copy: %d bytes, %v", len(body), err)
+	}
+}
+
+func TestClient_ResponseHeaderTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		_, _ = w.Write([]byte("late"))
+	}))
+	defer server.Close()
+
+	c := client.NewClient(client.WithResponseHeaderTimeout(20*time.Millisecond), client.WithMaxRetries(0))
+	if tr := c.HTTPClient.Transport.(*http.Transport); tr.ResponseHeaderTimeout != 20*time.Millisecond {
+		t.Errorf("ResponseHeaderTimeout = %v", tr.ResponseHeaderTimeout)
+	}
+	if _, err := c.GetBody(context.Background(), server.URL); err == nil {
+		t.Error("expected a server that doesn't answer in time to fail")
+	}
+}
+
+func TestClient_RecordSources(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := client.NewFakeClock(start)
+	c := client.NewClient(client.WithClock(clock)).WithCache(client.NewMemoryCache()).WithCacheTTL(time.Hour)
+
+	if _, err := c.GetBody(context.Background(), server.URL+"/a"); err != nil {
+		t.Fatalf("GetBody failed: %v", err)
+	}
+	clock.Advance(10 * time.Minute)
+
+	ctx, log := client.RecordSources(context.Background())
+	for _, path := range []string{"/a", "/b", "/a"} {
+		if _, err := c.GetBody(ctx, server.URL+path); err != nil {
+			t.Fatalf("GetBody failed: %v", err)
+		}
+	}
+	if _, err := c.GetBody(ctx, server.URL+"/missing\x7f"); err == nil {
+		t.Fatal("expected an error for an invalid URL")
+	}
+	client.NoteFields(ctx, server.URL+"/b", "Licenses")
+
+	sources := log.Sources()
+	if len(sources) != 2 {
+		t.Fatalf("expected 2 sources, got %+v", sources)
+	}
+	if !sources[0].FetchedAt.Equal(start) {
+		t.Errorf("cached response fetched at %v, want %v", sources[0].FetchedAt, start)
+	}
+	if !sources[1].FetchedAt.Equal(start.Add(10*time.Minute)) || len(sources[1].Fields) != 1 {
+		t.Errorf("unexpected second source: %+v", sources[1])
+	}
+
+	// Requests made without the context aren't recorded
+	if _, err := c.GetBody(context.Background(), server.URL+"/c"); err != nil {
+		t.Fatalf("GetBody failed: %v", err)
+	}
+	if n := len(log.Sources()); n != 2 {
+		t.Errorf("expected 2 sources still, got %d", n)
+	}
+}
+
+func TestClient_ClientStats(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Limit", "60")
+		w.Header().Set("X-RateLimit-Remaining", "57")
+		w.Header().Set("X-RateLimit-Reset", "1700000000")
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	clock := client.NewFakeClock(time.Unix(0, 0))
+	var during client.Stats
+	var c *client.Client
+	c = client.NewClient(client.WithClock(clock)).WithRetryPolicy(&client.RetryPolicy{
+		MaxRetries: 3,
+		BaseDelay:  2 * time.Second,
+		OnRetry: func(host string, retry int, delay time.Duration) {
+			during = c.ClientStats()
+		},
+	})
+	if _, err := c.GetBody(context.Background(), server.URL); err != nil {
+		t.Fatalf("GetBody failed: %v", err)
+	}
+	// Copies count into the same stats
+	if _, err := c.WithUserAgent("other").Head(context.Background(), server.URL); err != nil {
+		t.Fatalf("Head failed: %v", err)
+	}
+
+	host := strings.TrimPrefix(server.URL, "http://")
+	if b := during.Hosts[host].Backoff; b != 2*time.Second {
+		t.Errorf("backoff while waiting to retry = %v, want 2s", b)
+	}
+	stats := c.ClientStats()
+	if stats.Requests[http.StatusServiceUnavailable] != 1 || stats.Requests[http.StatusOK] != 2 || stats.Retries != 1 {
+		t.Errorf("stats = %+v", stats)
+	}
+	h := stats.Hosts[host]
+	if h.Backoff != 0 || h.Retries != 1 {
+		t.Errorf("host stats = %+v", h)
+	}
+	if rl := h.RateLimit; rl == nil || rl.Limit != 60 || rl.Remaining != 57 || !rl.Reset.Equal(time.Unix(1700000000, 0)) {
+		t.Errorf("rate limit = %+v", rl)
+	}
+
+	var metrics strings.Builder
+	if err := stats.WritePrometheus(&metrics); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{
+		`registries_http_responses_total{host="` + host + `",status="503"} 1`,
+		`registries_http_retries_total{host="` + host + `"} 1`,
+		`registries_rate_limit_remaining{host="` + host + `"} 57`,
+	} {
+		if !strings.Contains(metrics.String(), line+"\n") {
+			t.Errorf("metrics missing %q:\n%s", line, metrics.String())
+		}
+	}
+
+	// Requests that get no response count under status 0
+	server.Close()
+	_, _ = c.WithRetryPolicy(&client.RetryPolicy{}).GetBody(context.Background(), server.URL+"/gone")
+	if n := c.ClientStats().Requests[0]; n != 1 {
+		t.Errorf("requests without a response = %d, want 1", n)
+	}
+}
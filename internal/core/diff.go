@@ -0,0 +1,302 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/git-pkgs/purl"
+)
+
+// VersionDiff is what changed between two versions of a package, as
+// returned by Diff and DiffVersions.
+type VersionDiff struct {
+	Name string `json:"name"`
+	From string `json:"from"`
+	To   string `json:"to"`
+
+	// Dependencies lists the dependencies added, removed or given a new
+	// requirement, sorted by name.
+	Dependencies []DependencyChange `json:"dependencies,omitempty"`
+
+	// LicensesFrom and LicensesTo are the versions' declared licenses;
+	// LicenseChanged reports whether they differ.
+	LicensesFrom string `json:"licenses_from,omitempty"`
+	LicensesTo   string `json:"licenses_to,omitempty"`
+
+	// MaintainersAdded and MaintainersRemoved compare the maintainers
+	// recorded on each version, for registries that record them.
+	// PublisherFrom and PublisherTo are set when the account publishing
+	// the versions changed.
+	MaintainersAdded   []Maintainer `json:"maintainers_added,omitempty"`
+	MaintainersRemoved []Maintainer `json:"maintainers_removed,omitempty"`
+	PublisherFrom      *Maintainer  `json:"publisher_from,omitempty"`
+	PublisherTo        *Maintainer  `json:"publisher_to,omitempty"`
+
+	// SizeDelta is how many bytes larger To is than From, comparing
+	// unpacked sizes where both have one and download sizes otherwise,
+	// and SizeChange the ratio SizeChange gives. Both are zero if the
+	// versions have no size in common.
+	SizeDelta  int64   `json:"size_delta,omitempty"`
+	SizeChange float64 `json:"size_change,omitempty"`
+}
+
+// LicenseChanged reports whether the versions declare different licenses,
+// after normalizing them to SPDX expressions.
+func (d *VersionDiff) LicenseChanged() bool {
+	return normalizedLicense(d.LicensesFrom) != normalizedLicense(d.LicensesTo)
+}
+
+// Empty reports whether nothing the diff compares changed.
+func (d *VersionDiff) Empty() bool {
+	return len(d.Dependencies) == 0 && !d.LicenseChanged() &&
+		len(d.MaintainersAdded) == 0 && len(d.MaintainersRemoved) == 0 &&
+		d.PublisherFrom == nil && d.PublisherTo == nil && d.SizeDelta == 0
+}
+
+func normalizedLicense(license string) string {
+	if normalized := NormalizeLicense(license); normalized != "" {
+		return normalized
+	}
+	return strings.TrimSpace(license)
+}
+
+// DependencyChangeKind says how a dependency changed between versions.
+type DependencyChangeKind string
+
+const (
+	DependencyAdded   DependencyChangeKind = "added"
+	DependencyRemoved DependencyChangeKind = "removed"
+	DependencyChanged DependencyChangeKind = "changed"
+)
+
+// DependencyChange is a dependency that was added, removed or changed its
+// requirement between two versions. Dependencies are matched on name,
+// scope, target, extra and relation, so a dependency moving from
+// development to runtime is reported as removed from one scope and added
+// to the other.
+type DependencyChange struct {
+	Kind     DependencyChangeKind `json:"kind"`
+	Name     string               `json:"name"`
+	Scope    Scope                `json:"scope,omitempty"`
+	Target   string               `json:"target,omitempty"`
+	Extra    string               `json:"extra,omitempty"`
+	Relation Relation             `json:"relation,omitempty"`
+	// From and To are the requirements in each version, empty for the
+	// version without the dependency.
+	From string `json:"from,omitempty"`
+	To   string `json:"to,omitempty"`
+	// OptionalFrom and OptionalTo say whether the dependency is optional
+	// in each version.
+	OptionalFrom bool `json:"optional_from,omitempty"`
+	OptionalTo   bool `json:"optional_to,omitempty"`
+}
+
+// Diff fetches the versions of two PURLs, such as
+// pkg:npm/lodash@4.17.20 and pkg:npm/lodash@4.17.21, and reports what
+// changed from the first to the second. Both must be versions of the same
+// package. Dependencies are left out for registries that don't record
+// them.
+func Diff(ctx context.Context, purlA, purlB string, client *Client) (*VersionDiff, error) {
+	a, err := purl.Parse(purlA)
+	if err != nil {
+		return nil, err
+	}
+	b, err := purl.Parse(purlB)
+	if err != nil {
+		return nil, err
+	}
+	if a.Type != b.Type || a.FullName() != b.FullName() {
+		return nil, fmt.Errorf("%s and %s are not the same package", purlA, purlB)
+	}
+	if b.Version == "" {
+		return nil, fmt.Errorf("PURL has no version: %s", purlB)
+	}
+
+	reg, name, from, err := NewFromPURL(purlA, client)
+	if err != nil {
+		return nil, err
+	}
+	if from == "" {
+		return nil, fmt.Errorf("PURL has no version: %s", purlA)
+	}
+	to := b.Version
+
+	versions, err := FetchVersions(ctx, reg, name)
+	if err != nil {
+		return nil, err
+	}
+	var fromVersion, toVersion *Version
+	for i := range versions {
+		switch versions[i].Number {
+		case from:
+			fromVersion = &versions[i]
+		case to:
+			toVersion = &versions[i]
+		}
+	}
+	for _, found := range []struct {
+		v      *Version
+		number string
+	}{{fromVersion, from}, {toVersion, to}} {
+		if found.v == nil {
+			return nil, &NotFoundError{Ecosystem: reg.Ecosystem(), Name: name, Version: found.number}
+		}
+	}
+
+	fromDeps, err := FetchDependencies(ctx, reg, name, from)
+	if err != nil && !errors.Is(err, ErrNotSupported) {
+		return nil, err
+	}
+	toDeps, err := FetchDependencies(ctx, reg, name, to)
+	if err != nil && !errors.Is(err, ErrNotSupported) {
+		return nil, err
+	}
+
+	d := DiffVersions(*fromVersion, *toVersion, fromDeps, toDeps)
+	d.Name = name
+	return d, nil
+}
+
+// DiffVersions compares two versions already fetched, with the
+// dependencies of each, and reports what changed from one to the other.
+// The diff's Name is left for the caller to set.
+func DiffVersions(from, to Version, fromDeps, toDeps []Dependency) *VersionDiff {
+	d := &VersionDiff{
+		From:         from.Number,
+		To:           to.Number,
+		Dependencies: diffDependencies(fromDeps, toDeps),
+		LicensesFrom: from.Licenses,
+		LicensesTo:   to.Licenses,
+		SizeChange:   SizeChange(from, to),
+	}
+
+	if len(from.Maintainers) > 0 && len(to.Maintainers) > 0 {
+		d.MaintainersAdded = maintainersMissing(to.Maintainers, from.Maintainers)
+		d.MaintainersRemoved = maintainersMissing(from.Maintainers, to.Maintainers)
+	}
+	if from.Publisher != nil && to.Publisher != nil && maintainerKey(*from.Publisher) != maintainerKey(*to.Publisher) {
+		d.PublisherFrom, d.PublisherTo = from.Publisher, to.Publisher
+	}
+
+	switch {
+	case from.UnpackedSize > 0 && to.UnpackedSize > 0:
+		d.SizeDelta = to.UnpackedSize - from.UnpackedSize
+	case from.Size > 0 && to.Size > 0:
+		d.SizeDelta = to.Size - from.Size
+	}
+	return d
+}
+
+// dependencyKey identifies a dependency across versions.
+type dependencyKey struct {
+	name, target, extra string
+	scope               Scope
+	relation            Relation
+}
+
+// dependencyEntry is what a version requires of a dependency. Registries
+// can list a dependency more than once under the same key, such as once
+// per conditional branch, so the requirements are joined.
+type dependencyEntry struct {
+	requirements []string
+	optional     bool
+}
+
+func (e *dependencyEntry) requirement() string {
+	sort.Strings(e.requirements)
+	return strings.Join(e.requirements, ", ")
+}
+
+func dependencyEntries(deps []Dependency) map[dependencyKey]*dependencyEntry {
+	entries := make(map[dependencyKey]*dependencyEntry, len(deps))
+	for _, dep := range deps {
+		key := dependencyKey{name: dep.Name, target: dep.Target, extra: dep.Extra, scope: dep.Scope, relation: dep.Relation}
+		e := entries[key]
+		if e == nil {
+			e = &dependencyEntry{}
+			entries[key] = e
+		}
+		if dep.Requirements != "" && !slices.Contains(e.requirements, dep.Requirements) {
+			e.requirements = append(e.requirements, dep.Requirements)
+		}
+		e.optional = e.optional || dep.Optional
+	}
+	return entries
+}
+
+func diffDependencies(fromDeps, toDeps []Dependency) []DependencyChange {
+	from := dependencyEntries(fromDeps)
+	to := dependencyEntries(toDeps)
+
+	var changes []DependencyChange
+	change := func(kind DependencyChangeKind, key dependencyKey) DependencyChange {
+		return DependencyChange{Kind: kind, Name: key.name, Scope: key.scope, Target: key.target, Extra: key.extra, Relation: key.relation}
+	}
+	for key, f := range from {
+		t, ok := to[key]
+		if !ok {
+			c := change(DependencyRemoved, key)
+			c.From, c.OptionalFrom = f.requirement(), f.optional
+			changes = append(changes, c)
+			continue
+		}
+		if f.requirement() != t.requirement() || f.optional != t.optional {
+			c := change(DependencyChanged, key)
+			c.From, c.OptionalFrom = f.requirement(), f.optional
+			c.To, c.OptionalTo = t.requirement(), t.optional
+			changes = append(changes, c)
+		}
+	}
+	for key, t := range to {
+		if _, ok := from[key]; !ok {
+			c := change(DependencyAdded, key)
+			c.To, c.OptionalTo = t.requirement(), t.optional
+			changes = append(changes, c)
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		a, b := changes[i], changes[j]
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		if a.Scope != b.Scope {
+			return a.Scope < b.Scope
+		}
+		if a.Target != b.Target {
+			return a.Target < b.Target
+		}
+		return a.Extra < b.Extra
+	})
+	return changes
+}
+
+// maintainerKey identifies a maintainer by the most stable field the
+// registry gives.
+func maintainerKey(m Maintainer) string {
+	for _, id := range []string{m.UUID, m.Login, m.Email, m.Name} {
+		if id != "" {
+			return strings.ToLower(id)
+		}
+	}
+	return ""
+}
+
+// maintainersMissing returns the maintainers in a that aren't in b.
+func maintainersMissing(a, b []Maintainer) []Maintainer {
+	in := make(map[string]bool, len(b))
+	for _, m := range b {
+		in[maintainerKey(m)] = true
+	}
+	var missing []Maintainer
+	for _, m := range a {
+		if !in[maintainerKey(m)] {
+			missing = append(missing, m)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,112 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestDiffVersions(t *testing.T) {
+	from := Version{
+		Number:       "1.0.0",
+		Licenses:     "MIT",
+		UnpackedSize: 1000,
+		Maintainers:  []Maintainer{{Login: "alice"}, {Login: "bob"}},
+		Publisher:    &Maintainer{Login: "alice"},
+	}
+	to := Version{
+		Number:       "2.0.0",
+		Licenses:     "Apache-2.0",
+		UnpackedSize: 4000,
+		Maintainers:  []Maintainer{{Login: "Alice"}, {Login: "mallory"}},
+		Publisher:    &Maintainer{Login: "mallory"},
+	}
+	fromDeps := []Dependency{
+		{Name: "chalk", Requirements: "^4.0.0", Scope: Runtime},
+		{Name: "debug", Requirements: "^4.3.0", Scope: Runtime},
+		{Name: "jest", Requirements: "^29.0.0", Scope: Development},
+		{Name: "fsevents", Requirements: "^2.3.0", Scope: Runtime, Optional: true},
+	}
+	toDeps := []Dependency{
+		{Name: "chalk", Requirements: "^5.0.0", Scope: Runtime},
+		{Name: "debug", Requirements: "^4.3.0", Scope: Runtime},
+		{Name: "jest", Requirements: "^29.0.0", Scope: Runtime},
+		{Name: "fsevents", Requirements: "^2.3.0", Scope: Runtime},
+		{Name: "postinstall-thing", Requirements: "1.0.0", Scope: Runtime},
+	}
+
+	d := DiffVersions(from, to, fromDeps, toDeps)
+	want := []DependencyChange{
+		{Kind: DependencyChanged, Name: "chalk", Scope: Runtime, From: "^4.0.0", To: "^5.0.0"},
+		{Kind: DependencyChanged, Name: "fsevents", Scope: Runtime, From: "^2.3.0", To: "^2.3.0", OptionalFrom: true},
+		{Kind: DependencyRemoved, Name: "jest", Scope: Development, From: "^29.0.0"},
+		{Kind: DependencyAdded, Name: "jest", Scope: Runtime, To: "^29.0.0"},
+		{Kind: DependencyAdded, Name: "postinstall-thing", Scope: Runtime, To: "1.0.0"},
+	}
+	if !reflect.DeepEqual(d.Dependencies, want) {
+		t.Errorf("Dependencies = %+v, want %+v", d.Dependencies, want)
+	}
+	if !d.LicenseChanged() {
+		t.Error("expected a license change")
+	}
+	if len(d.MaintainersAdded) != 1 || d.MaintainersAdded[0].Login != "mallory" {
+		t.Errorf("MaintainersAdded = %+v", d.MaintainersAdded)
+	}
+	if len(d.MaintainersRemoved) != 1 || d.MaintainersRemoved[0].Login != "bob" {
+		t.Errorf("MaintainersRemoved = %+v", d.MaintainersRemoved)
+	}
+	if d.PublisherFrom == nil || d.PublisherTo == nil || d.PublisherTo.Login != "mallory" {
+		t.Errorf("publisher change = %+v -> %+v", d.PublisherFrom, d.PublisherTo)
+	}
+	if d.SizeDelta != 3000 || d.SizeChange != 4 {
+		t.Errorf("SizeDelta = %d, SizeChange = %v", d.SizeDelta, d.SizeChange)
+	}
+
+	same := DiffVersions(Version{Number: "1.0.0", Licenses: "mit"}, Version{Number: "1.0.1", Licenses: "MIT"}, fromDeps, fromDeps)
+	if !same.Empty() {
+		t.Errorf("expected an empty diff, got %+v", same)
+	}
+}
+
+type diffRegistry struct{}
+
+func (diffRegistry) Ecosystem() string { return "difftest" }
+func (diffRegistry) URLs() URLBuilder  { return nil }
+func (diffRegistry) FetchPackage(ctx context.Context, name string) (*Package, error) {
+	return &Package{Name: name}, nil
+}
+func (diffRegistry) FetchVersions(ctx context.Context, name string) ([]Version, error) {
+	return []Version{{Number: "1.0.0", Size: 100}, {Number: "1.1.0", Size: 150}}, nil
+}
+func (diffRegistry) FetchDependencies(ctx context.Context, name, version string) ([]Dependency, error) {
+	if version == "1.1.0" {
+		return []Dependency{{Name: "b", Requirements: ">= 1"}}, nil
+	}
+	return nil, nil
+}
+
+func TestDiff(t *testing.T) {
+	Register("difftest", "https://diff.example.com", func(baseURL string, client *Client) Registry {
+		return diffRegistry{}
+	})
+	ctx := context.Background()
+
+	d, err := Diff(ctx, "pkg:difftest/a@1.0.0", "pkg:difftest/a@1.1.0", nil)
+	if err != nil {
+		t.Fatalf("Diff failed: %v", err)
+	}
+	if d.Name != "a" || d.From != "1.0.0" || d.To != "1.1.0" || d.SizeDelta != 50 {
+		t.Errorf("unexpected diff %+v", d)
+	}
+	if len(d.Dependencies) != 1 || d.Dependencies[0].Kind != DependencyAdded {
+		t.Errorf("Dependencies = %+v", d.Dependencies)
+	}
+
+	if _, err := Diff(ctx, "pkg:difftest/a@1.0.0", "pkg:difftest/other@1.1.0", nil); err == nil {
+		t.Error("expected an error diffing different packages")
+	}
+	if _, err := Diff(ctx, "pkg:difftest/a@1.0.0", "pkg:difftest/a@9.9.9", nil); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for a missing version, got %v", err)
+	}
+}
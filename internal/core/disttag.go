@@ -0,0 +1,36 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// DistTag is a named release channel, such as npm's "latest", "next" or
+// "beta", and the version it currently points at.
+type DistTag struct {
+	Name    string
+	Version string
+	// PublishedAt is when the tagged version was published. Registries
+	// don't record when a tag was moved, so this is the earliest it can
+	// have moved to Version.
+	PublishedAt time.Time
+}
+
+// DistTagFetcher is implemented by registries with release channels that
+// point at versions.
+type DistTagFetcher interface {
+	// FetchDistTags returns every tag of a package, "latest" first and the
+	// rest by name.
+	FetchDistTags(ctx context.Context, name string) ([]DistTag, error)
+}
+
+// FetchDistTags returns the dist-tags of a package using reg. It returns an
+// error wrapping ErrNotSupported if the registry has no tags.
+func FetchDistTags(ctx context.Context, reg Registry, name string) ([]DistTag, error) {
+	df, ok := reg.(DistTagFetcher)
+	if !ok {
+		return nil, fmt.Errorf("%s dist-tags: %w", reg.Ecosystem(), ErrNotSupported)
+	}
+	return df.FetchDistTags(ctx, name)
+}
@@ -0,0 +1,108 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Content types of a Document.
+const (
+	Markdown         = "text/markdown"
+	ReStructuredText = "text/x-rst"
+	HTML             = "text/html"
+	PlainText        = "text/plain"
+)
+
+// Document is a long-form text such as a README or package description.
+type Document struct {
+	Content     string
+	ContentType string // Markdown, ReStructuredText, HTML or PlainText
+	Filename    string // original file name if known, e.g. README.md
+	URL         string // where the document was fetched from
+}
+
+// ReadmeFetcher is implemented by registries that serve a package's README
+// or long description.
+type ReadmeFetcher interface {
+	// FetchReadme returns the README of a version, or of the latest version
+	// if version is empty.
+	FetchReadme(ctx context.Context, name, version string) (*Document, error)
+}
+
+// FetchReadme returns the README of a package version using reg. It returns
+// an error wrapping ErrNotSupported if the registry doesn't serve READMEs.
+func FetchReadme(ctx context.Context, reg Registry, name, version string) (*Document, error) {
+	rf, ok := reg.(ReadmeFetcher)
+	if !ok {
+		return nil, fmt.Errorf("%s readme: %w", reg.Ecosystem(), ErrNotSupported)
+	}
+	return rf.FetchReadme(ctx, name, version)
+}
+
+// FetchReadmeFromPURL returns the README for a PURL, using the latest
+// version if the PURL has none.
+func FetchReadmeFromPURL(ctx context.Context, purlStr string, client *Client) (*Document, error) {
+	reg, name, version, err := NewFromPURL(purlStr, client)
+	if err != nil {
+		return nil, err
+	}
+	return FetchReadme(ctx, reg, name, version)
+}
+
+// DocumentationURL returns where a package's documentation lives. A URL
+// the package declares, such as PyPI's "Documentation" project URL or a
+// crate's documentation field, is preferred; the registry's guess from
+// URLBuilder.Documentation is only used when nothing is declared.
+func DocumentationURL(ctx context.Context, reg Registry, name, version string) (string, error) {
+	pkg, err := reg.FetchPackage(ctx, name)
+	if err != nil {
+		return "", err
+	}
+	if pkg.Documentation != "" {
+		return pkg.Documentation, nil
+	}
+	return reg.URLs().Documentation(name, version), nil
+}
+
+// DocumentationURLFromPURL returns the documentation URL for a PURL.
+func DocumentationURLFromPURL(ctx context.Context, purlStr string, client *Client) (string, error) {
+	reg, name, version, err := NewFromPURL(purlStr, client)
+	if err != nil {
+		return "", err
+	}
+	return DocumentationURL(ctx, reg, name, version)
+}
+
+// ContentTypeFromFilename guesses a Document content type from a README file
+// name. Files without a recognised extension are treated as Markdown, which
+// is what most registries render them as.
+func ContentTypeFromFilename(filename string) string {
+	switch strings.ToLower(path.Ext(filename)) {
+	case ".rst", ".rest":
+		return ReStructuredText
+	case ".html", ".htm":
+		return HTML
+	case ".txt", ".text":
+		return PlainText
+	default:
+		return Markdown
+	}
+}
+
+// NormalizeContentType maps a MIME type such as "text/markdown; charset=UTF-8"
+// to one of the Document content types. Unknown types become PlainText.
+func NormalizeContentType(contentType string) string {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	switch strings.ToLower(strings.TrimSpace(mediaType)) {
+	case Markdown, "text/x-markdown":
+		return Markdown
+	case ReStructuredText, "text/rst", "text/prs.fallenstein.rst":
+		return ReStructuredText
+	case HTML, "application/xhtml+xml":
+		return HTML
+	default:
+		return PlainText
+	}
+}
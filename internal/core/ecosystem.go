@@ -0,0 +1,125 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/git-pkgs/registries/names"
+)
+
+// ecosystemAliases maps other names for an ecosystem to the name its
+// registry is registered under, which is the PURL type of the PURLs it
+// builds. The aliases are the names registries, package managers and
+// vulnerability databases such as OSV use, and PURL types from the spec
+// that differ from ours, such as "homebrew".
+var ecosystemAliases = map[string]string{
+	"anaconda":        "conda",
+	"bundler":         "gem",
+	"conda-forge":     "conda",
+	"crates":          "cargo",
+	"crates.io":       "cargo",
+	"git":             "gittags",
+	"github-releases": "github-release",
+	"go":              "golang",
+	"gomod":           "golang",
+	"hex.pm":          "hex",
+	"hexpm":           "hex",
+	"homebrew":        "brew",
+	"npmjs":           "npm",
+	"packagist":       "composer",
+	"pip":             "pypi",
+	"pub.dev":         "pub",
+	"rubygems":        "gem",
+	"wasm-pkg":        "wasm",
+	"wkg":             "wasm",
+}
+
+// CanonicalEcosystem returns the name ecosystem is registered under, so
+// CanonicalEcosystem("rubygems") is "gem" and CanonicalEcosystem("Go") is
+// "golang". Names are matched case-insensitively; ones that aren't aliases
+// are returned lowercased.
+func CanonicalEcosystem(ecosystem string) string {
+	eco := strings.ToLower(strings.TrimSpace(ecosystem))
+	if canonical, ok := ecosystemAliases[eco]; ok {
+		return canonical
+	}
+	return eco
+}
+
+// EcosystemAliases returns the alias table CanonicalEcosystem uses, from
+// alias to registered name.
+func EcosystemAliases() map[string]string {
+	aliases := make(map[string]string, len(ecosystemAliases))
+	for alias, eco := range ecosystemAliases {
+		aliases[alias] = eco
+	}
+	return aliases
+}
+
+// UnknownEcosystemError is returned by New for an ecosystem that isn't
+// registered under its name or an alias. The ecosystem's package may just
+// not be imported; registries/all imports them all.
+type UnknownEcosystemError struct {
+	Ecosystem string
+	// Supported lists the registered ecosystems, sorted.
+	Supported []string
+	// Suggestions lists registered ecosystems close to Ecosystem, nearest
+	// first.
+	Suggestions []string
+}
+
+func (e *UnknownEcosystemError) Error() string {
+	if len(e.Suggestions) > 0 {
+		return fmt.Sprintf("unknown ecosystem: %s (did you mean %s?)", e.Ecosystem, strings.Join(e.Suggestions, ", "))
+	}
+	if len(e.Supported) == 0 {
+		return fmt.Sprintf("unknown ecosystem: %s (none are registered; import registries/all)", e.Ecosystem)
+	}
+	return fmt.Sprintf("unknown ecosystem: %s (supported: %s)", e.Ecosystem, strings.Join(e.Supported, ", "))
+}
+
+// unknownEcosystem builds the error for ecosystem, suggesting registered
+// ecosystems whose name or alias is a few edits away or starts with it.
+func unknownEcosystem(ecosystem string) *UnknownEcosystemError {
+	supported := SupportedEcosystems()
+	sort.Strings(supported)
+
+	eco := strings.ToLower(strings.TrimSpace(ecosystem))
+	best := make(map[string]int)
+	consider := func(name, registered string) {
+		dist := names.Distance(eco, name)
+		if len(eco) >= 2 && strings.HasPrefix(name, eco) {
+			dist = min(dist, 1)
+		}
+		if dist > max(1, len(name)/3) {
+			return
+		}
+		if d, ok := best[registered]; !ok || dist < d {
+			best[registered] = dist
+		}
+	}
+	registered := make(map[string]bool, len(supported))
+	for _, name := range supported {
+		registered[name] = true
+		consider(name, name)
+	}
+	for alias, name := range ecosystemAliases {
+		if registered[name] {
+			consider(alias, name)
+		}
+	}
+
+	suggestions := make([]string, 0, len(best))
+	for name := range best {
+		suggestions = append(suggestions, name)
+	}
+	sort.Slice(suggestions, func(i, j int) bool {
+		a, b := suggestions[i], suggestions[j]
+		if best[a] != best[b] {
+			return best[a] < best[b]
+		}
+		return a < b
+	})
+	return &UnknownEcosystemError{Ecosystem: ecosystem, Supported: supported, Suggestions: suggestions}
+}
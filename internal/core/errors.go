@@ -7,9 +7,13 @@ import (
 // ErrNotFound is returned when a package or version is not found.
 var ErrNotFound = client.ErrNotFound
 
+// ErrNotSupported is returned when a registry doesn't offer the requested data.
+var ErrNotSupported = client.ErrNotSupported
+
 // Type aliases for backward compatibility.
 type (
 	HTTPError      = client.HTTPError
 	NotFoundError  = client.NotFoundError
 	RateLimitError = client.RateLimitError
+	GraphQLError   = client.GraphQLError
 )
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"sort"
+	"strings"
 
 	"github.com/git-pkgs/purl"
 )
@@ -13,23 +14,44 @@ const defaultConcurrency = 15
 // NewFromPURL creates a registry client from a PURL and returns the parsed components.
 // Returns the registry, full package name, and version (empty if not in PURL).
 // If the PURL has a repository_url qualifier, it's used as the base URL for private registries.
+// Other qualifiers are applied to registries implementing QualifiedRegistry.
+// Names that fail ValidateName are rejected before any request is made.
 func NewFromPURL(purlStr string, client *Client) (Registry, string, string, error) {
 	p, err := purl.Parse(purlStr)
 	if err != nil {
 		return nil, "", "", err
 	}
+	if err := ValidateName(p.Type, p.FullName()); err != nil {
+		return nil, "", "", err
+	}
 
-	// Extract repository_url qualifier for private registry support
-	baseURL := p.RepositoryURL()
-
-	reg, err := New(p.Type, baseURL, client)
+	reg, err := New(p.Type, registryURL(p), client)
 	if err != nil {
 		return nil, "", "", err
 	}
 
+	if q, ok := reg.(QualifiedRegistry); ok {
+		qualifiers := p.Qualifiers.Map()
+		delete(qualifiers, "repository_url")
+		if len(qualifiers) > 0 {
+			reg = q.WithQualifiers(qualifiers)
+		}
+	}
+
 	return reg, p.FullName(), p.Version, nil
 }
 
+// registryURL returns the base URL from a PURL's repository_url qualifier.
+// The PURL spec allows it without a scheme ("repo.spring.io/release"), in
+// which case https is assumed.
+func registryURL(p *purl.PURL) string {
+	u := p.RepositoryURL()
+	if u != "" && !strings.Contains(u, "://") {
+		u = "https://" + u
+	}
+	return u
+}
+
 // FetchPackageFromPURL fetches package metadata using a PURL.
 func FetchPackageFromPURL(ctx context.Context, purlStr string, client *Client) (*Package, error) {
 	reg, name, _, err := NewFromPURL(purlStr, client)
@@ -43,58 +65,51 @@ func FetchPackageFromPURL(ctx context.Context, purlStr string, client *Client) (
 // FetchVersionFromPURL fetches a specific version's metadata using a PURL.
 // Returns an error if the PURL doesn't include a version.
 func FetchVersionFromPURL(ctx context.Context, purlStr string, client *Client) (*Version, error) {
-	p, err := purl.Parse(purlStr)
+	reg, name, version, err := NewFromPURL(purlStr, client)
 	if err != nil {
 		return nil, err
 	}
 
-	if p.Version == "" {
-		return nil, fmt.Errorf("PURL has no version: %s", purlStr)
-	}
+	return fetchVersion(ctx, reg, name, version, purlStr)
+}
 
-	baseURL := p.RepositoryURL()
-	reg, err := New(p.Type, baseURL, client)
-	if err != nil {
-		return nil, err
+// fetchVersion finds version among the versions of name listed by reg.
+func fetchVersion(ctx context.Context, reg Registry, name, version, purlStr string) (*Version, error) {
+	if version == "" {
+		return nil, fmt.Errorf("PURL has no version: %s", purlStr)
 	}
 
-	versions, err := reg.FetchVersions(ctx, p.FullName())
+	versions, err := FetchVersions(ctx, reg, name)
 	if err != nil {
 		return nil, err
 	}
 
 	for _, v := range versions {
-		if v.Number == p.Version {
+		if v.Number == version {
 			return &v, nil
 		}
 	}
 
 	return nil, &NotFoundError{
-		Ecosystem: p.Type,
-		Name:      p.FullName(),
-		Version:   p.Version,
+		Ecosystem: reg.Ecosystem(),
+		Name:      name,
+		Version:   version,
 	}
 }
 
 // FetchDependenciesFromPURL fetches dependencies for a specific version using a PURL.
 // Returns an error if the PURL doesn't include a version.
 func FetchDependenciesFromPURL(ctx context.Context, purlStr string, client *Client) ([]Dependency, error) {
-	p, err := purl.Parse(purlStr)
+	reg, name, version, err := NewFromPURL(purlStr, client)
 	if err != nil {
 		return nil, err
 	}
 
-	if p.Version == "" {
+	if version == "" {
 		return nil, fmt.Errorf("PURL has no version: %s", purlStr)
 	}
 
-	baseURL := p.RepositoryURL()
-	reg, err := New(p.Type, baseURL, client)
-	if err != nil {
-		return nil, err
-	}
-
-	return reg.FetchDependencies(ctx, p.FullName(), p.Version)
+	return FetchDependencies(ctx, reg, name, version)
 }
 
 // FetchMaintainersFromPURL fetches maintainer information using a PURL.
@@ -104,13 +119,13 @@ func FetchMaintainersFromPURL(ctx context.Context, purlStr string, client *Clien
 		return nil, err
 	}
 
-	return reg.FetchMaintainers(ctx, name)
+	return FetchMaintainers(ctx, reg, name)
 }
 
 // FetchLatestVersion returns the latest non-yanked/retracted/deprecated version.
 // Returns nil if no valid versions exist.
 func FetchLatestVersion(ctx context.Context, reg Registry, name string) (*Version, error) {
-	versions, err := reg.FetchVersions(ctx, name)
+	versions, err := FetchVersions(ctx, reg, name)
 	if err != nil {
 		return nil, err
 	}
@@ -168,8 +183,13 @@ func BulkFetchPackages(ctx context.Context, purls []string, client *Client) map[
 
 // BulkFetchPackagesWithConcurrency fetches packages with a custom concurrency limit.
 func BulkFetchPackagesWithConcurrency(ctx context.Context, purls []string, client *Client, concurrency int) map[string]*Package {
+	pool := NewPool(client)
 	return ParallelMap(ctx, purls, concurrency, func(ctx context.Context, p string) (*Package, error) {
-		return FetchPackageFromPURL(ctx, p, client)
+		reg, name, _, err := pool.FromPURL(p)
+		if err != nil {
+			return nil, err
+		}
+		return reg.FetchPackage(ctx, name)
 	})
 }
 
@@ -183,8 +203,13 @@ func BulkFetchVersions(ctx context.Context, purls []string, client *Client) map[
 
 // BulkFetchVersionsWithConcurrency fetches versions with a custom concurrency limit.
 func BulkFetchVersionsWithConcurrency(ctx context.Context, purls []string, client *Client, concurrency int) map[string]*Version {
+	pool := NewPool(client)
 	return ParallelMap(ctx, purls, concurrency, func(ctx context.Context, p string) (*Version, error) {
-		return FetchVersionFromPURL(ctx, p, client)
+		reg, name, version, err := pool.FromPURL(p)
+		if err != nil {
+			return nil, err
+		}
+		return fetchVersion(ctx, reg, name, version, p)
 	})
 }
 
@@ -196,7 +221,12 @@ func BulkFetchLatestVersions(ctx context.Context, purls []string, client *Client
 
 // BulkFetchLatestVersionsWithConcurrency fetches latest versions with a custom concurrency limit.
 func BulkFetchLatestVersionsWithConcurrency(ctx context.Context, purls []string, client *Client, concurrency int) map[string]*Version {
+	pool := NewPool(client)
 	return ParallelMap(ctx, purls, concurrency, func(ctx context.Context, p string) (*Version, error) {
-		return FetchLatestVersionFromPURL(ctx, p, client)
+		reg, name, _, err := pool.FromPURL(p)
+		if err != nil {
+			return nil, err
+		}
+		return FetchLatestVersion(ctx, reg, name)
 	})
 }
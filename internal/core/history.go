@@ -0,0 +1,77 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// PackageSnapshot is a package as it looked at a point in time, for
+// reproducing past resolutions and incident forensics.
+type PackageSnapshot struct {
+	// At is the time the snapshot was taken for.
+	At time.Time
+
+	// Package describes the package as of At where the registry keeps
+	// enough history, and as it is now otherwise. LatestVersion,
+	// LatestStableVersion and LatestReleasedAt are always as of At.
+	Package Package
+
+	// Versions are those published at or before At, newest first. Their
+	// Status is as the registry reports it now, since registries don't
+	// record when a version was yanked or deprecated.
+	Versions []Version
+}
+
+// HistoricalFetcher is implemented by registries that record when each
+// version was published, so a package can be reconstructed as of a past
+// time.
+type HistoricalFetcher interface {
+	FetchPackageAt(ctx context.Context, name string, at time.Time) (*PackageSnapshot, error)
+}
+
+// FetchPackageAt returns a package as it looked at time at using reg. It
+// returns a NotFoundError if nothing had been published by then, and an
+// error wrapping ErrNotSupported if the registry can't reconstruct history.
+func FetchPackageAt(ctx context.Context, reg Registry, name string, at time.Time) (*PackageSnapshot, error) {
+	hf, ok := reg.(HistoricalFetcher)
+	if !ok {
+		return nil, fmt.Errorf("%s package history: %w", reg.Ecosystem(), ErrNotSupported)
+	}
+	return hf.FetchPackageAt(ctx, name, at)
+}
+
+// SnapshotAt cuts a package and its versions down to what had been
+// published by at, for registries implementing HistoricalFetcher.
+// LatestVersion becomes the newest release published by then, or the
+// newest pre-release if there was no release yet, and UpdatedAt is cleared
+// as the registry doesn't say what it was. Versions without a publish time
+// can't be placed and are left out.
+func SnapshotAt(ecosystem string, pkg Package, versions []Version, at time.Time) (*PackageSnapshot, error) {
+	var published []Version
+	var numbers []string
+	for _, v := range versions {
+		if v.PublishedAt.IsZero() || v.PublishedAt.After(at) {
+			continue
+		}
+		published = append(published, v)
+		numbers = append(numbers, v.Number)
+	}
+	if len(published) == 0 {
+		return nil, &NotFoundError{Ecosystem: ecosystem, Name: pkg.Name}
+	}
+	sort.SliceStable(published, func(i, j int) bool {
+		return published[i].PublishedAt.After(published[j].PublishedAt)
+	})
+
+	pkg.LatestStableVersion = LatestStableOf(ecosystem, numbers)
+	pkg.LatestVersion = pkg.LatestStableVersion
+	if pkg.LatestVersion == "" {
+		pkg.LatestVersion = LatestOf(ecosystem, numbers)
+	}
+	pkg.LatestReleasedAt = published[0].PublishedAt
+	pkg.UpdatedAt = time.Time{}
+
+	return &PackageSnapshot{At: at, Package: pkg, Versions: published}, nil
+}
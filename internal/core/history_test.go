@@ -0,0 +1,51 @@
+package core
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSnapshotAt(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+	pkg := Package{
+		Name:          "left-pad",
+		LatestVersion: "2.0.0",
+		UpdatedAt:     day(20),
+	}
+	versions := []Version{
+		{Number: "1.0.0", PublishedAt: day(1)},
+		{Number: "1.1.0", PublishedAt: day(5)},
+		{Number: "2.0.0-rc.1", PublishedAt: day(8)},
+		{Number: "2.0.0", PublishedAt: day(15)},
+		{Number: "0.0.1"},
+	}
+
+	snap, err := SnapshotAt("npm", pkg, versions, day(10))
+	if err != nil {
+		t.Fatalf("SnapshotAt failed: %v", err)
+	}
+	if len(snap.Versions) != 3 || snap.Versions[0].Number != "2.0.0-rc.1" {
+		t.Errorf("versions = %v, want the three published by day 10, newest first", snap.Versions)
+	}
+	if snap.Package.LatestVersion != "1.1.0" || snap.Package.LatestStableVersion != "1.1.0" {
+		t.Errorf("latest = %q, stable = %q", snap.Package.LatestVersion, snap.Package.LatestStableVersion)
+	}
+	if !snap.Package.LatestReleasedAt.Equal(day(8)) || !snap.Package.UpdatedAt.IsZero() {
+		t.Errorf("LatestReleasedAt = %v, UpdatedAt = %v", snap.Package.LatestReleasedAt, snap.Package.UpdatedAt)
+	}
+
+	// Only a pre-release had been published
+	snap, err = SnapshotAt("npm", pkg, versions[2:], day(10))
+	if err != nil {
+		t.Fatalf("SnapshotAt failed: %v", err)
+	}
+	if snap.Package.LatestVersion != "2.0.0-rc.1" || snap.Package.LatestStableVersion != "" {
+		t.Errorf("latest = %q, stable = %q", snap.Package.LatestVersion, snap.Package.LatestStableVersion)
+	}
+
+	var notFound *NotFoundError
+	if _, err := SnapshotAt("npm", pkg, versions, day(1).Add(-time.Hour)); !errors.As(err, &notFound) {
+		t.Errorf("expected NotFoundError before the first release, got %v", err)
+	}
+}
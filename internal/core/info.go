@@ -0,0 +1,65 @@
+package core
+
+import (
+	"context"
+	"fmt"
+)
+
+// RegistryInfo describes a registry server as it describes itself.
+type RegistryInfo struct {
+	Ecosystem string
+	URL       string
+	// Software is the server implementation or API version where the
+	// registry reports one, such as "couch/stub" for npmjs.org.
+	Software string
+	// Packages and Downloads are registry-wide totals, zero when the
+	// registry doesn't publish them.
+	Packages  int64
+	Downloads int64
+	Metadata  map[string]any
+}
+
+// InfoFetcher is implemented by registries with an endpoint describing the
+// registry itself.
+type InfoFetcher interface {
+	RegistryInfo(ctx context.Context) (*RegistryInfo, error)
+}
+
+// Pinger is implemented by registries with a cheap request that checks the
+// registry is reachable and accepts the client's credentials.
+type Pinger interface {
+	// Ping returns nil if the registry answered, or the error that a real
+	// request would have hit, such as an HTTPError for a rejected token.
+	Ping(ctx context.Context) error
+}
+
+// FetchRegistryInfo returns what reg's server says about itself. It returns
+// an error wrapping ErrNotSupported if the registry has no such endpoint.
+func FetchRegistryInfo(ctx context.Context, reg Registry) (*RegistryInfo, error) {
+	inf, ok := reg.(InfoFetcher)
+	if !ok {
+		return nil, fmt.Errorf("%s registry info: %w", reg.Ecosystem(), ErrNotSupported)
+	}
+	return inf.RegistryInfo(ctx)
+}
+
+// Ping checks that reg's server is reachable with the client's credentials,
+// using the registry's Ping, or else its RegistryInfo. It returns an error
+// wrapping ErrNotSupported if the registry has neither.
+func Ping(ctx context.Context, reg Registry) error {
+	if p, ok := reg.(Pinger); ok {
+		return p.Ping(ctx)
+	}
+	if _, ok := reg.(InfoFetcher); ok {
+		_, err := FetchRegistryInfo(ctx, reg)
+		return err
+	}
+	return fmt.Errorf("%s ping: %w", reg.Ecosystem(), ErrNotSupported)
+}
+
+// PingURL makes a GET request to url that bypasses the client's cache, so a
+// ping always reaches the server.
+func PingURL(ctx context.Context, client *Client, url string) error {
+	_, err := client.WithCache(nil).WithoutDeduplication().GetBody(ctx, url)
+	return err
+}
@@ -0,0 +1,49 @@
+package core
+
+// Version.Metadata keys describing what installing a version does beyond
+// unpacking files, set the same way by every registry that can tell so
+// security tools can gate on them without knowing each ecosystem.
+const (
+	// MetadataInstallScripts is a []string naming what runs code when the
+	// version is installed or built: npm lifecycle scripts such as
+	// "postinstall", a crate's "build.rs", a Python "sdist" with no wheel
+	// to install instead, or NuGet's "tools/install.ps1".
+	MetadataInstallScripts = "install_scripts"
+
+	// MetadataNativeCode is true when the version compiles or ships
+	// native code: node-gyp addons, crates linking a native library,
+	// platform-specific wheels and gems, NuGet native runtimes.
+	MetadataNativeCode = "native_code"
+)
+
+// InstallScripts returns the install scripts recorded in a version's
+// metadata, or nil when there are none or the registry can't tell.
+func InstallScripts(v Version) []string {
+	scripts, _ := v.Metadata[MetadataInstallScripts].([]string)
+	return scripts
+}
+
+// HasInstallScripts reports whether installing the version runs code. A
+// false result can also mean the registry doesn't say.
+func HasInstallScripts(v Version) bool {
+	return len(InstallScripts(v)) > 0
+}
+
+// HasNativeCode reports whether the version compiles or ships native code.
+// A false result can also mean the registry doesn't say.
+func HasNativeCode(v Version) bool {
+	native, _ := v.Metadata[MetadataNativeCode].(bool)
+	return native
+}
+
+// SetInstallSignals records install scripts and native code in a version's
+// metadata for registries to call. Signals that weren't found are left
+// out rather than stored as empty.
+func SetInstallSignals(metadata map[string]any, scripts []string, native bool) {
+	if len(scripts) > 0 {
+		metadata[MetadataInstallScripts] = scripts
+	}
+	if native {
+		metadata[MetadataNativeCode] = true
+	}
+}
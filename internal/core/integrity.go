@@ -0,0 +1,158 @@
+package core
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// Integrity is a file digest: the hash algorithm and the raw digest.
+// Version.Integrity and Artifact.Integrity hold its String form, a
+// Subresource Integrity value such as "sha256-<base64>".
+type Integrity struct {
+	Algorithm string // "md5", "sha1", "sha256", "sha384" or "sha512"
+	Digest    []byte
+}
+
+// integrityAlgorithms lists the supported algorithms from weakest to
+// strongest, with their digest size in bytes and multihash code.
+var integrityAlgorithms = []struct {
+	name string
+	size int
+	code uint64
+}{
+	{"md5", 16, 0xd5},
+	{"sha1", 20, 0x11},
+	{"sha256", 32, 0x12},
+	{"sha384", 48, 0x20},
+	{"sha512", 64, 0x13},
+}
+
+func integrityAlgorithm(name string) (rank, size int, code uint64, ok bool) {
+	for i, a := range integrityAlgorithms {
+		if a.name == name {
+			return i, a.size, a.code, true
+		}
+	}
+	return 0, 0, 0, false
+}
+
+// ParseIntegrity parses an integrity string. Besides SRI ("sha512-<base64>")
+// it reads the "sha256-<hex>" form this module used before, and
+// "sha256:<hex>" digests as written by OCI and others. An SRI value
+// listing several hashes, separated by spaces, returns the strongest.
+func ParseIntegrity(s string) (Integrity, error) {
+	var best Integrity
+	bestRank := -1
+	for _, field := range strings.Fields(s) {
+		in, err := parseIntegrityHash(field)
+		if err != nil {
+			return Integrity{}, err
+		}
+		if rank, _, _, _ := integrityAlgorithm(in.Algorithm); rank > bestRank {
+			best, bestRank = in, rank
+		}
+	}
+	if bestRank < 0 {
+		return Integrity{}, fmt.Errorf("invalid integrity %q: empty", s)
+	}
+	return best, nil
+}
+
+func parseIntegrityHash(s string) (Integrity, error) {
+	// SRI allows options after the digest, which don't change it.
+	s, _, _ = strings.Cut(s, "?")
+	i := strings.IndexAny(s, "-:")
+	if i <= 0 {
+		return Integrity{}, fmt.Errorf("invalid integrity %q: no algorithm", s)
+	}
+	algorithm, value := strings.ToLower(s[:i]), s[i+1:]
+	_, size, _, ok := integrityAlgorithm(algorithm)
+	if !ok {
+		return Integrity{}, fmt.Errorf("invalid integrity %q: unsupported algorithm %q", s, algorithm)
+	}
+
+	// A hex digest is twice the size in characters, which no base64
+	// encoding of the right size can be.
+	if len(value) == 2*size {
+		if digest, err := hex.DecodeString(value); err == nil {
+			return Integrity{Algorithm: algorithm, Digest: digest}, nil
+		}
+	}
+	digest, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		digest, err = base64.RawStdEncoding.DecodeString(value)
+	}
+	if err != nil || len(digest) != size {
+		return Integrity{}, fmt.Errorf("invalid integrity %q: %s needs a %d byte digest in base64 or hex", s, algorithm, size)
+	}
+	return Integrity{Algorithm: algorithm, Digest: digest}, nil
+}
+
+// HexIntegrity returns the SRI string for a hex digest, as registries
+// publish them. It returns "" for an empty or malformed digest, so
+// versions without a usable checksum have no Integrity.
+func HexIntegrity(algorithm, digest string) string {
+	digest = strings.ToLower(strings.TrimSpace(digest))
+	in, err := parseIntegrityHash(algorithm + "-" + digest)
+	if err != nil || len(digest) != 2*len(in.Digest) {
+		return ""
+	}
+	return in.String()
+}
+
+// String returns the integrity in SRI form, "<algorithm>-<base64>".
+func (in Integrity) String() string {
+	if in.Algorithm == "" {
+		return ""
+	}
+	return in.Algorithm + "-" + base64.StdEncoding.EncodeToString(in.Digest)
+}
+
+// Hex returns the digest in lower-case hex, as checksum files and most
+// registry APIs write it.
+func (in Integrity) Hex() string {
+	return hex.EncodeToString(in.Digest)
+}
+
+// Equal reports whether two integrities have the same algorithm and
+// digest.
+func (in Integrity) Equal(other Integrity) bool {
+	return in.Algorithm == other.Algorithm && bytes.Equal(in.Digest, other.Digest)
+}
+
+// Multihash returns the digest as a multihash: the algorithm's multicodec
+// code and the digest length as varints, followed by the digest.
+func (in Integrity) Multihash() ([]byte, error) {
+	_, size, code, ok := integrityAlgorithm(in.Algorithm)
+	if !ok || len(in.Digest) != size {
+		return nil, fmt.Errorf("multihash: unsupported integrity %s", in)
+	}
+	b := binary.AppendUvarint(nil, code)
+	b = binary.AppendUvarint(b, uint64(len(in.Digest)))
+	return append(b, in.Digest...), nil
+}
+
+// ParseMultihash reads a multihash of one of the supported algorithms.
+func ParseMultihash(b []byte) (Integrity, error) {
+	code, n := binary.Uvarint(b)
+	if n <= 0 {
+		return Integrity{}, fmt.Errorf("multihash: invalid code")
+	}
+	length, m := binary.Uvarint(b[n:])
+	if m <= 0 || uint64(len(b[n+m:])) != length {
+		return Integrity{}, fmt.Errorf("multihash: length doesn't match digest")
+	}
+	for _, a := range integrityAlgorithms {
+		if a.code == code {
+			if int(length) != a.size {
+				return Integrity{}, fmt.Errorf("multihash: %s needs a %d byte digest", a.name, a.size)
+			}
+			return Integrity{Algorithm: a.name, Digest: bytes.Clone(b[n+m:])}, nil
+		}
+	}
+	return Integrity{}, fmt.Errorf("multihash: unsupported code 0x%x", code)
+}
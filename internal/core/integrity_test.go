@@ -0,0 +1,81 @@
+package core
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+func TestParseIntegrity(t *testing.T) {
+	// Digests of "abc".
+	sha1Hex := "a9993e364706816aba3e25717850c26c9cd0d89d"
+	sha256Hex := "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"
+	sha256SRI := "sha256-ungWv48Bz+pBQUDeXa4iI7ADYaOWF3qctBD/YfIAFa0="
+
+	tests := []struct {
+		in, algorithm, hex string
+	}{
+		{sha256SRI, "sha256", sha256Hex},
+		{"sha256-ungWv48Bz+pBQUDeXa4iI7ADYaOWF3qctBD/YfIAFa0", "sha256", sha256Hex},
+		{"sha256-" + sha256Hex, "sha256", sha256Hex},
+		{"SHA256:" + sha256Hex, "sha256", sha256Hex},
+		{"sha1-qZk+NkcGgWq6PiVxeFDCbJzQ2J0=?ct=application/gzip", "sha1", sha1Hex},
+		{"sha1-qZk+NkcGgWq6PiVxeFDCbJzQ2J0= " + sha256SRI, "sha256", sha256Hex},
+	}
+	for _, tt := range tests {
+		in, err := ParseIntegrity(tt.in)
+		if err != nil || in.Algorithm != tt.algorithm || in.Hex() != tt.hex {
+			t.Errorf("ParseIntegrity(%q) = %s %s, %v", tt.in, in.Algorithm, in.Hex(), err)
+		}
+	}
+
+	for _, in := range []string{"", "abc", "sha256-abc123", "crc32-AAAAAA==", "sha1-" + sha256Hex} {
+		if _, err := ParseIntegrity(in); err == nil {
+			t.Errorf("ParseIntegrity(%q) expected an error", in)
+		}
+	}
+}
+
+func TestHexIntegrity(t *testing.T) {
+	if got := HexIntegrity("sha256", "BA7816BF8F01CFEA414140DE5DAE2223B00361A396177A9CB410FF61F20015AD"); got != "sha256-ungWv48Bz+pBQUDeXa4iI7ADYaOWF3qctBD/YfIAFa0=" {
+		t.Errorf("HexIntegrity = %q", got)
+	}
+	// Malformed digests and base64 posing as hex give no integrity.
+	for _, digest := range []string{"", "abc123", "ungWv48Bz+pBQUDeXa4iI7ADYaOWF3qctBD/YfIAFa0="} {
+		if got := HexIntegrity("sha256", digest); got != "" {
+			t.Errorf("HexIntegrity(%q) = %q, want empty", digest, got)
+		}
+	}
+}
+
+func TestMultihash(t *testing.T) {
+	in, err := ParseIntegrity("sha256-ungWv48Bz+pBQUDeXa4iI7ADYaOWF3qctBD/YfIAFa0=")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mh, err := in.Multihash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "1220ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"; hex.EncodeToString(mh) != want {
+		t.Errorf("Multihash = %x, want %s", mh, want)
+	}
+	back, err := ParseMultihash(mh)
+	if err != nil || !back.Equal(in) {
+		t.Errorf("ParseMultihash = %s, %v", back, err)
+	}
+
+	// MD5's code takes two bytes as a varint.
+	md5 := Integrity{Algorithm: "md5", Digest: bytes.Repeat([]byte{1}, 16)}
+	mh, err = md5.Multihash()
+	if err != nil || mh[0] != 0xd5 || mh[1] != 0x01 || mh[2] != 16 {
+		t.Errorf("md5 Multihash = %x, %v", mh, err)
+	}
+	if back, err := ParseMultihash(mh); err != nil || !back.Equal(md5) {
+		t.Errorf("ParseMultihash = %s, %v", back, err)
+	}
+
+	if _, err := ParseMultihash(mh[:len(mh)-1]); err == nil {
+		t.Error("expected an error for a truncated multihash")
+	}
+}
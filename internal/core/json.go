@@ -0,0 +1,202 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// SchemaVersion is the version of the JSON encoding of Package, Version,
+// Dependency and Maintainer, written to each as schema_version. It goes up
+// when a field is renamed, removed or changes meaning; new fields don't
+// change it, and decoders ignore fields they don't know.
+const SchemaVersion = 1
+
+// ErrUnsupportedSchema is wrapped by the error returned when decoding JSON
+// written with a newer SchemaVersion than this module understands.
+var ErrUnsupportedSchema = errors.New("unsupported schema_version")
+
+// Plain versions of the types have their fields and tags but not their
+// methods, so marshaling one doesn't recurse, and a Maintainer nested in a
+// Version doesn't repeat schema_version.
+type (
+	plainPackage    Package
+	plainVersion    Version
+	plainDependency Dependency
+	plainMaintainer Maintainer
+)
+
+// MarshalJSON encodes a Package with its schema_version.
+func (p Package) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		SchemaVersion int `json:"schema_version"`
+		plainPackage
+	}{SchemaVersion, plainPackage(p)})
+}
+
+// UnmarshalJSON decodes a Package, see readMetadata for how Metadata is
+// read.
+func (p *Package) UnmarshalJSON(data []byte) error {
+	var v struct {
+		SchemaVersion int `json:"schema_version"`
+		plainPackage
+		Metadata json.RawMessage `json:"metadata"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	if err := checkSchema("package", v.SchemaVersion); err != nil {
+		return err
+	}
+	metadata, err := readMetadata(v.Metadata)
+	if err != nil {
+		return err
+	}
+	*p = Package(v.plainPackage)
+	p.Metadata = metadata
+	return nil
+}
+
+// MarshalJSON encodes a Version with its schema_version.
+func (v Version) MarshalJSON() ([]byte, error) {
+	var maintainers []plainMaintainer
+	for _, m := range v.Maintainers {
+		maintainers = append(maintainers, plainMaintainer(m))
+	}
+	return json.Marshal(struct {
+		SchemaVersion int `json:"schema_version"`
+		plainVersion
+		Publisher   *plainMaintainer  `json:"publisher,omitempty"`
+		Maintainers []plainMaintainer `json:"maintainers,omitempty"`
+	}{SchemaVersion, plainVersion(v), (*plainMaintainer)(v.Publisher), maintainers})
+}
+
+// UnmarshalJSON decodes a Version, see readMetadata for how Metadata is
+// read.
+func (v *Version) UnmarshalJSON(data []byte) error {
+	var w struct {
+		SchemaVersion int `json:"schema_version"`
+		plainVersion
+		Metadata json.RawMessage `json:"metadata"`
+	}
+	if err := json.Unmarshal(data, &w); err != nil {
+		return err
+	}
+	if err := checkSchema("version", w.SchemaVersion); err != nil {
+		return err
+	}
+	metadata, err := readMetadata(w.Metadata)
+	if err != nil {
+		return err
+	}
+	*v = Version(w.plainVersion)
+	v.Metadata = metadata
+	return nil
+}
+
+// MarshalJSON encodes a Dependency with its schema_version.
+func (d Dependency) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		SchemaVersion int `json:"schema_version"`
+		plainDependency
+	}{SchemaVersion, plainDependency(d)})
+}
+
+// UnmarshalJSON decodes a Dependency, see readMetadata for how Metadata
+// is read.
+func (d *Dependency) UnmarshalJSON(data []byte) error {
+	var v struct {
+		SchemaVersion int `json:"schema_version"`
+		plainDependency
+		Metadata json.RawMessage `json:"metadata"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	if err := checkSchema("dependency", v.SchemaVersion); err != nil {
+		return err
+	}
+	metadata, err := readMetadata(v.Metadata)
+	if err != nil {
+		return err
+	}
+	*d = Dependency(v.plainDependency)
+	d.Metadata = metadata
+	return nil
+}
+
+// MarshalJSON encodes a Maintainer with its schema_version.
+func (m Maintainer) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		SchemaVersion int `json:"schema_version"`
+		plainMaintainer
+	}{SchemaVersion, plainMaintainer(m)})
+}
+
+// UnmarshalJSON decodes a Maintainer.
+func (m *Maintainer) UnmarshalJSON(data []byte) error {
+	var v struct {
+		SchemaVersion int `json:"schema_version"`
+		plainMaintainer
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	if err := checkSchema("maintainer", v.SchemaVersion); err != nil {
+		return err
+	}
+	*m = Maintainer(v.plainMaintainer)
+	return nil
+}
+
+// checkSchema rejects JSON from a newer SchemaVersion. A missing
+// schema_version, as on a Maintainer nested in a Version, is read as the
+// current one.
+func checkSchema(kind string, version int) error {
+	if version > SchemaVersion {
+		return fmt.Errorf("%s JSON: %w %d, this module reads up to %d", kind, ErrUnsupportedSchema, version, SchemaVersion)
+	}
+	return nil
+}
+
+// readMetadata reads a Metadata map so that it comes back as registries
+// build it: whole numbers that fit are int rather than float64, so counts
+// and sizes compare equal after a round trip. Times are left as the RFC 3339
+// strings they were encoded as.
+func readMetadata(raw json.RawMessage) (map[string]any, error) {
+	if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+		return nil, nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var m map[string]any
+	if err := dec.Decode(&m); err != nil {
+		return nil, err
+	}
+	return normalizeNumbers(m).(map[string]any), nil
+}
+
+func normalizeNumbers(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, e := range v {
+			v[k] = normalizeNumbers(e)
+		}
+		return v
+	case []any:
+		for i, e := range v {
+			v[i] = normalizeNumbers(e)
+		}
+		return v
+	case json.Number:
+		if i, err := v.Int64(); err == nil && i >= math.MinInt && i <= math.MaxInt {
+			return int(i)
+		}
+		f, _ := v.Float64()
+		return f
+	default:
+		return v
+	}
+}
@@ -0,0 +1,92 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJSONRoundTrip(t *testing.T) {
+	published := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	v := Version{
+		Number:      "1.2.0",
+		PublishedAt: published,
+		Status:      StatusYanked,
+		Publisher:   &Maintainer{Login: "alice"},
+		Maintainers: []Maintainer{{Login: "alice"}, {Login: "bob", Role: "owner"}},
+		Metadata: map[string]any{
+			"size":    int(4096),
+			"ratio":   0.5,
+			"engines": map[string]any{"node": ">=18"},
+			"files":   []any{"index.js", int(3)},
+		},
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := string(data)
+	if !strings.HasPrefix(s, `{"schema_version":1,"number":"1.2.0","published_at":"2024-03-01T12:00:00Z"`) {
+		t.Errorf("unexpected encoding: %s", s)
+	}
+	if strings.Count(s, "schema_version") != 1 {
+		t.Errorf("nested maintainers shouldn't repeat schema_version: %s", s)
+	}
+	if strings.Contains(s, "integrity") || strings.Contains(s, "sources") {
+		t.Errorf("empty fields should be omitted: %s", s)
+	}
+
+	var got Version
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, v) {
+		t.Errorf("round trip changed the version:\n got %#v\nwant %#v", got, v)
+	}
+}
+
+func TestJSONTypes(t *testing.T) {
+	pkg := Package{Name: "serde", LatestVersion: "1.0.0", Metadata: map[string]any{"downloads": 12}}
+	data, err := json.Marshal(pkg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"schema_version":1,"name":"serde","latest_version":"1.0.0","metadata":{"downloads":12}}` {
+		t.Errorf("unexpected package encoding: %s", data)
+	}
+	var gotPkg Package
+	if err := json.Unmarshal(data, &gotPkg); err != nil || !reflect.DeepEqual(gotPkg, pkg) {
+		t.Errorf("package round trip = %#v, %v", gotPkg, err)
+	}
+
+	dep := Dependency{Name: "libc", Requirements: "^0.2", Scope: Runtime, Target: "cfg(unix)"}
+	data, err = json.Marshal(dep)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"schema_version":1,"name":"libc","requirements":"^0.2","scope":"runtime","target":"cfg(unix)"}` {
+		t.Errorf("unexpected dependency encoding: %s", data)
+	}
+	var gotDep Dependency
+	if err := json.Unmarshal(data, &gotDep); err != nil || !reflect.DeepEqual(gotDep, dep) {
+		t.Errorf("dependency round trip = %#v, %v", gotDep, err)
+	}
+
+	// schema_version is optional, for hand-written JSON
+	var m Maintainer
+	if err := json.Unmarshal([]byte(`{"login":"alice"}`), &m); err != nil || m.Login != "alice" {
+		t.Errorf("maintainer = %#v, %v", m, err)
+	}
+}
+
+func TestJSONNewerSchema(t *testing.T) {
+	var pkg Package
+	err := json.Unmarshal([]byte(`{"schema_version":2,"name":"serde"}`), &pkg)
+	if !errors.Is(err, ErrUnsupportedSchema) {
+		t.Errorf("expected ErrUnsupportedSchema, got %v", err)
+	}
+}
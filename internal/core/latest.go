@@ -0,0 +1,85 @@
+package core
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/git-pkgs/vers"
+)
+
+var (
+	// PEP 440 pre-releases (a, b, rc and their spellings) and development
+	// releases. Post-releases are stable.
+	pypiPrerelease = regexp.MustCompile(`(?i)[0-9._-](a|b|c|rc|alpha|beta|pre|preview|dev)[0-9._-]*`)
+
+	// Maven qualifiers sorting before a release. "final", "ga", "release"
+	// and "sp" qualifiers are stable.
+	mavenPrerelease = regexp.MustCompile(`(?i)[.-](snapshot|alpha|beta|a|b|m|milestone|rc|cr|ea|preview|dev)(?:[0-9.-]|$)`)
+
+	// Composer's dev, alpha, beta and RC stabilities, which may be attached
+	// without a separator as in "1.0.0RC1". Patch releases are stable.
+	composerPrerelease = regexp.MustCompile(`(?i)(^dev-|-dev$|[0-9._-](alpha|beta|rc|a|b)[0-9.]*$)`)
+)
+
+// IsPrerelease reports whether version is a pre-release under the
+// ecosystem's versioning rules:
+//
+//   - pypi: PEP 440 alpha, beta, release-candidate and development releases
+//   - gem: any letter in the version, as RubyGems decides
+//   - maven: qualifiers such as -SNAPSHOT, -alpha, -M1 and -RC1
+//   - composer: Composer's dev, alpha, beta and RC stabilities
+//   - cpan: developer releases, which have an underscore
+//   - golang: SemVer pre-releases, which include pseudo-versions
+//   - everything else: a SemVer pre-release suffix after "-"
+func IsPrerelease(ecosystem, version string) bool {
+	version = strings.TrimSpace(version)
+	if version == "" {
+		return false
+	}
+	switch ecosystem {
+	case "pypi":
+		return pypiPrerelease.MatchString(version)
+	case "gem":
+		return strings.IndexFunc(version, func(r rune) bool {
+			return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z'
+		}) >= 0
+	case "maven", "clojars":
+		return mavenPrerelease.MatchString(version)
+	case "composer":
+		return composerPrerelease.MatchString(version)
+	case "cpan":
+		return strings.Contains(version, "_")
+	}
+	version, _, _ = strings.Cut(version, "+")
+	return strings.Contains(version, "-")
+}
+
+// CompareVersions orders two versions of an ecosystem, returning -1, 0 or
+// 1. Maven and NuGet use their own ordering rules; other ecosystems are
+// compared as SemVer-like numbers.
+func CompareVersions(ecosystem, a, b string) int {
+	return vers.CompareWithScheme(a, b, ecosystem)
+}
+
+// LatestOf returns the highest of versions, or "" if there are none.
+func LatestOf(ecosystem string, versions []string) string {
+	var latest string
+	for _, v := range versions {
+		if v != "" && (latest == "" || CompareVersions(ecosystem, v, latest) > 0) {
+			latest = v
+		}
+	}
+	return latest
+}
+
+// LatestStableOf returns the highest of versions that isn't a pre-release,
+// or "" if there is none.
+func LatestStableOf(ecosystem string, versions []string) string {
+	var stable []string
+	for _, v := range versions {
+		if !IsPrerelease(ecosystem, v) {
+			stable = append(stable, v)
+		}
+	}
+	return LatestOf(ecosystem, stable)
+}
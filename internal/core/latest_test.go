@@ -0,0 +1,55 @@
+package core
+
+import "testing"
+
+func TestIsPrerelease(t *testing.T) {
+	tests := []struct {
+		ecosystem, version string
+		want               bool
+	}{
+		{"npm", "1.0.0", false},
+		{"npm", "1.0.0-beta.1", true},
+		{"npm", "1.0.0+build.5", false},
+		{"cargo", "0.1.0-alpha", true},
+		{"golang", "v0.0.0-20240101000000-abcdef123456", true},
+		{"pypi", "2.31.0", false},
+		{"pypi", "2.0.0rc1", true},
+		{"pypi", "1.0b2", true},
+		{"pypi", "1.0.dev0", true},
+		{"pypi", "1.0.post1", false},
+		{"gem", "7.1.0", false},
+		{"gem", "7.1.0.beta1", true},
+		{"maven", "2.0.9", false},
+		{"maven", "1.0-SNAPSHOT", true},
+		{"maven", "6.0.0-M1", true},
+		{"maven", "5.3.0.Final", false},
+		{"maven", "31.1-jre", false},
+		{"maven", "4.0.0-android", false},
+		{"composer", "v3.4.1", false},
+		{"composer", "3.4.1-RC2", true},
+		{"composer", "dev-main", true},
+		{"composer", "2.x-dev", true},
+		{"composer", "1.0.0-p1", false},
+		{"cpan", "1.23_01", true},
+		{"nuget", "8.0.0-preview.1", true},
+		{"nuget", "13.0.3", false},
+	}
+	for _, tt := range tests {
+		if got := IsPrerelease(tt.ecosystem, tt.version); got != tt.want {
+			t.Errorf("IsPrerelease(%q, %q) = %v, want %v", tt.ecosystem, tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestLatestStableOf(t *testing.T) {
+	versions := []string{"1.9.0", "2.0.0-rc.1", "1.10.0", "1.2.0"}
+	if got := LatestOf("npm", versions); got != "2.0.0-rc.1" {
+		t.Errorf("LatestOf = %q", got)
+	}
+	if got := LatestStableOf("npm", versions); got != "1.10.0" {
+		t.Errorf("LatestStableOf = %q", got)
+	}
+	if got := LatestStableOf("npm", []string{"1.0.0-alpha"}); got != "" {
+		t.Errorf("expected no stable version, got %q", got)
+	}
+}
@@ -0,0 +1,52 @@
+package core
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// MetadataAs decodes a version's Metadata map into T, a struct whose json
+// tags name the map keys, such as metadata.NpmVersion. It reports false if
+// none of the map's keys belong to T, as when T is another ecosystem's
+// schema, or the values don't fit T's fields.
+func MetadataAs[T any](v Version) (T, bool) {
+	return decodeMetadata[T](v.Metadata)
+}
+
+// PackageMetadataAs decodes a package's Metadata map into T, such as
+// metadata.NpmPackage.
+func PackageMetadataAs[T any](p Package) (T, bool) {
+	return decodeMetadata[T](p.Metadata)
+}
+
+// decodeMetadata round-trips the map through JSON. Metadata values are the
+// registry's own response structs, whose json tags match the public schema
+// types, so this converts them without each client knowing about T.
+func decodeMetadata[T any](m map[string]any) (T, bool) {
+	var t T
+	if !sharesKey(reflect.TypeOf(t), m) {
+		return t, false
+	}
+	b, err := json.Marshal(m)
+	if err != nil {
+		return t, false
+	}
+	if err := json.Unmarshal(b, &t); err != nil {
+		return t, false
+	}
+	return t, true
+}
+
+func sharesKey(typ reflect.Type, m map[string]any) bool {
+	if typ == nil || typ.Kind() != reflect.Struct {
+		return len(m) > 0
+	}
+	for i := 0; i < typ.NumField(); i++ {
+		name, _, _ := strings.Cut(typ.Field(i).Tag.Get("json"), ",")
+		if _, ok := m[name]; ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,69 @@
+package core
+
+import (
+	"context"
+	"fmt"
+)
+
+// Namespace kinds.
+const (
+	NamespaceOrg       = "org"
+	NamespaceUser      = "user"
+	NamespacePublisher = "publisher"
+	NamespacePrefix    = "prefix"
+)
+
+// Namespace is an owner of packages that is more than a string: an npm
+// organization, a NuGet reserved ID prefix, a pub.dev publisher or the
+// GitHub owner of Go modules.
+type Namespace struct {
+	Ecosystem string
+	Name      string
+	// Kind is one of NamespaceOrg, NamespaceUser, NamespacePublisher or
+	// NamespacePrefix.
+	Kind string
+	URL  string
+	// Verified reports whether the registry or host vouches for the owner,
+	// such as a NuGet reserved prefix, a pub.dev publisher's verified
+	// domain or a GitHub organization's verified domain.
+	Verified bool
+	// Members lists the namespace's members where the API makes them
+	// public. An empty list doesn't mean the namespace has none.
+	Members  []Maintainer
+	Metadata map[string]any
+}
+
+// NamespaceFetcher is implemented by registries whose namespaces are real
+// accounts with their own metadata.
+type NamespaceFetcher interface {
+	FetchNamespace(ctx context.Context, namespace string) (*Namespace, error)
+}
+
+// FetchNamespace returns a namespace using reg. It returns an error wrapping
+// ErrNotSupported if the registry's namespaces are plain strings.
+func FetchNamespace(ctx context.Context, reg Registry, namespace string) (*Namespace, error) {
+	nf, ok := reg.(NamespaceFetcher)
+	if !ok {
+		return nil, fmt.Errorf("%s namespace: %w", reg.Ecosystem(), ErrNotSupported)
+	}
+	return nf.FetchNamespace(ctx, namespace)
+}
+
+// NamespaceLister is implemented by registries that can list the packages
+// published by an owner.
+type NamespaceLister interface {
+	ListPackagesByNamespace(ctx context.Context, namespace string) ([]string, error)
+}
+
+// ListPackagesByNamespace returns the sorted names of the packages
+// published under namespace using reg. What a namespace is depends on the
+// registry: an npm scope, a RubyGems owner, a crates.io user or team, or a
+// GitHub owner of Go modules. It returns an error wrapping ErrNotSupported
+// if the registry can't list packages by owner.
+func ListPackagesByNamespace(ctx context.Context, reg Registry, namespace string) ([]string, error) {
+	nl, ok := reg.(NamespaceLister)
+	if !ok {
+		return nil, fmt.Errorf("%s namespace listing: %w", reg.Ecosystem(), ErrNotSupported)
+	}
+	return nl.ListPackagesByNamespace(ctx, namespace)
+}
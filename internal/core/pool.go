@@ -0,0 +1,213 @@
+package core
+
+import (
+	"container/list"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/git-pkgs/purl"
+	"github.com/git-pkgs/registries/client"
+)
+
+// PoolIdleConnsPerHost is how many idle connections the client of a pool
+// created without one keeps open to each registry host.
+const PoolIdleConnsPerHost = 32
+
+// PoolMaxRegistries is how many registries a pool holds by default before
+// evicting the least recently used. See Pool.SetMaxRegistries.
+const PoolMaxRegistries = 1024
+
+// Pool hands out registries, creating one per ecosystem, base URL and
+// credentials on first use and returning the same instance afterwards. All
+// of them share the pool's client, so a long-running service that looks up
+// packages from many goroutines uses one HTTP transport and one set of
+// connections rather than a new client per lookup. A Pool is safe for
+// concurrent use.
+//
+// Base URLs and credentials taken from users, such as repository_url
+// qualifiers, would otherwise grow a pool without bound, so it holds at
+// most PoolMaxRegistries registries and evicts the least recently used.
+// An evicted registry keeps working for callers that still hold it.
+type Pool struct {
+	client *Client
+
+	mu         sync.Mutex
+	max        int
+	registries map[poolKey]*list.Element
+	order      *list.List // of *poolEntry, most recently used first
+
+	hits      atomic.Int64
+	misses    atomic.Int64
+	evictions atomic.Int64
+}
+
+type poolEntry struct {
+	key poolKey
+	reg Registry
+}
+
+type poolKey struct {
+	ecosystem, baseURL string
+	header, value      string
+}
+
+// PoolStats reports a pool's size and how often it reused a registry.
+type PoolStats struct {
+	Registries int   // registries created and held by the pool
+	Hits       int64 // lookups answered with an existing registry
+	Misses     int64 // lookups that created a registry
+	Evictions  int64 // registries dropped to stay within the size limit
+}
+
+// NewPool returns an empty pool whose registries use c. A nil c is replaced
+// with a client that keeps PoolIdleConnsPerHost idle connections to each
+// host, instead of Go's default of two.
+func NewPool(c *Client) *Pool {
+	if c == nil {
+		c = NewClient(client.WithMaxIdleConnsPerHost(PoolIdleConnsPerHost))
+	}
+	return &Pool{
+		client:     c,
+		max:        PoolMaxRegistries,
+		registries: make(map[poolKey]*list.Element),
+		order:      list.New(),
+	}
+}
+
+// SetMaxRegistries changes how many registries the pool holds, evicting
+// the least recently used ones over the new limit. Zero or less means no
+// limit, for pools whose base URLs all come from trusted configuration.
+func (p *Pool) SetMaxRegistries(n int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.max = n
+	p.evictLocked()
+}
+
+// Forget drops every registry the pool holds for ecosystem at baseURL,
+// whatever its credentials, so the next Get creates a new one.
+func (p *Pool) Forget(ecosystem, baseURL string) {
+	key := p.normalize(poolKey{ecosystem: ecosystem, baseURL: baseURL})
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for k, e := range p.registries {
+		if k.ecosystem == key.ecosystem && k.baseURL == key.baseURL {
+			p.order.Remove(e)
+			delete(p.registries, k)
+		}
+	}
+}
+
+func (p *Pool) evictLocked() {
+	for p.max > 0 && p.order.Len() > p.max {
+		oldest := p.order.Back()
+		p.order.Remove(oldest)
+		delete(p.registries, oldest.Value.(*poolEntry).key)
+		p.evictions.Add(1)
+	}
+}
+
+// Client returns the client shared by the pool's registries.
+func (p *Pool) Client() *Client {
+	return p.client
+}
+
+// Get returns the pool's registry for ecosystem at baseURL, creating it
+// with New the first time. An empty baseURL means the default registry and
+// shares its entry with that URL given explicitly.
+func (p *Pool) Get(ecosystem, baseURL string) (Registry, error) {
+	return p.get(poolKey{ecosystem: ecosystem, baseURL: baseURL})
+}
+
+// GetWithAuth is like Get, but the registry sends header with value, such
+// as "Authorization" and a bearer token, on requests to baseURL and the
+// paths below it. Requests to other hosts, such as tarball CDNs or GitHub,
+// don't carry it. Each distinct credential gets its own registry; all of
+// them still share the pool's connections.
+func (p *Pool) GetWithAuth(ecosystem, baseURL, header, value string) (Registry, error) {
+	return p.get(poolKey{ecosystem: ecosystem, baseURL: baseURL, header: header, value: value})
+}
+
+// FromPURL is the pooled form of NewFromPURL. It returns the registry for
+// the PURL's type and repository_url, with any other qualifiers applied,
+// along with the package name and version.
+func (p *Pool) FromPURL(purlStr string) (Registry, string, string, error) {
+	pu, err := purl.Parse(purlStr)
+	if err != nil {
+		return nil, "", "", err
+	}
+	if err := ValidateName(pu.Type, pu.FullName()); err != nil {
+		return nil, "", "", err
+	}
+
+	reg, err := p.Get(pu.Type, registryURL(pu))
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	if q, ok := reg.(QualifiedRegistry); ok {
+		qualifiers := pu.Qualifiers.Map()
+		delete(qualifiers, "repository_url")
+		if len(qualifiers) > 0 {
+			reg = q.WithQualifiers(qualifiers)
+		}
+	}
+
+	return reg, pu.FullName(), pu.Version, nil
+}
+
+// Stats returns the pool's current usage counts.
+func (p *Pool) Stats() PoolStats {
+	p.mu.Lock()
+	n := len(p.registries)
+	p.mu.Unlock()
+	return PoolStats{Registries: n, Hits: p.hits.Load(), Misses: p.misses.Load(), Evictions: p.evictions.Load()}
+}
+
+// normalize resolves the default base URL and canonical ecosystem name.
+func (p *Pool) normalize(key poolKey) poolKey {
+	key.ecosystem = CanonicalEcosystem(key.ecosystem)
+	if key.baseURL == "" {
+		mu.RLock()
+		key.baseURL = defaults[key.ecosystem]
+		mu.RUnlock()
+	}
+	key.baseURL = strings.TrimSuffix(key.baseURL, "/")
+	return key
+}
+
+func (p *Pool) get(key poolKey) (Registry, error) {
+	key = p.normalize(key)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if e, ok := p.registries[key]; ok {
+		p.hits.Add(1)
+		p.order.MoveToFront(e)
+		return e.Value.(*poolEntry).reg, nil
+	}
+
+	c := p.client
+	if key.header != "" {
+		header, value, baseURL := key.header, key.value, key.baseURL
+		fallback := c.AuthFunc
+		c = c.WithAuthFunc(func(url string) (string, string) {
+			if url == baseURL || strings.HasPrefix(url, baseURL+"/") {
+				return header, value
+			}
+			if fallback != nil {
+				return fallback(url)
+			}
+			return "", ""
+		})
+	}
+	reg, err := New(key.ecosystem, key.baseURL, c)
+	if err != nil {
+		return nil, err
+	}
+	p.misses.Add(1)
+	p.registries[key] = p.order.PushFront(&poolEntry{key: key, reg: reg})
+	p.evictLocked()
+	return reg, nil
+}
@@ -0,0 +1,171 @@
+package core
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// pooled records how the pool constructed it.
+type pooled struct {
+	packageOnly
+	baseURL string
+	client  *Client
+}
+
+func TestPool(t *testing.T) {
+	built := 0
+	Register("pooltest", "https://pool.example.com", func(baseURL string, client *Client) Registry {
+		built++
+		return &pooled{baseURL: baseURL, client: client}
+	})
+
+	pool := NewPool(nil)
+	if _, ok := pool.Client().HTTPClient.Transport.(*http.Transport); !ok {
+		t.Fatalf("default pool client has no tuned transport")
+	}
+
+	first, err := pool.Get("pooltest", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The default URL, given explicitly or with a trailing slash, is the
+	// same registry.
+	for _, url := range []string{"https://pool.example.com", "https://pool.example.com/"} {
+		if reg, _ := pool.Get("pooltest", url); reg != first {
+			t.Errorf("Get(%q) built a second registry", url)
+		}
+	}
+
+	mirror, err := pool.Get("pooltest", "https://mirror.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	authed, err := pool.GetWithAuth("pooltest", "", "Authorization", "Bearer secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mirror == first || authed == first {
+		t.Fatal("distinct URLs and credentials share a registry")
+	}
+
+	a := authed.(*pooled).client
+	if header, value := a.AuthFunc("https://pool.example.com/x"); header != "Authorization" || value != "Bearer secret" {
+		t.Errorf("auth = %q: %q", header, value)
+	}
+	// The credential stays with the registry's host
+	for _, url := range []string{"https://cdn.example.com/x.tgz", "https://pool.example.com.evil.com/x"} {
+		if header, value := a.AuthFunc(url); header != "" || value != "" {
+			t.Errorf("auth for %s = %q: %q", url, header, value)
+		}
+	}
+	if a.HTTPClient != first.(*pooled).client.HTTPClient || mirror.(*pooled).client != pool.Client() {
+		t.Error("registries don't share the pool's HTTP client")
+	}
+
+	if reg, name, version, err := pool.FromPURL("pkg:pooltest/widgets@1.0.0"); err != nil || reg != first || name != "widgets" || version != "1.0.0" {
+		t.Errorf("FromPURL = %v, %q, %q, %v", reg, name, version, err)
+	}
+
+	if _, err := pool.Get("nosuchecosystem", ""); err == nil {
+		t.Error("expected an error for an unknown ecosystem")
+	}
+
+	want := PoolStats{Registries: 3, Hits: 3, Misses: 3}
+	if got := pool.Stats(); got != want {
+		t.Errorf("Stats = %+v, want %+v", got, want)
+	}
+	if built != 3 {
+		t.Errorf("built %d registries, want 3", built)
+	}
+}
+
+func TestPoolAuthScopedToBaseURL(t *testing.T) {
+	Register("pooltest-auth", "https://pool.example.com", func(baseURL string, client *Client) Registry {
+		return &pooled{baseURL: baseURL, client: client}
+	})
+	var seen sync.Map
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen.Store(r.Host, r.Header.Get("Authorization"))
+	})
+	registry := httptest.NewServer(handler)
+	defer registry.Close()
+	cdn := httptest.NewServer(handler)
+	defer cdn.Close()
+
+	reg, err := NewPool(nil).GetWithAuth("pooltest-auth", registry.URL, "Authorization", "Bearer secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := reg.(*pooled).client
+	_, _ = c.GetBody(context.Background(), registry.URL+"/widgets")
+	_, _ = c.GetBody(context.Background(), cdn.URL+"/widgets.tgz")
+
+	if got, _ := seen.Load(registry.Listener.Addr().String()); got != "Bearer secret" {
+		t.Errorf("registry got Authorization %q", got)
+	}
+	if got, _ := seen.Load(cdn.Listener.Addr().String()); got != "" {
+		t.Errorf("another host got Authorization %q", got)
+	}
+}
+
+func TestPoolEviction(t *testing.T) {
+	Register("pooltest-evict", "https://pool.example.com", func(baseURL string, client *Client) Registry {
+		return &pooled{baseURL: baseURL, client: client}
+	})
+	pool := NewPool(nil)
+	pool.SetMaxRegistries(2)
+
+	a, _ := pool.Get("pooltest-evict", "https://a.example.com")
+	_, _ = pool.Get("pooltest-evict", "https://b.example.com")
+	// Using a makes b the least recently used
+	if reg, _ := pool.Get("pooltest-evict", "https://a.example.com"); reg != a {
+		t.Fatal("a was rebuilt before the pool was full")
+	}
+	_, _ = pool.Get("pooltest-evict", "https://c.example.com")
+
+	if stats := pool.Stats(); stats.Registries != 2 || stats.Evictions != 1 {
+		t.Errorf("Stats = %+v, want 2 registries and 1 eviction", stats)
+	}
+	if reg, _ := pool.Get("pooltest-evict", "https://a.example.com"); reg != a {
+		t.Error("the most recently used registry was evicted")
+	}
+
+	_, _ = pool.GetWithAuth("pooltest-evict", "https://a.example.com/", "Authorization", "Bearer secret")
+	pool.Forget("pooltest-evict", "https://a.example.com/")
+	if reg, _ := pool.Get("pooltest-evict", "https://a.example.com"); reg == a {
+		t.Error("Forget kept the registry")
+	}
+	if stats := pool.Stats(); stats.Registries != 1 {
+		t.Errorf("Forget left %d registries, want 1", stats.Registries)
+	}
+}
+
+func TestPoolConcurrent(t *testing.T) {
+	Register("pooltest-concurrent", "https://pool.example.com", func(baseURL string, client *Client) Registry {
+		return &pooled{baseURL: baseURL, client: client}
+	})
+	pool := NewPool(nil)
+
+	var wg sync.WaitGroup
+	regs := make([]Registry, 50)
+	for i := range regs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			regs[i], _ = pool.Get("pooltest-concurrent", "")
+		}()
+	}
+	wg.Wait()
+
+	for _, reg := range regs {
+		if reg != regs[0] {
+			t.Fatal("concurrent lookups built more than one registry")
+		}
+	}
+	if stats := pool.Stats(); stats.Registries != 1 || stats.Misses != 1 || stats.Hits != 49 {
+		t.Errorf("Stats = %+v", stats)
+	}
+}
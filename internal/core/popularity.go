@@ -0,0 +1,21 @@
+package core
+
+// Popularity is how widely a package is used, by the counts its registry
+// publishes, in the same fields whatever the ecosystem. A zero count means
+// the registry doesn't report it, not that nobody uses the package.
+type Popularity struct {
+	// DownloadsTotal counts downloads of every version since the package
+	// was published.
+	DownloadsTotal int64 `json:"downloads_total,omitempty"`
+
+	// DownloadsRecent counts downloads over the last RecentDays days, the
+	// window the registry reports: 90 for crates.io and Hex, 30 for pub.dev
+	// and Packagist.
+	DownloadsRecent int64 `json:"downloads_recent,omitempty"`
+	RecentDays      int   `json:"recent_days,omitempty"`
+
+	// Stars counts the users who marked the package as a favourite: pub.dev
+	// likes, Packagist favers, or GitHub stars where the registry mirrors
+	// them, as VimAwesome, deno.land/x and GitHub releases do.
+	Stars int64 `json:"stars,omitempty"`
+}
@@ -0,0 +1,51 @@
+package core
+
+import (
+	"context"
+	"fmt"
+)
+
+// Publisher kinds of trusted publishing, the identity of the CI workflow
+// that uploaded a file in place of a maintainer's token.
+const (
+	PublisherGitHub      = "GitHub"
+	PublisherGitLab      = "GitLab"
+	PublisherGoogle      = "Google"
+	PublisherActiveState = "ActiveState"
+)
+
+// Provenance is an attestation of where a published file came from: the
+// trusted publisher that uploaded it and the source repository and
+// workflow it was built by. Files uploaded with a maintainer's API token
+// have none, so having provenance is itself a trust signal.
+type Provenance struct {
+	File string `json:"file"` // the attested file, e.g. "requests-2.32.0-py3-none-any.whl"
+
+	// Publisher is the kind of trusted publisher, such as PublisherGitHub.
+	Publisher   string `json:"publisher"`
+	Repository  string `json:"repository,omitempty"`  // as the publisher names it, e.g. "psf/requests"
+	Workflow    string `json:"workflow,omitempty"`    // CI workflow file, e.g. "publish.yml"
+	Environment string `json:"environment,omitempty"` // deployment environment the workflow ran in
+
+	URL      string         `json:"url,omitempty"`      // the attestation document
+	Metadata map[string]any `json:"metadata,omitempty"` // the publisher's other claims
+}
+
+// ProvenanceFetcher is implemented by registries that publish attestations
+// for the files of a version.
+type ProvenanceFetcher interface {
+	// FetchProvenance returns the provenance of each attested file of a
+	// version. A version without attestations returns none and no error.
+	FetchProvenance(ctx context.Context, name, version string) ([]Provenance, error)
+}
+
+// FetchProvenance returns the provenance of a version's files using reg. It
+// returns an error wrapping ErrNotSupported if the registry doesn't publish
+// attestations.
+func FetchProvenance(ctx context.Context, reg Registry, name, version string) ([]Provenance, error) {
+	pf, ok := reg.(ProvenanceFetcher)
+	if !ok {
+		return nil, fmt.Errorf("%s provenance: %w", reg.Ecosystem(), ErrNotSupported)
+	}
+	return pf.FetchProvenance(ctx, name, version)
+}
@@ -0,0 +1,64 @@
+package core
+
+import (
+	"context"
+	"fmt"
+)
+
+// QualitySignals are indicators of how stable and relied upon a package
+// version is, drawn from services beside the registry such as test farms
+// and dependency graphs.
+type QualitySignals struct {
+	Version string
+
+	// Tests summarises third-party test reports for the version, nil if
+	// the ecosystem has none.
+	Tests *TestSummary
+
+	// DirectDependents and TotalDependents count the packages that depend
+	// on this one directly and transitively. Both are -1 when unknown.
+	DirectDependents int
+	TotalDependents  int
+
+	Metadata map[string]any
+}
+
+// TestSummary counts test reports by outcome. Matrix breaks them down by
+// platform and runtime version where the source provides it.
+type TestSummary struct {
+	Pass    int
+	Fail    int
+	NA      int // the tests declared the platform unsupported
+	Unknown int // the tests couldn't be run or reported nothing
+	Matrix  []TestCell
+}
+
+// TestCell is the report counts for one platform and runtime version, such
+// as linux and perl 5.38.0.
+type TestCell struct {
+	Platform string
+	Runtime  string
+	Pass     int
+	Fail     int
+	NA       int
+	Unknown  int
+}
+
+// QualityFetcher is implemented by registries with quality signals for
+// package versions.
+type QualityFetcher interface {
+	// FetchQualitySignals returns the signals for a version, or for the
+	// latest version if version is empty.
+	FetchQualitySignals(ctx context.Context, name, version string) (*QualitySignals, error)
+}
+
+// FetchQualitySignals returns the quality signals of a package version using
+// reg. It returns an error wrapping ErrNotSupported if the registry has
+// none.
+func FetchQualitySignals(ctx context.Context, reg Registry, name, version string) (*QualitySignals, error) {
+	qf, ok := reg.(QualityFetcher)
+	if !ok {
+		return nil, fmt.Errorf("%s quality signals: %w", reg.Ecosystem(), ErrNotSupported)
+	}
+	return qf.FetchQualitySignals(ctx, name, version)
+}
@@ -7,6 +7,8 @@ import (
 )
 
 // Registry is the interface implemented by all ecosystem registry clients.
+// Versions, dependencies and maintainers are optional capabilities, see
+// VersionFetcher, DependencyFetcher and MaintainerFetcher.
 type Registry interface {
 	// Ecosystem returns the PURL type for this registry (e.g., "cargo", "npm", "gem").
 	Ecosystem() string
@@ -14,17 +16,71 @@ type Registry interface {
 	// FetchPackage retrieves package metadata.
 	FetchPackage(ctx context.Context, name string) (*Package, error)
 
+	// URLs returns the URL builder for this registry.
+	URLs() URLBuilder
+}
+
+// VersionFetcher is implemented by registries that list a package's
+// versions.
+type VersionFetcher interface {
 	// FetchVersions retrieves all versions of a package.
 	FetchVersions(ctx context.Context, name string) ([]Version, error)
+}
 
+// DependencyFetcher is implemented by registries that record what each
+// version depends on.
+type DependencyFetcher interface {
 	// FetchDependencies retrieves dependencies for a specific version.
 	FetchDependencies(ctx context.Context, name, version string) ([]Dependency, error)
+}
 
+// MaintainerFetcher is implemented by registries that say who maintains a
+// package.
+type MaintainerFetcher interface {
 	// FetchMaintainers retrieves maintainer information.
 	FetchMaintainers(ctx context.Context, name string) ([]Maintainer, error)
+}
 
-	// URLs returns the URL builder for this registry.
-	URLs() URLBuilder
+// FetchVersions lists the versions of a package using reg, or returns an
+// error wrapping ErrNotSupported if reg doesn't implement VersionFetcher.
+func FetchVersions(ctx context.Context, reg Registry, name string) ([]Version, error) {
+	vf, ok := reg.(VersionFetcher)
+	if !ok {
+		return nil, fmt.Errorf("%s versions: %w", reg.Ecosystem(), ErrNotSupported)
+	}
+	return vf.FetchVersions(ctx, name)
+}
+
+// FetchDependencies returns the dependencies of a version using reg, or an
+// error wrapping ErrNotSupported if reg doesn't implement
+// DependencyFetcher.
+func FetchDependencies(ctx context.Context, reg Registry, name, version string) ([]Dependency, error) {
+	df, ok := reg.(DependencyFetcher)
+	if !ok {
+		return nil, fmt.Errorf("%s dependencies: %w", reg.Ecosystem(), ErrNotSupported)
+	}
+	return df.FetchDependencies(ctx, name, version)
+}
+
+// FetchMaintainers returns the maintainers of a package using reg, or an
+// error wrapping ErrNotSupported if reg doesn't implement
+// MaintainerFetcher.
+func FetchMaintainers(ctx context.Context, reg Registry, name string) ([]Maintainer, error) {
+	mf, ok := reg.(MaintainerFetcher)
+	if !ok {
+		return nil, fmt.Errorf("%s maintainers: %w", reg.Ecosystem(), ErrNotSupported)
+	}
+	return mf.FetchMaintainers(ctx, name)
+}
+
+// QualifiedRegistry is implemented by registries whose packages are further
+// identified by PURL qualifiers, such as a conda channel or a Maven
+// classifier. NewFromPURL passes a PURL's qualifiers to WithQualifiers, and
+// the returned registry includes them in the PURLs its URLBuilder generates.
+// Unrecognised qualifiers are ignored.
+type QualifiedRegistry interface {
+	Registry
+	WithQualifiers(qualifiers map[string]string) Registry
 }
 
 // Factory creates a registry instance for a given base URL.
@@ -46,20 +102,26 @@ func Register(ecosystem string, defaultURL string, factory Factory) {
 	defaults[ecosystem] = defaultURL
 }
 
-// New creates a new registry for the given ecosystem.
-// If baseURL is empty, the default registry URL is used.
+// New creates a new registry for the given ecosystem, which may be an alias
+// such as "rubygems" (see CanonicalEcosystem).
+// If baseURL is empty, the default registry URL is used. A baseURL that
+// fails ValidateURL returns an error wrapping ErrInvalidURL, and an
+// ecosystem that isn't registered an *UnknownEcosystemError.
 func New(ecosystem string, baseURL string, client *Client) (Registry, error) {
+	ecosystem = CanonicalEcosystem(ecosystem)
 	mu.RLock()
 	factory, ok := factories[ecosystem]
 	defaultURL := defaults[ecosystem]
 	mu.RUnlock()
 
 	if !ok {
-		return nil, fmt.Errorf("unknown ecosystem: %s", ecosystem)
+		return nil, unknownEcosystem(ecosystem)
 	}
 
 	if baseURL == "" {
 		baseURL = defaultURL
+	} else if err := ValidateURL(ecosystem, baseURL); err != nil {
+		return nil, err
 	}
 
 	if client == nil {
@@ -69,6 +131,24 @@ func New(ecosystem string, baseURL string, client *Client) (Registry, error) {
 	return factory(baseURL, client), nil
 }
 
+// Construct builds a registry with newRegistry the way New would, without
+// looking up a registered factory. A non-empty baseURL must pass
+// ValidateURL, and a nil client is replaced with DefaultClient. It backs
+// the New functions of the public per-ecosystem packages, such as
+// registries/npm.
+func Construct[R Registry](ecosystem, baseURL string, client *Client, newRegistry func(string, *Client) R) (R, error) {
+	if baseURL != "" {
+		if err := ValidateURL(ecosystem, baseURL); err != nil {
+			var zero R
+			return zero, err
+		}
+	}
+	if client == nil {
+		client = DefaultClient()
+	}
+	return newRegistry(baseURL, client), nil
+}
+
 // SupportedEcosystems returns all registered ecosystem types.
 func SupportedEcosystems() []string {
 	mu.RLock()
@@ -81,9 +161,9 @@ func SupportedEcosystems() []string {
 	return ecosystems
 }
 
-// DefaultURL returns the default registry URL for an ecosystem.
+// DefaultURL returns the default registry URL for an ecosystem or alias.
 func DefaultURL(ecosystem string) string {
 	mu.RLock()
 	defer mu.RUnlock()
-	return defaults[ecosystem]
+	return defaults[CanonicalEcosystem(ecosystem)]
 }
@@ -0,0 +1,45 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// packageOnly implements Registry without any optional capability.
+type packageOnly struct{}
+
+func (packageOnly) Ecosystem() string { return "example" }
+func (packageOnly) FetchPackage(ctx context.Context, name string) (*Package, error) {
+	return &Package{Name: name}, nil
+}
+func (packageOnly) URLs() URLBuilder { return nil }
+
+// withVersions adds VersionFetcher.
+type withVersions struct{ packageOnly }
+
+func (withVersions) FetchVersions(ctx context.Context, name string) ([]Version, error) {
+	return []Version{{Number: "1.0.0"}}, nil
+}
+
+func TestOptionalCapabilities(t *testing.T) {
+	ctx := context.Background()
+
+	if _, err := FetchVersions(ctx, packageOnly{}, "x"); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("FetchVersions: expected ErrNotSupported, got %v", err)
+	}
+	if _, err := FetchDependencies(ctx, packageOnly{}, "x", "1.0.0"); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("FetchDependencies: expected ErrNotSupported, got %v", err)
+	}
+	if _, err := FetchMaintainers(ctx, packageOnly{}, "x"); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("FetchMaintainers: expected ErrNotSupported, got %v", err)
+	}
+
+	versions, err := FetchVersions(ctx, withVersions{}, "x")
+	if err != nil || len(versions) != 1 {
+		t.Errorf("FetchVersions = %v, %v", versions, err)
+	}
+	if _, err := FetchLatestVersion(ctx, packageOnly{}, "x"); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("FetchLatestVersion: expected ErrNotSupported, got %v", err)
+	}
+}
@@ -0,0 +1,16 @@
+package core
+
+// SizeChange returns how many times larger next is than prev, such as 10
+// for a release that grew tenfold or 0.5 for one that halved. Unpacked
+// sizes are compared when both versions have one, since they don't depend
+// on how well the contents compress, and download sizes otherwise. It
+// returns 0 if the versions have no size in common.
+func SizeChange(prev, next Version) float64 {
+	if prev.UnpackedSize > 0 && next.UnpackedSize > 0 {
+		return float64(next.UnpackedSize) / float64(prev.UnpackedSize)
+	}
+	if prev.Size > 0 && next.Size > 0 {
+		return float64(next.Size) / float64(prev.Size)
+	}
+	return 0
+}
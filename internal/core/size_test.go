@@ -0,0 +1,20 @@
+package core
+
+import "testing"
+
+func TestSizeChange(t *testing.T) {
+	tests := []struct {
+		name       string
+		prev, next Version
+		want       float64
+	}{
+		{"unpacked", Version{Size: 100, UnpackedSize: 1000}, Version{Size: 150, UnpackedSize: 10000}, 10},
+		{"download", Version{Size: 200}, Version{Size: 100, UnpackedSize: 500}, 0.5},
+		{"unknown", Version{UnpackedSize: 1000}, Version{Size: 100}, 0},
+	}
+	for _, tt := range tests {
+		if got := SizeChange(tt.prev, tt.next); got != tt.want {
+			t.Errorf("%s: SizeChange = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
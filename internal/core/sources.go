@@ -0,0 +1,36 @@
+package core
+
+import (
+	"context"
+)
+
+// FetchPackageWithSources fetches a package as reg.FetchPackage does and
+// records in its Sources every response it was built from, with when each
+// was fetched. Registries that take fields from other responses than the
+// main document, such as Maven licenses inherited from a parent POM, say
+// which in Source.Fields.
+func FetchPackageWithSources(ctx context.Context, reg Registry, name string) (*Package, error) {
+	ctx, log := RecordSources(ctx)
+	pkg, err := reg.FetchPackage(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	pkg.Sources = log.Sources()
+	return pkg, nil
+}
+
+// FetchVersionsWithSources lists versions as FetchVersions does and records
+// the responses they were built from in each version's Sources. Registries
+// usually list every version from the same responses, so the versions
+// share one set of sources.
+func FetchVersionsWithSources(ctx context.Context, reg Registry, name string) ([]Version, error) {
+	ctx, log := RecordSources(ctx)
+	versions, err := FetchVersions(ctx, reg, name)
+	if err != nil {
+		return nil, err
+	}
+	for i := range versions {
+		versions[i].Sources = log.Sources()
+	}
+	return versions, nil
+}
@@ -0,0 +1,190 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// PackageState is the package-level status of a package, as opposed to the
+// status of individual versions.
+type PackageState string
+
+const (
+	// PackageActive has at least one version that is not yanked, deprecated
+	// or retracted.
+	PackageActive PackageState = "active"
+	// PackageDeprecated has every version deprecated, or is marked abandoned.
+	PackageDeprecated PackageState = "deprecated"
+	// PackageYanked has every version yanked or retracted.
+	PackageYanked PackageState = "yanked"
+	// PackageRemoved was unpublished or deleted, or lists no versions.
+	PackageRemoved PackageState = "removed"
+)
+
+// PackageStatus summarises whether a package is still maintained.
+type PackageStatus struct {
+	State         PackageState
+	Message       string // deprecation, yank or removal message, if the registry gives one
+	Replacement   string // package the registry suggests instead, if any
+	LatestVersion string // newest version with no status, empty unless State is PackageActive
+	Repository    string
+	Archived      bool // the source repository is archived; only set by FetchStatusFromPURL
+	Metadata      map[string]any
+}
+
+// StatusFetcher is implemented by registries with package-level status that
+// can't be derived from their versions, such as npm unpublishes.
+type StatusFetcher interface {
+	FetchStatus(ctx context.Context, name string) (*PackageStatus, error)
+}
+
+// FetchStatus returns the package-level status of a package. Registries that
+// implement StatusFetcher answer directly; for the rest the status is derived
+// from the statuses of all versions.
+func FetchStatus(ctx context.Context, reg Registry, name string) (*PackageStatus, error) {
+	if sf, ok := reg.(StatusFetcher); ok {
+		return sf.FetchStatus(ctx, name)
+	}
+
+	versions, err := FetchVersions(ctx, reg, name)
+	if err != nil {
+		return nil, err
+	}
+	status := StatusFromVersions(versions)
+
+	if pkg, err := reg.FetchPackage(ctx, name); err == nil {
+		status.Repository = pkg.Repository
+	}
+	return status, nil
+}
+
+// FetchKnownStatus is FetchStatus for a package the caller has seen on the
+// registry before, such as one pinned in a lockfile. Registries such as
+// PyPI answer 404 for deleted packages, which FetchStatus can't tell apart
+// from a mistyped name; here a package the registry no longer has is
+// PackageRemoved.
+func FetchKnownStatus(ctx context.Context, reg Registry, name string) (*PackageStatus, error) {
+	status, err := FetchStatus(ctx, reg, name)
+	if errors.Is(err, ErrNotFound) {
+		return &PackageStatus{State: PackageRemoved}, nil
+	}
+	return status, err
+}
+
+// StatusFromVersions derives a package status from its versions, assumed to
+// be ordered newest first as FetchVersions returns them.
+func StatusFromVersions(versions []Version) *PackageStatus {
+	if len(versions) == 0 {
+		return &PackageStatus{State: PackageRemoved}
+	}
+
+	deprecated := false
+	for _, v := range versions {
+		switch v.Status {
+		case StatusNone:
+			return &PackageStatus{State: PackageActive, LatestVersion: latestActive(versions)}
+		case StatusDeprecated:
+			deprecated = true
+		}
+	}
+
+	status := &PackageStatus{State: PackageYanked, Message: statusMessage(versions[0])}
+	if deprecated {
+		status.State = PackageDeprecated
+	}
+	return status
+}
+
+// latestActive returns the newest version with no status. Versions are not
+// always ordered, so the publish date decides.
+func latestActive(versions []Version) string {
+	var latest *Version
+	for i := range versions {
+		v := &versions[i]
+		if v.Status != StatusNone {
+			continue
+		}
+		if latest == nil || v.PublishedAt.After(latest.PublishedAt) {
+			latest = v
+		}
+	}
+	if latest == nil {
+		return ""
+	}
+	return latest.Number
+}
+
+// statusMessage finds a deprecation or yank message in version metadata.
+func statusMessage(v Version) string {
+	for _, key := range []string{"deprecated", "deprecation_reason", "yank_message", "yanked_reason"} {
+		if s, ok := v.Metadata[key].(string); ok && s != "" {
+			return s
+		}
+	}
+	return ""
+}
+
+// FetchStatusFromPURL returns the package-level status for a PURL and probes
+// the source repository to report whether it is archived. Probe failures are
+// ignored; GitHub allows 60 unauthenticated requests an hour, so configure
+// an AuthFunc for api.github.com when checking many packages.
+func FetchStatusFromPURL(ctx context.Context, purlStr string, client *Client) (*PackageStatus, error) {
+	reg, name, _, err := NewFromPURL(purlStr, client)
+	if err != nil {
+		return nil, err
+	}
+	status, err := FetchStatus(ctx, reg, name)
+	if err != nil {
+		return nil, err
+	}
+	if status.Repository != "" {
+		if client == nil {
+			client = DefaultClient()
+		}
+		status.Archived, _ = RepositoryArchived(ctx, client, status.Repository)
+	}
+	return status, nil
+}
+
+// errUnknownHost is returned for repositories on hosts with no known API.
+var errUnknownHost = errors.New("repository host not supported")
+
+// RepositoryArchived reports whether a repository on GitHub, GitLab or a
+// Gitea/Forgejo host such as Codeberg is archived.
+func RepositoryArchived(ctx context.Context, client *Client, repoURL string) (bool, error) {
+	u, err := url.Parse(repoURL)
+	if err != nil {
+		return false, err
+	}
+	path := strings.TrimSuffix(strings.Trim(u.Path, "/"), ".git")
+	if strings.Count(path, "/") < 1 {
+		return false, fmt.Errorf("%s: no owner/repo in URL", repoURL)
+	}
+
+	// GitLab allows nested groups; elsewhere anything after owner/repo is a
+	// path inside the repository
+	ownerRepo := strings.Join(strings.SplitN(path, "/", 3)[:2], "/")
+
+	var apiURL string
+	switch host := strings.ToLower(u.Host); {
+	case host == "github.com":
+		apiURL = "https://api.github.com/repos/" + ownerRepo
+	case host == "gitlab.com":
+		apiURL = "https://gitlab.com/api/v4/projects/" + url.PathEscape(path)
+	case host == "codeberg.org" || strings.HasPrefix(host, "gitea."):
+		apiURL = "https://" + host + "/api/v1/repos/" + ownerRepo
+	default:
+		return false, fmt.Errorf("%s: %w", repoURL, errUnknownHost)
+	}
+
+	var resp struct {
+		Archived bool `json:"archived"`
+	}
+	if err := client.GetJSON(ctx, apiURL, &resp); err != nil {
+		return false, err
+	}
+	return resp.Archived, nil
+}
@@ -0,0 +1,110 @@
+package core
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestStatusFromVersions(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name     string
+		versions []Version
+		state    PackageState
+		latest   string
+		message  string
+	}{
+		{"empty", nil, PackageRemoved, "", ""},
+		{"active", []Version{
+			{Number: "2.0.0", PublishedAt: day(3), Status: StatusYanked},
+			{Number: "1.1.0", PublishedAt: day(2)},
+			{Number: "1.0.0", PublishedAt: day(1)},
+		}, PackageActive, "1.1.0", ""},
+		{"all yanked", []Version{
+			{Number: "1.0.0", Status: StatusYanked, Metadata: map[string]any{"yank_message": "security"}},
+			{Number: "0.9.0", Status: StatusRetracted},
+		}, PackageYanked, "", "security"},
+		{"deprecated", []Version{
+			{Number: "1.0.0", Status: StatusDeprecated, Metadata: map[string]any{"deprecated": "use other"}},
+			{Number: "0.9.0", Status: StatusYanked},
+		}, PackageDeprecated, "", "use other"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := StatusFromVersions(tt.versions)
+			if got.State != tt.state || got.LatestVersion != tt.latest || got.Message != tt.message {
+				t.Errorf("got %+v, want state %s latest %q message %q", got, tt.state, tt.latest, tt.message)
+			}
+		})
+	}
+}
+
+// rewriteTransport sends every request to a test server, keeping the path
+// and passing the original host in the Host header.
+type rewriteTransport struct {
+	target *url.URL
+	paths  []string
+}
+
+func (rt *rewriteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	rt.paths = append(rt.paths, req.URL.Host+req.URL.EscapedPath())
+	req.Host = req.URL.Host
+	req.URL.Scheme = rt.target.Scheme
+	req.URL.Host = rt.target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestRepositoryArchived(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.EscapedPath() {
+		case "/repos/request/request", "/api/v4/projects/group%2Fsub%2Fproject":
+			_, _ = w.Write([]byte(`{"archived": true}`))
+		default:
+			_, _ = w.Write([]byte(`{"archived": false}`))
+		}
+	}))
+	defer server.Close()
+
+	target, _ := url.Parse(server.URL)
+	rt := &rewriteTransport{target: target}
+	c := DefaultClient()
+	c.HTTPClient = &http.Client{Transport: rt}
+	ctx := context.Background()
+
+	tests := map[string]bool{
+		"https://github.com/request/request":             true,
+		"https://github.com/request/request/tree/master": true,
+		"https://gitlab.com/group/sub/project":           true,
+		"https://codeberg.org/forgejo/forgejo.git":       false,
+	}
+	for repo, want := range tests {
+		got, err := RepositoryArchived(ctx, c, repo)
+		if err != nil {
+			t.Errorf("RepositoryArchived(%s) error: %v", repo, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("RepositoryArchived(%s) = %v, want %v", repo, got, want)
+		}
+	}
+
+	wantPaths := map[string]bool{
+		"api.github.com/repos/request/request":             true,
+		"gitlab.com/api/v4/projects/group%2Fsub%2Fproject": true,
+		"codeberg.org/api/v1/repos/forgejo/forgejo":        true,
+	}
+	for _, p := range rt.paths {
+		if !wantPaths[p] {
+			t.Errorf("unexpected API request %s", p)
+		}
+	}
+
+	if _, err := RepositoryArchived(ctx, c, "https://example.com/owner/repo"); err == nil {
+		t.Error("expected error for unknown host")
+	}
+}
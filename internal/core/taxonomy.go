@@ -0,0 +1,213 @@
+package core
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// taxonomy maps normalised category terms from every ecosystem's scheme to
+// shared category slugs. Keys are lowercase letters and digits only, so
+// "command-line-utilities", "Command Line" and "CommandLine" all meet.
+var taxonomy = map[string][]string{
+	// Web and networking
+	"web":                 {"web"},
+	"webprogramming":      {"web"},
+	"webtechnologies":     {"web"},
+	"webframeworks":       {"web"},
+	"wwwhttp":             {"web", "http"},
+	"http":                {"http"},
+	"httpclient":          {"http"},
+	"httpserver":          {"http", "web"},
+	"wasm":                {"web"},
+	"network":             {"networking"},
+	"networking":          {"networking"},
+	"networkprogramming":  {"networking"},
+	"internet":            {"networking"},
+	"email":               {"email"},
+	"communicationsemail": {"email"},
+	"webassembly":         {"web"},
+
+	// Interfaces
+	"cli":                  {"cli"},
+	"console":              {"cli"},
+	"commandline":          {"cli"},
+	"commandlineutilities": {"cli"},
+	"commandlineinterface": {"cli"},
+	"terminals":            {"cli"},
+	"gui":                  {"gui"},
+	"userinterfaces":       {"gui"},
+	"desktopenvironment":   {"gui"},
+
+	// Data
+	"database":                  {"database"},
+	"databases":                 {"database"},
+	"databaseimplementations":   {"database"},
+	"databaseengineservers":     {"database"},
+	"encoding":                  {"serialization"},
+	"serialization":             {"serialization"},
+	"json":                      {"serialization"},
+	"parsing":                   {"parsing"},
+	"parser":                    {"parsing"},
+	"parserimplementations":     {"parsing"},
+	"text":                      {"text-processing"},
+	"textprocessing":            {"text-processing"},
+	"valueformatting":           {"text-processing"},
+	"naturallanguageprocessing": {"text-processing", "machine-learning"},
+	"compression":               {"compression"},
+	"codec":                     {"compression"},
+	"archiving":                 {"compression"},
+	"dateandtime":               {"date-time"},
+	"time":                      {"date-time"},
+	"datetime":                  {"date-time"},
+	"internationalization":      {"internationalization"},
+	"localization":              {"internationalization"},
+	"i18n":                      {"internationalization"},
+	"templateengine":            {"templating"},
+	"templating":                {"templating"},
+	"template":                  {"templating"},
+
+	// Security
+	"cryptography":     {"cryptography"},
+	"crypto":           {"cryptography"},
+	"cryptocurrencies": {"cryptography", "finance"},
+	"security":         {"security"},
+	"authentication":   {"authentication"},
+
+	// Development
+	"developmenttools": {"development-tools"},
+	"development":      {"development-tools"},
+	"buildtools":       {"development-tools"},
+	"debuggers":        {"development-tools"},
+	"debugging":        {"development-tools"},
+	"codegenerators":   {"development-tools"},
+	"versioncontrol":   {"development-tools"},
+	"testing":          {"testing"},
+	"test":             {"testing"},
+	"qualityassurance": {"testing"},
+	"logging":          {"logging"},
+	"config":           {"configuration"},
+	"configuration":    {"configuration"},
+	"apibindings":      {"api-bindings"},
+	"ffi":              {"api-bindings"},
+	"documentation":    {"documentation"},
+
+	// Systems
+	"os":                       {"operating-systems"},
+	"operatingsystem":          {"operating-systems"},
+	"operatingsystems":         {"operating-systems"},
+	"virtualization":           {"operating-systems"},
+	"filesystem":               {"filesystem"},
+	"filesystems":              {"filesystem"},
+	"concurrency":              {"concurrency"},
+	"parallel":                 {"concurrency"},
+	"distributedcomputing":     {"concurrency"},
+	"highperformancecomputing": {"concurrency"},
+	"asynchronous":             {"asynchronous"},
+	"async":                    {"asynchronous"},
+	"embedded":                 {"embedded"},
+	"hardwaresupport":          {"embedded"},
+	"hardware":                 {"embedded"},
+	"memorymanagement":         {"memory-management"},
+
+	// Science and data
+	"science":                {"science"},
+	"scientificengineering":  {"science"},
+	"simulation":             {"science"},
+	"physics":                {"science"},
+	"chemistry":              {"science"},
+	"math":                   {"mathematics"},
+	"mathematics":            {"mathematics"},
+	"optimization":           {"mathematics"},
+	"statistics":             {"statistics"},
+	"bayesian":               {"statistics"},
+	"timeseries":             {"statistics"},
+	"econometrics":           {"statistics", "finance"},
+	"distributions":          {"statistics"},
+	"machinelearning":        {"machine-learning"},
+	"artificialintelligence": {"machine-learning"},
+	"ai":                     {"machine-learning"},
+	"datascience":            {"data-science"},
+	"bioinformatics":         {"bioinformatics"},
+	"genetics":               {"bioinformatics"},
+	"omics":                  {"bioinformatics"},
+	"spatial":                {"geospatial"},
+	"gis":                    {"geospatial"},
+	"geography":              {"geospatial"},
+	"visualization":          {"visualization"},
+	"finance":                {"finance"},
+	"financial":              {"finance"},
+
+	// Media
+	"graphics":           {"graphics"},
+	"rendering":          {"graphics"},
+	"multimedia":         {"multimedia"},
+	"video":              {"multimedia"},
+	"audio":              {"audio"},
+	"sound":              {"audio"},
+	"soundaudio":         {"audio"},
+	"gamedevelopment":    {"game-development"},
+	"gameengines":        {"game-development"},
+	"games":              {"game-development"},
+	"game":               {"game-development"},
+	"gamesentertainment": {"game-development"},
+}
+
+// normalizeTerm reduces a category term to lowercase letters and digits.
+func normalizeTerm(term string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(term) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// NormalizeCategories maps registry categories and keywords onto the shared
+// taxonomy returned by CategoryTaxonomy. Hierarchical categories such as
+// cargo's "web-programming::http-client", PyPI's "Internet :: WWW/HTTP" and
+// dub's "library.networking" match on the full path and on each segment.
+// Terms with no mapping are dropped. The result is sorted and deduplicated.
+func NormalizeCategories(categories []string) []string {
+	seen := make(map[string]bool)
+	for _, category := range categories {
+		terms := []string{category}
+		for _, sep := range []string{"::", "."} {
+			if strings.Contains(category, sep) {
+				terms = append(terms, strings.Split(category, sep)...)
+			}
+		}
+		for _, term := range terms {
+			for _, slug := range taxonomy[normalizeTerm(term)] {
+				seen[slug] = true
+			}
+		}
+	}
+
+	if len(seen) == 0 {
+		return nil
+	}
+	out := make([]string, 0, len(seen))
+	for slug := range seen {
+		out = append(out, slug)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// CategoryTaxonomy returns every slug NormalizeCategories can produce.
+func CategoryTaxonomy() []string {
+	seen := make(map[string]bool)
+	for _, slugs := range taxonomy {
+		for _, slug := range slugs {
+			seen[slug] = true
+		}
+	}
+	out := make([]string, 0, len(seen))
+	for slug := range seen {
+		out = append(out, slug)
+	}
+	sort.Strings(out)
+	return out
+}
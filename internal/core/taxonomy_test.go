@@ -0,0 +1,42 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalizeCategories(t *testing.T) {
+	tests := []struct {
+		name string
+		in   []string
+		want []string
+	}{
+		{"cargo", []string{"web-programming::http-client", "command-line-utilities", "no-std"}, []string{"cli", "http", "web"}},
+		{"pypi", []string{"Internet :: WWW/HTTP", "Software Development :: Libraries", "Scientific/Engineering :: Bio-Informatics"}, []string{"bioinformatics", "http", "networking", "science", "web"}},
+		{"hackage", []string{"Network", "Cryptography"}, []string{"cryptography", "networking"}},
+		{"cran task views", []string{"MachineLearning", "TimeSeries"}, []string{"machine-learning", "statistics"}},
+		{"dub", []string{"library.networking"}, []string{"networking"}},
+		{"keywords", []string{"CLI", "testing", "react"}, []string{"cli", "testing"}},
+		{"unmapped", []string{"misc"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NormalizeCategories(tt.in); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NormalizeCategories(%v) = %v, want %v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCategoryTaxonomy(t *testing.T) {
+	slugs := CategoryTaxonomy()
+	seen := make(map[string]bool)
+	for _, s := range slugs {
+		seen[s] = true
+	}
+	for _, want := range []string{"web", "cli", "database", "machine-learning"} {
+		if !seen[want] {
+			t.Errorf("taxonomy missing %q", want)
+		}
+	}
+}
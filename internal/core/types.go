@@ -5,25 +5,58 @@ import "time"
 
 // Package represents metadata about a package from a registry.
 type Package struct {
-	Name          string
-	Description   string
-	Homepage      string
-	Repository    string
-	Licenses      string
-	Keywords      []string
-	Namespace     string         // @scope for npm, groupId for maven
-	LatestVersion string         // latest version if returned by registry
-	Metadata      map[string]any // registry-specific data
+	Name          string         `json:"name"`
+	Description   string         `json:"description,omitempty"`
+	Homepage      string         `json:"homepage,omitempty"`
+	Repository    string         `json:"repository,omitempty"`
+	Documentation string         `json:"documentation,omitempty"` // documentation URL the package declares, see DocumentationURL
+	Licenses      string         `json:"licenses,omitempty"`
+	Keywords      []string       `json:"keywords,omitempty"`
+	Categories    []string       `json:"categories,omitempty"`     // registry-defined categories, see NormalizeCategories
+	Namespace     string         `json:"namespace,omitempty"`      // @scope for npm, groupId for maven
+	LatestVersion string         `json:"latest_version,omitempty"` // latest version if returned by registry
+	Metadata      map[string]any `json:"metadata,omitempty"`       // registry-specific data
+
+	// LatestStableVersion is the newest version that isn't a pre-release,
+	// where the ecosystem tells them apart. It is empty when every version
+	// is a pre-release, and equals LatestVersion when that is stable.
+	LatestStableVersion string `json:"latest_stable_version,omitempty"`
+
+	// CreatedAt is when the package was first published, UpdatedAt when the
+	// registry last changed it, and LatestReleasedAt when its most recent
+	// version was published. Each is zero where the registry doesn't say.
+	CreatedAt        time.Time `json:"created_at,omitzero"`
+	UpdatedAt        time.Time `json:"updated_at,omitzero"`
+	LatestReleasedAt time.Time `json:"latest_released_at,omitzero"`
+
+	// Popularity holds the download and star counts the registry returns
+	// with the package. It is nil where the registry returns none.
+	Popularity *Popularity `json:"popularity,omitempty"`
+
+	// Sources lists the responses the package was built from. It is only
+	// filled in by FetchPackageWithSources.
+	Sources []Source `json:"sources,omitempty"`
 }
 
 // Version represents a specific version of a package.
 type Version struct {
-	Number      string
-	PublishedAt time.Time
-	Licenses    string
-	Integrity   string        // sha256-..., sha512-...
-	Status      VersionStatus // "", "yanked", "deprecated", "retracted"
-	Metadata    map[string]any
+	Number      string         `json:"number"`
+	PublishedAt time.Time      `json:"published_at,omitzero"`
+	Licenses    string         `json:"licenses,omitempty"`
+	Integrity   string         `json:"integrity,omitempty"`   // SRI, e.g. sha256-<base64>; see ParseIntegrity
+	Status      VersionStatus  `json:"status,omitempty"`      // "", "yanked", "deprecated", "retracted"
+	Publisher   *Maintainer    `json:"publisher,omitempty"`   // account that published this version, if the registry records it
+	Maintainers []Maintainer   `json:"maintainers,omitempty"` // maintainers at the time of this version, if the registry records them
+	Metadata    map[string]any `json:"metadata,omitempty"`
+	Sources     []Source       `json:"sources,omitempty"` // responses the version was built from, see FetchVersionsWithSources
+
+	// Size is the size in bytes of the file a version is downloaded as,
+	// and UnpackedSize and FileCount measure what it extracts to. Each is
+	// zero where the registry doesn't say. See SizeChange for comparing
+	// versions.
+	Size         int64 `json:"size,omitempty"`
+	UnpackedSize int64 `json:"unpacked_size,omitempty"`
+	FileCount    int   `json:"file_count,omitempty"`
 }
 
 // VersionStatus represents the status of a package version.
@@ -38,12 +71,34 @@ const (
 
 // Dependency represents a package dependency.
 type Dependency struct {
-	Name         string
-	Requirements string
-	Scope        Scope
-	Optional     bool
+	Name              string         `json:"name"`
+	Requirements      string         `json:"requirements,omitempty"`
+	Scope             Scope          `json:"scope,omitempty"`
+	Optional          bool           `json:"optional,omitempty"`
+	Target            string         `json:"target,omitempty"`             // platform or framework the dependency applies to, e.g. cfg(windows), net8.0, linux-64
+	EnvironmentMarker string         `json:"environment_marker,omitempty"` // PEP 508 environment marker, e.g. python_version < "3.10"
+	Extra             string         `json:"extra,omitempty"`              // optional feature group that pulls the dependency in, e.g. "socks"
+	Relation          Relation       `json:"relation,omitempty"`           // set when the entry isn't something the package requires
+	Metadata          map[string]any `json:"metadata,omitempty"`           // registry-specific data
 }
 
+// Relation is how a package relates to a dependency entry that it doesn't
+// require, such as Composer's conflict, replace and provide links. Entries
+// without a Relation are requirements.
+type Relation string
+
+const (
+	// RelationConflict means the package can't be installed alongside
+	// versions of the dependency matching Requirements.
+	RelationConflict Relation = "conflict"
+	// RelationReplace means the package contains the dependency, so it
+	// is never installed alongside it.
+	RelationReplace Relation = "replace"
+	// RelationProvide means the package implements the dependency, often
+	// a virtual package such as psr/log-implementation.
+	RelationProvide Relation = "provide"
+)
+
 // Scope indicates when a dependency is required.
 // Aligns with github.com/git-pkgs/manifests core.Scope.
 type Scope string
@@ -58,10 +113,10 @@ const (
 
 // Maintainer represents a package maintainer.
 type Maintainer struct {
-	UUID  string
-	Login string
-	Name  string
-	Email string
-	URL   string
-	Role  string
+	UUID  string `json:"uuid,omitempty"`
+	Login string `json:"login,omitempty"`
+	Name  string `json:"name,omitempty"`
+	Email string `json:"email,omitempty"`
+	URL   string `json:"url,omitempty"`
+	Role  string `json:"role,omitempty"`
 }
@@ -0,0 +1,69 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/git-pkgs/registries/names"
+)
+
+// ErrInvalidURL is wrapped by the error New returns for a base URL that
+// can't be a registry.
+var ErrInvalidURL = errors.New("invalid registry URL")
+
+// ValidateName reports whether name could exist on an ecosystem's registry.
+// See names.Validate for the rules.
+func ValidateName(ecosystem, name string) error {
+	return names.Validate(CanonicalEcosystem(ecosystem), name)
+}
+
+// ValidateURL reports whether baseURL is usable as a registry base URL: an
+// absolute http or https URL, for Cargo an index URL such as
+// "sparse+https://..." or "git+ssh://...", and for static registries a
+// directory path or file:// URL.
+func ValidateURL(ecosystem, baseURL string) error {
+	ecosystem = CanonicalEcosystem(ecosystem)
+	invalid := func(reason string) error {
+		return fmt.Errorf("%s: %w %q: %s", ecosystem, ErrInvalidURL, baseURL, reason)
+	}
+	if ecosystem == "static" {
+		if u, err := url.Parse(baseURL); err == nil && len(u.Scheme) > 1 && u.Scheme != "file" {
+			return invalid("static registries read a directory, given as a path or file:// URL")
+		}
+		return nil
+	}
+	if strings.TrimSpace(baseURL) != baseURL || strings.ContainsAny(baseURL, " \t\r\n") {
+		return invalid("URL contains whitespace")
+	}
+
+	raw := baseURL
+	if ecosystem == "cargo" {
+		raw = strings.TrimPrefix(strings.TrimPrefix(raw, "sparse+"), "git+")
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return invalid(err.Error())
+	}
+	if u.Scheme == "" {
+		return invalid(fmt.Sprintf("URL has no scheme; did you mean \"https://%s\"?", baseURL))
+	}
+
+	switch u.Scheme {
+	case "http", "https":
+		if u.Host == "" {
+			return invalid("URL has no host")
+		}
+	case "ssh", "file", "git":
+		if ecosystem != "cargo" || raw == baseURL {
+			return invalid(fmt.Sprintf("%s URLs are only supported for git-based Cargo indexes", u.Scheme))
+		}
+	default:
+		return invalid(fmt.Sprintf("unsupported scheme %q", u.Scheme))
+	}
+	if u.RawQuery != "" || u.Fragment != "" {
+		return invalid("base URLs take no query or fragment")
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/git-pkgs/registries/names"
+)
+
+func TestValidateURL(t *testing.T) {
+	valid := []struct{ ecosystem, url string }{
+		{"npm", "https://registry.npmjs.org"},
+		{"npm", "http://localhost:4873/"},
+		{"cargo", "sparse+https://cargo.example.com/index/"},
+		{"cargo", "git+ssh://git@example.com/index.git"},
+	}
+	for _, tt := range valid {
+		if err := ValidateURL(tt.ecosystem, tt.url); err != nil {
+			t.Errorf("ValidateURL(%q, %q) = %v", tt.ecosystem, tt.url, err)
+		}
+	}
+
+	invalid := []struct{ ecosystem, url string }{
+		{"npm", "registry.npmjs.org"},
+		{"npm", "https://"},
+		{"npm", "ftp://example.com"},
+		{"npm", "https://example.com/ npm"},
+		{"npm", "https://example.com/?token=x"},
+		{"pypi", "git+https://example.com/simple"},
+		{"npm", "file:///srv/npm"},
+	}
+	for _, tt := range invalid {
+		if err := ValidateURL(tt.ecosystem, tt.url); !errors.Is(err, ErrInvalidURL) {
+			t.Errorf("ValidateURL(%q, %q) = %v, want ErrInvalidURL", tt.ecosystem, tt.url, err)
+		}
+	}
+}
+
+func TestNewFromPURLValidatesName(t *testing.T) {
+	Register("validatetest", "https://example.com", func(baseURL string, client *Client) Registry {
+		t.Fatal("registry built for an invalid name")
+		return nil
+	})
+	// Rules are per ecosystem, so an unknown one only gets the common checks
+	if _, _, _, err := NewFromPURL("pkg:validatetest/%20padded", nil); !errors.Is(err, names.ErrInvalidName) {
+		t.Errorf("expected ErrInvalidName, got %v", err)
+	}
+	if _, err := FetchPackageFromPURL(context.Background(), "pkg:validatetest/%20padded", nil); !errors.Is(err, names.ErrInvalidName) {
+		t.Errorf("expected ErrInvalidName, got %v", err)
+	}
+}
@@ -0,0 +1,60 @@
+package cpan
+
+import (
+	"fmt"
+	"strings"
+)
+
+// BackPANURL is the archive of every release ever uploaded to CPAN.
+// Authors delete old releases from CPAN to keep their directory small, and
+// MetaCPAN marks those with the status "backpan"; their files are only
+// kept here.
+const BackPANURL = "https://backpan.perl.org"
+
+// statusBackPAN is the MetaCPAN status of releases deleted from CPAN.
+const statusBackPAN = "backpan"
+
+// WithoutBackPAN returns a new Registry whose FetchVersions leaves out
+// releases that have moved to BackPAN, listing only what a CPAN mirror
+// still serves. By default they are listed with StatusYanked.
+func (r *Registry) WithoutBackPAN() *Registry {
+	copy := *r
+	copy.excludeBackPAN = true
+	return &copy
+}
+
+// authorPath returns the directory of an author's uploads under
+// /authors/id on CPAN and BackPAN, such as "E/ET/ETHER".
+func authorPath(author string) string {
+	return fmt.Sprintf("%s/%s/%s", author[:1], author[:min(2, len(author))], author)
+}
+
+// downloadURL returns where a release's archive can be downloaded. MetaCPAN
+// gives a CPAN URL for every release, which stops working once the release
+// is deleted, so BackPAN releases are pointed at BackPAN instead.
+func (rel releaseInfo) downloadURL() string {
+	if rel.Status == statusBackPAN && rel.Author != "" && rel.Archive != "" {
+		return fmt.Sprintf("%s/authors/id/%s/%s", BackPANURL, authorPath(strings.ToUpper(rel.Author)), rel.Archive)
+	}
+	return rel.DownloadURL
+}
+
+// metadata returns a release's version metadata: its MetaCPAN status, its
+// author and archive, and the URL the archive downloads from.
+func (rel releaseInfo) metadata() map[string]any {
+	metadata := map[string]any{}
+	for key, value := range map[string]string{
+		"status":       rel.Status,
+		"author":       rel.Author,
+		"archive":      rel.Archive,
+		"download_url": rel.downloadURL(),
+	} {
+		if value != "" {
+			metadata[key] = value
+		}
+	}
+	if rel.Status == statusBackPAN {
+		metadata["backpan"] = true
+	}
+	return metadata
+}
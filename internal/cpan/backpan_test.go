@@ -0,0 +1,73 @@
+package cpan
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+func TestBackPANReleases(t *testing.T) {
+	var pages []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/release/_search", func(w http.ResponseWriter, r *http.Request) {
+		from := r.URL.Query().Get("from")
+		pages = append(pages, from)
+		// A full first page of current releases, then the old ones that
+		// were deleted to BackPAN
+		var hits []string
+		if from == "0" {
+			for i := range releasePageSize {
+				hits = append(hits, fmt.Sprintf(`{"_source": {"version": "1.%03d", "status": "cpan", "author": "TOBYINK", "archive": "Moo-1.%03d.tar.gz",
+					"download_url": "https://cpan.metacpan.org/authors/id/T/TO/TOBYINK/Moo-1.%03d.tar.gz"}}`, 999-i, 999-i, 999-i))
+			}
+		} else {
+			hits = append(hits, `{"_source": {"version": "0.009001", "status": "backpan", "author": "mstrout", "archive": "Moo-0.009001.tar.gz",
+				"download_url": "https://cpan.metacpan.org/authors/id/M/MS/MSTROUT/Moo-0.009001.tar.gz"}}`)
+		}
+		_, _ = fmt.Fprintf(w, `{"hits": {"hits": [%s]}}`, strings.Join(hits, ","))
+	})
+	mux.HandleFunc("/v1/release/MSTROUT/Moo-0.009001", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"name": "Moo-0.009001", "dependency": [{"module": "Class::Method::Modifiers", "version": "1.04", "phase": "runtime", "relationship": "requires"}]}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	ctx := context.Background()
+
+	reg := New(server.URL, core.DefaultClient())
+	versions, err := reg.FetchVersions(ctx, "Moo")
+	if err != nil {
+		t.Fatalf("FetchVersions failed: %v", err)
+	}
+	if len(versions) != releasePageSize+1 || len(pages) != 2 || pages[1] != "100" {
+		t.Fatalf("expected %d versions over 2 pages, got %d over %v", releasePageSize+1, len(versions), pages)
+	}
+	old := versions[len(versions)-1]
+	if old.Status != core.StatusYanked || old.Metadata["backpan"] != true {
+		t.Errorf("unexpected BackPAN version %+v", old)
+	}
+	if got := old.Metadata["download_url"]; got != "https://backpan.perl.org/authors/id/M/MS/MSTROUT/Moo-0.009001.tar.gz" {
+		t.Errorf("unexpected BackPAN download URL %v", got)
+	}
+	if got := versions[0].Metadata["download_url"]; got != "https://cpan.metacpan.org/authors/id/T/TO/TOBYINK/Moo-1.999.tar.gz" {
+		t.Errorf("unexpected CPAN download URL %v", got)
+	}
+
+	current, err := reg.WithoutBackPAN().FetchVersions(ctx, "Moo")
+	if err != nil || len(current) != releasePageSize {
+		t.Errorf("expected only CPAN releases, got %d: %v", len(current), err)
+	}
+
+	deps, err := reg.FetchDependencies(ctx, "MSTROUT/Moo", "0.009001")
+	if err != nil || len(deps) != 1 || deps[0].Name != "Class::Method::Modifiers" {
+		t.Errorf("FetchDependencies = %+v, %v", deps, err)
+	}
+
+	if got := reg.URLs().Download("Moo", "0.009001"); got != "" {
+		t.Errorf("expected no download URL without the author, got %q", got)
+	}
+}
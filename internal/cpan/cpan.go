@@ -7,6 +7,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/git-pkgs/purl"
 	"github.com/git-pkgs/registries/internal/core"
 	"github.com/git-pkgs/registries/internal/urlparser"
 )
@@ -23,9 +24,11 @@ func init() {
 }
 
 type Registry struct {
-	baseURL string
-	client  *core.Client
-	urls    *URLs
+	baseURL        string
+	testersURL     string
+	client         *core.Client
+	urls           *URLs
+	excludeBackPAN bool
 }
 
 func New(baseURL string, client *core.Client) *Registry {
@@ -33,8 +36,9 @@ func New(baseURL string, client *core.Client) *Registry {
 		baseURL = DefaultURL
 	}
 	r := &Registry{
-		baseURL: strings.TrimSuffix(baseURL, "/"),
-		client:  client,
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		testersURL: TestersURL,
+		client:     client,
 	}
 	r.urls = &URLs{baseURL: r.baseURL}
 	return r
@@ -92,6 +96,9 @@ type releaseInfo struct {
 	License      []string `json:"license"`
 	Status       string   `json:"status"`
 	Checksum     string   `json:"checksum_sha256"`
+	Author       string   `json:"author"`
+	Archive      string   `json:"archive"`
+	DownloadURL  string   `json:"download_url"`
 }
 
 type authorResponse struct {
@@ -102,6 +109,7 @@ type authorResponse struct {
 }
 
 func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package, error) {
+	_, name = splitAuthor(name)
 	// Normalize name: replace - with ::
 	moduleName := strings.ReplaceAll(name, "-", "::")
 	url := fmt.Sprintf("%s/v1/module/%s", r.baseURL, moduleName)
@@ -127,39 +135,68 @@ func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package
 		licenses = strings.Join(resp.License, ",")
 	}
 
+	// The module's indexed release is never a developer release, but
+	// check rather than assume
+	var stable string
+	if !core.IsPrerelease(ecosystem, resp.Version) {
+		stable = resp.Version
+	}
+
 	return &core.Package{
-		Name:        resp.Name,
-		Description: resp.Abstract,
-		Homepage:    resp.Resources.Homepage,
-		Repository:  repository,
-		Licenses:    licenses,
+		Name:          resp.Name,
+		Description:   resp.Abstract,
+		Homepage:      resp.Resources.Homepage,
+		Repository:    repository,
+		Licenses:      licenses,
+		LatestVersion: resp.Version,
 		Metadata: map[string]any{
 			"author":     resp.Author,
 			"bugtracker": resp.Resources.Bugtracker.Web,
 		},
+		LatestStableVersion: stable,
 	}, nil
 }
 
+// releasePageSize is how many releases each search request returns.
+const releasePageSize = 100
+
+// FetchVersions returns every release of a distribution, newest first,
+// paging through MetaCPAN's release search. Releases deleted from CPAN are
+// included with StatusYanked and a BackPAN download URL, unless the
+// registry was made with WithoutBackPAN.
 func (r *Registry) FetchVersions(ctx context.Context, name string) ([]core.Version, error) {
+	_, name = splitAuthor(name)
 	// Use the release endpoint to search for all versions
 	distName := strings.ReplaceAll(name, "::", "-")
-	url := fmt.Sprintf("%s/v1/release/_search?q=distribution:%s&size=100&sort=date:desc", r.baseURL, distName)
 
-	var resp releaseSearchResponse
-	if err := r.client.GetJSON(ctx, url, &resp); err != nil {
-		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
-			return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name}
+	var releases []releaseInfo
+	for from := 0; ; from += releasePageSize {
+		url := fmt.Sprintf("%s/v1/release/_search?q=distribution:%s&size=%d&from=%d&sort=date:desc", r.baseURL, distName, releasePageSize, from)
+
+		var resp releaseSearchResponse
+		if err := r.client.GetJSON(ctx, url, &resp); err != nil {
+			if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
+				return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name}
+			}
+			return nil, err
+		}
+		for _, hit := range resp.Hits.Hits {
+			releases = append(releases, hit.Source)
+		}
+		if len(resp.Hits.Hits) < releasePageSize {
+			break
 		}
-		return nil, err
 	}
 
-	if len(resp.Hits.Hits) == 0 {
+	if len(releases) == 0 {
 		return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name}
 	}
 
-	versions := make([]core.Version, len(resp.Hits.Hits))
-	for i, hit := range resp.Hits.Hits {
-		rel := hit.Source
+	versions := make([]core.Version, 0, len(releases))
+	for _, rel := range releases {
+		if r.excludeBackPAN && rel.Status == statusBackPAN {
+			continue
+		}
 
 		var publishedAt time.Time
 		if rel.Date != "" {
@@ -167,32 +204,38 @@ func (r *Registry) FetchVersions(ctx context.Context, name string) ([]core.Versi
 		}
 
 		var status core.VersionStatus
-		if rel.Status == "backpan" {
+		if rel.Status == statusBackPAN {
 			status = core.StatusYanked
 		}
 
 		var integrity string
 		if rel.Checksum != "" {
-			integrity = "sha256-" + rel.Checksum
+			integrity = core.HexIntegrity("sha256", rel.Checksum)
 		}
 
-		versions[i] = core.Version{
+		versions = append(versions, core.Version{
 			Number:      rel.Version,
 			PublishedAt: publishedAt,
 			Licenses:    strings.Join(rel.License, ","),
 			Status:      status,
 			Integrity:   integrity,
-		}
+			Metadata:    rel.metadata(),
+		})
 	}
 
 	return versions, nil
 }
 
 func (r *Registry) FetchDependencies(ctx context.Context, name, version string) ([]core.Dependency, error) {
-	// Fetch the release info
+	author, name := splitAuthor(name)
+	// Fetch the release info. With the author, the release is looked up
+	// by its full name, which also finds releases deleted to BackPAN.
 	distName := strings.ReplaceAll(name, "::", "-")
 	releaseName := fmt.Sprintf("%s-%s", distName, version)
 	url := fmt.Sprintf("%s/v1/release/%s", r.baseURL, releaseName)
+	if author != "" {
+		url = fmt.Sprintf("%s/v1/release/%s/%s", r.baseURL, author, releaseName)
+	}
 
 	var resp distributionResponse
 	if err := r.client.GetJSON(ctx, url, &resp); err != nil {
@@ -243,6 +286,7 @@ func mapPhaseToScope(phase, relationship string) core.Scope {
 }
 
 func (r *Registry) FetchMaintainers(ctx context.Context, name string) ([]core.Maintainer, error) {
+	_, name = splitAuthor(name)
 	// First get the module to find the author
 	moduleName := strings.ReplaceAll(name, "-", "::")
 	moduleURL := fmt.Sprintf("%s/v1/module/%s", r.baseURL, moduleName)
@@ -286,35 +330,46 @@ func (r *Registry) FetchMaintainers(ctx context.Context, name string) ([]core.Ma
 	}}, nil
 }
 
+// splitAuthor separates the PAUSE ID from a name of the form "ETHER/Moose",
+// the author/distribution form used by CPAN PURLs. Names without an author
+// are returned unchanged.
+func splitAuthor(name string) (author, dist string) {
+	if i := strings.Index(name, "/"); i > 0 {
+		return strings.ToUpper(name[:i]), name[i+1:]
+	}
+	return "", name
+}
+
 type URLs struct {
 	baseURL string
 }
 
 func (u *URLs) Registry(name, version string) string {
+	author, name := splitAuthor(name)
 	distName := strings.ReplaceAll(name, "::", "-")
 	if version != "" {
-		return fmt.Sprintf("https://metacpan.org/release/%s/%s-%s", getAuthorPlaceholder(), distName, version)
+		return fmt.Sprintf("https://metacpan.org/release/%s/%s-%s", author, distName, version)
 	}
 	return fmt.Sprintf("https://metacpan.org/dist/%s", distName)
 }
 
-func getAuthorPlaceholder() string {
-	// Without making an API call, we can't know the author
-	// Return a generic dist URL instead
-	return ""
-}
-
 func (u *URLs) Download(name, version string) string {
 	if version == "" {
 		return ""
 	}
+	author, name := splitAuthor(name)
 	distName := strings.ReplaceAll(name, "::", "-")
-	// CPAN download URLs require the author, which we don't have without an API call
-	// Return a search URL that will redirect
-	return fmt.Sprintf("https://cpan.metacpan.org/authors/id/%s-%s.tar.gz", distName, version)
+	if author != "" {
+		return fmt.Sprintf("https://cpan.metacpan.org/authors/id/%s/%s-%s.tar.gz", authorPath(author), distName, version)
+	}
+	// CPAN download URLs require the author, which we don't have without an
+	// API call. The "download_url" version metadata has the full URL,
+	// including BackPAN's for deleted releases.
+	return ""
 }
 
 func (u *URLs) Documentation(name, version string) string {
+	_, name = splitAuthor(name)
 	moduleName := strings.ReplaceAll(name, "-", "::")
 	if version != "" {
 		return fmt.Sprintf("https://metacpan.org/pod/release/%s-%s/%s", strings.ReplaceAll(name, "::", "-"), version, moduleName)
@@ -322,8 +377,16 @@ func (u *URLs) Documentation(name, version string) string {
 	return fmt.Sprintf("https://metacpan.org/pod/%s", moduleName)
 }
 
+// PURL includes the author as the namespace when the name has one, as the
+// PURL spec requires. Names without an author produce a PURL that strict
+// parsers reject; use "AUTHOR/Distribution" names (the author is in
+// Package.Metadata["author"]) for PURLs that round-trip.
 func (u *URLs) PURL(name, version string) string {
+	author, name := splitAuthor(name)
 	distName := strings.ReplaceAll(name, "::", "-")
+	if author != "" {
+		return purl.New(ecosystem, author, distName, version, nil).String()
+	}
 	if version != "" {
 		return fmt.Sprintf("pkg:cpan/%s@%s", distName, version)
 	}
@@ -82,7 +82,7 @@ func TestFetchVersions(t *testing.T) {
 				Hits: []struct {
 					Source releaseInfo `json:"_source"`
 				}{
-					{Source: releaseInfo{Version: "2.2201", Date: "2023-10-15T12:00:00Z", License: []string{"perl_5"}, Checksum: "abc123"}},
+					{Source: releaseInfo{Version: "2.2201", Date: "2023-10-15T12:00:00Z", License: []string{"perl_5"}, Checksum: "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"}},
 					{Source: releaseInfo{Version: "2.2200", Date: "2023-08-01T12:00:00Z", License: []string{"perl_5"}, Status: "backpan"}},
 				},
 			},
@@ -107,7 +107,7 @@ func TestFetchVersions(t *testing.T) {
 	if versions[0].Status != core.StatusNone {
 		t.Errorf("expected no status for first version, got %q", versions[0].Status)
 	}
-	if versions[0].Integrity != "sha256-abc123" {
+	if versions[0].Integrity != "sha256-ungWv48Bz+pBQUDeXa4iI7ADYaOWF3qctBD/YfIAFa0=" {
 		t.Errorf("unexpected integrity: %q", versions[0].Integrity)
 	}
 
@@ -229,6 +229,10 @@ func TestURLBuilder(t *testing.T) {
 		{"documentation_module", func() string { return urls.Documentation("DBIx::Class", "") }, "https://metacpan.org/pod/DBIx::Class"},
 		{"purl", func() string { return urls.PURL("Moose", "2.2201") }, "pkg:cpan/Moose@2.2201"},
 		{"purl_with_colons", func() string { return urls.PURL("DBIx::Class", "0.08") }, "pkg:cpan/DBIx-Class@0.08"},
+		{"purl_with_author", func() string { return urls.PURL("ETHER/Moose", "2.2201") }, "pkg:cpan/ETHER/Moose@2.2201"},
+		{"registry_with_author", func() string { return urls.Registry("ETHER/Moose", "2.2201") }, "https://metacpan.org/release/ETHER/Moose-2.2201"},
+		{"download_with_author", func() string { return urls.Download("ETHER/Moose", "2.2201") }, "https://cpan.metacpan.org/authors/id/E/ET/ETHER/Moose-2.2201.tar.gz"},
+		{"documentation_with_author", func() string { return urls.Documentation("ETHER/Moose", "") }, "https://metacpan.org/pod/Moose"},
 	}
 
 	for _, tt := range tests {
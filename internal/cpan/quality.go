@@ -0,0 +1,144 @@
+package cpan
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/git-pkgs/registries/internal/core"
+	"github.com/git-pkgs/vers"
+)
+
+// TestersURL is the CPAN Testers API, which has a report per test run.
+const TestersURL = "https://api.cpantesters.org"
+
+// releaseTests is the MetaCPAN release document's summary of CPAN Testers
+// reports.
+type releaseTests struct {
+	Version string `json:"version"`
+	Tests   *struct {
+		Pass    int `json:"pass"`
+		Fail    int `json:"fail"`
+		NA      int `json:"na"`
+		Unknown int `json:"unknown"`
+	} `json:"tests"`
+}
+
+// distributionRiver is the MetaCPAN distribution document's river: how
+// many distributions depend on this one directly (immediate) and at all
+// (total), and the resulting 0-5 bucket shown on metacpan.org.
+type distributionRiver struct {
+	River *struct {
+		Bucket    int `json:"bucket"`
+		Immediate int `json:"immediate"`
+		Total     int `json:"total"`
+	} `json:"river"`
+}
+
+type testerReport struct {
+	Grade    string `json:"grade"`
+	OSName   string `json:"osname"`
+	Perl     string `json:"perl"`
+	Platform string `json:"platform"`
+}
+
+// FetchQualitySignals returns the CPAN Testers results of a release and
+// the distribution's position in the CPAN river. Counts come from MetaCPAN;
+// the per-platform matrix comes from the CPAN Testers API and is left out
+// if that is unavailable.
+func (r *Registry) FetchQualitySignals(ctx context.Context, name, version string) (*core.QualitySignals, error) {
+	_, name = splitAuthor(name)
+	distName := strings.ReplaceAll(name, "::", "-")
+
+	releaseURL := fmt.Sprintf("%s/v1/release/%s", r.baseURL, distName)
+	if version != "" {
+		releaseURL += "-" + version
+	}
+	var rel releaseTests
+	if err := r.client.GetJSON(ctx, releaseURL, &rel); err != nil {
+		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
+			return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name, Version: version}
+		}
+		return nil, err
+	}
+	if version == "" {
+		version = rel.Version
+	}
+
+	signals := &core.QualitySignals{
+		Version:          version,
+		DirectDependents: -1,
+		TotalDependents:  -1,
+	}
+
+	var dist distributionRiver
+	if err := r.client.GetJSON(ctx, fmt.Sprintf("%s/v1/distribution/%s", r.baseURL, distName), &dist); err == nil && dist.River != nil {
+		signals.DirectDependents = dist.River.Immediate
+		signals.TotalDependents = dist.River.Total
+		signals.Metadata = map[string]any{"river_bucket": dist.River.Bucket}
+	}
+
+	var reports []testerReport
+	testersURL := fmt.Sprintf("%s/v3/summary/%s/%s", r.testersURL, distName, version)
+	if err := r.client.GetJSON(ctx, testersURL, &reports); err != nil {
+		reports = nil
+	}
+
+	if rel.Tests != nil || len(reports) > 0 {
+		signals.Tests = testMatrix(reports)
+		if rel.Tests != nil {
+			signals.Tests.Pass = rel.Tests.Pass
+			signals.Tests.Fail = rel.Tests.Fail
+			signals.Tests.NA = rel.Tests.NA
+			signals.Tests.Unknown = rel.Tests.Unknown
+		}
+	}
+	return signals, nil
+}
+
+// testMatrix counts reports by grade, overall and per operating system and
+// perl version. Cells are sorted by platform, then perl version newest
+// first.
+func testMatrix(reports []testerReport) *core.TestSummary {
+	summary := &core.TestSummary{}
+	cells := make(map[[2]string]*core.TestCell)
+	for _, rep := range reports {
+		platform := rep.OSName
+		if platform == "" {
+			platform = rep.Platform
+		}
+		key := [2]string{platform, rep.Perl}
+		cell := cells[key]
+		if cell == nil {
+			cell = &core.TestCell{Platform: platform, Runtime: rep.Perl}
+			cells[key] = cell
+		}
+		switch strings.ToLower(rep.Grade) {
+		case "pass":
+			summary.Pass++
+			cell.Pass++
+		case "fail":
+			summary.Fail++
+			cell.Fail++
+		case "na":
+			summary.NA++
+			cell.NA++
+		default:
+			summary.Unknown++
+			cell.Unknown++
+		}
+	}
+
+	for _, cell := range cells {
+		summary.Matrix = append(summary.Matrix, *cell)
+	}
+	sort.Slice(summary.Matrix, func(i, j int) bool {
+		a, b := summary.Matrix[i], summary.Matrix[j]
+		if a.Platform != b.Platform {
+			return a.Platform < b.Platform
+		}
+		return vers.Compare(a.Runtime, b.Runtime) > 0
+	})
+	return summary
+}
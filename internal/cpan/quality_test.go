@@ -0,0 +1,81 @@
+package cpan
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+func TestFetchQualitySignals(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/release/Moose":
+			_, _ = w.Write([]byte(`{"version":"2.2207","tests":{"pass":3,"fail":1,"na":0,"unknown":0}}`))
+		case "/v1/distribution/Moose":
+			_, _ = w.Write([]byte(`{"name":"Moose","river":{"bucket":5,"immediate":1200,"total":9800}}`))
+		case "/v3/summary/Moose/2.2207":
+			_, _ = w.Write([]byte(`[
+				{"grade":"pass","osname":"linux","perl":"5.8.9"},
+				{"grade":"pass","osname":"linux","perl":"5.38.0"},
+				{"grade":"fail","osname":"linux","perl":"5.38.0"},
+				{"grade":"pass","osname":"darwin","perl":"5.36.0"}
+			]`))
+		case "/v1/release/Quiet-Dist-0.01":
+			_, _ = w.Write([]byte(`{"version":"0.01"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	reg.testersURL = server.URL
+	ctx := context.Background()
+
+	signals, err := reg.FetchQualitySignals(ctx, "Moose", "")
+	if err != nil {
+		t.Fatalf("FetchQualitySignals failed: %v", err)
+	}
+	if signals.Version != "2.2207" || signals.DirectDependents != 1200 || signals.TotalDependents != 9800 {
+		t.Errorf("unexpected signals: %+v", signals)
+	}
+	if signals.Metadata["river_bucket"] != 5 {
+		t.Errorf("unexpected river bucket: %v", signals.Metadata)
+	}
+	if signals.Tests == nil || signals.Tests.Pass != 3 || signals.Tests.Fail != 1 {
+		t.Fatalf("unexpected tests: %+v", signals.Tests)
+	}
+	want := []core.TestCell{
+		{Platform: "darwin", Runtime: "5.36.0", Pass: 1},
+		{Platform: "linux", Runtime: "5.38.0", Pass: 1, Fail: 1},
+		{Platform: "linux", Runtime: "5.8.9", Pass: 1},
+	}
+	if len(signals.Tests.Matrix) != len(want) {
+		t.Fatalf("unexpected matrix: %+v", signals.Tests.Matrix)
+	}
+	for i, cell := range want {
+		if signals.Tests.Matrix[i] != cell {
+			t.Errorf("matrix[%d] = %+v, want %+v", i, signals.Tests.Matrix[i], cell)
+		}
+	}
+
+	// No river, no reports
+	signals, err = reg.FetchQualitySignals(ctx, "Quiet::Dist", "0.01")
+	if err != nil {
+		t.Fatalf("FetchQualitySignals failed: %v", err)
+	}
+	if signals.Tests != nil || signals.DirectDependents != -1 {
+		t.Errorf("unexpected signals: %+v", signals)
+	}
+
+	if _, err := reg.FetchQualitySignals(ctx, "Missing", "1.0"); !errors.Is(err, core.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	if _, err := core.FetchQualitySignals(ctx, reg, "Moose", ""); err != nil {
+		t.Errorf("cpan should implement QualityFetcher: %v", err)
+	}
+}
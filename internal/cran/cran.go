@@ -25,9 +25,11 @@ func init() {
 }
 
 type Registry struct {
-	baseURL string
-	client  *core.Client
-	urls    *URLs
+	baseURL   string
+	crandbURL string
+	taskViews bool
+	client    *core.Client
+	urls      *URLs
 }
 
 func New(baseURL string, client *core.Client) *Registry {
@@ -42,6 +44,15 @@ func New(baseURL string, client *core.Client) *Registry {
 	return r
 }
 
+// WithTaskViews returns a new Registry whose FetchPackage also reads the
+// CRAN Task Views that list a package into Categories. It costs a request
+// for the package's CRAN page on every FetchPackage; see FetchTaskViews.
+func (r *Registry) WithTaskViews() *Registry {
+	copy := *r
+	copy.taskViews = true
+	return &copy
+}
+
 func (r *Registry) Ecosystem() string {
 	return ecosystem
 }
@@ -70,6 +81,10 @@ type descriptionInfo struct {
 }
 
 func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package, error) {
+	if r.crandbURL != "" {
+		return r.fetchPackageCRANDB(ctx, name)
+	}
+
 	// Fetch the DESCRIPTION file
 	descURL := fmt.Sprintf("%s/web/packages/%s/DESCRIPTION", r.baseURL, name)
 	body, err := r.client.GetBody(ctx, descURL)
@@ -85,12 +100,20 @@ func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package
 	// Extract repository URL from URL field
 	repository := extractRepository(desc.URL)
 
+	categories, err := r.categories(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
 	return &core.Package{
 		Name:        desc.Package,
 		Description: desc.Title,
 		Homepage:    getFirstURL(desc.URL),
 		Repository:  repository,
 		Licenses:    desc.License,
+		Categories:  categories,
+		// CRAN has no pre-releases, so there's no separate stable version
+		LatestVersion: desc.Version,
 		Metadata: map[string]any{
 			"author":       desc.Author,
 			"maintainer":   desc.Maintainer,
@@ -100,6 +123,35 @@ func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package
 	}, nil
 }
 
+var taskViewPattern = regexp.MustCompile(`href="\.\./\.\./views/([A-Za-z0-9]+)\.html"`)
+
+// FetchTaskViews returns the CRAN Task Views that list the package, read
+// from its CRAN page since no API exposes them. A mirror without the page
+// returns none.
+func (r *Registry) FetchTaskViews(ctx context.Context, name string) ([]string, error) {
+	body, err := r.client.GetText(ctx, fmt.Sprintf("%s/web/packages/%s/index.html", r.baseURL, name))
+	if err != nil {
+		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var views []string
+	for _, m := range taskViewPattern.FindAllStringSubmatch(body, -1) {
+		views = append(views, m[1])
+	}
+	return views, nil
+}
+
+// categories returns the package's task views if WithTaskViews asked for
+// them.
+func (r *Registry) categories(ctx context.Context, name string) ([]string, error) {
+	if !r.taskViews {
+		return nil, nil
+	}
+	return r.FetchTaskViews(ctx, name)
+}
+
 func parseDescription(content string) descriptionInfo {
 	info := descriptionInfo{}
 	scanner := bufio.NewScanner(strings.NewReader(content))
@@ -194,6 +246,10 @@ func getFirstURL(urlField string) string {
 }
 
 func (r *Registry) FetchVersions(ctx context.Context, name string) ([]core.Version, error) {
+	if r.crandbURL != "" {
+		return r.fetchVersionsCRANDB(ctx, name)
+	}
+
 	// CRAN only keeps the current version, but we can get archived versions
 	// First get current version from DESCRIPTION
 	descURL := fmt.Sprintf("%s/web/packages/%s/DESCRIPTION", r.baseURL, name)
@@ -253,6 +309,10 @@ func parseArchiveVersions(html, pkgName string) []string {
 }
 
 func (r *Registry) FetchDependencies(ctx context.Context, name, version string) ([]core.Dependency, error) {
+	if r.crandbURL != "" {
+		return r.fetchDependenciesCRANDB(ctx, name, version)
+	}
+
 	// For current version, use DESCRIPTION; for archived, fetch from archive
 	var body []byte
 	var err error
@@ -271,7 +331,7 @@ func (r *Registry) FetchDependencies(ctx context.Context, name, version string)
 
 	// Note: If version doesn't match, we'd ideally fetch from archive, but CRAN
 	// archive doesn't have extracted DESCRIPTION files. Using current version's
-	// dependencies as an approximation.
+	// dependencies as an approximation. Use WithCRANDB for per-version data.
 
 	var deps []core.Dependency
 
@@ -336,6 +396,10 @@ func parseDependencyList(depString string, scope core.Scope) []core.Dependency {
 }
 
 func (r *Registry) FetchMaintainers(ctx context.Context, name string) ([]core.Maintainer, error) {
+	if r.crandbURL != "" {
+		return r.fetchMaintainersCRANDB(ctx, name)
+	}
+
 	descURL := fmt.Sprintf("%s/web/packages/%s/DESCRIPTION", r.baseURL, name)
 	body, err := r.client.GetBody(ctx, descURL)
 	if err != nil {
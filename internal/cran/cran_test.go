@@ -2,8 +2,10 @@ package cran
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"sync/atomic"
 	"testing"
 
 	"github.com/git-pkgs/registries/internal/core"
@@ -33,16 +35,19 @@ NeedsCompilation: no
 
 func TestFetchPackage(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if r.URL.Path != "/web/packages/ggplot2/DESCRIPTION" {
+		switch r.URL.Path {
+		case "/web/packages/ggplot2/DESCRIPTION":
+			_, _ = w.Write([]byte(sampleDescription))
+		case "/web/packages/ggplot2/index.html":
+			_, _ = w.Write([]byte(`<tr><td>In&nbsp;views:</td><td><a href="../../views/Phylogenetics.html">Phylogenetics</a>, <a href="../../views/Spatial.html">Spatial</a></td></tr>`))
+		default:
 			t.Errorf("unexpected path: %s", r.URL.Path)
 			w.WriteHeader(404)
-			return
 		}
-		_, _ = w.Write([]byte(sampleDescription))
 	}))
 	defer server.Close()
 
-	reg := New(server.URL, core.DefaultClient())
+	reg := New(server.URL, core.DefaultClient()).WithTaskViews()
 	pkg, err := reg.FetchPackage(context.Background(), "ggplot2")
 	if err != nil {
 		t.Fatalf("FetchPackage failed: %v", err)
@@ -63,6 +68,40 @@ func TestFetchPackage(t *testing.T) {
 	if pkg.Homepage != "https://ggplot2.tidyverse.org" {
 		t.Errorf("unexpected homepage: %q", pkg.Homepage)
 	}
+	if len(pkg.Categories) != 2 || pkg.Categories[0] != "Phylogenetics" || pkg.Categories[1] != "Spatial" {
+		t.Errorf("unexpected task views: %v", pkg.Categories)
+	}
+}
+
+func TestFetchPackageTaskViews(t *testing.T) {
+	var pageRequests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/web/packages/ggplot2/index.html" {
+			pageRequests.Add(1)
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		_, _ = w.Write([]byte(sampleDescription))
+	}))
+	defer server.Close()
+	client := core.DefaultClient()
+
+	// Task views are only read when asked for
+	pkg, err := New(server.URL, client).FetchPackage(context.Background(), "ggplot2")
+	if err != nil || len(pkg.Categories) != 0 || pageRequests.Load() != 0 {
+		t.Errorf("FetchPackage = %v, %v with %d page requests", pkg, err, pageRequests.Load())
+	}
+
+	// and then a failure to read them is an error, not a package without
+	// categories
+	if _, err := New(server.URL, client).WithTaskViews().FetchPackage(context.Background(), "ggplot2"); err == nil {
+		t.Error("expected the page's error")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := New(server.URL, client).WithTaskViews().FetchTaskViews(ctx, "ggplot2"); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
 }
 
 func TestFetchVersions(t *testing.T) {
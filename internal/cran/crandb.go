@@ -0,0 +1,184 @@
+package cran
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+// CRANDBURL is the public crandb instance run by R-hub.
+const CRANDBURL = "https://crandb.r-pkg.org"
+
+// crandbDescription is a DESCRIPTION file as served by crandb. Dependency
+// fields are objects mapping package name to constraint ("*" for none).
+type crandbDescription struct {
+	Package          string            `json:"Package"`
+	Version          string            `json:"Version"`
+	Title            string            `json:"Title"`
+	Description      string            `json:"Description"`
+	License          string            `json:"License"`
+	URL              string            `json:"URL"`
+	BugReports       string            `json:"BugReports"`
+	Author           string            `json:"Author"`
+	Maintainer       string            `json:"Maintainer"`
+	Depends          map[string]string `json:"Depends"`
+	Imports          map[string]string `json:"Imports"`
+	Suggests         map[string]string `json:"Suggests"`
+	LinkingTo        map[string]string `json:"LinkingTo"`
+	NeedsCompilation string            `json:"NeedsCompilation"`
+	DatePublication  string            `json:"Date/Publication"`
+}
+
+type crandbAllResponse struct {
+	Name     string                       `json:"name"`
+	Latest   string                       `json:"latest"`
+	Archived bool                         `json:"archived"`
+	Versions map[string]crandbDescription `json:"versions"`
+	Timeline map[string]string            `json:"timeline"`
+}
+
+// WithCRANDB returns a new Registry that reads package metadata from a crandb
+// instance instead of the live DESCRIPTION file and archive listing. This gives
+// every historical version its own publish date, license and dependencies.
+// If url is empty, CRANDBURL is used.
+func (r *Registry) WithCRANDB(url string) *Registry {
+	if url == "" {
+		url = CRANDBURL
+	}
+	copy := *r
+	copy.crandbURL = strings.TrimSuffix(url, "/")
+	return &copy
+}
+
+func (r *Registry) fetchCRANDB(ctx context.Context, path string, v any, name, version string) error {
+	url := fmt.Sprintf("%s/%s", r.crandbURL, path)
+	if err := r.client.GetJSON(ctx, url, v); err != nil {
+		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
+			return &core.NotFoundError{Ecosystem: ecosystem, Name: name, Version: version}
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *Registry) fetchPackageCRANDB(ctx context.Context, name string) (*core.Package, error) {
+	var desc crandbDescription
+	if err := r.fetchCRANDB(ctx, name, &desc, name, ""); err != nil {
+		return nil, err
+	}
+	categories, err := r.categories(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &core.Package{
+		Name:          desc.Package,
+		Description:   desc.Title,
+		Homepage:      getFirstURL(desc.URL),
+		Repository:    extractRepository(desc.URL),
+		Licenses:      desc.License,
+		LatestVersion: desc.Version,
+		Categories:    categories,
+		Metadata: map[string]any{
+			"author":            desc.Author,
+			"maintainer":        desc.Maintainer,
+			"bug_reports":       desc.BugReports,
+			"needs_compilation": desc.NeedsCompilation,
+		},
+	}, nil
+}
+
+func (r *Registry) fetchVersionsCRANDB(ctx context.Context, name string) ([]core.Version, error) {
+	var resp crandbAllResponse
+	if err := r.fetchCRANDB(ctx, name+"/all", &resp, name, ""); err != nil {
+		return nil, err
+	}
+
+	versions := make([]core.Version, 0, len(resp.Versions))
+	for num, desc := range resp.Versions {
+		var publishedAt time.Time
+		if ts, ok := resp.Timeline[num]; ok {
+			publishedAt, _ = time.Parse(time.RFC3339, ts)
+		}
+
+		versions = append(versions, core.Version{
+			Number:      num,
+			PublishedAt: publishedAt,
+			Licenses:    desc.License,
+			Metadata: map[string]any{
+				"maintainer":        desc.Maintainer,
+				"needs_compilation": desc.NeedsCompilation,
+				"archived":          resp.Archived,
+			},
+		})
+	}
+
+	// Newest first, matching the DESCRIPTION-based listing
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].PublishedAt.After(versions[j].PublishedAt)
+	})
+
+	return versions, nil
+}
+
+func (r *Registry) fetchDependenciesCRANDB(ctx context.Context, name, version string) ([]core.Dependency, error) {
+	var desc crandbDescription
+	if err := r.fetchCRANDB(ctx, name+"/"+version, &desc, name, version); err != nil {
+		return nil, err
+	}
+
+	var deps []core.Dependency
+	deps = append(deps, crandbDependencies(desc.Depends, core.Runtime)...)
+	deps = append(deps, crandbDependencies(desc.Imports, core.Runtime)...)
+	deps = append(deps, crandbDependencies(desc.Suggests, core.Optional)...)
+	deps = append(deps, crandbDependencies(desc.LinkingTo, core.Build)...)
+	return deps, nil
+}
+
+func crandbDependencies(fields map[string]string, scope core.Scope) []core.Dependency {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		// Skip R itself
+		if name != "R" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	deps := make([]core.Dependency, len(names))
+	for i, name := range names {
+		requirements := fields[name]
+		if requirements == "*" {
+			requirements = ""
+		}
+		deps[i] = core.Dependency{
+			Name:         name,
+			Requirements: requirements,
+			Scope:        scope,
+			Optional:     scope == core.Optional,
+		}
+	}
+	return deps
+}
+
+func (r *Registry) fetchMaintainersCRANDB(ctx context.Context, name string) ([]core.Maintainer, error) {
+	var desc crandbDescription
+	if err := r.fetchCRANDB(ctx, name, &desc, name, ""); err != nil {
+		return nil, err
+	}
+
+	if desc.Maintainer == "" {
+		return nil, nil
+	}
+
+	maintainer := parseMaintainer(desc.Maintainer)
+	if maintainer.Name == "" && maintainer.Email == "" {
+		return nil, nil
+	}
+
+	return []core.Maintainer{maintainer}, nil
+}
@@ -0,0 +1,147 @@
+package cran
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+const crandbAll = `{
+  "_id": "jsonlite",
+  "name": "jsonlite",
+  "latest": "1.8.8",
+  "archived": false,
+  "versions": {
+    "1.8.7": {"Package": "jsonlite", "Version": "1.8.7", "License": "MIT + file LICENSE"},
+    "1.8.8": {"Package": "jsonlite", "Version": "1.8.8", "License": "MIT + file LICENSE", "Maintainer": "Jeroen Ooms <jeroen@berkeley.edu>"},
+    "0.9.0": {"Package": "jsonlite", "Version": "0.9.0", "License": "MIT"}
+  },
+  "timeline": {
+    "0.9.0": "2013-12-03T20:14:04+00:00",
+    "1.8.7": "2023-06-29T08:10:02+00:00",
+    "1.8.8": "2023-12-04T12:50:02+00:00"
+  }
+}`
+
+const crandbVersion = `{
+  "Package": "jsonlite",
+  "Version": "1.8.7",
+  "Title": "A Simple and Robust JSON Parser and Generator for R",
+  "License": "MIT + file LICENSE",
+  "URL": "https://jeroen.r-universe.dev/jsonlite, https://github.com/jeroen/jsonlite",
+  "Maintainer": "Jeroen Ooms <jeroen@berkeley.edu>",
+  "Depends": {"methods": "*"},
+  "Imports": {"R": ">= 3.5"},
+  "Suggests": {"httr": "*", "curl": ">= 0.5", "testthat": "*"}
+}`
+
+func TestCRANDBFetchVersions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/jsonlite/all" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+			w.WriteHeader(404)
+			return
+		}
+		_, _ = w.Write([]byte(crandbAll))
+	}))
+	defer server.Close()
+
+	reg := New("", core.DefaultClient()).WithCRANDB(server.URL)
+	versions, err := reg.FetchVersions(context.Background(), "jsonlite")
+	if err != nil {
+		t.Fatalf("FetchVersions failed: %v", err)
+	}
+
+	if len(versions) != 3 {
+		t.Fatalf("expected 3 versions, got %d", len(versions))
+	}
+	if versions[0].Number != "1.8.8" || versions[2].Number != "0.9.0" {
+		t.Errorf("expected newest first, got %s ... %s", versions[0].Number, versions[2].Number)
+	}
+	if versions[2].PublishedAt.Year() != 2013 {
+		t.Errorf("expected archived version to have a publish date, got %v", versions[2].PublishedAt)
+	}
+	if versions[2].Licenses != "MIT" {
+		t.Errorf("expected per-version license 'MIT', got %q", versions[2].Licenses)
+	}
+}
+
+func TestCRANDBFetchDependencies(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/jsonlite/1.8.7" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+			w.WriteHeader(404)
+			return
+		}
+		_, _ = w.Write([]byte(crandbVersion))
+	}))
+	defer server.Close()
+
+	reg := New("", core.DefaultClient()).WithCRANDB(server.URL)
+	deps, err := reg.FetchDependencies(context.Background(), "jsonlite", "1.8.7")
+	if err != nil {
+		t.Fatalf("FetchDependencies failed: %v", err)
+	}
+
+	// methods + 3 suggests, R is skipped
+	if len(deps) != 4 {
+		t.Fatalf("expected 4 dependencies, got %d", len(deps))
+	}
+	if deps[0].Name != "methods" || deps[0].Requirements != "" || deps[0].Scope != core.Runtime {
+		t.Errorf("unexpected first dependency: %+v", deps[0])
+	}
+	if deps[1].Name != "curl" || deps[1].Requirements != ">= 0.5" || !deps[1].Optional {
+		t.Errorf("unexpected curl dependency: %+v", deps[1])
+	}
+}
+
+func TestCRANDBFetchPackageAndMaintainers(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Task views still come from the CRAN package page
+		if r.URL.Path == "/web/packages/jsonlite/index.html" {
+			_, _ = w.Write([]byte(`<a href="../../views/WebTechnologies.html">WebTechnologies</a>`))
+			return
+		}
+		_, _ = w.Write([]byte(crandbVersion))
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient()).WithCRANDB(server.URL).WithTaskViews()
+	pkg, err := reg.FetchPackage(context.Background(), "jsonlite")
+	if err != nil {
+		t.Fatalf("FetchPackage failed: %v", err)
+	}
+	if len(pkg.Categories) != 1 || pkg.Categories[0] != "WebTechnologies" {
+		t.Errorf("unexpected task views: %v", pkg.Categories)
+	}
+	if pkg.Repository != "https://github.com/jeroen/jsonlite" {
+		t.Errorf("unexpected repository: %q", pkg.Repository)
+	}
+	if pkg.LatestVersion != "1.8.7" {
+		t.Errorf("unexpected latest version: %q", pkg.LatestVersion)
+	}
+
+	maintainers, err := reg.FetchMaintainers(context.Background(), "jsonlite")
+	if err != nil {
+		t.Fatalf("FetchMaintainers failed: %v", err)
+	}
+	if len(maintainers) != 1 || maintainers[0].Email != "jeroen@berkeley.edu" {
+		t.Errorf("unexpected maintainers: %+v", maintainers)
+	}
+}
+
+func TestCRANDBNotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(404)
+	}))
+	defer server.Close()
+
+	reg := New("", core.DefaultClient()).WithCRANDB(server.URL)
+	_, err := reg.FetchDependencies(context.Background(), "nonexistent", "1.0.0")
+	if _, ok := err.(*core.NotFoundError); !ok {
+		t.Errorf("expected NotFoundError, got %T", err)
+	}
+}
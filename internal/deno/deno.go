@@ -4,6 +4,9 @@ package deno
 import (
 	"context"
 	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -13,6 +16,7 @@ import (
 
 const (
 	DefaultURL = "https://apiland.deno.dev"
+	CDNURL     = "https://cdn.deno.land"
 	ecosystem  = "deno"
 )
 
@@ -24,6 +28,7 @@ func init() {
 
 type Registry struct {
 	baseURL string
+	cdnURL  string
 	client  *core.Client
 	urls    *URLs
 }
@@ -36,6 +41,12 @@ func New(baseURL string, client *core.Client) *Registry {
 		baseURL: strings.TrimSuffix(baseURL, "/"),
 		client:  client,
 	}
+	// The module graph lives on the CDN rather than the API. A mirror is
+	// expected to serve both from the same host.
+	r.cdnURL = r.baseURL
+	if r.baseURL == DefaultURL {
+		r.cdnURL = CDNURL
+	}
 	r.urls = &URLs{baseURL: r.baseURL}
 	return r
 }
@@ -49,10 +60,25 @@ func (r *Registry) URLs() core.URLBuilder {
 }
 
 type moduleResponse struct {
-	Name        string `json:"name"`
-	Description string `json:"description"`
-	Star        int    `json:"star"`
-	RepoID      int64  `json:"repo_id"`
+	Name            string  `json:"name"`
+	Description     string  `json:"description"`
+	StarCount       int     `json:"star_count"`
+	PopularityScore float64 `json:"popularity_score"`
+}
+
+type moduleListResponse struct {
+	Items []moduleResponse `json:"items"`
+}
+
+// depsResponse is the module graph deno.land/x computes for every published
+// version, keyed by module URL.
+type depsResponse struct {
+	Graph struct {
+		Nodes map[string]struct {
+			Size int64    `json:"size"`
+			Deps []string `json:"deps"`
+		} `json:"nodes"`
+	} `json:"graph"`
 }
 
 type moduleInfoResponse struct {
@@ -102,6 +128,11 @@ func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package
 		repository = urlparser.Parse("https://github.com/" + resp.UploadOptions.Repository)
 	}
 
+	stable := resp.LatestVersion
+	if core.IsPrerelease(ecosystem, stable) {
+		stable = core.LatestStableOf(ecosystem, resp.Versions)
+	}
+
 	return &core.Package{
 		Name:          resp.Name,
 		Description:   resp.Description,
@@ -111,6 +142,7 @@ func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package
 		Metadata: map[string]any{
 			"upload_type": resp.UploadOptions.Type,
 		},
+		LatestStableVersion: stable,
 	}, nil
 }
 
@@ -125,10 +157,14 @@ func (r *Registry) FetchVersions(ctx context.Context, name string) ([]core.Versi
 		return nil, err
 	}
 
+	// Versions are the git tags the module was published from.
 	versions := make([]core.Version, 0, len(resp.Versions))
 	for _, v := range resp.Versions {
 		versions = append(versions, core.Version{
 			Number: v,
+			Metadata: map[string]any{
+				"tag": v,
+			},
 		})
 	}
 
@@ -136,16 +172,149 @@ func (r *Registry) FetchVersions(ctx context.Context, name string) ([]core.Versi
 }
 
 func (r *Registry) FetchDependencies(ctx context.Context, name, version string) ([]core.Dependency, error) {
-	// Deno modules use URL imports, not a manifest file
-	// Dependencies are determined by analyzing the source code
-	// The API doesn't expose a dependency list directly
-	return nil, nil
+	// Deno modules use URL imports rather than a manifest, so dependencies
+	// come from the module graph deno.land/x builds at publish time.
+	url := fmt.Sprintf("%s/%s/versions/%s/meta/deps_v2.json", r.cdnURL, name, version)
+
+	var resp depsResponse
+	if err := r.client.GetJSON(ctx, url, &resp); err != nil {
+		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
+			return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name, Version: version}
+		}
+		return nil, err
+	}
+
+	nodes := make([]string, 0, len(resp.Graph.Nodes))
+	for node := range resp.Graph.Nodes {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+
+	var deps []core.Dependency
+	seen := make(map[string]bool)
+	for _, node := range nodes {
+		for _, specifier := range resp.Graph.Nodes[node].Deps {
+			depName, requirement, kind := parseSpecifier(specifier)
+			if kind == "deno" && depName == name {
+				continue
+			}
+			key := kind + "\x00" + depName + "\x00" + requirement
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			deps = append(deps, core.Dependency{
+				Name:         depName,
+				Requirements: requirement,
+				Scope:        core.Runtime,
+				Metadata: map[string]any{
+					"kind": kind,
+				},
+			})
+		}
+	}
+
+	return deps, nil
 }
 
-func (r *Registry) FetchMaintainers(ctx context.Context, name string) ([]core.Maintainer, error) {
-	// Deno modules are linked to GitHub repos
-	// Maintainer info would come from the GitHub API
-	return nil, nil
+// parseSpecifier maps an import specifier from the module graph to a package
+// name, version and the registry it comes from: "deno" for deno.land/x and
+// std, "npm" for npm: and esm.sh imports, "jsr" for jsr: imports, and "url"
+// for anything else, in which case the name is the URL itself.
+func parseSpecifier(specifier string) (name, version, kind string) {
+	switch {
+	case strings.HasPrefix(specifier, "npm:"):
+		name, version = splitVersion(strings.TrimPrefix(specifier, "npm:"))
+		return name, version, "npm"
+	case strings.HasPrefix(specifier, "jsr:"):
+		name, version = splitVersion(strings.TrimPrefix(specifier, "jsr:"))
+		return name, version, "jsr"
+	}
+
+	u, err := url.Parse(specifier)
+	if err != nil || u.Host == "" {
+		return specifier, "", "url"
+	}
+	path := strings.TrimPrefix(u.Path, "/")
+
+	switch u.Host {
+	case "deno.land":
+		if strings.HasPrefix(path, "x/") {
+			name, version = splitVersion(strings.TrimPrefix(path, "x/"))
+			return name, version, "deno"
+		}
+		if path == "std" || strings.HasPrefix(path, "std@") || strings.HasPrefix(path, "std/") {
+			_, version = splitVersion(path)
+			return "std", version, "deno"
+		}
+	case "esm.sh":
+		// esm.sh paths may carry a build prefix such as /v135/.
+		if first, rest, ok := strings.Cut(path, "/"); ok && len(first) > 1 && first[0] == 'v' {
+			if _, err := strconv.Atoi(first[1:]); err == nil {
+				path = rest
+			}
+		}
+		name, version = splitVersion(path)
+		return name, version, "npm"
+	}
+
+	return u.Host + u.Path, "", "url"
+}
+
+// splitVersion splits "name@version/sub/path" or "@scope/name@version/sub"
+// into its name and version, dropping any sub path.
+func splitVersion(s string) (string, string) {
+	segments := 1
+	if strings.HasPrefix(s, "@") {
+		segments = 2
+	}
+	parts := strings.SplitN(s, "/", segments+1)
+	if len(parts) > segments {
+		parts = parts[:segments]
+	}
+	head := strings.Join(parts, "/")
+
+	at := strings.LastIndex(head, "@")
+	if at <= 0 {
+		return head, ""
+	}
+	return head[:at], head[at+1:]
+}
+
+// ListModules returns a page of modules from the deno.land/x module index,
+// optionally filtered by a search query. Pages start at 1.
+func (r *Registry) ListModules(ctx context.Context, query string, page int) ([]core.Package, error) {
+	if page < 1 {
+		page = 1
+	}
+	params := url.Values{}
+	params.Set("limit", "100")
+	params.Set("page", strconv.Itoa(page))
+	if query != "" {
+		params.Set("query", query)
+	}
+	listURL := fmt.Sprintf("%s/v2/modules?%s", r.baseURL, params.Encode())
+
+	var resp moduleListResponse
+	if err := r.client.GetJSON(ctx, listURL, &resp); err != nil {
+		return nil, err
+	}
+
+	packages := make([]core.Package, 0, len(resp.Items))
+	for _, m := range resp.Items {
+		packages = append(packages, core.Package{
+			Name:        m.Name,
+			Description: m.Description,
+			Homepage:    fmt.Sprintf("https://deno.land/x/%s", m.Name),
+			Metadata: map[string]any{
+				"stars":            m.StarCount,
+				"popularity_score": m.PopularityScore,
+			},
+			Popularity: &core.Popularity{Stars: int64(m.StarCount)},
+		})
+	}
+
+	return packages, nil
 }
 
 type URLs struct {
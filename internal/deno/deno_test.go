@@ -3,6 +3,7 @@ package deno
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -78,27 +79,116 @@ func TestFetchVersions(t *testing.T) {
 }
 
 func TestFetchDependencies(t *testing.T) {
-	reg := New("", core.DefaultClient())
-	deps, err := reg.FetchDependencies(context.Background(), "oak", "12.6.1")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/oak/versions/v12.6.1/meta/deps_v2.json" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+			w.WriteHeader(404)
+			return
+		}
+		_, _ = w.Write([]byte(`{"graph": {"nodes": {
+			"https://deno.land/x/oak@v12.6.1/mod.ts": {"size": 100, "deps": [
+				"https://deno.land/x/oak@v12.6.1/application.ts",
+				"https://deno.land/std@0.200.0/http/http_status.ts",
+				"https://deno.land/x/path_to_regexp@v6.2.1/index.ts"
+			]},
+			"https://deno.land/x/oak@v12.6.1/application.ts": {"size": 200, "deps": [
+				"https://deno.land/std@0.200.0/io/buffer.ts",
+				"npm:@types/node@^20/fs",
+				"https://esm.sh/v135/preact@10.19.2/hooks",
+				"https://cdn.skypack.dev/lodash"
+			]}
+		}}}`))
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	deps, err := reg.FetchDependencies(context.Background(), "oak", "v12.6.1")
 	if err != nil {
 		t.Fatalf("FetchDependencies failed: %v", err)
 	}
 
-	// Deno doesn't expose dependencies via API
-	if len(deps) != 0 {
-		t.Errorf("expected 0 dependencies, got %d", len(deps))
+	expected := []struct {
+		name, requirements, kind string
+	}{
+		{"std", "0.200.0", "deno"},
+		{"@types/node", "^20", "npm"},
+		{"preact", "10.19.2", "npm"},
+		{"cdn.skypack.dev/lodash", "", "url"},
+		{"path_to_regexp", "v6.2.1", "deno"},
+	}
+	if len(deps) != len(expected) {
+		t.Fatalf("expected %d dependencies, got %d: %+v", len(expected), len(deps), deps)
+	}
+	for i, want := range expected {
+		if deps[i].Name != want.name || deps[i].Requirements != want.requirements || deps[i].Metadata["kind"] != want.kind {
+			t.Errorf("dependency %d: expected %+v, got %+v", i, want, deps[i])
+		}
 	}
 }
 
-func TestFetchMaintainers(t *testing.T) {
-	reg := New("", nil)
-	maintainers, err := reg.FetchMaintainers(context.Background(), "oak")
+func TestParseSpecifier(t *testing.T) {
+	tests := []struct {
+		specifier, name, version, kind string
+	}{
+		{"https://deno.land/x/oak@v12.6.1/mod.ts", "oak", "v12.6.1", "deno"},
+		{"https://deno.land/x/oak/mod.ts", "oak", "", "deno"},
+		{"https://deno.land/std@0.200.0/path/mod.ts", "std", "0.200.0", "deno"},
+		{"npm:express@4", "express", "4", "npm"},
+		{"npm:@types/node@^20/fs", "@types/node", "^20", "npm"},
+		{"jsr:@std/path@^1.0.0", "@std/path", "^1.0.0", "jsr"},
+		{"https://esm.sh/react@18.2.0", "react", "18.2.0", "npm"},
+		{"https://esm.sh/v135/@preact/signals@1.2.1/dist/index.js", "@preact/signals", "1.2.1", "npm"},
+		{"https://raw.githubusercontent.com/user/repo/main/mod.ts", "raw.githubusercontent.com/user/repo/main/mod.ts", "", "url"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.specifier, func(t *testing.T) {
+			name, version, kind := parseSpecifier(tt.specifier)
+			if name != tt.name || version != tt.version || kind != tt.kind {
+				t.Errorf("parseSpecifier(%q) = %q, %q, %q; want %q, %q, %q", tt.specifier, name, version, kind, tt.name, tt.version, tt.kind)
+			}
+		})
+	}
+}
+
+func TestListModules(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/modules" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+			w.WriteHeader(404)
+			return
+		}
+		if r.URL.Query().Get("page") != "2" || r.URL.Query().Get("query") != "http" {
+			t.Errorf("unexpected query: %s", r.URL.RawQuery)
+		}
+		_, _ = w.Write([]byte(`{"items": [
+			{"name": "oak", "description": "A middleware framework", "star_count": 5000, "popularity_score": 12000},
+			{"name": "hono", "description": "Web framework", "star_count": 4000}
+		]}`))
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	modules, err := reg.ListModules(context.Background(), "http", 2)
 	if err != nil {
-		t.Fatalf("FetchMaintainers failed: %v", err)
+		t.Fatalf("ListModules failed: %v", err)
+	}
+
+	if len(modules) != 2 {
+		t.Fatalf("expected 2 modules, got %d", len(modules))
+	}
+	if modules[0].Name != "oak" || modules[0].Metadata["stars"] != 5000 {
+		t.Errorf("unexpected first module: %+v", modules[0])
+	}
+	if modules[1].Homepage != "https://deno.land/x/hono" {
+		t.Errorf("unexpected homepage: %q", modules[1].Homepage)
 	}
+}
 
-	if len(maintainers) != 0 {
-		t.Errorf("expected 0 maintainers, got %d", len(maintainers))
+func TestFetchMaintainers(t *testing.T) {
+	_, err := core.FetchMaintainers(context.Background(), New("", nil), "oak")
+	if !errors.Is(err, core.ErrNotSupported) {
+		t.Errorf("expected ErrNotSupported, got %v", err)
 	}
 }
 
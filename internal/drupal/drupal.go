@@ -0,0 +1,345 @@
+// Package drupal provides a registry client for Drupal.org projects.
+package drupal
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+const (
+	DefaultURL  = "https://www.drupal.org"
+	UpdatesURL  = "https://updates.drupal.org"
+	PackagesURL = "https://packages.drupal.org"
+	ecosystem   = "drupal"
+)
+
+func init() {
+	core.Register(ecosystem, DefaultURL, func(baseURL string, client *core.Client) core.Registry {
+		return New(baseURL, client)
+	})
+}
+
+// Registry reads project metadata from the drupal.org API, releases from the
+// update status feed and dependencies from the Composer repository. A mirror
+// is expected to serve all three from the same host.
+type Registry struct {
+	baseURL     string
+	updatesURL  string
+	packagesURL string
+	client      *core.Client
+	urls        *URLs
+}
+
+func New(baseURL string, client *core.Client) *Registry {
+	if baseURL == "" {
+		baseURL = DefaultURL
+	}
+	r := &Registry{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client:  client,
+	}
+	r.updatesURL, r.packagesURL = r.baseURL, r.baseURL
+	if r.baseURL == DefaultURL {
+		r.updatesURL, r.packagesURL = UpdatesURL, PackagesURL
+	}
+	r.urls = &URLs{baseURL: r.baseURL}
+	return r
+}
+
+func (r *Registry) Ecosystem() string {
+	return ecosystem
+}
+
+func (r *Registry) URLs() core.URLBuilder {
+	return r.urls
+}
+
+type nodeListResponse struct {
+	List []nodeResponse `json:"list"`
+}
+
+type nodeResponse struct {
+	Title string `json:"title"`
+	Body  struct {
+		Value   string `json:"value"`
+		Summary string `json:"summary"`
+	} `json:"body"`
+	MachineName      string `json:"field_project_machine_name"`
+	Type             string `json:"type"`
+	URL              string `json:"url"`
+	Created          string `json:"created"`
+	Changed          string `json:"changed"`
+	SecurityCoverage string `json:"field_security_advisory_coverage"`
+	ProjectType      string `json:"field_project_type"`
+}
+
+// releaseHistory is the update status feed used by Drupal's Update Manager.
+type releaseHistory struct {
+	XMLName           xml.Name  `xml:"project"`
+	Title             string    `xml:"title"`
+	ShortName         string    `xml:"short_name"`
+	Type              string    `xml:"type"`
+	SupportedBranches string    `xml:"supported_branches"`
+	Releases          []release `xml:"releases>release"`
+}
+
+type release struct {
+	Name              string `xml:"name"`
+	Version           string `xml:"version"`
+	Tag               string `xml:"tag"`
+	Status            string `xml:"status"`
+	ReleaseLink       string `xml:"release_link"`
+	DownloadLink      string `xml:"download_link"`
+	Date              int64  `xml:"date"`
+	MDHash            string `xml:"mdhash"`
+	Filesize          int64  `xml:"filesize"`
+	CoreCompatibility string `xml:"core_compatibility"`
+	Security          struct {
+		Covered string `xml:"covered,attr"`
+	} `xml:"security"`
+	Terms []struct {
+		Name  string `xml:"name"`
+		Value string `xml:"value"`
+	} `xml:"terms>term"`
+}
+
+func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package, error) {
+	params := url.Values{}
+	params.Set("field_project_machine_name", name)
+	nodeURL := fmt.Sprintf("%s/api-d7/node.json?%s", r.baseURL, params.Encode())
+
+	var resp nodeListResponse
+	if err := r.client.GetJSON(ctx, nodeURL, &resp); err != nil {
+		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
+			return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name}
+		}
+		return nil, err
+	}
+	if len(resp.List) == 0 {
+		return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name}
+	}
+	node := resp.List[0]
+
+	description := node.Body.Summary
+	if description == "" {
+		description = node.Body.Value
+	}
+
+	metadata := map[string]any{
+		"title":        node.Title,
+		"project_type": strings.TrimPrefix(node.Type, "project_"),
+	}
+	if node.SecurityCoverage != "" {
+		metadata["security_advisory_coverage"] = node.SecurityCoverage
+	}
+	if node.ProjectType == "sandbox" {
+		metadata["sandbox"] = true
+	}
+
+	return &core.Package{
+		Name:        node.MachineName,
+		Description: strings.TrimSpace(stripTags(description)),
+		Homepage:    r.urls.Registry(node.MachineName, ""),
+		Repository:  fmt.Sprintf("https://git.drupalcode.org/project/%s", node.MachineName),
+		Metadata:    metadata,
+	}, nil
+}
+
+func (r *Registry) fetchReleases(ctx context.Context, name string) (*releaseHistory, error) {
+	historyURL := fmt.Sprintf("%s/release-history/%s/current", r.updatesURL, name)
+
+	body, err := r.client.GetBody(ctx, historyURL)
+	if err != nil {
+		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
+			return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name}
+		}
+		return nil, err
+	}
+
+	// Unknown projects get a 200 with an <error> document
+	var history releaseHistory
+	if err := xml.Unmarshal(body, &history); err != nil {
+		return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name}
+	}
+	return &history, nil
+}
+
+// FetchVersions returns published releases from the update status feed,
+// newest first, with the range of Drupal core each is compatible with.
+func (r *Registry) FetchVersions(ctx context.Context, name string) ([]core.Version, error) {
+	history, err := r.fetchReleases(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]core.Version, 0, len(history.Releases))
+	for _, rel := range history.Releases {
+		if rel.Status != "" && rel.Status != "published" {
+			continue
+		}
+
+		metadata := map[string]any{
+			"download_url": rel.DownloadLink,
+			"release_url":  rel.ReleaseLink,
+		}
+		if rel.CoreCompatibility != "" {
+			metadata["core_compatibility"] = rel.CoreCompatibility
+		}
+		if rel.MDHash != "" {
+			metadata["md5"] = rel.MDHash
+		}
+		if rel.Security.Covered != "" {
+			metadata["security_covered"] = rel.Security.Covered == "1"
+		}
+		var releaseTypes []string
+		for _, term := range rel.Terms {
+			if term.Name == "Release type" {
+				releaseTypes = append(releaseTypes, term.Value)
+			}
+		}
+		if len(releaseTypes) > 0 {
+			metadata["release_types"] = releaseTypes
+		}
+
+		var publishedAt time.Time
+		if rel.Date > 0 {
+			publishedAt = time.Unix(rel.Date, 0).UTC()
+		}
+
+		versions = append(versions, core.Version{
+			Number:      rel.Version,
+			PublishedAt: publishedAt,
+			Metadata:    metadata,
+			Size:        rel.Filesize,
+		})
+	}
+
+	return versions, nil
+}
+
+// composerResponse is a Composer v2 metadata file. Entries are minified: each
+// one only lists the fields that changed from the previous entry, and
+// "__unset" removes a field.
+type composerResponse struct {
+	Packages map[string][]map[string]json.RawMessage `json:"packages"`
+}
+
+func (r *Registry) FetchDependencies(ctx context.Context, name, version string) ([]core.Dependency, error) {
+	composerURL := fmt.Sprintf("%s/files/packages/8/p2/drupal/%s.json", r.packagesURL, name)
+
+	var resp composerResponse
+	if err := r.client.GetJSON(ctx, composerURL, &resp); err != nil {
+		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
+			return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name, Version: version}
+		}
+		return nil, err
+	}
+
+	target := composerVersion(version)
+	current := map[string]json.RawMessage{}
+	for _, entry := range resp.Packages["drupal/"+name] {
+		for key, value := range entry {
+			if string(value) == `"__unset"` {
+				delete(current, key)
+			} else {
+				current[key] = value
+			}
+		}
+
+		var v string
+		_ = json.Unmarshal(current["version"], &v)
+		if v != target && v != version {
+			continue
+		}
+
+		var require, requireDev map[string]string
+		_ = json.Unmarshal(current["require"], &require)
+		_ = json.Unmarshal(current["require-dev"], &requireDev)
+
+		var deps []core.Dependency
+		deps = append(deps, composerDependencies(require, core.Runtime)...)
+		deps = append(deps, composerDependencies(requireDev, core.Development)...)
+		return deps, nil
+	}
+
+	return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name, Version: version}
+}
+
+func composerDependencies(require map[string]string, scope core.Scope) []core.Dependency {
+	names := make([]string, 0, len(require))
+	for depName := range require {
+		// Skip PHP and extension requirements
+		if depName == "php" || strings.HasPrefix(depName, "ext-") {
+			continue
+		}
+		names = append(names, depName)
+	}
+	sort.Strings(names)
+
+	deps := make([]core.Dependency, 0, len(names))
+	for _, depName := range names {
+		deps = append(deps, core.Dependency{
+			Name:         depName,
+			Requirements: require[depName],
+			Scope:        scope,
+		})
+	}
+	return deps
+}
+
+var legacyVersion = regexp.MustCompile(`^\d+\.x-(\d+)\.(\d+)(.*)$`)
+
+// composerVersion maps a drupal.org release version onto the version the
+// Composer repository publishes it under: "8.x-1.15" becomes "1.15.0" and
+// "8.x-2.0-rc1" becomes "2.0.0-rc1". Semantic versions are unchanged.
+func composerVersion(version string) string {
+	m := legacyVersion.FindStringSubmatch(version)
+	if m == nil {
+		return version
+	}
+	return fmt.Sprintf("%s.%s.0%s", m[1], m[2], m[3])
+}
+
+var tagPattern = regexp.MustCompile(`<[^>]*>`)
+
+func stripTags(s string) string {
+	return tagPattern.ReplaceAllString(s, "")
+}
+
+type URLs struct {
+	baseURL string
+}
+
+func (u *URLs) Registry(name, version string) string {
+	if version != "" {
+		return fmt.Sprintf("https://www.drupal.org/project/%s/releases/%s", name, version)
+	}
+	return fmt.Sprintf("https://www.drupal.org/project/%s", name)
+}
+
+func (u *URLs) Download(name, version string) string {
+	if version == "" {
+		return ""
+	}
+	return fmt.Sprintf("https://ftp.drupal.org/files/projects/%s-%s.tar.gz", name, version)
+}
+
+func (u *URLs) Documentation(name, version string) string {
+	return fmt.Sprintf("https://www.drupal.org/project/%s", name)
+}
+
+func (u *URLs) PURL(name, version string) string {
+	if version != "" {
+		return fmt.Sprintf("pkg:drupal/%s@%s", name, version)
+	}
+	return fmt.Sprintf("pkg:drupal/%s", name)
+}
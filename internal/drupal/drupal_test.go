@@ -0,0 +1,242 @@
+package drupal
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+const tokenReleases = `<?xml version="1.0" encoding="utf-8"?>
+<project xmlns:dc="http://purl.org/dc/elements/1.1/">
+<title>Token</title>
+<short_name>token</short_name>
+<type>project_module</type>
+<supported_branches>8.x-1.</supported_branches>
+<releases>
+ <release>
+  <name>token 8.x-1.15</name>
+  <version>8.x-1.15</version>
+  <tag>8.x-1.15</tag>
+  <status>published</status>
+  <release_link>https://www.drupal.org/project/token/releases/8.x-1.15</release_link>
+  <download_link>https://ftp.drupal.org/files/projects/token-8.x-1.15.tar.gz</download_link>
+  <date>1719404129</date>
+  <mdhash>0f3c8ea8b8ed2a3e8b3c8d6f4b2a1c9d</mdhash>
+  <filesize>84321</filesize>
+  <terms><term><name>Release type</name><value>Bug fixes</value></term></terms>
+  <security covered="1">Covered by Drupal's security advisory policy</security>
+  <core_compatibility>^9.2 || ^10 || ^11</core_compatibility>
+ </release>
+ <release>
+  <name>token 8.x-1.14</name>
+  <version>8.x-1.14</version>
+  <status>unpublished</status>
+  <date>1710000000</date>
+ </release>
+ <release>
+  <name>token 8.x-1.13</name>
+  <version>8.x-1.13</version>
+  <status>published</status>
+  <date>1700000000</date>
+  <terms><term><name>Release type</name><value>Security update</value></term></terms>
+  <core_compatibility>^9.2 || ^10</core_compatibility>
+ </release>
+</releases>
+</project>`
+
+func TestFetchPackage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api-d7/node.json" || r.URL.Query().Get("field_project_machine_name") != "token" {
+			t.Errorf("unexpected request: %s", r.URL)
+			w.WriteHeader(404)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"list": [{
+			"title": "Token",
+			"body": {"value": "<p>Provides a user interface for the Token API.</p>", "summary": ""},
+			"field_project_machine_name": "token",
+			"field_project_type": "full",
+			"field_security_advisory_coverage": "covered",
+			"type": "project_module",
+			"url": "https://www.drupal.org/project/token"
+		}]}`))
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	pkg, err := reg.FetchPackage(context.Background(), "token")
+	if err != nil {
+		t.Fatalf("FetchPackage failed: %v", err)
+	}
+
+	if pkg.Name != "token" {
+		t.Errorf("expected name 'token', got %q", pkg.Name)
+	}
+	if pkg.Description != "Provides a user interface for the Token API." {
+		t.Errorf("unexpected description: %q", pkg.Description)
+	}
+	if pkg.Repository != "https://git.drupalcode.org/project/token" {
+		t.Errorf("unexpected repository: %q", pkg.Repository)
+	}
+	if pkg.Metadata["project_type"] != "module" || pkg.Metadata["security_advisory_coverage"] != "covered" {
+		t.Errorf("unexpected metadata: %v", pkg.Metadata)
+	}
+}
+
+func TestFetchPackageNotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api-d7/node.json":
+			_, _ = w.Write([]byte(`{"list": []}`))
+		default:
+			_, _ = w.Write([]byte(`<?xml version="1.0" encoding="utf-8"?>
+<error>No release history was found for the requested project (nonexistent).</error>`))
+		}
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	if _, err := reg.FetchPackage(context.Background(), "nonexistent"); !errors.Is(err, core.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	if _, err := reg.FetchVersions(context.Background(), "nonexistent"); !errors.Is(err, core.ErrNotFound) {
+		t.Errorf("expected ErrNotFound from FetchVersions, got %v", err)
+	}
+}
+
+func TestFetchVersions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/release-history/token/current" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+			w.WriteHeader(404)
+			return
+		}
+		w.Header().Set("Content-Type", "application/xml")
+		_, _ = w.Write([]byte(tokenReleases))
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	versions, err := reg.FetchVersions(context.Background(), "token")
+	if err != nil {
+		t.Fatalf("FetchVersions failed: %v", err)
+	}
+
+	if len(versions) != 2 {
+		t.Fatalf("expected 2 published versions, got %d", len(versions))
+	}
+	latest := versions[0]
+	if latest.Number != "8.x-1.15" {
+		t.Errorf("unexpected version: %q", latest.Number)
+	}
+	if !latest.PublishedAt.Equal(time.Unix(1719404129, 0)) {
+		t.Errorf("unexpected published_at: %v", latest.PublishedAt)
+	}
+	if latest.Metadata["core_compatibility"] != "^9.2 || ^10 || ^11" {
+		t.Errorf("unexpected core compatibility: %v", latest.Metadata["core_compatibility"])
+	}
+	if latest.Metadata["security_covered"] != true {
+		t.Errorf("expected security coverage, got %v", latest.Metadata["security_covered"])
+	}
+	types, _ := versions[1].Metadata["release_types"].([]string)
+	if len(types) != 1 || types[0] != "Security update" {
+		t.Errorf("unexpected release types: %v", versions[1].Metadata["release_types"])
+	}
+}
+
+func TestFetchDependencies(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/files/packages/8/p2/drupal/token.json" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+			w.WriteHeader(404)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"minified": "composer/2.0", "packages": {"drupal/token": [
+			{"name": "drupal/token", "version": "1.15.0", "require": {"drupal/core": "^9.2 || ^10 || ^11", "php": ">=8.1"}, "require-dev": {"drupal/metatag": "*"}},
+			{"version": "1.14.0", "require-dev": "__unset"},
+			{"version": "1.13.0", "require": {"drupal/core": "^9.2 || ^10"}}
+		]}}`))
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+
+	deps, err := reg.FetchDependencies(context.Background(), "token", "8.x-1.15")
+	if err != nil {
+		t.Fatalf("FetchDependencies failed: %v", err)
+	}
+	if len(deps) != 2 {
+		t.Fatalf("expected 2 dependencies, got %d: %+v", len(deps), deps)
+	}
+	if deps[0].Name != "drupal/core" || deps[0].Requirements != "^9.2 || ^10 || ^11" || deps[0].Scope != core.Runtime {
+		t.Errorf("unexpected runtime dependency: %+v", deps[0])
+	}
+	if deps[1].Name != "drupal/metatag" || deps[1].Scope != core.Development {
+		t.Errorf("unexpected development dependency: %+v", deps[1])
+	}
+
+	// 1.14.0 inherits require from the previous entry and unsets require-dev
+	deps, err = reg.FetchDependencies(context.Background(), "token", "8.x-1.14")
+	if err != nil {
+		t.Fatalf("FetchDependencies failed: %v", err)
+	}
+	if len(deps) != 1 || deps[0].Requirements != "^9.2 || ^10 || ^11" {
+		t.Errorf("unexpected dependencies for 8.x-1.14: %+v", deps)
+	}
+
+	if _, err := reg.FetchDependencies(context.Background(), "token", "8.x-0.1"); !errors.Is(err, core.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for an unknown version, got %v", err)
+	}
+}
+
+func TestComposerVersion(t *testing.T) {
+	tests := map[string]string{
+		"8.x-1.15":    "1.15.0",
+		"8.x-2.0-rc1": "2.0.0-rc1",
+		"7.x-3.4":     "3.4.0",
+		"2.0.3":       "2.0.3",
+	}
+	for in, want := range tests {
+		if got := composerVersion(in); got != want {
+			t.Errorf("composerVersion(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestURLBuilder(t *testing.T) {
+	reg := New("", nil)
+	urls := reg.URLs()
+
+	tests := []struct {
+		name     string
+		fn       func() string
+		expected string
+	}{
+		{"registry", func() string { return urls.Registry("token", "") }, "https://www.drupal.org/project/token"},
+		{"registry with version", func() string { return urls.Registry("token", "8.x-1.15") }, "https://www.drupal.org/project/token/releases/8.x-1.15"},
+		{"download", func() string { return urls.Download("token", "8.x-1.15") }, "https://ftp.drupal.org/files/projects/token-8.x-1.15.tar.gz"},
+		{"purl", func() string { return urls.PURL("token", "8.x-1.15") }, "pkg:drupal/token@8.x-1.15"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.fn(); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestEcosystem(t *testing.T) {
+	reg := New("", nil)
+	if reg.Ecosystem() != "drupal" {
+		t.Errorf("expected ecosystem 'drupal', got %q", reg.Ecosystem())
+	}
+}
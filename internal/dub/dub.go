@@ -84,6 +84,14 @@ func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package
 		license = resp.Versions[0].License
 	}
 
+	// Branch versions such as "~master" aren't releases
+	var releases []string
+	for _, v := range resp.Versions {
+		if !strings.HasPrefix(v.Version, "~") {
+			releases = append(releases, v.Version)
+		}
+	}
+
 	// Extract repository URL
 	repository := urlparser.Parse(resp.Repository)
 	if repository == "" {
@@ -91,16 +99,20 @@ func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package
 	}
 
 	return &core.Package{
-		Name:        resp.Name,
-		Description: resp.Description,
-		Homepage:    resp.Homepage,
-		Repository:  repository,
-		Licenses:    license,
-		Keywords:    resp.Categories,
+		Name:          resp.Name,
+		Description:   resp.Description,
+		Homepage:      resp.Homepage,
+		Repository:    repository,
+		Documentation: resp.DocumentationURL,
+		Licenses:      license,
+		Keywords:      resp.Categories,
+		Categories:    resp.Categories,
 		Metadata: map[string]any{
 			"owner":            resp.Owner,
 			"documentation_url": resp.DocumentationURL,
 		},
+		LatestVersion:       core.LatestOf(ecosystem, releases),
+		LatestStableVersion: core.LatestStableOf(ecosystem, releases),
 	}, nil
 }
 
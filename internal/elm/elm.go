@@ -121,6 +121,8 @@ func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package
 			"elm_version": elmInfo.ElmVersion,
 			"type":        elmInfo.Type,
 		},
+		// Elm versions are plain MAJOR.MINOR.PATCH with no pre-releases
+		LatestVersion: latestVersion,
 	}, nil
 }
 
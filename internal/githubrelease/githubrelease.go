@@ -0,0 +1,383 @@
+// Package githubrelease provides a registry client that treats a GitHub
+// repository's releases as package versions. Carthage, and other tools that
+// install binary frameworks or prebuilt tools, resolve dependencies this way.
+package githubrelease
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+const (
+	DefaultURL = "https://api.github.com"
+	RawURL     = "https://raw.githubusercontent.com"
+	ecosystem  = "github-release"
+
+	// perPage is the largest page the releases API returns.
+	perPage = 100
+	// maxPages bounds how many release pages FetchVersions follows.
+	maxPages = 10
+)
+
+func init() {
+	core.Register(ecosystem, DefaultURL, func(baseURL string, client *core.Client) core.Registry {
+		return New(baseURL, client)
+	})
+}
+
+// Registry reads repository metadata and releases from the GitHub API, and
+// Cartfiles from raw file hosting. GitHub Enterprise Server is supported by
+// passing its API root (https://github.example.com/api/v3) as the base URL.
+type Registry struct {
+	baseURL string
+	rawURL  string
+	client  *core.Client
+	urls    *URLs
+}
+
+func New(baseURL string, client *core.Client) *Registry {
+	if baseURL == "" {
+		baseURL = DefaultURL
+	}
+	r := &Registry{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client:  client,
+	}
+	switch {
+	case r.baseURL == DefaultURL:
+		r.rawURL = RawURL
+	case strings.HasSuffix(r.baseURL, "/api/v3"):
+		r.rawURL = strings.TrimSuffix(r.baseURL, "/api/v3") + "/raw"
+	default:
+		r.rawURL = r.baseURL
+	}
+	r.urls = &URLs{baseURL: r.baseURL}
+	return r
+}
+
+func (r *Registry) Ecosystem() string {
+	return ecosystem
+}
+
+func (r *Registry) URLs() core.URLBuilder {
+	return r.urls
+}
+
+type repoResponse struct {
+	FullName    string   `json:"full_name"`
+	Description string   `json:"description"`
+	Homepage    string   `json:"homepage"`
+	HTMLURL     string   `json:"html_url"`
+	Archived    bool     `json:"archived"`
+	Stars       int      `json:"stargazers_count"`
+	Topics      []string `json:"topics"`
+	License     *struct {
+		SPDXID string `json:"spdx_id"`
+	} `json:"license"`
+	Owner struct {
+		Login   string `json:"login"`
+		Type    string `json:"type"`
+		HTMLURL string `json:"html_url"`
+	} `json:"owner"`
+}
+
+type releaseResponse struct {
+	TagName     string          `json:"tag_name"`
+	Name        string          `json:"name"`
+	Draft       bool            `json:"draft"`
+	Prerelease  bool            `json:"prerelease"`
+	PublishedAt time.Time       `json:"published_at"`
+	HTMLURL     string          `json:"html_url"`
+	Assets      []assetResponse `json:"assets"`
+	Author      struct {
+		Login   string `json:"login"`
+		HTMLURL string `json:"html_url"`
+	} `json:"author"`
+}
+
+type assetResponse struct {
+	Name               string `json:"name"`
+	ContentType        string `json:"content_type"`
+	Size               int64  `json:"size"`
+	Digest             string `json:"digest"`
+	BrowserDownloadURL string `json:"browser_download_url"`
+}
+
+// splitName splits "owner/repo" into its parts.
+func splitName(name string) (string, string, error) {
+	owner, repo, ok := strings.Cut(name, "/")
+	if !ok || owner == "" || repo == "" || strings.Contains(repo, "/") {
+		return "", "", fmt.Errorf("github-release package name must be owner/repo: %q", name)
+	}
+	return owner, repo, nil
+}
+
+func (r *Registry) fetchRepo(ctx context.Context, name string) (*repoResponse, error) {
+	owner, repo, err := splitName(name)
+	if err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("%s/repos/%s/%s", r.baseURL, owner, repo)
+
+	var resp repoResponse
+	if err := r.client.GetJSON(ctx, url, &resp); err != nil {
+		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
+			return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name}
+		}
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package, error) {
+	repo, err := r.fetchRepo(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	var licenses string
+	if repo.License != nil && repo.License.SPDXID != "NOASSERTION" {
+		licenses = repo.License.SPDXID
+	}
+
+	homepage := repo.Homepage
+	if homepage == "" {
+		homepage = repo.HTMLURL
+	}
+
+	// The latest release excludes drafts and prereleases. Repositories
+	// without releases return 404, which isn't an error here.
+	var latest releaseResponse
+	latestURL := fmt.Sprintf("%s/repos/%s/releases/latest", r.baseURL, repo.FullName)
+	if err := r.client.GetJSON(ctx, latestURL, &latest); err != nil {
+		if httpErr, ok := err.(*core.HTTPError); !ok || !httpErr.IsNotFound() {
+			return nil, err
+		}
+	}
+
+	return &core.Package{
+		Name:          repo.FullName,
+		Description:   repo.Description,
+		Homepage:      homepage,
+		Repository:    repo.HTMLURL,
+		Licenses:      licenses,
+		Keywords:      repo.Topics,
+		Namespace:     repo.Owner.Login,
+		LatestVersion: latest.TagName,
+		Metadata: map[string]any{
+			"archived": repo.Archived,
+			"stars":    repo.Stars,
+		},
+		Popularity:          &core.Popularity{Stars: int64(repo.Stars)},
+		LatestStableVersion: latest.TagName,
+	}, nil
+}
+
+// FetchVersions returns published releases, newest first, with their assets
+// in Metadata["assets"]. Drafts are skipped; prereleases are included and
+// flagged in Metadata["prerelease"].
+func (r *Registry) FetchVersions(ctx context.Context, name string) ([]core.Version, error) {
+	owner, repo, err := splitName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []core.Version
+	for page := 1; page <= maxPages; page++ {
+		url := fmt.Sprintf("%s/repos/%s/%s/releases?per_page=%d&page=%d", r.baseURL, owner, repo, perPage, page)
+
+		var releases []releaseResponse
+		if err := r.client.GetJSON(ctx, url, &releases); err != nil {
+			if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
+				return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name}
+			}
+			return nil, err
+		}
+
+		for _, rel := range releases {
+			if rel.Draft {
+				continue
+			}
+			versions = append(versions, versionFromRelease(rel))
+		}
+
+		if len(releases) < perPage {
+			break
+		}
+	}
+
+	return versions, nil
+}
+
+func versionFromRelease(rel releaseResponse) core.Version {
+	assets := make([]map[string]any, 0, len(rel.Assets))
+	for _, a := range rel.Assets {
+		asset := map[string]any{
+			"name":         a.Name,
+			"url":          a.BrowserDownloadURL,
+			"size":         a.Size,
+			"content_type": a.ContentType,
+		}
+		// GitHub reports digests as "sha256:<hex>"
+		if in, err := core.ParseIntegrity(a.Digest); err == nil {
+			asset["integrity"] = in.String()
+		}
+		assets = append(assets, asset)
+	}
+
+	v := core.Version{
+		Number:      rel.TagName,
+		PublishedAt: rel.PublishedAt,
+		Metadata: map[string]any{
+			"name":       rel.Name,
+			"url":        rel.HTMLURL,
+			"prerelease": rel.Prerelease,
+			"assets":     assets,
+		},
+	}
+	if rel.Author.Login != "" {
+		v.Publisher = &core.Maintainer{
+			Login: rel.Author.Login,
+			URL:   rel.Author.HTMLURL,
+		}
+	}
+	return v
+}
+
+// FetchDependencies reads the Cartfile committed at the release tag. Entries
+// from Cartfile.private, which Carthage only builds for the repository
+// itself, are returned with the development scope. Repositories without a
+// Cartfile have no dependencies.
+func (r *Registry) FetchDependencies(ctx context.Context, name, version string) ([]core.Dependency, error) {
+	owner, repo, err := splitName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var deps []core.Dependency
+	for _, file := range []struct {
+		name  string
+		scope core.Scope
+	}{
+		{"Cartfile", core.Runtime},
+		{"Cartfile.private", core.Development},
+	} {
+		url := fmt.Sprintf("%s/%s/%s/%s/%s", r.rawURL, owner, repo, version, file.name)
+		body, err := r.client.GetText(ctx, url)
+		if err != nil {
+			if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
+				continue
+			}
+			return nil, err
+		}
+		deps = append(deps, parseCartfile(body, file.scope)...)
+	}
+
+	return deps, nil
+}
+
+// parseCartfile parses Cartfile lines of the form
+//
+//	github "ReactiveX/RxSwift" ~> 6.0
+//	git "https://example.com/lib.git" "branch"
+//	binary "https://example.com/Framework.json" >= 1.0
+//
+// github dependencies are named owner/repo, others by their URL.
+func parseCartfile(body string, scope core.Scope) []core.Dependency {
+	var deps []core.Dependency
+	for _, line := range strings.Split(body, "\n") {
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		origin, rest, ok := strings.Cut(line, " ")
+		if !ok {
+			continue
+		}
+		rest = strings.TrimSpace(rest)
+		if !strings.HasPrefix(rest, `"`) {
+			continue
+		}
+		end := strings.Index(rest[1:], `"`)
+		if end < 0 {
+			continue
+		}
+		identifier := rest[1 : end+1]
+		requirement := strings.TrimSpace(rest[end+2:])
+		// A quoted requirement names a branch, tag or commit
+		requirement = strings.Trim(requirement, `"`)
+
+		if origin == "github" {
+			identifier = strings.TrimSuffix(strings.TrimPrefix(identifier, "https://github.com/"), ".git")
+		}
+
+		deps = append(deps, core.Dependency{
+			Name:         identifier,
+			Requirements: requirement,
+			Scope:        scope,
+			Metadata: map[string]any{
+				"origin": origin,
+			},
+		})
+	}
+	return deps
+}
+
+func (r *Registry) FetchMaintainers(ctx context.Context, name string) ([]core.Maintainer, error) {
+	repo, err := r.fetchRepo(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return []core.Maintainer{{
+		Login: repo.Owner.Login,
+		URL:   repo.Owner.HTMLURL,
+		Role:  strings.ToLower(repo.Owner.Type),
+	}}, nil
+}
+
+type URLs struct {
+	baseURL string
+}
+
+// webURL returns the web host matching the API: github.com for api.github.com,
+// the Enterprise Server host otherwise.
+func (u *URLs) webURL() string {
+	if u.baseURL == DefaultURL {
+		return "https://github.com"
+	}
+	return strings.TrimSuffix(u.baseURL, "/api/v3")
+}
+
+func (u *URLs) Registry(name, version string) string {
+	if version != "" {
+		return fmt.Sprintf("%s/%s/releases/tag/%s", u.webURL(), name, version)
+	}
+	return fmt.Sprintf("%s/%s/releases", u.webURL(), name)
+}
+
+func (u *URLs) Download(name, version string) string {
+	if version == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s/%s/archive/refs/tags/%s.tar.gz", u.webURL(), name, version)
+}
+
+func (u *URLs) Documentation(name, version string) string {
+	return fmt.Sprintf("%s/%s", u.webURL(), name)
+}
+
+func (u *URLs) PURL(name, version string) string {
+	if version != "" {
+		return fmt.Sprintf("pkg:github-release/%s@%s", name, version)
+	}
+	return fmt.Sprintf("pkg:github-release/%s", name)
+}
@@ -0,0 +1,284 @@
+package githubrelease
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+const repoJSON = `{
+	"full_name": "ReactiveX/RxSwift",
+	"description": "Reactive Programming in Swift",
+	"homepage": "",
+	"html_url": "https://github.com/ReactiveX/RxSwift",
+	"archived": false,
+	"stargazers_count": 24000,
+	"topics": ["swift", "rxswift"],
+	"license": {"spdx_id": "MIT"},
+	"owner": {"login": "ReactiveX", "type": "Organization", "html_url": "https://github.com/ReactiveX"}
+}`
+
+const releaseJSON = `{
+	"tag_name": "6.8.0",
+	"name": "RxSwift 6.8.0",
+	"draft": false,
+	"prerelease": false,
+	"published_at": "2024-08-01T12:00:00Z",
+	"html_url": "https://github.com/ReactiveX/RxSwift/releases/tag/6.8.0",
+	"author": {"login": "freak4pc", "html_url": "https://github.com/freak4pc"},
+	"assets": [{
+		"name": "RxSwift.xcframework.zip",
+		"content_type": "application/zip",
+		"size": 1048576,
+		"digest": "sha256:ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad",
+		"browser_download_url": "https://github.com/ReactiveX/RxSwift/releases/download/6.8.0/RxSwift.xcframework.zip"
+	}]
+}`
+
+func TestFetchPackage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/repos/ReactiveX/RxSwift":
+			_, _ = w.Write([]byte(repoJSON))
+		case "/repos/ReactiveX/RxSwift/releases/latest":
+			_, _ = w.Write([]byte(releaseJSON))
+		default:
+			t.Errorf("unexpected path: %s", r.URL.Path)
+			w.WriteHeader(404)
+		}
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	pkg, err := reg.FetchPackage(context.Background(), "ReactiveX/RxSwift")
+	if err != nil {
+		t.Fatalf("FetchPackage failed: %v", err)
+	}
+
+	if pkg.Name != "ReactiveX/RxSwift" {
+		t.Errorf("expected name 'ReactiveX/RxSwift', got %q", pkg.Name)
+	}
+	if pkg.Homepage != "https://github.com/ReactiveX/RxSwift" {
+		t.Errorf("expected homepage to fall back to the repository, got %q", pkg.Homepage)
+	}
+	if pkg.Licenses != "MIT" {
+		t.Errorf("unexpected licenses: %q", pkg.Licenses)
+	}
+	if pkg.LatestVersion != "6.8.0" {
+		t.Errorf("unexpected latest version: %q", pkg.LatestVersion)
+	}
+	if pkg.Namespace != "ReactiveX" {
+		t.Errorf("unexpected namespace: %q", pkg.Namespace)
+	}
+}
+
+func TestFetchPackageWithoutReleases(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/repos/ReactiveX/RxSwift" {
+			_, _ = w.Write([]byte(repoJSON))
+			return
+		}
+		w.WriteHeader(404)
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	pkg, err := reg.FetchPackage(context.Background(), "ReactiveX/RxSwift")
+	if err != nil {
+		t.Fatalf("FetchPackage failed: %v", err)
+	}
+	if pkg.LatestVersion != "" {
+		t.Errorf("expected no latest version, got %q", pkg.LatestVersion)
+	}
+}
+
+func TestFetchPackageNotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(404)
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	if _, err := reg.FetchPackage(context.Background(), "nobody/nothing"); !errors.Is(err, core.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	if _, err := reg.FetchPackage(context.Background(), "RxSwift"); err == nil {
+		t.Error("expected an error for a name without an owner")
+	}
+}
+
+func TestFetchVersions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/ReactiveX/RxSwift/releases" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+			w.WriteHeader(404)
+			return
+		}
+		// A full first page, then a short second page
+		var releases []string
+		switch r.URL.Query().Get("page") {
+		case "1":
+			releases = append(releases, releaseJSON, `{"tag_name": "7.0.0-beta", "draft": true}`)
+			for i := len(releases); i < perPage; i++ {
+				releases = append(releases, fmt.Sprintf(`{"tag_name": "6.%d.0", "prerelease": %t}`, i, i == 2))
+			}
+		case "2":
+			releases = append(releases, `{"tag_name": "1.0.0"}`)
+		}
+		_, _ = w.Write([]byte("[" + strings.Join(releases, ",") + "]"))
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	versions, err := reg.FetchVersions(context.Background(), "ReactiveX/RxSwift")
+	if err != nil {
+		t.Fatalf("FetchVersions failed: %v", err)
+	}
+
+	if len(versions) != perPage {
+		t.Fatalf("expected %d versions without the draft, got %d", perPage, len(versions))
+	}
+	latest := versions[0]
+	if latest.Number != "6.8.0" || latest.PublishedAt.IsZero() {
+		t.Errorf("unexpected first version: %+v", latest)
+	}
+	if latest.Publisher == nil || latest.Publisher.Login != "freak4pc" {
+		t.Errorf("unexpected publisher: %+v", latest.Publisher)
+	}
+	assets, _ := latest.Metadata["assets"].([]map[string]any)
+	if len(assets) != 1 || assets[0]["name"] != "RxSwift.xcframework.zip" || assets[0]["integrity"] != "sha256-ungWv48Bz+pBQUDeXa4iI7ADYaOWF3qctBD/YfIAFa0=" {
+		t.Errorf("unexpected assets: %v", latest.Metadata["assets"])
+	}
+	if versions[1].Metadata["prerelease"] != true {
+		t.Errorf("expected 6.2.0 to be a prerelease, got %+v", versions[1])
+	}
+	if versions[len(versions)-1].Number != "1.0.0" {
+		t.Errorf("expected the second page to be followed, got %q", versions[len(versions)-1].Number)
+	}
+}
+
+func TestFetchDependencies(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/Moya/Moya/15.0.0/Cartfile":
+			_, _ = w.Write([]byte(`# Networking
+github "Alamofire/Alamofire" ~> 5.0
+github "ReactiveX/RxSwift" "6.5.0"
+git "https://example.com/internal/Lib.git" "main"
+binary "https://example.com/Framework.json" >= 1.0
+`))
+		case "/Moya/Moya/15.0.0/Cartfile.private":
+			_, _ = w.Write([]byte(`github "Quick/Nimble" ~> 10.0` + "\n"))
+		default:
+			w.WriteHeader(404)
+		}
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	deps, err := reg.FetchDependencies(context.Background(), "Moya/Moya", "15.0.0")
+	if err != nil {
+		t.Fatalf("FetchDependencies failed: %v", err)
+	}
+
+	expected := []struct {
+		name, requirements, origin string
+		scope                      core.Scope
+	}{
+		{"Alamofire/Alamofire", "~> 5.0", "github", core.Runtime},
+		{"ReactiveX/RxSwift", "6.5.0", "github", core.Runtime},
+		{"https://example.com/internal/Lib.git", "main", "git", core.Runtime},
+		{"https://example.com/Framework.json", ">= 1.0", "binary", core.Runtime},
+		{"Quick/Nimble", "~> 10.0", "github", core.Development},
+	}
+	if len(deps) != len(expected) {
+		t.Fatalf("expected %d dependencies, got %d: %+v", len(expected), len(deps), deps)
+	}
+	for i, want := range expected {
+		d := deps[i]
+		if d.Name != want.name || d.Requirements != want.requirements || d.Metadata["origin"] != want.origin || d.Scope != want.scope {
+			t.Errorf("dependency %d: expected %+v, got %+v", i, want, d)
+		}
+	}
+}
+
+func TestFetchDependenciesWithoutCartfile(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(404)
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	deps, err := reg.FetchDependencies(context.Background(), "cli/cli", "v2.50.0")
+	if err != nil {
+		t.Fatalf("FetchDependencies failed: %v", err)
+	}
+	if len(deps) != 0 {
+		t.Errorf("expected no dependencies, got %+v", deps)
+	}
+}
+
+func TestFetchMaintainers(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(repoJSON))
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	maintainers, err := reg.FetchMaintainers(context.Background(), "ReactiveX/RxSwift")
+	if err != nil {
+		t.Fatalf("FetchMaintainers failed: %v", err)
+	}
+
+	if len(maintainers) != 1 || maintainers[0].Login != "ReactiveX" || maintainers[0].Role != "organization" {
+		t.Errorf("unexpected maintainers: %+v", maintainers)
+	}
+}
+
+func TestEnterpriseURLs(t *testing.T) {
+	reg := New("https://github.example.com/api/v3", nil)
+	if reg.rawURL != "https://github.example.com/raw" {
+		t.Errorf("unexpected raw URL: %q", reg.rawURL)
+	}
+	if got := reg.URLs().Registry("team/tool", "v1.0.0"); got != "https://github.example.com/team/tool/releases/tag/v1.0.0" {
+		t.Errorf("unexpected registry URL: %q", got)
+	}
+}
+
+func TestURLBuilder(t *testing.T) {
+	reg := New("", nil)
+	urls := reg.URLs()
+
+	tests := []struct {
+		name     string
+		fn       func() string
+		expected string
+	}{
+		{"registry", func() string { return urls.Registry("ReactiveX/RxSwift", "") }, "https://github.com/ReactiveX/RxSwift/releases"},
+		{"registry with version", func() string { return urls.Registry("ReactiveX/RxSwift", "6.8.0") }, "https://github.com/ReactiveX/RxSwift/releases/tag/6.8.0"},
+		{"download", func() string { return urls.Download("ReactiveX/RxSwift", "6.8.0") }, "https://github.com/ReactiveX/RxSwift/archive/refs/tags/6.8.0.tar.gz"},
+		{"purl", func() string { return urls.PURL("ReactiveX/RxSwift", "6.8.0") }, "pkg:github-release/ReactiveX/RxSwift@6.8.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.fn(); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestEcosystem(t *testing.T) {
+	reg := New("", nil)
+	if reg.Ecosystem() != "github-release" {
+		t.Errorf("expected ecosystem 'github-release', got %q", reg.Ecosystem())
+	}
+}
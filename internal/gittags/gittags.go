@@ -0,0 +1,843 @@
+// Package gittags provides a registry client that treats the tags of a git
+// repository as package versions. Many internal "registries" are just tagged
+// repositories, and PURLs of type github, gitlab, bitbucket and generic (with
+// a vcs_url qualifier) identify packages this way.
+//
+// Tags and their dates come from the hosting provider's API when the host is
+// recognised (GitHub, GitLab, Bitbucket, Gitea, Forgejo and Codeberg), and
+// from the git smart HTTP protocol otherwise, which lists tags without dates.
+package gittags
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/git-pkgs/purl"
+	"github.com/git-pkgs/registries/internal/core"
+	"github.com/git-pkgs/vers"
+)
+
+const (
+	DefaultURL      = "https://github.com"
+	GitLabURL       = "https://gitlab.com"
+	BitbucketURL    = "https://bitbucket.org"
+	GitHubAPIURL    = "https://api.github.com"
+	BitbucketAPIURL = "https://api.bitbucket.org/2.0"
+	ecosystem       = "gittags"
+
+	// perPage is the page size requested from provider tag listings. Gitea
+	// caps pages at 50 by default and is asked for that instead.
+	perPage      = 100
+	giteaPerPage = 50
+	// maxPages bounds how many tag pages FetchVersions follows.
+	maxPages = 10
+)
+
+func init() {
+	register(ecosystem, DefaultURL)
+	register("github", DefaultURL)
+	register("gitlab", GitLabURL)
+	register("bitbucket", BitbucketURL)
+	register("generic", DefaultURL)
+}
+
+func register(eco, defaultURL string) {
+	core.Register(eco, defaultURL, func(baseURL string, client *core.Client) core.Registry {
+		return newRegistry(eco, baseURL, defaultURL, client)
+	})
+}
+
+// Provider identifies the API a Registry uses to list tags.
+type Provider string
+
+const (
+	GitHub    Provider = "github"
+	GitLab    Provider = "gitlab"
+	Gitea     Provider = "gitea"
+	Bitbucket Provider = "bitbucket"
+	// Git lists tags over the git smart HTTP protocol, which works against
+	// any host but carries no tag dates.
+	Git Provider = "git"
+)
+
+// Registry lists tags of repositories on a single git host. Package names are
+// repository paths relative to the host ("owner/repo", or "group/sub/repo" on
+// GitLab); a registry built from a vcs_url qualifier ignores the name and
+// always reads the repository the qualifier points at.
+type Registry struct {
+	ecosystem string
+	baseURL   string
+	apiURL    string
+	provider  Provider
+	repo      string
+	client    *core.Client
+	urls      *URLs
+}
+
+// New returns a registry for the git host at baseURL (https://github.com by
+// default), choosing the provider API from the host name.
+func New(baseURL string, client *core.Client) *Registry {
+	return newRegistry(ecosystem, baseURL, DefaultURL, client)
+}
+
+func newRegistry(eco, baseURL, defaultURL string, client *core.Client) *Registry {
+	if baseURL == "" {
+		baseURL = defaultURL
+	}
+	baseURL = strings.TrimSuffix(baseURL, "/")
+	r := &Registry{
+		ecosystem: eco,
+		baseURL:   baseURL,
+		client:    client,
+	}
+	r.setProvider(DetectProvider(baseURL))
+	return r
+}
+
+func (r *Registry) setProvider(p Provider) {
+	r.provider = p
+	r.apiURL = apiURL(p, r.baseURL)
+	r.urls = &URLs{baseURL: r.baseURL, provider: p, repo: r.repo}
+}
+
+// DetectProvider guesses the hosting software behind a git host URL. Hosts it
+// doesn't recognise are read over the git protocol.
+func DetectProvider(baseURL string) Provider {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return Git
+	}
+	host := strings.ToLower(u.Hostname())
+	switch {
+	case host == "github.com" || strings.HasPrefix(host, "github."):
+		return GitHub
+	case host == "gitlab.com" || strings.HasPrefix(host, "gitlab."):
+		return GitLab
+	case host == "bitbucket.org":
+		return Bitbucket
+	case host == "codeberg.org" || strings.HasPrefix(host, "gitea.") || strings.HasPrefix(host, "forgejo."):
+		return Gitea
+	}
+	return Git
+}
+
+func apiURL(p Provider, baseURL string) string {
+	switch p {
+	case GitHub:
+		if baseURL == DefaultURL {
+			return GitHubAPIURL
+		}
+		return baseURL + "/api/v3"
+	case GitLab:
+		return baseURL + "/api/v4"
+	case Gitea:
+		return baseURL + "/api/v1"
+	case Bitbucket:
+		if baseURL == BitbucketURL {
+			return BitbucketAPIURL
+		}
+		return baseURL + "/2.0"
+	}
+	return ""
+}
+
+func (r *Registry) Ecosystem() string {
+	return r.ecosystem
+}
+
+func (r *Registry) URLs() core.URLBuilder {
+	return r.urls
+}
+
+// Provider returns the API the registry lists tags through.
+func (r *Registry) Provider() Provider {
+	return r.provider
+}
+
+// WithQualifiers returns a copy of the registry configured from PURL
+// qualifiers. vcs_url points the registry at a single repository, on
+// whichever host it lives, and provider overrides host detection for
+// self-hosted instances with unremarkable names (provider=gitlab for
+// git.example.com, say).
+func (r *Registry) WithQualifiers(qualifiers map[string]string) core.Registry {
+	copy := *r
+	if host, repo, ok := parseVCSURL(qualifiers["vcs_url"]); ok {
+		copy.baseURL = host
+		copy.repo = repo
+		copy.setProvider(DetectProvider(host))
+	}
+	switch p := Provider(strings.ToLower(qualifiers["provider"])); p {
+	case GitHub, GitLab, Gitea, Bitbucket, Git:
+		copy.setProvider(p)
+	}
+	return &copy
+}
+
+// parseVCSURL splits a vcs_url such as git+https://host/group/repo.git@v1
+// into the host's base URL and the repository path. scp-style addresses
+// (git@host:group/repo.git) are read as https.
+func parseVCSURL(s string) (host, repo string, ok bool) {
+	s = strings.TrimPrefix(s, "git+")
+	if s == "" {
+		return "", "", false
+	}
+	if !strings.Contains(s, "://") {
+		at := strings.Index(s, "@")
+		colon := strings.Index(s, ":")
+		if colon < 0 || colon < at {
+			return "", "", false
+		}
+		s = "https://" + s[at+1:colon] + "/" + s[colon+1:]
+	}
+	u, err := url.Parse(s)
+	if err != nil || u.Host == "" {
+		return "", "", false
+	}
+	scheme := u.Scheme
+	if scheme != "http" {
+		scheme = "https"
+	}
+	path := strings.Trim(u.Path, "/")
+	// a trailing @revision names a commit, not part of the repository
+	if i := strings.LastIndex(path, "@"); i > 0 {
+		path = path[:i]
+	}
+	path = strings.TrimSuffix(path, ".git")
+	if path == "" {
+		return "", "", false
+	}
+	return scheme + "://" + u.Host, path, true
+}
+
+func (r *Registry) path(name string) string {
+	if r.repo != "" {
+		return r.repo
+	}
+	return strings.TrimSuffix(strings.Trim(name, "/"), ".git")
+}
+
+// repository is the subset of repository metadata every provider returns,
+// normalised so FetchPackage can build a package from any of them.
+type repository struct {
+	fullName      string
+	description   string
+	homepage      string
+	webURL        string
+	license       string
+	defaultBranch string
+	topics        []string
+	archived      bool
+}
+
+type githubRepo struct {
+	FullName      string   `json:"full_name"`
+	Description   string   `json:"description"`
+	Homepage      string   `json:"homepage"`
+	HTMLURL       string   `json:"html_url"`
+	DefaultBranch string   `json:"default_branch"`
+	Archived      bool     `json:"archived"`
+	Topics        []string `json:"topics"`
+	License       *struct {
+		SPDXID string `json:"spdx_id"`
+	} `json:"license"`
+}
+
+type gitlabProject struct {
+	PathWithNamespace string   `json:"path_with_namespace"`
+	Description       string   `json:"description"`
+	WebURL            string   `json:"web_url"`
+	DefaultBranch     string   `json:"default_branch"`
+	Archived          bool     `json:"archived"`
+	Topics            []string `json:"topics"`
+}
+
+type giteaRepo struct {
+	FullName      string   `json:"full_name"`
+	Description   string   `json:"description"`
+	Website       string   `json:"website"`
+	HTMLURL       string   `json:"html_url"`
+	DefaultBranch string   `json:"default_branch"`
+	Archived      bool     `json:"archived"`
+	Topics        []string `json:"topics"`
+}
+
+type bitbucketRepo struct {
+	FullName    string `json:"full_name"`
+	Description string `json:"description"`
+	Website     string `json:"website"`
+	MainBranch  *struct {
+		Name string `json:"name"`
+	} `json:"mainbranch"`
+	Links struct {
+		HTML struct {
+			Href string `json:"href"`
+		} `json:"html"`
+	} `json:"links"`
+}
+
+func (r *Registry) fetchRepository(ctx context.Context, path string) (*repository, error) {
+	switch r.provider {
+	case GitHub:
+		var resp githubRepo
+		if err := r.client.GetJSON(ctx, fmt.Sprintf("%s/repos/%s", r.apiURL, path), &resp); err != nil {
+			return nil, err
+		}
+		repo := &repository{
+			fullName:      resp.FullName,
+			description:   resp.Description,
+			homepage:      resp.Homepage,
+			webURL:        resp.HTMLURL,
+			defaultBranch: resp.DefaultBranch,
+			topics:        resp.Topics,
+			archived:      resp.Archived,
+		}
+		if resp.License != nil && resp.License.SPDXID != "NOASSERTION" {
+			repo.license = resp.License.SPDXID
+		}
+		return repo, nil
+	case GitLab:
+		var resp gitlabProject
+		if err := r.client.GetJSON(ctx, fmt.Sprintf("%s/projects/%s", r.apiURL, url.PathEscape(path)), &resp); err != nil {
+			return nil, err
+		}
+		return &repository{
+			fullName:      resp.PathWithNamespace,
+			description:   resp.Description,
+			webURL:        resp.WebURL,
+			defaultBranch: resp.DefaultBranch,
+			topics:        resp.Topics,
+			archived:      resp.Archived,
+		}, nil
+	case Gitea:
+		var resp giteaRepo
+		if err := r.client.GetJSON(ctx, fmt.Sprintf("%s/repos/%s", r.apiURL, path), &resp); err != nil {
+			return nil, err
+		}
+		return &repository{
+			fullName:      resp.FullName,
+			description:   resp.Description,
+			homepage:      resp.Website,
+			webURL:        resp.HTMLURL,
+			defaultBranch: resp.DefaultBranch,
+			topics:        resp.Topics,
+			archived:      resp.Archived,
+		}, nil
+	case Bitbucket:
+		var resp bitbucketRepo
+		if err := r.client.GetJSON(ctx, fmt.Sprintf("%s/repositories/%s", r.apiURL, path), &resp); err != nil {
+			return nil, err
+		}
+		repo := &repository{
+			fullName:    resp.FullName,
+			description: resp.Description,
+			homepage:    resp.Website,
+			webURL:      resp.Links.HTML.Href,
+		}
+		if resp.MainBranch != nil {
+			repo.defaultBranch = resp.MainBranch.Name
+		}
+		return repo, nil
+	}
+
+	// The git protocol has no repository metadata; listing refs at least
+	// confirms the repository exists.
+	if _, err := r.fetchGitTags(ctx, path); err != nil {
+		return nil, err
+	}
+	return &repository{fullName: path}, nil
+}
+
+func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package, error) {
+	path := r.path(name)
+	repo, err := r.fetchRepository(ctx, path)
+	if err != nil {
+		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
+			return nil, &core.NotFoundError{Ecosystem: r.ecosystem, Name: name}
+		}
+		return nil, err
+	}
+
+	fullName := repo.fullName
+	if fullName == "" {
+		fullName = path
+	}
+	webURL := repo.webURL
+	if webURL == "" {
+		webURL = r.baseURL + "/" + path
+	}
+	homepage := repo.homepage
+	if homepage == "" {
+		homepage = webURL
+	}
+
+	var namespace string
+	if i := strings.LastIndex(fullName, "/"); i > 0 {
+		namespace = fullName[:i]
+	}
+
+	return &core.Package{
+		Name:        fullName,
+		Description: repo.description,
+		Homepage:    homepage,
+		Repository:  webURL,
+		Licenses:    repo.license,
+		Keywords:    repo.topics,
+		Namespace:   namespace,
+		Metadata: map[string]any{
+			"provider":       string(r.provider),
+			"default_branch": repo.defaultBranch,
+			"archived":       repo.archived,
+		},
+	}, nil
+}
+
+// tag is a git tag normalised across providers. date is the tagger date for
+// annotated tags where the provider exposes it, and the date of the tagged
+// commit otherwise.
+type tag struct {
+	name   string
+	commit string
+	date   time.Time
+}
+
+func (r *Registry) FetchVersions(ctx context.Context, name string) ([]core.Version, error) {
+	tags, err := r.fetchTags(ctx, r.path(name))
+	if err != nil {
+		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
+			return nil, &core.NotFoundError{Ecosystem: r.ecosystem, Name: name}
+		}
+		return nil, err
+	}
+
+	sort.SliceStable(tags, func(i, j int) bool {
+		return vers.Compare(tags[i].name, tags[j].name) > 0
+	})
+
+	versions := make([]core.Version, 0, len(tags))
+	for _, t := range tags {
+		v := core.Version{
+			Number:      t.name,
+			PublishedAt: t.date,
+		}
+		if t.commit != "" {
+			v.Metadata = map[string]any{"commit": t.commit}
+		}
+		versions = append(versions, v)
+	}
+	return versions, nil
+}
+
+func (r *Registry) fetchTags(ctx context.Context, path string) ([]tag, error) {
+	switch r.provider {
+	case GitHub:
+		return r.fetchGitHubTags(ctx, path)
+	case GitLab:
+		return r.fetchGitLabTags(ctx, path)
+	case Gitea:
+		return r.fetchGiteaTags(ctx, path)
+	case Bitbucket:
+		return r.fetchBitbucketTags(ctx, path)
+	}
+	return r.fetchGitTags(ctx, path)
+}
+
+type githubTag struct {
+	Name   string `json:"name"`
+	Commit struct {
+		SHA string `json:"sha"`
+	} `json:"commit"`
+}
+
+// githubTagsQuery lists tags with their dates in one request a page. The
+// REST tags listing has no dates, and dating each tag from its commit would
+// cost a request per tag.
+const githubTagsQuery = `query($owner: String!, $name: String!, $first: Int!, $after: String) {
+  repository(owner: $owner, name: $name) {
+    refs(refPrefix: "refs/tags/", first: $first, after: $after) {
+      pageInfo { hasNextPage endCursor }
+      nodes {
+        name
+        target {
+          oid
+          ... on Commit { committedDate }
+          ... on Tag { tagger { date } target { oid ... on Commit { committedDate } } }
+        }
+      }
+    }
+  }
+}`
+
+type githubTarget struct {
+	OID           string     `json:"oid"`
+	CommittedDate *time.Time `json:"committedDate"`
+	Tagger        *struct {
+		Date *time.Time `json:"date"`
+	} `json:"tagger"`
+	Target *githubTarget `json:"target"`
+}
+
+type githubTagsResponse struct {
+	Repository *struct {
+		Refs struct {
+			PageInfo struct {
+				HasNextPage bool   `json:"hasNextPage"`
+				EndCursor   string `json:"endCursor"`
+			} `json:"pageInfo"`
+			Nodes []struct {
+				Name   string       `json:"name"`
+				Target githubTarget `json:"target"`
+			} `json:"nodes"`
+		} `json:"refs"`
+	} `json:"repository"`
+}
+
+func (r *Registry) githubGraphQLURL() string {
+	if r.baseURL == DefaultURL {
+		return GitHubAPIURL + "/graphql"
+	}
+	return r.baseURL + "/api/graphql"
+}
+
+// fetchGitHubTags lists tags with their dates through the GraphQL API.
+// GitHub only answers GraphQL queries with a token, so without one the
+// tags are listed over REST, undated.
+func (r *Registry) fetchGitHubTags(ctx context.Context, path string) ([]tag, error) {
+	// GraphQL reports a missing repository in the response body, so it is
+	// turned into the 404 the REST API would have given
+	missing := &core.HTTPError{StatusCode: http.StatusNotFound, URL: r.baseURL + "/" + path}
+	owner, name, ok := strings.Cut(path, "/")
+	if !ok {
+		return nil, missing
+	}
+	variables := map[string]any{"owner": owner, "name": name, "first": perPage}
+
+	var tags []tag
+	for page := 1; page <= maxPages; page++ {
+		var resp githubTagsResponse
+		err := r.client.GraphQL(ctx, r.githubGraphQLURL(), githubTagsQuery, variables, &resp)
+		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.StatusCode == http.StatusUnauthorized {
+			return r.fetchGitHubRESTTags(ctx, path)
+		}
+		if gqlErr, ok := err.(*core.GraphQLError); ok && gqlErr.IsNotFound() {
+			return nil, missing
+		}
+		if err != nil {
+			return nil, err
+		}
+		if resp.Repository == nil {
+			return nil, missing
+		}
+
+		refs := resp.Repository.Refs
+		for _, n := range refs.Nodes {
+			t := tag{name: n.Name, commit: n.Target.OID}
+			target := n.Target
+			// Annotated tags point at a tag object, which points at the commit
+			if target.Target != nil {
+				t.commit = target.Target.OID
+				if target.Tagger != nil && target.Tagger.Date != nil {
+					t.date = *target.Tagger.Date
+				} else if target.Target.CommittedDate != nil {
+					t.date = *target.Target.CommittedDate
+				}
+			} else if target.CommittedDate != nil {
+				t.date = *target.CommittedDate
+			}
+			tags = append(tags, t)
+		}
+		if !refs.PageInfo.HasNextPage {
+			break
+		}
+		variables["after"] = refs.PageInfo.EndCursor
+	}
+	return tags, nil
+}
+
+// fetchGitHubRESTTags lists tags through the REST API, which has no dates.
+func (r *Registry) fetchGitHubRESTTags(ctx context.Context, path string) ([]tag, error) {
+	var tags []tag
+	for page := 1; page <= maxPages; page++ {
+		var resp []githubTag
+		url := fmt.Sprintf("%s/repos/%s/tags?per_page=%d&page=%d", r.apiURL, path, perPage, page)
+		if err := r.client.GetJSON(ctx, url, &resp); err != nil {
+			return nil, err
+		}
+		for _, t := range resp {
+			tags = append(tags, tag{name: t.Name, commit: t.Commit.SHA})
+		}
+		if len(resp) < perPage {
+			break
+		}
+	}
+	return tags, nil
+}
+
+type gitlabTag struct {
+	Name      string     `json:"name"`
+	CreatedAt *time.Time `json:"created_at"`
+	Commit    struct {
+		ID            string    `json:"id"`
+		CommittedDate time.Time `json:"committed_date"`
+	} `json:"commit"`
+}
+
+func (r *Registry) fetchGitLabTags(ctx context.Context, path string) ([]tag, error) {
+	var tags []tag
+	for page := 1; page <= maxPages; page++ {
+		var resp []gitlabTag
+		tagsURL := fmt.Sprintf("%s/projects/%s/repository/tags?per_page=%d&page=%d", r.apiURL, url.PathEscape(path), perPage, page)
+		if err := r.client.GetJSON(ctx, tagsURL, &resp); err != nil {
+			return nil, err
+		}
+		for _, t := range resp {
+			date := t.Commit.CommittedDate
+			// created_at is only set for annotated tags
+			if t.CreatedAt != nil {
+				date = *t.CreatedAt
+			}
+			tags = append(tags, tag{name: t.Name, commit: t.Commit.ID, date: date})
+		}
+		if len(resp) < perPage {
+			break
+		}
+	}
+	return tags, nil
+}
+
+type giteaTag struct {
+	Name   string `json:"name"`
+	Commit struct {
+		SHA     string    `json:"sha"`
+		Created time.Time `json:"created"`
+	} `json:"commit"`
+}
+
+func (r *Registry) fetchGiteaTags(ctx context.Context, path string) ([]tag, error) {
+	var tags []tag
+	for page := 1; page <= maxPages; page++ {
+		var resp []giteaTag
+		url := fmt.Sprintf("%s/repos/%s/tags?limit=%d&page=%d", r.apiURL, path, giteaPerPage, page)
+		if err := r.client.GetJSON(ctx, url, &resp); err != nil {
+			return nil, err
+		}
+		for _, t := range resp {
+			tags = append(tags, tag{name: t.Name, commit: t.Commit.SHA, date: t.Commit.Created})
+		}
+		if len(resp) < giteaPerPage {
+			break
+		}
+	}
+	return tags, nil
+}
+
+type bitbucketTags struct {
+	Values []struct {
+		Name   string     `json:"name"`
+		Date   *time.Time `json:"date"`
+		Target struct {
+			Hash string    `json:"hash"`
+			Date time.Time `json:"date"`
+		} `json:"target"`
+	} `json:"values"`
+	Next string `json:"next"`
+}
+
+func (r *Registry) fetchBitbucketTags(ctx context.Context, path string) ([]tag, error) {
+	var tags []tag
+	next := fmt.Sprintf("%s/repositories/%s/refs/tags?pagelen=%d", r.apiURL, path, perPage)
+	for page := 1; page <= maxPages && next != ""; page++ {
+		var resp bitbucketTags
+		if err := r.client.GetJSON(ctx, next, &resp); err != nil {
+			return nil, err
+		}
+		for _, t := range resp.Values {
+			date := t.Target.Date
+			if t.Date != nil {
+				date = *t.Date
+			}
+			tags = append(tags, tag{name: t.Name, commit: t.Target.Hash, date: date})
+		}
+		next = resp.Next
+	}
+	return tags, nil
+}
+
+// fetchGitTags reads tags from the ref advertisement of git's smart HTTP
+// protocol. Annotated tags are advertised twice, the second time peeled
+// ("refs/tags/v1^{}") to the commit they point at, which is the hash kept.
+func (r *Registry) fetchGitTags(ctx context.Context, path string) ([]tag, error) {
+	url := fmt.Sprintf("%s/%s.git/info/refs?service=git-upload-pack", r.baseURL, path)
+	body, err := r.client.GetBody(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	refs, err := parseRefAdvertisement(body)
+	if err != nil {
+		return nil, err
+	}
+
+	var tags []tag
+	index := make(map[string]int)
+	for _, ref := range refs {
+		name, ok := strings.CutPrefix(ref.name, "refs/tags/")
+		if !ok {
+			continue
+		}
+		name, peeled := strings.CutSuffix(name, "^{}")
+		if i, ok := index[name]; ok {
+			if peeled {
+				tags[i].commit = ref.hash
+			}
+			continue
+		}
+		index[name] = len(tags)
+		tags = append(tags, tag{name: name, commit: ref.hash})
+	}
+	return tags, nil
+}
+
+type ref struct {
+	hash string
+	name string
+}
+
+// parseRefAdvertisement decodes the pkt-line framed response to
+// info/refs?service=git-upload-pack: a "# service=" line and a flush, then one
+// "<hash> <ref>" line per ref, the first carrying capabilities after a NUL.
+func parseRefAdvertisement(body []byte) ([]ref, error) {
+	var refs []ref
+	for len(body) > 0 {
+		if len(body) < 4 {
+			return nil, fmt.Errorf("truncated pkt-line")
+		}
+		n, err := strconv.ParseUint(string(body[:4]), 16, 16)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pkt-line length %q", body[:4])
+		}
+		if n == 0 {
+			body = body[4:]
+			continue
+		}
+		if n < 4 || int(n) > len(body) {
+			return nil, fmt.Errorf("invalid pkt-line length %d", n)
+		}
+		line := body[4:n]
+		body = body[n:]
+
+		line = bytes.TrimSuffix(line, []byte("\n"))
+		if i := bytes.IndexByte(line, 0); i >= 0 {
+			line = line[:i]
+		}
+		if bytes.HasPrefix(line, []byte("#")) {
+			continue
+		}
+		hash, name, ok := strings.Cut(string(line), " ")
+		if !ok {
+			continue
+		}
+		refs = append(refs, ref{hash: hash, name: name})
+	}
+	return refs, nil
+}
+
+// FetchMaintainers returns the account or group that owns the repository,
+// taken from the first segment of its path. Hosts read over the git protocol
+// have no notion of owners and return nil.
+func (r *Registry) FetchMaintainers(ctx context.Context, name string) ([]core.Maintainer, error) {
+	if r.provider == Git {
+		return nil, nil
+	}
+	owner, _, ok := strings.Cut(r.path(name), "/")
+	if !ok || owner == "" {
+		return nil, nil
+	}
+	return []core.Maintainer{{
+		Login: owner,
+		URL:   r.baseURL + "/" + owner,
+	}}, nil
+}
+
+type URLs struct {
+	baseURL  string
+	provider Provider
+	repo     string
+}
+
+func (u *URLs) path(name string) string {
+	if u.repo != "" {
+		return u.repo
+	}
+	return strings.Trim(name, "/")
+}
+
+func (u *URLs) Registry(name, version string) string {
+	web := u.baseURL + "/" + u.path(name)
+	if version == "" {
+		return web
+	}
+	switch u.provider {
+	case GitHub:
+		return web + "/tree/" + version
+	case GitLab:
+		return web + "/-/tree/" + version
+	case Gitea:
+		return web + "/src/tag/" + version
+	case Bitbucket:
+		return web + "/src/" + version
+	}
+	return web
+}
+
+func (u *URLs) Download(name, version string) string {
+	if version == "" {
+		return ""
+	}
+	path := u.path(name)
+	web := u.baseURL + "/" + path
+	switch u.provider {
+	case GitHub:
+		return fmt.Sprintf("%s/archive/refs/tags/%s.tar.gz", web, version)
+	case GitLab:
+		return fmt.Sprintf("%s/-/archive/%s/%s-%s.tar.gz", web, version, path[strings.LastIndex(path, "/")+1:], version)
+	case Gitea:
+		return fmt.Sprintf("%s/archive/%s.tar.gz", web, version)
+	case Bitbucket:
+		return fmt.Sprintf("%s/get/%s.tar.gz", web, version)
+	}
+	return ""
+}
+
+func (u *URLs) Documentation(name, version string) string {
+	return ""
+}
+
+// PURL uses the github, gitlab and bitbucket types for repositories on those
+// public hosts, and pkg:generic with a vcs_url qualifier for any other. A
+// provider that host detection wouldn't pick is recorded as a qualifier too.
+func (u *URLs) PURL(name, version string) string {
+	path := u.path(name)
+	namespace, pkgName := "", path
+	if i := strings.LastIndex(path, "/"); i >= 0 {
+		namespace, pkgName = path[:i], path[i+1:]
+	}
+	switch u.baseURL {
+	case DefaultURL:
+		return purl.New("github", namespace, pkgName, version, nil).String()
+	case GitLabURL:
+		return purl.New("gitlab", namespace, pkgName, version, nil).String()
+	case BitbucketURL:
+		return purl.New("bitbucket", namespace, pkgName, version, nil).String()
+	}
+	qualifiers := map[string]string{"vcs_url": "git+" + u.baseURL + "/" + path + ".git"}
+	if u.provider != DetectProvider(u.baseURL) {
+		qualifiers["provider"] = string(u.provider)
+	}
+	return purl.New("generic", namespace, pkgName, version, qualifiers).String()
+}
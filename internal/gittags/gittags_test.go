@@ -0,0 +1,406 @@
+package gittags
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+func withProvider(reg *Registry, p Provider) *Registry {
+	return reg.WithQualifiers(map[string]string{"provider": string(p)}).(*Registry)
+}
+
+func TestFetchPackageGitHub(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v3/repos/cli/cli" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+			w.WriteHeader(404)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"full_name":"cli/cli","description":"GitHub's official command line tool","homepage":"https://cli.github.com","html_url":"https://github.com/cli/cli","default_branch":"trunk","topics":["cli","git"],"license":{"spdx_id":"MIT"}}`))
+	}))
+	defer server.Close()
+
+	reg := withProvider(New(server.URL, core.DefaultClient()), GitHub)
+	pkg, err := reg.FetchPackage(context.Background(), "cli/cli")
+	if err != nil {
+		t.Fatalf("FetchPackage failed: %v", err)
+	}
+
+	if pkg.Name != "cli/cli" || pkg.Namespace != "cli" {
+		t.Errorf("unexpected name %q namespace %q", pkg.Name, pkg.Namespace)
+	}
+	if pkg.Homepage != "https://cli.github.com" {
+		t.Errorf("unexpected homepage %q", pkg.Homepage)
+	}
+	if pkg.Repository != "https://github.com/cli/cli" {
+		t.Errorf("unexpected repository %q", pkg.Repository)
+	}
+	if pkg.Licenses != "MIT" {
+		t.Errorf("unexpected licenses %q", pkg.Licenses)
+	}
+	if pkg.Metadata["default_branch"] != "trunk" {
+		t.Errorf("unexpected default_branch %v", pkg.Metadata["default_branch"])
+	}
+}
+
+func TestFetchVersionsGitHub(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/api/graphql" || r.Method != http.MethodPost {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(404)
+			return
+		}
+		var req struct {
+			Variables map[string]any `json:"variables"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if req.Variables["owner"] != "cli" || req.Variables["name"] != "cli" {
+			t.Errorf("unexpected variables %v", req.Variables)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch req.Variables["after"] {
+		case nil:
+			_, _ = w.Write([]byte(`{"data":{"repository":{"refs":{"pageInfo":{"hasNextPage":true,"endCursor":"c1"},"nodes":[
+				{"name":"v2.9.0","target":{"oid":"aaa","committedDate":"2022-04-27T10:00:00Z"}}]}}}}`))
+		case "c1":
+			_, _ = w.Write([]byte(`{"data":{"repository":{"refs":{"pageInfo":{"hasNextPage":false},"nodes":[
+				{"name":"v2.10.0","target":{"oid":"tagobj","tagger":{"date":"2022-05-10T10:00:00Z"},"target":{"oid":"bbb","committedDate":"2022-05-09T10:00:00Z"}}}]}}}}`))
+		}
+	}))
+	defer server.Close()
+
+	reg := withProvider(New(server.URL, core.DefaultClient()), GitHub)
+	versions, err := reg.FetchVersions(context.Background(), "cli/cli")
+	if err != nil {
+		t.Fatalf("FetchVersions failed: %v", err)
+	}
+
+	if len(versions) != 2 {
+		t.Fatalf("expected 2 versions, got %d", len(versions))
+	}
+	if requests != 2 {
+		t.Errorf("expected one request a page, got %d", requests)
+	}
+	if versions[0].Number != "v2.10.0" {
+		t.Errorf("expected newest tag first, got %q", versions[0].Number)
+	}
+	if want := time.Date(2022, 5, 10, 10, 0, 0, 0, time.UTC); !versions[0].PublishedAt.Equal(want) {
+		t.Errorf("annotated tag should carry the tagger date, got %v", versions[0].PublishedAt)
+	}
+	if versions[0].Metadata["commit"] != "bbb" {
+		t.Errorf("annotated tag should record the tagged commit, got %v", versions[0].Metadata["commit"])
+	}
+	if want := time.Date(2022, 4, 27, 10, 0, 0, 0, time.UTC); !versions[1].PublishedAt.Equal(want) {
+		t.Errorf("unexpected published at %v", versions[1].PublishedAt)
+	}
+	if versions[1].Metadata["commit"] != "aaa" {
+		t.Errorf("unexpected commit %v", versions[1].Metadata["commit"])
+	}
+}
+
+func TestFetchVersionsGitHubErrors(t *testing.T) {
+	var commits int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		token := r.Header.Get("Authorization")
+		switch {
+		case r.URL.Path == "/api/graphql" && token == "":
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"message":"This endpoint requires you to be authenticated."}`))
+		case r.URL.Path == "/api/graphql" && token == "Bearer limited":
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"message":"API rate limit exceeded"}`))
+		case r.URL.Path == "/api/graphql":
+			_, _ = w.Write([]byte(`{"data":{"repository":null},"errors":[{"type":"NOT_FOUND","message":"Could not resolve to a Repository"}]}`))
+		case r.URL.Path == "/api/v3/repos/cli/cli/tags":
+			_, _ = w.Write([]byte(`[{"name":"v2.9.0","commit":{"sha":"aaa"}}]`))
+		case strings.Contains(r.URL.Path, "/commits/"):
+			commits++
+			w.WriteHeader(404)
+		default:
+			t.Errorf("unexpected path: %s", r.URL.Path)
+			w.WriteHeader(404)
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	withToken := func(token string) *Registry {
+		client := core.DefaultClient()
+		client.AuthFunc = func(string) (string, string) { return "Authorization", "Bearer " + token }
+		return withProvider(New(server.URL, client), GitHub)
+	}
+
+	// Without a token the tags are listed undated, with no request per tag
+	versions, err := withProvider(New(server.URL, core.DefaultClient()), GitHub).FetchVersions(ctx, "cli/cli")
+	if err != nil || len(versions) != 1 || versions[0].Metadata["commit"] != "aaa" || !versions[0].PublishedAt.IsZero() {
+		t.Errorf("FetchVersions without a token = %+v, %v", versions, err)
+	}
+	if commits != 0 {
+		t.Errorf("expected no commit lookups, got %d", commits)
+	}
+
+	var httpErr *core.HTTPError
+	if _, err := withToken("limited").FetchVersions(ctx, "cli/cli"); !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusForbidden {
+		t.Errorf("expected the rate limit error, got %v", err)
+	}
+	if _, err := withToken("valid").FetchVersions(ctx, "cli/missing"); !errors.Is(err, core.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestFetchVersionsGitLab(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.EscapedPath() != "/api/v4/projects/group%2Fsub%2Ftool/repository/tags" {
+			t.Errorf("unexpected path: %s", r.URL.EscapedPath())
+			w.WriteHeader(404)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[
+			{"name":"v1.1.0","created_at":"2024-02-02T00:00:00Z","commit":{"id":"c2","committed_date":"2024-02-01T00:00:00Z"}},
+			{"name":"v1.0.0","created_at":null,"commit":{"id":"c1","committed_date":"2024-01-01T00:00:00Z"}}
+		]`))
+	}))
+	defer server.Close()
+
+	reg := New("https://gitlab.example.com", core.DefaultClient())
+	if reg.Provider() != GitLab {
+		t.Fatalf("expected gitlab provider, got %q", reg.Provider())
+	}
+	reg = withProvider(New(server.URL, core.DefaultClient()), GitLab)
+	versions, err := reg.FetchVersions(context.Background(), "group/sub/tool")
+	if err != nil {
+		t.Fatalf("FetchVersions failed: %v", err)
+	}
+
+	if len(versions) != 2 {
+		t.Fatalf("expected 2 versions, got %d", len(versions))
+	}
+	if want := time.Date(2024, 2, 2, 0, 0, 0, 0, time.UTC); !versions[0].PublishedAt.Equal(want) {
+		t.Errorf("expected annotated tag date, got %v", versions[0].PublishedAt)
+	}
+	if want := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC); !versions[1].PublishedAt.Equal(want) {
+		t.Errorf("expected commit date for lightweight tag, got %v", versions[1].PublishedAt)
+	}
+}
+
+func TestFetchVersionsBitbucket(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("page") == "2" {
+			_, _ = w.Write([]byte(`{"values":[{"name":"1.0","target":{"hash":"c1","date":"2023-01-01T00:00:00+00:00"}}]}`))
+			return
+		}
+		_, _ = fmt.Fprintf(w, `{"values":[{"name":"1.1","target":{"hash":"c2","date":"2023-06-01T00:00:00+00:00"}}],"next":"%s/2.0/repositories/team/repo/refs/tags?page=2"}`, server.URL)
+	}))
+	defer server.Close()
+
+	reg := withProvider(New(server.URL, core.DefaultClient()), Bitbucket)
+	versions, err := reg.FetchVersions(context.Background(), "team/repo")
+	if err != nil {
+		t.Fatalf("FetchVersions failed: %v", err)
+	}
+
+	if len(versions) != 2 || versions[0].Number != "1.1" || versions[1].Number != "1.0" {
+		t.Fatalf("unexpected versions: %+v", versions)
+	}
+}
+
+func pktLine(s string) string {
+	return fmt.Sprintf("%04x%s", len(s)+4, s)
+}
+
+func TestFetchVersionsGitProtocol(t *testing.T) {
+	body := pktLine("# service=git-upload-pack\n") + "0000" +
+		pktLine("1111111111111111111111111111111111111111 HEAD\x00multi_ack symref=HEAD:refs/heads/main\n") +
+		pktLine("1111111111111111111111111111111111111111 refs/heads/main\n") +
+		pktLine("2222222222222222222222222222222222222222 refs/tags/v1.0.0\n") +
+		pktLine("3333333333333333333333333333333333333333 refs/tags/v1.1.0\n") +
+		pktLine("4444444444444444444444444444444444444444 refs/tags/v1.1.0^{}\n") +
+		"0000"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("service") != "git-upload-pack" {
+			t.Errorf("unexpected request: %s", r.URL.RequestURI())
+		}
+		if r.URL.Path != "/tools/widget.git/info/refs" {
+			w.WriteHeader(404)
+			return
+		}
+		w.Header().Set("Content-Type", "application/x-git-upload-pack-advertisement")
+		_, _ = w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	if reg.Provider() != Git {
+		t.Fatalf("expected git provider, got %q", reg.Provider())
+	}
+
+	versions, err := reg.FetchVersions(context.Background(), "tools/widget")
+	if err != nil {
+		t.Fatalf("FetchVersions failed: %v", err)
+	}
+	if len(versions) != 2 {
+		t.Fatalf("expected 2 versions, got %d", len(versions))
+	}
+	if versions[0].Number != "v1.1.0" || versions[0].Metadata["commit"] != "4444444444444444444444444444444444444444" {
+		t.Errorf("expected peeled commit for annotated tag, got %+v", versions[0])
+	}
+	if !versions[0].PublishedAt.IsZero() {
+		t.Errorf("expected no date over the git protocol, got %v", versions[0].PublishedAt)
+	}
+
+	pkg, err := reg.FetchPackage(context.Background(), "tools/widget")
+	if err != nil {
+		t.Fatalf("FetchPackage failed: %v", err)
+	}
+	if pkg.Name != "tools/widget" || pkg.Repository != server.URL+"/tools/widget" {
+		t.Errorf("unexpected package %+v", pkg)
+	}
+
+	_, err = reg.FetchVersions(context.Background(), "tools/missing")
+	var notFound *core.NotFoundError
+	if !errors.As(err, &notFound) {
+		t.Errorf("expected NotFoundError, got %v", err)
+	}
+}
+
+func TestParseRefAdvertisementInvalid(t *testing.T) {
+	for _, body := range []string{"00", "zzzz", "00ffshort"} {
+		if _, err := parseRefAdvertisement([]byte(body)); err == nil {
+			t.Errorf("expected error for %q", body)
+		}
+	}
+}
+
+func TestDetectProvider(t *testing.T) {
+	tests := map[string]Provider{
+		"https://github.com":           GitHub,
+		"https://github.example.com":   GitHub,
+		"https://gitlab.com":           GitLab,
+		"https://gitlab.gnome.org":     GitLab,
+		"https://bitbucket.org":        Bitbucket,
+		"https://codeberg.org":         Gitea,
+		"https://gitea.example.com":    Gitea,
+		"https://git.kernel.org":       Git,
+		"https://source.example.com/x": Git,
+	}
+	for baseURL, want := range tests {
+		if got := DetectProvider(baseURL); got != want {
+			t.Errorf("DetectProvider(%q) = %q, want %q", baseURL, got, want)
+		}
+	}
+}
+
+func TestParseVCSURL(t *testing.T) {
+	tests := []struct {
+		in, host, repo string
+	}{
+		{"git+https://git.example.com/tools/widget.git", "https://git.example.com", "tools/widget"},
+		{"https://codeberg.org/forgejo/forgejo", "https://codeberg.org", "forgejo/forgejo"},
+		{"git+https://github.com/cli/cli.git@abc123", "https://github.com", "cli/cli"},
+		{"git@gitlab.com:group/sub/tool.git", "https://gitlab.com", "group/sub/tool"},
+	}
+	for _, tt := range tests {
+		host, repo, ok := parseVCSURL(tt.in)
+		if !ok || host != tt.host || repo != tt.repo {
+			t.Errorf("parseVCSURL(%q) = %q, %q, %v", tt.in, host, repo, ok)
+		}
+	}
+	if _, _, ok := parseVCSURL("widget"); ok {
+		t.Error("expected a bare name to be rejected")
+	}
+}
+
+func TestWithQualifiersVCSURL(t *testing.T) {
+	reg := New("", nil).WithQualifiers(map[string]string{
+		"vcs_url": "git+https://git.example.com/tools/widget.git",
+	}).(*Registry)
+
+	if reg.Provider() != Git {
+		t.Errorf("expected git provider, got %q", reg.Provider())
+	}
+	if got := reg.path("widget"); got != "tools/widget" {
+		t.Errorf("path = %q, want tools/widget", got)
+	}
+
+	purl := reg.URLs().PURL("widget", "v1.0.0")
+	want := "pkg:generic/tools/widget@v1.0.0?vcs_url=git%2Bhttps:%2F%2Fgit.example.com%2Ftools%2Fwidget.git"
+	if purl != want {
+		t.Errorf("PURL = %q, want %q", purl, want)
+	}
+
+	gitlab := reg.WithQualifiers(map[string]string{"provider": "gitlab"})
+	if p := gitlab.URLs().PURL("widget", ""); !strings.Contains(p, "provider=gitlab") {
+		t.Errorf("expected provider qualifier in %q", p)
+	}
+}
+
+func TestURLs(t *testing.T) {
+	tests := []struct {
+		baseURL, name            string
+		registry, download, purl string
+	}{
+		{
+			"", "cli/cli",
+			"https://github.com/cli/cli/tree/v2.0.0",
+			"https://github.com/cli/cli/archive/refs/tags/v2.0.0.tar.gz",
+			"pkg:github/cli/cli@v2.0.0",
+		},
+		{
+			"https://gitlab.com", "group/sub/tool",
+			"https://gitlab.com/group/sub/tool/-/tree/v2.0.0",
+			"https://gitlab.com/group/sub/tool/-/archive/v2.0.0/tool-v2.0.0.tar.gz",
+			"pkg:gitlab/group/sub/tool@v2.0.0",
+		},
+		{
+			"https://bitbucket.org", "team/repo",
+			"https://bitbucket.org/team/repo/src/v2.0.0",
+			"https://bitbucket.org/team/repo/get/v2.0.0.tar.gz",
+			"pkg:bitbucket/team/repo@v2.0.0",
+		},
+		{
+			"https://codeberg.org", "forgejo/forgejo",
+			"https://codeberg.org/forgejo/forgejo/src/tag/v2.0.0",
+			"https://codeberg.org/forgejo/forgejo/archive/v2.0.0.tar.gz",
+			"pkg:generic/forgejo/forgejo@v2.0.0?vcs_url=git%2Bhttps:%2F%2Fcodeberg.org%2Fforgejo%2Fforgejo.git",
+		},
+	}
+	for _, tt := range tests {
+		urls := New(tt.baseURL, nil).URLs()
+		if got := urls.Registry(tt.name, "v2.0.0"); got != tt.registry {
+			t.Errorf("Registry = %q, want %q", got, tt.registry)
+		}
+		if got := urls.Download(tt.name, "v2.0.0"); got != tt.download {
+			t.Errorf("Download = %q, want %q", got, tt.download)
+		}
+		if got := urls.PURL(tt.name, "v2.0.0"); got != tt.purl {
+			t.Errorf("PURL = %q, want %q", got, tt.purl)
+		}
+	}
+}
+
+func TestFetchMaintainers(t *testing.T) {
+	maintainers, err := New("", nil).FetchMaintainers(context.Background(), "cli/cli")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(maintainers) != 1 || maintainers[0].Login != "cli" || maintainers[0].URL != "https://github.com/cli" {
+		t.Errorf("unexpected maintainers %+v", maintainers)
+	}
+}
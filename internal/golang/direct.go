@@ -0,0 +1,410 @@
+package golang
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/git-pkgs/registries/internal/core"
+	"github.com/git-pkgs/registries/internal/gittags"
+	"github.com/git-pkgs/registries/internal/urlparser"
+	"github.com/git-pkgs/vers"
+)
+
+// Private modules aren't on proxy.golang.org, which answers 404 or 410 for
+// them. In direct mode the client resolves such modules the way the go
+// command does with GOPRIVATE or GOPROXY=direct: it finds the repository
+// from the module path's go-import meta tag and lists versions from the
+// repository's tags, through the hosting provider's API where it knows one.
+
+// WithDirectFallback returns a new Registry that resolves modules the proxy
+// doesn't have directly from their repositories. Modules matching any of the
+// private patterns, which use GOPRIVATE syntax ("*.corp.example.com,
+// github.com/myorg/*"), are never sent to the proxy at all, so their paths
+// don't leak to it.
+func (r *Registry) WithDirectFallback(private ...string) *Registry {
+	copy := *r
+	copy.direct = true
+	copy.private = nil
+	for _, p := range private {
+		for _, pattern := range strings.Split(p, ",") {
+			if pattern = strings.Trim(strings.TrimSpace(pattern), "/"); pattern != "" {
+				copy.private = append(copy.private, pattern)
+			}
+		}
+	}
+	return &copy
+}
+
+// directCredential is a header sent to one git host in direct mode.
+type directCredential struct {
+	host, header, value string
+}
+
+// WithDirectAuth returns a new Registry that sends header: value with the
+// requests direct mode makes to host, as the go command reads credentials
+// for private repositories from .netrc. host is a host name, with a port if
+// it isn't the default one, such as "github.com" or "git.corp.example";
+// credentials for github.com also go to api.github.com and
+// raw.githubusercontent.com. They are never sent to the proxy.
+func (r *Registry) WithDirectAuth(host, header, value string) *Registry {
+	copy := *r
+	copy.directAuth = append(append([]directCredential(nil), r.directAuth...),
+		directCredential{host: strings.ToLower(host), header: header, value: value})
+	return &copy
+}
+
+// directClient returns the client for requests to repository hosts, which
+// adds the direct mode credentials to the client's own.
+func (r *Registry) directClient() *core.Client {
+	if len(r.directAuth) == 0 {
+		return r.client
+	}
+	creds := r.directAuth
+	next := r.client.AuthFunc
+	return r.client.WithAuthFunc(func(rawURL string) (string, string) {
+		if u, err := url.Parse(rawURL); err == nil {
+			host, name := strings.ToLower(u.Host), strings.ToLower(u.Hostname())
+			for _, c := range creds {
+				github := c.host == "github.com" && (name == "api.github.com" || name == "raw.githubusercontent.com")
+				if host == c.host || name == c.host || github {
+					return c.header, c.value
+				}
+			}
+		}
+		if next != nil {
+			return next(rawURL)
+		}
+		return "", ""
+	})
+}
+
+// isPrivate reports whether a module matches a private pattern. As with
+// GOPRIVATE, a pattern matches a module whose leading path elements match it,
+// so "example.com/org" covers "example.com/org/repo/sub".
+func (r *Registry) isPrivate(module string) bool {
+	elems := strings.Split(module, "/")
+	for _, pattern := range r.private {
+		n := strings.Count(pattern, "/") + 1
+		if len(elems) < n {
+			continue
+		}
+		if ok, _ := path.Match(pattern, strings.Join(elems[:n], "/")); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// repoRoot is where the code for an import path prefix lives, as given by a
+// go-import meta tag.
+type repoRoot struct {
+	prefix string
+	vcs    string
+	url    string
+}
+
+// resolveRepo finds the repository holding a module. github.com and
+// bitbucket.org paths map to repositories directly, as in the go command;
+// anything else is looked up through the go-import meta tag served at
+// https://<module>?go-get=1.
+func (r *Registry) resolveRepo(ctx context.Context, module string) (*repoRoot, error) {
+	if strings.HasPrefix(module, "github.com/") || strings.HasPrefix(module, "bitbucket.org/") {
+		parts := strings.Split(module, "/")
+		if len(parts) < 3 {
+			return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: module}
+		}
+		prefix := strings.Join(parts[:3], "/")
+		return &repoRoot{prefix: prefix, vcs: "git", url: "https://" + prefix}, nil
+	}
+
+	body, err := r.directClient().GetText(ctx, "https://"+module+"?go-get=1")
+	if err != nil {
+		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
+			return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: module}
+		}
+		return nil, err
+	}
+	root := matchGoImport(parseGoImports(body), module)
+	if root == nil {
+		return nil, fmt.Errorf("no go-import meta tag for %s: %w", module, &core.NotFoundError{Ecosystem: ecosystem, Name: module})
+	}
+	return root, nil
+}
+
+var (
+	metaTagRe = regexp.MustCompile(`(?is)<meta\s[^>]*>`)
+	attrRe    = regexp.MustCompile(`(?s)([a-zA-Z][a-zA-Z0-9_-]*)\s*=\s*(?:"([^"]*)"|'([^']*)')`)
+)
+
+// parseGoImports returns the go-import meta tags in an HTML page.
+func parseGoImports(page string) []repoRoot {
+	var roots []repoRoot
+	for _, tag := range metaTagRe.FindAllString(page, -1) {
+		attrs := make(map[string]string)
+		for _, m := range attrRe.FindAllStringSubmatch(tag, -1) {
+			attrs[strings.ToLower(m[1])] = html.UnescapeString(m[2] + m[3])
+		}
+		if attrs["name"] != "go-import" {
+			continue
+		}
+		fields := strings.Fields(attrs["content"])
+		if len(fields) != 3 {
+			continue
+		}
+		roots = append(roots, repoRoot{prefix: fields[0], vcs: fields[1], url: fields[2]})
+	}
+	return roots
+}
+
+// matchGoImport picks the go-import tag whose prefix is the longest one
+// covering module.
+func matchGoImport(roots []repoRoot, module string) *repoRoot {
+	var best *repoRoot
+	for i, root := range roots {
+		if module != root.prefix && !strings.HasPrefix(module, root.prefix+"/") {
+			continue
+		}
+		if best == nil || len(root.prefix) > len(best.prefix) {
+			best = &roots[i]
+		}
+	}
+	return best
+}
+
+// splitRepoURL splits a repository URL into the host's base URL and the
+// repository path on it.
+func splitRepoURL(repoURL string) (host, repo string, err error) {
+	u, err := url.Parse(repoURL)
+	if err != nil || u.Host == "" {
+		return "", "", fmt.Errorf("invalid repository URL %q", repoURL)
+	}
+	repo = strings.TrimSuffix(strings.Trim(u.Path, "/"), ".git")
+	return u.Scheme + "://" + u.Host, repo, nil
+}
+
+// modulePaths returns where a module lives in its repository: dir is its
+// directory relative to the root, and tagPrefix is what its version tags
+// start with, which leaves out a /vN major version suffix.
+func modulePaths(module string, root *repoRoot) (dir, tagPrefix string) {
+	dir = strings.Trim(strings.TrimPrefix(module, root.prefix), "/")
+	tagPrefix = dir
+	if _, ok := majorSuffix(module); ok {
+		tagPrefix = strings.Trim(tagPrefix[:strings.LastIndex("/"+tagPrefix, "/")], "/")
+	}
+	if tagPrefix != "" {
+		tagPrefix += "/"
+	}
+	return dir, tagPrefix
+}
+
+// majorSuffix returns N for a module path ending in /vN with N >= 2.
+func majorSuffix(module string) (int, bool) {
+	i := strings.LastIndex(module, "/v")
+	if i < 0 {
+		return 0, false
+	}
+	n, err := strconv.Atoi(module[i+2:])
+	if err != nil || n < 2 || strconv.Itoa(n) != module[i+2:] {
+		return 0, false
+	}
+	return n, true
+}
+
+var semverRe = regexp.MustCompile(`^v(0|[1-9][0-9]*)\.(0|[1-9][0-9]*)\.(0|[1-9][0-9]*)(-[0-9A-Za-z.-]+)?$`)
+
+// moduleVersion reports whether a tag (with its prefix removed) is a version
+// of module: a canonical semantic version whose major version agrees with
+// the module path. Tags that would only be +incompatible versions are left
+// out.
+func moduleVersion(module, tag string) bool {
+	m := semverRe.FindStringSubmatch(tag)
+	if m == nil {
+		return false
+	}
+	major, _ := strconv.Atoi(m[1])
+	if n, ok := majorSuffix(module); ok {
+		return major == n
+	}
+	return major <= 1
+}
+
+// directVersions lists a module's versions from its repository's tags,
+// newest first.
+func (r *Registry) directVersions(ctx context.Context, module string) ([]core.Version, *repoRoot, error) {
+	root, err := r.resolveRepo(ctx, module)
+	if err != nil {
+		return nil, nil, err
+	}
+	switch root.vcs {
+	case "mod":
+		// The path is served by its own module proxy, which lists versions
+		// in no particular order
+		versions, err := New(root.url, r.directClient()).FetchVersions(ctx, module)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(versions) == 0 {
+			return nil, nil, &core.NotFoundError{Ecosystem: ecosystem, Name: module}
+		}
+		sort.SliceStable(versions, func(i, j int) bool {
+			return vers.Compare(versions[i].Number, versions[j].Number) > 0
+		})
+		return versions, root, nil
+	case "git":
+	default:
+		return nil, nil, fmt.Errorf("golang direct mode for %s repositories: %w", root.vcs, core.ErrNotSupported)
+	}
+
+	host, repo, err := splitRepoURL(root.url)
+	if err != nil {
+		return nil, nil, err
+	}
+	tags, err := gittags.New(host, r.directClient()).FetchVersions(ctx, repo)
+	if err != nil {
+		if _, ok := err.(*core.NotFoundError); ok {
+			return nil, nil, &core.NotFoundError{Ecosystem: ecosystem, Name: module}
+		}
+		return nil, nil, err
+	}
+
+	_, tagPrefix := modulePaths(module, root)
+	var versions []core.Version
+	for _, t := range tags {
+		number, ok := strings.CutPrefix(t.Number, tagPrefix)
+		if !ok || !moduleVersion(module, number) {
+			continue
+		}
+		t.Number = number
+		versions = append(versions, t)
+	}
+	if len(versions) == 0 {
+		return nil, nil, &core.NotFoundError{Ecosystem: ecosystem, Name: module}
+	}
+	return versions, root, nil
+}
+
+func (r *Registry) fetchPackageDirect(ctx context.Context, name string) (*core.Package, error) {
+	versions, root, err := r.directVersions(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	repoURL := urlparser.Parse(deriveRepoURL(name))
+	if root.vcs == "git" {
+		// urlparser only recognises public hosts
+		repoURL = strings.TrimSuffix(root.url, ".git")
+		if parsed := urlparser.Parse(repoURL); parsed != "" {
+			repoURL = parsed
+		}
+	}
+
+	parts := strings.Split(name, "/")
+	namespace := ""
+	if len(parts) > 1 {
+		namespace = strings.Join(parts[:len(parts)-1], "/")
+	}
+
+	var numbers []string
+	for _, v := range versions {
+		numbers = append(numbers, v.Number)
+	}
+
+	return &core.Package{
+		Name:                name,
+		Repository:          repoURL,
+		Homepage:            repoURL,
+		Namespace:           namespace,
+		LatestVersion:       latestOf(versions),
+		LatestStableVersion: core.LatestStableOf(ecosystem, numbers),
+	}, nil
+}
+
+// fetchDependenciesDirect reads go.mod at the version's tag from the
+// provider's raw file endpoint. Hosts only reachable over the git protocol
+// have no such endpoint.
+func (r *Registry) fetchDependenciesDirect(ctx context.Context, name, version string) ([]core.Dependency, error) {
+	root, err := r.resolveRepo(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	switch root.vcs {
+	case "mod":
+		return New(root.url, r.directClient()).FetchDependencies(ctx, name, version)
+	case "git":
+	default:
+		return nil, fmt.Errorf("golang direct mode for %s repositories: %w", root.vcs, core.ErrNotSupported)
+	}
+
+	host, repo, err := splitRepoURL(root.url)
+	if err != nil {
+		return nil, err
+	}
+	dir, tagPrefix := modulePaths(name, root)
+	tag := tagPrefix + version
+
+	// A /vN module is either in a vN subdirectory or at the root of a
+	// major version branch
+	dirs := []string{dir}
+	if _, ok := majorSuffix(name); ok {
+		dirs = append(dirs, strings.TrimSuffix(tagPrefix, "/"))
+	}
+	for _, d := range dirs {
+		modURL := rawFileURL(host, repo, tag, path.Join(d, "go.mod"))
+		if modURL == "" {
+			return nil, fmt.Errorf("golang direct mode for %s: %w", host, core.ErrNotSupported)
+		}
+		body, err := r.directClient().GetText(ctx, modURL)
+		if err == nil {
+			return parseGoMod(body), nil
+		}
+		if httpErr, ok := err.(*core.HTTPError); !ok || !httpErr.IsNotFound() {
+			return nil, err
+		}
+	}
+	return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name, Version: version}
+}
+
+// rawFileURL returns the URL serving a file from a repository at a tag, or
+// "" for hosts without one.
+func rawFileURL(host, repo, tag, file string) string {
+	switch gittags.DetectProvider(host) {
+	case gittags.GitHub:
+		if host == gittags.DefaultURL {
+			return fmt.Sprintf("https://raw.githubusercontent.com/%s/%s/%s", repo, tag, file)
+		}
+		return fmt.Sprintf("%s/%s/raw/%s/%s", host, repo, tag, file)
+	case gittags.GitLab:
+		return fmt.Sprintf("%s/%s/-/raw/%s/%s", host, repo, tag, file)
+	case gittags.Gitea:
+		return fmt.Sprintf("%s/%s/raw/tag/%s/%s", host, repo, tag, file)
+	case gittags.Bitbucket:
+		return fmt.Sprintf("%s/%s/raw/%s/%s", host, repo, tag, file)
+	}
+	return ""
+}
+
+// latestVersionDirect returns the highest release version, or the highest
+// pre-release if there is no release, as the go command's @latest query does.
+func (r *Registry) latestVersionDirect(ctx context.Context, name string) (string, error) {
+	versions, _, err := r.directVersions(ctx, name)
+	if err != nil {
+		return "", err
+	}
+	return latestOf(versions), nil
+}
+
+func latestOf(versions []core.Version) string {
+	for _, v := range versions {
+		if !strings.Contains(v.Number, "-") {
+			return v.Number
+		}
+	}
+	return versions[0].Number
+}
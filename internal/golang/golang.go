@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/git-pkgs/registries/internal/core"
+	"github.com/git-pkgs/registries/internal/gittags"
 	"github.com/git-pkgs/registries/internal/urlparser"
 )
 
@@ -27,6 +28,23 @@ type Registry struct {
 	baseURL string
 	client  *core.Client
 	urls    *URLs
+
+	// direct resolves modules missing from the proxy from their
+	// repositories, and private lists the GOPRIVATE-style patterns of
+	// modules never asked of the proxy. See WithDirectFallback.
+	direct  bool
+	private []string
+
+	// directAuth holds credentials for the hosts direct mode reads
+	// repositories from. See WithDirectAuth.
+	directAuth []directCredential
+
+	// proxies is the GOPROXY list lookups go through in place of baseURL.
+	// See WithProxyList.
+	proxies []proxy
+
+	// githubAPI is the GitHub API FetchNamespace asks about owners.
+	githubAPI string
 }
 
 func New(baseURL string, client *core.Client) *Registry {
@@ -34,8 +52,9 @@ func New(baseURL string, client *core.Client) *Registry {
 		baseURL = DefaultURL
 	}
 	r := &Registry{
-		baseURL: strings.TrimSuffix(baseURL, "/"),
-		client:  client,
+		baseURL:   strings.TrimSuffix(baseURL, "/"),
+		client:    client,
+		githubAPI: gittags.GitHubAPIURL,
 	}
 	r.urls = &URLs{baseURL: r.baseURL}
 	return r
@@ -74,18 +93,37 @@ func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package
 	encoded := encodeForProxy(name)
 
 	// Try to get the version list first to verify the module exists
-	listURL := fmt.Sprintf("%s/%s/@v/list", r.baseURL, encoded)
-	body, err := r.client.GetText(ctx, listURL)
+	var body string
+	var direct *core.Package
+	err := r.lookup(name, func(baseURL string) (err error) {
+		body, err = r.client.GetText(ctx, fmt.Sprintf("%s/%s/@v/list", baseURL, encoded))
+		return err
+	}, func() (err error) {
+		direct, err = r.fetchPackageDirect(ctx, name)
+		return err
+	})
 	if err != nil {
 		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
 			return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name}
 		}
 		return nil, err
 	}
+	if direct != nil {
+		return direct, nil
+	}
 
 	if strings.TrimSpace(body) == "" {
 		return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name}
 	}
+	tags := strings.Fields(body)
+
+	// Like the go command, latest prefers the newest release over a newer
+	// pre-release
+	stable := core.LatestStableOf(ecosystem, tags)
+	latest := stable
+	if latest == "" {
+		latest = core.LatestOf(ecosystem, tags)
+	}
 
 	// Go modules don't have rich metadata in the proxy protocol
 	// The repository URL is typically derived from the module path
@@ -98,10 +136,12 @@ func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package
 	}
 
 	return &core.Package{
-		Name:       name,
-		Repository: repoURL,
-		Homepage:   repoURL,
-		Namespace:  namespace,
+		Name:                name,
+		Repository:          repoURL,
+		Homepage:            repoURL,
+		Namespace:           namespace,
+		LatestVersion:       latest,
+		LatestStableVersion: stable,
 	}, nil
 }
 
@@ -122,15 +162,29 @@ func deriveRepoURL(modulePath string) string {
 
 func (r *Registry) FetchVersions(ctx context.Context, name string) ([]core.Version, error) {
 	encoded := encodeForProxy(name)
-	listURL := fmt.Sprintf("%s/%s/@v/list", r.baseURL, encoded)
 
-	body, err := r.client.GetText(ctx, listURL)
+	// Version details come from the proxy that listed the versions
+	var body, proxyURL string
+	var direct []core.Version
+	resolvedDirectly := false
+	err := r.lookup(name, func(baseURL string) (err error) {
+		proxyURL = baseURL
+		body, err = r.client.GetText(ctx, fmt.Sprintf("%s/%s/@v/list", baseURL, encoded))
+		return err
+	}, func() (err error) {
+		resolvedDirectly = true
+		direct, _, err = r.directVersions(ctx, name)
+		return err
+	})
 	if err != nil {
 		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
 			return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name}
 		}
 		return nil, err
 	}
+	if resolvedDirectly {
+		return direct, nil
+	}
 
 	lines := strings.Split(strings.TrimSpace(body), "\n")
 	versions := make([]core.Version, 0, len(lines))
@@ -142,7 +196,7 @@ func (r *Registry) FetchVersions(ctx context.Context, name string) ([]core.Versi
 		}
 
 		// Get version info for the timestamp
-		infoURL := fmt.Sprintf("%s/%s/@v/%s.info", r.baseURL, encoded, line)
+		infoURL := fmt.Sprintf("%s/%s/@v/%s.info", proxyURL, encoded, line)
 		var info versionInfo
 		if err := r.client.GetJSON(ctx, infoURL, &info); err == nil {
 			versions = append(versions, core.Version{
@@ -162,9 +216,18 @@ func (r *Registry) FetchVersions(ctx context.Context, name string) ([]core.Versi
 
 func (r *Registry) FetchDependencies(ctx context.Context, name, version string) ([]core.Dependency, error) {
 	encoded := encodeForProxy(name)
-	modURL := fmt.Sprintf("%s/%s/@v/%s.mod", r.baseURL, encoded, version)
 
-	body, err := r.client.GetText(ctx, modURL)
+	var deps []core.Dependency
+	err := r.lookup(name, func(baseURL string) error {
+		body, err := r.client.GetText(ctx, fmt.Sprintf("%s/%s/@v/%s.mod", baseURL, encoded, version))
+		if err == nil {
+			deps = parseGoMod(body)
+		}
+		return err
+	}, func() (err error) {
+		deps, err = r.fetchDependenciesDirect(ctx, name, version)
+		return err
+	})
 	if err != nil {
 		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
 			return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name, Version: version}
@@ -172,7 +235,7 @@ func (r *Registry) FetchDependencies(ctx context.Context, name, version string)
 		return nil, err
 	}
 
-	return parseGoMod(body), nil
+	return deps, nil
 }
 
 func parseGoMod(content string) []core.Dependency {
@@ -245,11 +308,6 @@ func parseRequireLine(line string) *core.Dependency {
 	}
 }
 
-func (r *Registry) FetchMaintainers(ctx context.Context, name string) ([]core.Maintainer, error) {
-	// Go modules don't have a maintainer concept in the proxy protocol
-	return nil, nil
-}
-
 type URLs struct {
 	baseURL string
 }
@@ -308,17 +366,26 @@ func (u *URLs) PURL(name, version string) string {
 // LatestVersion fetches the latest version of a module.
 func (r *Registry) LatestVersion(ctx context.Context, name string) (string, error) {
 	encoded := encodeForProxy(name)
-	latestURL := fmt.Sprintf("%s/%s/@latest", r.baseURL, encoded)
 
-	body, err := r.client.GetBody(ctx, latestURL)
+	var latest string
+	err := r.lookup(name, func(baseURL string) error {
+		body, err := r.client.GetBody(ctx, fmt.Sprintf("%s/%s/@latest", baseURL, encoded))
+		if err != nil {
+			return err
+		}
+		var info versionInfo
+		if err := json.Unmarshal(body, &info); err != nil {
+			return err
+		}
+		latest = info.Version
+		return nil
+	}, func() (err error) {
+		latest, err = r.latestVersionDirect(ctx, name)
+		return err
+	})
 	if err != nil {
 		return "", err
 	}
 
-	var info versionInfo
-	if err := json.Unmarshal(body, &info); err != nil {
-		return "", err
-	}
-
-	return info.Version, nil
+	return latest, nil
 }
@@ -3,8 +3,11 @@ package golang
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -250,3 +253,221 @@ func TestDeriveRepoURL(t *testing.T) {
 		})
 	}
 }
+
+func pktLine(s string) string {
+	return fmt.Sprintf("%04x%s", len(s)+4, s)
+}
+
+func TestDirectFallback(t *testing.T) {
+	var vcs *httptest.Server
+	vcs = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := strings.TrimPrefix(vcs.URL, "https://")
+		switch {
+		case r.URL.Query().Get("go-get") == "1":
+			fmt.Fprintf(w, `<html><head>
+<meta name="go-import" content="%s/widgets git https://%s/widgets.git">
+<meta name="go-source" content="%s/widgets _ _ _">
+</head></html>`, host, host, host)
+		case r.URL.Path == "/widgets.git/info/refs":
+			_, _ = w.Write([]byte(pktLine("# service=git-upload-pack\n") + "0000" +
+				pktLine("1111111111111111111111111111111111111111 HEAD\x00multi_ack\n") +
+				pktLine("2222222222222222222222222222222222222222 refs/tags/v1.0.0\n") +
+				pktLine("3333333333333333333333333333333333333333 refs/tags/sub/v1.5.0\n") +
+				pktLine("4444444444444444444444444444444444444444 refs/tags/sub/v2.0.0\n") +
+				pktLine("5555555555555555555555555555555555555555 refs/tags/sub/v2.0.1\n") +
+				pktLine("6666666666666666666666666666666666666666 refs/tags/sub/v2.1.0-rc.1\n") +
+				pktLine("7777777777777777777777777777777777777777 refs/tags/sub/release-2\n") +
+				"0000"))
+		default:
+			w.WriteHeader(404)
+		}
+	}))
+	defer vcs.Close()
+
+	proxyHits := 0
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxyHits++
+		w.WriteHeader(http.StatusGone)
+	}))
+	defer proxy.Close()
+
+	client := core.DefaultClient()
+	client.HTTPClient = vcs.Client()
+	module := strings.TrimPrefix(vcs.URL, "https://") + "/widgets/sub/v2"
+
+	reg := New(proxy.URL, client)
+	if _, err := reg.FetchVersions(context.Background(), module); err == nil {
+		t.Fatal("expected an error without direct mode")
+	}
+
+	reg = reg.WithDirectFallback()
+	versions, err := reg.FetchVersions(context.Background(), module)
+	if err != nil {
+		t.Fatalf("FetchVersions failed: %v", err)
+	}
+	var got []string
+	for _, v := range versions {
+		got = append(got, v.Number)
+	}
+	want := []string{"v2.1.0-rc.1", "v2.0.1", "v2.0.0"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("versions = %v, want %v", got, want)
+	}
+
+	pkg, err := reg.FetchPackage(context.Background(), module)
+	if err != nil {
+		t.Fatalf("FetchPackage failed: %v", err)
+	}
+	if pkg.LatestVersion != "v2.0.1" {
+		t.Errorf("LatestVersion = %q, want v2.0.1", pkg.LatestVersion)
+	}
+	if pkg.Repository != vcs.URL+"/widgets" {
+		t.Errorf("Repository = %q", pkg.Repository)
+	}
+
+	if _, err := reg.FetchDependencies(context.Background(), module, "v2.0.1"); !errors.Is(err, core.ErrNotSupported) {
+		t.Errorf("FetchDependencies error = %v, want ErrNotSupported", err)
+	}
+
+	hits := proxyHits
+	private := reg.WithDirectFallback(strings.TrimPrefix(vcs.URL, "https://") + "/widgets")
+	if _, err := private.FetchVersions(context.Background(), module); err != nil {
+		t.Fatalf("FetchVersions failed: %v", err)
+	}
+	if proxyHits != hits {
+		t.Error("private module was requested from the proxy")
+	}
+}
+
+func TestDirectAuth(t *testing.T) {
+	var vcs *httptest.Server
+	vcs = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Private repositories look missing without credentials
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(404)
+			return
+		}
+		host := strings.TrimPrefix(vcs.URL, "https://")
+		switch {
+		case r.URL.Query().Get("go-get") == "1":
+			fmt.Fprintf(w, `<meta name="go-import" content="%s/widgets git https://%s/widgets.git">`, host, host)
+		case r.URL.Path == "/widgets.git/info/refs":
+			_, _ = w.Write([]byte(pktLine("# service=git-upload-pack\n") + "0000" +
+				pktLine("2222222222222222222222222222222222222222 refs/tags/v1.0.0\n") + "0000"))
+		default:
+			w.WriteHeader(404)
+		}
+	}))
+	defer vcs.Close()
+
+	var proxyAuth []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxyAuth = append(proxyAuth, r.Header.Get("Authorization"))
+		w.WriteHeader(http.StatusGone)
+	}))
+	defer proxy.Close()
+
+	client := core.DefaultClient()
+	client.HTTPClient = vcs.Client()
+	host := strings.TrimPrefix(vcs.URL, "https://")
+	module := host + "/widgets"
+
+	reg := New(proxy.URL, client).WithDirectFallback()
+	if _, err := reg.FetchVersions(context.Background(), module); !errors.Is(err, core.ErrNotFound) {
+		t.Errorf("expected ErrNotFound without credentials, got %v", err)
+	}
+
+	versions, err := reg.WithDirectAuth(host, "Authorization", "Bearer secret").FetchVersions(context.Background(), module)
+	if err != nil || len(versions) != 1 || versions[0].Number != "v1.0.0" {
+		t.Fatalf("FetchVersions = %+v, %v", versions, err)
+	}
+	if len(proxyAuth) != 2 {
+		t.Fatalf("expected the proxy to be asked twice, got %d", len(proxyAuth))
+	}
+	for _, auth := range proxyAuth {
+		if auth != "" {
+			t.Errorf("proxy received credentials %q", auth)
+		}
+	}
+}
+
+func TestIsPrivate(t *testing.T) {
+	reg := New("", nil).WithDirectFallback("*.corp.example.com,github.com/myorg", "git.example.com/*/tools")
+	tests := map[string]bool{
+		"git.corp.example.com/team/repo": true,
+		"corp.example.com/team/repo":     false,
+		"github.com/myorg/repo/sub":      true,
+		"github.com/myorganisation/repo": false,
+		"git.example.com/team/tools/v2":  true,
+		"git.example.com/team/other":     false,
+		"git.example.com/team":           false,
+	}
+	for module, want := range tests {
+		if got := reg.isPrivate(module); got != want {
+			t.Errorf("isPrivate(%q) = %v, want %v", module, got, want)
+		}
+	}
+}
+
+func TestParseGoImports(t *testing.T) {
+	page := `<!DOCTYPE html><html><head>
+<meta name="go-import" content="example.com/mono git https://git.example.com/mono">
+<META content='example.com/mono/tools mod https://proxy.example.com' name='go-import'/>
+<meta name="description" content="not this one">
+</head></html>`
+	roots := parseGoImports(page)
+	if len(roots) != 2 {
+		t.Fatalf("got %d go-import tags, want 2", len(roots))
+	}
+
+	root := matchGoImport(roots, "example.com/mono/tools/cmd")
+	if root == nil || root.vcs != "mod" || root.url != "https://proxy.example.com" {
+		t.Errorf("matchGoImport picked %+v", root)
+	}
+	root = matchGoImport(roots, "example.com/mono/lib/v3")
+	if root == nil || root.vcs != "git" {
+		t.Fatalf("matchGoImport picked %+v", root)
+	}
+	dir, prefix := modulePaths("example.com/mono/lib/v3", root)
+	if dir != "lib/v3" || prefix != "lib/" {
+		t.Errorf("modulePaths = %q, %q", dir, prefix)
+	}
+	if matchGoImport(roots, "example.com/monolith") != nil {
+		t.Error("matched a prefix that isn't a path element boundary")
+	}
+}
+
+func TestModuleVersion(t *testing.T) {
+	tests := []struct {
+		module, tag string
+		want        bool
+	}{
+		{"example.com/a", "v1.2.3", true},
+		{"example.com/a", "v0.1.0-beta.1", true},
+		{"example.com/a", "v2.0.0", false},
+		{"example.com/a", "1.2.3", false},
+		{"example.com/a", "v1.2", false},
+		{"example.com/a/v2", "v2.0.0", true},
+		{"example.com/a/v2", "v1.9.0", false},
+		{"example.com/a/v02", "v1.0.0", true},
+	}
+	for _, tt := range tests {
+		if got := moduleVersion(tt.module, tt.tag); got != tt.want {
+			t.Errorf("moduleVersion(%q, %q) = %v, want %v", tt.module, tt.tag, got, tt.want)
+		}
+	}
+}
+
+func TestRawFileURL(t *testing.T) {
+	tests := map[string]string{
+		"https://github.com":      "https://raw.githubusercontent.com/org/repo/sub/v1.0.0/sub/go.mod",
+		"https://gitlab.com":      "https://gitlab.com/org/repo/-/raw/sub/v1.0.0/sub/go.mod",
+		"https://codeberg.org":    "https://codeberg.org/org/repo/raw/tag/sub/v1.0.0/sub/go.mod",
+		"https://git.example.com": "",
+	}
+	for host, want := range tests {
+		if got := rawFileURL(host, "org/repo", "sub/v1.0.0", "sub/go.mod"); got != want {
+			t.Errorf("rawFileURL(%q) = %q, want %q", host, got, want)
+		}
+	}
+}
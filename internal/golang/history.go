@@ -0,0 +1,24 @@
+package golang
+
+import (
+	"context"
+	"time"
+
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+// FetchPackageAt reconstructs a module as of at from the times the proxy
+// records for each tagged version. As with the go command, the latest
+// version then is the newest release, or the newest pre-release if there
+// was none.
+func (r *Registry) FetchPackageAt(ctx context.Context, name string, at time.Time) (*core.PackageSnapshot, error) {
+	pkg, err := r.FetchPackage(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	versions, err := r.FetchVersions(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return core.SnapshotAt(ecosystem, *pkg, versions, at)
+}
@@ -0,0 +1,142 @@
+package golang
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+type githubOwnerResponse struct {
+	Login   string `json:"login"`
+	Type    string `json:"type"`
+	Name    string `json:"name"`
+	HTMLURL string `json:"html_url"`
+	Blog    string `json:"blog"`
+	Company string `json:"company"`
+}
+
+type githubOrgResponse struct {
+	IsVerified bool `json:"is_verified"`
+}
+
+type githubMemberResponse struct {
+	Login   string `json:"login"`
+	HTMLURL string `json:"html_url"`
+}
+
+type githubRepoResponse struct {
+	FullName string `json:"full_name"`
+	Language string `json:"language"`
+	Fork     bool   `json:"fork"`
+}
+
+// githubOwner returns "github.com/<owner>" and the escaped owner for a
+// namespace of that form or any module path below it.
+func githubOwner(namespace string) (name, owner string, err error) {
+	parts := strings.Split(strings.TrimSuffix(namespace, "/"), "/")
+	if len(parts) < 2 || parts[0] != "github.com" || parts[1] == "" {
+		return "", "", fmt.Errorf("golang namespace %q: only github.com owners are supported: %w", namespace, core.ErrNotSupported)
+	}
+	return parts[0] + "/" + parts[1], url.PathEscape(parts[1]), nil
+}
+
+// FetchNamespace returns the GitHub user or organization owning modules
+// under namespace, which is "github.com/<owner>" or any module path below
+// it. Organizations are verified when GitHub has verified one of their
+// domains, and their members are those who made their membership public.
+// Module paths on other hosts return an error wrapping ErrNotSupported.
+//
+// The requests go to the GitHub API, which allows few unauthenticated
+// requests; set the client's AuthFunc for api.github.com when fetching
+// many owners.
+func (r *Registry) FetchNamespace(ctx context.Context, namespace string) (*core.Namespace, error) {
+	name, owner, err := githubOwner(namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	var user githubOwnerResponse
+	if err := r.client.GetJSON(ctx, fmt.Sprintf("%s/users/%s", r.githubAPI, owner), &user); err != nil {
+		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
+			return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name}
+		}
+		return nil, err
+	}
+
+	ns := &core.Namespace{
+		Ecosystem: ecosystem,
+		Name:      name,
+		URL:       user.HTMLURL,
+		Metadata:  map[string]any{},
+	}
+	if user.Name != "" {
+		ns.Metadata["name"] = user.Name
+	}
+	if user.Blog != "" {
+		ns.Metadata["website"] = user.Blog
+	}
+	if user.Company != "" {
+		ns.Metadata["company"] = user.Company
+	}
+
+	if user.Type != "Organization" {
+		ns.Kind = core.NamespaceUser
+		ns.Members = []core.Maintainer{{Login: user.Login, Name: user.Name, URL: user.HTMLURL}}
+		return ns, nil
+	}
+
+	ns.Kind = core.NamespaceOrg
+	var org githubOrgResponse
+	if err := r.client.GetJSON(ctx, fmt.Sprintf("%s/orgs/%s", r.githubAPI, owner), &org); err != nil {
+		return nil, err
+	}
+	ns.Verified = org.IsVerified
+
+	var members []githubMemberResponse
+	if err := r.client.GetJSON(ctx, fmt.Sprintf("%s/orgs/%s/public_members?per_page=100", r.githubAPI, owner), &members); err != nil {
+		return nil, err
+	}
+	for _, m := range members {
+		ns.Members = append(ns.Members, core.Maintainer{Login: m.Login, URL: m.HTMLURL, Role: "member"})
+	}
+	return ns, nil
+}
+
+// ListPackagesByNamespace returns the module paths of a GitHub owner's
+// repositories whose main language is Go, for a namespace of the form
+// "github.com/<owner>". Forks are left out. Each repository is taken to be
+// one module at its root, so modules in subdirectories and major version
+// suffixes aren't listed. Module paths on other hosts return an error
+// wrapping ErrNotSupported.
+func (r *Registry) ListPackagesByNamespace(ctx context.Context, namespace string) ([]string, error) {
+	name, owner, err := githubOwner(namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	var modules []string
+	for page := 1; ; page++ {
+		var repos []githubRepoResponse
+		endpoint := fmt.Sprintf("%s/users/%s/repos?per_page=100&page=%d", r.githubAPI, owner, page)
+		if err := r.client.GetJSON(ctx, endpoint, &repos); err != nil {
+			if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
+				return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name}
+			}
+			return nil, err
+		}
+		for _, repo := range repos {
+			if repo.Language == "Go" && !repo.Fork {
+				modules = append(modules, "github.com/"+repo.FullName)
+			}
+		}
+		if len(repos) < 100 {
+			break
+		}
+	}
+	sort.Strings(modules)
+	return modules, nil
+}
@@ -0,0 +1,98 @@
+package golang
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+func TestFetchNamespace(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/users/golang":
+			_, _ = w.Write([]byte(`{"login":"golang","type":"Organization","name":"Go","html_url":"https://github.com/golang","blog":"https://go.dev"}`))
+		case "/orgs/golang":
+			_, _ = w.Write([]byte(`{"login":"golang","is_verified":true}`))
+		case "/orgs/golang/public_members":
+			_, _ = w.Write([]byte(`[{"login":"rsc","html_url":"https://github.com/rsc"}]`))
+		case "/users/rsc":
+			_, _ = w.Write([]byte(`{"login":"rsc","type":"User","name":"Russ Cox","html_url":"https://github.com/rsc"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	reg := New("", core.DefaultClient())
+	reg.githubAPI = server.URL
+	ctx := context.Background()
+
+	ns, err := reg.FetchNamespace(ctx, "github.com/golang/go")
+	if err != nil {
+		t.Fatalf("FetchNamespace failed: %v", err)
+	}
+	if ns.Name != "github.com/golang" || ns.Kind != core.NamespaceOrg || !ns.Verified || ns.Metadata["website"] != "https://go.dev" {
+		t.Errorf("unexpected namespace %+v", ns)
+	}
+	if len(ns.Members) != 1 || ns.Members[0].Login != "rsc" {
+		t.Errorf("unexpected members %+v", ns.Members)
+	}
+
+	user, err := reg.FetchNamespace(ctx, "github.com/rsc")
+	if err != nil {
+		t.Fatalf("FetchNamespace failed: %v", err)
+	}
+	if user.Kind != core.NamespaceUser || user.Verified || len(user.Members) != 1 {
+		t.Errorf("unexpected namespace %+v", user)
+	}
+
+	if _, err := reg.FetchNamespace(ctx, "github.com/missing"); !errors.Is(err, core.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	if _, err := reg.FetchNamespace(ctx, "golang.org/x"); !errors.Is(err, core.ErrNotSupported) {
+		t.Errorf("expected ErrNotSupported, got %v", err)
+	}
+}
+
+func TestListPackagesByNamespace(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/users/golang/repos" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.URL.Query().Get("page") != "1" {
+			_, _ = w.Write([]byte(`[]`))
+			return
+		}
+		_, _ = w.Write([]byte(`[
+			{"full_name":"golang/tools","language":"Go"},
+			{"full_name":"golang/vscode-go","language":"TypeScript"},
+			{"full_name":"golang/mock","language":"Go","fork":true},
+			{"full_name":"golang/net","language":"Go"}
+		]`))
+	}))
+	defer server.Close()
+
+	reg := New("", core.DefaultClient())
+	reg.githubAPI = server.URL
+	ctx := context.Background()
+
+	modules, err := reg.ListPackagesByNamespace(ctx, "github.com/golang")
+	if err != nil {
+		t.Fatalf("ListPackagesByNamespace failed: %v", err)
+	}
+	if len(modules) != 2 || modules[0] != "github.com/golang/net" || modules[1] != "github.com/golang/tools" {
+		t.Errorf("unexpected modules %v", modules)
+	}
+
+	if _, err := reg.ListPackagesByNamespace(ctx, "github.com/nobody"); !errors.Is(err, core.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	if _, err := reg.ListPackagesByNamespace(ctx, "golang.org/x"); !errors.Is(err, core.ErrNotSupported) {
+		t.Errorf("expected ErrNotSupported, got %v", err)
+	}
+}
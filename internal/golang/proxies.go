@@ -0,0 +1,105 @@
+package golang
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+// proxy is one entry of a GOPROXY list.
+type proxy struct {
+	url string // a proxy's base URL, "direct" or "off"
+
+	// anyError moves on to the next entry after any error from this one,
+	// as when a "|" follows it. After a "," only 404 and 410 do.
+	anyError bool
+}
+
+// parseProxyList reads a GOPROXY value such as
+// "https://proxy.corp.example|https://proxy.golang.org,direct".
+func parseProxyList(list string) []proxy {
+	var proxies []proxy
+	for list != "" {
+		entry, sep := list, byte(0)
+		if i := strings.IndexAny(list, ",|"); i >= 0 {
+			entry, sep, list = list[:i], list[i], list[i+1:]
+		} else {
+			list = ""
+		}
+		entry = strings.TrimSuffix(strings.TrimSpace(entry), "/")
+		if entry == "" {
+			continue
+		}
+		proxies = append(proxies, proxy{url: entry, anyError: sep == '|'})
+	}
+	return proxies
+}
+
+// WithProxyList returns a new Registry that asks the proxies of a GOPROXY
+// list in turn, as the go command does. A proxy followed by "," is passed
+// over only when it answers 404 or 410; one followed by "|" is passed over
+// after any error, including an unreachable proxy. "direct" resolves a
+// module from its repository, as WithDirectFallback does, and "off" fails
+// the lookup. An empty list goes back to the registry's base URL alone.
+// Credentials from the client's AuthFunc are sent to whichever proxies it
+// covers.
+func (r *Registry) WithProxyList(goproxy string) *Registry {
+	copy := *r
+	copy.proxies = parseProxyList(goproxy)
+	for _, p := range copy.proxies {
+		if p.url != "direct" && p.url != "off" {
+			copy.urls = &URLs{baseURL: p.url}
+			break
+		}
+	}
+	return &copy
+}
+
+// proxyList returns the entries a lookup tries, in order: the GOPROXY list,
+// or the base URL, followed by direct resolution when direct fallback is
+// on.
+func (r *Registry) proxyList() []proxy {
+	list := r.proxies
+	if len(list) == 0 {
+		list = []proxy{{url: r.baseURL}}
+	}
+	if r.direct && list[len(list)-1].url != "direct" {
+		list = append(slices.Clip(list), proxy{url: "direct"})
+	}
+	return list
+}
+
+// lookup calls fetch with the base URL of each proxy in turn until one
+// answers, or direct for a "direct" entry and for private modules, and
+// returns the error of the last one tried.
+func (r *Registry) lookup(name string, fetch func(baseURL string) error, direct func() error) error {
+	if r.isPrivate(name) {
+		return direct()
+	}
+	var err error
+	for _, p := range r.proxyList() {
+		switch p.url {
+		case "direct":
+			return direct()
+		case "off":
+			return fmt.Errorf("%s: module lookup disabled by GOPROXY=off", name)
+		}
+		if err = fetch(p.url); err == nil {
+			return nil
+		}
+		if !p.anyError && !notFoundOrGone(err) {
+			return err
+		}
+	}
+	return err
+}
+
+// notFoundOrGone reports whether a proxy error says the proxy doesn't have
+// the module, which lets the go command try the next proxy.
+func notFoundOrGone(err error) bool {
+	httpErr, ok := err.(*core.HTTPError)
+	return ok && (httpErr.IsNotFound() || httpErr.StatusCode == http.StatusGone)
+}
@@ -0,0 +1,86 @@
+package golang
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+func TestParseProxyList(t *testing.T) {
+	got := parseProxyList(" https://a.example/ |https://b.example,,direct,off")
+	want := []proxy{
+		{url: "https://a.example", anyError: true},
+		{url: "https://b.example"},
+		{url: "direct"},
+		{url: "off"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseProxyList = %+v, want %+v", got, want)
+	}
+}
+
+func TestProxyListFallback(t *testing.T) {
+	// The corporate proxy only has internal modules and is failing for
+	// one of them
+	corp := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/corp.example/lib/"):
+			_, _ = w.Write([]byte("v1.0.0\n"))
+		case strings.HasPrefix(r.URL.Path, "/corp.example/broken/"):
+			w.WriteHeader(http.StatusForbidden)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer corp.Close()
+	var publicHits []string
+	public := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		publicHits = append(publicHits, r.URL.Path)
+		switch r.URL.Path {
+		case "/github.com/pkg/errors/@v/list", "/corp.example/broken/@v/list":
+			_, _ = w.Write([]byte("v0.9.1\n"))
+		case "/github.com/pkg/errors/@v/v0.9.1.info":
+			_, _ = w.Write([]byte(`{"Version": "v0.9.1", "Time": "2020-01-14T19:47:44Z"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer public.Close()
+	ctx := context.Background()
+
+	comma := New("", core.DefaultClient()).WithProxyList(corp.URL + "," + public.URL)
+	if pkg, err := comma.FetchPackage(ctx, "corp.example/lib"); err != nil || pkg.LatestVersion != "v1.0.0" {
+		t.Errorf("FetchPackage from the first proxy = %+v, %v", pkg, err)
+	}
+	versions, err := comma.FetchVersions(ctx, "github.com/pkg/errors")
+	if err != nil || len(versions) != 1 || versions[0].PublishedAt.IsZero() {
+		t.Errorf("FetchVersions after a 404 = %+v, %v", versions, err)
+	}
+	// After "," only 404 and 410 move on
+	if _, err := comma.FetchPackage(ctx, "corp.example/broken"); err == nil {
+		t.Error("expected the first proxy's 403 to be returned")
+	}
+	if got := comma.URLs().Download("corp.example/lib", "v1.0.0"); got != corp.URL+"/corp.example/lib/@v/v1.0.0.zip" {
+		t.Errorf("expected downloads from the first proxy, got %q", got)
+	}
+
+	// After "|" any error does
+	pipe := New("", core.DefaultClient()).WithProxyList(corp.URL + "|" + public.URL)
+	if pkg, err := pipe.FetchPackage(ctx, "corp.example/broken"); err != nil || pkg.LatestVersion != "v0.9.1" {
+		t.Errorf("FetchPackage after a 403 = %+v, %v", pkg, err)
+	}
+
+	off := New("", core.DefaultClient()).WithProxyList(corp.URL + ",off")
+	publicHits = nil
+	if _, err := off.FetchPackage(ctx, "github.com/pkg/errors"); err == nil || !strings.Contains(err.Error(), "GOPROXY=off") {
+		t.Errorf("expected GOPROXY=off error, got %v", err)
+	}
+	if len(publicHits) != 0 {
+		t.Errorf("off sent requests on: %v", publicHits)
+	}
+}
@@ -0,0 +1,65 @@
+package hackage
+
+import "strings"
+
+// Flag is a flag stanza of a cabal file, which configures the conditional
+// blocks that test flag(name). Flags are turned on or off when a package is
+// built, and cabal's solver may flip those that aren't manual to find a
+// build plan.
+type Flag struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Default     bool   `json:"default"`
+	Manual      bool   `json:"manual,omitempty"`
+}
+
+// parseFlags returns the flags a cabal file declares, in file order. Flag
+// names are case-insensitive and returned in lower case, as cabal compares
+// them.
+func parseFlags(content string) []Flag {
+	var flags []Flag
+	var current *Flag
+	var field string
+
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+
+		if line[0] != ' ' && line[0] != '\t' {
+			current = nil
+			fields := strings.Fields(trimmed)
+			if len(fields) == 2 && strings.EqualFold(fields[0], "flag") {
+				// Cabal defaults a flag to on
+				flags = append(flags, Flag{Name: strings.ToLower(fields[1]), Default: true})
+				current = &flags[len(flags)-1]
+			}
+			continue
+		}
+		if current == nil {
+			continue
+		}
+
+		key, value, ok := strings.Cut(trimmed, ":")
+		if !ok || strings.ContainsAny(strings.TrimSpace(key), " \t") {
+			// Continuation of a multi-line description
+			if field == "description" {
+				current.Description += " " + trimmed
+			}
+			continue
+		}
+		field = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+		switch field {
+		case "description":
+			current.Description = value
+		case "default":
+			current.Default = strings.EqualFold(value, "true")
+		case "manual":
+			current.Manual = strings.EqualFold(value, "true")
+		}
+	}
+
+	return flags
+}
@@ -133,10 +133,14 @@ func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package
 		Repository:  repository,
 		Licenses:    cabal.License,
 		Keywords:    keywords,
+		Categories:  keywords,
 		Metadata: map[string]any{
 			"author":     cabal.Author,
 			"maintainer": cabal.Maintainer,
+			"flags":      parseFlags(string(cabalBody)),
 		},
+		// Hackage versions have no pre-release form
+		LatestVersion: latestVersion,
 	}, nil
 }
 
@@ -334,6 +338,54 @@ func (r *Registry) FetchDependencies(ctx context.Context, name, version string)
 	return deps, nil
 }
 
+// cabalStanza is the top-level component a build-depends field belongs to.
+type cabalStanza struct {
+	kind  string // library, executable, test-suite, benchmark, foreign-library, common
+	name  string
+	scope core.Scope
+}
+
+// component returns the stanza as written in the cabal file, e.g. "test-suite spec".
+func (s cabalStanza) component() string {
+	if s.name == "" {
+		return s.kind
+	}
+	return s.kind + " " + s.name
+}
+
+// parseStanza recognizes a top-level section header such as "library",
+// "executable aeson-bench" or "test-suite spec".
+func parseStanza(line string) (cabalStanza, bool) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return cabalStanza{}, false
+	}
+
+	stanza := cabalStanza{kind: strings.ToLower(fields[0])}
+	if len(fields) > 1 {
+		stanza.name = fields[1]
+	}
+
+	switch stanza.kind {
+	case "library", "executable", "foreign-library", "common":
+		stanza.scope = core.Runtime
+	case "test-suite":
+		stanza.scope = core.Test
+	case "benchmark":
+		stanza.scope = core.Development
+	default:
+		return cabalStanza{}, false
+	}
+	return stanza, true
+}
+
+// parseDependencies collects build-depends from every component stanza.
+// Each dependency is attributed to its stanza via Metadata["component"] and
+// scoped by stanza kind: test suites are Test, benchmarks Development, and
+// libraries and executables Runtime (executables also set Metadata["executable"]).
+// Dependencies inside if/else blocks record the conditions of the branches
+// enclosing them, outermost first, in Metadata["conditions"], with an else
+// branch written as the negation of its if.
 func parseDependencies(content string) []core.Dependency {
 	var deps []core.Dependency
 	seen := make(map[string]bool)
@@ -343,39 +395,73 @@ func parseDependencies(content string) []core.Dependency {
 
 	lines := strings.Split(content, "\n")
 	inBuildDepends := false
+	dependsIndent := 0
+	stanza := cabalStanza{kind: "library", scope: core.Runtime}
+	var branches []branch
 
 	for _, line := range lines {
 		lowerLine := strings.ToLower(strings.TrimSpace(line))
+		if lowerLine == "" || strings.HasPrefix(lowerLine, "--") {
+			continue
+		}
+		indent := len(line) - len(strings.TrimLeft(line, " \t"))
+
+		// Top-level section headers switch the component being parsed
+		if indent == 0 {
+			branches = nil
+			if !strings.Contains(line, ":") {
+				if next, ok := parseStanza(line); ok {
+					stanza = next
+					inBuildDepends = false
+					continue
+				}
+			}
+		}
+
+		// Leaving the body of an if or else
+		for len(branches) > 0 && indent < branches[len(branches)-1].indent {
+			branches = branches[:len(branches)-1]
+		}
+		if len(branches) > 0 && indent == branches[len(branches)-1].indent {
+			top := &branches[len(branches)-1]
+			if lowerLine == "else" || strings.HasPrefix(lowerLine, "else ") {
+				top.condition = "!(" + top.condition + ")"
+				inBuildDepends = false
+				continue
+			}
+			branches = branches[:len(branches)-1]
+		}
+		if strings.HasPrefix(lowerLine, "if ") {
+			branches = append(branches, branch{indent: indent, condition: strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(line)[3:], "{"))})
+			inBuildDepends = false
+			continue
+		}
 
 		// Check for build-depends: line (case insensitive)
 		if strings.HasPrefix(lowerLine, "build-depends:") {
 			inBuildDepends = true
+			dependsIndent = indent
 			// Get the part after build-depends:
 			idx := strings.Index(strings.ToLower(line), "build-depends:")
 			if idx >= 0 {
 				rest := strings.TrimSpace(line[idx+14:])
 				if rest != "" {
-					processDeps(rest, &deps, seen, depItemRegex)
+					processDeps(rest, stanza, conditions(branches), &deps, seen, depItemRegex)
 				}
 			}
 			continue
 		}
 
-		// Continue parsing if we're in a build-depends block (continuation lines start with whitespace)
+		// Continue parsing if we're in a build-depends block (continuation lines are indented further)
 		if inBuildDepends {
 			trimmed := strings.TrimSpace(line)
 
-			// If line doesn't start with whitespace, we're done with this build-depends block
-			if !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "\t") && line != "" {
+			// A line no further indented than build-depends: ends the block
+			if indent <= dependsIndent {
 				inBuildDepends = false
 				continue
 			}
 
-			// Skip empty lines or comments
-			if trimmed == "" || strings.HasPrefix(trimmed, "--") {
-				continue
-			}
-
 			// Check if this looks like a new field (has a colon not in version constraint)
 			if strings.Contains(trimmed, ":") {
 				colonIdx := strings.Index(trimmed, ":")
@@ -387,14 +473,33 @@ func parseDependencies(content string) []core.Dependency {
 				}
 			}
 
-			processDeps(trimmed, &deps, seen, depItemRegex)
+			processDeps(trimmed, stanza, conditions(branches), &deps, seen, depItemRegex)
 		}
 	}
 
 	return deps
 }
 
-func processDeps(line string, deps *[]core.Dependency, seen map[string]bool, depRegex *regexp.Regexp) {
+// branch is an if or else block enclosing the line being parsed.
+type branch struct {
+	indent    int    // indentation of the if line
+	condition string // e.g. "flag(embed_data_files)" or "!(os(windows))"
+}
+
+func conditions(branches []branch) []string {
+	if len(branches) == 0 {
+		return nil
+	}
+	conds := make([]string, len(branches))
+	for i, b := range branches {
+		conds[i] = b.condition
+	}
+	return conds
+}
+
+func processDeps(line string, stanza cabalStanza, conds []string, deps *[]core.Dependency, seen map[string]bool, depRegex *regexp.Regexp) {
+	component := stanza.component()
+
 	// Split by comma
 	parts := strings.Split(line, ",")
 	for _, part := range parts {
@@ -406,20 +511,30 @@ func processDeps(line string, deps *[]core.Dependency, seen map[string]bool, dep
 		matches := depRegex.FindStringSubmatch(part)
 		if len(matches) > 1 {
 			name := matches[1]
-			if name == "base" || seen[name] {
+			key := component + "\x00" + strings.Join(conds, "\x00") + "\x00" + name
+			if name == "base" || seen[key] {
 				continue
 			}
-			seen[name] = true
+			seen[key] = true
 
 			requirements := ""
 			if len(matches) > 2 {
 				requirements = strings.TrimSpace(matches[2])
 			}
 
+			metadata := map[string]any{"component": component}
+			if stanza.kind == "executable" {
+				metadata["executable"] = true
+			}
+			if conds != nil {
+				metadata["conditions"] = conds
+			}
+
 			*deps = append(*deps, core.Dependency{
 				Name:         name,
 				Requirements: requirements,
-				Scope:        core.Runtime,
+				Scope:        stanza.scope,
+				Metadata:     metadata,
 			})
 		}
 	}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/git-pkgs/registries/internal/core"
@@ -152,6 +153,136 @@ test-suite tests
 	}
 }
 
+func TestParseDependenciesComponents(t *testing.T) {
+	cabal := `name:           pandoc
+version:        3.1
+
+library
+  build-depends:    base >= 4.12 && < 5,
+                    text >= 1.1.1.0
+  if flag(embed_data_files)
+    build-depends:  file-embed >= 0.0 && < 0.1
+
+executable pandoc
+  main-is:          pandoc.hs
+  build-depends:    pandoc, text
+
+test-suite test-pandoc
+  type:             exitcode-stdio-1.0
+  build-depends:    tasty >= 0.11,
+                    text
+
+benchmark benchmark-pandoc
+  build-depends:    tasty-bench >= 0.2
+`
+
+	deps := parseDependencies(cabal)
+
+	type attribution struct {
+		scope     core.Scope
+		component string
+	}
+	got := make(map[string][]attribution)
+	for _, d := range deps {
+		got[d.Name] = append(got[d.Name], attribution{d.Scope, d.Metadata["component"].(string)})
+	}
+
+	if a := got["file-embed"]; len(a) != 1 || a[0].component != "library" || a[0].scope != core.Runtime {
+		t.Errorf("unexpected file-embed attribution: %v", a)
+	}
+	if a := got["tasty"]; len(a) != 1 || a[0].component != "test-suite test-pandoc" || a[0].scope != core.Test {
+		t.Errorf("unexpected tasty attribution: %v", a)
+	}
+	if a := got["tasty-bench"]; len(a) != 1 || a[0].component != "benchmark benchmark-pandoc" || a[0].scope != core.Development {
+		t.Errorf("unexpected tasty-bench attribution: %v", a)
+	}
+	if a := got["text"]; len(a) != 3 {
+		t.Errorf("expected text in 3 components, got %v", a)
+	}
+
+	for _, d := range deps {
+		if d.Name == "pandoc" {
+			if d.Metadata["executable"] != true {
+				t.Error("expected pandoc executable dependency to be flagged")
+			}
+			if d.Metadata["component"] != "executable pandoc" {
+				t.Errorf("unexpected component: %v", d.Metadata["component"])
+			}
+		}
+	}
+}
+
+func TestParseDependenciesConditions(t *testing.T) {
+	cabal := `name:           process
+version:        1.6.19.0
+
+flag old-time
+  description: Build against the old-time package,
+               as GHC 7 shipped
+  default:     False
+  manual:      True
+
+library
+  build-depends:    base >= 4.10 && < 4.20,
+                    deepseq >= 1.1 && < 1.6
+  if os(windows)
+    cpp-options:    -DWINDOWS
+    build-depends:  Win32 >= 2.4 && < 2.14
+    if flag(old-time)
+      build-depends: old-time
+  else
+    build-depends:  unix >= 2.5 && < 2.9
+  ghc-options:      -Wall
+  build-depends:    filepath
+
+test-suite spec
+  if impl(ghc >= 9.2) {
+    build-depends:  hspec
+  }
+`
+
+	deps := parseDependencies(cabal)
+	got := make(map[string][]string)
+	for _, d := range deps {
+		if d.Name == "if" || d.Name == "else" {
+			t.Errorf("conditional parsed as a dependency: %+v", d)
+		}
+		conds, _ := d.Metadata["conditions"].([]string)
+		got[d.Name] = conds
+	}
+
+	tests := map[string][]string{
+		"deepseq":  nil,
+		"Win32":    {"os(windows)"},
+		"old-time": {"os(windows)", "flag(old-time)"},
+		"unix":     {"!(os(windows))"},
+		"filepath": nil,
+		"hspec":    {"impl(ghc >= 9.2)"},
+	}
+	if len(got) != len(tests) {
+		t.Errorf("expected %d dependencies, got %v", len(tests), got)
+	}
+	for name, want := range tests {
+		conds, ok := got[name]
+		if !ok {
+			t.Errorf("missing dependency %s", name)
+			continue
+		}
+		if strings.Join(conds, " && ") != strings.Join(want, " && ") {
+			t.Errorf("%s conditions = %q, want %q", name, conds, want)
+		}
+	}
+
+	flags := parseFlags(cabal)
+	if len(flags) != 1 {
+		t.Fatalf("expected 1 flag, got %+v", flags)
+	}
+	want := Flag{Name: "old-time", Description: "Build against the old-time package, as GHC 7 shipped", Default: false, Manual: true}
+	if flags[0] != want {
+		t.Errorf("parseFlags = %+v, want %+v", flags[0], want)
+	}
+}
+
 func TestParseCabalFile(t *testing.T) {
 	cabal := `name:           test-package
 version:        1.0.0
@@ -81,6 +81,11 @@ func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package
 	// Extract repository URL from website
 	repository := urlparser.Parse(resp.Website)
 
+	var numbers []string
+	for _, v := range resp.Versions {
+		numbers = append(numbers, v.Version)
+	}
+
 	return &core.Package{
 		Name:        resp.Name,
 		Description: resp.Description,
@@ -93,6 +98,9 @@ func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package
 			"downloads":    resp.Downloads,
 			"contributors": resp.Contributors,
 		},
+		Popularity:          &core.Popularity{DownloadsTotal: int64(resp.Downloads)},
+		LatestVersion:       core.LatestOf(ecosystem, numbers),
+		LatestStableVersion: core.LatestStableOf(ecosystem, numbers),
 	}, nil
 }
 
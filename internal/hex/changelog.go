@@ -0,0 +1,37 @@
+package hex
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+// FetchChangelog returns the notes for a version from the "Changelog" link
+// of the package's metadata when it points at a changelog file, and
+// otherwise from its repository's releases or CHANGELOG.md. Links to a
+// page on HexDocs can't be read as a changelog, so they fall back to the
+// repository too.
+func (r *Registry) FetchChangelog(ctx context.Context, name, version string) (*core.Document, error) {
+	pkg, err := r.FetchPackage(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if version == "" {
+		version = pkg.LatestVersion
+	}
+
+	links, _ := pkg.Metadata["links"].(map[string]string)
+	for k, v := range links {
+		switch strings.ToLower(k) {
+		case "changelog", "changes", "release notes":
+			doc, err := core.ChangelogFromURL(ctx, r.client, v, version)
+			if !errors.Is(err, core.ErrNotFound) {
+				return doc, err
+			}
+		}
+	}
+
+	return core.RepositoryChangelog(ctx, r.client, pkg.Repository, version)
+}
@@ -26,6 +26,7 @@ type Registry struct {
 	baseURL string
 	client  *core.Client
 	urls    *URLs
+	org     string // organization of names given without one; see WithOrganization
 }
 
 func New(baseURL string, client *core.Client) *Registry {
@@ -54,6 +55,11 @@ type packageResponse struct {
 	Releases  []releaseInfo    `json:"releases"`
 	Downloads downloadsInfo    `json:"downloads"`
 	Owners    []ownerInfo      `json:"owners"`
+	DocsHTMLURL         string `json:"docs_html_url"`
+	LatestVersion       string `json:"latest_version"`
+	LatestStableVersion string `json:"latest_stable_version"`
+	InsertedAt string          `json:"inserted_at"`
+	UpdatedAt  string          `json:"updated_at"`
 }
 
 type metaInfo struct {
@@ -68,7 +74,8 @@ type releaseInfo struct {
 }
 
 type downloadsInfo struct {
-	All int `json:"all"`
+	All    int `json:"all"`
+	Recent int `json:"recent"` // last 90 days
 }
 
 type ownerInfo struct {
@@ -91,7 +98,7 @@ type requirementInfo struct {
 }
 
 func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package, error) {
-	url := fmt.Sprintf("%s/api/packages/%s", r.baseURL, name)
+	url := r.packageURL(name)
 
 	var resp packageResponse
 	if err := r.client.GetJSON(ctx, url, &resp); err != nil {
@@ -119,21 +126,59 @@ func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package
 		repository = urlparser.Parse(homepage)
 	}
 
+	// docs_html_url is only set once docs have been published to HexDocs
+	documentation := links["docs"]
+	if documentation == "" {
+		documentation = links["documentation"]
+	}
+	if documentation == "" {
+		documentation = resp.DocsHTMLURL
+	}
+
+	var latestReleased time.Time
+	for _, rel := range resp.Releases {
+		if t, err := time.Parse(time.RFC3339, rel.InsertedAt); err == nil && t.After(latestReleased) {
+			latestReleased = t
+		}
+	}
+	insertedAt, _ := time.Parse(time.RFC3339, resp.InsertedAt)
+	updatedAt, _ := time.Parse(time.RFC3339, resp.UpdatedAt)
+
+	// An organization's packages keep their organization in the name, so
+	// the name round-trips through the URL builder and PURLs.
+	pkgName := resp.Name
+	org, _ := splitName(name, r.org)
+	if org != "" {
+		pkgName = org + "/" + resp.Name
+	}
+
 	return &core.Package{
-		Name:        resp.Name,
-		Description: resp.Meta.Description,
-		Homepage:    homepage,
-		Repository:  repository,
-		Licenses:    strings.Join(resp.Meta.Licenses, ","),
+		Name:          pkgName,
+		Namespace:     org,
+		Description:   resp.Meta.Description,
+		Homepage:      homepage,
+		Repository:    repository,
+		Documentation: documentation,
+		Licenses:      strings.Join(resp.Meta.Licenses, ","),
 		Metadata: map[string]any{
 			"downloads": resp.Downloads.All,
 			"links":     resp.Meta.Links,
 		},
+		Popularity: &core.Popularity{
+			DownloadsTotal:  int64(resp.Downloads.All),
+			DownloadsRecent: int64(resp.Downloads.Recent),
+			RecentDays:      90,
+		},
+		CreatedAt:           insertedAt,
+		UpdatedAt:           updatedAt,
+		LatestReleasedAt:    latestReleased,
+		LatestVersion:       resp.LatestVersion,
+		LatestStableVersion: resp.LatestStableVersion,
 	}, nil
 }
 
 func (r *Registry) FetchVersions(ctx context.Context, name string) ([]core.Version, error) {
-	url := fmt.Sprintf("%s/api/packages/%s", r.baseURL, name)
+	url := r.packageURL(name)
 
 	var resp packageResponse
 	if err := r.client.GetJSON(ctx, url, &resp); err != nil {
@@ -143,12 +188,27 @@ func (r *Registry) FetchVersions(ctx context.Context, name string) ([]core.Versi
 		return nil, err
 	}
 
+	// Each release needs its own request, so the time left before the
+	// caller's deadline is shared between them rather than spent on
+	// whichever comes first.
+	budget := core.NewBudget(ctx, "hex versions", len(resp.Releases))
 	versions := make([]core.Version, 0, len(resp.Releases))
 	for _, rel := range resp.Releases {
+		releaseCtx, cancel, err := budget.Next()
+		if err != nil {
+			return nil, budget.Exceeded(versions)
+		}
+
 		// Fetch detailed version info for checksum and retirement status
-		versionURL := fmt.Sprintf("%s/api/packages/%s/releases/%s", r.baseURL, name, rel.Version)
+		versionURL := r.releaseURL(name, rel.Version)
 		var versionResp versionResponse
-		if err := r.client.GetJSON(ctx, versionURL, &versionResp); err != nil {
+		err = r.client.GetJSON(releaseCtx, versionURL, &versionResp)
+		cancel()
+		budget.Done()
+		if err != nil {
+			if budget.Expired() {
+				return nil, budget.Exceeded(versions)
+			}
 			// If we can't get details, still include basic info
 			var publishedAt time.Time
 			if rel.InsertedAt != "" {
@@ -173,7 +233,7 @@ func (r *Registry) FetchVersions(ctx context.Context, name string) ([]core.Versi
 
 		var integrity string
 		if versionResp.Checksum != "" {
-			integrity = "sha256-" + versionResp.Checksum
+			integrity = core.HexIntegrity("sha256", versionResp.Checksum)
 		}
 
 		versions = append(versions, core.Version{
@@ -192,7 +252,7 @@ func (r *Registry) FetchVersions(ctx context.Context, name string) ([]core.Versi
 }
 
 func (r *Registry) FetchDependencies(ctx context.Context, name, version string) ([]core.Dependency, error) {
-	url := fmt.Sprintf("%s/api/packages/%s/releases/%s", r.baseURL, name, version)
+	url := r.releaseURL(name, version)
 
 	var resp versionResponse
 	if err := r.client.GetJSON(ctx, url, &resp); err != nil {
@@ -221,7 +281,7 @@ func (r *Registry) FetchDependencies(ctx context.Context, name, version string)
 }
 
 func (r *Registry) FetchMaintainers(ctx context.Context, name string) ([]core.Maintainer, error) {
-	url := fmt.Sprintf("%s/api/packages/%s", r.baseURL, name)
+	url := r.packageURL(name)
 
 	var resp packageResponse
 	if err := r.client.GetJSON(ctx, url, &resp); err != nil {
@@ -245,32 +305,58 @@ func (r *Registry) FetchMaintainers(ctx context.Context, name string) ([]core.Ma
 
 type URLs struct {
 	baseURL string
+	org     string
+}
+
+// fullName returns name with its organization, if it has one, as hex.pm's
+// package pages and PURLs write it.
+func (u *URLs) fullName(name string) string {
+	if org, pkg := splitName(name, u.org); org != "" {
+		return org + "/" + pkg
+	}
+	_, pkg := splitName(name, "")
+	return pkg
 }
 
 func (u *URLs) Registry(name, version string) string {
 	if version != "" {
-		return fmt.Sprintf("%s/packages/%s/%s", u.baseURL, name, version)
+		return fmt.Sprintf("%s/packages/%s/%s", u.baseURL, u.fullName(name), version)
 	}
-	return fmt.Sprintf("%s/packages/%s", u.baseURL, name)
+	return fmt.Sprintf("%s/packages/%s", u.baseURL, u.fullName(name))
 }
 
+// Download returns the tarball URL. An organization's tarballs are under
+// /repos/<org> on the same host and need the API key.
 func (u *URLs) Download(name, version string) string {
 	if version == "" {
 		return ""
 	}
-	return fmt.Sprintf("https://repo.hex.pm/tarballs/%s-%s.tar", name, version)
+	org, pkg := splitName(name, u.org)
+	if org != "" {
+		return fmt.Sprintf("%s/repos/%s/tarballs/%s-%s.tar", repoURL, org, pkg, version)
+	}
+	return fmt.Sprintf("%s/tarballs/%s-%s.tar", repoURL, pkg, version)
 }
 
+// Documentation returns the package's HexDocs. An organization's docs are
+// private, on a subdomain of its own.
 func (u *URLs) Documentation(name, version string) string {
+	docs := "https://hexdocs.pm"
+	org, pkg := splitName(name, u.org)
+	if org != "" {
+		docs = fmt.Sprintf("https://%s.hexdocs.pm", org)
+	}
 	if version != "" {
-		return fmt.Sprintf("https://hexdocs.pm/%s/%s", name, version)
+		return fmt.Sprintf("%s/%s/%s", docs, pkg, version)
 	}
-	return fmt.Sprintf("https://hexdocs.pm/%s", name)
+	return fmt.Sprintf("%s/%s", docs, pkg)
 }
 
+// PURL puts an organization in the namespace, as the PURL spec has it for
+// private hex packages.
 func (u *URLs) PURL(name, version string) string {
 	if version != "" {
-		return fmt.Sprintf("pkg:hex/%s@%s", name, version)
+		return fmt.Sprintf("pkg:hex/%s@%s", u.fullName(name), version)
 	}
-	return fmt.Sprintf("pkg:hex/%s", name)
+	return fmt.Sprintf("pkg:hex/%s", u.fullName(name))
 }
@@ -3,9 +3,11 @@ package hex
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/git-pkgs/registries/internal/core"
 )
@@ -32,6 +34,9 @@ func TestFetchPackage(t *testing.T) {
 			Owners: []ownerInfo{
 				{Username: "chrismccord", Email: "chris@example.com"},
 			},
+			LatestVersion:       "1.8.0-rc.0",
+			LatestStableVersion: "1.7.14",
+			DocsHTMLURL:         "https://hexdocs.pm/phoenix/",
 		}
 
 		w.Header().Set("Content-Type", "application/json")
@@ -54,6 +59,12 @@ func TestFetchPackage(t *testing.T) {
 	if pkg.Licenses != "MIT" {
 		t.Errorf("unexpected licenses: %q", pkg.Licenses)
 	}
+	if pkg.Documentation != "https://hexdocs.pm/phoenix/" {
+		t.Errorf("unexpected documentation: %q", pkg.Documentation)
+	}
+	if pkg.LatestVersion != "1.8.0-rc.0" || pkg.LatestStableVersion != "1.7.14" {
+		t.Errorf("latest = %q, stable = %q", pkg.LatestVersion, pkg.LatestStableVersion)
+	}
 }
 
 func TestFetchVersions(t *testing.T) {
@@ -71,7 +82,7 @@ func TestFetchVersions(t *testing.T) {
 		case "/api/packages/phoenix/releases/1.7.0":
 			resp := versionResponse{
 				Version:  "1.7.0",
-				Checksum: "abc123",
+				Checksum: "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad",
 				Downloads: 1000000,
 			}
 			_ = json.NewEncoder(w).Encode(resp)
@@ -105,7 +116,7 @@ func TestFetchVersions(t *testing.T) {
 	if versions[0].Number != "1.7.0" {
 		t.Errorf("expected version '1.7.0', got %q", versions[0].Number)
 	}
-	if versions[0].Integrity != "sha256-abc123" {
+	if versions[0].Integrity != "sha256-ungWv48Bz+pBQUDeXa4iI7ADYaOWF3qctBD/YfIAFa0=" {
 		t.Errorf("unexpected integrity: %q", versions[0].Integrity)
 	}
 	if versions[0].Status != core.StatusNone {
@@ -117,6 +128,50 @@ func TestFetchVersions(t *testing.T) {
 	}
 }
 
+func TestFetchVersionsDeadline(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/packages/phoenix":
+			resp := packageResponse{
+				Name: "phoenix",
+				Releases: []releaseInfo{
+					{Version: "1.7.0"},
+					{Version: "1.6.0"},
+					{Version: "1.5.0"},
+				},
+			}
+			_ = json.NewEncoder(w).Encode(resp)
+		case "/api/packages/phoenix/releases/1.7.0":
+			_ = json.NewEncoder(w).Encode(versionResponse{Version: "1.7.0", Checksum: "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"})
+		default:
+			// hang until the client gives up
+			<-r.Context().Done()
+		}
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+
+	reg := New(server.URL, core.DefaultClient())
+	_, err := reg.FetchVersions(ctx, "phoenix")
+
+	var budgetErr *core.BudgetExceededError
+	if !errors.As(err, &budgetErr) {
+		t.Fatalf("expected BudgetExceededError, got %v", err)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the error to wrap context.DeadlineExceeded")
+	}
+	partial, _ := budgetErr.Partial.([]core.Version)
+	if len(partial) == 0 || partial[0].Integrity != "sha256-ungWv48Bz+pBQUDeXa4iI7ADYaOWF3qctBD/YfIAFa0=" {
+		t.Errorf("expected the fetched release in the partial results, got %+v", budgetErr.Partial)
+	}
+	if budgetErr.Planned != 3 {
+		t.Errorf("expected 3 planned requests, got %d", budgetErr.Planned)
+	}
+}
+
 func TestFetchDependencies(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path != "/api/packages/phoenix/releases/1.7.0" {
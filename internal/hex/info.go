@@ -0,0 +1,25 @@
+package hex
+
+import (
+	"context"
+
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+// RegistryInfo returns the hex API root document, which describes the API.
+func (r *Registry) RegistryInfo(ctx context.Context) (*core.RegistryInfo, error) {
+	var doc map[string]any
+	if err := r.client.GetJSON(ctx, r.baseURL+"/api", &doc); err != nil {
+		return nil, err
+	}
+	return &core.RegistryInfo{
+		Ecosystem: ecosystem,
+		URL:       r.baseURL,
+		Metadata:  doc,
+	}, nil
+}
+
+// Ping requests the API root.
+func (r *Registry) Ping(ctx context.Context) error {
+	return core.PingURL(ctx, r.client, r.baseURL+"/api")
+}
@@ -0,0 +1,33 @@
+package hex
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+func TestRegistryInfo(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(`{"packages_url":"https://hex.pm/api/packages/{name}","documentation_url":"https://hex.pm/docs/api"}`))
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	info, err := reg.RegistryInfo(context.Background())
+	if err != nil {
+		t.Fatalf("RegistryInfo failed: %v", err)
+	}
+	if info.Ecosystem != "hex" || info.Metadata["documentation_url"] != "https://hex.pm/docs/api" {
+		t.Errorf("unexpected info %+v", info)
+	}
+	if err := reg.Ping(context.Background()); err != nil {
+		t.Errorf("Ping failed: %v", err)
+	}
+}
@@ -0,0 +1,88 @@
+package hex
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+// publicRepo is the repository of public packages on hex.pm. Organizations
+// each have a repository of their own, named after the organization.
+const publicRepo = "hexpm"
+
+// repoURL is where hex.pm serves tarballs, for public packages and
+// organizations alike.
+const repoURL = "https://repo.hex.pm"
+
+// WithOrganization returns a new Registry that looks names without an
+// organization up in org's private repository, as mix does for a project
+// whose dependencies set organization: org. Names of the form "org/name",
+// as in pkg:hex/org/name PURLs, are always looked up in the organization
+// they name, and "hexpm/name" in the public repository. Organization
+// packages need an API key; see WithAPIKey.
+func (r *Registry) WithOrganization(org string) *Registry {
+	copy := *r
+	copy.org = org
+	copy.urls = &URLs{baseURL: r.baseURL, org: org}
+	return &copy
+}
+
+// WithAPIKey returns a new Registry that sends a hex.pm API key, as mix
+// does, with every request to the registry and to repo.hex.pm, so
+// packages of the organizations the key has access to resolve. A key
+// generated for one organization with "mix hex.organization key" works
+// too. Requests to other hosts use the client's own AuthFunc.
+func (r *Registry) WithAPIKey(key string) *Registry {
+	client := r.client
+	if client == nil {
+		client = core.DefaultClient()
+	}
+	copy := *r
+	fallback := client.AuthFunc
+	copy.client = client.WithAuthFunc(func(url string) (string, string) {
+		for _, base := range []string{r.baseURL, repoURL} {
+			if url == base || strings.HasPrefix(url, base+"/") {
+				return "Authorization", key
+			}
+		}
+		if fallback != nil {
+			return fallback(url)
+		}
+		return "", ""
+	})
+	return &copy
+}
+
+// Organization returns the organization whose repository names without one
+// are looked up in, or "" for the public repository.
+func (r *Registry) Organization() string {
+	return r.org
+}
+
+// splitName returns the organization a name is looked up in and the
+// package's name within it. The organization is "" for public packages.
+func splitName(name, org string) (string, string) {
+	if repo, pkg, ok := strings.Cut(name, "/"); ok {
+		name, org = pkg, repo
+	}
+	if org == publicRepo {
+		org = ""
+	}
+	return org, name
+}
+
+// packageURL returns the API URL of a package, under /api/repos/<org> for
+// an organization's packages.
+func (r *Registry) packageURL(name string) string {
+	org, pkg := splitName(name, r.org)
+	if org == "" {
+		return fmt.Sprintf("%s/api/packages/%s", r.baseURL, pkg)
+	}
+	return fmt.Sprintf("%s/api/repos/%s/packages/%s", r.baseURL, org, pkg)
+}
+
+// releaseURL returns the API URL of one release of a package.
+func (r *Registry) releaseURL(name, version string) string {
+	return fmt.Sprintf("%s/releases/%s", r.packageURL(name), version)
+}
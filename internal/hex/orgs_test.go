@@ -0,0 +1,83 @@
+package hex
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+func TestOrganizationPackages(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/repos/acme/packages/billing", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"name": "billing", "repository": "acme", "meta": {"description": "Invoices"},
+			"releases": [{"version": "0.2.0", "inserted_at": "2024-03-01T10:00:00Z"}],
+			"owners": [{"username": "ops"}]}`))
+	})
+	mux.HandleFunc("/api/repos/acme/packages/billing/releases/0.2.0", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"version": "0.2.0", "requirements": {"decimal": {"requirement": "~> 2.0", "optional": false, "app": "decimal"}}}`))
+	})
+	mux.HandleFunc("/api/packages/decimal", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"name": "decimal", "releases": []}`))
+	})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "k3y" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+	ctx := context.Background()
+
+	unauthenticated := New(server.URL, core.DefaultClient())
+	var httpErr *core.HTTPError
+	if _, err := unauthenticated.FetchPackage(ctx, "acme/billing"); !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected 401 without an API key, got %v", err)
+	}
+
+	reg := New(server.URL, core.DefaultClient()).WithAPIKey("k3y")
+	pkg, err := reg.FetchPackage(ctx, "acme/billing")
+	if err != nil {
+		t.Fatalf("FetchPackage failed: %v", err)
+	}
+	if pkg.Name != "acme/billing" || pkg.Namespace != "acme" || pkg.Description != "Invoices" {
+		t.Errorf("unexpected package %+v", pkg)
+	}
+
+	// With a default organization, bare names are the organization's and
+	// "hexpm/" reaches public packages.
+	orgReg := reg.WithOrganization("acme")
+	versions, err := orgReg.FetchVersions(ctx, "billing")
+	if err != nil || len(versions) != 1 || versions[0].Number != "0.2.0" {
+		t.Errorf("FetchVersions = %+v, %v", versions, err)
+	}
+	deps, err := orgReg.FetchDependencies(ctx, "billing", "0.2.0")
+	if err != nil || len(deps) != 1 || deps[0].Name != "decimal" {
+		t.Errorf("FetchDependencies = %+v, %v", deps, err)
+	}
+	if _, err := orgReg.FetchPackage(ctx, "hexpm/decimal"); err != nil {
+		t.Errorf("FetchPackage(hexpm/decimal) failed: %v", err)
+	}
+	if orgReg.Organization() != "acme" || reg.Organization() != "" {
+		t.Error("WithOrganization changed the original registry")
+	}
+
+	urls := orgReg.URLs()
+	tests := []struct{ got, want string }{
+		{urls.Registry("billing", ""), server.URL + "/packages/acme/billing"},
+		{urls.Download("billing", "0.2.0"), "https://repo.hex.pm/repos/acme/tarballs/billing-0.2.0.tar"},
+		{urls.Documentation("billing", "0.2.0"), "https://acme.hexdocs.pm/billing/0.2.0"},
+		{urls.PURL("billing", "0.2.0"), "pkg:hex/acme/billing@0.2.0"},
+		{urls.PURL("hexpm/decimal", ""), "pkg:hex/decimal"},
+		{reg.URLs().Download("acme/billing", "0.2.0"), "https://repo.hex.pm/repos/acme/tarballs/billing-0.2.0.tar"},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("got %q, want %q", tt.got, tt.want)
+		}
+	}
+}
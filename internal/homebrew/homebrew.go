@@ -126,6 +126,8 @@ func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package
 			"status":            status,
 			"deprecation_reason": resp.DeprecationReason,
 		},
+		LatestVersion:       resp.Versions.Stable,
+		LatestStableVersion: resp.Versions.Stable,
 	}, nil
 }
 
@@ -178,11 +180,8 @@ func (r *Registry) FetchVersions(ctx context.Context, name string) ([]core.Versi
 }
 
 func formatIntegrity(checksum string) string {
-	if checksum == "" {
-		return ""
-	}
 	// Homebrew uses SHA256
-	return "sha256-" + checksum
+	return core.HexIntegrity("sha256", checksum)
 }
 
 func (r *Registry) FetchDependencies(ctx context.Context, name, version string) ([]core.Dependency, error) {
@@ -234,12 +233,6 @@ func (r *Registry) FetchDependencies(ctx context.Context, name, version string)
 	return deps, nil
 }
 
-func (r *Registry) FetchMaintainers(ctx context.Context, name string) ([]core.Maintainer, error) {
-	// Homebrew formulae don't expose maintainer info via API
-	// Maintainers are tracked in the tap repository
-	return nil, nil
-}
-
 type URLs struct {
 	baseURL string
 }
@@ -3,6 +3,7 @@ package homebrew
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -82,7 +83,7 @@ func TestFetchVersions(t *testing.T) {
 			},
 			URLs: urlsInfo{
 				Stable: urlInfo{
-					Checksum: "abc123def456",
+					Checksum: "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad",
 				},
 			},
 			VersionedFormulae: []string{"python@3.11", "python@3.10", "python@3.9"},
@@ -104,7 +105,7 @@ func TestFetchVersions(t *testing.T) {
 	if versions[0].Number != "3.12.1" {
 		t.Errorf("expected first version '3.12.1', got %q", versions[0].Number)
 	}
-	if versions[0].Integrity != "sha256-abc123def456" {
+	if versions[0].Integrity != "sha256-ungWv48Bz+pBQUDeXa4iI7ADYaOWF3qctBD/YfIAFa0=" {
 		t.Errorf("unexpected integrity: %q", versions[0].Integrity)
 	}
 }
@@ -152,14 +153,9 @@ func TestFetchDependencies(t *testing.T) {
 }
 
 func TestFetchMaintainers(t *testing.T) {
-	reg := New("", nil)
-	maintainers, err := reg.FetchMaintainers(context.Background(), "wget")
-	if err != nil {
-		t.Fatalf("FetchMaintainers failed: %v", err)
-	}
-
-	if len(maintainers) != 0 {
-		t.Errorf("expected 0 maintainers, got %d", len(maintainers))
+	_, err := core.FetchMaintainers(context.Background(), New("", nil), "wget")
+	if !errors.Is(err, core.ErrNotSupported) {
+		t.Errorf("expected ErrNotSupported, got %v", err)
 	}
 }
 
@@ -0,0 +1,301 @@
+// Package jsr provides a registry client for jsr.io, the JavaScript Registry.
+package jsr
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/git-pkgs/purl"
+	"github.com/git-pkgs/registries/internal/core"
+	"github.com/git-pkgs/registries/internal/urlparser"
+)
+
+const (
+	DefaultURL = "https://api.jsr.io"
+	webURL     = "https://jsr.io"
+	ecosystem  = "jsr"
+)
+
+func init() {
+	core.Register(ecosystem, DefaultURL, func(baseURL string, client *core.Client) core.Registry {
+		return New(baseURL, client)
+	})
+}
+
+type Registry struct {
+	baseURL string
+	client  *core.Client
+	urls    *URLs
+}
+
+func New(baseURL string, client *core.Client) *Registry {
+	if baseURL == "" {
+		baseURL = DefaultURL
+	}
+	r := &Registry{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client:  client,
+	}
+	r.urls = &URLs{baseURL: r.baseURL}
+	return r
+}
+
+func (r *Registry) Ecosystem() string {
+	return ecosystem
+}
+
+func (r *Registry) URLs() core.URLBuilder {
+	return r.urls
+}
+
+// splitName splits "@std/path" into its scope and package name. The
+// leading "@" is optional.
+func splitName(name string) (scope, pkg string, err error) {
+	parts := strings.SplitN(strings.TrimPrefix(name, "@"), "/", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("invalid JSR package name: %s (expected @scope/name)", name)
+	}
+	return parts[0], parts[1], nil
+}
+
+type packageResponse struct {
+	Scope            string            `json:"scope"`
+	Name             string            `json:"name"`
+	Description      string            `json:"description"`
+	GitHubRepository *githubRepository `json:"githubRepository"`
+	RuntimeCompat    map[string]bool   `json:"runtimeCompat"`
+	Score            *int              `json:"score"`
+	LatestVersion    string            `json:"latestVersion"`
+	IsArchived       bool              `json:"isArchived"`
+}
+
+type githubRepository struct {
+	Owner string `json:"owner"`
+	Name  string `json:"name"`
+}
+
+type versionResponse struct {
+	Version    string `json:"version"`
+	Yanked     bool   `json:"yanked"`
+	UsesNPM    bool   `json:"usesNpm"`
+	RekorLogID string `json:"rekorLogId"`
+	CreatedAt  string `json:"createdAt"`
+}
+
+type dependencyResponse struct {
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+	Constraint string `json:"constraint"`
+	Path       string `json:"path"`
+}
+
+type memberResponse struct {
+	User    userResponse `json:"user"`
+	IsAdmin bool         `json:"isAdmin"`
+}
+
+type userResponse struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	GitHubID int64  `json:"githubId"`
+}
+
+func (r *Registry) packageURL(name string) (string, error) {
+	scope, pkg, err := splitName(name)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s/scopes/%s/packages/%s", r.baseURL, scope, pkg), nil
+}
+
+func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package, error) {
+	url, err := r.packageURL(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp packageResponse
+	if err := r.client.GetJSON(ctx, url, &resp); err != nil {
+		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
+			return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name}
+		}
+		return nil, err
+	}
+
+	var repository string
+	if gh := resp.GitHubRepository; gh != nil && gh.Owner != "" && gh.Name != "" {
+		repository = urlparser.Parse(fmt.Sprintf("https://github.com/%s/%s", gh.Owner, gh.Name))
+	}
+
+	fullName := fmt.Sprintf("@%s/%s", resp.Scope, resp.Name)
+	metadata := map[string]any{
+		"runtime_compat": resp.RuntimeCompat,
+		"archived":       resp.IsArchived,
+	}
+	if resp.Score != nil {
+		metadata["score"] = *resp.Score
+	}
+
+	// JSR only points latestVersion at a pre-release when there is
+	// nothing else
+	var stable string
+	if !core.IsPrerelease(ecosystem, resp.LatestVersion) {
+		stable = resp.LatestVersion
+	}
+
+	return &core.Package{
+		Name:                fullName,
+		Description:         resp.Description,
+		Homepage:            fmt.Sprintf("%s/%s", webURL, fullName),
+		Repository:          repository,
+		Namespace:           resp.Scope,
+		LatestVersion:       resp.LatestVersion,
+		LatestStableVersion: stable,
+		Metadata:            metadata,
+	}, nil
+}
+
+func (r *Registry) FetchVersions(ctx context.Context, name string) ([]core.Version, error) {
+	url, err := r.packageURL(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp []versionResponse
+	if err := r.client.GetJSON(ctx, url+"/versions", &resp); err != nil {
+		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
+			return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name}
+		}
+		return nil, err
+	}
+
+	versions := make([]core.Version, len(resp))
+	for i, v := range resp {
+		var publishedAt time.Time
+		if v.CreatedAt != "" {
+			publishedAt, _ = time.Parse(time.RFC3339, v.CreatedAt)
+		}
+
+		var status core.VersionStatus
+		if v.Yanked {
+			status = core.StatusYanked
+		}
+
+		versions[i] = core.Version{
+			Number:      v.Version,
+			PublishedAt: publishedAt,
+			Status:      status,
+			Metadata: map[string]any{
+				"uses_npm":     v.UsesNPM,
+				"rekor_log_id": v.RekorLogID,
+			},
+		}
+	}
+
+	return versions, nil
+}
+
+// FetchDependencies returns the packages a version imports. JSR derives the
+// list from the version's module graph, reporting each import separately,
+// so imports of several paths from one package are merged. Dependencies on
+// npm packages have Metadata["kind"] set to "npm".
+func (r *Registry) FetchDependencies(ctx context.Context, name, version string) ([]core.Dependency, error) {
+	url, err := r.packageURL(name)
+	if err != nil {
+		return nil, err
+	}
+	url = fmt.Sprintf("%s/versions/%s/dependencies", url, version)
+
+	var resp []dependencyResponse
+	if err := r.client.GetJSON(ctx, url, &resp); err != nil {
+		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
+			return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name, Version: version}
+		}
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var deps []core.Dependency
+	for _, d := range resp {
+		key := d.Kind + ":" + d.Name + "@" + d.Constraint
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		deps = append(deps, core.Dependency{
+			Name:         d.Name,
+			Requirements: d.Constraint,
+			Scope:        core.Runtime,
+			Metadata: map[string]any{
+				"kind": d.Kind,
+			},
+		})
+	}
+
+	return deps, nil
+}
+
+// FetchMaintainers returns the members of the package's scope, who can all
+// publish to it.
+func (r *Registry) FetchMaintainers(ctx context.Context, name string) ([]core.Maintainer, error) {
+	scope, _, err := splitName(name)
+	if err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("%s/scopes/%s/members", r.baseURL, scope)
+
+	var resp []memberResponse
+	if err := r.client.GetJSON(ctx, url, &resp); err != nil {
+		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
+			return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name}
+		}
+		return nil, err
+	}
+
+	maintainers := make([]core.Maintainer, len(resp))
+	for i, m := range resp {
+		role := "member"
+		if m.IsAdmin {
+			role = "admin"
+		}
+		maintainers[i] = core.Maintainer{
+			UUID: m.User.ID,
+			Name: m.User.Name,
+			Role: role,
+		}
+	}
+
+	return maintainers, nil
+}
+
+type URLs struct {
+	baseURL string
+}
+
+func (u *URLs) Registry(name, version string) string {
+	name = "@" + strings.TrimPrefix(name, "@")
+	if version != "" {
+		return fmt.Sprintf("%s/%s@%s", webURL, name, version)
+	}
+	return fmt.Sprintf("%s/%s", webURL, name)
+}
+
+func (u *URLs) Download(name, version string) string {
+	// JSR serves a version's files individually; there is no archive
+	return ""
+}
+
+func (u *URLs) Documentation(name, version string) string {
+	return u.Registry(name, version) + "/doc"
+}
+
+func (u *URLs) PURL(name, version string) string {
+	scope, pkg, err := splitName(name)
+	if err != nil {
+		return purl.New(ecosystem, "", name, version, nil).String()
+	}
+	return purl.New(ecosystem, "@"+scope, pkg, version, nil).String()
+}
@@ -0,0 +1,209 @@
+package jsr
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+func TestFetchPackage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/scopes/std/packages/path" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+			w.WriteHeader(404)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{
+			"scope": "std",
+			"name": "path",
+			"description": "Utilities for working with file system paths",
+			"runtimeCompat": {"browser": true, "deno": true, "node": true},
+			"githubRepository": {"owner": "denoland", "name": "std"},
+			"score": 100,
+			"latestVersion": "1.0.8",
+			"isArchived": false
+		}`))
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	pkg, err := reg.FetchPackage(context.Background(), "@std/path")
+	if err != nil {
+		t.Fatalf("FetchPackage failed: %v", err)
+	}
+
+	if pkg.Name != "@std/path" {
+		t.Errorf("expected name '@std/path', got %q", pkg.Name)
+	}
+	if pkg.Namespace != "std" {
+		t.Errorf("expected namespace 'std', got %q", pkg.Namespace)
+	}
+	if pkg.Repository != "https://github.com/denoland/std" {
+		t.Errorf("unexpected repository: %q", pkg.Repository)
+	}
+	if pkg.Homepage != "https://jsr.io/@std/path" {
+		t.Errorf("unexpected homepage: %q", pkg.Homepage)
+	}
+	if pkg.LatestVersion != "1.0.8" {
+		t.Errorf("unexpected latest version: %q", pkg.LatestVersion)
+	}
+	if pkg.Metadata["score"] != 100 {
+		t.Errorf("unexpected score: %v", pkg.Metadata["score"])
+	}
+}
+
+func TestFetchPackageNotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(404)
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	if _, err := reg.FetchPackage(context.Background(), "@std/nonexistent"); !errors.Is(err, core.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	if _, err := reg.FetchPackage(context.Background(), "path"); err == nil {
+		t.Error("expected an error for a name without a scope")
+	}
+}
+
+func TestFetchVersions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/scopes/std/packages/path/versions" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+			w.WriteHeader(404)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[
+			{"scope": "std", "package": "path", "version": "1.0.8", "yanked": false, "usesNpm": false, "rekorLogId": "126346187", "createdAt": "2024-10-24T05:27:16.593283Z"},
+			{"scope": "std", "package": "path", "version": "0.225.0", "yanked": true, "createdAt": "2024-05-01T10:00:00Z"}
+		]`))
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	versions, err := reg.FetchVersions(context.Background(), "std/path")
+	if err != nil {
+		t.Fatalf("FetchVersions failed: %v", err)
+	}
+
+	if len(versions) != 2 {
+		t.Fatalf("expected 2 versions, got %d", len(versions))
+	}
+	if versions[0].Number != "1.0.8" || versions[0].Status != core.StatusNone {
+		t.Errorf("unexpected first version: %+v", versions[0])
+	}
+	expected, _ := time.Parse(time.RFC3339, "2024-10-24T05:27:16.593283Z")
+	if !versions[0].PublishedAt.Equal(expected) {
+		t.Errorf("unexpected published_at: %v", versions[0].PublishedAt)
+	}
+	if versions[1].Status != core.StatusYanked {
+		t.Errorf("expected second version to be yanked, got %q", versions[1].Status)
+	}
+}
+
+func TestFetchDependencies(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/scopes/oak/packages/oak/versions/17.1.3/dependencies" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+			w.WriteHeader(404)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[
+			{"kind": "jsr", "name": "@std/assert", "constraint": "^1.0", "path": ""},
+			{"kind": "jsr", "name": "@std/media-types", "constraint": "^1.0", "path": "/content_type"},
+			{"kind": "jsr", "name": "@std/media-types", "constraint": "^1.0", "path": "/extension"},
+			{"kind": "npm", "name": "path-to-regexp", "constraint": "^6.2.1", "path": ""}
+		]`))
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	deps, err := reg.FetchDependencies(context.Background(), "@oak/oak", "17.1.3")
+	if err != nil {
+		t.Fatalf("FetchDependencies failed: %v", err)
+	}
+
+	if len(deps) != 3 {
+		t.Fatalf("expected 3 dependencies, got %d: %+v", len(deps), deps)
+	}
+	if deps[0].Name != "@std/assert" || deps[0].Requirements != "^1.0" || deps[0].Scope != core.Runtime {
+		t.Errorf("unexpected first dependency: %+v", deps[0])
+	}
+	if deps[2].Name != "path-to-regexp" || deps[2].Metadata["kind"] != "npm" {
+		t.Errorf("unexpected npm dependency: %+v", deps[2])
+	}
+}
+
+func TestFetchMaintainers(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/scopes/luca/members" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+			w.WriteHeader(404)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[
+			{"scope": "luca", "user": {"id": "a1b2", "name": "Luca Casonato", "githubId": 7829205}, "isAdmin": true},
+			{"scope": "luca", "user": {"id": "c3d4", "name": "Someone Else"}, "isAdmin": false}
+		]`))
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	maintainers, err := reg.FetchMaintainers(context.Background(), "@luca/flag")
+	if err != nil {
+		t.Fatalf("FetchMaintainers failed: %v", err)
+	}
+
+	if len(maintainers) != 2 {
+		t.Fatalf("expected 2 maintainers, got %d", len(maintainers))
+	}
+	if maintainers[0].Name != "Luca Casonato" || maintainers[0].UUID != "a1b2" || maintainers[0].Role != "admin" {
+		t.Errorf("unexpected first maintainer: %+v", maintainers[0])
+	}
+	if maintainers[1].Role != "member" {
+		t.Errorf("unexpected role: %q", maintainers[1].Role)
+	}
+}
+
+func TestURLBuilder(t *testing.T) {
+	reg := New("", nil)
+	urls := reg.URLs()
+
+	tests := []struct {
+		name     string
+		fn       func() string
+		expected string
+	}{
+		{"registry", func() string { return urls.Registry("@std/path", "1.0.8") }, "https://jsr.io/@std/path@1.0.8"},
+		{"registry without version", func() string { return urls.Registry("std/path", "") }, "https://jsr.io/@std/path"},
+		{"download", func() string { return urls.Download("@std/path", "1.0.8") }, ""},
+		{"documentation", func() string { return urls.Documentation("@std/path", "") }, "https://jsr.io/@std/path/doc"},
+		{"purl", func() string { return urls.PURL("@std/path", "1.0.8") }, "pkg:jsr/%40std/path@1.0.8"},
+		{"purl without version", func() string { return urls.PURL("@std/path", "") }, "pkg:jsr/%40std/path"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.fn(); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestEcosystem(t *testing.T) {
+	reg := New("", nil)
+	if reg.Ecosystem() != "jsr" {
+		t.Errorf("expected ecosystem 'jsr', got %q", reg.Ecosystem())
+	}
+}
@@ -0,0 +1,143 @@
+package julia
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+// The General registry doesn't record artifacts: they are declared in an
+// Artifacts.toml (or JuliaArtifacts.toml) at the root of the package's own
+// source tree, mapping each artifact name to a git-tree-sha1 and, for
+// binaries, the tarballs it can be downloaded from. Platform-specific
+// artifacts, such as JLL binaries, have one entry per platform.
+// https://pkgdocs.julialang.org/v1/artifacts/
+
+// githubRawURL serves files from GitHub repositories at a tag.
+const githubRawURL = "https://raw.githubusercontent.com"
+
+var artifactsFiles = []string{"Artifacts.toml", "JuliaArtifacts.toml"}
+
+// Artifact is one entry of a package version's Artifacts.toml.
+type Artifact struct {
+	Name        string `json:"name"`
+	GitTreeSHA1 string `json:"git_tree_sha1"`
+	// Lazy artifacts are only downloaded when first used.
+	Lazy bool `json:"lazy,omitempty"`
+	// Platform holds the keys selecting the platform an artifact is for,
+	// such as "os", "arch" and "libc". It is empty for artifacts that apply
+	// everywhere.
+	Platform  map[string]string  `json:"platform,omitempty"`
+	Downloads []ArtifactDownload `json:"downloads,omitempty"`
+}
+
+// ArtifactDownload is a tarball an artifact can be installed from.
+type ArtifactDownload struct {
+	URL    string `json:"url"`
+	SHA256 string `json:"sha256"`
+}
+
+// FetchArtifacts returns the artifacts a version of a package declares,
+// read from the Artifacts.toml in its repository at the version's tag.
+// Only packages hosted on GitHub can be read; for others, and for versions
+// without an Artifacts.toml, it returns none and no error.
+func (r *Registry) FetchArtifacts(ctx context.Context, name, version string) ([]Artifact, error) {
+	pkg, err := r.FetchPackage(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	repo := pkg.Repository
+	if !strings.HasPrefix(repo, "https://github.com/") {
+		return nil, nil
+	}
+	repo = strings.TrimPrefix(repo, "https://github.com/")
+	subdir, _ := pkg.Metadata["subdir"].(string)
+
+	for _, file := range artifactsFiles {
+		body, err := r.client.GetBody(ctx, artifactsURL(r.rawURL, repo, subdir, pkg.Name, version, file))
+		if err != nil {
+			if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
+				continue
+			}
+			return nil, err
+		}
+		artifacts, err := parseArtifactsToml(body)
+		if err != nil {
+			return nil, fmt.Errorf("%s: parsing %s of %s %s: %w", ecosystem, file, name, version, err)
+		}
+		return artifacts, nil
+	}
+	return nil, nil
+}
+
+// artifactsURL returns the raw URL of an artifacts file at the tag
+// TagBot gives a version: v1.2.3, or Name-v1.2.3 for a package in a
+// subdirectory of its repository.
+func artifactsURL(rawURL, repo, subdir, name, version, file string) string {
+	tag := "v" + version
+	if subdir != "" {
+		tag = name + "-" + tag
+		file = strings.Trim(subdir, "/") + "/" + file
+	}
+	return fmt.Sprintf("%s/%s/%s/%s", rawURL, repo, tag, file)
+}
+
+// parseArtifactsToml reads an Artifacts.toml, in which each name maps to
+// a table, or to an array of tables for platform-specific artifacts.
+// Artifacts are returned sorted by name, in file order within a name.
+func parseArtifactsToml(content []byte) ([]Artifact, error) {
+	var doc map[string]any
+	if err := toml.Unmarshal(content, &doc); err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(doc))
+	for name := range doc {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var artifacts []Artifact
+	for _, name := range names {
+		switch entry := doc[name].(type) {
+		case map[string]any:
+			artifacts = append(artifacts, parseArtifact(name, entry))
+		case []map[string]any:
+			for _, e := range entry {
+				artifacts = append(artifacts, parseArtifact(name, e))
+			}
+		}
+	}
+	return artifacts, nil
+}
+
+func parseArtifact(name string, entry map[string]any) Artifact {
+	a := Artifact{Name: name}
+	for key, value := range entry {
+		switch key {
+		case "git-tree-sha1":
+			a.GitTreeSHA1, _ = value.(string)
+		case "lazy":
+			a.Lazy, _ = value.(bool)
+		case "download":
+			downloads, _ := value.([]map[string]any)
+			for _, d := range downloads {
+				url, _ := d["url"].(string)
+				sha256, _ := d["sha256"].(string)
+				a.Downloads = append(a.Downloads, ArtifactDownload{URL: url, SHA256: sha256})
+			}
+		default:
+			if s, ok := value.(string); ok {
+				if a.Platform == nil {
+					a.Platform = make(map[string]string)
+				}
+				a.Platform[key] = s
+			}
+		}
+	}
+	return a
+}
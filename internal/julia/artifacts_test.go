@@ -0,0 +1,87 @@
+package julia
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+const sampleArtifactsToml = `[socrates]
+git-tree-sha1 = "43563e7631a7eafae1f9f8d9d332e3de44ad7239"
+lazy = true
+
+    [[socrates.download]]
+    url = "https://github.com/staticfloat/small_bin/raw/master/socrates.tar.gz"
+    sha256 = "e65d2f13f2085f2c279830e863292312a72930fee5ba3c792b14c33ce5c5cc58"
+
+[[c_simple]]
+arch = "x86_64"
+git-tree-sha1 = "4bdf4556050cb55b67b211d4e78009aaec378cbc"
+libc = "musl"
+os = "linux"
+
+    [[c_simple.download]]
+    sha256 = "411d6befd49942826ea1e59041bddf7dbb72fb871bb03165bf4e164b13ab5130"
+    url = "https://github.com/JuliaBinaryWrappers/c_simple_jll.jl/releases/download/c_simple+v1.2.3+0/c_simple.v1.2.3.x86_64-linux-musl.tar.gz"
+
+[[c_simple]]
+arch = "aarch64"
+git-tree-sha1 = "c5a8cd5f9b0e9f3e9a4d8e1f6a2b3c4d5e6f7a8b"
+os = "macos"
+`
+
+func TestFetchArtifacts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/C/CSimple/Package.toml":
+			_, _ = w.Write([]byte("name = \"CSimple\"\nrepo = \"https://github.com/JuliaBinaryWrappers/CSimple.jl.git\"\n"))
+		case "/JuliaBinaryWrappers/CSimple.jl/v1.2.3/Artifacts.toml":
+			_, _ = w.Write([]byte(sampleArtifactsToml))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	reg.rawURL = server.URL
+	artifacts, err := reg.FetchArtifacts(context.Background(), "CSimple", "1.2.3")
+	if err != nil {
+		t.Fatalf("FetchArtifacts failed: %v", err)
+	}
+	if len(artifacts) != 3 {
+		t.Fatalf("expected 3 artifacts, got %+v", artifacts)
+	}
+
+	linux := artifacts[0]
+	if linux.Name != "c_simple" || linux.Platform["os"] != "linux" || linux.Platform["libc"] != "musl" {
+		t.Errorf("unexpected first artifact %+v", linux)
+	}
+	if len(linux.Downloads) != 1 || linux.Downloads[0].SHA256 != "411d6befd49942826ea1e59041bddf7dbb72fb871bb03165bf4e164b13ab5130" {
+		t.Errorf("unexpected downloads %+v", linux.Downloads)
+	}
+	if artifacts[1].Platform["os"] != "macos" || len(artifacts[1].Downloads) != 0 {
+		t.Errorf("unexpected second artifact %+v", artifacts[1])
+	}
+	socrates := artifacts[2]
+	if socrates.Name != "socrates" || !socrates.Lazy || socrates.Platform != nil || socrates.GitTreeSHA1 != "43563e7631a7eafae1f9f8d9d332e3de44ad7239" {
+		t.Errorf("unexpected platform-independent artifact %+v", socrates)
+	}
+
+	none, err := reg.FetchArtifacts(context.Background(), "CSimple", "1.0.0")
+	if err != nil || len(none) != 0 {
+		t.Errorf("expected no artifacts without an Artifacts.toml, got %+v, %v", none, err)
+	}
+}
+
+func TestArtifactsURL(t *testing.T) {
+	if got := artifactsURL(githubRawURL, "JuliaIO/JSON.jl", "", "JSON", "0.21.4", "Artifacts.toml"); got != "https://raw.githubusercontent.com/JuliaIO/JSON.jl/v0.21.4/Artifacts.toml" {
+		t.Errorf("unexpected URL %q", got)
+	}
+	if got := artifactsURL(githubRawURL, "SciML/SciMLBase.jl", "lib/SciMLBaseMLStyleExt/", "SciMLBaseMLStyleExt", "1.0.0", "Artifacts.toml"); got != "https://raw.githubusercontent.com/SciML/SciMLBase.jl/SciMLBaseMLStyleExt-v1.0.0/lib/SciMLBaseMLStyleExt/Artifacts.toml" {
+		t.Errorf("unexpected subdirectory URL %q", got)
+	}
+}
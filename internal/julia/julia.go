@@ -3,12 +3,15 @@ package julia
 
 import (
 	"bufio"
+	"cmp"
 	"context"
 	"fmt"
 	"sort"
+	"strconv"
 	"strings"
 	"unicode"
 
+	"github.com/git-pkgs/purl"
 	"github.com/git-pkgs/registries/internal/core"
 	"github.com/git-pkgs/registries/internal/urlparser"
 )
@@ -26,6 +29,7 @@ func init() {
 
 type Registry struct {
 	baseURL string
+	rawURL  string // where FetchArtifacts reads package repositories
 	client  *core.Client
 	urls    *URLs
 }
@@ -36,12 +40,26 @@ func New(baseURL string, client *core.Client) *Registry {
 	}
 	r := &Registry{
 		baseURL: strings.TrimSuffix(baseURL, "/"),
+		rawURL:  githubRawURL,
 		client:  client,
 	}
 	r.urls = &URLs{baseURL: r.baseURL}
 	return r
 }
 
+// WithQualifiers applies the uuid qualifier of a PURL. Julia package names
+// are not unique, so the PURL spec requires the UUID; it is included in the
+// PURLs this registry generates, as found in Package.Metadata["uuid"].
+func (r *Registry) WithQualifiers(qualifiers map[string]string) core.Registry {
+	copy := *r
+	urls := *r.urls
+	if uuid := qualifiers["uuid"]; uuid != "" {
+		urls.uuid = uuid
+	}
+	copy.urls = &urls
+	return &copy
+}
+
 func (r *Registry) Ecosystem() string {
 	return ecosystem
 }
@@ -211,28 +229,34 @@ func parseVersionsToml(content string) map[string]versionInfo {
 
 func (r *Registry) FetchDependencies(ctx context.Context, name, version string) ([]core.Dependency, error) {
 	path := getPackagePath(name)
-	depsURL := fmt.Sprintf("%s/%s/Deps.toml", r.baseURL, path)
 
-	body, err := r.client.GetBody(ctx, depsURL)
+	// No Deps.toml means no dependencies
+	runtime, err := r.fetchDeps(ctx, path, "Deps.toml", version)
+	if err != nil {
+		return nil, err
+	}
+	// Weak dependencies, which load a package extension when the project
+	// also has them, are listed apart in WeakDeps.toml (Julia 1.9+)
+	weak, err := r.fetchDeps(ctx, path, "WeakDeps.toml", version)
 	if err != nil {
-		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
-			// No Deps.toml means no dependencies
-			return nil, nil
-		}
 		return nil, err
 	}
 
-	depsByVersion := parseDepsToml(string(body))
-
-	// Get dependencies for the specific version
 	var deps []core.Dependency
-	if verDeps, ok := depsByVersion[version]; ok {
-		for depName := range verDeps {
-			deps = append(deps, core.Dependency{
-				Name:  depName,
-				Scope: core.Runtime,
-			})
-		}
+	for depName, uuid := range runtime {
+		deps = append(deps, core.Dependency{
+			Name:     depName,
+			Scope:    core.Runtime,
+			Metadata: map[string]any{"uuid": uuid},
+		})
+	}
+	for depName, uuid := range weak {
+		deps = append(deps, core.Dependency{
+			Name:     depName,
+			Scope:    core.Optional,
+			Optional: true,
+			Metadata: map[string]any{"uuid": uuid, "weak": true},
+		})
 	}
 
 	// Sort dependencies by name for consistent output
@@ -243,7 +267,35 @@ func (r *Registry) FetchDependencies(ctx context.Context, name, version string)
 	return deps, nil
 }
 
-// parseDepsToml parses Julia's Deps.toml format
+// fetchDeps reads a Deps.toml-style file of a package and returns the
+// entries whose version ranges include version, or none if the package
+// has no such file.
+func (r *Registry) fetchDeps(ctx context.Context, path, file, version string) (map[string]string, error) {
+	depsURL := fmt.Sprintf("%s/%s/%s", r.baseURL, path, file)
+
+	body, err := r.client.GetBody(ctx, depsURL)
+	if err != nil {
+		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	deps := make(map[string]string)
+	for versionRange, sectionDeps := range parseDepsToml(string(body)) {
+		if !rangeContains(versionRange, version) {
+			continue
+		}
+		for depName, uuid := range sectionDeps {
+			deps[depName] = uuid
+		}
+	}
+	return deps, nil
+}
+
+// parseDepsToml parses Julia's Deps.toml format, returning the
+// dependencies of each version range section. WeakDeps.toml uses the same
+// format.
 // Format:
 // ["1.0"]
 // PackageA = "uuid-a"
@@ -254,7 +306,7 @@ func parseDepsToml(content string) map[string]map[string]string {
 	deps := make(map[string]map[string]string)
 	scanner := bufio.NewScanner(strings.NewReader(content))
 
-	var currentVersions []string
+	var currentRange string
 
 	for scanner.Scan() {
 		line := strings.TrimSpace(scanner.Text())
@@ -264,56 +316,95 @@ func parseDepsToml(content string) map[string]map[string]string {
 
 		// Check for version section header: ["1.0"] or ["1.0-2.0"]
 		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
-			versionRange := strings.Trim(line, "[]\"")
-			currentVersions = expandVersionRange(versionRange)
-			// Initialize maps for all versions in range
-			for _, v := range currentVersions {
-				if deps[v] == nil {
-					deps[v] = make(map[string]string)
-				}
+			currentRange = strings.Trim(line, "[]\"")
+			if deps[currentRange] == nil {
+				deps[currentRange] = make(map[string]string)
 			}
 			continue
 		}
 
 		// Parse dependency: PackageName = "uuid"
 		parts := strings.SplitN(line, "=", 2)
-		if len(parts) != 2 {
+		if len(parts) != 2 || currentRange == "" {
 			continue
 		}
 
 		depName := strings.TrimSpace(parts[0])
 		uuid := strings.Trim(strings.TrimSpace(parts[1]), "\"")
-
-		// Add dependency to all current versions
-		for _, v := range currentVersions {
-			if deps[v] != nil {
-				deps[v][depName] = uuid
-			}
-		}
+		deps[currentRange][depName] = uuid
 	}
 
 	return deps
 }
 
-// expandVersionRange expands a version range like "1.0-2.0" or just "1.0"
-// For simplicity, we return it as-is since Julia uses semver ranges in section headers
-func expandVersionRange(versionRange string) []string {
-	// Handle ranges like "1.0-2.0" - we'll store under both endpoints
-	parts := strings.Split(versionRange, "-")
-	if len(parts) == 2 {
-		return parts
+// rangeContains reports whether version falls in a registry version range
+// such as "1", "0.5-0.7" or "1.2-*". As in Pkg, each bound matches every
+// version it is a prefix of: "0.5-0.7" covers 0.5.0 up to any 0.7.x, and
+// "1" covers all of 1.x.
+func rangeContains(versionRange, version string) bool {
+	lower, upper, ok := strings.Cut(versionRange, "-")
+	if !ok {
+		upper = lower
 	}
-	return []string{versionRange}
+	// Build metadata and prereleases are ordered with their release
+	version, _, _ = strings.Cut(version, "+")
+	version, _, _ = strings.Cut(version, "-")
+	v := versionParts(version)
+	if v == nil {
+		return false
+	}
+
+	if lower != "*" {
+		low := versionParts(lower)
+		if low == nil || compareParts(v, low) < 0 {
+			return false
+		}
+	}
+	if upper != "*" {
+		high := versionParts(upper)
+		if high == nil || compareParts(v[:min(len(v), len(high))], high) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// versionParts splits a version such as "1.2.3" into its numbers, or
+// returns nil if it isn't one.
+func versionParts(version string) []int {
+	fields := strings.Split(strings.TrimPrefix(strings.TrimSpace(version), "v"), ".")
+	parts := make([]int, 0, len(fields))
+	for _, f := range fields {
+		n, err := strconv.Atoi(f)
+		if err != nil || n < 0 {
+			return nil
+		}
+		parts = append(parts, n)
+	}
+	return parts
 }
 
-func (r *Registry) FetchMaintainers(ctx context.Context, name string) ([]core.Maintainer, error) {
-	// Julia's General registry doesn't store maintainer info
-	// That information is in the package's GitHub repo
-	return nil, nil
+// compareParts compares two versions, treating missing trailing numbers as
+// zero.
+func compareParts(a, b []int) int {
+	for i := 0; i < max(len(a), len(b)); i++ {
+		var x, y int
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		if x != y {
+			return cmp.Compare(x, y)
+		}
+	}
+	return 0
 }
 
 type URLs struct {
 	baseURL string
+	uuid    string
 }
 
 func (u *URLs) Registry(name, version string) string {
@@ -343,8 +434,9 @@ func (u *URLs) PURL(name, version string) string {
 		return -1
 	}, name)
 
-	if version != "" {
-		return fmt.Sprintf("pkg:julia/%s@%s", cleanName, version)
+	var qualifiers map[string]string
+	if u.uuid != "" {
+		qualifiers = map[string]string{"uuid": u.uuid}
 	}
-	return fmt.Sprintf("pkg:julia/%s", cleanName)
+	return purl.New(ecosystem, "", cleanName, version, qualifiers).String()
 }
@@ -2,6 +2,7 @@ package julia
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -126,38 +127,92 @@ func TestFetchDependencies(t *testing.T) {
 	}
 }
 
-func TestFetchDependenciesNoDeps(t *testing.T) {
+const sampleWeakDepsToml = `["0.21.4-0"]
+Arrow = "69666777-d1a9-59fb-9406-91d4454c9d45"
+`
+
+func TestFetchWeakDependencies(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(404)
+		switch r.URL.Path {
+		case "/J/JSON/Deps.toml":
+			_, _ = w.Write([]byte(sampleDepsToml))
+		case "/J/JSON/WeakDeps.toml":
+			_, _ = w.Write([]byte(sampleWeakDepsToml))
+		default:
+			w.WriteHeader(404)
+		}
 	}))
 	defer server.Close()
 
 	reg := New(server.URL, core.DefaultClient())
-	deps, err := reg.FetchDependencies(context.Background(), "NoDeps", "1.0.0")
+	deps, err := reg.FetchDependencies(context.Background(), "JSON", "0.21.4")
 	if err != nil {
 		t.Fatalf("FetchDependencies failed: %v", err)
 	}
+	if len(deps) != 4 {
+		t.Fatalf("expected 4 dependencies for 0.21.4, got %+v", deps)
+	}
+	arrow := deps[0]
+	if arrow.Name != "Arrow" || arrow.Scope != core.Optional || !arrow.Optional || arrow.Metadata["weak"] != true {
+		t.Errorf("expected Arrow as a weak dependency, got %+v", arrow)
+	}
+	for _, d := range deps[1:] {
+		if d.Scope != core.Runtime || d.Optional {
+			t.Errorf("expected %s as a runtime dependency, got %+v", d.Name, d)
+		}
+	}
 
-	if len(deps) != 0 {
-		t.Errorf("expected 0 dependencies, got %d", len(deps))
+	deps, err = reg.FetchDependencies(context.Background(), "JSON", "0.21.3")
+	if err != nil || len(deps) != 3 {
+		t.Errorf("expected no weak dependencies before 0.21.4, got %+v, %v", deps, err)
 	}
 }
 
-func TestFetchMaintainers(t *testing.T) {
+func TestRangeContains(t *testing.T) {
+	tests := []struct {
+		versionRange, version string
+		want                  bool
+	}{
+		{"1", "1.8.0", true},
+		{"1", "2.0.0", false},
+		{"0.5-0.7", "0.5.0", true},
+		{"0.5-0.7", "0.7.12", true},
+		{"0.5-0.7", "0.8.0", false},
+		{"0.5-0.7", "0.4.9", false},
+		{"0.21.4-0", "0.21.3", false},
+		{"0.21.4-0", "0.25.1", true},
+		{"1.2-*", "9.0.0", true},
+		{"1.2.3", "1.2.3+0", true},
+		{"1.2.3", "1.2.4", false},
+	}
+	for _, tt := range tests {
+		if got := rangeContains(tt.versionRange, tt.version); got != tt.want {
+			t.Errorf("rangeContains(%q, %q) = %v, want %v", tt.versionRange, tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestFetchDependenciesNoDeps(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		_, _ = w.Write([]byte(samplePackageToml))
+		w.WriteHeader(404)
 	}))
 	defer server.Close()
 
 	reg := New(server.URL, core.DefaultClient())
-	maintainers, err := reg.FetchMaintainers(context.Background(), "JSON")
+	deps, err := reg.FetchDependencies(context.Background(), "NoDeps", "1.0.0")
 	if err != nil {
-		t.Fatalf("FetchMaintainers failed: %v", err)
+		t.Fatalf("FetchDependencies failed: %v", err)
 	}
 
-	// Julia registry doesn't store maintainer info
-	if len(maintainers) != 0 {
-		t.Errorf("expected 0 maintainers, got %d", len(maintainers))
+	if len(deps) != 0 {
+		t.Errorf("expected 0 dependencies, got %d", len(deps))
+	}
+}
+
+func TestFetchMaintainers(t *testing.T) {
+	_, err := core.FetchMaintainers(context.Background(), New("", nil), "JSON")
+	if !errors.Is(err, core.ErrNotSupported) {
+		t.Errorf("expected ErrNotSupported, got %v", err)
 	}
 }
 
@@ -231,6 +286,13 @@ func TestURLBuilder(t *testing.T) {
 	}
 }
 
+func TestWithQualifiers(t *testing.T) {
+	urls := New("", nil).WithQualifiers(map[string]string{"uuid": "682c06a0-de6a-54ab-a142-c8b1cf79cde6"}).URLs()
+	if got := urls.PURL("JSON", "0.21.4"); got != "pkg:julia/JSON@0.21.4?uuid=682c06a0-de6a-54ab-a142-c8b1cf79cde6" {
+		t.Errorf("unexpected PURL: %q", got)
+	}
+}
+
 func TestEcosystem(t *testing.T) {
 	reg := New("", nil)
 	if reg.Ecosystem() != "julia" {
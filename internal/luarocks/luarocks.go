@@ -97,12 +97,22 @@ func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package
 		return nil, err
 	}
 
+	// Versions carry a rockspec revision ("1.2.0-1"), so pre-releases
+	// can't be told apart; "scm" and "dev" rocks track a branch.
+	var releases []string
+	for v := range resp.Versions {
+		if !strings.HasPrefix(v, "scm-") && !strings.HasPrefix(v, "dev-") {
+			releases = append(releases, v)
+		}
+	}
+
 	return &core.Package{
-		Name:        resp.Name,
-		Description: resp.Description,
-		Homepage:    resp.Homepage,
-		Licenses:    resp.License,
-		Keywords:    resp.Labels,
+		Name:          resp.Name,
+		Description:   resp.Description,
+		Homepage:      resp.Homepage,
+		Licenses:      resp.License,
+		Keywords:      resp.Labels,
+		LatestVersion: core.LatestOf(ecosystem, releases),
 	}, nil
 }
 
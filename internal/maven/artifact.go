@@ -0,0 +1,145 @@
+package maven
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+// checksumConcurrency bounds the checksum requests made by FetchVersions
+// when checksums are enabled.
+const checksumConcurrency = 8
+
+// checksumAlgorithms are the checksum files tried for an artifact, in order.
+// Maven Central has .sha1 and .md5 files for everything and .sha256 for
+// artifacts deployed by newer tooling.
+var checksumAlgorithms = []struct {
+	ext  string
+	size int
+}{
+	{"sha256", 64},
+	{"sha1", 40},
+	{"md5", 32},
+}
+
+// WithChecksums returns a new Registry whose FetchVersions sets Integrity
+// from the checksum file of each version's main artifact. This is one
+// request per version, or up to three where the repository lacks .sha256
+// files.
+func (r *Registry) WithChecksums() *Registry {
+	copy := *r
+	copy.checksums = true
+	return &copy
+}
+
+// ArtifactURL returns the URL of a file of a version. An empty classifier is
+// the main artifact, and an empty extension the one implied by the
+// registry's packaging type (jar unless a type qualifier says otherwise).
+func (u *URLs) ArtifactURL(name, version, classifier, extension string) string {
+	if version == "" {
+		return ""
+	}
+	groupID, artifactID, _ := ParseCoordinates(name)
+	if extension == "" {
+		extension = u.extension()
+	}
+	file := artifactID + "-" + version
+	if classifier != "" {
+		file += "-" + classifier
+	}
+	return fmt.Sprintf("%s/%s/%s/%s/%s.%s",
+		u.baseURL, groupIDToPath(groupID), artifactID, version, file, extension)
+}
+
+// FetchArtifact returns a file of a version with the integrity from its
+// checksum file. Artifacts without any checksum file are checked with a
+// HEAD request, so a missing classifier is reported as NotFoundError.
+func (r *Registry) FetchArtifact(ctx context.Context, name, version, classifier, extension string) (*core.Artifact, error) {
+	groupID, artifactID, _ := ParseCoordinates(name)
+	if groupID == "" || artifactID == "" {
+		return nil, fmt.Errorf("invalid Maven coordinate: %s (expected groupId:artifactId)", name)
+	}
+	if extension == "" {
+		extension = r.urls.extension()
+	}
+
+	fileURL := r.urls.ArtifactURL(name, version, classifier, extension)
+	integrity, err := r.fetchChecksum(ctx, fileURL)
+	if err != nil {
+		return nil, err
+	}
+	if integrity == "" {
+		status, _, err := r.client.HeadHeader(ctx, fileURL)
+		if err != nil {
+			return nil, err
+		}
+		if status == http.StatusNotFound {
+			return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name, Version: version}
+		}
+	}
+
+	return &core.Artifact{
+		URL:        fileURL,
+		Filename:   path.Base(fileURL),
+		Classifier: classifier,
+		Extension:  extension,
+		Integrity:  integrity,
+	}, nil
+}
+
+// fetchChecksum reads the first checksum file published for fileURL and
+// returns it as an integrity string, or "" if there is none.
+func (r *Registry) fetchChecksum(ctx context.Context, fileURL string) (string, error) {
+	for _, alg := range checksumAlgorithms {
+		body, err := r.client.GetText(ctx, fileURL+"."+alg.ext)
+		if err != nil {
+			if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
+				continue
+			}
+			return "", err
+		}
+		if sum := parseChecksumFile(body, alg.size); sum != "" {
+			return core.HexIntegrity(alg.ext, sum), nil
+		}
+	}
+	return "", nil
+}
+
+// parseChecksumFile extracts the hex digest from a checksum file, which is
+// either the bare digest or, as written by some tools, "<digest>  <file>".
+func parseChecksumFile(body string, size int) string {
+	fields := strings.Fields(body)
+	if len(fields) == 0 {
+		return ""
+	}
+	sum := strings.ToLower(fields[0])
+	if len(sum) != size {
+		return ""
+	}
+	if _, err := hex.DecodeString(sum); err != nil {
+		return ""
+	}
+	return sum
+}
+
+// fillIntegrity sets the integrity of each version from its main
+// artifact's checksum file. Versions whose checksum can't be read keep an
+// empty Integrity.
+func (r *Registry) fillIntegrity(ctx context.Context, name string, versions []core.Version) {
+	indexes := make([]int, len(versions))
+	for i := range versions {
+		indexes[i] = i
+	}
+	sums := core.ParallelMap(ctx, indexes, checksumConcurrency, func(ctx context.Context, i int) (*string, error) {
+		sum, err := r.fetchChecksum(ctx, r.urls.Download(name, versions[i].Number))
+		return &sum, err
+	})
+	for i, sum := range sums {
+		versions[i].Integrity = *sum
+	}
+}
@@ -0,0 +1,109 @@
+package maven
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+func TestFetchArtifact(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/com/example/lib/1.0.0/lib-1.0.0-sources.jar.sha1", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("A9993E364706816ABA3E25717850C26C9CD0D89D  lib-1.0.0-sources.jar\n"))
+	})
+	mux.HandleFunc("/com/example/lib/1.0.0/lib-1.0.0.aar.sha256", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"))
+	})
+	mux.HandleFunc("/com/example/lib/1.0.0/lib-1.0.0-javadoc.jar", func(w http.ResponseWriter, r *http.Request) {})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	ctx := context.Background()
+
+	a, err := reg.FetchArtifact(ctx, "com.example:lib", "1.0.0", "sources", "")
+	if err != nil {
+		t.Fatalf("FetchArtifact failed: %v", err)
+	}
+	if a.URL != server.URL+"/com/example/lib/1.0.0/lib-1.0.0-sources.jar" || a.Filename != "lib-1.0.0-sources.jar" {
+		t.Errorf("unexpected artifact %+v", a)
+	}
+	if a.Integrity != "sha1-qZk+NkcGgWq6PiVxeFDCbJzQ2J0=" {
+		t.Errorf("Integrity = %q", a.Integrity)
+	}
+
+	a, err = reg.FetchArtifact(ctx, "com.example:lib", "1.0.0", "", "aar")
+	if err != nil {
+		t.Fatalf("FetchArtifact failed: %v", err)
+	}
+	if a.Integrity != "sha256-ungWv48Bz+pBQUDeXa4iI7ADYaOWF3qctBD/YfIAFa0=" {
+		t.Errorf("Integrity = %q", a.Integrity)
+	}
+
+	// No checksum files, but the artifact exists
+	a, err = reg.FetchArtifact(ctx, "com.example:lib", "1.0.0", "javadoc", "")
+	if err != nil {
+		t.Fatalf("FetchArtifact failed: %v", err)
+	}
+	if a.Integrity != "" {
+		t.Errorf("Integrity = %q, want none", a.Integrity)
+	}
+
+	if _, err := reg.FetchArtifact(ctx, "com.example:lib", "1.0.0", "tests", ""); err == nil {
+		t.Error("expected NotFoundError for a missing classifier")
+	} else if _, ok := err.(*core.NotFoundError); !ok {
+		t.Errorf("expected NotFoundError, got %T", err)
+	}
+}
+
+func TestWithChecksums(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/com/example/lib/maven-metadata.xml", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<metadata><versioning><versions><version>1.0.0</version><version>1.1.0</version></versions></versioning></metadata>`))
+	})
+	mux.HandleFunc("/com/example/lib/1.0.0/lib-1.0.0.jar.md5", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("900150983cd24fb0d6963f7d28e17f72"))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	versions, err := reg.FetchVersions(context.Background(), "com.example:lib")
+	if err != nil {
+		t.Fatalf("FetchVersions failed: %v", err)
+	}
+	if versions[0].Integrity != "" {
+		t.Errorf("checksums fetched without WithChecksums: %q", versions[0].Integrity)
+	}
+
+	versions, err = reg.WithChecksums().FetchVersions(context.Background(), "com.example:lib")
+	if err != nil {
+		t.Fatalf("FetchVersions failed: %v", err)
+	}
+	if versions[0].Integrity != "md5-kAFQmDzST7DWlj99KOF/cg==" {
+		t.Errorf("Integrity = %q", versions[0].Integrity)
+	}
+	if versions[1].Integrity != "" {
+		t.Errorf("expected no integrity for 1.1.0, got %q", versions[1].Integrity)
+	}
+}
+
+func TestArtifactURL(t *testing.T) {
+	u := &URLs{baseURL: DefaultURL}
+	tests := []struct {
+		classifier, extension, want string
+	}{
+		{"", "", DefaultURL + "/com/example/lib/1.0.0/lib-1.0.0.jar"},
+		{"javadoc", "", DefaultURL + "/com/example/lib/1.0.0/lib-1.0.0-javadoc.jar"},
+		{"", "pom", DefaultURL + "/com/example/lib/1.0.0/lib-1.0.0.pom"},
+		{"", "war", DefaultURL + "/com/example/lib/1.0.0/lib-1.0.0.war"},
+	}
+	for _, tt := range tests {
+		if got := u.ArtifactURL("com.example:lib", "1.0.0", tt.classifier, tt.extension); got != tt.want {
+			t.Errorf("ArtifactURL(%q, %q) = %q, want %q", tt.classifier, tt.extension, got, tt.want)
+		}
+	}
+}
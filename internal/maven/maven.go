@@ -4,11 +4,15 @@ package maven
 import (
 	"context"
 	"encoding/xml"
+	"errors"
 	"fmt"
+	"maps"
 	"net/url"
+	"slices"
 	"strings"
 	"time"
 
+	"github.com/git-pkgs/purl"
 	"github.com/git-pkgs/registries/internal/core"
 	"github.com/git-pkgs/registries/internal/urlparser"
 )
@@ -27,10 +31,13 @@ func init() {
 }
 
 type Registry struct {
-	baseURL   string
-	searchURL string
-	client    *core.Client
-	urls      *URLs
+	baseURL        string
+	searchURL      string
+	client         *core.Client
+	urls           *URLs
+	moduleMetadata bool
+	checksums      bool
+	poms           *pomCache
 }
 
 func New(baseURL string, client *core.Client) *Registry {
@@ -38,14 +45,36 @@ func New(baseURL string, client *core.Client) *Registry {
 		baseURL = DefaultURL
 	}
 	r := &Registry{
-		baseURL:   strings.TrimSuffix(baseURL, "/"),
-		searchURL: SearchURL,
-		client:    client,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client:  client,
+		poms:    newPOMCache(pomCacheSize),
+	}
+	// search.maven.org only indexes Central. Asking it about artifacts in
+	// another repository, such as GitHub Packages or a company Nexus,
+	// would return Central's artifacts of the same name.
+	if r.baseURL == DefaultURL {
+		r.searchURL = SearchURL
 	}
 	r.urls = &URLs{baseURL: r.baseURL}
 	return r
 }
 
+// WithQualifiers applies the type and classifier qualifiers of a PURL, which
+// select the artifact file that Download points to, such as the sources jar
+// ("classifier=sources") or the POM ("type=pom").
+func (r *Registry) WithQualifiers(qualifiers map[string]string) core.Registry {
+	copy := *r
+	urls := *r.urls
+	if t := qualifiers["type"]; t != "" && t != "jar" {
+		urls.packaging = t
+	}
+	if c := qualifiers["classifier"]; c != "" {
+		urls.classifier = c
+	}
+	copy.urls = &urls
+	return &copy
+}
+
 func (r *Registry) Ecosystem() string {
 	return ecosystem
 }
@@ -111,12 +140,20 @@ type pomSCM struct {
 }
 
 type pomDep struct {
+	GroupID    string         `xml:"groupId"`
+	ArtifactID string         `xml:"artifactId"`
+	Version    string         `xml:"version"`
+	Scope      string         `xml:"scope"`
+	Optional   string         `xml:"optional"`
+	Type       string         `xml:"type"`
+	Classifier string         `xml:"classifier"`
+	SystemPath string         `xml:"systemPath"`
+	Exclusions []pomExclusion `xml:"exclusions>exclusion"`
+}
+
+type pomExclusion struct {
 	GroupID    string `xml:"groupId"`
 	ArtifactID string `xml:"artifactId"`
-	Version    string `xml:"version"`
-	Scope      string `xml:"scope"`
-	Optional   string `xml:"optional"`
-	Type       string `xml:"type"`
 }
 
 type pomDeveloper struct {
@@ -160,15 +197,21 @@ func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package
 	}
 
 	// First try the search API to get basic metadata
-	searchURL := fmt.Sprintf("%s/solrsearch/select?q=g:%s+AND+a:%s&core=gav&rows=1&wt=json",
-		r.searchURL, url.QueryEscape(groupID), url.QueryEscape(artifactID))
-
-	var searchResp searchResponse
-	if err := r.client.GetJSON(ctx, searchURL, &searchResp); err == nil && searchResp.Response.NumFound > 0 {
-		doc := searchResp.Response.Docs[0]
-		// Fetch the POM for more details
-		pom, _ := r.fetchPOM(ctx, groupID, artifactID, doc.Version, 0)
-		return r.packageFromSearchAndPOM(doc, pom), nil
+	if r.searchURL != "" {
+		searchURL := fmt.Sprintf("%s/solrsearch/select?q=g:%s+AND+a:%s&core=gav&rows=1&wt=json",
+			r.searchURL, url.QueryEscape(groupID), url.QueryEscape(artifactID))
+
+		var searchResp searchResponse
+		if err := r.client.GetJSON(ctx, searchURL, &searchResp); err == nil && searchResp.Response.NumFound > 0 {
+			doc := searchResp.Response.Docs[0]
+			// Fetch the POM for more details
+			pom, err := r.fetchPOM(ctx, groupID, artifactID, doc.Version)
+			if budgetErr, partial := budgetExceeded(err); budgetErr != nil {
+				budgetErr.Partial = r.packageFromSearchAndPOM(doc, partial)
+				return nil, budgetErr
+			}
+			return r.packageFromSearchAndPOM(doc, pom), nil
+		}
 	}
 
 	// Fallback: try to get maven-metadata.xml
@@ -194,7 +237,11 @@ func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package
 		latestVersion = metadata.Versioning.Versions[len(metadata.Versioning.Versions)-1]
 	}
 
-	pom, _ := r.fetchPOM(ctx, groupID, artifactID, latestVersion, 0)
+	pom, err := r.fetchPOM(ctx, groupID, artifactID, latestVersion)
+	if budgetErr, partial := budgetExceeded(err); budgetErr != nil {
+		budgetErr.Partial = r.packageFromMetadataAndPOM(metadata, partial)
+		return nil, budgetErr
+	}
 	return r.packageFromMetadataAndPOM(metadata, pom), nil
 }
 
@@ -210,41 +257,131 @@ type versioning struct {
 	Versions []string `xml:"versions>version"`
 }
 
-func (r *Registry) fetchPOM(ctx context.Context, groupID, artifactID, version string, depth int) (*pomXML, error) {
-	if depth > maxParentDepth {
-		return nil, fmt.Errorf("max parent depth exceeded")
+// fetchPOM fetches a POM and merges in what it inherits from its parents.
+// The parent chain is only discovered one POM at a time, so each parent adds
+// to the time budget as it is found. If the deadline is reached partway up
+// the chain, the error is a BudgetExceededError whose Partial is the POM
+// merged with the parents fetched so far.
+func (r *Registry) fetchPOM(ctx context.Context, groupID, artifactID, version string) (*pomXML, error) {
+	budget := core.NewBudget(ctx, "maven parent POMs", 1)
+	var chain []*pomXML
+	var urls []string
+	var err error
+	for depth := 0; depth <= maxParentDepth; depth++ {
+		var pom *pomXML
+		pom, err = r.getPOM(budget, groupID, artifactID, version)
+		if err != nil {
+			break
+		}
+		chain = append(chain, pom)
+		urls = append(urls, r.pomURL(groupID, artifactID, version))
+		if pom.Parent == nil {
+			break
+		}
+		groupID, artifactID, version = pom.Parent.GroupID, pom.Parent.ArtifactID, pom.Parent.Version
+		if depth < maxParentDepth {
+			budget.Add(1)
+		}
+	}
+	if len(chain) == 0 {
+		return nil, err
 	}
 
-	pomURL := fmt.Sprintf("%s/%s/%s/%s/%s-%s.pom",
-		r.baseURL, groupIDToPath(groupID), artifactID, version, artifactID, version)
+	noteInheritedFields(ctx, chain, urls)
+
+	// Merge from the furthest ancestor down, so each POM inherits what its
+	// parent already inherited.
+	for i := len(chain) - 1; i > 0; i-- {
+		mergePOMs(chain[i-1], chain[i])
+	}
+	for _, pom := range chain {
+		// Fill in groupID/version from parent if not set
+		if pom.GroupID == "" && pom.Parent != nil {
+			pom.GroupID = pom.Parent.GroupID
+		}
+		if pom.Version == "" && pom.Parent != nil {
+			pom.Version = pom.Parent.Version
+		}
+	}
+
+	// A parent that can't be fetched is skipped, as the artifact's own POM
+	// is still usable, unless the reason is the caller's deadline.
+	if err != nil && budget.Expired() {
+		return nil, budget.Exceeded(chain[0])
+	}
+	return chain[0], nil
+}
+
+// budgetExceeded unpacks a BudgetExceededError from fetchPOM along with the
+// partially merged POM it carries. Callers replace Partial with their own
+// result so the POM type never reaches users.
+func budgetExceeded(err error) (*core.BudgetExceededError, *pomXML) {
+	var budgetErr *core.BudgetExceededError
+	if !errors.As(err, &budgetErr) {
+		return nil, nil
+	}
+	pom, _ := budgetErr.Partial.(*pomXML)
+	return budgetErr, pom
+}
 
-	body, err := r.client.GetBody(ctx, pomURL)
+func (r *Registry) getPOM(budget *core.Budget, groupID, artifactID, version string) (*pomXML, error) {
+	ctx, cancel, err := budget.Next()
 	if err != nil {
 		return nil, err
 	}
+	defer cancel()
+
+	// Parents are shared by many artifacts, so POMs are kept by GAV
+	var body []byte
+	url := r.pomURL(groupID, artifactID, version)
+	gav := groupID + ":" + artifactID + ":" + version
+	if cached, ok := r.poms.get(gav); ok {
+		body = cached.body
+		core.NoteSource(ctx, url, cached.fetchedAt)
+	} else {
+		body, err = r.client.GetBody(ctx, url)
+		if err != nil {
+			return nil, err
+		}
+		if cacheable(version) {
+			r.poms.add(gav, body, time.Now())
+		}
+	}
+	budget.Done()
 
 	var pom pomXML
 	if err := xml.Unmarshal(body, &pom); err != nil {
 		return nil, err
 	}
+	return &pom, nil
+}
 
-	// Resolve parent POM if present
-	if pom.Parent != nil && depth < maxParentDepth {
-		parentPOM, err := r.fetchPOM(ctx, pom.Parent.GroupID, pom.Parent.ArtifactID, pom.Parent.Version, depth+1)
-		if err == nil {
-			mergePOMs(&pom, parentPOM)
-		}
-	}
+func (r *Registry) pomURL(groupID, artifactID, version string) string {
+	return fmt.Sprintf("%s/%s/%s/%s/%s-%s.pom",
+		r.baseURL, groupIDToPath(groupID), artifactID, version, artifactID, version)
+}
 
-	// Fill in groupID/version from parent if not set
-	if pom.GroupID == "" && pom.Parent != nil {
-		pom.GroupID = pom.Parent.GroupID
+// noteInheritedFields attributes the package fields mergePOMs fills in to
+// the POM in chain they will come from, for FetchPackageWithSources. It
+// must run before the chain is merged.
+func noteInheritedFields(ctx context.Context, chain []*pomXML, urls []string) {
+	origin := make(map[string]string)
+	for i := len(chain) - 1; i >= 0; i-- {
+		pom := chain[i]
+		for field, set := range map[string]bool{
+			"Description": pom.Description != "",
+			"Homepage":    pom.URL != "",
+			"Licenses":    len(pom.Licenses) > 0,
+			"Repository":  pom.SCM.URL != "",
+		} {
+			if set {
+				origin[field] = urls[i]
+			}
+		}
 	}
-	if pom.Version == "" && pom.Parent != nil {
-		pom.Version = pom.Parent.Version
+	for _, field := range slices.Sorted(maps.Keys(origin)) {
+		core.NoteFields(ctx, origin[field], field)
 	}
-
-	return &pom, nil
 }
 
 func mergePOMs(child, parent *pomXML) {
@@ -267,14 +404,18 @@ func mergePOMs(child, parent *pomXML) {
 
 func (r *Registry) packageFromSearchAndPOM(doc searchDoc, pom *pomXML) *core.Package {
 	pkg := &core.Package{
-		Name:      fmt.Sprintf("%s:%s", doc.GroupID, doc.ArtifactID),
-		Namespace: doc.GroupID,
+		Name:          fmt.Sprintf("%s:%s", doc.GroupID, doc.ArtifactID),
+		Namespace:     doc.GroupID,
+		LatestVersion: doc.Version,
 		Metadata: map[string]any{
 			"group_id":      doc.GroupID,
 			"artifact_id":   doc.ArtifactID,
 			"version_count": doc.VersionCount,
 		},
 	}
+	if !core.IsPrerelease(ecosystem, doc.Version) {
+		pkg.LatestStableVersion = doc.Version
+	}
 
 	if pom != nil {
 		pkg.Description = pom.Description
@@ -287,9 +428,21 @@ func (r *Registry) packageFromSearchAndPOM(doc searchDoc, pom *pomXML) *core.Pac
 }
 
 func (r *Registry) packageFromMetadataAndPOM(metadata mavenMetadata, pom *pomXML) *core.Package {
+	latest := metadata.Versioning.Latest
+	if latest == "" && len(metadata.Versioning.Versions) > 0 {
+		latest = metadata.Versioning.Versions[len(metadata.Versioning.Versions)-1]
+	}
+	// <release> skips snapshots but not milestones or release candidates
+	stable := metadata.Versioning.Release
+	if stable == "" || core.IsPrerelease(ecosystem, stable) {
+		stable = core.LatestStableOf(ecosystem, metadata.Versioning.Versions)
+	}
+
 	pkg := &core.Package{
-		Name:      fmt.Sprintf("%s:%s", metadata.GroupID, metadata.ArtifactID),
-		Namespace: metadata.GroupID,
+		Name:                fmt.Sprintf("%s:%s", metadata.GroupID, metadata.ArtifactID),
+		Namespace:           metadata.GroupID,
+		LatestVersion:       latest,
+		LatestStableVersion: stable,
 		Metadata: map[string]any{
 			"group_id":    metadata.GroupID,
 			"artifact_id": metadata.ArtifactID,
@@ -325,23 +478,28 @@ func (r *Registry) FetchVersions(ctx context.Context, name string) ([]core.Versi
 	}
 
 	// Use search API to get all versions
-	searchURL := fmt.Sprintf("%s/solrsearch/select?q=g:%s+AND+a:%s&core=gav&rows=200&wt=json",
-		r.searchURL, url.QueryEscape(groupID), url.QueryEscape(artifactID))
-
-	var searchResp searchResponse
-	if err := r.client.GetJSON(ctx, searchURL, &searchResp); err == nil && searchResp.Response.NumFound > 0 {
-		versions := make([]core.Version, len(searchResp.Response.Docs))
-		for i, doc := range searchResp.Response.Docs {
-			var publishedAt time.Time
-			if doc.Timestamp > 0 {
-				publishedAt = time.UnixMilli(doc.Timestamp)
+	if r.searchURL != "" {
+		searchURL := fmt.Sprintf("%s/solrsearch/select?q=g:%s+AND+a:%s&core=gav&rows=200&wt=json",
+			r.searchURL, url.QueryEscape(groupID), url.QueryEscape(artifactID))
+
+		var searchResp searchResponse
+		if err := r.client.GetJSON(ctx, searchURL, &searchResp); err == nil && searchResp.Response.NumFound > 0 {
+			versions := make([]core.Version, len(searchResp.Response.Docs))
+			for i, doc := range searchResp.Response.Docs {
+				var publishedAt time.Time
+				if doc.Timestamp > 0 {
+					publishedAt = time.UnixMilli(doc.Timestamp)
+				}
+				versions[i] = core.Version{
+					Number:      doc.Version,
+					PublishedAt: publishedAt,
+				}
 			}
-			versions[i] = core.Version{
-				Number:      doc.Version,
-				PublishedAt: publishedAt,
+			if r.checksums {
+				r.fillIntegrity(ctx, name, versions)
 			}
+			return versions, nil
 		}
-		return versions, nil
 	}
 
 	// Fallback: maven-metadata.xml
@@ -367,17 +525,99 @@ func (r *Registry) FetchVersions(ctx context.Context, name string) ([]core.Versi
 			Number: v,
 		}
 	}
+	if r.checksums {
+		r.fillIntegrity(ctx, name, versions)
+	}
 
 	return versions, nil
 }
 
+// checksumDoc is a search result from the gav core, one per artifact version.
+type checksumDoc struct {
+	ID         string   `json:"id"`
+	GroupID    string   `json:"g"`
+	ArtifactID string   `json:"a"`
+	Version    string   `json:"v"`
+	Packaging  string   `json:"p"`
+	Timestamp  int64    `json:"timestamp"`
+	Extensions []string `json:"ec"`
+}
+
+type checksumResponse struct {
+	Response struct {
+		NumFound int           `json:"numFound"`
+		Docs     []checksumDoc `json:"docs"`
+	} `json:"response"`
+}
+
+// LookupByChecksum finds the artifact versions that published a file with
+// the given digest, using the search API's SHA-1 index. Central doesn't index
+// other digests, so SHA-256 and SHA-512 digests return an error wrapping
+// ErrNotSupported, as do registries for repositories other than Central.
+func (r *Registry) LookupByChecksum(ctx context.Context, digest string) ([]core.ArtifactMatch, error) {
+	algorithm, sum, err := core.ParseDigest(digest)
+	if err != nil {
+		return nil, err
+	}
+	if r.searchURL == "" {
+		return nil, fmt.Errorf("maven checksum lookup outside Central: %w", core.ErrNotSupported)
+	}
+	if algorithm != "sha1" {
+		return nil, fmt.Errorf("maven checksum lookup by %s: %w", algorithm, core.ErrNotSupported)
+	}
+
+	searchURL := fmt.Sprintf("%s/solrsearch/select?q=1:%%22%s%%22&rows=20&wt=json", r.searchURL, sum)
+	var resp checksumResponse
+	if err := r.client.GetJSON(ctx, searchURL, &resp); err != nil {
+		return nil, err
+	}
+
+	matches := make([]core.ArtifactMatch, 0, len(resp.Response.Docs))
+	for _, doc := range resp.Response.Docs {
+		name := doc.GroupID + ":" + doc.ArtifactID
+		var publishedAt time.Time
+		if doc.Timestamp > 0 {
+			publishedAt = time.UnixMilli(doc.Timestamp)
+		}
+		matches = append(matches, core.ArtifactMatch{
+			Name:        name,
+			Version:     doc.Version,
+			PURL:        r.urls.PURL(name, doc.Version),
+			PublishedAt: publishedAt,
+			Metadata: map[string]any{
+				"packaging":  doc.Packaging,
+				"extensions": doc.Extensions,
+			},
+		})
+	}
+	return matches, nil
+}
+
 func (r *Registry) FetchDependencies(ctx context.Context, name, version string) ([]core.Dependency, error) {
 	groupID, artifactID, _ := ParseCoordinates(name)
 	if groupID == "" || artifactID == "" {
 		return nil, fmt.Errorf("invalid Maven coordinate: %s (expected groupId:artifactId)", name)
 	}
 
-	pom, err := r.fetchPOM(ctx, groupID, artifactID, version, 0)
+	if r.moduleMetadata {
+		// Modules whose variants all live elsewhere (available-at) list
+		// no dependencies of their own; the POM still does
+		module, err := r.FetchModule(ctx, name, version)
+		if err == nil {
+			if deps := module.Dependencies(); len(deps) > 0 {
+				return deps, nil
+			}
+		} else if _, ok := err.(*core.NotFoundError); !ok {
+			return nil, err
+		}
+	}
+
+	pom, err := r.fetchPOM(ctx, groupID, artifactID, version)
+	// Dependencies aren't inherited from parents here, so a POM whose
+	// parents ran out of time still has everything needed.
+	if _, partial := budgetExceeded(err); partial != nil {
+		pom, err = partial, nil
+	}
 	if err != nil {
 		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
 			return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name, Version: version}
@@ -399,19 +639,57 @@ func (r *Registry) FetchDependencies(ctx context.Context, name, version string)
 			Requirements: d.Version,
 			Scope:        scope,
 			Optional:     optional,
+			Metadata:     dependencyMetadata(d),
 		})
 	}
 
 	return deps, nil
 }
 
+// dependencyMetadata returns the scope, type, classifier, system path and
+// exclusions declared on a POM dependency, or nil if none are set. The
+// scope is kept as written because several Maven scopes share a
+// core.Scope. Exclusions are "groupId:artifactId" strings and may use "*"
+// wildcards.
+func dependencyMetadata(d pomDep) map[string]any {
+	if d.Scope == "" && d.Type == "" && d.Classifier == "" && d.SystemPath == "" && len(d.Exclusions) == 0 {
+		return nil
+	}
+
+	metadata := make(map[string]any)
+	if d.Scope != "" {
+		metadata["scope"] = strings.ToLower(d.Scope)
+	}
+	if d.Type != "" {
+		metadata["type"] = d.Type
+	}
+	if d.Classifier != "" {
+		metadata["classifier"] = d.Classifier
+	}
+	if d.SystemPath != "" {
+		metadata["system_path"] = d.SystemPath
+	}
+	if len(d.Exclusions) > 0 {
+		exclusions := make([]string, len(d.Exclusions))
+		for i, e := range d.Exclusions {
+			exclusions[i] = fmt.Sprintf("%s:%s", e.GroupID, e.ArtifactID)
+		}
+		metadata["exclusions"] = exclusions
+	}
+	return metadata
+}
+
+// mapMavenScope maps a POM dependency scope. Provided and system
+// dependencies are needed to compile but supplied by the environment, not
+// packaged with the artifact, and an import brings in a BOM's dependency
+// management; all three are Build.
 func mapMavenScope(scope string) core.Scope {
 	switch strings.ToLower(scope) {
 	case "compile", "":
 		return core.Runtime
 	case "test":
 		return core.Test
-	case "provided":
+	case "provided", "system", "import":
 		return core.Build
 	case "runtime":
 		return core.Runtime
@@ -436,11 +714,22 @@ func (r *Registry) FetchMaintainers(ctx context.Context, name string) ([]core.Ma
 	}
 
 	latestVersion := versions[0].Number
-	pom, err := r.fetchPOM(ctx, groupID, artifactID, latestVersion, 0)
+	pom, err := r.fetchPOM(ctx, groupID, artifactID, latestVersion)
+	if budgetErr, partial := budgetExceeded(err); budgetErr != nil {
+		budgetErr.Partial = pomMaintainers(partial)
+		return nil, budgetErr
+	}
 	if err != nil {
 		return nil, err
 	}
 
+	return pomMaintainers(pom), nil
+}
+
+func pomMaintainers(pom *pomXML) []core.Maintainer {
+	if pom == nil {
+		return nil
+	}
 	maintainers := make([]core.Maintainer, len(pom.Developers))
 	for i, dev := range pom.Developers {
 		maintainers[i] = core.Maintainer{
@@ -451,8 +740,7 @@ func (r *Registry) FetchMaintainers(ctx context.Context, name string) ([]core.Ma
 			URL:   dev.URL,
 		}
 	}
-
-	return maintainers, nil
+	return maintainers
 }
 
 func groupIDToPath(groupID string) string {
@@ -460,7 +748,9 @@ func groupIDToPath(groupID string) string {
 }
 
 type URLs struct {
-	baseURL string
+	baseURL    string
+	packaging  string
+	classifier string
 }
 
 func (u *URLs) Registry(name, version string) string {
@@ -472,12 +762,18 @@ func (u *URLs) Registry(name, version string) string {
 }
 
 func (u *URLs) Download(name, version string) string {
-	if version == "" {
-		return ""
+	return u.ArtifactURL(name, version, u.classifier, "")
+}
+
+// extension returns the file extension of the artifact, which is the
+// packaging type except for packagings that produce a jar.
+func (u *URLs) extension() string {
+	switch u.packaging {
+	case "", "bundle", "maven-plugin", "ejb":
+		return "jar"
+	default:
+		return u.packaging
 	}
-	groupID, artifactID, _ := ParseCoordinates(name)
-	return fmt.Sprintf("%s/%s/%s/%s/%s-%s.jar",
-		u.baseURL, groupIDToPath(groupID), artifactID, version, artifactID, version)
 }
 
 func (u *URLs) Documentation(name, version string) string {
@@ -490,8 +786,12 @@ func (u *URLs) Documentation(name, version string) string {
 
 func (u *URLs) PURL(name, version string) string {
 	groupID, artifactID, _ := ParseCoordinates(name)
-	if version != "" {
-		return fmt.Sprintf("pkg:maven/%s/%s@%s", groupID, artifactID, version)
+	qualifiers := make(map[string]string)
+	if u.packaging != "" {
+		qualifiers["type"] = u.packaging
+	}
+	if u.classifier != "" {
+		qualifiers["classifier"] = u.classifier
 	}
-	return fmt.Sprintf("pkg:maven/%s/%s", groupID, artifactID)
+	return purl.New(ecosystem, groupID, artifactID, version, qualifiers).String()
 }
@@ -3,9 +3,12 @@ package maven
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/git-pkgs/registries/internal/core"
 )
@@ -184,6 +187,60 @@ func TestFetchVersionsFallback(t *testing.T) {
 	}
 }
 
+func TestNonCentralSkipsSearch(t *testing.T) {
+	if reg := New("", nil); reg.searchURL != SearchURL {
+		t.Errorf("expected Central to use search, got %q", reg.searchURL)
+	}
+
+	reg := New("https://maven.pkg.github.com/octo-org/octo-repo", nil)
+	if reg.searchURL != "" {
+		t.Errorf("expected other repositories not to use Central's search, got %q", reg.searchURL)
+	}
+}
+
+func TestLookupByChecksum(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if q := r.URL.Query().Get("q"); q != `1:"8ad7e2a9b3d8c3f0c8c0e6d0a2e1f45b5c1ad1c4"` {
+			t.Errorf("unexpected query %q", q)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"response":{"numFound":1,"docs":[{"id":"org.slf4j:slf4j-api:2.0.9","g":"org.slf4j","a":"slf4j-api","v":"2.0.9","p":"jar","timestamp":1693843282000,"ec":["-sources.jar",".jar",".pom"]}]}}`))
+	}))
+	defer server.Close()
+
+	reg := New("", core.DefaultClient())
+	reg.searchURL = server.URL
+
+	matches, err := reg.LookupByChecksum(context.Background(), "sha1-8AD7E2A9B3D8C3F0C8C0E6D0A2E1F45B5C1AD1C4")
+	if err != nil {
+		t.Fatalf("LookupByChecksum failed: %v", err)
+	}
+	if len(matches) != 1 {
+		t.Fatalf("expected 1 match, got %d", len(matches))
+	}
+	m := matches[0]
+	if m.Name != "org.slf4j:slf4j-api" || m.Version != "2.0.9" {
+		t.Errorf("unexpected match %s@%s", m.Name, m.Version)
+	}
+	if m.PURL != "pkg:maven/org.slf4j/slf4j-api@2.0.9" {
+		t.Errorf("unexpected PURL %q", m.PURL)
+	}
+	if m.PublishedAt.IsZero() {
+		t.Error("expected a publish time")
+	}
+
+	_, err = reg.LookupByChecksum(context.Background(), "sha256:"+strings.Repeat("ab", 32))
+	if !errors.Is(err, core.ErrNotSupported) {
+		t.Errorf("expected ErrNotSupported for SHA-256, got %v", err)
+	}
+
+	other := New("https://maven.pkg.github.com/octo-org/octo-repo", nil)
+	_, err = other.LookupByChecksum(context.Background(), strings.Repeat("ab", 20))
+	if !errors.Is(err, core.ErrNotSupported) {
+		t.Errorf("expected ErrNotSupported outside Central, got %v", err)
+	}
+}
+
 func TestFetchDependencies(t *testing.T) {
 	mux := http.NewServeMux()
 
@@ -211,6 +268,50 @@ func TestFetchDependencies(t *testing.T) {
       <artifactId>commons-lang3</artifactId>
       <version>3.12.0</version>
     </dependency>
+    <dependency>
+      <groupId>io.netty</groupId>
+      <artifactId>netty-transport-native-epoll</artifactId>
+      <version>4.1.100.Final</version>
+      <classifier>linux-x86_64</classifier>
+      <type>jar</type>
+      <exclusions>
+        <exclusion>
+          <groupId>io.netty</groupId>
+          <artifactId>netty-common</artifactId>
+        </exclusion>
+        <exclusion>
+          <groupId>*</groupId>
+          <artifactId>*</artifactId>
+        </exclusion>
+      </exclusions>
+    </dependency>
+    <dependency>
+      <groupId>jakarta.servlet</groupId>
+      <artifactId>jakarta.servlet-api</artifactId>
+      <version>6.0.0</version>
+      <scope>provided</scope>
+    </dependency>
+    <dependency>
+      <groupId>com.sun</groupId>
+      <artifactId>tools</artifactId>
+      <version>1.8</version>
+      <scope>system</scope>
+      <systemPath>${java.home}/../lib/tools.jar</systemPath>
+    </dependency>
+    <dependency>
+      <groupId>org.springframework.boot</groupId>
+      <artifactId>spring-boot-dependencies</artifactId>
+      <version>3.2.0</version>
+      <type>pom</type>
+      <scope>import</scope>
+    </dependency>
+    <dependency>
+      <groupId>org.projectlombok</groupId>
+      <artifactId>lombok</artifactId>
+      <version>1.18.30</version>
+      <scope>provided</scope>
+      <optional>true</optional>
+    </dependency>
   </dependencies>
 </project>`
 		_, _ = w.Write([]byte(pom))
@@ -226,8 +327,24 @@ func TestFetchDependencies(t *testing.T) {
 		t.Fatalf("FetchDependencies failed: %v", err)
 	}
 
-	if len(deps) != 3 {
-		t.Fatalf("expected 3 dependencies, got %d", len(deps))
+	if len(deps) != 8 {
+		t.Fatalf("expected 8 dependencies, got %d", len(deps))
+	}
+
+	if deps[2].Metadata != nil {
+		t.Errorf("expected no metadata for commons-lang3, got %v", deps[2].Metadata)
+	}
+
+	epoll := deps[3]
+	if epoll.Metadata["classifier"] != "linux-x86_64" {
+		t.Errorf("expected classifier 'linux-x86_64', got %v", epoll.Metadata["classifier"])
+	}
+	if epoll.Metadata["type"] != "jar" {
+		t.Errorf("expected type 'jar', got %v", epoll.Metadata["type"])
+	}
+	exclusions, _ := epoll.Metadata["exclusions"].([]string)
+	if len(exclusions) != 2 || exclusions[0] != "io.netty:netty-common" || exclusions[1] != "*:*" {
+		t.Errorf("unexpected exclusions: %v", epoll.Metadata["exclusions"])
 	}
 
 	scopeMap := make(map[string]core.Scope)
@@ -249,6 +366,27 @@ func TestFetchDependencies(t *testing.T) {
 	if scopeMap["org.apache.commons:commons-lang3"] != core.Runtime {
 		t.Errorf("expected runtime scope for commons-lang3, got %q", scopeMap["org.apache.commons:commons-lang3"])
 	}
+
+	// Scopes that share a core.Scope keep the one the POM declares
+	for _, tt := range []struct {
+		index      int
+		scope      core.Scope
+		mavenScope string
+	}{
+		{0, core.Test, "test"},
+		{4, core.Build, "provided"},
+		{5, core.Build, "system"},
+		{6, core.Build, "import"},
+		{7, core.Optional, "provided"},
+	} {
+		d := deps[tt.index]
+		if d.Scope != tt.scope || d.Metadata["scope"] != tt.mavenScope {
+			t.Errorf("%s: scope %q, metadata scope %v, want %q and %q", d.Name, d.Scope, d.Metadata["scope"], tt.scope, tt.mavenScope)
+		}
+	}
+	if deps[5].Metadata["system_path"] != "${java.home}/../lib/tools.jar" {
+		t.Errorf("expected system path, got %v", deps[5].Metadata["system_path"])
+	}
 }
 
 func TestFetchMaintainers(t *testing.T) {
@@ -355,11 +493,26 @@ func TestParentPOMResolution(t *testing.T) {
 
 	reg := New(server.URL, core.DefaultClient())
 
-	pom, err := reg.fetchPOM(context.Background(), "com.example", "child", "1.0.0", 0)
+	ctx, log := core.RecordSources(context.Background())
+	pom, err := reg.fetchPOM(ctx, "com.example", "child", "1.0.0")
 	if err != nil {
 		t.Fatalf("fetchPOM failed: %v", err)
 	}
 
+	sources := log.Sources()
+	if len(sources) != 2 || sources[0].URL != server.URL+"/com/example/child/1.0.0/child-1.0.0.pom" {
+		t.Fatalf("unexpected sources: %+v", sources)
+	}
+	if len(sources[0].Fields) != 0 {
+		t.Errorf("expected nothing attributed to the child POM, got %v", sources[0].Fields)
+	}
+	if got := strings.Join(sources[1].Fields, ","); got != "Description,Homepage,Licenses,Repository" {
+		t.Errorf("expected inherited fields attributed to the parent POM, got %q", got)
+	}
+	if sources[1].FetchedAt.IsZero() {
+		t.Error("expected a fetch time for the parent POM")
+	}
+
 	// Should inherit from parent
 	if pom.Description != "Parent project description" {
 		t.Errorf("expected inherited description, got %q", pom.Description)
@@ -372,6 +525,57 @@ func TestParentPOMResolution(t *testing.T) {
 	}
 }
 
+func TestParentPOMDeadline(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/com/example/child/1.0.0/child-1.0.0.pom", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<project>
+  <parent>
+    <groupId>com.example</groupId>
+    <artifactId>parent</artifactId>
+    <version>1.0.0</version>
+  </parent>
+  <artifactId>child</artifactId>
+  <description>Child project</description>
+  <dependencies>
+    <dependency>
+      <groupId>org.slf4j</groupId>
+      <artifactId>slf4j-api</artifactId>
+      <version>2.0.9</version>
+    </dependency>
+  </dependencies>
+</project>`))
+	})
+	mux.HandleFunc("/com/example/parent/1.0.0/parent-1.0.0.pom", func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	_, err := reg.fetchPOM(ctx, "com.example", "child", "1.0.0")
+	var budgetErr *core.BudgetExceededError
+	if !errors.As(err, &budgetErr) {
+		t.Fatalf("expected BudgetExceededError, got %v", err)
+	}
+	if pom, _ := budgetErr.Partial.(*pomXML); pom == nil || pom.Description != "Child project" {
+		t.Errorf("expected the child POM as partial result, got %+v", budgetErr.Partial)
+	}
+
+	// Dependencies come from the artifact's own POM, which did arrive.
+	ctx, cancel = context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	deps, err := reg.FetchDependencies(ctx, "com.example:child", "1.0.0")
+	if err != nil {
+		t.Fatalf("FetchDependencies failed: %v", err)
+	}
+	if len(deps) != 1 || deps[0].Name != "org.slf4j:slf4j-api" {
+		t.Errorf("unexpected dependencies %+v", deps)
+	}
+}
+
 func TestURLBuilder(t *testing.T) {
 	reg := New("https://repo1.maven.org/maven2", nil)
 	urls := reg.URLs()
@@ -397,6 +601,40 @@ func TestURLBuilder(t *testing.T) {
 	}
 }
 
+func TestWithQualifiers(t *testing.T) {
+	tests := []struct {
+		qualifiers   map[string]string
+		download     string
+		expectedPURL string
+	}{
+		{
+			map[string]string{"classifier": "sources"},
+			"https://repo1.maven.org/maven2/com/google/guava/guava/32.1.0/guava-32.1.0-sources.jar",
+			"pkg:maven/com.google.guava/guava@32.1.0?classifier=sources",
+		},
+		{
+			map[string]string{"type": "pom"},
+			"https://repo1.maven.org/maven2/com/google/guava/guava/32.1.0/guava-32.1.0.pom",
+			"pkg:maven/com.google.guava/guava@32.1.0?type=pom",
+		},
+		{
+			map[string]string{"type": "jar"},
+			"https://repo1.maven.org/maven2/com/google/guava/guava/32.1.0/guava-32.1.0.jar",
+			"pkg:maven/com.google.guava/guava@32.1.0",
+		},
+	}
+
+	for _, tt := range tests {
+		urls := New("", nil).WithQualifiers(tt.qualifiers).URLs()
+		if got := urls.Download("com.google.guava:guava", "32.1.0"); got != tt.download {
+			t.Errorf("%v: expected download %q, got %q", tt.qualifiers, tt.download, got)
+		}
+		if got := urls.PURL("com.google.guava:guava", "32.1.0"); got != tt.expectedPURL {
+			t.Errorf("%v: expected PURL %q, got %q", tt.qualifiers, tt.expectedPURL, got)
+		}
+	}
+}
+
 func TestEcosystem(t *testing.T) {
 	reg := New("", nil)
 	if reg.Ecosystem() != "maven" {
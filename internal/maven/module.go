@@ -0,0 +1,220 @@
+package maven
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+// Module is a parsed Gradle Module Metadata (.module) file.
+// See https://github.com/gradle/gradle/blob/master/platforms/documentation/docs/src/docs/design/gradle-module-metadata-latest-specification.md
+type Module struct {
+	FormatVersion string
+	Group         string
+	Module        string
+	Version       string
+	Variants      []Variant
+}
+
+// Variant is a single published variant of a module, such as apiElements,
+// runtimeElements or sourcesElements.
+type Variant struct {
+	Name         string
+	Attributes   map[string]any
+	Dependencies []core.Dependency
+	// Constraints are the variant's dependencyConstraints: versions it
+	// requires of modules if something else brings them in, as a BOM does.
+	// They aren't dependencies, so Dependencies leaves them out.
+	Constraints []core.Dependency
+	Files       []VariantFile
+	AvailableAt string // coordinate of another module that provides this variant
+}
+
+// VariantFile is an artifact file attached to a variant.
+type VariantFile struct {
+	Name   string
+	URL    string
+	Size   int64
+	SHA256 string
+	SHA1   string
+}
+
+type moduleJSON struct {
+	FormatVersion string `json:"formatVersion"`
+	Component     struct {
+		Group   string `json:"group"`
+		Module  string `json:"module"`
+		Version string `json:"version"`
+	} `json:"component"`
+	Variants []moduleVariant `json:"variants"`
+}
+
+type moduleVariant struct {
+	Name         string             `json:"name"`
+	Attributes   map[string]any     `json:"attributes"`
+	Dependencies []moduleDependency `json:"dependencies"`
+	Constraints  []moduleDependency `json:"dependencyConstraints"`
+	Files        []moduleFile       `json:"files"`
+	AvailableAt  *struct {
+		Group   string `json:"group"`
+		Module  string `json:"module"`
+		Version string `json:"version"`
+	} `json:"available-at"`
+}
+
+type moduleDependency struct {
+	Group   string `json:"group"`
+	Module  string `json:"module"`
+	Version struct {
+		Requires string `json:"requires"`
+		Strictly string `json:"strictly"`
+		Prefers  string `json:"prefers"`
+	} `json:"version"`
+	Excludes []struct {
+		Group  string `json:"group"`
+		Module string `json:"module"`
+	} `json:"excludes"`
+	Reason string `json:"reason"`
+}
+
+type moduleFile struct {
+	Name   string `json:"name"`
+	URL    string `json:"url"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+	SHA1   string `json:"sha1"`
+}
+
+// WithModuleMetadata returns a new Registry that reads Gradle Module Metadata
+// when it is published, using its variant dependencies in FetchDependencies
+// instead of the POM. Artifacts without a .module file fall back to the POM.
+func (r *Registry) WithModuleMetadata() *Registry {
+	copy := *r
+	copy.moduleMetadata = true
+	return &copy
+}
+
+// FetchModule retrieves and parses the Gradle Module Metadata for a version.
+func (r *Registry) FetchModule(ctx context.Context, name, version string) (*Module, error) {
+	groupID, artifactID, _ := ParseCoordinates(name)
+	if groupID == "" || artifactID == "" {
+		return nil, fmt.Errorf("invalid Maven coordinate: %s (expected groupId:artifactId)", name)
+	}
+
+	moduleURL := fmt.Sprintf("%s/%s/%s/%s/%s-%s.module",
+		r.baseURL, groupIDToPath(groupID), artifactID, version, artifactID, version)
+
+	body, err := r.client.GetBody(ctx, moduleURL)
+	if err != nil {
+		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
+			return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name, Version: version}
+		}
+		return nil, err
+	}
+
+	var raw moduleJSON
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, err
+	}
+
+	return parseModule(raw), nil
+}
+
+func parseModule(raw moduleJSON) *Module {
+	m := &Module{
+		FormatVersion: raw.FormatVersion,
+		Group:         raw.Component.Group,
+		Module:        raw.Component.Module,
+		Version:       raw.Component.Version,
+		Variants:      make([]Variant, len(raw.Variants)),
+	}
+
+	for i, v := range raw.Variants {
+		variant := Variant{
+			Name:       v.Name,
+			Attributes: v.Attributes,
+		}
+		if v.AvailableAt != nil {
+			variant.AvailableAt = fmt.Sprintf("%s:%s:%s", v.AvailableAt.Group, v.AvailableAt.Module, v.AvailableAt.Version)
+		}
+		for _, d := range v.Dependencies {
+			variant.Dependencies = append(variant.Dependencies, moduleDependencyToCore(d))
+		}
+		for _, d := range v.Constraints {
+			variant.Constraints = append(variant.Constraints, moduleDependencyToCore(d))
+		}
+		for _, f := range v.Files {
+			variant.Files = append(variant.Files, VariantFile(f))
+		}
+		m.Variants[i] = variant
+	}
+
+	return m
+}
+
+func moduleDependencyToCore(d moduleDependency) core.Dependency {
+	requirements := d.Version.Strictly
+	if requirements == "" {
+		requirements = d.Version.Requires
+	}
+	if requirements == "" {
+		requirements = d.Version.Prefers
+	}
+
+	var metadata map[string]any
+	if len(d.Excludes) > 0 || d.Reason != "" {
+		metadata = make(map[string]any)
+		if len(d.Excludes) > 0 {
+			exclusions := make([]string, len(d.Excludes))
+			for i, e := range d.Excludes {
+				exclusions[i] = fmt.Sprintf("%s:%s", e.Group, e.Module)
+			}
+			metadata["exclusions"] = exclusions
+		}
+		if d.Reason != "" {
+			metadata["reason"] = d.Reason
+		}
+	}
+
+	return core.Dependency{
+		Name:         fmt.Sprintf("%s:%s", d.Group, d.Module),
+		Requirements: requirements,
+		Scope:        core.Runtime,
+		Metadata:     metadata,
+	}
+}
+
+// Dependencies flattens the dependencies of every variant into one list,
+// recording the variants each dependency appears in under Metadata["variants"].
+// A dependency is listed once for each version constraint it has across the
+// variants. All of them are Runtime: dependencies of API variants such as
+// apiElements are needed by consumers to compile and to run. Variants whose
+// files are published elsewhere (available-at) are skipped.
+func (m *Module) Dependencies() []core.Dependency {
+	var deps []core.Dependency
+	index := make(map[string]int)
+
+	for _, v := range m.Variants {
+		if v.AvailableAt != "" {
+			continue
+		}
+		for _, d := range v.Dependencies {
+			key := d.Name + "\x00" + d.Requirements
+			if i, ok := index[key]; ok {
+				deps[i].Metadata["variants"] = append(deps[i].Metadata["variants"].([]string), v.Name)
+				continue
+			}
+			metadata := map[string]any{"variants": []string{v.Name}}
+			for k, val := range d.Metadata {
+				metadata[k] = val
+			}
+			d.Metadata = metadata
+			index[key] = len(deps)
+			deps = append(deps, d)
+		}
+	}
+
+	return deps
+}
@@ -0,0 +1,231 @@
+package maven
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+const guavaModule = `{
+  "formatVersion": "1.1",
+  "component": {"group": "com.google.guava", "module": "guava", "version": "33.0.0-jre"},
+  "variants": [
+    {
+      "name": "apiElements",
+      "attributes": {"org.gradle.usage": "java-api", "org.gradle.category": "library"},
+      "dependencies": [
+        {"group": "com.google.guava", "module": "failureaccess", "version": {"requires": "1.0.2"}},
+        {"group": "com.google.code.findbugs", "module": "jsr305", "version": {"requires": "3.0.2", "strictly": "[3.0,4.0)"}}
+      ],
+      "files": [{"name": "guava-33.0.0-jre.jar", "url": "guava-33.0.0-jre.jar", "size": 3077000, "sha256": "abc"}]
+    },
+    {
+      "name": "runtimeElements",
+      "attributes": {"org.gradle.usage": "java-runtime", "org.gradle.category": "library"},
+      "dependencies": [
+        {"group": "com.google.guava", "module": "failureaccess", "version": {"requires": "1.0.2"}},
+        {
+          "group": "org.checkerframework", "module": "checker-qual", "version": {"requires": "3.41.0"},
+          "excludes": [{"group": "*", "module": "*"}],
+          "reason": "annotations only"
+        }
+      ],
+      "dependencyConstraints": [
+        {"group": "com.google.errorprone", "module": "error_prone_annotations", "version": {"requires": "2.23.0"}}
+      ]
+    },
+    {
+      "name": "sourcesElements",
+      "attributes": {"org.gradle.docstype": "sources"},
+      "files": [{"name": "guava-33.0.0-jre-sources.jar", "url": "guava-33.0.0-jre-sources.jar"}]
+    }
+  ]
+}`
+
+func TestFetchModule(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/com/google/guava/guava/33.0.0-jre/guava-33.0.0-jre.module", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(guavaModule))
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	module, err := reg.FetchModule(context.Background(), "com.google.guava:guava", "33.0.0-jre")
+	if err != nil {
+		t.Fatalf("FetchModule failed: %v", err)
+	}
+
+	if module.FormatVersion != "1.1" {
+		t.Errorf("expected format version '1.1', got %q", module.FormatVersion)
+	}
+	if len(module.Variants) != 3 {
+		t.Fatalf("expected 3 variants, got %d", len(module.Variants))
+	}
+
+	api := module.Variants[0]
+	if api.Attributes["org.gradle.usage"] != "java-api" {
+		t.Errorf("unexpected api attributes: %v", api.Attributes)
+	}
+	if len(api.Dependencies) != 2 {
+		t.Fatalf("expected 2 api dependencies, got %d", len(api.Dependencies))
+	}
+	if api.Dependencies[1].Requirements != "[3.0,4.0)" {
+		t.Errorf("expected strict version to win, got %q", api.Dependencies[1].Requirements)
+	}
+	if len(api.Files) != 1 || api.Files[0].SHA256 != "abc" {
+		t.Errorf("unexpected api files: %+v", api.Files)
+	}
+
+	runtime := module.Variants[1]
+	if len(runtime.Constraints) != 1 || runtime.Constraints[0].Name != "com.google.errorprone:error_prone_annotations" || runtime.Constraints[0].Requirements != "2.23.0" {
+		t.Errorf("unexpected runtime constraints: %+v", runtime.Constraints)
+	}
+
+	if len(module.Variants[2].Dependencies) != 0 {
+		t.Errorf("expected no dependencies for sources variant")
+	}
+}
+
+func TestFetchDependenciesWithModuleMetadata(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/com/google/guava/guava/33.0.0-jre/guava-33.0.0-jre.module", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(guavaModule))
+	})
+	mux.HandleFunc("/com/google/guava/guava/33.0.0-jre/guava-33.0.0-jre.pom", func(w http.ResponseWriter, r *http.Request) {
+		t.Error("POM should not be fetched when module metadata is available")
+		w.WriteHeader(404)
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient()).WithModuleMetadata()
+	deps, err := reg.FetchDependencies(context.Background(), "com.google.guava:guava", "33.0.0-jre")
+	if err != nil {
+		t.Fatalf("FetchDependencies failed: %v", err)
+	}
+
+	// failureaccess is in both the API and runtime variants, and the
+	// runtime variant's constraint isn't a dependency
+	want := []struct {
+		name     string
+		variants []string
+	}{
+		{"com.google.guava:failureaccess", []string{"apiElements", "runtimeElements"}},
+		{"com.google.code.findbugs:jsr305", []string{"apiElements"}},
+		{"org.checkerframework:checker-qual", []string{"runtimeElements"}},
+	}
+	if len(deps) != len(want) {
+		t.Fatalf("expected %d dependencies, got %+v", len(want), deps)
+	}
+	for i, w := range want {
+		if deps[i].Name != w.name || deps[i].Scope != core.Runtime {
+			t.Errorf("deps[%d] = %s (%s), want %s (runtime)", i, deps[i].Name, deps[i].Scope, w.name)
+		}
+		if got := deps[i].Metadata["variants"]; !reflect.DeepEqual(got, w.variants) {
+			t.Errorf("deps[%d] variants = %v, want %v", i, got, w.variants)
+		}
+	}
+
+	checker := deps[2]
+	if checker.Metadata["reason"] != "annotations only" {
+		t.Errorf("unexpected reason: %v", checker.Metadata["reason"])
+	}
+	exclusions, _ := checker.Metadata["exclusions"].([]string)
+	if len(exclusions) != 1 || exclusions[0] != "*:*" {
+		t.Errorf("unexpected exclusions: %v", checker.Metadata["exclusions"])
+	}
+}
+
+func TestFetchDependenciesModuleFallback(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/com/example/lib/1.0.0/lib-1.0.0.pom", func(w http.ResponseWriter, r *http.Request) {
+		pom := `<?xml version="1.0" encoding="UTF-8"?>
+<project>
+  <groupId>com.example</groupId>
+  <artifactId>lib</artifactId>
+  <version>1.0.0</version>
+  <dependencies>
+    <dependency>
+      <groupId>org.slf4j</groupId>
+      <artifactId>slf4j-api</artifactId>
+      <version>2.0.9</version>
+    </dependency>
+  </dependencies>
+</project>`
+		_, _ = w.Write([]byte(pom))
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient()).WithModuleMetadata()
+	deps, err := reg.FetchDependencies(context.Background(), "com.example:lib", "1.0.0")
+	if err != nil {
+		t.Fatalf("FetchDependencies failed: %v", err)
+	}
+
+	if len(deps) != 1 || deps[0].Name != "org.slf4j:slf4j-api" {
+		t.Errorf("expected POM dependencies, got %+v", deps)
+	}
+}
+
+func TestModuleDependenciesKeepsConstraints(t *testing.T) {
+	m := &Module{Variants: []Variant{
+		{
+			Name:         "jdk8RuntimeElements",
+			Attributes:   map[string]any{"org.gradle.usage": "java-runtime"},
+			Dependencies: []core.Dependency{{Name: "org.slf4j:slf4j-api", Requirements: "1.7.36"}},
+		},
+		{
+			Name:         "jdk11RuntimeElements",
+			Attributes:   map[string]any{"org.gradle.usage": "java-runtime"},
+			Dependencies: []core.Dependency{{Name: "org.slf4j:slf4j-api", Requirements: "2.0.9"}},
+		},
+		{
+			Name:         "jdk17RuntimeElements",
+			Attributes:   map[string]any{"org.gradle.usage": "java-runtime"},
+			Dependencies: []core.Dependency{{Name: "org.slf4j:slf4j-api", Requirements: "2.0.9"}},
+		},
+	}}
+
+	deps := m.Dependencies()
+	if len(deps) != 2 || deps[0].Requirements != "1.7.36" || deps[1].Requirements != "2.0.9" {
+		t.Fatalf("expected one dependency per constraint, got %+v", deps)
+	}
+	if variants, _ := deps[1].Metadata["variants"].([]string); len(variants) != 2 {
+		t.Errorf("expected 2.0.9 from two variants, got %v", variants)
+	}
+}
+
+func TestFetchDependenciesAvailableAtFallsBackToPOM(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/com/example/lib/1.0.0/lib-1.0.0.module", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"formatVersion": "1.1", "variants": [
+			{"name": "jvmRuntimeElements", "available-at": {"group": "com.example", "module": "lib-jvm", "version": "1.0.0"}}
+		]}`))
+	})
+	mux.HandleFunc("/com/example/lib/1.0.0/lib-1.0.0.pom", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<project><dependencies><dependency>
+  <groupId>org.jetbrains.kotlin</groupId><artifactId>kotlin-stdlib</artifactId><version>1.9.0</version>
+</dependency></dependencies></project>`))
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient()).WithModuleMetadata()
+	deps, err := reg.FetchDependencies(context.Background(), "com.example:lib", "1.0.0")
+	if err != nil {
+		t.Fatalf("FetchDependencies failed: %v", err)
+	}
+	if len(deps) != 1 || deps[0].Name != "org.jetbrains.kotlin:kotlin-stdlib" {
+		t.Errorf("expected POM dependencies, got %+v", deps)
+	}
+}
@@ -0,0 +1,65 @@
+package maven
+
+import (
+	"container/list"
+	"strings"
+	"sync"
+	"time"
+)
+
+// pomCacheSize is how many POMs a registry keeps in memory. Parent POMs
+// such as org.springframework:spring-parent are shared by thousands of
+// artifacts, so a few hundred covers the parents of most dependency trees.
+const pomCacheSize = 512
+
+// pomCache keeps the bodies of recently fetched POMs by GAV, evicting the
+// least recently used. Released POMs never change, so entries don't
+// expire; SNAPSHOT versions aren't cached. Bodies rather than parsed POMs
+// are kept because fetchPOM merges parents into the POMs it is given.
+type pomCache struct {
+	mu      sync.Mutex
+	size    int
+	entries map[string]*list.Element
+	order   *list.List // most recently used first
+}
+
+type pomEntry struct {
+	gav       string
+	body      []byte
+	fetchedAt time.Time
+}
+
+func newPOMCache(size int) *pomCache {
+	return &pomCache{size: size, entries: make(map[string]*list.Element), order: list.New()}
+}
+
+func cacheable(version string) bool {
+	return !strings.HasSuffix(version, "-SNAPSHOT")
+}
+
+func (c *pomCache) get(gav string) (*pomEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[gav]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(e)
+	return e.Value.(*pomEntry), true
+}
+
+func (c *pomCache) add(gav string, body []byte, fetchedAt time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[gav]; ok {
+		e.Value = &pomEntry{gav: gav, body: body, fetchedAt: fetchedAt}
+		c.order.MoveToFront(e)
+		return
+	}
+	c.entries[gav] = c.order.PushFront(&pomEntry{gav: gav, body: body, fetchedAt: fetchedAt})
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*pomEntry).gav)
+	}
+}
@@ -0,0 +1,89 @@
+package maven
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+func TestParentPOMCache(t *testing.T) {
+	var parentFetches, snapshotFetches atomic.Int32
+	mux := http.NewServeMux()
+	for _, child := range []string{"a", "b"} {
+		mux.HandleFunc(fmt.Sprintf("/com/example/%s/1.0.0/%s-1.0.0.pom", child, child), func(w http.ResponseWriter, r *http.Request) {
+			_, _ = fmt.Fprintf(w, `<project>
+  <parent><groupId>com.example</groupId><artifactId>parent</artifactId><version>1.0.0</version></parent>
+  <artifactId>%s</artifactId>
+</project>`, child)
+		})
+	}
+	mux.HandleFunc("/com/example/parent/1.0.0/parent-1.0.0.pom", func(w http.ResponseWriter, r *http.Request) {
+		parentFetches.Add(1)
+		_, _ = w.Write([]byte(`<project>
+  <groupId>com.example</groupId><artifactId>parent</artifactId><version>1.0.0</version>
+  <licenses><license><name>MIT</name></license></licenses>
+</project>`))
+	})
+	mux.HandleFunc("/com/example/c/1.0.0-SNAPSHOT/c-1.0.0-SNAPSHOT.pom", func(w http.ResponseWriter, r *http.Request) {
+		snapshotFetches.Add(1)
+		_, _ = w.Write([]byte(`<project><groupId>com.example</groupId><artifactId>c</artifactId></project>`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	// Copies for PURL qualifiers share the cache
+	copy := reg.WithQualifiers(map[string]string{"classifier": "sources"}).(*Registry)
+
+	if _, err := reg.fetchPOM(context.Background(), "com.example", "a", "1.0.0"); err != nil {
+		t.Fatal(err)
+	}
+	ctx, log := core.RecordSources(context.Background())
+	pom, err := copy.fetchPOM(ctx, "com.example", "b", "1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := parentFetches.Load(); n != 1 {
+		t.Errorf("parent POM fetched %d times, want 1", n)
+	}
+	if len(pom.Licenses) != 1 || pom.Licenses[0].Name != "MIT" {
+		t.Errorf("expected license from the cached parent, got %v", pom.Licenses)
+	}
+	sources := log.Sources()
+	if len(sources) != 2 || sources[1].URL != server.URL+"/com/example/parent/1.0.0/parent-1.0.0.pom" || sources[1].FetchedAt.IsZero() {
+		t.Errorf("cached parent missing from sources: %+v", sources)
+	}
+
+	for range 2 {
+		if _, err := reg.fetchPOM(context.Background(), "com.example", "c", "1.0.0-SNAPSHOT"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := snapshotFetches.Load(); n != 2 {
+		t.Errorf("SNAPSHOT POM fetched %d times, want 2", n)
+	}
+}
+
+func TestPOMCacheEviction(t *testing.T) {
+	c := newPOMCache(2)
+	now := time.Now()
+	c.add("g:a:1", []byte("a"), now)
+	c.add("g:b:1", []byte("b"), now)
+	c.get("g:a:1")
+	c.add("g:c:1", []byte("c"), now)
+
+	if _, ok := c.get("g:b:1"); ok {
+		t.Error("least recently used entry wasn't evicted")
+	}
+	for _, gav := range []string{"g:a:1", "g:c:1"} {
+		if _, ok := c.get(gav); !ok {
+			t.Errorf("%s evicted", gav)
+		}
+	}
+}
@@ -98,18 +98,29 @@ func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package
 		homepage = resp.URL
 	}
 
+	// "#head" and other "#" versions name a git ref, not a release
+	var releases []string
+	for _, v := range resp.Versions {
+		if !strings.HasPrefix(v.Version, "#") {
+			releases = append(releases, v.Version)
+		}
+	}
+
 	return &core.Package{
-		Name:        resp.Name,
-		Description: resp.Description,
-		Homepage:    homepage,
-		Repository:  urlparser.Parse(resp.URL),
-		Licenses:    resp.License,
-		Keywords:    resp.Tags,
+		Name:          resp.Name,
+		Description:   resp.Description,
+		Homepage:      homepage,
+		Repository:    urlparser.Parse(resp.URL),
+		Documentation: resp.Doc,
+		Licenses:      resp.License,
+		Keywords:      resp.Tags,
 		Metadata: map[string]any{
 			"method": resp.Method,
 			"doc":    resp.Doc,
 			"alias":  resp.Alias,
 		},
+		LatestVersion:       core.LatestOf(ecosystem, releases),
+		LatestStableVersion: core.LatestStableOf(ecosystem, releases),
 	}, nil
 }
 
@@ -200,12 +211,6 @@ func parseDependency(dep string) (name, requirements string) {
 	return
 }
 
-func (r *Registry) FetchMaintainers(ctx context.Context, name string) ([]core.Maintainer, error) {
-	// Nimble directory doesn't expose maintainer info via API
-	// The owner info is typically in the git repository
-	return nil, nil
-}
-
 type URLs struct {
 	baseURL string
 }
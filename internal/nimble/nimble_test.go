@@ -3,6 +3,7 @@ package nimble
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -139,15 +140,9 @@ func TestFetchDependencies(t *testing.T) {
 }
 
 func TestFetchMaintainers(t *testing.T) {
-	reg := New("", nil)
-	maintainers, err := reg.FetchMaintainers(context.Background(), "chronicles")
-	if err != nil {
-		t.Fatalf("FetchMaintainers failed: %v", err)
-	}
-
-	// Nimble doesn't expose maintainer info
-	if len(maintainers) != 0 {
-		t.Errorf("expected 0 maintainers, got %d", len(maintainers))
+	_, err := core.FetchMaintainers(context.Background(), New("", nil), "chronicles")
+	if !errors.Is(err, core.ErrNotSupported) {
+		t.Errorf("expected ErrNotSupported, got %v", err)
 	}
 }
 
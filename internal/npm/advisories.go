@@ -0,0 +1,239 @@
+package npm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+// advisoryBatchSize is how many packages go in each bulk advisory request.
+// npm sends a whole lockfile at once; batching keeps request bodies for very
+// large trees within what registries and proxies accept.
+const advisoryBatchSize = 500
+
+type cvssInfo struct {
+	Score        float64 `json:"score"`
+	VectorString string  `json:"vectorString"`
+}
+
+// bulkAdvisory is an entry in the response to /-/npm/v1/security/advisories/bulk,
+// which maps each package name to the advisories affecting the posted versions.
+type bulkAdvisory struct {
+	ID                 int64    `json:"id"`
+	URL                string   `json:"url"`
+	Title              string   `json:"title"`
+	Severity           string   `json:"severity"`
+	VulnerableVersions string   `json:"vulnerable_versions"`
+	CWE                []string `json:"cwe"`
+	CVSS               cvssInfo `json:"cvss"`
+}
+
+// quickAuditResponse is the response to /-/npm/v1/security/audits/quick,
+// the older endpoint npm falls back to when a registry has no bulk endpoint.
+type quickAuditResponse struct {
+	Advisories map[string]quickAdvisory `json:"advisories"`
+}
+
+type quickAdvisory struct {
+	ID                 int64           `json:"id"`
+	ModuleName         string          `json:"module_name"`
+	URL                string          `json:"url"`
+	Title              string          `json:"title"`
+	Severity           string          `json:"severity"`
+	VulnerableVersions string          `json:"vulnerable_versions"`
+	GitHubAdvisoryID   string          `json:"github_advisory_id"`
+	CWE                json.RawMessage `json:"cwe"`
+	CVSS               cvssInfo        `json:"cvss"`
+}
+
+// quickAuditRequest is the npm-shrinkwrap shaped payload the quick audit
+// endpoint expects, describing a root project and its dependency tree.
+type quickAuditRequest struct {
+	Name         string                   `json:"name"`
+	Version      string                   `json:"version"`
+	Requires     map[string]string        `json:"requires"`
+	Dependencies map[string]quickAuditDep `json:"dependencies"`
+}
+
+type quickAuditDep struct {
+	Version string `json:"version"`
+}
+
+// FetchAdvisories posts the versions to the registry's bulk advisory
+// endpoint, as `npm audit` does, in batches of advisoryBatchSize packages.
+// Registries without the bulk endpoint, which answer 404 or 405, are asked
+// through the quick audit endpoint instead.
+func (r *Registry) FetchAdvisories(ctx context.Context, versions map[string][]string) ([]core.Advisory, error) {
+	names := make([]string, 0, len(versions))
+	for name, vs := range versions {
+		if len(vs) > 0 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	seen := make(map[string]bool)
+	var advisories []core.Advisory
+	add := func(a core.Advisory) {
+		key := a.Name + "\x00" + a.ID
+		if !seen[key] {
+			seen[key] = true
+			advisories = append(advisories, a)
+		}
+	}
+
+	for start := 0; start < len(names); start += advisoryBatchSize {
+		batch := names[start:min(start+advisoryBatchSize, len(names))]
+		found, err := r.fetchBulkAdvisories(ctx, batch, versions)
+		if err != nil {
+			if httpErr, ok := err.(*core.HTTPError); ok && (httpErr.StatusCode == http.StatusNotFound || httpErr.StatusCode == http.StatusMethodNotAllowed) {
+				found, err = r.fetchQuickAudit(ctx, names[start:], versions)
+				if err != nil {
+					return nil, err
+				}
+				for _, a := range found {
+					add(a)
+				}
+				break
+			}
+			return nil, err
+		}
+		for _, a := range found {
+			add(a)
+		}
+	}
+
+	sort.SliceStable(advisories, func(i, j int) bool {
+		if advisories[i].Name != advisories[j].Name {
+			return advisories[i].Name < advisories[j].Name
+		}
+		return advisories[i].ID < advisories[j].ID
+	})
+	return advisories, nil
+}
+
+func (r *Registry) fetchBulkAdvisories(ctx context.Context, names []string, versions map[string][]string) ([]core.Advisory, error) {
+	payload := make(map[string][]string, len(names))
+	for _, name := range names {
+		payload[name] = versions[name]
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := r.client.Post(ctx, r.baseURL+"/-/npm/v1/security/advisories/bulk", "application/json", body)
+	if err != nil {
+		return nil, err
+	}
+	var result map[string][]bulkAdvisory
+	if err := json.Unmarshal(resp, &result); err != nil {
+		return nil, fmt.Errorf("parsing bulk advisories: %w", err)
+	}
+
+	var advisories []core.Advisory
+	for name, list := range result {
+		for _, a := range list {
+			advisories = append(advisories, core.Advisory{
+				ID:                 strconv.FormatInt(a.ID, 10),
+				GHSA:               ghsaFromURL(a.URL),
+				Name:               name,
+				Title:              a.Title,
+				Severity:           a.Severity,
+				VulnerableVersions: a.VulnerableVersions,
+				URL:                a.URL,
+				CWEs:               a.CWE,
+				CVSSScore:          a.CVSS.Score,
+				CVSSVector:         a.CVSS.VectorString,
+			})
+		}
+	}
+	return advisories, nil
+}
+
+// fetchQuickAudit describes the packages as the direct dependencies of a
+// placeholder project. A dependency tree holds one version per name at each
+// level, so a package asked about at several versions takes one request per
+// extra version.
+func (r *Registry) fetchQuickAudit(ctx context.Context, names []string, versions map[string][]string) ([]core.Advisory, error) {
+	var advisories []core.Advisory
+	for layer := 0; ; layer++ {
+		req := quickAuditRequest{
+			Name:         "registries-audit",
+			Version:      "0.0.0",
+			Requires:     make(map[string]string),
+			Dependencies: make(map[string]quickAuditDep),
+		}
+		for _, name := range names {
+			if vs := versions[name]; layer < len(vs) {
+				req.Requires[name] = vs[layer]
+				req.Dependencies[name] = quickAuditDep{Version: vs[layer]}
+			}
+		}
+		if len(req.Dependencies) == 0 {
+			return advisories, nil
+		}
+
+		body, err := json.Marshal(req)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := r.client.Post(ctx, r.baseURL+"/-/npm/v1/security/audits/quick", "application/json", body)
+		if err != nil {
+			return nil, err
+		}
+		var result quickAuditResponse
+		if err := json.Unmarshal(resp, &result); err != nil {
+			return nil, fmt.Errorf("parsing quick audit: %w", err)
+		}
+
+		for _, a := range result.Advisories {
+			ghsa := a.GitHubAdvisoryID
+			if ghsa == "" {
+				ghsa = ghsaFromURL(a.URL)
+			}
+			advisories = append(advisories, core.Advisory{
+				ID:                 strconv.FormatInt(a.ID, 10),
+				GHSA:               ghsa,
+				Name:               a.ModuleName,
+				Title:              a.Title,
+				Severity:           a.Severity,
+				VulnerableVersions: a.VulnerableVersions,
+				URL:                a.URL,
+				CWEs:               parseCWE(a.CWE),
+				CVSSScore:          a.CVSS.Score,
+				CVSSVector:         a.CVSS.VectorString,
+			})
+		}
+	}
+}
+
+// parseCWE reads the quick audit cwe field, a single string on older
+// advisories and a list on newer ones.
+func parseCWE(raw json.RawMessage) []string {
+	var list []string
+	if err := json.Unmarshal(raw, &list); err == nil {
+		return list
+	}
+	var single string
+	if err := json.Unmarshal(raw, &single); err == nil && single != "" {
+		return []string{single}
+	}
+	return nil
+}
+
+// ghsaFromURL returns the GHSA ID from an advisory URL such as
+// https://github.com/advisories/GHSA-p6mc-m468-83gw.
+func ghsaFromURL(u string) string {
+	if id := path.Base(u); strings.HasPrefix(id, "GHSA-") {
+		return id
+	}
+	return ""
+}
@@ -0,0 +1,37 @@
+package npm
+
+import (
+	"context"
+
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+// BulkDependencyBatchSize is 1 as a packument covers a single package.
+func (r *Registry) BulkDependencyBatchSize() int {
+	return 1
+}
+
+// FetchDependenciesBulk reads the dependencies of every requested version
+// of a package from one packument instead of fetching it per version.
+func (r *Registry) FetchDependenciesBulk(ctx context.Context, versions []core.PackageVersion) (map[core.PackageVersion][]core.Dependency, error) {
+	packuments := make(map[string]*packageResponse)
+	result := make(map[core.PackageVersion][]core.Dependency)
+	for _, pv := range versions {
+		resp, ok := packuments[pv.Name]
+		if !ok {
+			var err error
+			if resp, err = r.fetchPackument(ctx, pv.Name); err != nil {
+				return nil, err
+			}
+			packuments[pv.Name] = resp
+		}
+		if v, ok := resp.Versions[pv.Version]; ok {
+			deps, err := r.versionDependencies(ctx, pv.Name, v)
+			if err != nil {
+				return nil, err
+			}
+			result[pv] = deps
+		}
+	}
+	return result, nil
+}
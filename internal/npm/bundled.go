@@ -0,0 +1,131 @@
+package npm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"slices"
+
+	"github.com/git-pkgs/registries/inspect"
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+// bundleList is a bundleDependencies field: a list of dependency names, or
+// true to bundle every dependency.
+type bundleList struct {
+	all   bool
+	names []string
+}
+
+func (b *bundleList) UnmarshalJSON(data []byte) error {
+	var all bool
+	if json.Unmarshal(data, &all) == nil {
+		*b = bundleList{all: all}
+		return nil
+	}
+	var names []string
+	if json.Unmarshal(data, &names) == nil {
+		*b = bundleList{names: names}
+	}
+	// Anything else is treated as bundling nothing, as npm does.
+	return nil
+}
+
+// bundled returns the names of the dependencies the version ships in its
+// tarball, sorted. npm accepts both bundleDependencies and
+// bundledDependencies.
+func (v versionInfo) bundled() []string {
+	var names []string
+	for _, b := range []bundleList{v.BundleDeps, v.BundledDeps} {
+		if b.all {
+			for name := range v.Dependencies {
+				names = append(names, name)
+			}
+		}
+		names = append(names, b.names...)
+	}
+	slices.Sort(names)
+	return slices.Compact(names)
+}
+
+// WithBundledInspection returns a new Registry whose FetchDependencies
+// downloads the tarball of versions that bundle dependencies and reports
+// what is actually in it. Declared bundled dependencies get the version
+// found in Metadata["bundled_version"], and the packages bundled along with
+// them are added with their exact version as Requirements. This is one
+// tarball download per such version.
+func (r *Registry) WithBundledInspection() *Registry {
+	copy := *r
+	copy.inspectBundled = true
+	return &copy
+}
+
+// FetchBundled downloads a version's tarball and lists the packages in its
+// node_modules, including bundled dependencies of bundled packages. It
+// returns nothing for a version that doesn't bundle dependencies.
+func (r *Registry) FetchBundled(ctx context.Context, name, version string) ([]inspect.BundledPackage, error) {
+	resp, err := r.fetchPackument(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	v, ok := resp.Versions[version]
+	if !ok {
+		return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name, Version: version}
+	}
+	return r.route(name).fetchBundled(ctx, name, v)
+}
+
+// versionDependencies returns v's dependencies, with what its tarball
+// bundles if WithBundledInspection is set.
+func (r *Registry) versionDependencies(ctx context.Context, name string, v versionInfo) ([]core.Dependency, error) {
+	deps := v.dependencies()
+	if !r.inspectBundled {
+		return deps, nil
+	}
+	found, err := r.route(name).fetchBundled(ctx, name, v)
+	if err != nil {
+		return nil, err
+	}
+	return addBundled(deps, found), nil
+}
+
+func (r *Registry) fetchBundled(ctx context.Context, name string, v versionInfo) ([]inspect.BundledPackage, error) {
+	if len(v.bundled()) == 0 {
+		return nil, nil
+	}
+	url := v.Dist.Tarball
+	if url == "" {
+		url = r.urls.Download(name, v.Version)
+	}
+	body, err := r.client.GetBody(ctx, url)
+	if err != nil {
+		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
+			return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name, Version: v.Version}
+		}
+		return nil, err
+	}
+	return inspect.ReadBundled(bytes.NewReader(body))
+}
+
+// addBundled records the packages found in a version's tarball in deps:
+// the version of each declared bundled dependency, and the packages bundled
+// with them as extra runtime dependencies.
+func addBundled(deps []core.Dependency, found []inspect.BundledPackage) []core.Dependency {
+	for _, pkg := range found {
+		top := pkg.Path == "node_modules/"+pkg.Name
+		i := slices.IndexFunc(deps, func(d core.Dependency) bool {
+			return d.Name == pkg.Name && d.Metadata["bundled"] == true
+		})
+		if top && i >= 0 {
+			deps[i].Metadata["bundled_version"] = pkg.Version
+			continue
+		}
+		deps = append(deps, core.Dependency{
+			Name:         pkg.Name,
+			Requirements: pkg.Version,
+			Scope:        core.Runtime,
+			Metadata:     map[string]any{"bundled": true, "bundled_path": pkg.Path},
+		})
+	}
+	return deps
+}
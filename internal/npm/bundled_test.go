@@ -0,0 +1,123 @@
+package npm
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+func npmTarball(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, data := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(data)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		_, _ = tw.Write([]byte(data))
+	}
+	_ = tw.Close()
+	_ = gz.Close()
+	return buf.Bytes()
+}
+
+func TestBundledDependencies(t *testing.T) {
+	tarball := npmTarball(t, map[string]string{
+		"package/package.json":                                          `{"name": "bundler", "version": "1.0.0"}`,
+		"package/node_modules/semver/package.json":                      `{"name": "semver", "version": "7.5.4"}`,
+		"package/node_modules/semver/lib/package.json":                  `{"type": "commonjs"}`,
+		"package/node_modules/lru-cache/package.json":                   `{"name": "lru-cache", "version": "6.0.0"}`,
+		"package/node_modules/@npmcli/fs/package.json":                  `{"name": "@npmcli/fs", "version": "3.1.0"}`,
+		"package/node_modules/semver/node_modules/yallist/package.json": `{"name": "yallist", "version": "4.0.0"}`,
+	})
+	tarballRequests := 0
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/bundler/-/bundler-1.0.0.tgz" {
+			tarballRequests++
+			_, _ = w.Write(tarball)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"_id": "bundler",
+			"versions": map[string]any{
+				"1.0.0": map[string]any{
+					"version":            "1.0.0",
+					"dependencies":       map[string]string{"semver": "^7.0.0", "@npmcli/fs": "^3.0.0", "chalk": "^5.0.0"},
+					"bundleDependencies": []string{"semver", "@npmcli/fs"},
+					"dist":               map[string]string{"tarball": server.URL + "/bundler/-/bundler-1.0.0.tgz"},
+				},
+				"0.9.0": map[string]any{
+					"version":             "0.9.0",
+					"dependencies":        map[string]string{"semver": "^7.0.0"},
+					"bundledDependencies": true,
+				},
+			},
+		})
+	}))
+	defer server.Close()
+	ctx := context.Background()
+
+	reg := New(server.URL, core.DefaultClient())
+	versions, err := reg.FetchVersions(ctx, "bundler")
+	if err != nil {
+		t.Fatalf("FetchVersions failed: %v", err)
+	}
+	for _, v := range versions {
+		want := map[string][]string{"1.0.0": {"@npmcli/fs", "semver"}, "0.9.0": {"semver"}}[v.Number]
+		if got, _ := v.Metadata["bundled_dependencies"].([]string); !slices.Equal(got, want) {
+			t.Errorf("%s: bundled_dependencies = %v, want %v", v.Number, got, want)
+		}
+	}
+
+	deps, err := reg.FetchDependencies(ctx, "bundler", "1.0.0")
+	if err != nil {
+		t.Fatalf("FetchDependencies failed: %v", err)
+	}
+	for _, d := range deps {
+		if bundled := d.Metadata["bundled"] == true; bundled != (d.Name != "chalk") {
+			t.Errorf("%s: bundled = %v", d.Name, bundled)
+		}
+	}
+	if tarballRequests != 0 {
+		t.Error("tarball fetched without WithBundledInspection")
+	}
+
+	deps, err = reg.WithBundledInspection().FetchDependencies(ctx, "bundler", "1.0.0")
+	if err != nil {
+		t.Fatalf("FetchDependencies with inspection failed: %v", err)
+	}
+	byName := make(map[string]core.Dependency)
+	for _, d := range deps {
+		byName[d.Name] = d
+	}
+	if len(deps) != 5 {
+		t.Fatalf("expected 3 declared and 2 extra bundled dependencies, got %+v", deps)
+	}
+	if v := byName["semver"].Metadata["bundled_version"]; v != "7.5.4" || byName["semver"].Requirements != "^7.0.0" {
+		t.Errorf("semver = %+v", byName["semver"])
+	}
+	if v := byName["@npmcli/fs"].Metadata["bundled_version"]; v != "3.1.0" {
+		t.Errorf("@npmcli/fs = %+v", byName["@npmcli/fs"])
+	}
+	if d := byName["yallist"]; d.Requirements != "4.0.0" || d.Metadata["bundled_path"] != "node_modules/semver/node_modules/yallist" {
+		t.Errorf("yallist = %+v", d)
+	}
+	if d := byName["lru-cache"]; d.Requirements != "6.0.0" || d.Scope != core.Runtime || d.Metadata["bundled"] != true {
+		t.Errorf("lru-cache = %+v", d)
+	}
+
+	bundled, err := reg.FetchBundled(ctx, "bundler", "1.0.0")
+	if err != nil || len(bundled) != 4 {
+		t.Errorf("FetchBundled = %+v, %v", bundled, err)
+	}
+}
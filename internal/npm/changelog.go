@@ -0,0 +1,28 @@
+package npm
+
+import (
+	"context"
+
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+// FetchChangelog returns the notes for a version from the GitHub or GitLab
+// repository its package.json names: the release for its tag, or its
+// section of the repository's CHANGELOG.md. The registry itself keeps no
+// changelogs.
+func (r *Registry) FetchChangelog(ctx context.Context, name, version string) (*core.Document, error) {
+	resp, err := r.fetchPackument(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if version == "" {
+		version = resp.DistTags["latest"]
+	}
+	v, ok := resp.Versions[version]
+	if !ok {
+		return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name, Version: version}
+	}
+
+	repoURL := core.ExtractRepoURLWithFallback(v.Repository, resp.Repository)
+	return core.RepositoryChangelog(ctx, r.client, repoURL, version)
+}
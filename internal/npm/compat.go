@@ -0,0 +1,193 @@
+package npm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+// Private registries such as Verdaccio and Nexus serve packuments that
+// differ from npmjs.org's: the time map can be missing or incomplete, hold
+// non-string values, or use other timestamp layouts; _id can be escaped or
+// absent; and maintainers can be strings rather than objects. The decoding
+// here tolerates all of these, and WithCompatibilityMode fills in what is
+// missing at the cost of extra requests.
+
+// compatConcurrency bounds the HEAD requests made for missing publish times.
+const compatConcurrency = 8
+
+// WithCompatibilityMode returns a new Registry for private registries whose
+// packuments lack publish times, as Verdaccio's do for versions it proxied
+// before recording them and Nexus group repositories' do altogether.
+// FetchVersions then asks for the tarball of each version without a time
+// and uses its Last-Modified header, so versions aren't returned with zero
+// timestamps. This is one HEAD request per such version.
+func (r *Registry) WithCompatibilityMode() *Registry {
+	copy := *r
+	copy.compat = true
+	return &copy
+}
+
+// timeMap is a packument's time map. Entries that aren't strings, such as
+// the "unpublished" object, are dropped rather than failing the decode.
+type timeMap map[string]string
+
+func (t *timeMap) UnmarshalJSON(data []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		// Some servers send the time map as null or a bare string
+		*t = nil
+		return nil
+	}
+	m := make(timeMap, len(raw))
+	for k, v := range raw {
+		var s string
+		if json.Unmarshal(v, &s) == nil {
+			m[k] = s
+		}
+	}
+	*t = m
+	return nil
+}
+
+// timeLayouts are the timestamp formats seen in packument time maps.
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.000Z0700",
+	"2006-01-02T15:04:05Z0700",
+	"2006-01-02T15:04:05.000",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	time.RFC1123,
+}
+
+// parseTime parses a packument timestamp, treating ones without a zone as
+// UTC. It returns the zero time for anything unrecognised.
+func parseTime(s string) time.Time {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}
+	}
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// rawTime parses a time map entry kept as raw JSON.
+func rawTime(raw json.RawMessage) time.Time {
+	var s string
+	if json.Unmarshal(raw, &s) != nil {
+		return time.Time{}
+	}
+	return parseTime(s)
+}
+
+// maintainerList decodes maintainers given as objects or as "name <email>"
+// strings, and a single one given instead of a list.
+type maintainerList []maintainerInfo
+
+func (m *maintainerList) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && (data[0] == '{' || data[0] == '"') {
+		data = append(append([]byte{'['}, data...), ']')
+	}
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		*m = nil
+		return nil
+	}
+	list := make(maintainerList, 0, len(raw))
+	for _, item := range raw {
+		var info maintainerInfo
+		var s string
+		switch {
+		case json.Unmarshal(item, &info) == nil:
+		case json.Unmarshal(item, &s) == nil:
+			info = parsePerson(s)
+		default:
+			continue
+		}
+		if info.Name != "" || info.Email != "" {
+			list = append(list, info)
+		}
+	}
+	*m = list
+	return nil
+}
+
+// parsePerson parses npm's "Name <email> (url)" person shorthand.
+func parsePerson(s string) maintainerInfo {
+	if i := strings.Index(s, "("); i >= 0 {
+		s = s[:i]
+	}
+	var info maintainerInfo
+	if start := strings.Index(s, "<"); start >= 0 {
+		if end := strings.Index(s[start:], ">"); end >= 0 {
+			info.Email = strings.TrimSpace(s[start+1 : start+end])
+		}
+		s = s[:start]
+	}
+	info.Name = strings.TrimSpace(s)
+	return info
+}
+
+// packageName returns the name of a packument. Some servers escape _id
+// ("@scope%2fname") or leave it out, so the name field and then the
+// requested name are used instead.
+func packageName(resp *packageResponse, requested string) string {
+	id := resp.ID
+	if unescaped, err := url.PathUnescape(id); err == nil {
+		id = unescaped
+	}
+	switch {
+	case resp.Name != "" && id != resp.Name:
+		return resp.Name
+	case id != "":
+		return id
+	default:
+		return requested
+	}
+}
+
+// fillPublishTimes sets the publish time of versions that have none from
+// the Last-Modified header of their tarball.
+func (r *Registry) fillPublishTimes(ctx context.Context, versions []core.Version) {
+	var missing []int
+	for i, v := range versions {
+		if v.PublishedAt.IsZero() && versionTarball(v) != "" {
+			missing = append(missing, i)
+		}
+	}
+	if len(missing) == 0 {
+		return
+	}
+
+	times := core.ParallelMap(ctx, missing, compatConcurrency, func(ctx context.Context, i int) (*time.Time, error) {
+		status, header, err := r.client.HeadHeader(ctx, versionTarball(versions[i]))
+		if err != nil || status != http.StatusOK {
+			return nil, err
+		}
+		t, err := http.ParseTime(header.Get("Last-Modified"))
+		if err != nil {
+			return nil, err
+		}
+		return &t, nil
+	})
+	for i, t := range times {
+		versions[i].PublishedAt = *t
+	}
+}
+
+func versionTarball(v core.Version) string {
+	tarball, _ := v.Metadata["tarball"].(string)
+	return tarball
+}
@@ -0,0 +1,48 @@
+package npm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+// distTagsResponse is the part of a packument needed for dist-tags. Time is
+// raw for the same reason as in statusResponse.
+type distTagsResponse struct {
+	DistTags map[string]string          `json:"dist-tags"`
+	Time     map[string]json.RawMessage `json:"time"`
+}
+
+// FetchDistTags returns the package's dist-tags with the publish time of
+// each tagged version from the packument's time map. The dist-tags endpoint
+// alone doesn't carry times, so the full packument is fetched.
+func (r *Registry) FetchDistTags(ctx context.Context, name string) ([]core.DistTag, error) {
+	r = r.route(name)
+	escapedName := url.PathEscape(name)
+	url := fmt.Sprintf("%s/%s", r.baseURL, escapedName)
+
+	var resp distTagsResponse
+	if err := r.client.GetJSON(ctx, url, &resp); err != nil {
+		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
+			return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name}
+		}
+		return nil, err
+	}
+
+	tags := make([]core.DistTag, 0, len(resp.DistTags))
+	for tag, version := range resp.DistTags {
+		publishedAt := rawTime(resp.Time[version])
+		tags = append(tags, core.DistTag{Name: tag, Version: version, PublishedAt: publishedAt})
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		if (tags[i].Name == "latest") != (tags[j].Name == "latest") {
+			return tags[i].Name == "latest"
+		}
+		return tags[i].Name < tags[j].Name
+	})
+	return tags, nil
+}
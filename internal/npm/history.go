@@ -0,0 +1,30 @@
+package npm
+
+import (
+	"context"
+	"time"
+
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+// FetchPackageAt reconstructs a package as of at from its packument's time
+// map. The package is described from the manifest of the version that was
+// latest then, so its description, license and repository are historical
+// too; dist-tags are left out as their history isn't recorded.
+func (r *Registry) FetchPackageAt(ctx context.Context, name string, at time.Time) (*core.PackageSnapshot, error) {
+	r = r.route(name)
+	resp, err := r.fetchPackument(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := r.versionsFrom(ctx, resp)
+	then, err := core.SnapshotAt(ecosystem, core.Package{Name: name}, versions, at)
+	if err != nil {
+		return nil, err
+	}
+
+	pkg := packageFrom(resp, name, then.Package.LatestVersion)
+	delete(pkg.Metadata, "dist-tags")
+	return core.SnapshotAt(ecosystem, *pkg, versions, at)
+}
@@ -0,0 +1,54 @@
+package npm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+func TestFetchPackageAt(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"name":      "event-stream",
+			"dist-tags": map[string]string{"latest": "4.0.1"},
+			"versions": map[string]any{
+				"3.3.5": map[string]any{"version": "3.3.5", "license": "MIT", "description": "construct pipes of streams"},
+				"3.3.6": map[string]any{"version": "3.3.6", "license": "MIT", "description": "compromised"},
+				"4.0.1": map[string]any{"version": "4.0.1", "license": "MIT", "description": "current"},
+			},
+			"time": map[string]string{
+				"created":  "2011-06-07T00:00:00.000Z",
+				"modified": "2018-11-27T00:00:00.000Z",
+				"3.3.5":    "2018-09-05T00:00:00.000Z",
+				"3.3.6":    "2018-09-09T00:00:00.000Z",
+				"4.0.1":    "2018-09-10T00:00:00.000Z",
+			},
+		})
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	at := time.Date(2018, 9, 9, 12, 0, 0, 0, time.UTC)
+	snap, err := core.FetchPackageAt(context.Background(), reg, "event-stream", at)
+	if err != nil {
+		t.Fatalf("FetchPackageAt failed: %v", err)
+	}
+
+	if snap.Package.LatestVersion != "3.3.6" {
+		t.Errorf("latest = %q, want 3.3.6", snap.Package.LatestVersion)
+	}
+	if snap.Package.Description != "compromised" {
+		t.Errorf("description = %q, want the 3.3.6 manifest's", snap.Package.Description)
+	}
+	if len(snap.Versions) != 2 {
+		t.Errorf("expected 2 versions published by then, got %d", len(snap.Versions))
+	}
+	if _, ok := snap.Package.Metadata["dist-tags"]; ok {
+		t.Error("current dist-tags shouldn't be in a snapshot")
+	}
+}
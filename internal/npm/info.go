@@ -0,0 +1,43 @@
+package npm
+
+import (
+	"context"
+
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+// registryDoc is the document npm registries serve at their root. npmjs.org
+// sends CouchDB-style database info; Verdaccio and others send less.
+type registryDoc struct {
+	DBName    string `json:"db_name"`
+	Engine    string `json:"engine"`
+	DocCount  int64  `json:"doc_count"`
+	UpdateSeq any    `json:"update_seq"`
+}
+
+// RegistryInfo returns the registry's root document.
+func (r *Registry) RegistryInfo(ctx context.Context) (*core.RegistryInfo, error) {
+	var doc registryDoc
+	if err := r.client.GetJSON(ctx, r.baseURL+"/", &doc); err != nil {
+		return nil, err
+	}
+	info := &core.RegistryInfo{
+		Ecosystem: ecosystem,
+		URL:       r.baseURL,
+		Software:  doc.Engine,
+		Packages:  doc.DocCount,
+	}
+	if doc.DBName != "" || doc.UpdateSeq != nil {
+		info.Metadata = map[string]any{
+			"db_name":    doc.DBName,
+			"update_seq": doc.UpdateSeq,
+		}
+	}
+	return info, nil
+}
+
+// Ping requests the registry's /-/ping endpoint, which npm's own client uses
+// for "npm ping".
+func (r *Registry) Ping(ctx context.Context) error {
+	return core.PingURL(ctx, r.client, r.baseURL+"/-/ping")
+}
@@ -0,0 +1,60 @@
+package npm
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+func TestRegistryInfo(t *testing.T) {
+	pings := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			_, _ = w.Write([]byte(`{"db_name":"registry","engine":"couch/stub","doc_count":3746542,"update_seq":"abc"}`))
+		case "/-/ping":
+			pings++
+			_, _ = w.Write([]byte(`{}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	ctx := context.Background()
+
+	info, err := core.FetchRegistryInfo(ctx, reg)
+	if err != nil {
+		t.Fatalf("FetchRegistryInfo failed: %v", err)
+	}
+	if info.Software != "couch/stub" || info.Packages != 3746542 || info.Metadata["db_name"] != "registry" {
+		t.Errorf("unexpected info %+v", info)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := core.Ping(ctx, reg); err != nil {
+			t.Fatalf("Ping failed: %v", err)
+		}
+	}
+	if pings != 2 {
+		t.Errorf("expected every ping to reach the server, got %d requests", pings)
+	}
+}
+
+func TestPingUnauthorized(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	err := New(server.URL, core.DefaultClient()).Ping(context.Background())
+	var httpErr *core.HTTPError
+	if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected 401 HTTPError, got %v", err)
+	}
+}
@@ -0,0 +1,44 @@
+package npm
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+func TestFetchVersionsInstallSignals(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"name":"addon","versions":{
+			"1.0.0":{"version":"1.0.0","scripts":{"test":"jest","postinstall":"node setup.js","preinstall":"node check.js"}},
+			"2.0.0":{"version":"2.0.0","gypfile":true,"scripts":{"test":"jest"}},
+			"3.0.0":{"version":"3.0.0","scripts":{"build":"tsc"}}
+		}}`))
+	}))
+	defer server.Close()
+
+	versions, err := New(server.URL, core.DefaultClient()).FetchVersions(context.Background(), "addon")
+	if err != nil {
+		t.Fatalf("FetchVersions failed: %v", err)
+	}
+	byNumber := map[string]core.Version{}
+	for _, v := range versions {
+		byNumber[v.Number] = v
+	}
+
+	if got := core.InstallScripts(byNumber["1.0.0"]); !reflect.DeepEqual(got, []string{"preinstall", "postinstall"}) {
+		t.Errorf("unexpected install scripts %v", got)
+	}
+	if core.HasNativeCode(byNumber["1.0.0"]) {
+		t.Error("expected no native code without a gypfile")
+	}
+	if v := byNumber["2.0.0"]; !core.HasNativeCode(v) || !reflect.DeepEqual(core.InstallScripts(v), []string{"install"}) {
+		t.Errorf("expected a gypfile to imply native code and node-gyp's install script, got %v", v.Metadata)
+	}
+	if v := byNumber["3.0.0"]; core.HasInstallScripts(v) || core.HasNativeCode(v) {
+		t.Errorf("expected no install signals, got %v", v.Metadata)
+	}
+}
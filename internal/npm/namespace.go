@@ -0,0 +1,79 @@
+package npm
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+// FetchNamespace returns an npm organization and its members, from the
+// registry's org API. namespace is the org's scope, with or without the
+// "@". npm doesn't verify organizations, and listing members may need a
+// token for the org on registries that don't make them public.
+func (r *Registry) FetchNamespace(ctx context.Context, namespace string) (*core.Namespace, error) {
+	scope := normalizeScope(namespace)
+	reg := r.route(scope + "/")
+	org := strings.TrimPrefix(scope, "@")
+
+	endpoint := fmt.Sprintf("%s/-/org/%s/user", reg.baseURL, url.PathEscape(org))
+	var roles map[string]string
+	if err := reg.client.GetJSON(ctx, endpoint, &roles); err != nil {
+		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
+			return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: scope}
+		}
+		return nil, err
+	}
+
+	members := make([]core.Maintainer, 0, len(roles))
+	for login, role := range roles {
+		members = append(members, core.Maintainer{
+			Login: login,
+			Role:  role,
+			URL:   "https://www.npmjs.com/~" + login,
+		})
+	}
+	sort.Slice(members, func(i, j int) bool { return members[i].Login < members[j].Login })
+
+	ns := &core.Namespace{
+		Ecosystem: ecosystem,
+		Name:      scope,
+		Kind:      core.NamespaceOrg,
+		Members:   members,
+	}
+	if reg.baseURL == DefaultURL {
+		ns.URL = "https://www.npmjs.com/org/" + org
+	}
+	return ns, nil
+}
+
+// ListPackagesByNamespace returns the packages in an npm scope, from the
+// registry's org API, or its user API when the scope belongs to a user
+// rather than an organization. namespace is the scope, with or without the
+// "@". Private packages are only listed for a token that can read them.
+func (r *Registry) ListPackagesByNamespace(ctx context.Context, namespace string) ([]string, error) {
+	scope := normalizeScope(namespace)
+	reg := r.route(scope + "/")
+	owner := url.PathEscape(strings.TrimPrefix(scope, "@"))
+
+	for _, kind := range []string{"org", "user"} {
+		endpoint := fmt.Sprintf("%s/-/%s/%s/package", reg.baseURL, kind, owner)
+		var access map[string]string
+		if err := reg.client.GetJSON(ctx, endpoint, &access); err != nil {
+			if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
+				continue
+			}
+			return nil, err
+		}
+		names := make([]string, 0, len(access))
+		for name := range access {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return names, nil
+	}
+	return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: scope}
+}
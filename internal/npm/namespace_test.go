@@ -0,0 +1,72 @@
+package npm
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+func TestFetchNamespace(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/-/org/babel/user" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(`{"nicolo-ribaudo":"owner","jlhwung":"developer"}`))
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	ns, err := reg.FetchNamespace(context.Background(), "@babel")
+	if err != nil {
+		t.Fatalf("FetchNamespace failed: %v", err)
+	}
+	if ns.Name != "@babel" || ns.Kind != core.NamespaceOrg || ns.Verified {
+		t.Errorf("unexpected namespace %+v", ns)
+	}
+	if len(ns.Members) != 2 || ns.Members[0].Login != "jlhwung" || ns.Members[1].Role != "owner" {
+		t.Errorf("unexpected members %+v", ns.Members)
+	}
+
+	if _, err := reg.FetchNamespace(context.Background(), "missing"); !errors.Is(err, core.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestListPackagesByNamespace(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/-/org/babel/package":
+			_, _ = w.Write([]byte(`{"@babel/core":"write","@babel/cli":"write","@babel/types":"read"}`))
+		case "/-/user/sindresorhus/package":
+			_, _ = w.Write([]byte(`{"@sindresorhus/is":"write"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	ctx := context.Background()
+
+	names, err := reg.ListPackagesByNamespace(ctx, "@babel")
+	if err != nil {
+		t.Fatalf("ListPackagesByNamespace failed: %v", err)
+	}
+	if len(names) != 3 || names[0] != "@babel/cli" || names[2] != "@babel/types" {
+		t.Errorf("unexpected names %v", names)
+	}
+
+	names, err = reg.ListPackagesByNamespace(ctx, "sindresorhus")
+	if err != nil || len(names) != 1 || names[0] != "@sindresorhus/is" {
+		t.Errorf("user scope: names = %v, err = %v", names, err)
+	}
+
+	if _, err := reg.ListPackagesByNamespace(ctx, "missing"); !errors.Is(err, core.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
@@ -5,8 +5,8 @@ import (
 	"context"
 	"fmt"
 	"net/url"
+	"slices"
 	"strings"
-	"time"
 
 	"github.com/git-pkgs/registries/internal/core"
 )
@@ -26,6 +26,10 @@ type Registry struct {
 	baseURL string
 	client  *core.Client
 	urls    *URLs
+	scopes  map[string]*Registry // by "@scope", set by WithScopeRegistry
+	compat  bool                 // set by WithCompatibilityMode
+
+	inspectBundled bool // set by WithBundledInspection
 }
 
 func New(baseURL string, client *core.Client) *Registry {
@@ -49,40 +53,72 @@ func (r *Registry) URLs() core.URLBuilder {
 }
 
 type packageResponse struct {
-	ID          string                     `json:"_id"`
-	Name        string                     `json:"name"`
-	Description string                     `json:"description"`
-	Homepage    interface{}                `json:"homepage"`
-	Repository  interface{}                `json:"repository"`
-	Versions    map[string]versionInfo     `json:"versions"`
-	Time        map[string]string          `json:"time"`
-	Maintainers []maintainerInfo           `json:"maintainers"`
-	DistTags    map[string]string          `json:"dist-tags"`
+	ID             string                 `json:"_id"`
+	Name           string                 `json:"name"`
+	Description    string                 `json:"description"`
+	Homepage       interface{}            `json:"homepage"`
+	Repository     interface{}            `json:"repository"`
+	Versions       map[string]versionInfo `json:"versions"`
+	Time           timeMap                `json:"time"`
+	Maintainers    maintainerList         `json:"maintainers"`
+	DistTags       map[string]string      `json:"dist-tags"`
+	Readme         string                 `json:"readme"`
+	ReadmeFilename string                 `json:"readmeFilename"`
 }
 
 type versionInfo struct {
-	Name         string                 `json:"name"`
-	Version      string                 `json:"version"`
-	Description  string                 `json:"description"`
-	Keywords     interface{}            `json:"keywords"`
-	License      interface{}            `json:"license"`
-	Homepage     interface{}            `json:"homepage"`
-	Repository   interface{}            `json:"repository"`
-	Dependencies map[string]string      `json:"dependencies"`
-	DevDeps      map[string]string      `json:"devDependencies"`
-	OptionalDeps map[string]string      `json:"optionalDependencies"`
-	Deprecated   string                 `json:"deprecated"`
-	Dist         distInfo               `json:"dist"`
-	Maintainers  []maintainerInfo       `json:"maintainers"`
-	NpmUser      map[string]interface{} `json:"_npmUser"`
-	Engines      map[string]string      `json:"engines"`
-	Funding      interface{}            `json:"funding"`
+	Name             string                 `json:"name"`
+	Version          string                 `json:"version"`
+	Description      string                 `json:"description"`
+	Keywords         interface{}            `json:"keywords"`
+	License          interface{}            `json:"license"`
+	Homepage         interface{}            `json:"homepage"`
+	Repository       interface{}            `json:"repository"`
+	Dependencies     map[string]string      `json:"dependencies"`
+	DevDeps          map[string]string      `json:"devDependencies"`
+	OptionalDeps     map[string]string      `json:"optionalDependencies"`
+	BundleDeps       bundleList             `json:"bundleDependencies"`
+	BundledDeps      bundleList             `json:"bundledDependencies"`
+	Deprecated       string                 `json:"deprecated"`
+	Dist             distInfo               `json:"dist"`
+	Maintainers      maintainerList         `json:"maintainers"`
+	NpmUser          map[string]interface{} `json:"_npmUser"`
+	Engines          map[string]string      `json:"engines"`
+	Funding          interface{}            `json:"funding"`
+	Scripts          map[string]string      `json:"scripts"`
+	GypFile          bool                   `json:"gypfile"`
+	HasInstallScript bool                   `json:"hasInstallScript"`
+	Readme           string                 `json:"readme"`
+	ReadmeFilename   string                 `json:"readmeFilename"`
+}
+
+// installLifecycle are the scripts npm runs when a package is installed
+// as a dependency, in the order it runs them.
+var installLifecycle = []string{"preinstall", "install", "postinstall"}
+
+// installScripts returns the lifecycle scripts that run when the version is
+// installed. A package with a binding.gyp and no install script gets an
+// implicit "node-gyp rebuild" install script, which the registry records
+// as gypfile or, in abbreviated metadata, only as hasInstallScript.
+func (v versionInfo) installScripts() []string {
+	var scripts []string
+	for _, name := range installLifecycle {
+		if v.Scripts[name] != "" {
+			scripts = append(scripts, name)
+		}
+	}
+	if len(scripts) == 0 && (v.GypFile || v.HasInstallScript) {
+		scripts = []string{"install"}
+	}
+	return scripts
 }
 
 type distInfo struct {
-	Shasum    string `json:"shasum"`
-	Tarball   string `json:"tarball"`
-	Integrity string `json:"integrity"`
+	Shasum       string `json:"shasum"`
+	Tarball      string `json:"tarball"`
+	Integrity    string `json:"integrity"`
+	UnpackedSize int64  `json:"unpackedSize,omitempty"`
+	FileCount    int    `json:"fileCount,omitempty"`
 }
 
 type maintainerInfo struct {
@@ -91,18 +127,16 @@ type maintainerInfo struct {
 }
 
 func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package, error) {
-	escapedName := url.PathEscape(name)
-	url := fmt.Sprintf("%s/%s", r.baseURL, escapedName)
-
-	var resp packageResponse
-	if err := r.client.GetJSON(ctx, url, &resp); err != nil {
-		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
-			return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name}
-		}
+	resp, err := r.fetchPackument(ctx, name)
+	if err != nil {
 		return nil, err
 	}
+	return packageFrom(resp, name, resp.DistTags["latest"]), nil
+}
 
-	latestVersion := resp.DistTags["latest"]
+// packageFrom builds a package from its packument, describing it with the
+// manifest of latestVersion.
+func packageFrom(resp *packageResponse, name, latestVersion string) *core.Package {
 	var latest versionInfo
 	if latestVersion != "" {
 		latest = resp.Versions[latestVersion]
@@ -114,41 +148,54 @@ func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package
 	}
 
 	pkg := &core.Package{
-		Name:          resp.ID,
+		Name:          packageName(resp, name),
 		Description:   coalesceString(latest.Description, resp.Description),
 		Homepage:      extractString(resp.Homepage),
 		Repository:    core.ExtractRepoURLWithFallback(latest.Repository, resp.Repository),
 		Licenses:      core.ExtractLicense(latest.License),
 		Keywords:      extractKeywords(latest.Keywords),
-		Namespace:     extractNamespace(resp.ID),
+		Namespace:     extractNamespace(packageName(resp, name)),
 		LatestVersion: latestVersion,
 		Metadata: map[string]any{
 			"dist-tags": resp.DistTags,
 			"funding":   latest.Funding,
 		},
+		CreatedAt: parseTime(resp.Time["created"]),
+		UpdatedAt: parseTime(resp.Time["modified"]),
+	}
+	// The time map keeps entries for unpublished versions, so only those
+	// still listed count
+	numbers := make([]string, 0, len(resp.Versions))
+	for num := range resp.Versions {
+		if t := parseTime(resp.Time[num]); t.After(pkg.LatestReleasedAt) {
+			pkg.LatestReleasedAt = t
+		}
+		numbers = append(numbers, num)
+	}
+	// "latest" is usually stable, but nothing stops a pre-release being
+	// tagged with it
+	pkg.LatestStableVersion = latestVersion
+	if latestVersion == "" || core.IsPrerelease(ecosystem, latestVersion) {
+		pkg.LatestStableVersion = core.LatestStableOf(ecosystem, numbers)
 	}
 
-	return pkg, nil
+	return pkg
 }
 
 func (r *Registry) FetchVersions(ctx context.Context, name string) ([]core.Version, error) {
-	escapedName := url.PathEscape(name)
-	url := fmt.Sprintf("%s/%s", r.baseURL, escapedName)
-
-	var resp packageResponse
-	if err := r.client.GetJSON(ctx, url, &resp); err != nil {
-		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
-			return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name}
-		}
+	r = r.route(name)
+	resp, err := r.fetchPackument(ctx, name)
+	if err != nil {
 		return nil, err
 	}
+	return r.versionsFrom(ctx, resp), nil
+}
 
+// versionsFrom lists the versions in a packument.
+func (r *Registry) versionsFrom(ctx context.Context, resp *packageResponse) []core.Version {
 	versions := make([]core.Version, 0, len(resp.Versions))
 	for num, v := range resp.Versions {
-		var publishedAt time.Time
-		if timeStr, ok := resp.Time[num]; ok {
-			publishedAt, _ = time.Parse(time.RFC3339, timeStr)
-		}
+		publishedAt := parseTime(resp.Time[num])
 
 		var status core.VersionStatus
 		if v.Deprecated != "" {
@@ -157,7 +204,19 @@ func (r *Registry) FetchVersions(ctx context.Context, name string) ([]core.Versi
 
 		integrity := v.Dist.Integrity
 		if integrity == "" && v.Dist.Shasum != "" {
-			integrity = "sha1-" + v.Dist.Shasum
+			integrity = core.HexIntegrity("sha1", v.Dist.Shasum)
+		}
+
+		metadata := map[string]any{
+			"deprecated": v.Deprecated,
+			"dist":       v.Dist,
+			"engines":    v.Engines,
+			"_npmUser":   v.NpmUser,
+			"tarball":    v.Dist.Tarball,
+		}
+		core.SetInstallSignals(metadata, v.installScripts(), v.GypFile)
+		if bundled := v.bundled(); len(bundled) > 0 {
+			metadata["bundled_dependencies"] = bundled
 		}
 
 		versions = append(versions, core.Version{
@@ -166,22 +225,39 @@ func (r *Registry) FetchVersions(ctx context.Context, name string) ([]core.Versi
 			Licenses:    core.ExtractLicense(v.License),
 			Integrity:   integrity,
 			Status:      status,
-			Metadata: map[string]any{
-				"deprecated":   v.Deprecated,
-				"dist":         v.Dist,
-				"engines":      v.Engines,
-				"_npmUser":     v.NpmUser,
-				"tarball":      v.Dist.Tarball,
-			},
+			Publisher:   npmUser(v.NpmUser),
+			Maintainers: versionMaintainers(v.Maintainers),
+			Metadata:    metadata,
+			// The packument doesn't record the tarball's own size
+			UnpackedSize: v.Dist.UnpackedSize,
+			FileCount:    v.Dist.FileCount,
 		})
 	}
 
-	return versions, nil
+	if r.compat {
+		r.fillPublishTimes(ctx, versions)
+	}
+
+	return versions
 }
 
 func (r *Registry) FetchDependencies(ctx context.Context, name, version string) ([]core.Dependency, error) {
-	escapedName := url.PathEscape(name)
-	url := fmt.Sprintf("%s/%s", r.baseURL, escapedName)
+	resp, err := r.fetchPackument(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	v, ok := resp.Versions[version]
+	if !ok {
+		return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name, Version: version}
+	}
+	return r.versionDependencies(ctx, name, v)
+}
+
+// fetchPackument fetches the document listing every version of a package.
+func (r *Registry) fetchPackument(ctx context.Context, name string) (*packageResponse, error) {
+	r = r.route(name)
+	url := fmt.Sprintf("%s/%s", r.baseURL, url.PathEscape(name))
 
 	var resp packageResponse
 	if err := r.client.GetJSON(ctx, url, &resp); err != nil {
@@ -190,20 +266,26 @@ func (r *Registry) FetchDependencies(ctx context.Context, name, version string)
 		}
 		return nil, err
 	}
+	return &resp, nil
+}
 
-	v, ok := resp.Versions[version]
-	if !ok {
-		return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name, Version: version}
-	}
-
+// dependencies returns the version's runtime, development and optional
+// dependencies. Runtime dependencies shipped in the tarball have
+// Metadata["bundled"] set.
+func (v versionInfo) dependencies() []core.Dependency {
 	var deps []core.Dependency
 
+	bundled := v.bundled()
 	for depName, req := range v.Dependencies {
-		deps = append(deps, core.Dependency{
+		dep := core.Dependency{
 			Name:         depName,
 			Requirements: req,
 			Scope:        core.Runtime,
-		})
+		}
+		if slices.Contains(bundled, depName) {
+			dep.Metadata = map[string]any{"bundled": true}
+		}
+		deps = append(deps, dep)
 	}
 
 	for depName, req := range v.DevDeps {
@@ -223,10 +305,11 @@ func (r *Registry) FetchDependencies(ctx context.Context, name, version string)
 		})
 	}
 
-	return deps, nil
+	return deps
 }
 
 func (r *Registry) FetchMaintainers(ctx context.Context, name string) ([]core.Maintainer, error) {
+	r = r.route(name)
 	escapedName := url.PathEscape(name)
 	url := fmt.Sprintf("%s/%s", r.baseURL, escapedName)
 
@@ -278,6 +361,27 @@ func extractKeywords(v interface{}) []string {
 	return nil
 }
 
+// npmUser converts the _npmUser field, the account that ran npm publish.
+func npmUser(user map[string]interface{}) *core.Maintainer {
+	name, _ := user["name"].(string)
+	email, _ := user["email"].(string)
+	if name == "" && email == "" {
+		return nil
+	}
+	return &core.Maintainer{Login: name, Email: email}
+}
+
+func versionMaintainers(maintainers maintainerList) []core.Maintainer {
+	if len(maintainers) == 0 {
+		return nil
+	}
+	result := make([]core.Maintainer, len(maintainers))
+	for i, m := range maintainers {
+		result[i] = core.Maintainer{Login: m.Name, Email: m.Email}
+	}
+	return result
+}
+
 func extractNamespace(id string) string {
 	if strings.HasPrefix(id, "@") && strings.Contains(id, "/") {
 		parts := strings.SplitN(id, "/", 2)
@@ -297,6 +401,7 @@ func coalesceString(values ...string) string {
 
 type URLs struct {
 	baseURL string
+	scopes  map[string]*URLs
 }
 
 func (u *URLs) Registry(name, version string) string {
@@ -310,6 +415,9 @@ func (u *URLs) Download(name, version string) string {
 	if version == "" {
 		return ""
 	}
+	if scoped, ok := u.scopes[scopeOf(name)]; ok {
+		return scoped.Download(name, version)
+	}
 	shortName := name
 	if strings.Contains(name, "/") {
 		parts := strings.SplitN(name, "/", 2)
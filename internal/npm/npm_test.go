@@ -3,9 +3,12 @@ package npm
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/git-pkgs/registries/internal/core"
 )
@@ -35,7 +38,11 @@ func TestFetchPackage(t *testing.T) {
 				},
 			},
 			"time": map[string]string{
-				"18.3.1": "2024-04-26T16:09:06.245Z",
+				"created":  "2011-10-26T17:46:21.942Z",
+				"modified": "2024-12-05T18:10:31.371Z",
+				"18.3.1":   "2024-04-26T16:09:06.245Z",
+				// Unpublished, so not in versions
+				"19.0.0-rc": "2024-06-01T00:00:00.000Z",
 			},
 			"maintainers": []map[string]string{
 				{"name": "react-bot", "email": "react-core@meta.com"},
@@ -61,6 +68,15 @@ func TestFetchPackage(t *testing.T) {
 	if pkg.Repository != "https://github.com/facebook/react" {
 		t.Errorf("unexpected repository: %q", pkg.Repository)
 	}
+	if pkg.CreatedAt.Year() != 2011 || pkg.UpdatedAt.Year() != 2024 {
+		t.Errorf("unexpected created/modified times: %v, %v", pkg.CreatedAt, pkg.UpdatedAt)
+	}
+	if pkg.LatestReleasedAt.Format("2006-01-02") != "2024-04-26" {
+		t.Errorf("LatestReleasedAt = %v, want the 18.3.1 publish time", pkg.LatestReleasedAt)
+	}
+	if pkg.LatestVersion != "18.3.1" || pkg.LatestStableVersion != "18.3.1" {
+		t.Errorf("latest = %q, stable = %q", pkg.LatestVersion, pkg.LatestStableVersion)
+	}
 }
 
 func TestFetchPackageScoped(t *testing.T) {
@@ -244,3 +260,454 @@ func TestExtractNamespace(t *testing.T) {
 		})
 	}
 }
+
+func TestFetchReadme(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resp := map[string]interface{}{
+			"_id":            "left-pad",
+			"name":           "left-pad",
+			"dist-tags":      map[string]string{"latest": "1.3.0"},
+			"readme":         "# left-pad\n\nString left pad",
+			"readmeFilename": "README.md",
+			"versions": map[string]interface{}{
+				"1.3.0": map[string]interface{}{"name": "left-pad", "version": "1.3.0"},
+				"1.0.0": map[string]interface{}{"name": "left-pad", "version": "1.0.0"},
+				"0.0.1": map[string]interface{}{
+					"name": "left-pad", "version": "0.0.1",
+					"readme": "left-pad\n========", "readmeFilename": "README.rst",
+				},
+			},
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	ctx := context.Background()
+
+	doc, err := reg.FetchReadme(ctx, "left-pad", "")
+	if err != nil {
+		t.Fatalf("FetchReadme failed: %v", err)
+	}
+	if doc.ContentType != core.Markdown || doc.Filename != "README.md" || doc.Content != "# left-pad\n\nString left pad" {
+		t.Errorf("unexpected document: %+v", doc)
+	}
+
+	doc, err = reg.FetchReadme(ctx, "left-pad", "0.0.1")
+	if err != nil {
+		t.Fatalf("FetchReadme failed: %v", err)
+	}
+	if doc.ContentType != core.ReStructuredText {
+		t.Errorf("expected rst, got %q", doc.ContentType)
+	}
+
+	// The top-level README belongs to 1.3.0, not 1.0.0
+	if _, err := reg.FetchReadme(ctx, "left-pad", "1.0.0"); !errors.Is(err, core.ErrNotFound) {
+		t.Errorf("expected not found for 1.0.0, got %v", err)
+	}
+}
+
+func TestFetchDistTags(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resp := map[string]interface{}{
+			"name":      "react",
+			"dist-tags": map[string]string{"latest": "18.3.1", "next": "19.0.0-rc.1", "beta": "19.0.0-beta-26f2496093-20240514"},
+			"time": map[string]string{
+				"created":     "2011-10-26T17:46:21.942Z",
+				"18.3.1":      "2024-04-26T16:42:56.242Z",
+				"19.0.0-rc.1": "2024-11-14T19:34:27.391Z",
+			},
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	tags, err := reg.FetchDistTags(context.Background(), "react")
+	if err != nil {
+		t.Fatalf("FetchDistTags failed: %v", err)
+	}
+
+	if len(tags) != 3 {
+		t.Fatalf("expected 3 tags, got %d", len(tags))
+	}
+	if tags[0].Name != "latest" || tags[1].Name != "beta" || tags[2].Name != "next" {
+		t.Errorf("unexpected order: %+v", tags)
+	}
+	if tags[2].Version != "19.0.0-rc.1" || tags[2].PublishedAt.Year() != 2024 || tags[2].PublishedAt.Month() != 11 {
+		t.Errorf("unexpected next tag: %+v", tags[2])
+	}
+	if !tags[1].PublishedAt.IsZero() {
+		t.Errorf("expected no time for beta, got %v", tags[1].PublishedAt)
+	}
+}
+
+func TestWithScopeRegistry(t *testing.T) {
+	public := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "" {
+			t.Errorf("credentials sent to the public registry")
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"_id": "lodash", "dist-tags": map[string]string{"latest": "4.17.21"}})
+	}))
+	defer public.Close()
+
+	var gotAuth, gotPath string
+	private := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth, gotPath = r.Header.Get("Authorization"), r.URL.EscapedPath()
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"_id": "@myorg/widget", "dist-tags": map[string]string{"latest": "1.0.0"}})
+	}))
+	defer private.Close()
+
+	reg := New(public.URL, core.DefaultClient()).WithScopeRegistry("@MyOrg", private.URL+"/npm/", func(url string) (string, string) {
+		return "Authorization", "Bearer s3cret"
+	})
+	ctx := context.Background()
+
+	pkg, err := reg.FetchPackage(ctx, "@myorg/widget")
+	if err != nil {
+		t.Fatalf("FetchPackage failed: %v", err)
+	}
+	if pkg.LatestVersion != "1.0.0" || gotPath != "/npm/@myorg%2Fwidget" || gotAuth != "Bearer s3cret" {
+		t.Errorf("scoped package not fetched from its registry: %s %q %q", pkg.LatestVersion, gotPath, gotAuth)
+	}
+
+	pkg, err = reg.FetchPackage(ctx, "lodash")
+	if err != nil {
+		t.Fatalf("FetchPackage failed: %v", err)
+	}
+	if pkg.LatestVersion != "4.17.21" {
+		t.Errorf("unscoped package not fetched from the public registry: %s", pkg.LatestVersion)
+	}
+
+	if got := reg.URLs().Download("@myorg/widget", "1.0.0"); got != private.URL+"/npm/@myorg/widget/-/widget-1.0.0.tgz" {
+		t.Errorf("unexpected scoped download URL %q", got)
+	}
+	if got := reg.URLs().Download("@other/widget", "1.0.0"); got != public.URL+"/@other/widget/-/widget-1.0.0.tgz" {
+		t.Errorf("unexpected download URL %q", got)
+	}
+}
+
+func TestFetchStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/request":
+			_, _ = w.Write([]byte(`{
+				"name": "request",
+				"dist-tags": {"latest": "2.88.2"},
+				"repository": {"type": "git", "url": "git+https://github.com/request/request.git"},
+				"versions": {
+					"2.88.2": {"version": "2.88.2", "deprecated": "request has been deprecated, see https://github.com/request/request/issues/3142"},
+					"2.88.0": {"version": "2.88.0", "deprecated": "request has been deprecated"}
+				},
+				"time": {"created": "2011-01-23T00:00:00.000Z", "2.88.2": "2020-02-11T00:00:00.000Z"}
+			}`))
+		case "/gone":
+			_, _ = w.Write([]byte(`{
+				"name": "gone",
+				"time": {
+					"created": "2015-01-01T00:00:00.000Z",
+					"unpublished": {"time": "2016-03-23T00:00:00.000Z", "versions": ["0.0.1", "0.0.2"]}
+				}
+			}`))
+		case "/mixed":
+			_, _ = w.Write([]byte(`{
+				"name": "mixed",
+				"dist-tags": {"latest": "2.0.0"},
+				"versions": {
+					"2.0.0": {"version": "2.0.0", "deprecated": "broken"},
+					"1.1.0": {"version": "1.1.0"},
+					"1.0.0": {"version": "1.0.0"}
+				},
+				"time": {"2.0.0": "2024-03-01T00:00:00Z", "1.1.0": "2024-02-01T00:00:00Z", "1.0.0": "2024-01-01T00:00:00Z"}
+			}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	ctx := context.Background()
+
+	status, err := reg.FetchStatus(ctx, "request")
+	if err != nil {
+		t.Fatalf("FetchStatus failed: %v", err)
+	}
+	if status.State != core.PackageDeprecated || status.Repository != "https://github.com/request/request" {
+		t.Errorf("unexpected status: %+v", status)
+	}
+
+	status, err = reg.FetchStatus(ctx, "gone")
+	if err != nil {
+		t.Fatalf("FetchStatus failed: %v", err)
+	}
+	if status.State != core.PackageRemoved {
+		t.Errorf("expected removed, got %+v", status)
+	}
+	if versions, _ := status.Metadata["unpublished_versions"].([]string); len(versions) != 2 {
+		t.Errorf("unexpected unpublished versions: %v", status.Metadata["unpublished_versions"])
+	}
+
+	status, err = reg.FetchStatus(ctx, "mixed")
+	if err != nil {
+		t.Fatalf("FetchStatus failed: %v", err)
+	}
+	if status.State != core.PackageActive || status.LatestVersion != "1.1.0" {
+		t.Errorf("expected active at 1.1.0, got %+v", status)
+	}
+
+	if _, err := reg.FetchStatus(ctx, "missing"); !errors.Is(err, core.ErrNotFound) {
+		t.Errorf("expected not found, got %v", err)
+	}
+}
+
+func TestFetchAdvisories(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/-/npm/v1/security/advisories/bulk" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		var payload map[string][]string
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Fatalf("decoding payload: %v", err)
+		}
+		if len(payload["lodash"]) != 2 || payload["left-pad"][0] != "1.3.0" {
+			t.Errorf("unexpected payload %v", payload)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"lodash":[{"id":1096305,"url":"https://github.com/advisories/GHSA-p6mc-m468-83gw","title":"Prototype Pollution in lodash","severity":"high","vulnerable_versions":"<4.17.19","cwe":["CWE-770","CWE-1321"],"cvss":{"score":7.4,"vectorString":"CVSS:3.1/AV:N/AC:H/PR:N/UI:N/S:U/C:N/I:H/A:H"}}]}`))
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	advisories, err := reg.FetchAdvisories(context.Background(), map[string][]string{
+		"lodash":   {"4.17.15", "4.17.21"},
+		"left-pad": {"1.3.0"},
+	})
+	if err != nil {
+		t.Fatalf("FetchAdvisories failed: %v", err)
+	}
+
+	if len(advisories) != 1 {
+		t.Fatalf("expected 1 advisory, got %d", len(advisories))
+	}
+	a := advisories[0]
+	if a.Name != "lodash" || a.ID != "1096305" || a.GHSA != "GHSA-p6mc-m468-83gw" {
+		t.Errorf("unexpected advisory %+v", a)
+	}
+	if a.Severity != "high" || a.VulnerableVersions != "<4.17.19" || a.CVSSScore != 7.4 || len(a.CWEs) != 2 {
+		t.Errorf("unexpected advisory details %+v", a)
+	}
+}
+
+func TestFetchAdvisoriesQuickAuditFallback(t *testing.T) {
+	var quickRequests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/-/npm/v1/security/audits/quick":
+			quickRequests++
+			var req quickAuditRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				t.Fatalf("decoding payload: %v", err)
+			}
+			if req.Requires["minimist"] == "" || req.Dependencies["minimist"].Version != req.Requires["minimist"] {
+				t.Errorf("unexpected payload %+v", req)
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"advisories":{"1179":{"id":1179,"module_name":"minimist","title":"Prototype Pollution","severity":"moderate","vulnerable_versions":"<0.2.1 || >=1.0.0 <1.2.3","github_advisory_id":"GHSA-vh95-rmgr-6w4m","cwe":"CWE-471","url":"https://npmjs.com/advisories/1179","cvss":{"score":5.6}}}}`))
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	advisories, err := reg.FetchAdvisories(context.Background(), map[string][]string{
+		"minimist": {"1.2.0", "0.0.8"},
+	})
+	if err != nil {
+		t.Fatalf("FetchAdvisories failed: %v", err)
+	}
+
+	if quickRequests != 2 {
+		t.Errorf("expected one quick audit per version, got %d", quickRequests)
+	}
+	if len(advisories) != 1 {
+		t.Fatalf("expected duplicate advisories to be merged, got %d", len(advisories))
+	}
+	a := advisories[0]
+	if a.Name != "minimist" || a.GHSA != "GHSA-vh95-rmgr-6w4m" || len(a.CWEs) != 1 || a.CWEs[0] != "CWE-471" {
+		t.Errorf("unexpected advisory %+v", a)
+	}
+}
+
+// verdaccioPackument is a Verdaccio 5 response for a package whose oldest
+// version was proxied from an uplink before Verdaccio recorded times, with
+// maintainers in npm's string shorthand.
+const verdaccioPackument = `{
+  "name": "@acme/logger",
+  "versions": {
+    "1.0.0": {
+      "name": "@acme/logger",
+      "version": "1.0.0",
+      "maintainers": ["Jane Doe <jane@acme.test> (https://acme.test)"],
+      "dist": {"shasum": "0c8c0d1b0c5e7e2c4a5c3b9d6f7e8a9b0c1d2e3f", "tarball": "{base}/@acme/logger/-/logger-1.0.0.tgz"},
+      "_id": "@acme/logger@1.0.0"
+    },
+    "1.1.0": {
+      "name": "@acme/logger",
+      "version": "1.1.0",
+      "maintainers": ["Jane Doe <jane@acme.test>"],
+      "dist": {"integrity": "sha512-abc", "tarball": "{base}/@acme/logger/-/logger-1.1.0.tgz"},
+      "_id": "@acme/logger@1.1.0"
+    }
+  },
+  "time": {"modified": "2024-03-02T10:00:00.000Z", "created": "2023-01-05T09:00:00.000Z", "1.1.0": "2024-03-02T10:00:00.000Z"},
+  "users": {},
+  "dist-tags": {"latest": "1.1.0"},
+  "_uplinks": {},
+  "_distfiles": {},
+  "_attachments": {},
+  "_rev": "4-5a2f1c3e8d9b7a60",
+  "_id": "@acme/logger",
+  "readme": "ERROR: No README data found!",
+  "maintainers": "Jane Doe <jane@acme.test>"
+}`
+
+// nexusPackument is a Nexus 3 npm group repository response: no time map,
+// an escaped _id, and times elsewhere without a zone.
+const nexusPackument = `{
+  "_id": "@acme%2fclient",
+  "_rev": "2-29f6b8e1",
+  "name": "@acme/client",
+  "dist-tags": {"latest": "2.0.0"},
+  "versions": {
+    "2.0.0": {
+      "name": "@acme/client",
+      "version": "2.0.0",
+      "maintainers": {"name": "ci", "email": "ci@acme.test"},
+      "dist": {"shasum": "1f2e3d4c", "tarball": "{base}/repository/npm-group/@acme/client/-/client-2.0.0.tgz"}
+    }
+  },
+  "time": null
+}`
+
+func TestPrivateRegistryPackuments(t *testing.T) {
+	var heads int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodHead:
+			heads++
+			w.Header().Set("Last-Modified", "Thu, 05 Jan 2023 09:00:00 GMT")
+		case strings.Contains(r.URL.Path, "client"):
+			_, _ = w.Write([]byte(strings.ReplaceAll(nexusPackument, "{base}", "http://"+r.Host)))
+		default:
+			_, _ = w.Write([]byte(strings.ReplaceAll(verdaccioPackument, "{base}", "http://"+r.Host)))
+		}
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	ctx := context.Background()
+
+	pkg, err := reg.FetchPackage(ctx, "@acme/client")
+	if err != nil {
+		t.Fatalf("FetchPackage failed: %v", err)
+	}
+	if pkg.Name != "@acme/client" || pkg.Namespace != "acme" {
+		t.Errorf("unexpected name %q namespace %q", pkg.Name, pkg.Namespace)
+	}
+
+	maintainers, err := reg.FetchMaintainers(ctx, "@acme/logger")
+	if err != nil {
+		t.Fatalf("FetchMaintainers failed: %v", err)
+	}
+	if len(maintainers) != 1 || maintainers[0].Login != "Jane Doe" || maintainers[0].Email != "jane@acme.test" {
+		t.Errorf("unexpected maintainers %+v", maintainers)
+	}
+
+	versions, err := reg.FetchVersions(ctx, "@acme/logger")
+	if err != nil {
+		t.Fatalf("FetchVersions failed: %v", err)
+	}
+	published := make(map[string]time.Time)
+	for _, v := range versions {
+		published[v.Number] = v.PublishedAt
+		// Versions with only a hex shasum get it as SRI, like dist.integrity.
+		if v.Number == "1.0.0" && v.Integrity != "sha1-DIwNGwxefixKXDudb36KmwwdLj8=" {
+			t.Errorf("1.0.0 integrity = %q", v.Integrity)
+		}
+	}
+	if published["1.1.0"].IsZero() || !published["1.0.0"].IsZero() || heads != 0 {
+		t.Errorf("unexpected times without compatibility mode: %v (%d HEADs)", published, heads)
+	}
+
+	compat := reg.WithCompatibilityMode()
+	versions, err = compat.FetchVersions(ctx, "@acme/logger")
+	if err != nil {
+		t.Fatalf("FetchVersions failed: %v", err)
+	}
+	for _, v := range versions {
+		published[v.Number] = v.PublishedAt
+	}
+	if !published["1.0.0"].Equal(time.Date(2023, 1, 5, 9, 0, 0, 0, time.UTC)) || heads != 1 {
+		t.Errorf("expected 1.0.0 time from Last-Modified, got %v (%d HEADs)", published["1.0.0"], heads)
+	}
+
+	versions, err = compat.FetchVersions(ctx, "@acme/client")
+	if err != nil {
+		t.Fatalf("FetchVersions failed: %v", err)
+	}
+	if len(versions) != 1 || versions[0].PublishedAt.IsZero() || len(versions[0].Maintainers) != 1 {
+		t.Errorf("unexpected Nexus versions %+v", versions)
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	want := time.Date(2024, 3, 2, 10, 0, 0, 0, time.UTC)
+	for _, s := range []string{
+		"2024-03-02T10:00:00.000Z",
+		"2024-03-02T10:00:00Z",
+		"2024-03-02T10:00:00.000+0000",
+		"2024-03-02T10:00:00",
+		"2024-03-02 10:00:00",
+	} {
+		if got := parseTime(s); !got.Equal(want) {
+			t.Errorf("parseTime(%q) = %v", s, got)
+		}
+	}
+	if !parseTime("yesterday").IsZero() {
+		t.Error("expected zero time for an unrecognised timestamp")
+	}
+}
+
+func TestFetchVersionsSizes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"_id": "left-pad", "dist-tags": {"latest": "1.3.0"}, "versions": {
+			"1.3.0": {"dist": {"tarball": "https://registry.npmjs.org/left-pad/-/left-pad-1.3.0.tgz", "unpackedSize": 11291, "fileCount": 8}},
+			"1.0.0": {"dist": {"tarball": "https://registry.npmjs.org/left-pad/-/left-pad-1.0.0.tgz"}}
+		}}`))
+	}))
+	defer server.Close()
+
+	versions, err := New(server.URL, core.DefaultClient()).FetchVersions(context.Background(), "left-pad")
+	if err != nil {
+		t.Fatalf("FetchVersions failed: %v", err)
+	}
+	for _, v := range versions {
+		switch v.Number {
+		case "1.3.0":
+			if v.UnpackedSize != 11291 || v.FileCount != 8 || v.Size != 0 {
+				t.Errorf("unexpected sizes for 1.3.0: %+v", v)
+			}
+		case "1.0.0":
+			if v.UnpackedSize != 0 || v.FileCount != 0 {
+				t.Errorf("expected no sizes for 1.0.0, got %+v", v)
+			}
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package npm
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+// noReadme is what the registry stores when a package was published without one.
+const noReadme = "ERROR: No README data found!"
+
+// FetchReadme returns the README stored in the packument. The registry only
+// keeps the README of the latest publish at the top level; older versions
+// have their own copy only if they were published with one.
+func (r *Registry) FetchReadme(ctx context.Context, name, version string) (*core.Document, error) {
+	r = r.route(name)
+	escapedName := url.PathEscape(name)
+	url := fmt.Sprintf("%s/%s", r.baseURL, escapedName)
+
+	var resp packageResponse
+	if err := r.client.GetJSON(ctx, url, &resp); err != nil {
+		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
+			return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name}
+		}
+		return nil, err
+	}
+
+	readme, filename := resp.Readme, resp.ReadmeFilename
+	if version != "" {
+		v, ok := resp.Versions[version]
+		if !ok {
+			return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name, Version: version}
+		}
+		if v.Readme != "" && v.Readme != noReadme {
+			readme, filename = v.Readme, v.ReadmeFilename
+		} else if version != resp.DistTags["latest"] {
+			// The top-level README belongs to a different version
+			readme = ""
+		}
+	}
+
+	if readme == "" || readme == noReadme {
+		return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name, Version: version}
+	}
+
+	return &core.Document{
+		Content:     readme,
+		ContentType: core.ContentTypeFromFilename(filename),
+		Filename:    filename,
+		URL:         url,
+	}, nil
+}
@@ -0,0 +1,72 @@
+package npm
+
+import (
+	"strings"
+)
+
+// WithScopeRegistry returns a new Registry that fetches packages in scope
+// from baseURL, as an .npmrc line such as "@myorg:registry=..." does, while
+// everything else still comes from the original registry. auth supplies the
+// header sent to the scope's registry, like Client.AuthFunc, and is only
+// asked about URLs under baseURL; when it is nil the client's own AuthFunc
+// is used. Advisories are always fetched from the original registry.
+func (r *Registry) WithScopeRegistry(scope, baseURL string, auth func(url string) (headerName, headerValue string)) *Registry {
+	scope = normalizeScope(scope)
+	baseURL = strings.TrimSuffix(baseURL, "/")
+
+	client := r.client
+	if auth != nil && client != nil {
+		fallback := client.AuthFunc
+		client = client.WithAuthFunc(func(url string) (string, string) {
+			if url == baseURL || strings.HasPrefix(url, baseURL+"/") {
+				return auth(url)
+			}
+			if fallback != nil {
+				return fallback(url)
+			}
+			return "", ""
+		})
+	}
+	scoped := New(baseURL, client)
+
+	copy := *r
+	copy.scopes = make(map[string]*Registry, len(r.scopes)+1)
+	urls := &URLs{baseURL: r.baseURL, scopes: make(map[string]*URLs, len(r.scopes)+1)}
+	for s, reg := range r.scopes {
+		copy.scopes[s] = reg
+		urls.scopes[s] = reg.urls
+	}
+	copy.scopes[scope] = scoped
+	urls.scopes[scope] = scoped.urls
+	copy.urls = urls
+	return &copy
+}
+
+// route returns the registry serving name: the one configured for its scope
+// with WithScopeRegistry, or r.
+func (r *Registry) route(name string) *Registry {
+	if scoped, ok := r.scopes[scopeOf(name)]; ok {
+		return scoped
+	}
+	return r
+}
+
+// scopeOf returns the "@scope" of a scoped package name, or "".
+func scopeOf(name string) string {
+	if !strings.HasPrefix(name, "@") {
+		return ""
+	}
+	scope, _, ok := strings.Cut(name, "/")
+	if !ok {
+		return ""
+	}
+	return strings.ToLower(scope)
+}
+
+func normalizeScope(scope string) string {
+	scope = strings.ToLower(strings.TrimSuffix(scope, "/"))
+	if !strings.HasPrefix(scope, "@") {
+		scope = "@" + scope
+	}
+	return scope
+}
@@ -0,0 +1,90 @@
+package npm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+// statusResponse is the part of a packument needed for package status. Time
+// is raw because unpublished packages carry an object under "unpublished".
+type statusResponse struct {
+	DistTags   map[string]string          `json:"dist-tags"`
+	Versions   map[string]versionInfo     `json:"versions"`
+	Time       map[string]json.RawMessage `json:"time"`
+	Repository interface{}                `json:"repository"`
+}
+
+type unpublishedInfo struct {
+	Time     time.Time `json:"time"`
+	Versions []string  `json:"versions"`
+}
+
+// FetchStatus reports unpublished packages, which have no versions left but
+// keep a stub packument, in addition to fully deprecated ones.
+func (r *Registry) FetchStatus(ctx context.Context, name string) (*core.PackageStatus, error) {
+	r = r.route(name)
+	escapedName := url.PathEscape(name)
+	url := fmt.Sprintf("%s/%s", r.baseURL, escapedName)
+
+	var resp statusResponse
+	if err := r.client.GetJSON(ctx, url, &resp); err != nil {
+		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
+			return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name}
+		}
+		return nil, err
+	}
+
+	if raw, ok := resp.Time["unpublished"]; ok {
+		var info unpublishedInfo
+		_ = json.Unmarshal(raw, &info)
+		return &core.PackageStatus{
+			State:   core.PackageRemoved,
+			Message: "unpublished",
+			Metadata: map[string]any{
+				"unpublished_at":       info.Time,
+				"unpublished_versions": info.Versions,
+			},
+		}, nil
+	}
+
+	latestTag := resp.DistTags["latest"]
+	latest := resp.Versions[latestTag]
+	status := &core.PackageStatus{
+		Repository: core.ExtractRepoURLWithFallback(latest.Repository, resp.Repository),
+	}
+
+	if len(resp.Versions) == 0 {
+		status.State = core.PackageRemoved
+		return status, nil
+	}
+
+	var newest string
+	var newestAt time.Time
+	for num, v := range resp.Versions {
+		if v.Deprecated != "" {
+			continue
+		}
+		publishedAt := rawTime(resp.Time[num])
+		if newest == "" || publishedAt.After(newestAt) {
+			newest, newestAt = num, publishedAt
+		}
+	}
+
+	if newest == "" {
+		status.State = core.PackageDeprecated
+		status.Message = latest.Deprecated
+		return status, nil
+	}
+
+	status.State = core.PackageActive
+	status.LatestVersion = newest
+	if latest.Deprecated == "" {
+		status.LatestVersion = latestTag
+	}
+	return status, nil
+}
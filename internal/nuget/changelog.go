@@ -0,0 +1,51 @@
+package nuget
+
+import (
+	"context"
+	"strings"
+
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+// FetchChangelog returns the <releaseNotes> of a version's nuspec, which
+// NuGet serves as plain text in the catalog entry. Versions published
+// without release notes fall back to the releases and changelog of the
+// repository the project URL points to. If version is empty, the latest
+// listed version is used.
+func (r *Registry) FetchChangelog(ctx context.Context, name, version string) (*core.Document, error) {
+	lowerName := strings.ToLower(name)
+	url, err := r.registrationURL(ctx, lowerName)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp registrationResponse
+	if err := r.client.GetJSON(ctx, url, &resp); err != nil {
+		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
+			return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name, Version: version}
+		}
+		return nil, err
+	}
+
+	var entry *catalogEntry
+	for _, page := range resp.Items {
+		for _, leaf := range page.Items {
+			e := leaf.CatalogEntry
+			if version == "" && (entry == nil || e.Listed) || strings.EqualFold(e.Version, version) {
+				entry = &e
+			}
+		}
+	}
+	if entry == nil {
+		return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name, Version: version}
+	}
+
+	if notes := strings.TrimSpace(entry.ReleaseNotes); notes != "" {
+		return &core.Document{
+			Content:     notes,
+			ContentType: core.PlainText,
+			URL:         entry.CatalogURL,
+		}, nil
+	}
+	return core.RepositoryChangelog(ctx, r.client, extractRepository(entry.ProjectURL), entry.Version)
+}
@@ -0,0 +1,59 @@
+package nuget
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+type flatVersionsResponse struct {
+	Versions []string `json:"versions"`
+}
+
+// WithFlatContainer returns a new Registry whose FetchVersions lists
+// versions from the package content (flat container) resource: one small
+// request returning every version number, where the registration index can
+// run to megabytes and, for packages with many versions, to a request per
+// page. The versions carry no publish time, listing status or license;
+// FetchPackage, FetchDependencies and FetchMaintainers still read the
+// registration index.
+func (r *Registry) WithFlatContainer() *Registry {
+	copy := *r
+	copy.flat = true
+	return &copy
+}
+
+// flatVersions returns the version numbers of a package, oldest first, as
+// the flat container lists them. Unlisted versions are included.
+func (r *Registry) flatVersions(ctx context.Context, name string) ([]string, error) {
+	base, err := r.flatContainerURL(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var resp flatVersionsResponse
+	url := fmt.Sprintf("%s/%s/index.json", base, strings.ToLower(name))
+	if err := r.client.GetJSON(ctx, url, &resp); err != nil {
+		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
+			return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name}
+		}
+		return nil, err
+	}
+	if len(resp.Versions) == 0 {
+		return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name}
+	}
+	return resp.Versions, nil
+}
+
+func (r *Registry) fetchFlatVersions(ctx context.Context, name string) ([]core.Version, error) {
+	numbers, err := r.flatVersions(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	versions := make([]core.Version, len(numbers))
+	for i, n := range numbers {
+		versions[i] = core.Version{Number: n}
+	}
+	return versions, nil
+}
@@ -0,0 +1,80 @@
+package nuget
+
+import (
+	"context"
+	"path"
+	"strings"
+
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+// installScripts are the PowerShell scripts NuGet runs from a package's
+// tools folder when it is added to a project in Visual Studio.
+var installScripts = map[string]bool{
+	"tools/init.ps1":    true,
+	"tools/install.ps1": true,
+}
+
+type catalogLeaf struct {
+	PackageSize    int64 `json:"packageSize"`
+	PackageEntries []struct {
+		FullName string `json:"fullName"`
+		Length   int64  `json:"length"`
+	} `json:"packageEntries"`
+}
+
+// WithInstallSignals returns a new Registry whose FetchVersions also reads
+// each version's catalog leaf, which lists the files in the package, to
+// record install scripts and native runtimes in the version metadata, and
+// the package's size and file count. That's a request per version, and
+// only servers with a catalog, such as nuget.org, have leaves to read;
+// other versions are left without the signals.
+func (r *Registry) WithInstallSignals() *Registry {
+	copy := *r
+	copy.install = true
+	return &copy
+}
+
+// fillInstallSignals sets the install signals and sizes of versions from
+// the catalog leaves at the same indexes.
+func (r *Registry) fillInstallSignals(ctx context.Context, versions []core.Version, leaves []string) error {
+	for i, url := range leaves {
+		if url == "" {
+			continue
+		}
+		var leaf catalogLeaf
+		if err := r.client.GetJSON(ctx, url, &leaf); err != nil {
+			if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
+				continue
+			}
+			return err
+		}
+		files := make([]string, len(leaf.PackageEntries))
+		var unpacked int64
+		for j, e := range leaf.PackageEntries {
+			files[j] = e.FullName
+			unpacked += e.Length
+		}
+		scripts, native := installSignals(files)
+		core.SetInstallSignals(versions[i].Metadata, scripts, native)
+		versions[i].Size = leaf.PackageSize
+		versions[i].UnpackedSize = unpacked
+		versions[i].FileCount = len(files)
+	}
+	return nil
+}
+
+// installSignals finds install scripts and native libraries, which live
+// under runtimes/<rid>/native/, among a package's files.
+func installSignals(files []string) (scripts []string, native bool) {
+	for _, f := range files {
+		name := strings.ToLower(path.Clean(strings.ReplaceAll(f, "\\", "/")))
+		if installScripts[name] {
+			scripts = append(scripts, f)
+		}
+		if parts := strings.Split(name, "/"); len(parts) > 3 && parts[0] == "runtimes" && parts[2] == "native" {
+			native = true
+		}
+	}
+	return scripts, native
+}
@@ -0,0 +1,59 @@
+package nuget
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+func TestWithInstallSignals(t *testing.T) {
+	var leafRequests int
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/index.json":
+			_, _ = fmt.Fprintf(w, `{"resources":[{"@id":"%s/registration","@type":"RegistrationsBaseUrl/3.6.0"}]}`, server.URL)
+		case "/registration/native.lib/index.json":
+			_, _ = fmt.Fprintf(w, `{"items":[{"items":[
+				{"catalogEntry":{"@id":"%[1]s/catalog/1.0.0.json","id":"Native.Lib","version":"1.0.0","listed":true}},
+				{"catalogEntry":{"@id":"%[1]s/catalog/2.0.0.json","id":"Native.Lib","version":"2.0.0","listed":true}}
+			]}]}`, server.URL)
+		case "/catalog/1.0.0.json":
+			leafRequests++
+			_, _ = w.Write([]byte(`{"packageSize":4096,"packageEntries":[{"fullName":"lib/net8.0/Native.Lib.dll","length":8000},{"fullName":"tools/install.ps1","length":200}]}`))
+		case "/catalog/2.0.0.json":
+			leafRequests++
+			_, _ = w.Write([]byte(`{"packageEntries":[{"fullName":"runtimes/linux-x64/native/libnative.so"}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	if _, err := reg.FetchVersions(context.Background(), "Native.Lib"); err != nil {
+		t.Fatalf("FetchVersions failed: %v", err)
+	}
+	if leafRequests != 0 {
+		t.Fatalf("expected no catalog requests without WithInstallSignals, got %d", leafRequests)
+	}
+
+	versions, err := reg.WithInstallSignals().FetchVersions(context.Background(), "Native.Lib")
+	if err != nil {
+		t.Fatalf("FetchVersions failed: %v", err)
+	}
+	if got := core.InstallScripts(versions[0]); !reflect.DeepEqual(got, []string{"tools/install.ps1"}) || core.HasNativeCode(versions[0]) {
+		t.Errorf("unexpected signals for 1.0.0: %v", versions[0].Metadata)
+	}
+	if core.HasInstallScripts(versions[1]) || !core.HasNativeCode(versions[1]) {
+		t.Errorf("unexpected signals for 2.0.0: %v", versions[1].Metadata)
+	}
+	if v := versions[0]; v.Size != 4096 || v.UnpackedSize != 8200 || v.FileCount != 2 {
+		t.Errorf("unexpected sizes for 1.0.0: %d, %d, %d files", v.Size, v.UnpackedSize, v.FileCount)
+	}
+}
@@ -0,0 +1,113 @@
+package nuget
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+// namespaceSearchSize is how many search results FetchNamespace reads. It
+// is the most nuget.org returns in one page.
+const namespaceSearchSize = 1000
+
+type searchResponse struct {
+	TotalHits int            `json:"totalHits"`
+	Data      []searchResult `json:"data"`
+}
+
+type searchResult struct {
+	ID       string          `json:"id"`
+	Verified bool            `json:"verified"`
+	Owners   json.RawMessage `json:"owners"`
+}
+
+// owners returns a search result's owners, which servers give as either a
+// list or a single string.
+func (s searchResult) owners() []string {
+	var list []string
+	if err := json.Unmarshal(s.Owners, &list); err == nil {
+		return list
+	}
+	var one string
+	if err := json.Unmarshal(s.Owners, &one); err == nil && one != "" {
+		return []string{one}
+	}
+	return nil
+}
+
+// FetchNamespace returns a NuGet package ID prefix, such as "Newtonsoft" or
+// "Microsoft.Extensions". NuGet has no prefix API, so the prefix is looked
+// up with the search service: it is verified if packages under it carry
+// the reserved-prefix checkmark, and its members are the owners of those
+// packages, or of every package under it when none are verified.
+func (r *Registry) FetchNamespace(ctx context.Context, namespace string) (*core.Namespace, error) {
+	search, err := r.searchURL(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if search == "" {
+		return nil, fmt.Errorf("nuget: server %s has no search service: %w", r.baseURL, core.ErrNotSupported)
+	}
+
+	prefix := strings.TrimSuffix(namespace, ".")
+	endpoint := fmt.Sprintf("%s?q=%s&prerelease=true&semVerLevel=2.0.0&take=%d",
+		search, url.QueryEscape("id:"+prefix), namespaceSearchSize)
+	var resp searchResponse
+	if err := r.client.GetJSON(ctx, endpoint, &resp); err != nil {
+		return nil, err
+	}
+
+	lowerPrefix := strings.ToLower(prefix)
+	var packages []string
+	var verified bool
+	verifiedOwners := map[string]bool{}
+	allOwners := map[string]bool{}
+	for _, result := range resp.Data {
+		id := strings.ToLower(result.ID)
+		if id != lowerPrefix && !strings.HasPrefix(id, lowerPrefix+".") {
+			continue
+		}
+		packages = append(packages, result.ID)
+		for _, owner := range result.owners() {
+			allOwners[owner] = true
+			if result.Verified {
+				verifiedOwners[owner] = true
+			}
+		}
+		verified = verified || result.Verified
+	}
+	if len(packages) == 0 {
+		return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: namespace}
+	}
+
+	owners := allOwners
+	if verified {
+		owners = verifiedOwners
+	}
+	members := make([]core.Maintainer, 0, len(owners))
+	for owner := range owners {
+		m := core.Maintainer{Login: owner}
+		if r.services == nil {
+			m.URL = "https://www.nuget.org/profiles/" + owner
+		}
+		members = append(members, m)
+	}
+	sort.Slice(members, func(i, j int) bool { return members[i].Login < members[j].Login })
+	sort.Strings(packages)
+
+	return &core.Namespace{
+		Ecosystem: ecosystem,
+		Name:      prefix,
+		Kind:      core.NamespacePrefix,
+		Verified:  verified,
+		Members:   members,
+		Metadata: map[string]any{
+			"packages": packages,
+		},
+	}, nil
+}
@@ -0,0 +1,46 @@
+package nuget
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+func TestFetchNamespace(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/index.json":
+			_, _ = w.Write([]byte(`{"resources":[{"@id":"` + server.URL + `/query","@type":"SearchQueryService/3.5.0"}]}`))
+		case "/query":
+			if r.URL.Query().Get("q") != "id:Newtonsoft" {
+				t.Errorf("unexpected query %q", r.URL.RawQuery)
+			}
+			_, _ = w.Write([]byte(`{"totalHits":3,"data":[
+				{"id":"Newtonsoft.Json","verified":true,"owners":["dotnetfoundation","jamesnk"]},
+				{"id":"Newtonsoft.Json.Bson","verified":true,"owners":"jamesnk"},
+				{"id":"NewtonsoftExtras","verified":false,"owners":["someone"]}
+			]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	ns, err := New(server.URL, core.DefaultClient()).FetchNamespace(context.Background(), "Newtonsoft")
+	if err != nil {
+		t.Fatalf("FetchNamespace failed: %v", err)
+	}
+	if !ns.Verified || ns.Kind != core.NamespacePrefix {
+		t.Errorf("unexpected namespace %+v", ns)
+	}
+	if packages := ns.Metadata["packages"].([]string); len(packages) != 2 {
+		t.Errorf("expected packages outside the prefix to be skipped, got %v", packages)
+	}
+	if len(ns.Members) != 2 || ns.Members[0].Login != "dotnetfoundation" || ns.Members[1].Login != "jamesnk" {
+		t.Errorf("unexpected members %+v", ns.Members)
+	}
+}
@@ -23,9 +23,12 @@ func init() {
 }
 
 type Registry struct {
-	baseURL string
-	client  *core.Client
-	urls    *URLs
+	baseURL  string
+	client   *core.Client
+	urls     *URLs
+	services *serviceIndex
+	flat     bool
+	install  bool // set by WithInstallSignals
 }
 
 func New(baseURL string, client *core.Client) *Registry {
@@ -36,7 +39,12 @@ func New(baseURL string, client *core.Client) *Registry {
 		baseURL: strings.TrimSuffix(baseURL, "/"),
 		client:  client,
 	}
-	r.urls = &URLs{baseURL: r.baseURL}
+	// nuget.org's layout is known, so only other servers are asked for
+	// their service index
+	if r.baseURL != DefaultURL {
+		r.services = newServiceIndex(r.baseURL)
+	}
+	r.urls = &URLs{baseURL: r.baseURL, services: r.services}
 	return r
 }
 
@@ -61,6 +69,7 @@ type registrationLeaf struct {
 }
 
 type catalogEntry struct {
+	CatalogURL    string   `json:"@id"`
 	ID            string   `json:"id"`
 	Version       string   `json:"version"`
 	Description   string   `json:"description"`
@@ -75,6 +84,7 @@ type catalogEntry struct {
 	Deprecation   *deprecationInfo `json:"deprecation"`
 	Dependencies  []dependencyGroup `json:"dependencyGroups"`
 	LicenseExpression string `json:"licenseExpression"`
+	ReleaseNotes      string `json:"releaseNotes"`
 }
 
 type deprecationInfo struct {
@@ -95,7 +105,10 @@ type dependency struct {
 func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package, error) {
 	// NuGet IDs are case-insensitive, lowercase for URL
 	lowerName := strings.ToLower(name)
-	url := fmt.Sprintf("%s/registration5-semver1/%s/index.json", r.baseURL, lowerName)
+	url, err := r.registrationURL(ctx, lowerName)
+	if err != nil {
+		return nil, err
+	}
 
 	var resp registrationResponse
 	if err := r.client.GetJSON(ctx, url, &resp); err != nil {
@@ -107,12 +120,16 @@ func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package
 
 	// Get the latest version's catalog entry
 	var latest *catalogEntry
+	var listed []string
 	for _, page := range resp.Items {
 		for _, leaf := range page.Items {
 			if latest == nil || leaf.CatalogEntry.Listed {
 				entry := leaf.CatalogEntry
 				latest = &entry
 			}
+			if leaf.CatalogEntry.Listed {
+				listed = append(listed, leaf.CatalogEntry.Version)
+			}
 		}
 	}
 
@@ -146,6 +163,8 @@ func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package
 			"icon_url":    latest.IconURL,
 			"license_url": latest.LicenseURL,
 		},
+		LatestVersion:       core.LatestOf(ecosystem, listed),
+		LatestStableVersion: core.LatestStableOf(ecosystem, listed),
 	}, nil
 }
 
@@ -154,8 +173,14 @@ func extractRepository(projectURL string) string {
 }
 
 func (r *Registry) FetchVersions(ctx context.Context, name string) ([]core.Version, error) {
+	if r.flat {
+		return r.fetchFlatVersions(ctx, name)
+	}
 	lowerName := strings.ToLower(name)
-	url := fmt.Sprintf("%s/registration5-semver1/%s/index.json", r.baseURL, lowerName)
+	url, err := r.registrationURL(ctx, lowerName)
+	if err != nil {
+		return nil, err
+	}
 
 	var resp registrationResponse
 	if err := r.client.GetJSON(ctx, url, &resp); err != nil {
@@ -166,6 +191,7 @@ func (r *Registry) FetchVersions(ctx context.Context, name string) ([]core.Versi
 	}
 
 	var versions []core.Version
+	var leaves []string
 	for _, page := range resp.Items {
 		for _, leaf := range page.Items {
 			entry := leaf.CatalogEntry
@@ -197,6 +223,13 @@ func (r *Registry) FetchVersions(ctx context.Context, name string) ([]core.Versi
 					"deprecation": entry.Deprecation,
 				},
 			})
+			leaves = append(leaves, entry.CatalogURL)
+		}
+	}
+
+	if r.install {
+		if err := r.fillInstallSignals(ctx, versions, leaves); err != nil {
+			return nil, err
 		}
 	}
 
@@ -205,7 +238,10 @@ func (r *Registry) FetchVersions(ctx context.Context, name string) ([]core.Versi
 
 func (r *Registry) FetchDependencies(ctx context.Context, name, version string) ([]core.Dependency, error) {
 	lowerName := strings.ToLower(name)
-	url := fmt.Sprintf("%s/registration5-semver1/%s/index.json", r.baseURL, lowerName)
+	url, err := r.registrationURL(ctx, lowerName)
+	if err != nil {
+		return nil, err
+	}
 
 	var resp registrationResponse
 	if err := r.client.GetJSON(ctx, url, &resp); err != nil {
@@ -228,32 +264,28 @@ func (r *Registry) FetchDependencies(ctx context.Context, name, version string)
 }
 
 func extractDependencies(groups []dependencyGroup) []core.Dependency {
-	// Use a map to deduplicate dependencies across target frameworks
-	seen := make(map[string]core.Dependency)
-
+	// Each target framework declares its own dependency set, so the same
+	// package can appear once per framework with a different range.
+	var deps []core.Dependency
 	for _, group := range groups {
 		for _, dep := range group.Dependencies {
-			key := dep.ID
-			if _, ok := seen[key]; !ok {
-				seen[key] = core.Dependency{
-					Name:         dep.ID,
-					Requirements: dep.Range,
-					Scope:        core.Runtime,
-				}
-			}
+			deps = append(deps, core.Dependency{
+				Name:         dep.ID,
+				Requirements: dep.Range,
+				Scope:        core.Runtime,
+				Target:       group.TargetFramework,
+			})
 		}
 	}
-
-	deps := make([]core.Dependency, 0, len(seen))
-	for _, d := range seen {
-		deps = append(deps, d)
-	}
 	return deps
 }
 
 func (r *Registry) FetchMaintainers(ctx context.Context, name string) ([]core.Maintainer, error) {
 	lowerName := strings.ToLower(name)
-	url := fmt.Sprintf("%s/registration5-semver1/%s/index.json", r.baseURL, lowerName)
+	url, err := r.registrationURL(ctx, lowerName)
+	if err != nil {
+		return nil, err
+	}
 
 	var resp registrationResponse
 	if err := r.client.GetJSON(ctx, url, &resp); err != nil {
@@ -291,7 +323,8 @@ func (r *Registry) FetchMaintainers(ctx context.Context, name string) ([]core.Ma
 }
 
 type URLs struct {
-	baseURL string
+	baseURL  string
+	services *serviceIndex
 }
 
 func (u *URLs) Registry(name, version string) string {
@@ -305,9 +338,19 @@ func (u *URLs) Download(name, version string) string {
 	if version == "" {
 		return ""
 	}
+	// A server's package content resource is only known once its service
+	// index has been read by a fetch
+	base := defaultFlatContainer(u.baseURL)
+	if u.services != nil {
+		if found := u.services.cached(packageContentTypes); found != "" {
+			base = found
+		} else if u.services.required {
+			return ""
+		}
+	}
 	lowerName := strings.ToLower(name)
 	lowerVersion := strings.ToLower(version)
-	return fmt.Sprintf("https://api.nuget.org/v3-flatcontainer/%s/%s/%s.%s.nupkg", lowerName, lowerVersion, lowerName, lowerVersion)
+	return fmt.Sprintf("%s/%s/%s/%s.%s.nupkg", base, lowerName, lowerVersion, lowerName, lowerVersion)
 }
 
 func (u *URLs) Documentation(name, version string) string {
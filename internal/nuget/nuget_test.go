@@ -3,6 +3,8 @@ package nuget
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -12,6 +14,11 @@ import (
 
 func TestFetchPackage(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/index.json" {
+			// Service index probe: this server uses nuget.org's layout
+			w.WriteHeader(404)
+			return
+		}
 		if r.URL.Path != "/registration5-semver1/newtonsoft.json/index.json" {
 			t.Errorf("unexpected path: %s", r.URL.Path)
 			w.WriteHeader(404)
@@ -24,13 +31,13 @@ func TestFetchPackage(t *testing.T) {
 					Items: []registrationLeaf{
 						{
 							CatalogEntry: catalogEntry{
-								ID:               "Newtonsoft.Json",
-								Version:          "13.0.3",
-								Description:      "Json.NET is a popular high-performance JSON framework for .NET",
-								ProjectURL:       "https://www.newtonsoft.com/json",
+								ID:                "Newtonsoft.Json",
+								Version:           "13.0.3",
+								Description:       "Json.NET is a popular high-performance JSON framework for .NET",
+								ProjectURL:        "https://www.newtonsoft.com/json",
 								LicenseExpression: "MIT",
-								Listed:           true,
-								Tags:             []string{"json"},
+								Listed:            true,
+								Tags:              []string{"json"},
 							},
 						},
 					},
@@ -60,6 +67,50 @@ func TestFetchPackage(t *testing.T) {
 	}
 }
 
+func TestServiceIndex(t *testing.T) {
+	var indexRequests int
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/octo-org/index.json":
+			indexRequests++
+			_, _ = fmt.Fprintf(w, `{"version":"3.0.0","resources":[
+				{"@id":"%[1]s/octo-org/download","@type":"PackageBaseAddress/3.0.0"},
+				{"@id":"%[1]s/octo-org/query/","@type":"RegistrationsBaseUrl"},
+				{"@id":"%[1]s/octo-org/registration-semver2/","@type":"RegistrationsBaseUrl/3.6.0"}
+			]}`, server.URL)
+		case "/octo-org/registration-semver2/octo.lib/index.json":
+			_ = json.NewEncoder(w).Encode(registrationResponse{Items: []registrationPage{{Items: []registrationLeaf{
+				{CatalogEntry: catalogEntry{ID: "Octo.Lib", Version: "2.0.0", Listed: true}},
+			}}}})
+		default:
+			w.WriteHeader(404)
+		}
+	}))
+	defer server.Close()
+
+	reg := New(server.URL+"/octo-org/index.json", core.DefaultClient())
+	pkg, err := reg.FetchPackage(context.Background(), "Octo.Lib")
+	if err != nil {
+		t.Fatalf("FetchPackage failed: %v", err)
+	}
+	if pkg.Name != "Octo.Lib" {
+		t.Errorf("unexpected package: %+v", pkg)
+	}
+
+	if _, err := reg.FetchVersions(context.Background(), "Octo.Lib"); err != nil {
+		t.Fatalf("FetchVersions failed: %v", err)
+	}
+	if indexRequests != 1 {
+		t.Errorf("expected the service index to be fetched once, got %d", indexRequests)
+	}
+
+	if _, err := reg.FetchPackage(context.Background(), "missing"); !errors.Is(err, core.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
 func TestFetchPackageWithGitHubRepository(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		resp := registrationResponse{
@@ -203,9 +254,9 @@ func TestFetchDependencies(t *testing.T) {
 		t.Fatalf("FetchDependencies failed: %v", err)
 	}
 
-	// Should have 2 unique dependencies (deduplicated across frameworks)
-	if len(deps) != 2 {
-		t.Fatalf("expected 2 dependencies, got %d", len(deps))
+	// One entry per dependency per target framework
+	if len(deps) != 3 {
+		t.Fatalf("expected 3 dependencies, got %d", len(deps))
 	}
 
 	for _, d := range deps {
@@ -213,6 +264,16 @@ func TestFetchDependencies(t *testing.T) {
 			t.Errorf("expected runtime scope, got %q", d.Scope)
 		}
 	}
+
+	if deps[0].Target != "net8.0" {
+		t.Errorf("expected target 'net8.0', got %q", deps[0].Target)
+	}
+	if deps[2].Target != "net6.0" {
+		t.Errorf("expected target 'net6.0', got %q", deps[2].Target)
+	}
+	if deps[2].Requirements != "[6.0.0, )" {
+		t.Errorf("expected net6.0 range '[6.0.0, )', got %q", deps[2].Requirements)
+	}
 }
 
 func TestFetchMaintainers(t *testing.T) {
@@ -281,3 +342,119 @@ func TestEcosystem(t *testing.T) {
 		t.Errorf("expected ecosystem 'nuget', got %q", reg.Ecosystem())
 	}
 }
+
+func TestFetchReadme(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v3-flatcontainer/newtonsoft.json/index.json":
+			_ = json.NewEncoder(w).Encode(map[string][]string{"versions": {"12.0.3", "13.0.3"}})
+		case "/v3-flatcontainer/newtonsoft.json/13.0.3/readme":
+			_, _ = w.Write([]byte("# Json.NET"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	reg := New(server.URL+"/v3", core.DefaultClient())
+	ctx := context.Background()
+
+	doc, err := reg.FetchReadme(ctx, "Newtonsoft.Json", "")
+	if err != nil {
+		t.Fatalf("FetchReadme failed: %v", err)
+	}
+	if doc.ContentType != core.Markdown || doc.Content != "# Json.NET" {
+		t.Errorf("unexpected document: %+v", doc)
+	}
+
+	if _, err := reg.FetchReadme(ctx, "Newtonsoft.Json", "12.0.3"); !errors.Is(err, core.ErrNotFound) {
+		t.Errorf("expected not found for package without readme, got %v", err)
+	}
+}
+
+func TestFlatContainer(t *testing.T) {
+	var registrationRequests int
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/nuget/index.json":
+			_, _ = fmt.Fprintf(w, `{"version":"3.0.0","resources":[
+				{"@id":"%[1]s/content/","@type":"PackageBaseAddress/3.0.0"},
+				{"@id":"%[1]s/reg/","@type":"RegistrationsBaseUrl/3.6.0"}
+			]}`, server.URL)
+		case "/content/octo.lib/index.json":
+			_, _ = w.Write([]byte(`{"versions":["1.0.0","1.1.0","2.0.0-beta.1"]}`))
+		case "/reg/octo.lib/index.json":
+			registrationRequests++
+			_ = json.NewEncoder(w).Encode(registrationResponse{Items: []registrationPage{{Items: []registrationLeaf{
+				{CatalogEntry: catalogEntry{ID: "Octo.Lib", Version: "1.0.0", Listed: true}},
+			}}}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	// The service index is found under a base URL that doesn't name it
+	reg := New(server.URL+"/nuget", core.DefaultClient())
+	if got := reg.URLs().Download("Octo.Lib", "1.0.0"); got != server.URL+"/nuget/v3-flatcontainer/octo.lib/1.0.0/octo.lib.1.0.0.nupkg" {
+		t.Errorf("Download before discovery = %q", got)
+	}
+
+	flat := reg.WithFlatContainer()
+	versions, err := flat.FetchVersions(context.Background(), "Octo.Lib")
+	if err != nil {
+		t.Fatalf("FetchVersions failed: %v", err)
+	}
+	if len(versions) != 3 || versions[2].Number != "2.0.0-beta.1" {
+		t.Errorf("unexpected versions: %+v", versions)
+	}
+	if registrationRequests != 0 {
+		t.Errorf("flat container listing read the registration index")
+	}
+	if got := reg.URLs().Download("Octo.Lib", "1.0.0"); got != server.URL+"/content/octo.lib/1.0.0/octo.lib.1.0.0.nupkg" {
+		t.Errorf("Download after discovery = %q", got)
+	}
+
+	if _, err := reg.FetchVersions(context.Background(), "Octo.Lib"); err != nil {
+		t.Fatalf("FetchVersions failed: %v", err)
+	}
+	if registrationRequests != 1 {
+		t.Errorf("expected the registration index to be read, got %d requests", registrationRequests)
+	}
+
+	if _, err := flat.FetchVersions(context.Background(), "missing"); !errors.Is(err, core.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestFetchChangelog(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(registrationResponse{Items: []registrationPage{{Items: []registrationLeaf{
+			{CatalogEntry: catalogEntry{ID: "Polly", Version: "8.0.0", Listed: true, ReleaseNotes: "  Resilience pipelines\n"}},
+			{CatalogEntry: catalogEntry{ID: "Polly", Version: "8.1.0", Listed: true, ReleaseNotes: "Fixes for 8.0"}},
+			{CatalogEntry: catalogEntry{ID: "Polly", Version: "8.2.0-beta.1", Listed: false}},
+		}}}})
+	}))
+	defer server.Close()
+
+	reg := New(server.URL+"/v3", core.DefaultClient())
+	ctx := context.Background()
+
+	doc, err := reg.FetchChangelog(ctx, "Polly", "8.0.0")
+	if err != nil {
+		t.Fatalf("FetchChangelog failed: %v", err)
+	}
+	if doc.Content != "Resilience pipelines" || doc.ContentType != core.PlainText {
+		t.Errorf("unexpected document: %+v", doc)
+	}
+
+	// The latest listed version, skipping the unlisted prerelease
+	if doc, err := reg.FetchChangelog(ctx, "Polly", ""); err != nil || doc.Content != "Fixes for 8.0" {
+		t.Errorf("latest release notes = %+v, %v", doc, err)
+	}
+
+	if _, err := reg.FetchChangelog(ctx, "Polly", "9.0.0"); !errors.Is(err, core.ErrNotFound) {
+		t.Errorf("expected not found for unknown version, got %v", err)
+	}
+}
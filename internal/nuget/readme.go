@@ -0,0 +1,44 @@
+package nuget
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+// FetchReadme returns the Markdown README embedded in the package. Only
+// packages built with a <readme> element have one. If version is empty, the
+// most recently published version is used.
+func (r *Registry) FetchReadme(ctx context.Context, name, version string) (*core.Document, error) {
+	lowerName := strings.ToLower(name)
+
+	if version == "" {
+		versions, err := r.flatVersions(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		version = versions[len(versions)-1]
+	}
+
+	base, err := r.flatContainerURL(ctx)
+	if err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("%s/%s/%s/readme", base, lowerName, strings.ToLower(version))
+	body, err := r.client.GetText(ctx, url)
+	if err != nil {
+		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
+			return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name, Version: version}
+		}
+		return nil, err
+	}
+
+	return &core.Document{
+		Content:     body,
+		ContentType: core.Markdown,
+		Filename:    "README.md",
+		URL:         url,
+	}, nil
+}
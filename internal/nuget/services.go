@@ -0,0 +1,185 @@
+package nuget
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+// registrationTypes are the registration resource versions in order of
+// preference. 3.6.0 includes SemVer 2.0.0 packages.
+var registrationTypes = []string{
+	"RegistrationsBaseUrl/3.6.0",
+	"RegistrationsBaseUrl/3.4.0",
+	"RegistrationsBaseUrl/3.0.0-rc",
+	"RegistrationsBaseUrl/3.0.0-beta",
+	"RegistrationsBaseUrl",
+}
+
+// packageContentTypes identify the package content (flat container)
+// resource, which lists version numbers and serves .nupkg files.
+var packageContentTypes = []string{
+	"PackageBaseAddress/3.0.0",
+}
+
+// searchTypes identify the search query service.
+var searchTypes = []string{
+	"SearchQueryService/3.5.0",
+	"SearchQueryService/3.0.0-rc",
+	"SearchQueryService",
+}
+
+// defaultSearchURL is nuget.org's search query service.
+const defaultSearchURL = "https://azuresearch-usnc.nuget.org/query"
+
+type serviceIndexResponse struct {
+	Resources []struct {
+		ID   string `json:"@id"`
+		Type string `json:"@type"`
+	} `json:"resources"`
+}
+
+// serviceIndex looks up resource URLs from a NuGet v3 service index once
+// and remembers them. It is shared by copies of a Registry.
+//
+// An index given explicitly as the base URL is required: failing to read it
+// is an error. Otherwise the index is probed at <baseURL>/index.json, and a
+// server without one is assumed to use nuget.org's layout.
+type serviceIndex struct {
+	url      string
+	required bool
+
+	mu        sync.Mutex
+	loaded    bool
+	resources map[string]string
+}
+
+// isServiceIndex reports whether baseURL names a service index document
+// (".../index.json") rather than nuget.org's API root.
+func isServiceIndex(baseURL string) bool {
+	return strings.HasSuffix(baseURL, "/index.json")
+}
+
+func newServiceIndex(baseURL string) *serviceIndex {
+	if isServiceIndex(baseURL) {
+		return &serviceIndex{url: baseURL, required: true}
+	}
+	return &serviceIndex{url: baseURL + "/index.json"}
+}
+
+// resource returns the URL of the first resource of the given types, or ""
+// if an optional index is missing or lacks them.
+func (s *serviceIndex) resource(ctx context.Context, client *core.Client, types []string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.loaded {
+		resp, definite, err := s.fetch(ctx, client)
+		if err != nil {
+			if s.required {
+				return "", fmt.Errorf("nuget: fetching service index: %w", err)
+			}
+			// A server error or network failure may be transient, so it
+			// isn't remembered as the server having no index
+			if !definite {
+				return "", nil
+			}
+		}
+		s.resources = make(map[string]string, len(resp.Resources))
+		for _, res := range resp.Resources {
+			if _, ok := s.resources[res.Type]; !ok && res.ID != "" {
+				s.resources[res.Type] = strings.TrimSuffix(res.ID, "/")
+			}
+		}
+		s.loaded = true
+	}
+	return s.lookup(types), nil
+}
+
+// fetch reads the index. definite reports whether a failure is a lasting
+// answer, a client error or a document that isn't an index, rather than one
+// worth retrying.
+func (s *serviceIndex) fetch(ctx context.Context, client *core.Client) (resp serviceIndexResponse, definite bool, err error) {
+	body, err := client.GetBody(ctx, s.url)
+	if err != nil {
+		httpErr, ok := err.(*core.HTTPError)
+		return resp, ok && httpErr.StatusCode < 500 && httpErr.StatusCode != 429, err
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return serviceIndexResponse{}, true, err
+	}
+	return resp, true, nil
+}
+
+func (s *serviceIndex) lookup(types []string) string {
+	for _, t := range types {
+		if id := s.resources[t]; id != "" {
+			return id
+		}
+	}
+	return ""
+}
+
+// cached returns the URL of a resource if the index has already been read,
+// for callers such as URL builders that can't make requests.
+func (s *serviceIndex) cached(types []string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lookup(types)
+}
+
+// registrationURL returns the registration index URL for a lowercased
+// package ID. The registration resource comes from the service index, as
+// third-party servers such as GitHub Packages need; nuget.org's layout is
+// assumed for nuget.org itself and for servers without an index.
+func (r *Registry) registrationURL(ctx context.Context, lowerName string) (string, error) {
+	base := r.baseURL + "/registration5-semver1"
+	if r.services != nil {
+		found, err := r.services.resource(ctx, r.client, registrationTypes)
+		if err != nil {
+			return "", err
+		}
+		switch {
+		case found != "":
+			base = found
+		case r.services.required:
+			return "", fmt.Errorf("nuget: service index %s has no registration resource", r.services.url)
+		}
+	}
+	return fmt.Sprintf("%s/%s/index.json", base, lowerName), nil
+}
+
+// flatContainerURL returns the package content (flat container) base URL,
+// from the service index where there is one. nuget.org serves it as a
+// sibling of the v3 API root.
+func (r *Registry) flatContainerURL(ctx context.Context) (string, error) {
+	if r.services != nil {
+		found, err := r.services.resource(ctx, r.client, packageContentTypes)
+		if err != nil {
+			return "", err
+		}
+		switch {
+		case found != "":
+			return found, nil
+		case r.services.required:
+			return "", fmt.Errorf("nuget: service index %s has no package content resource", r.services.url)
+		}
+	}
+	return defaultFlatContainer(r.baseURL), nil
+}
+
+// searchURL returns the search query service URL, from the service index
+// where there is one, or "" if the server has none.
+func (r *Registry) searchURL(ctx context.Context) (string, error) {
+	if r.services == nil {
+		return defaultSearchURL, nil
+	}
+	return r.services.resource(ctx, r.client, searchTypes)
+}
+
+func defaultFlatContainer(baseURL string) string {
+	return strings.TrimSuffix(baseURL, "/v3") + "/v3-flatcontainer"
+}
@@ -0,0 +1,39 @@
+package packagist
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+// BulkDependencyBatchSize is 1 as package metadata covers a single package.
+func (r *Registry) BulkDependencyBatchSize() int {
+	return 1
+}
+
+// FetchDependenciesBulk reads the dependencies of every requested version
+// of a package from its metadata, which lists all versions at once.
+func (r *Registry) FetchDependenciesBulk(ctx context.Context, versions []core.PackageVersion) (map[core.PackageVersion][]core.Dependency, error) {
+	packages := make(map[string]*packageInfo)
+	result := make(map[core.PackageVersion][]core.Dependency)
+	for _, pv := range versions {
+		pkg, ok := packages[pv.Name]
+		if !ok {
+			url := fmt.Sprintf("%s/packages/%s.json", r.baseURL, pv.Name)
+			var resp packageResponse
+			if err := r.client.GetJSON(ctx, url, &resp); err != nil {
+				if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
+					return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: pv.Name}
+				}
+				return nil, err
+			}
+			pkg = &resp.Package
+			packages[pv.Name] = pkg
+		}
+		if v, ok := pkg.version(pv.Version); ok {
+			result[pv] = v.dependencies()
+		}
+	}
+	return result, nil
+}
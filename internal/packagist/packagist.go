@@ -62,6 +62,13 @@ type packageInfo struct {
 	Repository  string                 `json:"repository"`
 	Language    string                 `json:"language"`
 	Abandoned   interface{}            `json:"abandoned"`
+	Downloads   *downloadsInfo         `json:"downloads"` // packagist.org only
+	Favers      *int                   `json:"favers"`
+}
+
+type downloadsInfo struct {
+	Total   int `json:"total"`
+	Monthly int `json:"monthly"`
 }
 
 type maintainerInfo struct {
@@ -81,6 +88,11 @@ type versionInfo struct {
 	Dist             distInfo          `json:"dist"`
 	Require          map[string]string `json:"require"`
 	RequireDev       map[string]string `json:"require-dev"`
+	Suggest          map[string]string `json:"suggest"`
+	Conflict         map[string]string `json:"conflict"`
+	Replace          map[string]string `json:"replace"`
+	Provide          map[string]string `json:"provide"`
+	Support          map[string]string `json:"support"`
 }
 
 type sourceInfo struct {
@@ -116,11 +128,14 @@ func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package
 	}
 
 	// Find the latest stable version for homepage/repository
-	var homepage, repository, licenses string
+	var homepage, repository, licenses, documentation string
 	for _, v := range pkg.Versions {
 		if v.Homepage != "" && homepage == "" {
 			homepage = v.Homepage
 		}
+		if v.Support["docs"] != "" && documentation == "" {
+			documentation = v.Support["docs"]
+		}
 		if v.Source.URL != "" && repository == "" {
 			repository = urlparser.Parse(v.Source.URL)
 		}
@@ -134,17 +149,50 @@ func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package
 		repository = urlparser.Parse(pkg.Repository)
 	}
 
+	// Branch versions' times are their latest commit, not a release
+	var latestReleased time.Time
+	var releases []string
+	for _, v := range pkg.Versions {
+		if strings.HasPrefix(v.Version, "dev-") || strings.HasSuffix(v.Version, "-dev") {
+			continue
+		}
+		releases = append(releases, v.Version)
+		if t, err := time.Parse(time.RFC3339, v.Time); err == nil && t.After(latestReleased) {
+			latestReleased = t
+		}
+	}
+	createdAt, _ := time.Parse(time.RFC3339, pkg.Time)
+
+	var popularity *core.Popularity
+	if pkg.Downloads != nil || pkg.Favers != nil {
+		popularity = &core.Popularity{}
+		if pkg.Downloads != nil {
+			popularity.DownloadsTotal = int64(pkg.Downloads.Total)
+			popularity.DownloadsRecent = int64(pkg.Downloads.Monthly)
+			popularity.RecentDays = 30
+		}
+		if pkg.Favers != nil {
+			popularity.Stars = int64(*pkg.Favers)
+		}
+	}
+
 	return &core.Package{
-		Name:        pkg.Name,
-		Description: pkg.Description,
-		Homepage:    homepage,
-		Repository:  repository,
-		Licenses:    licenses,
-		Namespace:   namespace,
+		Name:          pkg.Name,
+		Description:   pkg.Description,
+		Homepage:      homepage,
+		Repository:    repository,
+		Documentation: documentation,
+		Licenses:      licenses,
+		Namespace:     namespace,
 		Metadata: map[string]any{
 			"type":      pkg.Type,
 			"abandoned": pkg.Abandoned,
 		},
+		Popularity:          popularity,
+		CreatedAt:           createdAt,
+		LatestReleasedAt:    latestReleased,
+		LatestVersion:       core.LatestOf(ecosystem, releases),
+		LatestStableVersion: core.LatestStableOf(ecosystem, releases),
 	}, nil
 }
 
@@ -168,11 +216,12 @@ func (r *Registry) FetchVersions(ctx context.Context, name string) ([]core.Versi
 
 		var integrity string
 		if v.Dist.Shasum != "" {
-			integrity = "sha1-" + v.Dist.Shasum
+			integrity = core.HexIntegrity("sha1", v.Dist.Shasum)
 		}
 
 		var status core.VersionStatus
-		if resp.Package.Abandoned != nil {
+		// abandoned is false, true or the name of a replacement package
+		if a := resp.Package.Abandoned; a != nil && a != false {
 			status = core.StatusDeprecated
 		}
 
@@ -203,18 +252,29 @@ func (r *Registry) FetchDependencies(ctx context.Context, name, version string)
 		return nil, err
 	}
 
-	versionInfo, ok := resp.Package.Versions[version]
+	versionInfo, ok := resp.Package.version(version)
 	if !ok {
-		// Try with 'v' prefix
-		versionInfo, ok = resp.Package.Versions["v"+version]
-		if !ok {
-			return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name, Version: version}
-		}
+		return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name, Version: version}
 	}
+	return versionInfo.dependencies(), nil
+}
 
+// version looks up a version with or without its "v" prefix.
+func (p packageInfo) version(version string) (versionInfo, bool) {
+	if v, ok := p.Versions[version]; ok {
+		return v, true
+	}
+	v, ok := p.Versions["v"+version]
+	return v, ok
+}
+
+// dependencies returns the version's Composer requirements, leaving out
+// PHP itself and extensions, followed by its conflict, replace and provide
+// links as entries with a Relation.
+func (v versionInfo) dependencies() []core.Dependency {
 	var deps []core.Dependency
 
-	for depName, req := range versionInfo.Require {
+	for depName, req := range v.Require {
 		// Skip PHP and extension requirements
 		if depName == "php" || strings.HasPrefix(depName, "ext-") {
 			continue
@@ -226,7 +286,7 @@ func (r *Registry) FetchDependencies(ctx context.Context, name, version string)
 		})
 	}
 
-	for depName, req := range versionInfo.RequireDev {
+	for depName, req := range v.RequireDev {
 		deps = append(deps, core.Dependency{
 			Name:         depName,
 			Requirements: req,
@@ -234,7 +294,41 @@ func (r *Registry) FetchDependencies(ctx context.Context, name, version string)
 		})
 	}
 
-	return deps, nil
+	// Suggest values are free-text reasons rather than constraints
+	for depName, reason := range v.Suggest {
+		if depName == "php" || strings.HasPrefix(depName, "ext-") {
+			continue
+		}
+		dep := core.Dependency{
+			Name:         depName,
+			Requirements: "*",
+			Scope:        core.Optional,
+			Optional:     true,
+		}
+		if reason != "" {
+			dep.Metadata = map[string]any{"reason": reason}
+		}
+		deps = append(deps, dep)
+	}
+
+	for _, links := range []struct {
+		relation core.Relation
+		packages map[string]string
+	}{
+		{core.RelationConflict, v.Conflict},
+		{core.RelationReplace, v.Replace},
+		{core.RelationProvide, v.Provide},
+	} {
+		for depName, req := range links.packages {
+			deps = append(deps, core.Dependency{
+				Name:         depName,
+				Requirements: req,
+				Relation:     links.relation,
+			})
+		}
+	}
+
+	return deps
 }
 
 func (r *Registry) FetchMaintainers(ctx context.Context, name string) ([]core.Maintainer, error) {
@@ -5,12 +5,14 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/git-pkgs/registries/internal/core"
 )
 
 func TestFetchPackage(t *testing.T) {
+	favers := 34000
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path != "/packages/laravel/framework.json" {
 			t.Errorf("unexpected path: %s", r.URL.Path)
@@ -23,6 +25,8 @@ func TestFetchPackage(t *testing.T) {
 				Name:        "laravel/framework",
 				Description: "The Laravel Framework",
 				Repository:  "https://github.com/laravel/framework.git",
+				Downloads:   &downloadsInfo{Total: 480000000, Monthly: 9000000},
+				Favers:      &favers,
 				Versions: map[string]versionInfo{
 					"v11.0.0": {
 						Version:  "v11.0.0",
@@ -32,6 +36,9 @@ func TestFetchPackage(t *testing.T) {
 							URL: "https://github.com/laravel/framework.git",
 						},
 					},
+					"v12.0.0-beta1": {Version: "v12.0.0-beta1"},
+					"v10.48.0":      {Version: "v10.48.0"},
+					"dev-master":    {Version: "dev-master"},
 				},
 			},
 		}
@@ -59,6 +66,12 @@ func TestFetchPackage(t *testing.T) {
 	if pkg.Licenses != "MIT" {
 		t.Errorf("unexpected licenses: %q", pkg.Licenses)
 	}
+	if pkg.LatestVersion != "v12.0.0-beta1" || pkg.LatestStableVersion != "v11.0.0" {
+		t.Errorf("latest = %q, stable = %q", pkg.LatestVersion, pkg.LatestStableVersion)
+	}
+	if want := (core.Popularity{DownloadsTotal: 480000000, DownloadsRecent: 9000000, RecentDays: 30, Stars: 34000}); pkg.Popularity == nil || *pkg.Popularity != want {
+		t.Errorf("Popularity = %+v, want %+v", pkg.Popularity, want)
+	}
 }
 
 func TestFetchVersions(t *testing.T) {
@@ -72,7 +85,13 @@ func TestFetchVersions(t *testing.T) {
 						Time:    "2024-01-15T12:00:00+00:00",
 						License: []string{"MIT"},
 						Dist: distInfo{
-							Shasum: "abc123",
+							Shasum: "a9993e364706816aba3e25717850c26c9cd0d89d",
+						},
+						Replace: map[string]string{
+							"monolog/monolog-legacy": "self.version",
+						},
+						Provide: map[string]string{
+							"psr/log-implementation": "3.0.0",
 						},
 					},
 					"3.4.0": {
@@ -102,7 +121,7 @@ func TestFetchVersions(t *testing.T) {
 	for _, v := range versions {
 		if v.Integrity != "" {
 			hasIntegrity = true
-			if v.Integrity != "sha1-abc123" {
+			if v.Integrity != "sha1-qZk+NkcGgWq6PiVxeFDCbJzQ2J0=" {
 				t.Errorf("unexpected integrity: %q", v.Integrity)
 			}
 		}
@@ -110,6 +129,13 @@ func TestFetchVersions(t *testing.T) {
 	if !hasIntegrity {
 		t.Error("expected at least one version with integrity")
 	}
+
+	for _, v := range versions {
+		// Links are dependency entries, not version metadata
+		if _, ok := v.Metadata["provide"]; ok {
+			t.Errorf("%s: unexpected provide metadata", v.Number)
+		}
+	}
 }
 
 func TestFetchDependencies(t *testing.T) {
@@ -121,14 +147,27 @@ func TestFetchDependencies(t *testing.T) {
 					"v7.0.0": {
 						Version: "v7.0.0",
 						Require: map[string]string{
-							"php":                      ">=8.2",
+							"php":                       ">=8.2",
 							"symfony/polyfill-mbstring": "~1.0",
-							"symfony/string":           "^6.4|^7.0",
+							"symfony/string":            "^6.4|^7.0",
 						},
 						RequireDev: map[string]string{
 							"phpunit/phpunit": "^10.5",
 							"symfony/process": "^6.4|^7.0",
 						},
+						Suggest: map[string]string{
+							"psr/log":      "For using the console logger",
+							"ext-mbstring": "For faster string handling",
+						},
+						Conflict: map[string]string{
+							"symfony/dotenv": "<6.4",
+						},
+						Replace: map[string]string{
+							"symfony/console-legacy": "self.version",
+						},
+						Provide: map[string]string{
+							"psr/log-implementation": "3.0.0",
+						},
 					},
 				},
 			},
@@ -143,13 +182,16 @@ func TestFetchDependencies(t *testing.T) {
 		t.Fatalf("FetchDependencies failed: %v", err)
 	}
 
-	// Should have 4 deps (2 runtime excluding php, 2 dev)
-	if len(deps) != 4 {
-		t.Fatalf("expected 4 dependencies, got %d", len(deps))
+	// Should have 5 deps (2 runtime excluding php, 2 dev, 1 suggest excluding ext-)
+	// and the 3 links
+	if len(deps) != 8 {
+		t.Fatalf("expected 8 dependencies, got %d", len(deps))
 	}
 
 	runtimeCount := 0
 	devCount := 0
+	optionalCount := 0
+	relations := make(map[string]core.Relation)
 	for _, d := range deps {
 		// php should be filtered out
 		if d.Name == "php" {
@@ -160,6 +202,26 @@ func TestFetchDependencies(t *testing.T) {
 			runtimeCount++
 		case core.Development:
 			devCount++
+		case core.Optional:
+			optionalCount++
+			if d.Name != "psr/log" || !d.Optional || d.Metadata["reason"] != "For using the console logger" {
+				t.Errorf("unexpected suggested dependency: %+v", d)
+			}
+		}
+		if d.Relation != "" && d.Scope != "" {
+			t.Errorf("%s link %s has scope %q", d.Relation, d.Name, d.Scope)
+		}
+		relations[d.Name] = d.Relation
+	}
+
+	for name, want := range map[string]core.Relation{
+		"symfony/dotenv":         core.RelationConflict,
+		"symfony/console-legacy": core.RelationReplace,
+		"psr/log-implementation": core.RelationProvide,
+		"symfony/string":         "",
+	} {
+		if relations[name] != want {
+			t.Errorf("%s: relation %q, want %q", name, relations[name], want)
 		}
 	}
 
@@ -169,6 +231,9 @@ func TestFetchDependencies(t *testing.T) {
 	if devCount != 2 {
 		t.Errorf("expected 2 dev deps, got %d", devCount)
 	}
+	if optionalCount != 1 {
+		t.Errorf("expected 1 optional dep, got %d", optionalCount)
+	}
 }
 
 func TestFetchMaintainers(t *testing.T) {
@@ -231,3 +296,45 @@ func TestEcosystem(t *testing.T) {
 		t.Errorf("expected ecosystem 'composer', got %q", reg.Ecosystem())
 	}
 }
+
+func TestFetchStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		abandoned := map[string]interface{}{
+			"/packages/fzaninotto/faker.json":        true,
+			"/packages/swiftmailer/swiftmailer.json": "symfony/mailer",
+			"/packages/laravel/framework.json":       false,
+		}[r.URL.Path]
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"package": map[string]interface{}{
+				"name":      strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/packages/"), ".json"),
+				"abandoned": abandoned,
+				"versions": map[string]interface{}{
+					"v1.0.0": map[string]interface{}{"version": "v1.0.0", "time": "2024-01-01T00:00:00+00:00"},
+				},
+			},
+		})
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	ctx := context.Background()
+
+	tests := []struct {
+		name        string
+		state       core.PackageState
+		replacement string
+	}{
+		{"fzaninotto/faker", core.PackageDeprecated, ""},
+		{"swiftmailer/swiftmailer", core.PackageDeprecated, "symfony/mailer"},
+		{"laravel/framework", core.PackageActive, ""},
+	}
+	for _, tt := range tests {
+		status, err := reg.FetchStatus(ctx, tt.name)
+		if err != nil {
+			t.Fatalf("FetchStatus(%s) failed: %v", tt.name, err)
+		}
+		if status.State != tt.state || status.Replacement != tt.replacement {
+			t.Errorf("FetchStatus(%s) = %+v, want %s %q", tt.name, status, tt.state, tt.replacement)
+		}
+	}
+}
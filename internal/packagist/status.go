@@ -0,0 +1,42 @@
+package packagist
+
+import (
+	"context"
+
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+// FetchStatus reports abandoned packages as deprecated, along with the
+// replacement package Packagist suggests if the maintainer named one.
+func (r *Registry) FetchStatus(ctx context.Context, name string) (*core.PackageStatus, error) {
+	pkg, err := r.FetchPackage(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	switch abandoned := pkg.Metadata["abandoned"].(type) {
+	case string:
+		return &core.PackageStatus{
+			State:       core.PackageDeprecated,
+			Message:     "abandoned",
+			Replacement: abandoned,
+			Repository:  pkg.Repository,
+		}, nil
+	case bool:
+		if abandoned {
+			return &core.PackageStatus{
+				State:      core.PackageDeprecated,
+				Message:    "abandoned",
+				Repository: pkg.Repository,
+			}, nil
+		}
+	}
+
+	versions, err := r.FetchVersions(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	status := core.StatusFromVersions(versions)
+	status.Repository = pkg.Repository
+	return status, nil
+}
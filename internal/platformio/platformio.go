@@ -0,0 +1,308 @@
+// Package platformio provides a registry client for PlatformIO libraries.
+package platformio
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/git-pkgs/registries/internal/core"
+	"github.com/git-pkgs/registries/internal/urlparser"
+)
+
+const (
+	DefaultURL = "https://api.registry.platformio.org"
+	ecosystem  = "platformio"
+)
+
+func init() {
+	core.Register(ecosystem, DefaultURL, func(baseURL string, client *core.Client) core.Registry {
+		return New(baseURL, client)
+	})
+}
+
+type Registry struct {
+	baseURL string
+	client  *core.Client
+	urls    *URLs
+}
+
+func New(baseURL string, client *core.Client) *Registry {
+	if baseURL == "" {
+		baseURL = DefaultURL
+	}
+	r := &Registry{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client:  client,
+	}
+	r.urls = &URLs{baseURL: r.baseURL}
+	return r
+}
+
+func (r *Registry) Ecosystem() string {
+	return ecosystem
+}
+
+func (r *Registry) URLs() core.URLBuilder {
+	return r.urls
+}
+
+type packageResponse struct {
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	Owner struct {
+		Username string `json:"username"`
+		Type     string `json:"type"`
+	} `json:"owner"`
+	Description    string            `json:"description"`
+	Keywords       []string          `json:"keywords"`
+	PopularityRank int               `json:"popularity_rank"`
+	Version        versionResponse   `json:"version"`
+	Versions       []versionResponse `json:"versions"`
+}
+
+type versionResponse struct {
+	Name       string         `json:"name"`
+	ReleasedAt time.Time      `json:"released_at"`
+	Files      []fileResponse `json:"files"`
+	Manifest   struct {
+		Homepage   string   `json:"homepage"`
+		License    string   `json:"license"`
+		Frameworks []string `json:"frameworks"`
+		Platforms  []string `json:"platforms"`
+		Repository struct {
+			Type string `json:"type"`
+			URL  string `json:"url"`
+		} `json:"repository"`
+		Authors []struct {
+			Name       string `json:"name"`
+			Email      string `json:"email"`
+			URL        string `json:"url"`
+			Maintainer bool   `json:"maintainer"`
+		} `json:"authors"`
+	} `json:"manifest"`
+}
+
+type fileResponse struct {
+	Name     string `json:"name"`
+	Size     int64  `json:"size"`
+	Checksum struct {
+		SHA256 string `json:"sha256"`
+	} `json:"checksum"`
+	DownloadURL  string               `json:"download_url"`
+	System       any                  `json:"system"`
+	Dependencies []dependencyResponse `json:"dependencies"`
+}
+
+type dependencyResponse struct {
+	Owner        string   `json:"owner"`
+	Name         string   `json:"name"`
+	Requirements string   `json:"requirements"`
+	Platforms    []string `json:"platforms"`
+	Frameworks   []string `json:"frameworks"`
+}
+
+// splitName splits "bblanchon/ArduinoJson" into its owner and library name.
+func splitName(name string) (string, string, error) {
+	owner, lib, ok := strings.Cut(name, "/")
+	if !ok || owner == "" || lib == "" {
+		return "", "", fmt.Errorf("platformio package name must be owner/name: %q", name)
+	}
+	return owner, lib, nil
+}
+
+// fetchPackage fetches a library. With a version, the response's version
+// field describes that release instead of the latest one.
+func (r *Registry) fetchPackage(ctx context.Context, name, version string) (*packageResponse, error) {
+	owner, lib, err := splitName(name)
+	if err != nil {
+		return nil, err
+	}
+	pkgURL := fmt.Sprintf("%s/v3/packages/%s/library/%s", r.baseURL, owner, url.PathEscape(lib))
+	if version != "" {
+		pkgURL += "?version=" + url.QueryEscape(version)
+	}
+
+	var resp packageResponse
+	if err := r.client.GetJSON(ctx, pkgURL, &resp); err != nil {
+		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
+			return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name, Version: version}
+		}
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package, error) {
+	resp, err := r.fetchPackage(ctx, name, "")
+	if err != nil {
+		return nil, err
+	}
+	manifest := resp.Version.Manifest
+
+	repository := urlparser.Parse(manifest.Repository.URL)
+	if repository == "" {
+		repository = urlparser.Parse(manifest.Homepage)
+	}
+
+	return &core.Package{
+		Name:          resp.Owner.Username + "/" + resp.Name,
+		Description:   resp.Description,
+		Homepage:      manifest.Homepage,
+		Repository:    repository,
+		Licenses:      manifest.License,
+		Keywords:      resp.Keywords,
+		Namespace:     resp.Owner.Username,
+		LatestVersion: resp.Version.Name,
+		Metadata: map[string]any{
+			"frameworks":      manifest.Frameworks,
+			"platforms":       manifest.Platforms,
+			"popularity_rank": resp.PopularityRank,
+		},
+	}, nil
+}
+
+func (r *Registry) FetchVersions(ctx context.Context, name string) ([]core.Version, error) {
+	resp, err := r.fetchPackage(ctx, name, "")
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]core.Version, 0, len(resp.Versions))
+	for _, v := range resp.Versions {
+		version := core.Version{
+			Number:      v.Name,
+			PublishedAt: v.ReleasedAt,
+			Licenses:    v.Manifest.License,
+		}
+		if f := primaryFile(v.Files); f != nil {
+			if f.Checksum.SHA256 != "" {
+				version.Integrity = core.HexIntegrity("sha256", f.Checksum.SHA256)
+			}
+			version.Metadata = map[string]any{
+				"download_url": f.DownloadURL,
+				"size":         f.Size,
+			}
+			version.Size = f.Size
+		}
+		versions = append(versions, version)
+	}
+
+	return versions, nil
+}
+
+// primaryFile returns the platform-independent archive of a release, or the
+// first archive when every file targets a specific system.
+func primaryFile(files []fileResponse) *fileResponse {
+	for i := range files {
+		if s, ok := files[i].System.(string); ok && s == "*" {
+			return &files[i]
+		}
+	}
+	if len(files) > 0 {
+		return &files[0]
+	}
+	return nil
+}
+
+func (r *Registry) FetchDependencies(ctx context.Context, name, version string) ([]core.Dependency, error) {
+	resp, err := r.fetchPackage(ctx, name, version)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Version.Name != version {
+		return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name, Version: version}
+	}
+
+	f := primaryFile(resp.Version.Files)
+	if f == nil {
+		return nil, nil
+	}
+
+	deps := make([]core.Dependency, 0, len(f.Dependencies))
+	for _, d := range f.Dependencies {
+		depName := d.Name
+		if d.Owner != "" {
+			depName = d.Owner + "/" + d.Name
+		}
+		dep := core.Dependency{
+			Name:         depName,
+			Requirements: d.Requirements,
+			Scope:        core.Runtime,
+		}
+		if len(d.Platforms) > 0 || len(d.Frameworks) > 0 {
+			dep.Target = strings.Join(append(append([]string{}, d.Platforms...), d.Frameworks...), ",")
+			dep.Metadata = map[string]any{
+				"platforms":  d.Platforms,
+				"frameworks": d.Frameworks,
+			}
+		}
+		deps = append(deps, dep)
+	}
+
+	return deps, nil
+}
+
+func (r *Registry) FetchMaintainers(ctx context.Context, name string) ([]core.Maintainer, error) {
+	resp, err := r.fetchPackage(ctx, name, "")
+	if err != nil {
+		return nil, err
+	}
+
+	var maintainers []core.Maintainer
+	if resp.Owner.Username != "" {
+		maintainers = append(maintainers, core.Maintainer{
+			Login: resp.Owner.Username,
+			Role:  "owner",
+		})
+	}
+	for _, a := range resp.Version.Manifest.Authors {
+		role := "author"
+		if a.Maintainer {
+			role = "maintainer"
+		}
+		maintainers = append(maintainers, core.Maintainer{
+			Name:  a.Name,
+			Email: a.Email,
+			URL:   a.URL,
+			Role:  role,
+		})
+	}
+
+	return maintainers, nil
+}
+
+type URLs struct {
+	baseURL string
+}
+
+func (u *URLs) Registry(name, version string) string {
+	if version != "" {
+		return fmt.Sprintf("https://registry.platformio.org/libraries/%s/versions/%s", name, version)
+	}
+	return fmt.Sprintf("https://registry.platformio.org/libraries/%s", name)
+}
+
+func (u *URLs) Download(name, version string) string {
+	if version == "" {
+		return ""
+	}
+	owner, lib, err := splitName(name)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("https://dl.registry.platformio.org/download/%s/library/%s/%s/%s-%s.tar.gz", owner, lib, version, lib, version)
+}
+
+func (u *URLs) Documentation(name, version string) string {
+	return u.Registry(name, version)
+}
+
+func (u *URLs) PURL(name, version string) string {
+	if version != "" {
+		return fmt.Sprintf("pkg:platformio/%s@%s", name, version)
+	}
+	return fmt.Sprintf("pkg:platformio/%s", name)
+}
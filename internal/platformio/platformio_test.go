@@ -0,0 +1,206 @@
+package platformio
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+const latestJSON = `{
+	"name": "ArduinoJson",
+	"type": "library",
+	"owner": {"username": "bblanchon", "type": "user"},
+	"description": "A simple and efficient JSON library for embedded C++.",
+	"keywords": ["json", "rest"],
+	"popularity_rank": 1,
+	"version": {
+		"name": "7.0.4",
+		"released_at": "2024-03-12T09:00:00Z",
+		"manifest": {
+			"homepage": "https://arduinojson.org/",
+			"license": "MIT",
+			"frameworks": ["*"],
+			"platforms": ["*"],
+			"repository": {"type": "git", "url": "https://github.com/bblanchon/ArduinoJson.git"},
+			"authors": [{"name": "Benoit Blanchon", "url": "https://blog.benoitblanchon.fr", "maintainer": true}]
+		},
+		"files": [{
+			"name": "ArduinoJson-7.0.4.tar.gz",
+			"size": 123456,
+			"checksum": {"sha256": "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"},
+			"download_url": "https://dl.registry.platformio.org/download/bblanchon/library/ArduinoJson/7.0.4/ArduinoJson-7.0.4.tar.gz",
+			"system": "*",
+			"dependencies": []
+		}]
+	},
+	"versions": [
+		{"name": "7.0.4", "released_at": "2024-03-12T09:00:00Z", "files": [{"checksum": {"sha256": "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"}, "system": "*", "size": 123456}]},
+		{"name": "6.21.5", "released_at": "2024-01-10T09:00:00Z", "files": []}
+	]
+}`
+
+func TestFetchPackage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v3/packages/bblanchon/library/ArduinoJson" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+			w.WriteHeader(404)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(latestJSON))
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	pkg, err := reg.FetchPackage(context.Background(), "bblanchon/ArduinoJson")
+	if err != nil {
+		t.Fatalf("FetchPackage failed: %v", err)
+	}
+
+	if pkg.Name != "bblanchon/ArduinoJson" {
+		t.Errorf("expected name 'bblanchon/ArduinoJson', got %q", pkg.Name)
+	}
+	if pkg.Repository != "https://github.com/bblanchon/ArduinoJson" {
+		t.Errorf("unexpected repository: %q", pkg.Repository)
+	}
+	if pkg.Licenses != "MIT" {
+		t.Errorf("unexpected licenses: %q", pkg.Licenses)
+	}
+	if pkg.LatestVersion != "7.0.4" {
+		t.Errorf("unexpected latest version: %q", pkg.LatestVersion)
+	}
+}
+
+func TestFetchPackageNotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(404)
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	if _, err := reg.FetchPackage(context.Background(), "bblanchon/Missing"); !errors.Is(err, core.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	if _, err := reg.FetchPackage(context.Background(), "ArduinoJson"); err == nil {
+		t.Error("expected an error for a name without an owner")
+	}
+}
+
+func TestFetchVersions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(latestJSON))
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	versions, err := reg.FetchVersions(context.Background(), "bblanchon/ArduinoJson")
+	if err != nil {
+		t.Fatalf("FetchVersions failed: %v", err)
+	}
+
+	if len(versions) != 2 {
+		t.Fatalf("expected 2 versions, got %d", len(versions))
+	}
+	if versions[0].Number != "7.0.4" || versions[0].Integrity != "sha256-ungWv48Bz+pBQUDeXa4iI7ADYaOWF3qctBD/YfIAFa0=" {
+		t.Errorf("unexpected first version: %+v", versions[0])
+	}
+	if versions[1].PublishedAt.IsZero() {
+		t.Error("expected published_at on the second version")
+	}
+}
+
+func TestFetchDependencies(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("version") != "1.2.0" {
+			t.Errorf("expected version query, got %q", r.URL.RawQuery)
+		}
+		_, _ = w.Write([]byte(`{
+			"name": "FastLED-Helpers",
+			"owner": {"username": "someone"},
+			"version": {
+				"name": "1.2.0",
+				"files": [
+					{"system": ["linux_x86_64"], "dependencies": []},
+					{"system": "*", "dependencies": [
+						{"owner": "fastled", "name": "FastLED", "requirements": "^3.6.0"},
+						{"name": "Wire", "platforms": ["atmelavr"], "frameworks": ["arduino"]}
+					]}
+				]
+			}
+		}`))
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	deps, err := reg.FetchDependencies(context.Background(), "someone/FastLED-Helpers", "1.2.0")
+	if err != nil {
+		t.Fatalf("FetchDependencies failed: %v", err)
+	}
+
+	if len(deps) != 2 {
+		t.Fatalf("expected 2 dependencies, got %d", len(deps))
+	}
+	if deps[0].Name != "fastled/FastLED" || deps[0].Requirements != "^3.6.0" {
+		t.Errorf("unexpected first dependency: %+v", deps[0])
+	}
+	if deps[1].Name != "Wire" || deps[1].Target != "atmelavr,arduino" {
+		t.Errorf("unexpected second dependency: %+v", deps[1])
+	}
+}
+
+func TestFetchMaintainers(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(latestJSON))
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	maintainers, err := reg.FetchMaintainers(context.Background(), "bblanchon/ArduinoJson")
+	if err != nil {
+		t.Fatalf("FetchMaintainers failed: %v", err)
+	}
+
+	if len(maintainers) != 2 {
+		t.Fatalf("expected 2 maintainers, got %d", len(maintainers))
+	}
+	if maintainers[0].Login != "bblanchon" || maintainers[0].Role != "owner" {
+		t.Errorf("unexpected owner: %+v", maintainers[0])
+	}
+	if maintainers[1].Name != "Benoit Blanchon" || maintainers[1].Role != "maintainer" {
+		t.Errorf("unexpected author: %+v", maintainers[1])
+	}
+}
+
+func TestURLBuilder(t *testing.T) {
+	reg := New("", nil)
+	urls := reg.URLs()
+
+	tests := []struct {
+		name     string
+		fn       func() string
+		expected string
+	}{
+		{"registry", func() string { return urls.Registry("bblanchon/ArduinoJson", "") }, "https://registry.platformio.org/libraries/bblanchon/ArduinoJson"},
+		{"download", func() string { return urls.Download("bblanchon/ArduinoJson", "7.0.4") }, "https://dl.registry.platformio.org/download/bblanchon/library/ArduinoJson/7.0.4/ArduinoJson-7.0.4.tar.gz"},
+		{"purl", func() string { return urls.PURL("bblanchon/ArduinoJson", "7.0.4") }, "pkg:platformio/bblanchon/ArduinoJson@7.0.4"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.fn(); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestEcosystem(t *testing.T) {
+	reg := New("", nil)
+	if reg.Ecosystem() != "platformio" {
+		t.Errorf("expected ecosystem 'platformio', got %q", reg.Ecosystem())
+	}
+}
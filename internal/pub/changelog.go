@@ -0,0 +1,40 @@
+package pub
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/git-pkgs/registries/internal/core"
+	"github.com/git-pkgs/registries/internal/urlparser"
+)
+
+// FetchChangelog returns the version's section of the CHANGELOG.md in its
+// archive, the file pub.dev shows on a package's Changelog tab. Packages
+// published without one fall back to the releases and changelog of the
+// repository their pubspec names. If version is empty, the latest version
+// is used.
+func (r *Registry) FetchChangelog(ctx context.Context, name, version string) (*core.Document, error) {
+	info, archive, err := r.fetchArchive(ctx, name, version)
+	if err != nil {
+		return nil, err
+	}
+
+	filename, content, err := rootFileFromArchive(archive, "changelog")
+	if err != nil {
+		return nil, fmt.Errorf("pub: reading archive of %s %s: %w", name, info.Version, err)
+	}
+	if filename == "" {
+		repository := urlparser.Parse(info.Pubspec.Repository)
+		if repository == "" {
+			repository = urlparser.Parse(info.Pubspec.Homepage)
+		}
+		return core.RepositoryChangelog(ctx, r.client, repository, info.Version)
+	}
+
+	return &core.Document{
+		Content:     core.ChangelogSection(content, info.Version),
+		ContentType: core.ContentTypeFromFilename(filename),
+		Filename:    filename,
+		URL:         info.ArchiveURL,
+	}, nil
+}
@@ -0,0 +1,76 @@
+package pub
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+type publisherInfoResponse struct {
+	Description  string `json:"description"`
+	WebsiteURL   string `json:"websiteUrl"`
+	ContactEmail string `json:"contactEmail"`
+}
+
+type searchResponse struct {
+	Packages []struct {
+		Package string `json:"package"`
+	} `json:"packages"`
+	Next string `json:"next"`
+}
+
+// FetchNamespace returns a pub.dev publisher, such as "dart.dev". Every
+// publisher is named after a domain its admins proved they own, so
+// publishers are always verified. pub.dev doesn't list a publisher's
+// members; its packages, the first page of a publisher search, are in
+// Metadata["packages"].
+func (r *Registry) FetchNamespace(ctx context.Context, namespace string) (*core.Namespace, error) {
+	var info publisherInfoResponse
+	if err := r.client.GetJSON(ctx, fmt.Sprintf("%s/api/publishers/%s", r.baseURL, url.PathEscape(namespace)), &info); err != nil {
+		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
+			return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: namespace}
+		}
+		return nil, err
+	}
+
+	metadata := map[string]any{}
+	if info.Description != "" {
+		metadata["description"] = info.Description
+	}
+	if info.WebsiteURL != "" {
+		metadata["website"] = info.WebsiteURL
+	}
+	if info.ContactEmail != "" {
+		metadata["email"] = info.ContactEmail
+	}
+	if packages := r.publisherPackages(ctx, namespace); len(packages) > 0 {
+		metadata["packages"] = packages
+	}
+
+	return &core.Namespace{
+		Ecosystem: ecosystem,
+		Name:      namespace,
+		Kind:      core.NamespacePublisher,
+		URL:       fmt.Sprintf("%s/publishers/%s", r.baseURL, namespace),
+		Verified:  true,
+		Metadata:  metadata,
+	}, nil
+}
+
+// publisherPackages returns the first page of a publisher's packages. As
+// with fetchScore, self-hosted servers may not implement search, so any
+// failure returns nil.
+func (r *Registry) publisherPackages(ctx context.Context, publisher string) []string {
+	var resp searchResponse
+	endpoint := fmt.Sprintf("%s/api/search?q=%s", r.baseURL, url.QueryEscape("publisher:"+publisher))
+	if err := r.client.GetJSON(ctx, endpoint, &resp); err != nil {
+		return nil
+	}
+	packages := make([]string, 0, len(resp.Packages))
+	for _, p := range resp.Packages {
+		packages = append(packages, p.Package)
+	}
+	return packages
+}
@@ -55,9 +55,10 @@ type packageResponse struct {
 }
 
 type versionInfo struct {
-	Version   string    `json:"version"`
-	Published time.Time `json:"published"`
-	Pubspec   pubspec   `json:"pubspec"`
+	Version    string    `json:"version"`
+	Published  time.Time `json:"published"`
+	Pubspec    pubspec   `json:"pubspec"`
+	ArchiveURL string    `json:"archive_url"`
 }
 
 type pubspec struct {
@@ -88,13 +89,37 @@ func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package
 		repository = urlparser.Parse(latest.Homepage)
 	}
 
+	// pub.dev's latest is the newest stable release unless there is none
+	stable := resp.Latest.Version
+	var numbers []string
+	var first, last time.Time
+	for _, v := range resp.Versions {
+		numbers = append(numbers, v.Version)
+		if !v.Published.IsZero() && (first.IsZero() || v.Published.Before(first)) {
+			first = v.Published
+		}
+		if v.Published.After(last) {
+			last = v.Published
+		}
+	}
+
+	if core.IsPrerelease(ecosystem, stable) {
+		stable = core.LatestStableOf(ecosystem, numbers)
+	}
+
+	metadata, popularity := r.fetchScore(ctx, name)
 	return &core.Package{
-		Name:          resp.Name,
-		Description:   latest.Description,
-		Homepage:      latest.Homepage,
-		Repository:    repository,
-		Licenses:      latest.License,
-		LatestVersion: resp.Latest.Version,
+		Name:                resp.Name,
+		Description:         latest.Description,
+		Homepage:            latest.Homepage,
+		Repository:          repository,
+		Licenses:            latest.License,
+		LatestVersion:       resp.Latest.Version,
+		LatestStableVersion: stable,
+		Metadata:            metadata,
+		Popularity:          popularity,
+		CreatedAt:           first,
+		LatestReleasedAt:    last,
 	}, nil
 }
 
@@ -185,11 +210,6 @@ func formatRequirement(req interface{}) string {
 	return ""
 }
 
-func (r *Registry) FetchMaintainers(ctx context.Context, name string) ([]core.Maintainer, error) {
-	// pub.dev API doesn't expose maintainers in the standard package endpoint
-	return nil, nil
-}
-
 type URLs struct {
 	baseURL string
 }
@@ -1,8 +1,12 @@
 package pub
 
 import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -12,6 +16,10 @@ import (
 
 func TestFetchPackage(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/packages/flutter/score" {
+			_, _ = w.Write([]byte(`{"grantedPoints":150,"maxPoints":160,"likeCount":6000,"downloadCount30Days":1200000,"tags":["sdk:flutter","publisher:flutter.dev","is:dart3-compatible"]}`))
+			return
+		}
 		if r.URL.Path != "/api/packages/flutter" {
 			t.Errorf("unexpected path: %s", r.URL.Path)
 			w.WriteHeader(404)
@@ -52,6 +60,66 @@ func TestFetchPackage(t *testing.T) {
 	if pkg.Licenses != "BSD-3-Clause" {
 		t.Errorf("unexpected licenses: %q", pkg.Licenses)
 	}
+	if pkg.Metadata["points"] != 150 || pkg.Metadata["max_points"] != 160 || pkg.Metadata["likes"] != 6000 {
+		t.Errorf("unexpected score metadata: %v", pkg.Metadata)
+	}
+	if pkg.Metadata["downloads_30_days"] != 1200000 || pkg.Metadata["publisher"] != "flutter.dev" {
+		t.Errorf("unexpected score metadata: %v", pkg.Metadata)
+	}
+	if want := (core.Popularity{DownloadsRecent: 1200000, RecentDays: 30, Stars: 6000}); pkg.Popularity == nil || *pkg.Popularity != want {
+		t.Errorf("Popularity = %+v, want %+v", pkg.Popularity, want)
+	}
+}
+
+func TestFetchPackageWithoutScore(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/packages/private_pkg" {
+			w.WriteHeader(404)
+			return
+		}
+		_, _ = w.Write([]byte(`{"name":"private_pkg","latest":{"version":"1.0.0","pubspec":{}}}`))
+	}))
+	defer server.Close()
+
+	pkg, err := New(server.URL, core.DefaultClient()).FetchPackage(context.Background(), "private_pkg")
+	if err != nil {
+		t.Fatalf("FetchPackage failed: %v", err)
+	}
+	if pkg.Metadata != nil || pkg.Popularity != nil {
+		t.Errorf("expected no score metadata, got %v, %+v", pkg.Metadata, pkg.Popularity)
+	}
+}
+
+func TestFetchMaintainers(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/packages/http/publisher":
+			_, _ = w.Write([]byte(`{"publisherId":"dart.dev"}`))
+		case "/api/packages/left_pad/publisher":
+			_, _ = w.Write([]byte(`{"publisherId":null}`))
+		default:
+			w.WriteHeader(404)
+		}
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	maintainers, err := reg.FetchMaintainers(context.Background(), "http")
+	if err != nil {
+		t.Fatalf("FetchMaintainers failed: %v", err)
+	}
+	if len(maintainers) != 1 || maintainers[0].Login != "dart.dev" || maintainers[0].URL != server.URL+"/publishers/dart.dev" {
+		t.Errorf("unexpected maintainers: %+v", maintainers)
+	}
+
+	maintainers, err = reg.FetchMaintainers(context.Background(), "left_pad")
+	if err != nil || maintainers != nil {
+		t.Errorf("expected no maintainers for an unpublished package, got %+v, %v", maintainers, err)
+	}
+
+	if _, err := reg.FetchMaintainers(context.Background(), "missing"); err == nil {
+		t.Error("expected an error for a missing package")
+	}
 }
 
 func TestFetchVersions(t *testing.T) {
@@ -224,3 +292,93 @@ func TestEcosystem(t *testing.T) {
 		t.Errorf("expected ecosystem 'pub', got %q", reg.Ecosystem())
 	}
 }
+
+func TestFetchReadme(t *testing.T) {
+	var archive bytes.Buffer
+	gz := gzip.NewWriter(&archive)
+	tw := tar.NewWriter(gz)
+	for name, content := range map[string]string{
+		"pubspec.yaml":   "name: http",
+		"README":         "plain",
+		"README.md":      "# http",
+		"doc/README.txt": "nested",
+	} {
+		_ = tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(content)), Typeflag: tar.TypeReg})
+		_, _ = tw.Write([]byte(content))
+	}
+	_ = tw.Close()
+	_ = gz.Close()
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/packages/http":
+			latest := map[string]interface{}{"version": "1.2.0", "archive_url": server.URL + "/packages/http/versions/1.2.0.tar.gz"}
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"name":     "http",
+				"latest":   latest,
+				"versions": []interface{}{latest},
+			})
+		case "/packages/http/versions/1.2.0.tar.gz":
+			_, _ = w.Write(archive.Bytes())
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	doc, err := reg.FetchReadme(context.Background(), "http", "")
+	if err != nil {
+		t.Fatalf("FetchReadme failed: %v", err)
+	}
+	if doc.Filename != "README.md" || doc.Content != "# http" || doc.ContentType != core.Markdown {
+		t.Errorf("unexpected document: %+v", doc)
+	}
+
+	if _, err := reg.FetchReadme(context.Background(), "http", "0.1.0"); !errors.Is(err, core.ErrNotFound) {
+		t.Errorf("expected not found for unknown version, got %v", err)
+	}
+}
+
+func TestFetchChangelog(t *testing.T) {
+	var archive bytes.Buffer
+	gz := gzip.NewWriter(&archive)
+	tw := tar.NewWriter(gz)
+	for name, content := range map[string]string{
+		"pubspec.yaml": "name: http",
+		"CHANGELOG.md": "## 1.2.0\n\n* Adds retries.\n\n## 1.1.0\n\n* Initial release.\n",
+	} {
+		_ = tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(content)), Typeflag: tar.TypeReg})
+		_, _ = tw.Write([]byte(content))
+	}
+	_ = tw.Close()
+	_ = gz.Close()
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/packages/http":
+			latest := map[string]interface{}{"version": "1.2.0", "archive_url": server.URL + "/packages/http/versions/1.2.0.tar.gz"}
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"name":     "http",
+				"latest":   latest,
+				"versions": []interface{}{latest},
+			})
+		case "/packages/http/versions/1.2.0.tar.gz":
+			_, _ = w.Write(archive.Bytes())
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	doc, err := reg.FetchChangelog(context.Background(), "http", "1.2.0")
+	if err != nil {
+		t.Fatalf("FetchChangelog failed: %v", err)
+	}
+	if doc.Filename != "CHANGELOG.md" || doc.Content != "## 1.2.0\n\n* Adds retries." {
+		t.Errorf("unexpected document: %+v", doc)
+	}
+}
@@ -0,0 +1,127 @@
+package pub
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+// maxReadmeSize bounds how much of a README is read from an archive.
+const maxReadmeSize = 4 << 20
+
+// FetchReadme returns the README from the version's archive. pub.dev has no
+// endpoint for it, so the archive is downloaded and searched for a README at
+// its root. If version is empty, the latest version is used.
+func (r *Registry) FetchReadme(ctx context.Context, name, version string) (*core.Document, error) {
+	info, archive, err := r.fetchArchive(ctx, name, version)
+	if err != nil {
+		return nil, err
+	}
+
+	filename, content, err := readmeFromArchive(archive)
+	if err != nil {
+		return nil, fmt.Errorf("pub: reading archive of %s %s: %w", name, info.Version, err)
+	}
+	if filename == "" {
+		return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name, Version: info.Version}
+	}
+
+	return &core.Document{
+		Content:     content,
+		ContentType: core.ContentTypeFromFilename(filename),
+		Filename:    filename,
+		URL:         info.ArchiveURL,
+	}, nil
+}
+
+// fetchArchive downloads the archive of a version, or of the latest
+// version if version is empty.
+func (r *Registry) fetchArchive(ctx context.Context, name, version string) (versionInfo, []byte, error) {
+	url := fmt.Sprintf("%s/api/packages/%s", r.baseURL, name)
+
+	var resp packageResponse
+	if err := r.client.GetJSON(ctx, url, &resp); err != nil {
+		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
+			return versionInfo{}, nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name}
+		}
+		return versionInfo{}, nil, err
+	}
+
+	info := resp.Latest
+	if version != "" {
+		found := false
+		for _, v := range resp.Versions {
+			if v.Version == version {
+				info, found = v, true
+				break
+			}
+		}
+		if !found {
+			return versionInfo{}, nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name, Version: version}
+		}
+	}
+	if info.ArchiveURL == "" {
+		return versionInfo{}, nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name, Version: version}
+	}
+
+	archive, err := r.client.GetArtifact(ctx, info.ArchiveURL)
+	if err != nil {
+		return versionInfo{}, nil, err
+	}
+	return info, archive, nil
+}
+
+// readmeFromArchive returns the name and content of the README at the root
+// of a gzipped tarball, preferring README.md over other extensions.
+func readmeFromArchive(archive []byte) (string, string, error) {
+	return rootFileFromArchive(archive, "readme")
+}
+
+// rootFileFromArchive returns the name and content of the file at the root
+// of a gzipped tarball whose name without its extension is base, in any
+// case, preferring a .md file over other extensions.
+func rootFileFromArchive(archive []byte, base string) (string, string, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		return "", "", err
+	}
+	defer func() { _ = gz.Close() }()
+
+	var bestName, bestContent string
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", "", err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		name := strings.TrimPrefix(hdr.Name, "./")
+		if strings.Contains(name, "/") {
+			continue
+		}
+		if !strings.EqualFold(strings.TrimSuffix(name, path.Ext(name)), base) {
+			continue
+		}
+		if bestName != "" && !strings.EqualFold(path.Ext(name), ".md") {
+			continue
+		}
+		data, err := io.ReadAll(io.LimitReader(tr, maxReadmeSize))
+		if err != nil {
+			return "", "", err
+		}
+		bestName, bestContent = name, string(data)
+	}
+	return bestName, bestContent, nil
+}
@@ -0,0 +1,97 @@
+package pub
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+// scoreResponse is pub.dev's /api/packages/<name>/score. popularityScore
+// was retired in favour of download counts and is absent for newer data.
+type scoreResponse struct {
+	GrantedPoints       *int     `json:"grantedPoints"`
+	MaxPoints           *int     `json:"maxPoints"`
+	LikeCount           *int     `json:"likeCount"`
+	DownloadCount30Days *int     `json:"downloadCount30Days"`
+	PopularityScore     *float64 `json:"popularityScore"`
+	Tags                []string `json:"tags"`
+}
+
+type publisherResponse struct {
+	PublisherID string `json:"publisherId"`
+}
+
+// fetchScore returns a package's score as Package metadata, and its likes
+// and downloads as its Popularity. Self-hosted pub servers don't implement
+// the endpoint, so any failure returns nil rather than failing
+// FetchPackage.
+func (r *Registry) fetchScore(ctx context.Context, name string) (map[string]any, *core.Popularity) {
+	var resp scoreResponse
+	if err := r.client.GetJSON(ctx, fmt.Sprintf("%s/api/packages/%s/score", r.baseURL, name), &resp); err != nil {
+		return nil, nil
+	}
+
+	var popularity *core.Popularity
+	if resp.LikeCount != nil || resp.DownloadCount30Days != nil {
+		popularity = &core.Popularity{}
+		if resp.LikeCount != nil {
+			popularity.Stars = int64(*resp.LikeCount)
+		}
+		if resp.DownloadCount30Days != nil {
+			popularity.DownloadsRecent = int64(*resp.DownloadCount30Days)
+			popularity.RecentDays = 30
+		}
+	}
+
+	m := make(map[string]any)
+	if resp.GrantedPoints != nil {
+		m["points"] = *resp.GrantedPoints
+	}
+	if resp.MaxPoints != nil {
+		m["max_points"] = *resp.MaxPoints
+	}
+	if resp.LikeCount != nil {
+		m["likes"] = *resp.LikeCount
+	}
+	if resp.DownloadCount30Days != nil {
+		m["downloads_30_days"] = *resp.DownloadCount30Days
+	}
+	if resp.PopularityScore != nil {
+		m["popularity"] = *resp.PopularityScore
+	}
+	if len(resp.Tags) > 0 {
+		m["tags"] = resp.Tags
+	}
+	for _, tag := range resp.Tags {
+		if id, ok := strings.CutPrefix(tag, "publisher:"); ok {
+			m["publisher"] = id
+		}
+	}
+	if len(m) == 0 {
+		return nil, popularity
+	}
+	return m, popularity
+}
+
+// FetchMaintainers returns the package's verified publisher, the domain
+// that owns it on pub.dev. Packages without a publisher, which are owned by
+// individual uploaders pub.dev doesn't list, return nil.
+func (r *Registry) FetchMaintainers(ctx context.Context, name string) ([]core.Maintainer, error) {
+	var resp publisherResponse
+	if err := r.client.GetJSON(ctx, fmt.Sprintf("%s/api/packages/%s/publisher", r.baseURL, name), &resp); err != nil {
+		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
+			return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name}
+		}
+		return nil, err
+	}
+	if resp.PublisherID == "" {
+		return nil, nil
+	}
+	return []core.Maintainer{{
+		Login: resp.PublisherID,
+		URL:   fmt.Sprintf("%s/publishers/%s", r.baseURL, resp.PublisherID),
+		Role:  "publisher",
+	}}, nil
+}
@@ -0,0 +1,36 @@
+package pypi
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestInstallSignals(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   []releaseFile
+		scripts []string
+		native  bool
+	}{
+		{"pure wheel", []releaseFile{
+			{URL: "https://files.example/requests-2.31.0-py3-none-any.whl", PackageType: "bdist_wheel"},
+			{URL: "https://files.example/requests-2.31.0.tar.gz", PackageType: "sdist"},
+		}, nil, false},
+		{"platform wheel", []releaseFile{
+			{URL: "https://files.example/numpy-2.0.0-cp312-cp312-manylinux_2_17_x86_64.whl", PackageType: "bdist_wheel"},
+		}, nil, true},
+		{"sdist only", []releaseFile{
+			{URL: "https://files.example/oldpkg-0.1.tar.gz", PackageType: "sdist"},
+		}, []string{"sdist"}, false},
+	}
+	for _, tt := range tests {
+		scripts, native := installSignals(tt.files)
+		if !reflect.DeepEqual(scripts, tt.scripts) || native != tt.native {
+			t.Errorf("%s: got %v, %v; want %v, %v", tt.name, scripts, native, tt.scripts, tt.native)
+		}
+	}
+
+	if !nativeClassifiers([]string{"Programming Language :: Python :: 3", "Programming Language :: C"}) {
+		t.Error("expected the C classifier to mark native code")
+	}
+}
@@ -0,0 +1,304 @@
+package pypi
+
+import (
+	"regexp"
+	"strings"
+)
+
+// PEP 508 environment markers are boolean expressions over comparisons
+// such as python_version < "3.10" and extra == "socks". Extras can appear
+// anywhere in them, as in `extra == "a" or extra == "b"`, so the marker is
+// parsed rather than searched.
+// https://packaging.python.org/en/latest/specifications/dependency-specifiers/#environment-markers
+
+// extraMarker is the part of a marker that applies when one extra, or none,
+// is requested.
+type extraMarker struct {
+	extra  string // normalized; empty for the requirement without extras
+	marker string // the rest of the marker, empty if none
+}
+
+// splitExtraMarker splits a marker into one entry per extra it names, each
+// with the marker that remains when that extra is requested and no other.
+// A marker that can also be true with no extra requested, such as
+// `extra == "a" or python_version < "3"`, gets an entry with no extra too.
+// Markers without extras, or that can't be parsed, are returned whole.
+func splitExtraMarker(marker string) []extraMarker {
+	if strings.TrimSpace(marker) == "" {
+		return []extraMarker{{}}
+	}
+	p := &markerParser{src: marker}
+	p.tokenize()
+	node, ok := p.parseOr()
+	if !ok || p.pos != len(p.tokens) {
+		return []extraMarker{{extra: firstExtra(marker), marker: marker}}
+	}
+
+	extras := node.extras(nil)
+	if len(extras) == 0 {
+		return []extraMarker{{marker: marker}}
+	}
+
+	var out []extraMarker
+	if rest := node.assume(""); rest != nil && !rest.isFalse() {
+		out = append(out, extraMarker{marker: rest.String()})
+	}
+	for _, extra := range extras {
+		rest := node.assume(extra)
+		if rest == nil || rest.isFalse() {
+			continue
+		}
+		out = append(out, extraMarker{extra: extra, marker: rest.String()})
+	}
+	if len(out) == 0 {
+		return []extraMarker{{marker: marker}}
+	}
+	return out
+}
+
+var extraMarkerRegex = regexp.MustCompile(`\bextra\s*==\s*["']([^"']+)["']`)
+
+// firstExtra finds an extra in a marker that didn't parse.
+func firstExtra(marker string) string {
+	if m := extraMarkerRegex.FindStringSubmatch(marker); m != nil {
+		return normalizeName(m[1])
+	}
+	return ""
+}
+
+// markerNode is a parsed marker: an "and" or "or" of nodes, a comparison,
+// or, once extras have been assumed, a constant.
+type markerNode struct {
+	op       string // "and", "or", "cmp", "true" or "false"
+	children []*markerNode
+	text     string // the comparison as written
+	extra    string // for comparisons of extra, the normalized extra name
+	negated  bool   // the comparison is extra != name
+}
+
+func (n *markerNode) isFalse() bool { return n.op == "false" }
+
+// extras lists the extras the marker compares against, in order.
+func (n *markerNode) extras(seen []string) []string {
+	if n.op == "cmp" {
+		if n.extra != "" && !contains(seen, n.extra) {
+			seen = append(seen, n.extra)
+		}
+		return seen
+	}
+	for _, c := range n.children {
+		seen = c.extras(seen)
+	}
+	return seen
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// assume returns the marker simplified for extra being the only requested
+// extra, or for no extra at all if extra is empty.
+func (n *markerNode) assume(extra string) *markerNode {
+	switch n.op {
+	case "cmp":
+		if n.extra == "" {
+			return n
+		}
+		if (n.extra == extra) != n.negated {
+			return &markerNode{op: "true"}
+		}
+		return &markerNode{op: "false"}
+	case "and", "or":
+		// The constant that decides the whole expression, and the one
+		// that drops out of it
+		decides, dropped := "false", "true"
+		if n.op == "or" {
+			decides, dropped = "true", "false"
+		}
+		var kept []*markerNode
+		for _, c := range n.children {
+			c = c.assume(extra)
+			switch c.op {
+			case decides:
+				return c
+			case dropped:
+				continue
+			}
+			kept = append(kept, c)
+		}
+		switch len(kept) {
+		case 0:
+			return &markerNode{op: dropped}
+		case 1:
+			return kept[0]
+		}
+		return &markerNode{op: n.op, children: kept}
+	}
+	return n
+}
+
+// String writes the marker back out, with "" for true.
+func (n *markerNode) String() string {
+	switch n.op {
+	case "cmp":
+		return n.text
+	case "true":
+		return ""
+	case "false":
+		return "false"
+	}
+	parts := make([]string, len(n.children))
+	for i, c := range n.children {
+		parts[i] = c.String()
+		if n.op == "and" && c.op == "or" {
+			parts[i] = "(" + parts[i] + ")"
+		}
+	}
+	return strings.Join(parts, " "+n.op+" ")
+}
+
+type markerToken struct {
+	text       string
+	start, end int
+	quoted     bool
+}
+
+type markerParser struct {
+	src    string
+	tokens []markerToken
+	pos    int
+}
+
+func (p *markerParser) tokenize() {
+	s := p.src
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t':
+			i++
+		case c == '(' || c == ')':
+			p.tokens = append(p.tokens, markerToken{text: s[i : i+1], start: i, end: i + 1})
+			i++
+		case c == '"' || c == '\'':
+			j := strings.IndexByte(s[i+1:], c)
+			if j < 0 {
+				j = len(s) - i - 1
+			}
+			p.tokens = append(p.tokens, markerToken{text: s[i+1 : i+1+j], start: i, end: min(i+j+2, len(s)), quoted: true})
+			i += j + 2
+		case strings.ContainsRune("<>=!~", rune(c)):
+			j := i
+			for j < len(s) && strings.ContainsRune("<>=!~", rune(s[j])) {
+				j++
+			}
+			p.tokens = append(p.tokens, markerToken{text: s[i:j], start: i, end: j})
+			i = j
+		default:
+			j := i
+			for j < len(s) && !strings.ContainsRune(" \t()\"'<>=!~", rune(s[j])) {
+				j++
+			}
+			p.tokens = append(p.tokens, markerToken{text: s[i:j], start: i, end: j})
+			i = j
+		}
+	}
+}
+
+func (p *markerParser) peek() (markerToken, bool) {
+	if p.pos >= len(p.tokens) {
+		return markerToken{}, false
+	}
+	return p.tokens[p.pos], true
+}
+
+// keyword reports whether the next token is the unquoted word w, and
+// consumes it if so.
+func (p *markerParser) keyword(w string) bool {
+	if t, ok := p.peek(); ok && !t.quoted && t.text == w {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *markerParser) parseOr() (*markerNode, bool) {
+	return p.parseBinary("or", p.parseAnd)
+}
+
+func (p *markerParser) parseAnd() (*markerNode, bool) {
+	return p.parseBinary("and", p.parseExpr)
+}
+
+func (p *markerParser) parseBinary(op string, next func() (*markerNode, bool)) (*markerNode, bool) {
+	first, ok := next()
+	if !ok {
+		return nil, false
+	}
+	children := []*markerNode{first}
+	for p.keyword(op) {
+		n, ok := next()
+		if !ok {
+			return nil, false
+		}
+		children = append(children, n)
+	}
+	if len(children) == 1 {
+		return first, true
+	}
+	return &markerNode{op: op, children: children}, true
+}
+
+func (p *markerParser) parseExpr() (*markerNode, bool) {
+	if p.keyword("(") {
+		n, ok := p.parseOr()
+		if !ok || !p.keyword(")") {
+			return nil, false
+		}
+		return n, true
+	}
+
+	left, ok := p.peek()
+	if !ok || (!left.quoted && (left.text == ")" || left.text == "and" || left.text == "or")) {
+		return nil, false
+	}
+	p.pos++
+	var op string
+	switch {
+	case p.keyword("not"):
+		if !p.keyword("in") {
+			return nil, false
+		}
+		op = "not in"
+	case p.keyword("in"):
+		op = "in"
+	default:
+		t, ok := p.peek()
+		if !ok || t.quoted || !strings.ContainsRune("<>=!~", rune(t.text[0])) {
+			return nil, false
+		}
+		op = t.text
+		p.pos++
+	}
+	right, ok := p.peek()
+	if !ok || (!right.quoted && (right.text == "(" || right.text == ")")) {
+		return nil, false
+	}
+	p.pos++
+
+	n := &markerNode{op: "cmp", text: p.src[left.start:right.end]}
+	if op == "==" || op == "!=" {
+		switch {
+		case !left.quoted && left.text == "extra" && right.quoted:
+			n.extra = normalizeName(right.text)
+		case !right.quoted && right.text == "extra" && left.quoted:
+			n.extra = normalizeName(left.text)
+		}
+		n.negated = n.extra != "" && op == "!="
+	}
+	return n, true
+}
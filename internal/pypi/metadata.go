@@ -0,0 +1,203 @@
+package pypi
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+// WithMetadataFallback returns a new Registry whose FetchDependencies reads
+// the release's own metadata when the JSON API's requires_dist is empty, as
+// it is for many releases uploaded before 2023. A wheel's METADATA is
+// preferred, fetched on its own where PyPI serves it (PEP 658) and otherwise
+// from the wheel; failing that the sdist is downloaded for its PKG-INFO, or
+// the egg-info requires.txt that older setuptools wrote instead. This costs
+// a download per release without requires_dist, so it is off by default.
+func (r *Registry) WithMetadataFallback() *Registry {
+	copy := *r
+	copy.metadataFallback = true
+	return &copy
+}
+
+// fetchRequiresDist returns the Requires-Dist entries of the first release
+// file it can read.
+func (r *Registry) fetchRequiresDist(ctx context.Context, files []releaseFile) ([]string, error) {
+	var sdists []releaseFile
+	for _, f := range files {
+		switch f.PackageType {
+		case "bdist_wheel":
+			if body, err := r.client.GetBody(ctx, f.URL+".metadata"); err == nil {
+				return requiresDist(body), nil
+			}
+			body, err := r.client.GetArtifact(ctx, f.URL)
+			if err != nil {
+				return nil, err
+			}
+			return requiresDistFromWheel(body)
+		case "sdist":
+			sdists = append(sdists, f)
+		}
+	}
+
+	for _, f := range sdists {
+		body, err := r.client.GetArtifact(ctx, f.URL)
+		if err != nil {
+			return nil, err
+		}
+		return requiresDistFromSdist(body, f.URL)
+	}
+	return nil, nil
+}
+
+func requiresDistFromWheel(wheel []byte) ([]string, error) {
+	zr, err := zip.NewReader(bytes.NewReader(wheel), int64(len(wheel)))
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range zr.File {
+		dir, file := path.Split(f.Name)
+		if file != "METADATA" || !strings.HasSuffix(dir, ".dist-info/") || strings.Count(dir, "/") != 1 {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		data, err := io.ReadAll(rc)
+		_ = rc.Close()
+		if err != nil {
+			return nil, err
+		}
+		return requiresDist(data), nil
+	}
+	return nil, fmt.Errorf("wheel has no METADATA")
+}
+
+// requiresDistFromSdist reads PKG-INFO from a .tar.gz or .zip sdist,
+// falling back to egg-info/requires.txt when PKG-INFO lists no
+// requirements.
+func requiresDistFromSdist(sdist []byte, url string) ([]string, error) {
+	files := make(map[string][]byte)
+	wanted := func(name string) bool {
+		dir, file := path.Split(name)
+		depth := strings.Count(dir, "/")
+		return (file == "PKG-INFO" && depth == 1) ||
+			(file == "requires.txt" && depth == 2 && strings.HasSuffix(dir, ".egg-info/"))
+	}
+
+	if strings.HasSuffix(url, ".zip") {
+		zr, err := zip.NewReader(bytes.NewReader(sdist), int64(len(sdist)))
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range zr.File {
+			if !wanted(f.Name) {
+				continue
+			}
+			rc, err := f.Open()
+			if err != nil {
+				return nil, err
+			}
+			data, err := io.ReadAll(rc)
+			_ = rc.Close()
+			if err != nil {
+				return nil, err
+			}
+			files[path.Base(f.Name)] = data
+		}
+	} else {
+		gz, err := gzip.NewReader(bytes.NewReader(sdist))
+		if err != nil {
+			return nil, err
+		}
+		defer func() { _ = gz.Close() }()
+		tr := tar.NewReader(gz)
+		for {
+			hdr, err := tr.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+			if !wanted(strings.TrimPrefix(hdr.Name, "./")) {
+				continue
+			}
+			data, err := io.ReadAll(tr)
+			if err != nil {
+				return nil, err
+			}
+			files[path.Base(hdr.Name)] = data
+		}
+	}
+
+	if reqs := requiresDist(files["PKG-INFO"]); len(reqs) > 0 {
+		return reqs, nil
+	}
+	if data, ok := files["requires.txt"]; ok {
+		return parseRequiresTxt(data), nil
+	}
+	if _, ok := files["PKG-INFO"]; !ok {
+		return nil, fmt.Errorf("sdist has no PKG-INFO")
+	}
+	return nil, nil
+}
+
+// requiresDist returns the Requires-Dist headers of a METADATA or PKG-INFO
+// file. The headers end at the first blank line.
+func requiresDist(metadata []byte) []string {
+	var reqs []string
+	scanner := bufio.NewScanner(bytes.NewReader(metadata))
+	scanner.Buffer(make([]byte, 64*1024), 16<<20)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == "" {
+			break
+		}
+		name, value, ok := strings.Cut(line, ":")
+		if ok && strings.EqualFold(name, "Requires-Dist") {
+			reqs = append(reqs, strings.TrimSpace(value))
+		}
+	}
+	return reqs
+}
+
+// parseRequiresTxt converts setuptools' requires.txt into PEP 508
+// requirements. Sections name an extra, a marker, or both, as in
+// [socks:sys_platform == "win32"].
+func parseRequiresTxt(data []byte) []string {
+	var reqs []string
+	var marker string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			extra, cond, _ := strings.Cut(line[1:len(line)-1], ":")
+			extra, cond = strings.TrimSpace(extra), strings.TrimSpace(cond)
+			switch {
+			case extra != "" && cond != "":
+				marker = fmt.Sprintf("(%s) and extra == %q", cond, extra)
+			case extra != "":
+				marker = fmt.Sprintf("extra == %q", extra)
+			default:
+				marker = cond
+			}
+			continue
+		}
+		if marker != "" {
+			line += "; " + marker
+		}
+		reqs = append(reqs, line)
+	}
+	return reqs
+}
@@ -0,0 +1,82 @@
+package pypi
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path"
+
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+// provenanceResponse is a PEP 740 provenance object from PyPI's Integrity
+// API.
+type provenanceResponse struct {
+	AttestationBundles []struct {
+		Publisher map[string]any `json:"publisher"`
+	} `json:"attestation_bundles"`
+}
+
+// FetchProvenance returns the trusted publisher of each file of a version
+// that has PEP 740 attestations, from PyPI's Integrity API. Files uploaded
+// with an API token have no attestations and are left out. This is one
+// request per file of the version.
+func (r *Registry) FetchProvenance(ctx context.Context, name, version string) ([]core.Provenance, error) {
+	var resp versionInfoResponse
+	if err := r.client.GetJSON(ctx, fmt.Sprintf("%s/pypi/%s/%s/json", r.baseURL, name, version), &resp); err != nil {
+		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
+			return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name, Version: version}
+		}
+		return nil, err
+	}
+
+	var provenance []core.Provenance
+	for _, f := range resp.URLs {
+		file := f.Filename
+		if file == "" {
+			file = path.Base(f.URL)
+		}
+		u := fmt.Sprintf("%s/integrity/%s/%s/%s/provenance", r.baseURL, url.PathEscape(name), url.PathEscape(version), url.PathEscape(file))
+
+		var doc provenanceResponse
+		if err := r.client.GetJSON(ctx, u, &doc); err != nil {
+			if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
+				continue
+			}
+			return nil, err
+		}
+		for _, bundle := range doc.AttestationBundles {
+			provenance = append(provenance, publisherProvenance(file, u, bundle.Publisher))
+		}
+	}
+	return provenance, nil
+}
+
+// publisherProvenance reads a trusted publisher's claims. GitHub publishers
+// name their workflow "workflow" and GitLab ones "workflow_filepath"; what
+// isn't common to all publishers is kept in Metadata.
+func publisherProvenance(file, url string, publisher map[string]any) core.Provenance {
+	p := core.Provenance{File: file, URL: url}
+	metadata := make(map[string]any)
+	for key, value := range publisher {
+		s, _ := value.(string)
+		switch key {
+		case "kind":
+			p.Publisher = s
+		case "repository":
+			p.Repository = s
+		case "workflow", "workflow_filepath":
+			p.Workflow = s
+		case "environment":
+			p.Environment = s
+		default:
+			if value != nil {
+				metadata[key] = value
+			}
+		}
+	}
+	if len(metadata) > 0 {
+		p.Metadata = metadata
+	}
+	return p
+}
@@ -0,0 +1,67 @@
+package pypi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+func TestFetchProvenance(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/pypi/sampleproject/4.0.0/json", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"info": {"name": "sampleproject"}, "urls": [
+			{"filename": "sampleproject-4.0.0-py3-none-any.whl", "url": "https://files.example.com/sampleproject-4.0.0-py3-none-any.whl"},
+			{"filename": "sampleproject-4.0.0.tar.gz", "url": "https://files.example.com/sampleproject-4.0.0.tar.gz"}
+		]}`))
+	})
+	mux.HandleFunc("/integrity/sampleproject/4.0.0/sampleproject-4.0.0-py3-none-any.whl/provenance", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"version": 1, "attestation_bundles": [{
+			"publisher": {"kind": "GitHub", "repository": "pypa/sampleproject", "workflow": "release.yml", "environment": "pypi", "claims": null},
+			"attestations": [{"version": 1}]
+		}]}`))
+	})
+	// The sdist was uploaded with a token, so it has no provenance.
+	mux.HandleFunc("/integrity/sampleproject/4.0.0/sampleproject-4.0.0.tar.gz/provenance", http.NotFound)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	provenance, err := core.FetchProvenance(context.Background(), reg, "sampleproject", "4.0.0")
+	if err != nil {
+		t.Fatalf("FetchProvenance failed: %v", err)
+	}
+	want := core.Provenance{
+		File:        "sampleproject-4.0.0-py3-none-any.whl",
+		Publisher:   core.PublisherGitHub,
+		Repository:  "pypa/sampleproject",
+		Workflow:    "release.yml",
+		Environment: "pypi",
+		URL:         server.URL + "/integrity/sampleproject/4.0.0/sampleproject-4.0.0-py3-none-any.whl/provenance",
+	}
+	if len(provenance) != 1 || !reflect.DeepEqual(provenance[0], want) {
+		t.Errorf("provenance = %+v, want %+v", provenance, want)
+	}
+
+	var notFound *core.NotFoundError
+	if _, err := reg.FetchProvenance(context.Background(), "sampleproject", "9.9.9"); !errors.As(err, &notFound) {
+		t.Errorf("expected NotFoundError, got %v", err)
+	}
+}
+
+func TestPublisherProvenance(t *testing.T) {
+	p := publisherProvenance("pkg.whl", "", map[string]any{
+		"kind":              "GitLab",
+		"repository":        "group/project",
+		"workflow_filepath": ".gitlab-ci.yml",
+		"environment":       "",
+		"email":             "ci@example.com",
+	})
+	if p.Publisher != core.PublisherGitLab || p.Workflow != ".gitlab-ci.yml" || p.Metadata["email"] != "ci@example.com" {
+		t.Errorf("provenance = %+v", p)
+	}
+}
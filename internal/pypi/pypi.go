@@ -4,6 +4,7 @@ package pypi
 import (
 	"context"
 	"fmt"
+	"path"
 	"regexp"
 	"strings"
 	"time"
@@ -24,9 +25,10 @@ func init() {
 }
 
 type Registry struct {
-	baseURL string
-	client  *core.Client
-	urls    *URLs
+	baseURL          string
+	client           *core.Client
+	urls             *URLs
+	metadataFallback bool
 }
 
 func New(baseURL string, client *core.Client) *Registry {
@@ -50,39 +52,44 @@ func (r *Registry) URLs() core.URLBuilder {
 }
 
 type packageResponse struct {
-	Info     infoBlock                  `json:"info"`
-	Releases map[string][]releaseFile   `json:"releases"`
+	Info     infoBlock                `json:"info"`
+	Releases map[string][]releaseFile `json:"releases"`
 }
 
 type infoBlock struct {
-	Name              string            `json:"name"`
-	Summary           string            `json:"summary"`
-	Description       string            `json:"description"`
-	HomePage          string            `json:"home_page"`
-	License           string            `json:"license"`
-	LicenseExpression string            `json:"license_expression"`
-	Keywords          string            `json:"keywords"`
-	Version           string            `json:"version"`
-	Classifiers       []string          `json:"classifiers"`
-	ProjectURLs       map[string]string `json:"project_urls"`
-	RequiresDist      []string          `json:"requires_dist"`
-	RequiresPython    string            `json:"requires_python"`
+	Name                   string            `json:"name"`
+	Summary                string            `json:"summary"`
+	Description            string            `json:"description"`
+	DescriptionContentType string            `json:"description_content_type"`
+	HomePage               string            `json:"home_page"`
+	License                string            `json:"license"`
+	LicenseExpression      string            `json:"license_expression"`
+	Keywords               string            `json:"keywords"`
+	Version                string            `json:"version"`
+	Classifiers            []string          `json:"classifiers"`
+	ProjectURLs            map[string]string `json:"project_urls"`
+	DocsURL                string            `json:"docs_url"`
+	RequiresDist           []string          `json:"requires_dist"`
+	RequiresPython         string            `json:"requires_python"`
+	ProvidesExtra          []string          `json:"provides_extra"`
 }
 
 type releaseFile struct {
-	Digests         map[string]string `json:"digests"`
-	URL             string            `json:"url"`
-	UploadTime      string            `json:"upload_time"`
-	Yanked          bool              `json:"yanked"`
-	YankedReason    string            `json:"yanked_reason"`
-	PackageType     string            `json:"packagetype"`
-	PythonVersion   string            `json:"python_version"`
-	RequiresPython  string            `json:"requires_python"`
-	Size            int               `json:"size"`
+	Filename       string            `json:"filename"`
+	Digests        map[string]string `json:"digests"`
+	URL            string            `json:"url"`
+	UploadTime     string            `json:"upload_time"`
+	Yanked         bool              `json:"yanked"`
+	YankedReason   string            `json:"yanked_reason"`
+	PackageType    string            `json:"packagetype"`
+	PythonVersion  string            `json:"python_version"`
+	RequiresPython string            `json:"requires_python"`
+	Size           int               `json:"size"`
 }
 
 type versionInfoResponse struct {
-	Info infoBlock `json:"info"`
+	Info infoBlock     `json:"info"`
+	URLs []releaseFile `json:"urls"`
 }
 
 func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package, error) {
@@ -98,22 +105,71 @@ func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package
 
 	repoURL := extractRepoURL(resp.Info.ProjectURLs, resp.Info.HomePage)
 	homepage := extractHomepage(resp.Info.ProjectURLs, resp.Info.HomePage)
+	createdAt, latestReleased := releaseTimes(resp.Releases)
 
 	return &core.Package{
-		Name:        strings.ToLower(resp.Info.Name),
-		Description: resp.Info.Summary,
-		Homepage:    homepage,
-		Repository:  repoURL,
-		Licenses:    extractLicense(resp.Info),
-		Keywords:    parseKeywords(resp.Info.Keywords),
+		Name:          strings.ToLower(resp.Info.Name),
+		Description:   resp.Info.Summary,
+		Homepage:      homepage,
+		Repository:    repoURL,
+		Documentation: extractDocumentation(resp.Info),
+		Licenses:      extractLicense(resp.Info),
+		Keywords:      parseKeywords(resp.Info.Keywords),
+		Categories:    topicClassifiers(resp.Info.Classifiers),
+		// PyPI's version is its newest release, preferring final releases
+		LatestVersion:       resp.Info.Version,
+		LatestStableVersion: core.LatestStableOf(ecosystem, availableReleases(resp.Releases)),
 		Metadata: map[string]any{
-			"classifiers":      resp.Info.Classifiers,
-			"documentation":    resp.Info.ProjectURLs["Documentation"],
-			"normalized_name":  normalizeName(resp.Info.Name),
+			"classifiers":     resp.Info.Classifiers,
+			"documentation":   resp.Info.ProjectURLs["Documentation"],
+			"normalized_name": normalizeName(resp.Info.Name),
+			"provides_extra":  resp.Info.ProvidesExtra,
 		},
+		CreatedAt:        createdAt,
+		LatestReleasedAt: latestReleased,
 	}, nil
 }
 
+// availableReleases returns the releases with at least one file that
+// hasn't been yanked.
+func availableReleases(releases map[string][]releaseFile) []string {
+	var available []string
+	for num, files := range releases {
+		for _, f := range files {
+			if !f.Yanked {
+				available = append(available, num)
+				break
+			}
+		}
+	}
+	return available
+}
+
+// releaseTimes returns when the first and the most recent release were
+// published. A release's time is that of its first file, as wheels for new
+// platforms are often uploaded long after.
+func releaseTimes(releases map[string][]releaseFile) (first, latest time.Time) {
+	for _, files := range releases {
+		var released time.Time
+		for _, f := range files {
+			t, err := time.Parse("2006-01-02T15:04:05", f.UploadTime)
+			if err == nil && (released.IsZero() || t.Before(released)) {
+				released = t
+			}
+		}
+		if released.IsZero() {
+			continue
+		}
+		if first.IsZero() || released.Before(first) {
+			first = released
+		}
+		if released.After(latest) {
+			latest = released
+		}
+	}
+	return first, latest
+}
+
 func extractRepoURL(projectURLs map[string]string, homePage string) string {
 	priorityKeys := []string{"Repository", "Source", "Source Code", "Code"}
 	for _, key := range priorityKeys {
@@ -153,6 +209,18 @@ func extractHomepage(projectURLs map[string]string, homePage string) string {
 	return ""
 }
 
+// extractDocumentation returns the documentation project URL, whatever its
+// label's case, or the docs PyPI itself hosts for the project.
+func extractDocumentation(info infoBlock) string {
+	for label, url := range info.ProjectURLs {
+		switch strings.ToLower(label) {
+		case "documentation", "docs":
+			return url
+		}
+	}
+	return info.DocsURL
+}
+
 func extractLicense(info infoBlock) string {
 	// LicenseExpression is already SPDX-normalized by PyPI
 	if info.LicenseExpression != "" {
@@ -182,6 +250,18 @@ func parseKeywords(keywords string) []string {
 	return strings.Fields(keywords)
 }
 
+// topicClassifiers returns the Topic trove classifiers without their prefix,
+// e.g. "Internet :: WWW/HTTP".
+func topicClassifiers(classifiers []string) []string {
+	var topics []string
+	for _, c := range classifiers {
+		if topic, ok := strings.CutPrefix(c, "Topic :: "); ok {
+			topics = append(topics, topic)
+		}
+	}
+	return topics
+}
+
 func normalizeName(name string) string {
 	name = strings.ToLower(name)
 	name = strings.ReplaceAll(name, "_", "-")
@@ -222,27 +302,83 @@ func (r *Registry) FetchVersions(ctx context.Context, name string) ([]core.Versi
 
 		var integrity string
 		if sha256, ok := file.Digests["sha256"]; ok {
-			integrity = "sha256-" + sha256
+			integrity = core.HexIntegrity("sha256", sha256)
+		}
+
+		metadata := map[string]any{
+			"download_url":    file.URL,
+			"requires_python": file.RequiresPython,
+			"yanked_reason":   file.YankedReason,
+			"packagetype":     file.PackageType,
+			"size":            file.Size,
 		}
+		scripts, native := installSignals(files)
+		// Classifiers describe only the latest release
+		if num == resp.Info.Version && nativeClassifiers(resp.Info.Classifiers) {
+			native = true
+		}
+		core.SetInstallSignals(metadata, scripts, native)
 
 		versions = append(versions, core.Version{
 			Number:      num,
 			PublishedAt: publishedAt,
 			Integrity:   integrity,
 			Status:      status,
-			Metadata: map[string]any{
-				"download_url":    file.URL,
-				"requires_python": file.RequiresPython,
-				"yanked_reason":   file.YankedReason,
-				"packagetype":     file.PackageType,
-				"size":            file.Size,
-			},
+			Metadata:    metadata,
+			Size:        int64(file.Size),
 		})
 	}
 
 	return versions, nil
 }
 
+// installSignals works out from a release's files whether installing it
+// runs code and involves native code. A release without wheels can only be
+// installed by building its sdist, which runs the build backend and, for
+// setuptools, setup.py. A wheel whose platform tag isn't "any" contains
+// compiled code.
+func installSignals(files []releaseFile) (scripts []string, native bool) {
+	var wheels, sdists int
+	for _, f := range files {
+		filename := path.Base(f.URL)
+		switch {
+		case strings.HasSuffix(filename, ".whl"):
+			wheels++
+			tags := strings.Split(strings.TrimSuffix(filename, ".whl"), "-")
+			if platform := tags[len(tags)-1]; platform != "any" {
+				native = true
+			}
+		case f.PackageType == "sdist":
+			sdists++
+		}
+	}
+	if wheels == 0 && sdists > 0 {
+		scripts = []string{"sdist"}
+	}
+	return scripts, native
+}
+
+// nativeLanguages are the classifiers of languages that compile to native
+// extension modules.
+var nativeLanguages = map[string]bool{
+	"Programming Language :: C":       true,
+	"Programming Language :: C++":     true,
+	"Programming Language :: Cython":  true,
+	"Programming Language :: Rust":    true,
+	"Programming Language :: Fortran": true,
+}
+
+// nativeClassifiers reports whether classifiers declare a language used
+// for extension modules.
+func nativeClassifiers(classifiers []string) bool {
+	for _, c := range classifiers {
+		if nativeLanguages[c] {
+			return true
+		}
+	}
+	return false
+}
+
 var pep508NameRegex = regexp.MustCompile(`^([A-Za-z0-9][-A-Za-z0-9._]*[A-Za-z0-9]|[A-Za-z0-9])(\s*\[.*?\])?`)
 
 func (r *Registry) FetchDependencies(ctx context.Context, name, version string) ([]core.Dependency, error) {
@@ -256,30 +392,47 @@ func (r *Registry) FetchDependencies(ctx context.Context, name, version string)
 		return nil, err
 	}
 
-	if len(resp.Info.RequiresDist) == 0 {
-		return nil, nil
+	if len(resp.Info.RequiresDist) == 0 && r.metadataFallback {
+		requiresDist, err := r.fetchRequiresDist(ctx, resp.URLs)
+		if err != nil {
+			return nil, fmt.Errorf("reading metadata for %s %s: %w", name, version, err)
+		}
+		return ParseRequiresDist(requiresDist), nil
+	}
+
+	return ParseRequiresDist(resp.Info.RequiresDist), nil
+}
+
+// ParseRequiresDist converts PEP 508 requirement strings, as listed in
+// requires_dist and in Requires-Dist headers of METADATA files, into
+// dependencies. Requirements guarded by an extra are optional.
+func ParseRequiresDist(requiresDist []string) []core.Dependency {
+	if len(requiresDist) == 0 {
+		return nil
 	}
 
-	deps := make([]core.Dependency, 0, len(resp.Info.RequiresDist))
-	for _, req := range resp.Info.RequiresDist {
+	deps := make([]core.Dependency, 0, len(requiresDist))
+	for _, req := range requiresDist {
 		depName, requirements, envMarker := parsePEP508(req)
+		// A requirement guarded by several extras is listed once for each
+		for _, em := range splitExtraMarker(envMarker) {
+			scope := core.Runtime
+			if em.extra != "" {
+				scope = core.Optional
+			}
 
-		scope := core.Runtime
-		optional := false
-		if envMarker != "" {
-			scope = core.Scope(envMarker)
-			optional = true
+			deps = append(deps, core.Dependency{
+				Name:              depName,
+				Requirements:      requirements,
+				Scope:             scope,
+				Optional:          em.extra != "",
+				EnvironmentMarker: em.marker,
+				Extra:             em.extra,
+			})
 		}
-
-		deps = append(deps, core.Dependency{
-			Name:         depName,
-			Requirements: requirements,
-			Scope:        scope,
-			Optional:     optional,
-		})
 	}
 
-	return deps, nil
+	return deps
 }
 
 func parsePEP508(dep string) (name, requirements, envMarker string) {
@@ -314,12 +467,6 @@ func parsePEP508(dep string) (name, requirements, envMarker string) {
 	return
 }
 
-func (r *Registry) FetchMaintainers(ctx context.Context, name string) ([]core.Maintainer, error) {
-	// PyPI doesn't expose maintainers through JSON API
-	// Would require scraping or XML-RPC
-	return nil, nil
-}
-
 type URLs struct {
 	baseURL string
 }
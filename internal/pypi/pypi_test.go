@@ -1,10 +1,16 @@
 package pypi
 
 import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"testing"
 
 	"github.com/git-pkgs/registries/internal/core"
@@ -20,15 +26,21 @@ func TestFetchPackage(t *testing.T) {
 
 		resp := packageResponse{
 			Info: infoBlock{
-				Name:              "requests",
-				Summary:           "Python HTTP for Humans.",
-				License:           "Apache 2.0",
-				HomePage:          "https://requests.readthedocs.io",
-				Keywords:          "http,web,client",
+				Name:     "requests",
+				Summary:  "Python HTTP for Humans.",
+				Version:  "3.0.0b1",
+				License:  "Apache 2.0",
+				HomePage: "https://requests.readthedocs.io",
+				Keywords: "http,web,client",
 				ProjectURLs: map[string]string{
 					"Source":        "https://github.com/psf/requests",
 					"Documentation": "https://requests.readthedocs.io",
 				},
+				ProvidesExtra: []string{"security", "socks", "use-chardet-on-py3"},
+				Classifiers: []string{
+					"License :: OSI Approved :: Apache Software License",
+					"Topic :: Internet :: WWW/HTTP",
+				},
 			},
 			Releases: map[string][]releaseFile{
 				"2.31.0": {
@@ -36,7 +48,12 @@ func TestFetchPackage(t *testing.T) {
 						Digests:    map[string]string{"sha256": "abc123"},
 						UploadTime: "2023-05-22T12:00:00",
 					},
+					// A wheel uploaded later doesn't move the release time
+					{UploadTime: "2024-01-01T00:00:00"},
 				},
+				"0.2.0":   {{UploadTime: "2011-02-14T00:00:00"}},
+				"0.0.1":   {},
+				"3.0.0b1": {{}},
 			},
 		}
 
@@ -63,6 +80,18 @@ func TestFetchPackage(t *testing.T) {
 	if len(pkg.Keywords) != 3 {
 		t.Errorf("expected 3 keywords, got %d", len(pkg.Keywords))
 	}
+	if len(pkg.Categories) != 1 || pkg.Categories[0] != "Internet :: WWW/HTTP" {
+		t.Errorf("unexpected categories: %v", pkg.Categories)
+	}
+	if extras, _ := pkg.Metadata["provides_extra"].([]string); len(extras) != 3 {
+		t.Errorf("expected 3 declared extras, got %v", pkg.Metadata["provides_extra"])
+	}
+	if pkg.CreatedAt.Format("2006-01-02") != "2011-02-14" || pkg.LatestReleasedAt.Format("2006-01-02") != "2023-05-22" {
+		t.Errorf("unexpected release times: created %v, latest %v", pkg.CreatedAt, pkg.LatestReleasedAt)
+	}
+	if pkg.LatestVersion != "3.0.0b1" || pkg.LatestStableVersion != "2.31.0" {
+		t.Errorf("latest = %q, stable = %q", pkg.LatestVersion, pkg.LatestStableVersion)
+	}
 }
 
 func TestFetchPackageWithLicenseExpression(t *testing.T) {
@@ -160,6 +189,7 @@ func TestFetchDependencies(t *testing.T) {
 					"urllib3<3,>=1.21.1",
 					"certifi>=2017.4.17",
 					"PySocks!=1.5.7,>=1.5.6; extra == 'socks'",
+					"chardet<6,>=3.0.2; python_version < \"3.8\" and extra == 'use_chardet_on_py3'",
 				},
 			},
 		}
@@ -175,8 +205,8 @@ func TestFetchDependencies(t *testing.T) {
 		t.Fatalf("FetchDependencies failed: %v", err)
 	}
 
-	if len(deps) != 5 {
-		t.Fatalf("expected 5 dependencies, got %d", len(deps))
+	if len(deps) != 6 {
+		t.Fatalf("expected 6 dependencies, got %d", len(deps))
 	}
 
 	runtimeCount := 0
@@ -193,8 +223,69 @@ func TestFetchDependencies(t *testing.T) {
 	if runtimeCount != 4 {
 		t.Errorf("expected 4 runtime deps, got %d", runtimeCount)
 	}
-	if optionalCount != 1 {
-		t.Errorf("expected 1 optional dep, got %d", optionalCount)
+	if optionalCount != 2 {
+		t.Errorf("expected 2 optional deps, got %d", optionalCount)
+	}
+
+	if deps[4].Extra != "socks" || deps[4].Scope != core.Optional {
+		t.Errorf("expected PySocks in optional 'socks' extra, got %q %q", deps[4].Extra, deps[4].Scope)
+	}
+	if deps[4].EnvironmentMarker != "" {
+		t.Errorf("expected no remaining marker on PySocks, got %q", deps[4].EnvironmentMarker)
+	}
+	if deps[5].Extra != "use-chardet-on-py3" {
+		t.Errorf("expected normalized extra 'use-chardet-on-py3', got %q", deps[5].Extra)
+	}
+	if deps[5].EnvironmentMarker != `python_version < "3.8"` {
+		t.Errorf("expected python_version marker on chardet, got %q", deps[5].EnvironmentMarker)
+	}
+}
+
+func TestSplitExtraMarker(t *testing.T) {
+	tests := []struct {
+		marker string
+		want   []extraMarker
+	}{
+		{"", []extraMarker{{}}},
+		{`python_version < "3.10"`, []extraMarker{{marker: `python_version < "3.10"`}}},
+		{"extra == 'socks'", []extraMarker{{extra: "socks"}}},
+		{`extra == "Test_Suite"`, []extraMarker{{extra: "test-suite"}}},
+		{`extra == "tests" and sys_platform == "win32"`, []extraMarker{{extra: "tests", marker: `sys_platform == "win32"`}}},
+		{`(python_version < "3.8") and extra == "compat"`, []extraMarker{{extra: "compat", marker: `python_version < "3.8"`}}},
+		{`extra == 'a' or extra == 'b'`, []extraMarker{{extra: "a"}, {extra: "b"}}},
+		{`(extra == "a" or extra == "b") and python_version >= "3.9"`, []extraMarker{
+			{extra: "a", marker: `python_version >= "3.9"`},
+			{extra: "b", marker: `python_version >= "3.9"`},
+		}},
+		{`sys_platform == "linux" and (extra == "gpu" or extra == "all") and platform_machine == "x86_64"`, []extraMarker{
+			{extra: "gpu", marker: `sys_platform == "linux" and platform_machine == "x86_64"`},
+			{extra: "all", marker: `sys_platform == "linux" and platform_machine == "x86_64"`},
+		}},
+		{`extra == "a" and python_version < "3" or extra == "b"`, []extraMarker{
+			{extra: "a", marker: `python_version < "3"`},
+			{extra: "b"},
+		}},
+		// Required without the extra on old Pythons
+		{`python_version < "3.8" or extra == "compat"`, []extraMarker{
+			{marker: `python_version < "3.8"`},
+			{extra: "compat"},
+		}},
+		{`(os_name == "nt" or os_name == "posix") and extra == "cli"`, []extraMarker{
+			{extra: "cli", marker: `os_name == "nt" or os_name == "posix"`},
+		}},
+		{`"socks" == extra`, []extraMarker{{extra: "socks"}}},
+		{`platform_release not in "4.4.0-1" and extra == "x"`, []extraMarker{{extra: "x", marker: `platform_release not in "4.4.0-1"`}}},
+		// Unparseable markers are kept whole
+		{`extra == "a" and (`, []extraMarker{{extra: "a", marker: `extra == "a" and (`}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.marker, func(t *testing.T) {
+			got := splitExtraMarker(tt.marker)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitExtraMarker = %+v, want %+v", got, tt.want)
+			}
+		})
 	}
 }
 
@@ -275,6 +366,40 @@ func TestURLBuilder(t *testing.T) {
 	}
 }
 
+func TestDocumentationURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		info := infoBlock{Name: "click"}
+		if r.URL.Path == "/pypi/flask/json" {
+			info = infoBlock{
+				Name:        "flask",
+				ProjectURLs: map[string]string{"documentation": "https://flask.palletsprojects.com/"},
+			}
+		}
+		_ = json.NewEncoder(w).Encode(packageResponse{Info: info})
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	ctx := context.Background()
+
+	got, err := core.DocumentationURL(ctx, reg, "flask", "3.0.0")
+	if err != nil {
+		t.Fatalf("DocumentationURL failed: %v", err)
+	}
+	if got != "https://flask.palletsprojects.com/" {
+		t.Errorf("declared documentation = %q", got)
+	}
+
+	// Nothing declared, so the readthedocs guess is used
+	got, err = core.DocumentationURL(ctx, reg, "click", "8.1.0")
+	if err != nil {
+		t.Fatalf("DocumentationURL failed: %v", err)
+	}
+	if got != "https://click.readthedocs.io/en/8.1.0/" {
+		t.Errorf("fallback documentation = %q", got)
+	}
+}
+
 func TestEcosystem(t *testing.T) {
 	reg := New("", nil)
 	if reg.Ecosystem() != "pypi" {
@@ -302,3 +427,157 @@ func TestParseKeywords(t *testing.T) {
 		})
 	}
 }
+
+func TestFetchReadme(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		info := map[string]interface{}{"name": "requests"}
+		switch r.URL.Path {
+		case "/pypi/requests/json":
+			info["description"] = "# Requests\n\nHTTP for Humans"
+			info["description_content_type"] = "text/markdown; charset=UTF-8"
+		case "/pypi/requests/0.2.0/json":
+			info["description"] = "Requests\n========"
+		case "/pypi/requests/0.1.0/json":
+			info["description"] = "UNKNOWN"
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"info": info})
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	ctx := context.Background()
+
+	doc, err := reg.FetchReadme(ctx, "requests", "")
+	if err != nil {
+		t.Fatalf("FetchReadme failed: %v", err)
+	}
+	if doc.ContentType != core.Markdown || doc.Content != "# Requests\n\nHTTP for Humans" {
+		t.Errorf("unexpected document: %+v", doc)
+	}
+
+	// No declared content type means reStructuredText
+	doc, err = reg.FetchReadme(ctx, "requests", "0.2.0")
+	if err != nil {
+		t.Fatalf("FetchReadme failed: %v", err)
+	}
+	if doc.ContentType != core.ReStructuredText {
+		t.Errorf("expected rst, got %q", doc.ContentType)
+	}
+
+	if _, err := reg.FetchReadme(ctx, "requests", "0.1.0"); !errors.Is(err, core.ErrNotFound) {
+		t.Errorf("expected not found for UNKNOWN description, got %v", err)
+	}
+}
+
+func TestFetchDependenciesMetadataFallback(t *testing.T) {
+	var wheel bytes.Buffer
+	zw := zip.NewWriter(&wheel)
+	w, _ := zw.Create("oldpkg-1.0.dist-info/METADATA")
+	_, _ = w.Write([]byte("Metadata-Version: 2.1\nName: oldpkg\nVersion: 1.0\nRequires-Dist: six (>=1.10)\nRequires-Dist: PySocks ; extra == 'socks'\n\nLong description\nRequires-Dist: not-a-header\n"))
+	_ = zw.Close()
+
+	var sdist bytes.Buffer
+	gz := gzip.NewWriter(&sdist)
+	tw := tar.NewWriter(gz)
+	for name, data := range map[string]string{
+		"legacy-0.1/PKG-INFO":                       "Metadata-Version: 1.1\nName: legacy\nVersion: 0.1\n",
+		"legacy-0.1/legacy.egg-info/requires.txt":   "requests>=2\n\n[security]\npyOpenSSL\n\n[:python_version < \"3\"]\nipaddress\n",
+		"legacy-0.1/vendor/x.egg-info/requires.txt": "ignored\n",
+	} {
+		_ = tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(data)), Typeflag: tar.TypeReg})
+		_, _ = tw.Write([]byte(data))
+	}
+	_ = tw.Close()
+	_ = gz.Close()
+
+	var serverURL string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/pypi/oldpkg/1.0/json":
+			_ = json.NewEncoder(w).Encode(versionInfoResponse{URLs: []releaseFile{
+				{URL: serverURL + "/files/oldpkg-1.0.tar.gz", PackageType: "sdist"},
+				{URL: serverURL + "/files/oldpkg-1.0-py2.py3-none-any.whl", PackageType: "bdist_wheel"},
+			}})
+		case "/pypi/legacy/0.1/json":
+			_ = json.NewEncoder(w).Encode(versionInfoResponse{URLs: []releaseFile{
+				{URL: serverURL + "/files/legacy-0.1.tar.gz", PackageType: "sdist"},
+			}})
+		case "/files/oldpkg-1.0-py2.py3-none-any.whl":
+			_, _ = w.Write(wheel.Bytes())
+		case "/files/legacy-0.1.tar.gz":
+			_, _ = w.Write(sdist.Bytes())
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	serverURL = server.URL
+
+	c := core.DefaultClient()
+	c.MaxRetries = 0
+	reg := New(server.URL, c)
+	ctx := context.Background()
+
+	deps, err := reg.FetchDependencies(ctx, "oldpkg", "1.0")
+	if err != nil || len(deps) != 0 {
+		t.Fatalf("without the fallback: deps = %v, err = %v", deps, err)
+	}
+
+	reg = reg.WithMetadataFallback()
+	deps, err = reg.FetchDependencies(ctx, "oldpkg", "1.0")
+	if err != nil {
+		t.Fatalf("FetchDependencies failed: %v", err)
+	}
+	if len(deps) != 2 || deps[0].Name != "six" || deps[0].Requirements != ">=1.10" || deps[1].Extra != "socks" {
+		t.Errorf("wheel deps = %+v", deps)
+	}
+
+	deps, err = reg.FetchDependencies(ctx, "legacy", "0.1")
+	if err != nil {
+		t.Fatalf("FetchDependencies failed: %v", err)
+	}
+	if len(deps) != 3 {
+		t.Fatalf("sdist deps = %+v", deps)
+	}
+	if deps[1].Name != "pyOpenSSL" || deps[1].Extra != "security" || !deps[1].Optional {
+		t.Errorf("extra dep = %+v", deps[1])
+	}
+	if deps[2].Name != "ipaddress" || deps[2].EnvironmentMarker != `python_version < "3"` {
+		t.Errorf("marker dep = %+v", deps[2])
+	}
+}
+
+func TestFetchStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/pypi/yanked-project/json" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(`{"info": {"name": "yanked-project", "version": "1.0.0"}, "releases": {
+			"1.0.0": [{"filename": "yanked_project-1.0.0.tar.gz", "upload_time_iso_8601": "2024-01-01T00:00:00Z", "yanked": true, "yanked_reason": "broken"}]
+		}}`))
+	}))
+	defer server.Close()
+	ctx := context.Background()
+	reg := New(server.URL, core.DefaultClient())
+
+	status, err := core.FetchStatus(ctx, reg, "yanked-project")
+	if err != nil {
+		t.Fatalf("FetchStatus failed: %v", err)
+	}
+	if status.State != core.PackageYanked || status.Message != "broken" {
+		t.Errorf("yanked-project status = %+v", status)
+	}
+
+	// PyPI answers 404 for deleted projects
+	if _, err := core.FetchStatus(ctx, reg, "deleted-project"); !errors.Is(err, core.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	status, err = core.FetchKnownStatus(ctx, reg, "deleted-project")
+	if err != nil || status.State != core.PackageRemoved {
+		t.Errorf("FetchKnownStatus = %+v, %v; want removed", status, err)
+	}
+}
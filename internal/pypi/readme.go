@@ -0,0 +1,44 @@
+package pypi
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+// FetchReadme returns the project's long description, which is the README
+// for almost every package. Releases that don't declare a content type are
+// reStructuredText, the historical default PyPI renders them with.
+func (r *Registry) FetchReadme(ctx context.Context, name, version string) (*core.Document, error) {
+	url := fmt.Sprintf("%s/pypi/%s/json", r.baseURL, name)
+	if version != "" {
+		url = fmt.Sprintf("%s/pypi/%s/%s/json", r.baseURL, name, version)
+	}
+
+	var resp versionInfoResponse
+	if err := r.client.GetJSON(ctx, url, &resp); err != nil {
+		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
+			return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name, Version: version}
+		}
+		return nil, err
+	}
+
+	// Old uploads without a long description report "UNKNOWN"
+	description := resp.Info.Description
+	if strings.TrimSpace(description) == "" || description == "UNKNOWN" {
+		return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name, Version: version}
+	}
+
+	contentType := core.ReStructuredText
+	if resp.Info.DescriptionContentType != "" {
+		contentType = core.NormalizeContentType(resp.Info.DescriptionContentType)
+	}
+
+	return &core.Document{
+		Content:     description,
+		ContentType: contentType,
+		URL:         url,
+	}, nil
+}
@@ -0,0 +1,229 @@
+// Package racket provides a registry client for Racket packages.
+package racket
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/git-pkgs/registries/internal/core"
+	"github.com/git-pkgs/registries/internal/urlparser"
+)
+
+const (
+	DefaultURL = "https://pkgs.racket-lang.org"
+	ecosystem  = "racket"
+)
+
+func init() {
+	core.Register(ecosystem, DefaultURL, func(baseURL string, client *core.Client) core.Registry {
+		return New(baseURL, client)
+	})
+}
+
+type Registry struct {
+	baseURL string
+	client  *core.Client
+	urls    *URLs
+}
+
+func New(baseURL string, client *core.Client) *Registry {
+	if baseURL == "" {
+		baseURL = DefaultURL
+	}
+	r := &Registry{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client:  client,
+	}
+	r.urls = &URLs{baseURL: r.baseURL}
+	return r
+}
+
+func (r *Registry) Ecosystem() string {
+	return ecosystem
+}
+
+func (r *Registry) URLs() core.URLBuilder {
+	return r.urls
+}
+
+// packageResponse is a package entry from the catalog. Racket packages are
+// not versioned: the catalog tracks a single source and the checksum (usually
+// a git commit) it currently resolves to.
+type packageResponse struct {
+	Name         string            `json:"name"`
+	Description  string            `json:"description"`
+	Source       string            `json:"source"`
+	Checksum     string            `json:"checksum"`
+	Authors      []string          `json:"authors"`
+	Tags         []string          `json:"tags"`
+	Dependencies []json.RawMessage `json:"dependencies"`
+	LastUpdated  int64             `json:"last-updated"`
+	Ring         *int              `json:"ring"`
+	Collection   []any             `json:"collection"`
+}
+
+func (r *Registry) fetchPackage(ctx context.Context, name string) (*packageResponse, error) {
+	url := fmt.Sprintf("%s/pkg/%s.json", r.baseURL, name)
+
+	var resp packageResponse
+	if err := r.client.GetJSON(ctx, url, &resp); err != nil {
+		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
+			return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name}
+		}
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package, error) {
+	resp, err := r.fetchPackage(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := map[string]any{
+		"source": resp.Source,
+	}
+	if resp.Ring != nil {
+		metadata["ring"] = *resp.Ring
+	}
+
+	return &core.Package{
+		Name:          resp.Name,
+		Description:   resp.Description,
+		Homepage:      r.urls.Registry(resp.Name, ""),
+		Repository:    repository(resp.Source),
+		Keywords:      resp.Tags,
+		LatestVersion: resp.Checksum,
+		Metadata:      metadata,
+	}, nil
+}
+
+// repository returns the repository URL of a package source, rewriting the
+// catalog's legacy github://github.com/owner/repo/branch form first.
+func repository(source string) string {
+	if rest, ok := strings.CutPrefix(source, "github://github.com/"); ok {
+		source = "https://github.com/" + rest
+	}
+	return urlparser.Parse(source)
+}
+
+func (r *Registry) FetchVersions(ctx context.Context, name string) ([]core.Version, error) {
+	resp, err := r.fetchPackage(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.Checksum == "" {
+		return nil, nil
+	}
+
+	var publishedAt time.Time
+	if resp.LastUpdated > 0 {
+		publishedAt = time.Unix(resp.LastUpdated, 0).UTC()
+	}
+
+	return []core.Version{{
+		Number:      resp.Checksum,
+		PublishedAt: publishedAt,
+		Metadata: map[string]any{
+			"source": resp.Source,
+		},
+	}}, nil
+}
+
+// FetchDependencies returns the dependencies of the package's current
+// checksum. The catalog keeps no history, so version is not used.
+func (r *Registry) FetchDependencies(ctx context.Context, name, version string) ([]core.Dependency, error) {
+	resp, err := r.fetchPackage(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	var deps []core.Dependency
+	for _, raw := range resp.Dependencies {
+		if dep, ok := parseDependency(raw); ok {
+			deps = append(deps, dep)
+		}
+	}
+
+	return deps, nil
+}
+
+// parseDependency reads an info.rkt dependency, which is either a package
+// name or a list of a name followed by keyword arguments, for example
+// ["base", "#:version", "6.3"] or ["gui-lib", "#:platform", "macosx"].
+func parseDependency(raw json.RawMessage) (core.Dependency, bool) {
+	var name string
+	if err := json.Unmarshal(raw, &name); err == nil {
+		return core.Dependency{Name: name, Scope: core.Runtime}, name != ""
+	}
+
+	var parts []any
+	if err := json.Unmarshal(raw, &parts); err != nil || len(parts) == 0 {
+		return core.Dependency{}, false
+	}
+	name, _ = parts[0].(string)
+	if name == "" {
+		return core.Dependency{}, false
+	}
+
+	dep := core.Dependency{Name: name, Scope: core.Runtime}
+	for i := 1; i+1 < len(parts); i += 2 {
+		keyword, _ := parts[i].(string)
+		switch keyword {
+		case "#:version":
+			if v, ok := parts[i+1].(string); ok {
+				dep.Requirements = ">= " + v
+			}
+		case "#:platform":
+			if v, ok := parts[i+1].(string); ok {
+				dep.Target = v
+			}
+		}
+	}
+	return dep, true
+}
+
+func (r *Registry) FetchMaintainers(ctx context.Context, name string) ([]core.Maintainer, error) {
+	resp, err := r.fetchPackage(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	maintainers := make([]core.Maintainer, 0, len(resp.Authors))
+	for _, author := range resp.Authors {
+		maintainers = append(maintainers, core.Maintainer{
+			Email: author,
+			Role:  "author",
+		})
+	}
+
+	return maintainers, nil
+}
+
+type URLs struct {
+	baseURL string
+}
+
+func (u *URLs) Registry(name, version string) string {
+	return fmt.Sprintf("%s/package/%s", u.baseURL, name)
+}
+
+func (u *URLs) Download(name, version string) string {
+	return ""
+}
+
+func (u *URLs) Documentation(name, version string) string {
+	return ""
+}
+
+func (u *URLs) PURL(name, version string) string {
+	if version != "" {
+		return fmt.Sprintf("pkg:racket/%s@%s", name, version)
+	}
+	return fmt.Sprintf("pkg:racket/%s", name)
+}
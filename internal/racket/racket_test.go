@@ -0,0 +1,189 @@
+package racket
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+const pollenJSON = `{
+	"name": "pollen",
+	"description": "Publishing system for web-based books",
+	"source": "https://github.com/mbutterick/pollen.git",
+	"checksum": "2c9ebb3a5bdbe2e8ae24b6a2cf8b0da3d6ac8f41",
+	"authors": ["mb@mbtype.com"],
+	"tags": ["typography", "publishing"],
+	"dependencies": [
+		"base",
+		["txexpr", "#:version", "0.2"],
+		["gui-lib", "#:platform", "macosx"],
+		"sugar"
+	],
+	"last-updated": 1717000000,
+	"ring": 1
+}`
+
+func TestFetchPackage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/pkg/pollen.json" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+			w.WriteHeader(404)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(pollenJSON))
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	pkg, err := reg.FetchPackage(context.Background(), "pollen")
+	if err != nil {
+		t.Fatalf("FetchPackage failed: %v", err)
+	}
+
+	if pkg.Name != "pollen" {
+		t.Errorf("expected name 'pollen', got %q", pkg.Name)
+	}
+	if pkg.Repository != "https://github.com/mbutterick/pollen" {
+		t.Errorf("unexpected repository: %q", pkg.Repository)
+	}
+	if pkg.Homepage != server.URL+"/package/pollen" {
+		t.Errorf("unexpected homepage: %q", pkg.Homepage)
+	}
+	if pkg.LatestVersion != "2c9ebb3a5bdbe2e8ae24b6a2cf8b0da3d6ac8f41" {
+		t.Errorf("unexpected latest version: %q", pkg.LatestVersion)
+	}
+	if len(pkg.Keywords) != 2 {
+		t.Errorf("expected 2 keywords, got %v", pkg.Keywords)
+	}
+	if pkg.Metadata["ring"] != 1 {
+		t.Errorf("unexpected ring: %v", pkg.Metadata["ring"])
+	}
+}
+
+func TestFetchPackageNotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(404)
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	if _, err := reg.FetchPackage(context.Background(), "nonexistent"); !errors.Is(err, core.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestFetchVersions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(pollenJSON))
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	versions, err := reg.FetchVersions(context.Background(), "pollen")
+	if err != nil {
+		t.Fatalf("FetchVersions failed: %v", err)
+	}
+
+	if len(versions) != 1 {
+		t.Fatalf("expected 1 version, got %d", len(versions))
+	}
+	if versions[0].Number != "2c9ebb3a5bdbe2e8ae24b6a2cf8b0da3d6ac8f41" {
+		t.Errorf("unexpected version: %q", versions[0].Number)
+	}
+	if !versions[0].PublishedAt.Equal(time.Unix(1717000000, 0)) {
+		t.Errorf("unexpected published_at: %v", versions[0].PublishedAt)
+	}
+}
+
+func TestFetchDependencies(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(pollenJSON))
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	deps, err := reg.FetchDependencies(context.Background(), "pollen", "")
+	if err != nil {
+		t.Fatalf("FetchDependencies failed: %v", err)
+	}
+
+	if len(deps) != 4 {
+		t.Fatalf("expected 4 dependencies, got %d", len(deps))
+	}
+	if deps[0].Name != "base" || deps[0].Requirements != "" {
+		t.Errorf("unexpected first dependency: %+v", deps[0])
+	}
+	if deps[1].Name != "txexpr" || deps[1].Requirements != ">= 0.2" {
+		t.Errorf("unexpected versioned dependency: %+v", deps[1])
+	}
+	if deps[2].Name != "gui-lib" || deps[2].Target != "macosx" {
+		t.Errorf("unexpected platform dependency: %+v", deps[2])
+	}
+}
+
+func TestFetchMaintainers(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(pollenJSON))
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	maintainers, err := reg.FetchMaintainers(context.Background(), "pollen")
+	if err != nil {
+		t.Fatalf("FetchMaintainers failed: %v", err)
+	}
+
+	if len(maintainers) != 1 || maintainers[0].Email != "mb@mbtype.com" {
+		t.Errorf("unexpected maintainers: %+v", maintainers)
+	}
+}
+
+func TestRepository(t *testing.T) {
+	tests := map[string]string{
+		"git://github.com/racket/racket/?path=racket/collects": "https://github.com/racket/racket",
+		"github://github.com/mbutterick/pollen/master":         "https://github.com/mbutterick/pollen",
+		"https://github.com/jackfirth/rebellion.git":           "https://github.com/jackfirth/rebellion",
+	}
+	for source, want := range tests {
+		if got := repository(source); got != want {
+			t.Errorf("repository(%q) = %q, want %q", source, got, want)
+		}
+	}
+}
+
+func TestURLBuilder(t *testing.T) {
+	reg := New("", nil)
+	urls := reg.URLs()
+
+	tests := []struct {
+		name     string
+		fn       func() string
+		expected string
+	}{
+		{"registry", func() string { return urls.Registry("pollen", "") }, "https://pkgs.racket-lang.org/package/pollen"},
+		{"download", func() string { return urls.Download("pollen", "abc") }, ""},
+		{"purl", func() string { return urls.PURL("pollen", "abc") }, "pkg:racket/pollen@abc"},
+		{"purl without version", func() string { return urls.PURL("pollen", "") }, "pkg:racket/pollen"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.fn(); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestEcosystem(t *testing.T) {
+	reg := New("", nil)
+	if reg.Ecosystem() != "racket" {
+		t.Errorf("expected ecosystem 'racket', got %q", reg.Ecosystem())
+	}
+}
@@ -0,0 +1,76 @@
+package rubygems
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+// dependencyAPIBatch is the most gems the dependency API accepts at once.
+const dependencyAPIBatch = 200
+
+type dependencyAPIEntry struct {
+	Name         string      `json:"name"`
+	Number       string      `json:"number"`
+	Platform     string      `json:"platform"`
+	Dependencies [][2]string `json:"dependencies"`
+}
+
+// BulkDependencyBatchSize is how many gems the dependency API takes in one
+// request.
+func (r *Registry) BulkDependencyBatchSize() int {
+	return dependencyAPIBatch
+}
+
+// FetchDependenciesBulk looks up the versions of many gems in one request
+// to the dependency API (/api/v1/dependencies.json?gems=a,b,c). The API
+// only lists runtime dependencies, so the development dependencies
+// FetchDependencies returns aren't included. Where a version was built for
+// several platforms, the one selected by the platform qualifier is used.
+func (r *Registry) FetchDependenciesBulk(ctx context.Context, versions []core.PackageVersion) (map[core.PackageVersion][]core.Dependency, error) {
+	wanted := make(map[core.PackageVersion]bool)
+	seen := make(map[string]bool)
+	var names []string
+	for _, pv := range versions {
+		wanted[pv] = true
+		if !seen[pv.Name] {
+			seen[pv.Name] = true
+			names = append(names, pv.Name)
+		}
+	}
+
+	apiURL := fmt.Sprintf("%s/api/v1/dependencies.json?gems=%s", r.baseURL, url.QueryEscape(strings.Join(names, ",")))
+	var entries []dependencyAPIEntry
+	if err := r.client.GetJSON(ctx, apiURL, &entries); err != nil {
+		return nil, err
+	}
+
+	platform := r.urls.platform
+	if platform == "" {
+		platform = "ruby"
+	}
+
+	result := make(map[core.PackageVersion][]core.Dependency)
+	for _, e := range entries {
+		pv := core.PackageVersion{Name: e.Name, Version: e.Number}
+		if !wanted[pv] {
+			continue
+		}
+		if _, ok := result[pv]; ok && e.Platform != platform {
+			continue
+		}
+		var deps []core.Dependency
+		for _, d := range e.Dependencies {
+			deps = append(deps, core.Dependency{
+				Name:         d[0],
+				Requirements: d[1],
+				Scope:        core.Runtime,
+			})
+		}
+		result[pv] = deps
+	}
+	return result, nil
+}
@@ -0,0 +1,44 @@
+package rubygems
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+// FetchChangelog returns the notes for a version from the changelog its
+// gemspec links to as changelog_uri, such as a CHANGELOG.md on GitHub,
+// cut down to the version's section. Gems without one, or whose link
+// isn't a changelog file, fall back to the releases and changelog of
+// their source repository.
+func (r *Registry) FetchChangelog(ctx context.Context, name, version string) (*core.Document, error) {
+	url := fmt.Sprintf("%s/api/v1/gems/%s.json", r.baseURL, name)
+	if version != "" {
+		url = fmt.Sprintf("%s/api/v2/rubygems/%s/versions/%s.json", r.baseURL, name, version)
+	}
+
+	var resp gemResponse
+	if err := r.client.GetJSON(ctx, url, &resp); err != nil {
+		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
+			return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name, Version: version}
+		}
+		return nil, err
+	}
+	version = resp.Version
+
+	changelog := resp.ChangelogURI
+	if changelog == "" {
+		changelog = resp.Metadata["changelog_uri"]
+	}
+	if changelog != "" {
+		doc, err := core.ChangelogFromURL(ctx, r.client, changelog, version)
+		if !errors.Is(err, core.ErrNotFound) {
+			return doc, err
+		}
+	}
+
+	repoURL := extractRepoURL(resp.SourceCodeURI, resp.Metadata["source_code_uri"], resp.HomepageURI)
+	return core.RepositoryChangelog(ctx, r.client, repoURL, version)
+}
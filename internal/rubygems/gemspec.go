@@ -0,0 +1,25 @@
+package rubygems
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/git-pkgs/registries/inspect"
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+// FetchGemspec downloads a version's .gem and reads the gemspec stored in
+// it. FetchDependencies falls back to it for yanked versions, which the API
+// no longer describes, and for versions whose API response has no
+// dependency data. The manifest also carries the declared licenses.
+func (r *Registry) FetchGemspec(ctx context.Context, name, version string) (*inspect.Manifest, error) {
+	url := r.urls.Download(name, version)
+	body, err := r.client.GetBody(ctx, url)
+	if err != nil {
+		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
+			return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name, Version: version}
+		}
+		return nil, err
+	}
+	return inspect.Read(bytes.NewReader(body), url)
+}
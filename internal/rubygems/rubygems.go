@@ -4,9 +4,11 @@ package rubygems
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
+	"github.com/git-pkgs/purl"
 	"github.com/git-pkgs/registries/internal/core"
 	"github.com/git-pkgs/registries/internal/urlparser"
 )
@@ -40,6 +42,19 @@ func New(baseURL string, client *core.Client) *Registry {
 	return r
 }
 
+// WithQualifiers applies the platform qualifier of a PURL, selecting a
+// precompiled gem such as nokogiri's "x86_64-linux" build. The default
+// "ruby" platform is the source gem.
+func (r *Registry) WithQualifiers(qualifiers map[string]string) core.Registry {
+	copy := *r
+	urls := *r.urls
+	if p := qualifiers["platform"]; p != "" && p != "ruby" {
+		urls.platform = p
+	}
+	copy.urls = &urls
+	return &copy
+}
+
 func (r *Registry) Ecosystem() string {
 	return ecosystem
 }
@@ -64,6 +79,7 @@ type gemResponse struct {
 	FundingURI     string            `json:"funding_uri"`
 	Metadata       map[string]string `json:"metadata"`
 	Dependencies   dependenciesBlock `json:"dependencies"`
+	VersionCreatedAt string          `json:"version_created_at"`
 }
 
 type dependenciesBlock struct {
@@ -95,7 +111,7 @@ type ownerResponse struct {
 }
 
 type dependencyVersionResponse struct {
-	Dependencies dependenciesBlock `json:"dependencies"`
+	Dependencies *dependenciesBlock `json:"dependencies"`
 }
 
 func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package, error) {
@@ -110,17 +126,30 @@ func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package
 	}
 
 	repoURL := extractRepoURL(resp.SourceCodeURI, resp.WikiURI, resp.DocumentURI, resp.BugTrackerURI, resp.ChangelogURI, resp.HomepageURI)
+	latestReleased, _ := time.Parse(time.RFC3339, resp.VersionCreatedAt)
+
+	// The API's version is the newest release, a pre-release only when
+	// the gem has nothing else
+	var stable string
+	if !core.IsPrerelease(ecosystem, resp.Version) {
+		stable = resp.Version
+	}
 
 	return &core.Package{
-		Name:        resp.Name,
-		Description: resp.Info,
-		Homepage:    resp.HomepageURI,
-		Repository:  repoURL,
-		Licenses:    strings.Join(resp.Licenses, ","),
+		Name:                resp.Name,
+		Description:         resp.Info,
+		Homepage:            resp.HomepageURI,
+		Repository:          repoURL,
+		Documentation:       resp.DocumentURI,
+		Licenses:            strings.Join(resp.Licenses, ","),
+		LatestVersion:       resp.Version,
+		LatestStableVersion: stable,
 		Metadata: map[string]any{
 			"downloads":   resp.Downloads,
 			"funding_uri": resp.FundingURI,
 		},
+		Popularity:       &core.Popularity{DownloadsTotal: int64(resp.Downloads)},
+		LatestReleasedAt: latestReleased,
 	}, nil
 }
 
@@ -128,14 +157,12 @@ func extractRepoURL(urls ...string) string {
 	return urlparser.FirstRepoURL(urls...)
 }
 
+// FetchVersions lists the versions the gem has on the index. Yanked
+// versions are dropped from the API rather than marked, so none has
+// StatusYanked; see FetchYanked.
 func (r *Registry) FetchVersions(ctx context.Context, name string) ([]core.Version, error) {
-	url := fmt.Sprintf("%s/api/v1/versions/%s.json", r.baseURL, name)
-
-	var resp []versionResponse
-	if err := r.client.GetJSON(ctx, url, &resp); err != nil {
-		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
-			return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name}
-		}
+	resp, err := r.fetchVersionList(ctx, name)
+	if err != nil {
 		return nil, err
 	}
 
@@ -146,44 +173,81 @@ func (r *Registry) FetchVersions(ctx context.Context, name string) ([]core.Versi
 			publishedAt, _ = time.Parse(time.RFC3339, v.CreatedAt)
 		}
 
-		number := v.Number
-		if v.Platform != "" && v.Platform != "ruby" {
-			number = fmt.Sprintf("%s-%s", v.Number, v.Platform)
-		}
+		number := v.number()
 
 		var integrity string
 		if v.SHA != "" {
-			integrity = "sha256-" + v.SHA
+			integrity = core.HexIntegrity("sha256", v.SHA)
+		}
+
+		metadata := map[string]any{
+			"platform":         v.Platform,
+			"downloads":        v.Downloads,
+			"ruby_version":     v.RubyVersion,
+			"rubygems_version": v.RubygemsVersion,
+			"prerelease":       v.Prerelease,
 		}
+		// Gems built for a platform other than "ruby" or "java" ship
+		// precompiled extensions. Source extensions, compiled on install,
+		// are only listed in the gemspec; see FetchGemspec.
+		core.SetInstallSignals(metadata, nil, v.Platform != "" && v.Platform != "ruby" && v.Platform != "java")
 
 		versions[i] = core.Version{
 			Number:      number,
 			PublishedAt: publishedAt,
 			Licenses:    strings.Join(v.Licenses, ","),
 			Integrity:   integrity,
-			Metadata: map[string]any{
-				"platform":         v.Platform,
-				"downloads":        v.Downloads,
-				"ruby_version":     v.RubyVersion,
-				"rubygems_version": v.RubygemsVersion,
-				"prerelease":       v.Prerelease,
-			},
+			Metadata:    metadata,
 		}
 	}
 
 	return versions, nil
 }
 
+func (r *Registry) fetchVersionList(ctx context.Context, name string) ([]versionResponse, error) {
+	url := fmt.Sprintf("%s/api/v1/versions/%s.json", r.baseURL, name)
+
+	var resp []versionResponse
+	if err := r.client.GetJSON(ctx, url, &resp); err != nil {
+		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
+			return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name}
+		}
+		return nil, err
+	}
+	return resp, nil
+}
+
+// number returns the version as FetchVersions lists it, with the platform
+// appended for platform-specific gems.
+func (v versionResponse) number() string {
+	if v.Platform != "" && v.Platform != "ruby" {
+		return fmt.Sprintf("%s-%s", v.Number, v.Platform)
+	}
+	return v.Number
+}
+
 func (r *Registry) FetchDependencies(ctx context.Context, name, version string) ([]core.Dependency, error) {
 	url := fmt.Sprintf("%s/api/v2/rubygems/%s/versions/%s.json", r.baseURL, name, version)
 
 	var resp dependencyVersionResponse
 	if err := r.client.GetJSON(ctx, url, &resp); err != nil {
 		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
+			// Yanked versions are gone from the API but their .gem may
+			// still be downloadable
+			if spec, err := r.FetchGemspec(ctx, name, version); err == nil {
+				return spec.Dependencies, nil
+			}
 			return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name, Version: version}
 		}
 		return nil, err
 	}
+	if resp.Dependencies == nil {
+		spec, err := r.FetchGemspec(ctx, name, version)
+		if err != nil {
+			return nil, err
+		}
+		return spec.Dependencies, nil
+	}
 
 	var deps []core.Dependency
 
@@ -228,13 +292,44 @@ func (r *Registry) FetchMaintainers(ctx context.Context, name string) ([]core.Ma
 	return maintainers, nil
 }
 
+// ListPackagesByNamespace returns the gems owned by a RubyGems user, given
+// by handle or numeric ID.
+func (r *Registry) ListPackagesByNamespace(ctx context.Context, namespace string) ([]string, error) {
+	url := fmt.Sprintf("%s/api/v1/owners/%s/gems.json", r.baseURL, namespace)
+
+	var resp []gemResponse
+	if err := r.client.GetJSON(ctx, url, &resp); err != nil {
+		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
+			return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: namespace}
+		}
+		return nil, err
+	}
+
+	names := make([]string, len(resp))
+	for i, g := range resp {
+		names[i] = g.Name
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
 type URLs struct {
-	baseURL string
+	baseURL  string
+	platform string
+}
+
+// platformVersion appends the platform to a version the way RubyGems names
+// platform-specific gem files, e.g. "1.15.0-x86_64-linux".
+func (u *URLs) platformVersion(version string) string {
+	if u.platform == "" {
+		return version
+	}
+	return version + "-" + u.platform
 }
 
 func (u *URLs) Registry(name, version string) string {
 	if version != "" {
-		return fmt.Sprintf("%s/gems/%s/versions/%s", u.baseURL, name, version)
+		return fmt.Sprintf("%s/gems/%s/versions/%s", u.baseURL, name, u.platformVersion(version))
 	}
 	return fmt.Sprintf("%s/gems/%s", u.baseURL, name)
 }
@@ -243,7 +338,7 @@ func (u *URLs) Download(name, version string) string {
 	if version == "" {
 		return ""
 	}
-	return fmt.Sprintf("%s/downloads/%s-%s.gem", u.baseURL, name, version)
+	return fmt.Sprintf("%s/downloads/%s-%s.gem", u.baseURL, name, u.platformVersion(version))
 }
 
 func (u *URLs) Documentation(name, version string) string {
@@ -254,8 +349,9 @@ func (u *URLs) Documentation(name, version string) string {
 }
 
 func (u *URLs) PURL(name, version string) string {
-	if version != "" {
-		return fmt.Sprintf("pkg:gem/%s@%s", name, version)
+	var qualifiers map[string]string
+	if u.platform != "" {
+		qualifiers = map[string]string{"platform": u.platform}
 	}
-	return fmt.Sprintf("pkg:gem/%s", name)
+	return purl.New(ecosystem, "", name, version, qualifiers).String()
 }
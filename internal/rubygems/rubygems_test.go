@@ -1,8 +1,13 @@
 package rubygems
 
 import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -96,7 +101,7 @@ func TestFetchVersions(t *testing.T) {
 	if versions[1].Number != "1.13.6-x86_64-linux" {
 		t.Errorf("expected version '1.13.6-x86_64-linux', got %q", versions[1].Number)
 	}
-	if versions[0].Integrity != "sha256-b1512fdc0aba446e1ee30de3e0671518eb363e75fab53486e99e8891d44b8587" {
+	if versions[0].Integrity != "sha256-sVEv3Aq6RG4e4w3j4GcVGOs2PnX6tTSG6Z6IkdRLhYc=" {
 		t.Errorf("unexpected integrity: %q", versions[0].Integrity)
 	}
 }
@@ -110,7 +115,7 @@ func TestFetchDependencies(t *testing.T) {
 		}
 
 		resp := dependencyVersionResponse{
-			Dependencies: dependenciesBlock{
+			Dependencies: &dependenciesBlock{
 				Runtime: []gemDep{
 					{Name: "activesupport", Requirements: "= 7.1.0"},
 					{Name: "actionpack", Requirements: "= 7.1.0"},
@@ -188,6 +193,31 @@ func TestFetchMaintainers(t *testing.T) {
 	}
 }
 
+func TestListPackagesByNamespace(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/owners/dhh/gems.json" {
+			w.WriteHeader(404)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[{"name":"rails","version":"7.1.0"},{"name":"actioncable","version":"7.1.0"}]`))
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	names, err := reg.ListPackagesByNamespace(context.Background(), "dhh")
+	if err != nil {
+		t.Fatalf("ListPackagesByNamespace failed: %v", err)
+	}
+	if len(names) != 2 || names[0] != "actioncable" || names[1] != "rails" {
+		t.Errorf("unexpected names %v", names)
+	}
+
+	if _, err := reg.ListPackagesByNamespace(context.Background(), "nobody"); !errors.Is(err, core.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
 func TestURLBuilder(t *testing.T) {
 	reg := New("https://rubygems.org", nil)
 	urls := reg.URLs()
@@ -213,9 +243,114 @@ func TestURLBuilder(t *testing.T) {
 	}
 }
 
+func TestWithQualifiers(t *testing.T) {
+	urls := New("https://rubygems.org", nil).WithQualifiers(map[string]string{"platform": "x86_64-linux"}).URLs()
+
+	if got := urls.Download("nokogiri", "1.15.0"); got != "https://rubygems.org/downloads/nokogiri-1.15.0-x86_64-linux.gem" {
+		t.Errorf("unexpected download URL: %q", got)
+	}
+	if got := urls.Registry("nokogiri", "1.15.0"); got != "https://rubygems.org/gems/nokogiri/versions/1.15.0-x86_64-linux" {
+		t.Errorf("unexpected registry URL: %q", got)
+	}
+	if got := urls.PURL("nokogiri", "1.15.0"); got != "pkg:gem/nokogiri@1.15.0?platform=x86_64-linux" {
+		t.Errorf("unexpected PURL: %q", got)
+	}
+
+	ruby := New("https://rubygems.org", nil).WithQualifiers(map[string]string{"platform": "ruby"}).URLs()
+	if got := ruby.PURL("nokogiri", "1.15.0"); got != "pkg:gem/nokogiri@1.15.0" {
+		t.Errorf("expected the ruby platform to be omitted, got %q", got)
+	}
+}
+
 func TestEcosystem(t *testing.T) {
 	reg := New("", nil)
 	if reg.Ecosystem() != "gem" {
 		t.Errorf("expected ecosystem 'gem', got %q", reg.Ecosystem())
 	}
 }
+
+func TestFetchDependenciesFromGem(t *testing.T) {
+	spec := `--- !ruby/object:Gem::Specification
+name: oldgem
+version: !ruby/object:Gem::Version
+  version: 0.1.0
+dependencies:
+- !ruby/object:Gem::Dependency
+  name: json
+  requirement: !ruby/object:Gem::Requirement
+    requirements:
+    - - "~>"
+      - !ruby/object:Gem::Version
+        version: '1.8'
+  type: :runtime
+licenses:
+- MIT
+`
+	var metadata bytes.Buffer
+	gz := gzip.NewWriter(&metadata)
+	_, _ = gz.Write([]byte(spec))
+	_ = gz.Close()
+	var gem bytes.Buffer
+	tw := tar.NewWriter(&gem)
+	_ = tw.WriteHeader(&tar.Header{Name: "metadata.gz", Mode: 0o644, Size: int64(metadata.Len()), Typeflag: tar.TypeReg})
+	_, _ = tw.Write(metadata.Bytes())
+	_ = tw.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/downloads/oldgem-0.1.0.gem":
+			_, _ = w.Write(gem.Bytes())
+		case "/api/v2/rubygems/oldgem/versions/0.2.0.json":
+			// An API response without dependency data
+			_, _ = w.Write([]byte(`{"name":"oldgem","version":"0.2.0"}`))
+		case "/downloads/oldgem-0.2.0.gem":
+			_, _ = w.Write(gem.Bytes())
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	for _, version := range []string{"0.1.0", "0.2.0"} {
+		deps, err := reg.FetchDependencies(context.Background(), "oldgem", version)
+		if err != nil {
+			t.Fatalf("%s: %v", version, err)
+		}
+		if len(deps) != 1 || deps[0].Name != "json" || deps[0].Requirements != "~> 1.8" || deps[0].Scope != core.Runtime {
+			t.Errorf("%s: deps = %+v", version, deps)
+		}
+	}
+
+	if _, err := reg.FetchDependencies(context.Background(), "oldgem", "9.9.9"); !errors.Is(err, core.ErrNotFound) {
+		t.Errorf("expected ErrNotFound when neither API nor .gem has the version, got %v", err)
+	}
+}
+
+func TestFetchChangelog(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v2/rubygems/rack/versions/3.0.0.json":
+			_, _ = fmt.Fprintf(w, `{"name": "rack", "version": "3.0.0", "changelog_uri": "%s/rack/CHANGELOG.md"}`, server.URL)
+		case "/rack/CHANGELOG.md":
+			_, _ = w.Write([]byte("# Changelog\n\n## [3.0.1]\n\n- Fix\n\n## [3.0.0]\n\n- Rack 3\n"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	doc, err := reg.FetchChangelog(context.Background(), "rack", "3.0.0")
+	if err != nil {
+		t.Fatalf("FetchChangelog failed: %v", err)
+	}
+	if doc.Content != "## [3.0.0]\n\n- Rack 3" || doc.ContentType != core.Markdown {
+		t.Errorf("unexpected document: %+v", doc)
+	}
+
+	if _, err := reg.FetchChangelog(context.Background(), "rack", "0.0.1"); !errors.Is(err, core.ErrNotFound) {
+		t.Errorf("expected not found for unknown version, got %v", err)
+	}
+}
@@ -0,0 +1,36 @@
+package rubygems
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+// FetchStatus reports gems whose every version was yanked. The gem and
+// version endpoints answer those with 404, as if the gem never existed, but
+// the compact index still serves the gem's info file with no versions in
+// it, so only a 404 there means the gem is unknown.
+func (r *Registry) FetchStatus(ctx context.Context, name string) (*core.PackageStatus, error) {
+	versions, err := r.FetchVersions(ctx, name)
+	var notFound *core.NotFoundError
+	if errors.As(err, &notFound) {
+		if _, err := r.client.GetBody(ctx, fmt.Sprintf("%s/info/%s", r.baseURL, name)); err != nil {
+			if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
+				return nil, notFound
+			}
+			return nil, err
+		}
+		return &core.PackageStatus{State: core.PackageYanked}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	status := core.StatusFromVersions(versions)
+	if pkg, err := r.FetchPackage(ctx, name); err == nil {
+		status.Repository = pkg.Repository
+	}
+	return status, nil
+}
@@ -0,0 +1,50 @@
+package rubygems
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+func TestFetchStatus(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/versions/rails.json", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[{"number": "7.1.0", "platform": "ruby"}]`))
+	})
+	mux.HandleFunc("/api/v1/gems/rails.json", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"name": "rails", "version": "7.1.0", "source_code_uri": "https://github.com/rails/rails"}`))
+	})
+	// Every version of yanked-gem was yanked: only the compact index
+	// still knows it
+	mux.HandleFunc("/info/yanked-gem", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("---\n"))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	ctx := context.Background()
+	reg := New(server.URL, core.DefaultClient())
+
+	status, err := reg.FetchStatus(ctx, "rails")
+	if err != nil {
+		t.Fatalf("FetchStatus failed: %v", err)
+	}
+	if status.State != core.PackageActive || status.LatestVersion != "7.1.0" || status.Repository != "https://github.com/rails/rails" {
+		t.Errorf("rails status = %+v", status)
+	}
+
+	status, err = reg.FetchStatus(ctx, "yanked-gem")
+	if err != nil {
+		t.Fatalf("FetchStatus failed: %v", err)
+	}
+	if status.State != core.PackageYanked {
+		t.Errorf("yanked-gem state = %s, want yanked", status.State)
+	}
+
+	if _, err := reg.FetchStatus(ctx, "no-such-gem"); !errors.Is(err, core.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
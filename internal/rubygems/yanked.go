@@ -0,0 +1,73 @@
+package rubygems
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+// RubyGems has no API listing yanked versions: yanking removes a version
+// from the index and from every version endpoint. What remains is the
+// gem's download total, which still counts the yanked versions' downloads,
+// and the versions a caller saw before.
+
+// VersionTotals counts a gem's versions and downloads. Compared between
+// polls, a lower Versions count or a growing YankedDownloads shows that
+// versions were removed.
+type VersionTotals struct {
+	Versions        int   // versions on the index, counting each platform
+	Downloads       int64 // downloads of every version ever published
+	ListedDownloads int64 // downloads of the versions on the index
+}
+
+// YankedDownloads returns the downloads of versions no longer on the index.
+// It is above zero when some version of the gem has been yanked.
+func (t VersionTotals) YankedDownloads() int64 {
+	return max(t.Downloads-t.ListedDownloads, 0)
+}
+
+// FetchVersionTotals returns a gem's version count and its downloads with
+// and without yanked versions. It makes two requests.
+func (r *Registry) FetchVersionTotals(ctx context.Context, name string) (*VersionTotals, error) {
+	var gem gemResponse
+	if err := r.client.GetJSON(ctx, fmt.Sprintf("%s/api/v1/gems/%s.json", r.baseURL, name), &gem); err != nil {
+		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
+			return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name}
+		}
+		return nil, err
+	}
+	versions, err := r.fetchVersionList(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	totals := &VersionTotals{Versions: len(versions), Downloads: int64(gem.Downloads)}
+	for _, v := range versions {
+		totals.ListedDownloads += int64(v.Downloads)
+	}
+	return totals, nil
+}
+
+// FetchYanked returns the versions in known, as FetchVersions listed them
+// earlier, that the gem no longer lists. Yanking is the only way a version
+// leaves RubyGems, so each is returned with StatusYanked.
+func (r *Registry) FetchYanked(ctx context.Context, name string, known []string) ([]core.Version, error) {
+	resp, err := r.fetchVersionList(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	listed := make(map[string]bool, len(resp))
+	for _, v := range resp {
+		listed[v.number()] = true
+	}
+
+	var yanked []core.Version
+	for _, number := range known {
+		if !listed[number] && !slices.ContainsFunc(yanked, func(v core.Version) bool { return v.Number == number }) {
+			yanked = append(yanked, core.Version{Number: number, Status: core.StatusYanked})
+		}
+	}
+	return yanked, nil
+}
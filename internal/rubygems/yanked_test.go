@@ -0,0 +1,47 @@
+package rubygems
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+func TestYankedVersions(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/gems/rest-client.json", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"name": "rest-client", "version": "1.6.13", "downloads": 1500}`))
+	})
+	// 1.6.10 and 1.6.11 were yanked; their 300 downloads still count in
+	// the gem's total
+	mux.HandleFunc("/api/v1/versions/rest-client.json", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[
+			{"number": "1.6.13", "platform": "ruby", "downloads_count": 700},
+			{"number": "1.6.13", "platform": "x64-mingw32", "downloads_count": 100},
+			{"number": "1.6.9", "platform": "ruby", "downloads_count": 400}
+		]`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	ctx := context.Background()
+
+	reg := New(server.URL, core.DefaultClient())
+	totals, err := reg.FetchVersionTotals(ctx, "rest-client")
+	if err != nil {
+		t.Fatalf("FetchVersionTotals failed: %v", err)
+	}
+	if *totals != (VersionTotals{Versions: 3, Downloads: 1500, ListedDownloads: 1200}) || totals.YankedDownloads() != 300 {
+		t.Errorf("totals = %+v", totals)
+	}
+
+	known := []string{"1.6.9", "1.6.10", "1.6.11", "1.6.10", "1.6.13-x64-mingw32"}
+	yanked, err := reg.FetchYanked(ctx, "rest-client", known)
+	if err != nil {
+		t.Fatalf("FetchYanked failed: %v", err)
+	}
+	if len(yanked) != 2 || yanked[0].Number != "1.6.10" || yanked[1].Number != "1.6.11" || yanked[0].Status != core.StatusYanked {
+		t.Errorf("yanked = %+v", yanked)
+	}
+}
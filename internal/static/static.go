@@ -0,0 +1,244 @@
+// Package static provides a registry client that serves package metadata
+// from a directory of JSON or TOML files, for tests, air-gapped
+// environments and internal packages that aren't on any public registry.
+//
+// Each package is one file named after it, with the package fields of the
+// JSON encoding of core.Package at the top level alongside its versions and
+// maintainers. A scoped or namespaced name is a path: "@acme/widgets" is
+// read from @acme/widgets.json or @acme/widgets.toml.
+//
+//	name = "widgets"
+//	description = "Internal widget toolkit"
+//	licenses = "MIT"
+//
+//	[[versions]]
+//	number = "1.1.0"
+//	published_at = 2024-05-01T09:30:00Z
+//
+//	[[versions.dependencies]]
+//	name = "left-pad"
+//	requirements = "^1.3.0"
+//	scope = "runtime"
+package static
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+const (
+	// DefaultURL is the current directory.
+	DefaultURL = "."
+	ecosystem  = "static"
+)
+
+func init() {
+	core.Register(ecosystem, DefaultURL, func(baseURL string, client *core.Client) core.Registry {
+		return New(baseURL, client)
+	})
+}
+
+// Registry reads packages from the files in one directory. The client is
+// unused, as nothing is fetched over the network.
+type Registry struct {
+	dir  string
+	urls *URLs
+}
+
+// New returns a registry for the directory baseURL, given as a path or a
+// file:// URL. An empty baseURL is the current directory.
+func New(baseURL string, client *core.Client) *Registry {
+	dir := baseURL
+	if u, err := url.Parse(baseURL); err == nil && u.Scheme == "file" {
+		dir = u.Path
+	}
+	if dir == "" {
+		dir = DefaultURL
+	}
+	return &Registry{dir: dir, urls: &URLs{}}
+}
+
+func (r *Registry) Ecosystem() string {
+	return ecosystem
+}
+
+func (r *Registry) URLs() core.URLBuilder {
+	return r.urls
+}
+
+// packageFile is what one file describes.
+type packageFile struct {
+	pkg          core.Package
+	versions     []core.Version
+	dependencies map[string][]core.Dependency
+	maintainers  []core.Maintainer
+}
+
+// load reads and decodes the file for name, trying name.json then
+// name.toml. Names can't reach outside the directory.
+func (r *Registry) load(name string) (*packageFile, error) {
+	if name == "" {
+		return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name}
+	}
+	root, err := os.OpenRoot(r.dir)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = root.Close() }()
+
+	for _, ext := range []string{".json", ".toml"} {
+		data, err := root.ReadFile(name + ext)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		if ext == ".toml" {
+			if data, err = tomlToJSON(data); err != nil {
+				return nil, fmt.Errorf("static: %s%s: %w", name, ext, err)
+			}
+		}
+		file, err := parsePackageFile(data)
+		if err != nil {
+			return nil, fmt.Errorf("static: %s%s: %w", name, ext, err)
+		}
+		if file.pkg.Name == "" {
+			file.pkg.Name = name
+		}
+		return file, nil
+	}
+	return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name}
+}
+
+// tomlToJSON converts a TOML document to JSON so both formats share the
+// JSON field names. TOML datetimes become RFC 3339 strings.
+func tomlToJSON(data []byte) ([]byte, error) {
+	var doc map[string]any
+	if _, err := toml.NewDecoder(bytes.NewReader(data)).Decode(&doc); err != nil {
+		return nil, err
+	}
+	return json.Marshal(doc)
+}
+
+func parsePackageFile(data []byte) (*packageFile, error) {
+	file := &packageFile{dependencies: make(map[string][]core.Dependency)}
+	if err := json.Unmarshal(data, &file.pkg); err != nil {
+		return nil, err
+	}
+
+	var rest struct {
+		Versions    []json.RawMessage `json:"versions"`
+		Maintainers []core.Maintainer `json:"maintainers"`
+	}
+	if err := json.Unmarshal(data, &rest); err != nil {
+		return nil, err
+	}
+	file.maintainers = rest.Maintainers
+
+	for _, raw := range rest.Versions {
+		var v core.Version
+		if err := json.Unmarshal(raw, &v); err != nil {
+			return nil, err
+		}
+		if v.Number == "" {
+			return nil, errors.New("version without a number")
+		}
+		var deps struct {
+			Dependencies []core.Dependency `json:"dependencies"`
+		}
+		if err := json.Unmarshal(raw, &deps); err != nil {
+			return nil, err
+		}
+		file.versions = append(file.versions, v)
+		file.dependencies[v.Number] = deps.Dependencies
+	}
+	return file, nil
+}
+
+// FetchPackage returns the package a file describes. LatestVersion and
+// LatestStableVersion are worked out from its versions unless the file sets
+// them.
+func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package, error) {
+	file, err := r.load(name)
+	if err != nil {
+		return nil, err
+	}
+	pkg := file.pkg
+
+	numbers := make([]string, len(file.versions))
+	for i, v := range file.versions {
+		numbers[i] = v.Number
+	}
+	if pkg.LatestVersion == "" {
+		pkg.LatestVersion = core.LatestOf(ecosystem, numbers)
+	}
+	if pkg.LatestStableVersion == "" {
+		pkg.LatestStableVersion = core.LatestStableOf(ecosystem, numbers)
+	}
+	return &pkg, nil
+}
+
+// FetchVersions returns the versions a file lists, in file order.
+func (r *Registry) FetchVersions(ctx context.Context, name string) ([]core.Version, error) {
+	file, err := r.load(name)
+	if err != nil {
+		return nil, err
+	}
+	return file.versions, nil
+}
+
+// FetchDependencies returns the dependencies listed under a version.
+func (r *Registry) FetchDependencies(ctx context.Context, name, version string) ([]core.Dependency, error) {
+	file, err := r.load(name)
+	if err != nil {
+		return nil, err
+	}
+	deps, ok := file.dependencies[version]
+	if !ok {
+		return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name, Version: version}
+	}
+	return deps, nil
+}
+
+// FetchMaintainers returns the maintainers a file lists.
+func (r *Registry) FetchMaintainers(ctx context.Context, name string) ([]core.Maintainer, error) {
+	file, err := r.load(name)
+	if err != nil {
+		return nil, err
+	}
+	return file.maintainers, nil
+}
+
+// URLs has no registry pages or downloads to point at, only PURLs.
+type URLs struct{}
+
+func (u *URLs) Registry(name, version string) string {
+	return ""
+}
+
+func (u *URLs) Download(name, version string) string {
+	return ""
+}
+
+func (u *URLs) Documentation(name, version string) string {
+	return ""
+}
+
+func (u *URLs) PURL(name, version string) string {
+	name = strings.TrimPrefix(name, "/")
+	if version != "" {
+		return fmt.Sprintf("pkg:static/%s@%s", name, version)
+	}
+	return fmt.Sprintf("pkg:static/%s", name)
+}
@@ -0,0 +1,137 @@
+package static
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestJSONPackage(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "@acme/widgets.json", `{
+  "description": "Internal widget toolkit",
+  "licenses": "MIT",
+  "metadata": {"team": "platform", "tier": 1},
+  "maintainers": [{"login": "alice"}],
+  "versions": [
+    {"number": "1.0.0", "published_at": "2024-01-10T00:00:00Z", "dependencies": [{"name": "left-pad", "requirements": "^1.3.0", "scope": "runtime"}]},
+    {"number": "2.0.0-beta.1", "published_at": "2024-06-01T00:00:00Z"},
+    {"number": "1.1.0", "published_at": "2024-03-01T00:00:00Z", "status": "deprecated"}
+  ]
+}`)
+	reg := New("file://"+dir, nil)
+	ctx := context.Background()
+
+	pkg, err := reg.FetchPackage(ctx, "@acme/widgets")
+	if err != nil {
+		t.Fatalf("FetchPackage failed: %v", err)
+	}
+	if pkg.Name != "@acme/widgets" || pkg.Description != "Internal widget toolkit" || pkg.Licenses != "MIT" {
+		t.Errorf("unexpected package: %+v", pkg)
+	}
+	if pkg.LatestVersion != "2.0.0-beta.1" || pkg.LatestStableVersion != "1.1.0" {
+		t.Errorf("latest = %q, stable = %q", pkg.LatestVersion, pkg.LatestStableVersion)
+	}
+	if pkg.Metadata["tier"] != 1 {
+		t.Errorf("metadata = %v", pkg.Metadata)
+	}
+
+	versions, err := reg.FetchVersions(ctx, "@acme/widgets")
+	if err != nil {
+		t.Fatalf("FetchVersions failed: %v", err)
+	}
+	if len(versions) != 3 || versions[2].Status != core.StatusDeprecated {
+		t.Errorf("unexpected versions: %+v", versions)
+	}
+	if !versions[0].PublishedAt.Equal(time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("published = %v", versions[0].PublishedAt)
+	}
+
+	deps, err := reg.FetchDependencies(ctx, "@acme/widgets", "1.0.0")
+	if err != nil {
+		t.Fatalf("FetchDependencies failed: %v", err)
+	}
+	if len(deps) != 1 || deps[0].Name != "left-pad" || deps[0].Scope != core.Runtime {
+		t.Errorf("unexpected dependencies: %+v", deps)
+	}
+	if _, err := reg.FetchDependencies(ctx, "@acme/widgets", "9.9.9"); !errors.Is(err, core.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for a missing version, got %v", err)
+	}
+
+	maintainers, err := reg.FetchMaintainers(ctx, "@acme/widgets")
+	if err != nil || len(maintainers) != 1 || maintainers[0].Login != "alice" {
+		t.Errorf("maintainers = %+v, %v", maintainers, err)
+	}
+
+	if got := reg.URLs().PURL("@acme/widgets", "1.0.0"); got != "pkg:static/@acme/widgets@1.0.0" {
+		t.Errorf("PURL = %q", got)
+	}
+}
+
+func TestTOMLPackage(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "widgets.toml", `
+description = "Internal widget toolkit"
+
+[[versions]]
+number = "1.1.0"
+published_at = 2024-05-01T09:30:00Z
+
+[[versions.dependencies]]
+name = "left-pad"
+requirements = "^1.3.0"
+scope = "runtime"
+`)
+	reg := New(dir, nil)
+	ctx := context.Background()
+
+	pkg, err := reg.FetchPackage(ctx, "widgets")
+	if err != nil {
+		t.Fatalf("FetchPackage failed: %v", err)
+	}
+	if pkg.Name != "widgets" || pkg.LatestVersion != "1.1.0" {
+		t.Errorf("unexpected package: %+v", pkg)
+	}
+
+	versions, err := reg.FetchVersions(ctx, "widgets")
+	if err != nil {
+		t.Fatalf("FetchVersions failed: %v", err)
+	}
+	if len(versions) != 1 || !versions[0].PublishedAt.Equal(time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC)) {
+		t.Errorf("unexpected versions: %+v", versions)
+	}
+
+	deps, err := reg.FetchDependencies(ctx, "widgets", "1.1.0")
+	if err != nil || len(deps) != 1 || deps[0].Requirements != "^1.3.0" {
+		t.Errorf("dependencies = %+v, %v", deps, err)
+	}
+}
+
+func TestNotFound(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Dir(dir), "secret.json", `{"name":"secret"}`)
+	reg := New(dir, nil)
+
+	if _, err := reg.FetchPackage(context.Background(), "missing"); !errors.Is(err, core.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	if _, err := reg.FetchPackage(context.Background(), "../secret"); err == nil {
+		t.Error("expected names outside the directory to be rejected")
+	}
+}
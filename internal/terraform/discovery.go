@@ -0,0 +1,138 @@
+package terraform
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+// Terraform finds a registry's API through remote service discovery: the
+// host serves /.well-known/terraform.json mapping service IDs such as
+// "modules.v1" to the URL of each API, relative to the document or
+// absolute. Terraform Cloud puts its private module registry at
+// /api/registry/v1/modules/, for instance.
+// https://developer.hashicorp.com/terraform/internals/remote-service-discovery
+
+// defaultModulesPath is where the public registry serves modules, and
+// where hosts without a discovery document are assumed to.
+const defaultModulesPath = "/v1/modules/"
+
+// services holds what discovery found for a host, once it has run. The
+// results are read without the lock, which is only held while discovering,
+// because the discovery request itself asks WithToken's AuthFunc for the
+// modules host.
+type services struct {
+	mu      sync.Mutex
+	modules atomic.Pointer[string] // modules.v1 URL, ending in "/"
+	tfe     atomic.Bool            // the host is Terraform Cloud or Enterprise
+}
+
+// discovered returns the modules.v1 URL, or "" before discovery has run.
+func (s *services) discovered() string {
+	if m := s.modules.Load(); m != nil {
+		return *m
+	}
+	return ""
+}
+
+// discovery is the document served at /.well-known/terraform.json.
+type discovery map[string]any
+
+// modulesURL returns the base URL of the host's module registry API,
+// running service discovery the first time. The public registry's is
+// known, so it isn't asked.
+func (r *Registry) modulesURL(ctx context.Context) (string, error) {
+	s := r.services
+	if m := s.discovered(); m != "" {
+		return m, nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if m := s.discovered(); m != "" {
+		return m, nil
+	}
+	modules := r.baseURL + defaultModulesPath
+	if r.baseURL == DefaultURL {
+		s.modules.Store(&modules)
+		return modules, nil
+	}
+
+	u, err := url.Parse(r.baseURL)
+	if err != nil {
+		return "", err
+	}
+	docURL := &url.URL{Scheme: u.Scheme, Host: u.Host, Path: "/.well-known/terraform.json"}
+
+	var doc discovery
+	if err := r.client.GetJSON(ctx, docURL.String(), &doc); err != nil {
+		httpErr, ok := err.(*core.HTTPError)
+		if !ok || !httpErr.IsNotFound() {
+			return "", err
+		}
+		// No discovery document: assume the public registry's layout
+		s.modules.Store(&modules)
+		return modules, nil
+	}
+
+	service, _ := doc["modules.v1"].(string)
+	if service == "" {
+		return "", fmt.Errorf("%s: %s does not provide a module registry: %w", ecosystem, u.Host, core.ErrNotSupported)
+	}
+	ref, err := docURL.Parse(service)
+	if err != nil {
+		return "", fmt.Errorf("%s: invalid modules.v1 URL %q: %w", ecosystem, service, err)
+	}
+	modules = strings.TrimSuffix(ref.String(), "/") + "/"
+	_, tfe := doc["tfe.v2"]
+	s.tfe.Store(tfe)
+	s.modules.Store(&modules)
+	return modules, nil
+}
+
+// WithToken returns a new Registry that sends token as a bearer token to
+// the registry's host and to the host its module API was discovered on,
+// as Terraform does with a credentials block or TF_TOKEN_* variable. Use a
+// Terraform Cloud or Enterprise user or team token for private modules.
+// Requests to other hosts use the client's own AuthFunc.
+func (r *Registry) WithToken(token string) *Registry {
+	client := r.client
+	if client == nil {
+		client = core.DefaultClient()
+	}
+	copy := *r
+	services := r.services
+	fallback := client.AuthFunc
+	copy.client = client.WithAuthFunc(func(rawURL string) (string, string) {
+		host := hostOf(rawURL)
+		if host != "" && (host == hostOf(r.baseURL) || host == hostOf(services.discovered())) {
+			return "Authorization", "Bearer " + token
+		}
+		if fallback != nil {
+			return fallback(rawURL)
+		}
+		return "", ""
+	})
+	return &copy
+}
+
+// EnvToken returns the token Terraform would use for hostname from the
+// environment: TF_TOKEN_ followed by the hostname with "." written as "_"
+// and "-" as "__", such as TF_TOKEN_app_terraform_io.
+func EnvToken(hostname string) string {
+	name := strings.NewReplacer(".", "_", "-", "__").Replace(strings.ToLower(hostname))
+	return os.Getenv("TF_TOKEN_" + name)
+}
+
+func hostOf(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return u.Host
+}
@@ -0,0 +1,84 @@
+package terraform
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+func TestPrivateRegistry(t *testing.T) {
+	discoveries := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/.well-known/terraform.json" {
+			discoveries++
+			_, _ = w.Write([]byte(`{"modules.v1": "/api/registry/v1/modules/", "tfe.v2": "/api/v2/", "tfe.v2.1": "/api/v2/"}`))
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer s3cret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/api/registry/v1/modules/acme/network/aws":
+			_, _ = w.Write([]byte(`{"namespace": "acme", "name": "network", "provider": "aws", "version": "1.2.0", "versions": ["1.1.0", "1.2.0"]}`))
+		case "/api/registry/v1/modules/acme/network/aws/versions":
+			_, _ = w.Write([]byte(`{"modules": [{"versions": [{"version": "1.1.0"}, {"version": "1.2.0"}]}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	ctx := context.Background()
+
+	var httpErr *core.HTTPError
+	if _, err := New(server.URL, core.DefaultClient()).FetchPackage(ctx, "acme/network/aws"); !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected 401 without a token, got %v", err)
+	}
+
+	reg := New(server.URL, core.DefaultClient()).WithToken("s3cret")
+	pkg, err := reg.FetchPackage(ctx, "acme/network/aws")
+	if err != nil {
+		t.Fatalf("FetchPackage failed: %v", err)
+	}
+	if pkg.Name != "acme/network/aws" || pkg.LatestVersion != "1.2.0" {
+		t.Errorf("unexpected package %+v", pkg)
+	}
+	versions, err := reg.FetchVersions(ctx, "acme/network/aws")
+	if err != nil || len(versions) != 2 {
+		t.Errorf("FetchVersions = %+v, %v", versions, err)
+	}
+	if discoveries != 2 {
+		t.Errorf("expected discovery once per registry, got %d requests", discoveries)
+	}
+
+	urls := reg.URLs()
+	if got := urls.Registry("acme/network/aws", "1.2.0"); got != server.URL+"/app/acme/registry/modules/private/acme/network/aws/1.2.0" {
+		t.Errorf("unexpected registry URL %q", got)
+	}
+	if got := urls.Download("acme/network/aws", "1.2.0"); got != server.URL+"/api/registry/v1/modules/acme/network/aws/1.2.0/download" {
+		t.Errorf("unexpected download URL %q", got)
+	}
+}
+
+func TestDiscoveryWithoutModules(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"providers.v1": "/v1/providers/"}`))
+	}))
+	defer server.Close()
+
+	_, err := New(server.URL, core.DefaultClient()).FetchPackage(context.Background(), "acme/network/aws")
+	if !errors.Is(err, core.ErrNotSupported) {
+		t.Errorf("expected ErrNotSupported, got %v", err)
+	}
+}
+
+func TestEnvToken(t *testing.T) {
+	t.Setenv("TF_TOKEN_tfe_corp__example_com", "abc")
+	if got := EnvToken("tfe.corp-example.com"); got != "abc" {
+		t.Errorf("EnvToken = %q", got)
+	}
+}
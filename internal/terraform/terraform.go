@@ -23,9 +23,10 @@ func init() {
 }
 
 type Registry struct {
-	baseURL string
-	client  *core.Client
-	urls    *URLs
+	baseURL  string
+	client   *core.Client
+	urls     *URLs
+	services *services // what service discovery found, shared by copies
 }
 
 func New(baseURL string, client *core.Client) *Registry {
@@ -33,10 +34,11 @@ func New(baseURL string, client *core.Client) *Registry {
 		baseURL = DefaultURL
 	}
 	r := &Registry{
-		baseURL: strings.TrimSuffix(baseURL, "/"),
-		client:  client,
+		baseURL:  strings.TrimSuffix(baseURL, "/"),
+		client:   client,
+		services: &services{},
 	}
-	r.urls = &URLs{baseURL: r.baseURL}
+	r.urls = &URLs{baseURL: r.baseURL, services: r.services}
 	return r
 }
 
@@ -64,10 +66,11 @@ type moduleResponse struct {
 	Provider    string `json:"provider"`
 	Description string `json:"description"`
 	Source      string `json:"source"`
-	Version     string `json:"version"`
-	PublishedAt string `json:"published_at"`
-	Downloads   int    `json:"downloads"`
-	Verified    bool   `json:"verified"`
+	Version     string   `json:"version"`
+	Versions    []string `json:"versions"`
+	PublishedAt string   `json:"published_at"`
+	Downloads   int      `json:"downloads"`
+	Verified    bool     `json:"verified"`
 }
 
 type moduleVersionsResponse struct {
@@ -113,7 +116,11 @@ func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package
 		return nil, fmt.Errorf("terraform module name must be in format 'namespace/name/provider'")
 	}
 
-	url := fmt.Sprintf("%s/v1/modules/%s/%s/%s", r.baseURL, namespace, moduleName, provider)
+	modules, err := r.modulesURL(ctx)
+	if err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("%s%s/%s/%s", modules, namespace, moduleName, provider)
 
 	var resp moduleResponse
 	if err := r.client.GetJSON(ctx, url, &resp); err != nil {
@@ -126,10 +133,15 @@ func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package
 	// Extract repository from source
 	repository := urlparser.Parse(resp.Source)
 
+	stable := resp.Version
+	if core.IsPrerelease(ecosystem, stable) {
+		stable = core.LatestStableOf(ecosystem, resp.Versions)
+	}
+
 	return &core.Package{
 		Name:        fmt.Sprintf("%s/%s/%s", resp.Namespace, resp.Name, resp.Provider),
 		Description: resp.Description,
-		Homepage:    fmt.Sprintf("https://registry.terraform.io/modules/%s/%s/%s", namespace, moduleName, provider),
+		Homepage:    r.urls.Registry(name, ""),
 		Repository:  repository,
 		Namespace:   resp.Namespace,
 		Metadata: map[string]any{
@@ -137,6 +149,9 @@ func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package
 			"downloads": resp.Downloads,
 			"verified":  resp.Verified,
 		},
+		Popularity:          &core.Popularity{DownloadsTotal: int64(resp.Downloads)},
+		LatestVersion:       resp.Version,
+		LatestStableVersion: stable,
 	}, nil
 }
 
@@ -146,7 +161,11 @@ func (r *Registry) FetchVersions(ctx context.Context, name string) ([]core.Versi
 		return nil, fmt.Errorf("terraform module name must be in format 'namespace/name/provider'")
 	}
 
-	url := fmt.Sprintf("%s/v1/modules/%s/%s/%s/versions", r.baseURL, namespace, moduleName, provider)
+	modules, err := r.modulesURL(ctx)
+	if err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("%s%s/%s/%s/versions", modules, namespace, moduleName, provider)
 
 	var resp moduleVersionsResponse
 	if err := r.client.GetJSON(ctx, url, &resp); err != nil {
@@ -181,7 +200,11 @@ func (r *Registry) FetchDependencies(ctx context.Context, name, version string)
 		return nil, fmt.Errorf("terraform module name must be in format 'namespace/name/provider'")
 	}
 
-	url := fmt.Sprintf("%s/v1/modules/%s/%s/%s/%s", r.baseURL, namespace, moduleName, provider, version)
+	modules, err := r.modulesURL(ctx)
+	if err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("%s%s/%s/%s/%s", modules, namespace, moduleName, provider, version)
 
 	var resp versionEntry
 	if err := r.client.GetJSON(ctx, url, &resp); err != nil {
@@ -236,14 +259,29 @@ func (r *Registry) FetchMaintainers(ctx context.Context, name string) ([]core.Ma
 }
 
 type URLs struct {
-	baseURL string
+	baseURL  string
+	services *services
 }
 
+// Registry returns the module's page on the public registry, or in the
+// private registry of a Terraform Cloud or Enterprise organization, named
+// by the namespace, once discovery has found the host to be one. Other
+// private registries have no standard web pages.
 func (u *URLs) Registry(name, version string) string {
 	namespace, moduleName, provider, ok := parseModuleName(name)
 	if !ok {
 		return ""
 	}
+	if u.baseURL != DefaultURL {
+		if u.services == nil || !u.services.tfe.Load() {
+			return ""
+		}
+		page := fmt.Sprintf("%s/app/%s/registry/modules/private/%s/%s/%s", u.baseURL, namespace, namespace, moduleName, provider)
+		if version != "" {
+			page += "/" + version
+		}
+		return page
+	}
 	if version != "" {
 		return fmt.Sprintf("https://registry.terraform.io/modules/%s/%s/%s/%s", namespace, moduleName, provider, version)
 	}
@@ -255,7 +293,11 @@ func (u *URLs) Download(name, version string) string {
 	if !ok || version == "" {
 		return ""
 	}
-	return fmt.Sprintf("%s/v1/modules/%s/%s/%s/%s/download", u.baseURL, namespace, moduleName, provider, version)
+	modules := u.baseURL + defaultModulesPath
+	if u.services != nil && u.services.discovered() != "" {
+		modules = u.services.discovered()
+	}
+	return fmt.Sprintf("%s%s/%s/%s/%s/download", modules, namespace, moduleName, provider, version)
 }
 
 func (u *URLs) Documentation(name, version string) string {
@@ -12,6 +12,12 @@ import (
 
 func TestFetchPackage(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Without a discovery document the public registry's layout is
+		// assumed
+		if r.URL.Path == "/.well-known/terraform.json" {
+			w.WriteHeader(404)
+			return
+		}
 		if r.URL.Path != "/v1/modules/hashicorp/consul/aws" {
 			t.Errorf("unexpected path: %s", r.URL.Path)
 			w.WriteHeader(404)
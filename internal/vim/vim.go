@@ -0,0 +1,174 @@
+// Package vim provides a registry client for Vim plugins listed on VimAwesome.
+package vim
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/git-pkgs/registries/internal/core"
+	"github.com/git-pkgs/registries/internal/urlparser"
+)
+
+const (
+	DefaultURL = "https://vimawesome.com"
+	ecosystem  = "vim"
+)
+
+func init() {
+	core.Register(ecosystem, DefaultURL, func(baseURL string, client *core.Client) core.Registry {
+		return New(baseURL, client)
+	})
+}
+
+type Registry struct {
+	baseURL string
+	client  *core.Client
+	urls    *URLs
+}
+
+func New(baseURL string, client *core.Client) *Registry {
+	if baseURL == "" {
+		baseURL = DefaultURL
+	}
+	r := &Registry{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client:  client,
+	}
+	r.urls = &URLs{baseURL: r.baseURL}
+	return r
+}
+
+func (r *Registry) Ecosystem() string {
+	return ecosystem
+}
+
+func (r *Registry) URLs() core.URLBuilder {
+	return r.urls
+}
+
+type pluginResponse struct {
+	Slug               string   `json:"slug"`
+	Name               string   `json:"name"`
+	Author             string   `json:"author"`
+	Category           string   `json:"category"`
+	Tags               []string `json:"tags"`
+	ShortDesc          string   `json:"short_desc"`
+	GithubURL          string   `json:"github_url"`
+	GithubOwner        string   `json:"github_owner"`
+	GithubHomepage     string   `json:"github_homepage"`
+	GithubStars        int      `json:"github_stars"`
+	VimorgURL          string   `json:"vimorg_url"`
+	VimorgID           string   `json:"vimorg_id"`
+	PluginManagerUsers int      `json:"plugin_manager_users"`
+	CreatedAt          int64    `json:"created_at"`
+	UpdatedAt          int64    `json:"updated_at"`
+}
+
+func (r *Registry) fetchPlugin(ctx context.Context, name string) (*pluginResponse, error) {
+	url := fmt.Sprintf("%s/api/plugins/%s", r.baseURL, name)
+
+	var resp pluginResponse
+	if err := r.client.GetJSON(ctx, url, &resp); err != nil {
+		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
+			return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name}
+		}
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package, error) {
+	resp, err := r.fetchPlugin(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	homepage := resp.GithubHomepage
+	if homepage == "" {
+		homepage = resp.VimorgURL
+	}
+
+	var categories []string
+	if resp.Category != "" && resp.Category != "uncategorized" {
+		categories = []string{resp.Category}
+	}
+
+	metadata := map[string]any{
+		"display_name":         resp.Name,
+		"stars":                resp.GithubStars,
+		"plugin_manager_users": resp.PluginManagerUsers,
+	}
+	if resp.VimorgID != "" {
+		metadata["vimorg_id"] = resp.VimorgID
+	}
+	if resp.UpdatedAt > 0 {
+		metadata["updated_at"] = time.Unix(resp.UpdatedAt, 0).UTC()
+	}
+
+	return &core.Package{
+		Name:        resp.Slug,
+		Description: resp.ShortDesc,
+		Homepage:    homepage,
+		Repository:  urlparser.Parse(resp.GithubURL),
+		Keywords:    resp.Tags,
+		Categories:  categories,
+		Namespace:   resp.GithubOwner,
+		Metadata:    metadata,
+		Popularity:  &core.Popularity{Stars: int64(resp.GithubStars)},
+	}, nil
+}
+
+// FetchVersions returns nothing: Vim plugins are installed from a
+// repository's default branch and VimAwesome does not track releases.
+func (r *Registry) FetchVersions(ctx context.Context, name string) ([]core.Version, error) {
+	if _, err := r.fetchPlugin(ctx, name); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+func (r *Registry) FetchMaintainers(ctx context.Context, name string) ([]core.Maintainer, error) {
+	resp, err := r.fetchPlugin(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.Author == "" && resp.GithubOwner == "" {
+		return nil, nil
+	}
+
+	m := core.Maintainer{
+		Login: resp.GithubOwner,
+		Name:  resp.Author,
+		Role:  "author",
+	}
+	if resp.GithubOwner != "" {
+		m.URL = "https://github.com/" + resp.GithubOwner
+	}
+	return []core.Maintainer{m}, nil
+}
+
+type URLs struct {
+	baseURL string
+}
+
+func (u *URLs) Registry(name, version string) string {
+	return fmt.Sprintf("%s/plugin/%s", u.baseURL, name)
+}
+
+func (u *URLs) Download(name, version string) string {
+	return ""
+}
+
+func (u *URLs) Documentation(name, version string) string {
+	return fmt.Sprintf("%s/plugin/%s", u.baseURL, name)
+}
+
+func (u *URLs) PURL(name, version string) string {
+	if version != "" {
+		return fmt.Sprintf("pkg:vim/%s@%s", name, version)
+	}
+	return fmt.Sprintf("pkg:vim/%s", name)
+}
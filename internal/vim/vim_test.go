@@ -0,0 +1,132 @@
+package vim
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+const fugitiveJSON = `{
+	"slug": "fugitive-vim",
+	"name": "fugitive.vim",
+	"author": "Tim Pope",
+	"category": "version-control",
+	"tags": ["git"],
+	"short_desc": "A Git wrapper so awesome, it should be illegal",
+	"github_url": "https://github.com/tpope/vim-fugitive",
+	"github_owner": "tpope",
+	"github_homepage": "",
+	"github_stars": 18000,
+	"vimorg_url": "http://www.vim.org/scripts/script.php?script_id=2975",
+	"vimorg_id": "2975",
+	"plugin_manager_users": 25000,
+	"created_at": 1388534400,
+	"updated_at": 1700000000
+}`
+
+func TestFetchPackage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/plugins/fugitive-vim" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+			w.WriteHeader(404)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(fugitiveJSON))
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	pkg, err := reg.FetchPackage(context.Background(), "fugitive-vim")
+	if err != nil {
+		t.Fatalf("FetchPackage failed: %v", err)
+	}
+
+	if pkg.Name != "fugitive-vim" {
+		t.Errorf("expected name 'fugitive-vim', got %q", pkg.Name)
+	}
+	if pkg.Repository != "https://github.com/tpope/vim-fugitive" {
+		t.Errorf("unexpected repository: %q", pkg.Repository)
+	}
+	if pkg.Homepage != "http://www.vim.org/scripts/script.php?script_id=2975" {
+		t.Errorf("unexpected homepage: %q", pkg.Homepage)
+	}
+	if len(pkg.Categories) != 1 || pkg.Categories[0] != "version-control" {
+		t.Errorf("unexpected categories: %v", pkg.Categories)
+	}
+	if pkg.Namespace != "tpope" {
+		t.Errorf("unexpected namespace: %q", pkg.Namespace)
+	}
+	if pkg.Metadata["stars"] != 18000 {
+		t.Errorf("unexpected stars: %v", pkg.Metadata["stars"])
+	}
+}
+
+func TestFetchPackageNotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(404)
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	if _, err := reg.FetchPackage(context.Background(), "nonexistent"); !errors.Is(err, core.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	if _, err := reg.FetchVersions(context.Background(), "nonexistent"); !errors.Is(err, core.ErrNotFound) {
+		t.Errorf("expected ErrNotFound from FetchVersions, got %v", err)
+	}
+}
+
+func TestFetchMaintainers(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(fugitiveJSON))
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	maintainers, err := reg.FetchMaintainers(context.Background(), "fugitive-vim")
+	if err != nil {
+		t.Fatalf("FetchMaintainers failed: %v", err)
+	}
+
+	if len(maintainers) != 1 {
+		t.Fatalf("expected 1 maintainer, got %d", len(maintainers))
+	}
+	if maintainers[0].Login != "tpope" || maintainers[0].Name != "Tim Pope" {
+		t.Errorf("unexpected maintainer: %+v", maintainers[0])
+	}
+}
+
+func TestURLBuilder(t *testing.T) {
+	reg := New("", nil)
+	urls := reg.URLs()
+
+	tests := []struct {
+		name     string
+		fn       func() string
+		expected string
+	}{
+		{"registry", func() string { return urls.Registry("fugitive-vim", "") }, "https://vimawesome.com/plugin/fugitive-vim"},
+		{"download", func() string { return urls.Download("fugitive-vim", "") }, ""},
+		{"purl", func() string { return urls.PURL("fugitive-vim", "") }, "pkg:vim/fugitive-vim"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.fn(); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestEcosystem(t *testing.T) {
+	reg := New("", nil)
+	if reg.Ecosystem() != "vim" {
+		t.Errorf("expected ecosystem 'vim', got %q", reg.Ecosystem())
+	}
+}
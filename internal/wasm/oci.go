@@ -0,0 +1,254 @@
+package wasm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+// wasm-pkg registries are named by a domain serving
+// /.well-known/wasm-pkg/registry.json, which says where the packages are
+// stored. Most point at an OCI registry such as ghcr.io, where each
+// package is a repository under a prefix and each version a tag, following
+// the CNCF Wasm OCI artifact layout. The others run the warg protocol,
+// whose signed package logs aren't supported.
+// https://github.com/bytecodealliance/wasm-pkg-tools
+// https://tag-runtime.cncf.io/wgs/wasm/deliverables/wasm-oci-artifact/
+
+const (
+	ociManifestType = "application/vnd.oci.image.manifest.v1+json"
+	wasmConfigType  = "application/vnd.wasm.config.v0+json"
+	wasmLayerType   = "application/wasm"
+)
+
+// registryMetadata is the well-known document. Older registries put the
+// OCI settings at the top level.
+type registryMetadata struct {
+	PreferredProtocol string `json:"preferredProtocol"`
+	OCI               *struct {
+		Registry        string `json:"registry"`
+		NamespacePrefix string `json:"namespacePrefix"`
+	} `json:"oci"`
+	Warg *struct {
+		URL string `json:"url"`
+	} `json:"warg"`
+	OCIRegistry        string `json:"ociRegistry"`
+	OCINamespacePrefix string `json:"ociNamespacePrefix"`
+	WargURL            string `json:"wargUrl"`
+}
+
+// backend is where a registry's packages are stored.
+type backend struct {
+	ociURL string // scheme and host of the OCI registry
+	prefix string // prepended to "namespace/name" to form the repository
+}
+
+type token struct {
+	value   string // empty if the registry needs none
+	expires time.Time
+}
+
+// ociManifest is the part of an OCI image manifest read here.
+type ociManifest struct {
+	Config      ociDescriptor     `json:"config"`
+	Layers      []ociDescriptor   `json:"layers"`
+	Annotations map[string]string `json:"annotations"`
+}
+
+type ociDescriptor struct {
+	MediaType string `json:"mediaType"`
+	Digest    string `json:"digest"`
+	Size      int64  `json:"size"`
+}
+
+// ociRepository is a package's repository, with a client that
+// authenticates to it.
+type ociRepository struct {
+	base   string // https://ghcr.io/v2/webassembly/wasi/http
+	client *core.Client
+}
+
+func (o *ociRepository) url(path string) string {
+	return o.base + "/" + path
+}
+
+// reference returns the repository as image references write it, such as
+// "ghcr.io/webassembly/wasi/http".
+func (o *ociRepository) reference() string {
+	ref := strings.Replace(o.base, "/v2/", "/", 1)
+	return ref[strings.Index(ref, "://")+3:]
+}
+
+func (o *ociRepository) getJSON(ctx context.Context, url, accept string, v any) error {
+	body, err := o.client.GetBodyAccept(ctx, url, accept)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, v)
+}
+
+// registryFor returns the wasm-pkg registry serving namespace.
+func (r *Registry) registryFor(namespace string) string {
+	if r.baseURL == DefaultURL {
+		if reg, ok := namespaceRegistries[namespace]; ok {
+			return reg
+		}
+	}
+	return r.baseURL
+}
+
+// backend looks up, once per registry, where its packages are stored. A
+// registry without a well-known document is taken to be an OCI registry
+// itself.
+func (r *Registry) backend(ctx context.Context, registry string) (*backend, error) {
+	s := r.oci
+	s.mu.Lock()
+	b := s.backends[registry]
+	s.mu.Unlock()
+	if b != nil {
+		return b, nil
+	}
+
+	var meta registryMetadata
+	err := r.client.GetJSON(ctx, registry+"/.well-known/wasm-pkg/registry.json", &meta)
+	if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
+		b = &backend{ociURL: registry}
+	} else if err != nil {
+		return nil, err
+	} else {
+		ociRegistry, prefix := meta.OCIRegistry, meta.OCINamespacePrefix
+		if meta.OCI != nil {
+			ociRegistry, prefix = meta.OCI.Registry, meta.OCI.NamespacePrefix
+		}
+		if ociRegistry == "" || meta.PreferredProtocol == "warg" {
+			return nil, fmt.Errorf("%s: %s is a warg registry: %w", ecosystem, registry, core.ErrNotSupported)
+		}
+		if !strings.Contains(ociRegistry, "://") {
+			ociRegistry = "https://" + ociRegistry
+		}
+		b = &backend{ociURL: strings.TrimSuffix(ociRegistry, "/"), prefix: prefix}
+	}
+
+	s.mu.Lock()
+	s.backends[registry] = b
+	s.mu.Unlock()
+	return b, nil
+}
+
+// repository finds the OCI repository holding a package and authorizes
+// the client to pull from it.
+func (r *Registry) repository(ctx context.Context, name string) (*ociRepository, error) {
+	namespace, pkg, ok := splitName(name)
+	if !ok {
+		return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name}
+	}
+	b, err := r.backend(ctx, r.registryFor(namespace))
+	if err != nil {
+		return nil, err
+	}
+	repo := &ociRepository{
+		base:   b.ociURL + "/v2/" + b.prefix + namespace + "/" + pkg,
+		client: r.client,
+	}
+
+	tok, err := r.token(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+	if tok != "" {
+		fallback := r.client.AuthFunc
+		repo.client = r.client.WithAuthFunc(func(rawURL string) (string, string) {
+			if strings.HasPrefix(rawURL, repo.base+"/") {
+				return "Authorization", "Bearer " + tok
+			}
+			if fallback != nil {
+				return fallback(rawURL)
+			}
+			return "", ""
+		})
+	}
+	return repo, nil
+}
+
+// token returns a pull token for repo, or "" if it can be read without
+// one. Registries such as ghcr.io issue anonymous tokens for public
+// repositories from the realm named in their 401 challenge, as docker
+// pull does.
+func (r *Registry) token(ctx context.Context, repo *ociRepository) (string, error) {
+	s := r.oci
+	now := time.Now()
+	s.mu.Lock()
+	tok, ok := s.tokens[repo.base]
+	s.mu.Unlock()
+	if ok && now.Before(tok.expires) {
+		return tok.value, nil
+	}
+
+	status, header, err := r.client.HeadHeader(ctx, repo.url("tags/list"))
+	if err != nil {
+		return "", err
+	}
+	tok = token{expires: now.Add(time.Hour)}
+	if status == http.StatusUnauthorized {
+		realm, params := parseChallenge(header.Get("WWW-Authenticate"))
+		if realm == "" {
+			return "", fmt.Errorf("%s: %s needs credentials", ecosystem, repo.reference())
+		}
+		q := url.Values{}
+		if params["service"] != "" {
+			q.Set("service", params["service"])
+		}
+		scope := params["scope"]
+		if scope == "" {
+			scope = "repository:" + strings.SplitN(repo.reference(), "/", 2)[1] + ":pull"
+		}
+		q.Set("scope", scope)
+
+		var resp struct {
+			Token       string `json:"token"`
+			AccessToken string `json:"access_token"`
+			ExpiresIn   int    `json:"expires_in"`
+		}
+		if err := r.client.GetJSON(ctx, realm+"?"+q.Encode(), &resp); err != nil {
+			return "", err
+		}
+		tok.value = resp.Token
+		if tok.value == "" {
+			tok.value = resp.AccessToken
+		}
+		// Tokens last 60 seconds unless the registry says otherwise
+		lifetime := 60 * time.Second
+		if resp.ExpiresIn > 0 {
+			lifetime = time.Duration(resp.ExpiresIn) * time.Second
+		}
+		tok.expires = now.Add(lifetime * 9 / 10)
+	}
+
+	s.mu.Lock()
+	s.tokens[repo.base] = tok
+	s.mu.Unlock()
+	return tok.value, nil
+}
+
+var challengeParam = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+// parseChallenge reads a Bearer WWW-Authenticate challenge such as
+// `Bearer realm="https://ghcr.io/token",service="ghcr.io"`.
+func parseChallenge(challenge string) (realm string, params map[string]string) {
+	scheme, rest, _ := strings.Cut(challenge, " ")
+	if !strings.EqualFold(scheme, "Bearer") {
+		return "", nil
+	}
+	params = make(map[string]string)
+	for _, m := range challengeParam.FindAllStringSubmatch(rest, -1) {
+		params[strings.ToLower(m[1])] = m[2]
+	}
+	return params["realm"], params
+}
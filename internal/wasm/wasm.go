@@ -0,0 +1,337 @@
+// Package wasm provides a registry client for WebAssembly component and
+// WIT packages, such as wasi:http, published with the wasm-pkg tools
+// (wkg).
+package wasm
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/git-pkgs/purl"
+	"github.com/git-pkgs/registries/internal/core"
+	"github.com/git-pkgs/registries/internal/urlparser"
+)
+
+const (
+	DefaultURL = "https://bytecodealliance.org"
+	ecosystem  = "wasm"
+)
+
+// namespaceRegistries sends namespaces to registries other than the
+// default, as wkg's default configuration does. Only used when the
+// registry is DefaultURL.
+var namespaceRegistries = map[string]string{
+	"wasi": "https://wasi.dev",
+}
+
+// tagPattern matches tags that are versions, skipping "latest" and the
+// "sha256-..." tags signing tools attach.
+var tagPattern = regexp.MustCompile(`^v?[0-9]+\.[0-9]+\.[0-9]+`)
+
+func init() {
+	core.Register(ecosystem, DefaultURL, func(baseURL string, client *core.Client) core.Registry {
+		return New(baseURL, client)
+	})
+}
+
+type Registry struct {
+	baseURL string
+	client  *core.Client
+	urls    *URLs
+	oci     *ociState
+}
+
+func New(baseURL string, client *core.Client) *Registry {
+	if baseURL == "" {
+		baseURL = DefaultURL
+	}
+	r := &Registry{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client:  client,
+		oci:     &ociState{backends: make(map[string]*backend), tokens: make(map[string]token)},
+	}
+	r.urls = &URLs{baseURL: r.baseURL}
+	return r
+}
+
+func (r *Registry) Ecosystem() string {
+	return ecosystem
+}
+
+func (r *Registry) URLs() core.URLBuilder {
+	return r.urls
+}
+
+// splitName splits "namespace:name", or "namespace/name" as PURLs write
+// it, into its parts.
+func splitName(name string) (namespace, pkg string, ok bool) {
+	if namespace, pkg, ok = strings.Cut(name, ":"); ok {
+		return namespace, pkg, namespace != "" && pkg != ""
+	}
+	namespace, pkg, ok = strings.Cut(name, "/")
+	return namespace, pkg, ok && namespace != "" && pkg != ""
+}
+
+// Component describes what a version's OCI image holds: a component, or a
+// WIT package defining interfaces and worlds.
+type Component struct {
+	// Imports and Exports are the interfaces the component imports and
+	// exports, such as "wasi:io/streams@0.2.0". WIT packages list none.
+	Imports []string `json:"imports,omitempty"`
+	Exports []string `json:"exports,omitempty"`
+	// Target is the world the component was built for, where recorded.
+	Target string `json:"target,omitempty"`
+	// OS is the platform the image is for, such as "wasip2".
+	OS string `json:"os,omitempty"`
+	// Digest, Integrity and Size describe the .wasm layer. Integrity is
+	// the digest in SRI form, e.g. sha256-<base64>.
+	Digest    string    `json:"digest"`
+	Integrity string    `json:"integrity,omitempty"`
+	Size      int64     `json:"size"`
+	CreatedAt time.Time `json:"created_at,omitzero"`
+}
+
+// tags lists the versions of a package.
+func (r *Registry) tags(ctx context.Context, name string) (*ociRepository, []string, error) {
+	repo, err := r.repository(ctx, name)
+	if err != nil {
+		return nil, nil, err
+	}
+	var resp struct {
+		Tags []string `json:"tags"`
+	}
+	if err := repo.getJSON(ctx, repo.url("tags/list?n=1000"), "", &resp); err != nil {
+		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
+			return nil, nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name}
+		}
+		return nil, nil, err
+	}
+	var versions []string
+	for _, tag := range resp.Tags {
+		if tagPattern.MatchString(tag) {
+			versions = append(versions, tag)
+		}
+	}
+	if len(versions) == 0 {
+		return nil, nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name}
+	}
+	return repo, versions, nil
+}
+
+func (r *Registry) manifest(ctx context.Context, repo *ociRepository, name, version string) (*ociManifest, error) {
+	var m ociManifest
+	if err := repo.getJSON(ctx, repo.url("manifests/"+version), ociManifestType, &m); err != nil {
+		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
+			return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name, Version: version}
+		}
+		return nil, err
+	}
+	return &m, nil
+}
+
+func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package, error) {
+	repo, versions, err := r.tags(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	namespace, pkg, _ := splitName(name)
+	latest := core.LatestOf(ecosystem, versions)
+
+	p := &core.Package{
+		Name:                namespace + ":" + pkg,
+		Namespace:           namespace,
+		LatestVersion:       latest,
+		LatestStableVersion: core.LatestStableOf(ecosystem, versions),
+		Metadata: map[string]any{
+			"oci_reference": repo.reference(),
+		},
+	}
+
+	m, err := r.manifest(ctx, repo, name, latest)
+	if err != nil {
+		return nil, err
+	}
+	a := m.Annotations
+	p.Description = a["org.opencontainers.image.description"]
+	p.Homepage = a["org.opencontainers.image.url"]
+	p.Documentation = a["org.opencontainers.image.documentation"]
+	p.Licenses = a["org.opencontainers.image.licenses"]
+	p.Repository = urlparser.Parse(a["org.opencontainers.image.source"])
+	if created, err := time.Parse(time.RFC3339, a["org.opencontainers.image.created"]); err == nil {
+		p.LatestReleasedAt = created
+	}
+	if authors := a["org.opencontainers.image.authors"]; authors != "" {
+		p.Metadata["authors"] = authors
+	}
+	if vendor := a["org.opencontainers.image.vendor"]; vendor != "" {
+		p.Metadata["vendor"] = vendor
+	}
+	return p, nil
+}
+
+// FetchVersions lists the versions tagged in the package's repository. The
+// registry only keeps dates and digests in each version's manifest, so
+// versions carry just their numbers; see FetchComponent.
+func (r *Registry) FetchVersions(ctx context.Context, name string) ([]core.Version, error) {
+	_, tags, err := r.tags(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	versions := make([]core.Version, len(tags))
+	for i, tag := range tags {
+		versions[i] = core.Version{Number: tag}
+	}
+	return versions, nil
+}
+
+// FetchComponent reads a version's manifest and configuration for the
+// interfaces its component imports and exports.
+func (r *Registry) FetchComponent(ctx context.Context, name, version string) (*Component, error) {
+	repo, err := r.repository(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	m, err := r.manifest(ctx, repo, name, version)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Component{}
+	for _, layer := range m.Layers {
+		if layer.MediaType == wasmLayerType {
+			c.Digest, c.Integrity, c.Size = layer.Digest, integrity(layer.Digest), layer.Size
+			break
+		}
+	}
+	if created, err := time.Parse(time.RFC3339, m.Annotations["org.opencontainers.image.created"]); err == nil {
+		c.CreatedAt = created
+	}
+	if m.Config.MediaType != wasmConfigType {
+		return c, nil
+	}
+
+	var config struct {
+		Created   string `json:"created"`
+		OS        string `json:"os"`
+		Component *struct {
+			Imports []string `json:"imports"`
+			Exports []string `json:"exports"`
+			Target  string   `json:"target"`
+		} `json:"component"`
+	}
+	if err := repo.getJSON(ctx, repo.url("blobs/"+m.Config.Digest), "", &config); err != nil {
+		return nil, err
+	}
+	c.OS = config.OS
+	if created, err := time.Parse(time.RFC3339, config.Created); err == nil && c.CreatedAt.IsZero() {
+		c.CreatedAt = created
+	}
+	if config.Component != nil {
+		c.Imports = config.Component.Imports
+		c.Exports = config.Component.Exports
+		c.Target = config.Component.Target
+	}
+	return c, nil
+}
+
+// FetchDependencies returns the packages whose interfaces the version's
+// component imports, one per package with the interfaces in the
+// "interfaces" metadata and the imported version as the requirement.
+func (r *Registry) FetchDependencies(ctx context.Context, name, version string) ([]core.Dependency, error) {
+	c, err := r.FetchComponent(ctx, name, version)
+	if err != nil {
+		return nil, err
+	}
+
+	byPackage := make(map[string]*core.Dependency)
+	var order []string
+	for _, imp := range c.Imports {
+		pkg, iface, version := splitInterface(imp)
+		if pkg == "" {
+			continue
+		}
+		key := pkg + "@" + version
+		d := byPackage[key]
+		if d == nil {
+			d = &core.Dependency{Name: pkg, Requirements: version, Scope: core.Runtime, Metadata: map[string]any{"interfaces": []string{}}}
+			byPackage[key] = d
+			order = append(order, key)
+		}
+		if iface != "" {
+			d.Metadata["interfaces"] = append(d.Metadata["interfaces"].([]string), iface)
+		}
+	}
+
+	sort.Strings(order)
+	deps := make([]core.Dependency, len(order))
+	for i, key := range order {
+		deps[i] = *byPackage[key]
+	}
+	return deps, nil
+}
+
+// splitInterface splits an interface name such as
+// "wasi:io/streams@0.2.0" into its package, interface and version.
+// Unqualified names, which some toolchains record for local imports, have
+// no package.
+func splitInterface(name string) (pkg, iface, version string) {
+	name, version, _ = strings.Cut(name, "@")
+	pkg, iface, _ = strings.Cut(name, "/")
+	if !strings.Contains(pkg, ":") {
+		return "", "", ""
+	}
+	return pkg, iface, version
+}
+
+// integrity converts an OCI digest such as "sha256:<hex>" to SRI form.
+func integrity(digest string) string {
+	algo, hexSum, ok := strings.Cut(digest, ":")
+	if !ok {
+		return ""
+	}
+	sum, err := hex.DecodeString(hexSum)
+	if err != nil {
+		return ""
+	}
+	return algo + "-" + base64.StdEncoding.EncodeToString(sum)
+}
+
+type URLs struct {
+	baseURL string
+}
+
+// Registry returns "": packages have no page of their own on an OCI
+// registry that can be named without discovering it first.
+func (u *URLs) Registry(name, version string) string {
+	return ""
+}
+
+func (u *URLs) Download(name, version string) string {
+	return ""
+}
+
+func (u *URLs) Documentation(name, version string) string {
+	return ""
+}
+
+func (u *URLs) PURL(name, version string) string {
+	namespace, pkg, ok := splitName(name)
+	if !ok {
+		return purl.New(ecosystem, "", name, version, nil).String()
+	}
+	return purl.New(ecosystem, namespace, pkg, version, nil).String()
+}
+
+// ociState is what a Registry and its copies learn about the registries
+// packages live on.
+type ociState struct {
+	mu       sync.Mutex
+	backends map[string]*backend // by registry URL
+	tokens   map[string]token    // by repository URL
+}
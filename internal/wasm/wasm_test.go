@@ -0,0 +1,171 @@
+package wasm
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+const testConfig = `{"created": "2025-01-10T09:00:00Z", "architecture": "wasm", "os": "wasip2",
+	"component": {"target": "wasi:http/proxy@0.2.0",
+		"imports": ["wasi:io/error@0.2.0", "wasi:io/streams@0.2.0", "wasi:clocks/monotonic-clock@0.2.0", "local-logger"],
+		"exports": ["wasi:http/incoming-handler@0.2.0"]}}`
+
+// newServer serves a wasm-pkg registry whose packages are in an OCI
+// registry on the same host, under "acme-oci/", which hands out anonymous
+// pull tokens as ghcr.io does.
+func newServer(t *testing.T) (*httptest.Server, *int32) {
+	var tokens int32
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/.well-known/wasm-pkg/registry.json":
+			_, _ = w.Write([]byte(`{"preferredProtocol": "oci", "oci": {"registry": "` + server.URL + `", "namespacePrefix": "acme-oci/"}}`))
+			return
+		case r.URL.Path == "/token":
+			atomic.AddInt32(&tokens, 1)
+			if !strings.HasPrefix(r.URL.Query().Get("scope"), "repository:acme-oci/acme/") || r.URL.Query().Get("service") != "test-registry" {
+				t.Errorf("unexpected token request %s", r.URL.RawQuery)
+			}
+			_, _ = w.Write([]byte(`{"token": "anon", "expires_in": 300}`))
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer anon" {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="`+server.URL+`/token",service="test-registry"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/v2/acme-oci/acme/greeter/tags/list":
+			_, _ = w.Write([]byte(`{"name": "acme-oci/acme/greeter", "tags": ["0.1.0", "0.2.0", "0.3.0-rc.1", "latest", "sha256-0a1b.sig"]}`))
+		case "/v2/acme-oci/acme/greeter/manifests/0.2.0", "/v2/acme-oci/acme/greeter/manifests/0.3.0-rc.1":
+			if r.Header.Get("Accept") != ociManifestType {
+				t.Errorf("manifest requested with Accept %q", r.Header.Get("Accept"))
+			}
+			_, _ = w.Write([]byte(`{"schemaVersion": 2, "mediaType": "application/vnd.oci.image.manifest.v1+json",
+				"config": {"mediaType": "application/vnd.wasm.config.v0+json", "digest": "sha256:c0ffee", "size": 300},
+				"layers": [{"mediaType": "application/wasm", "digest": "sha256:0102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f20", "size": 4096}],
+				"annotations": {"org.opencontainers.image.description": "Says hello", "org.opencontainers.image.licenses": "MIT",
+					"org.opencontainers.image.source": "https://github.com/acme/greeter", "org.opencontainers.image.authors": "Acme"}}`))
+		case "/v2/acme-oci/acme/greeter/blobs/sha256:c0ffee":
+			_, _ = w.Write([]byte(testConfig))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, &tokens
+}
+
+func TestRegistry(t *testing.T) {
+	server, tokens := newServer(t)
+	ctx := context.Background()
+	reg := New(server.URL, core.DefaultClient())
+
+	pkg, err := reg.FetchPackage(ctx, "acme:greeter")
+	if err != nil {
+		t.Fatalf("FetchPackage failed: %v", err)
+	}
+	if pkg.Name != "acme:greeter" || pkg.Namespace != "acme" || pkg.LatestVersion != "0.3.0-rc.1" || pkg.LatestStableVersion != "0.2.0" {
+		t.Errorf("unexpected package %+v", pkg)
+	}
+	if pkg.Description != "Says hello" || pkg.Licenses != "MIT" || pkg.Repository != "https://github.com/acme/greeter" {
+		t.Errorf("annotations not read: %+v", pkg)
+	}
+	if ref := pkg.Metadata["oci_reference"]; ref != strings.TrimPrefix(server.URL, "http://")+"/acme-oci/acme/greeter" {
+		t.Errorf("oci_reference = %v", ref)
+	}
+
+	versions, err := reg.FetchVersions(ctx, "acme/greeter")
+	if err != nil || len(versions) != 3 {
+		t.Errorf("FetchVersions = %+v, %v; want the three version tags", versions, err)
+	}
+
+	component, err := reg.FetchComponent(ctx, "acme:greeter", "0.2.0")
+	if err != nil {
+		t.Fatalf("FetchComponent failed: %v", err)
+	}
+	if component.Target != "wasi:http/proxy@0.2.0" || component.OS != "wasip2" || len(component.Imports) != 4 || len(component.Exports) != 1 {
+		t.Errorf("unexpected component %+v", component)
+	}
+	if component.Size != 4096 || component.Integrity != "sha256-AQIDBAUGBwgJCgsMDQ4PEBESExQVFhcYGRobHB0eHyA=" {
+		t.Errorf("layer = %d %q", component.Size, component.Integrity)
+	}
+
+	deps, err := reg.FetchDependencies(ctx, "acme:greeter", "0.2.0")
+	if err != nil {
+		t.Fatalf("FetchDependencies failed: %v", err)
+	}
+	if len(deps) != 2 || deps[0].Name != "wasi:clocks" || deps[1].Name != "wasi:io" || deps[1].Requirements != "0.2.0" {
+		t.Fatalf("unexpected dependencies %+v", deps)
+	}
+	if got := deps[1].Metadata["interfaces"]; !reflect.DeepEqual(got, []string{"error", "streams"}) {
+		t.Errorf("wasi:io interfaces = %v", got)
+	}
+
+	if _, err := reg.FetchComponent(ctx, "acme:greeter", "9.9.9"); !errors.Is(err, core.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for a missing version, got %v", err)
+	}
+	if _, err := reg.FetchPackage(ctx, "acme:missing"); !errors.Is(err, core.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for a missing package, got %v", err)
+	}
+	// One token for each repository, reused across requests
+	if n := atomic.LoadInt32(tokens); n != 2 {
+		t.Errorf("expected two token requests, got %d", n)
+	}
+}
+
+func TestWargRegistry(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"preferredProtocol": "warg", "warg": {"url": "https://warg.example.com"}}`))
+	}))
+	defer server.Close()
+
+	_, err := New(server.URL, core.DefaultClient()).FetchPackage(context.Background(), "acme:greeter")
+	if !errors.Is(err, core.ErrNotSupported) {
+		t.Errorf("expected ErrNotSupported, got %v", err)
+	}
+}
+
+func TestRegistryFor(t *testing.T) {
+	reg := New("", nil)
+	if got := reg.registryFor("wasi"); got != "https://wasi.dev" {
+		t.Errorf("registryFor(wasi) = %q", got)
+	}
+	if got := reg.registryFor("ba"); got != DefaultURL {
+		t.Errorf("registryFor(ba) = %q", got)
+	}
+	if got := New("https://registry.example.com", nil).registryFor("wasi"); got != "https://registry.example.com" {
+		t.Errorf("a configured registry should serve every namespace, got %q", got)
+	}
+}
+
+func TestURLs(t *testing.T) {
+	urls := New("", nil).URLs()
+	tests := map[string]string{
+		urls.PURL("wasi:http", "0.2.0"): "pkg:wasm/wasi/http@0.2.0",
+		urls.PURL("wasi/http", ""):      "pkg:wasm/wasi/http",
+	}
+	for got, want := range tests {
+		if got != want {
+			t.Errorf("PURL = %q, want %q", got, want)
+		}
+	}
+}
+
+func TestParseChallenge(t *testing.T) {
+	realm, params := parseChallenge(`Bearer realm="https://ghcr.io/token",service="ghcr.io",scope="repository:webassembly/wasi/http:pull"`)
+	if realm != "https://ghcr.io/token" || params["service"] != "ghcr.io" || params["scope"] != "repository:webassembly/wasi/http:pull" {
+		t.Errorf("parseChallenge = %q, %v", realm, params)
+	}
+	if realm, _ := parseChallenge(`Basic realm="registry"`); realm != "" {
+		t.Errorf("Basic challenge gave realm %q", realm)
+	}
+}
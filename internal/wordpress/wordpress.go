@@ -0,0 +1,392 @@
+// Package wordpress provides a registry client for WordPress.org plugins and themes.
+package wordpress
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/git-pkgs/registries/internal/core"
+	"github.com/git-pkgs/registries/internal/urlparser"
+	"github.com/git-pkgs/vers"
+)
+
+const (
+	DefaultURL = "https://api.wordpress.org"
+	ecosystem  = "wordpress"
+)
+
+func init() {
+	core.Register(ecosystem, DefaultURL, func(baseURL string, client *core.Client) core.Registry {
+		return New(baseURL, client)
+	})
+}
+
+// Registry looks up plugins by default. Themes live in a separate directory
+// and are selected with the "type=theme" PURL qualifier.
+type Registry struct {
+	baseURL string
+	client  *core.Client
+	urls    *URLs
+	themes  bool
+}
+
+func New(baseURL string, client *core.Client) *Registry {
+	if baseURL == "" {
+		baseURL = DefaultURL
+	}
+	r := &Registry{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client:  client,
+	}
+	r.urls = &URLs{baseURL: r.baseURL}
+	return r
+}
+
+// WithQualifiers applies the type qualifier of a PURL: "theme" switches the
+// registry to the theme directory.
+func (r *Registry) WithQualifiers(qualifiers map[string]string) core.Registry {
+	copy := *r
+	if qualifiers["type"] == "theme" {
+		copy.themes = true
+		copy.urls = &URLs{baseURL: r.baseURL, themes: true}
+	}
+	return &copy
+}
+
+func (r *Registry) Ecosystem() string {
+	return ecosystem
+}
+
+func (r *Registry) URLs() core.URLBuilder {
+	return r.urls
+}
+
+// infoResponse is the plugin_information or theme_information response. The
+// API is a PHP serialiser, so empty maps arrive as [] and unset values as
+// false, hence the loosely typed fields.
+type infoResponse struct {
+	Name             string          `json:"name"`
+	Slug             string          `json:"slug"`
+	Version          string          `json:"version"`
+	Author           json.RawMessage `json:"author"`
+	AuthorProfile    string          `json:"author_profile"`
+	Contributors     json.RawMessage `json:"contributors"`
+	Requires         any             `json:"requires"`
+	Tested           any             `json:"tested"`
+	RequiresPHP      any             `json:"requires_php"`
+	RequiresPlugins  []string        `json:"requires_plugins"`
+	Rating           float64         `json:"rating"`
+	NumRatings       int             `json:"num_ratings"`
+	ActiveInstalls   int             `json:"active_installs"`
+	Downloaded       int             `json:"downloaded"`
+	LastUpdated      string          `json:"last_updated"`
+	Added            string          `json:"added"`
+	Homepage         string          `json:"homepage"`
+	ShortDescription string          `json:"short_description"`
+	Sections         json.RawMessage `json:"sections"`
+	DownloadLink     string          `json:"download_link"`
+	Tags             json.RawMessage `json:"tags"`
+	Versions         json.RawMessage `json:"versions"`
+	Error            string          `json:"error"`
+}
+
+type contributor struct {
+	Profile     string `json:"profile"`
+	DisplayName string `json:"display_name"`
+}
+
+type themeAuthor struct {
+	UserNicename string `json:"user_nicename"`
+	Profile      string `json:"profile"`
+	DisplayName  string `json:"display_name"`
+}
+
+func (r *Registry) fetchInfo(ctx context.Context, name string) (*infoResponse, error) {
+	directory, action := "plugins", "plugin_information"
+	if r.themes {
+		directory, action = "themes", "theme_information"
+	}
+
+	params := url.Values{}
+	params.Set("action", action)
+	params.Set("request[slug]", name)
+	params.Set("request[fields][versions]", "1")
+	params.Set("request[fields][short_description]", "1")
+	params.Set("request[fields][reviews]", "0")
+	infoURL := fmt.Sprintf("%s/%s/info/1.2/?%s", r.baseURL, directory, params.Encode())
+
+	var resp infoResponse
+	if err := r.client.GetJSON(ctx, infoURL, &resp); err != nil {
+		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
+			return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name}
+		}
+		return nil, err
+	}
+	if resp.Error != "" || resp.Slug == "" {
+		return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name}
+	}
+	return &resp, nil
+}
+
+func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package, error) {
+	resp, err := r.fetchInfo(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	homepage := resp.Homepage
+	if homepage == "" {
+		homepage = r.urls.Registry(resp.Slug, "")
+	}
+
+	description := resp.ShortDescription
+	if description == "" {
+		description = sectionText(resp.Sections, "description")
+	}
+
+	metadata := compatibility(resp)
+	metadata["active_installs"] = resp.ActiveInstalls
+	metadata["downloads"] = resp.Downloaded
+	metadata["rating"] = resp.Rating
+	metadata["num_ratings"] = resp.NumRatings
+	if r.themes {
+		metadata["type"] = "theme"
+	}
+
+	return &core.Package{
+		Name:          resp.Slug,
+		Description:   html.UnescapeString(description),
+		Homepage:      homepage,
+		Repository:    urlparser.Parse(resp.Homepage),
+		Keywords:      tagNames(resp.Tags),
+		LatestVersion: resp.Version,
+		Metadata:      metadata,
+		Popularity:    &core.Popularity{DownloadsTotal: int64(resp.Downloaded)},
+	}, nil
+}
+
+// compatibility returns the WordPress and PHP version ranges a release
+// declares in its readme header.
+func compatibility(resp *infoResponse) map[string]any {
+	metadata := map[string]any{}
+	if v := stringValue(resp.Requires); v != "" {
+		metadata["requires_wordpress"] = v
+	}
+	if v := stringValue(resp.Tested); v != "" {
+		metadata["tested_wordpress"] = v
+	}
+	if v := stringValue(resp.RequiresPHP); v != "" {
+		metadata["requires_php"] = v
+	}
+	return metadata
+}
+
+// FetchVersions returns every release in the directory, newest first. Only
+// the current release carries a date and compatibility range; the API
+// doesn't record them for older ones.
+func (r *Registry) FetchVersions(ctx context.Context, name string) ([]core.Version, error) {
+	resp, err := r.fetchInfo(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	var downloads map[string]string
+	_ = json.Unmarshal(resp.Versions, &downloads)
+
+	numbers := make([]string, 0, len(downloads))
+	for number := range downloads {
+		if number == "trunk" {
+			continue
+		}
+		numbers = append(numbers, number)
+	}
+	if len(numbers) == 0 && resp.Version != "" {
+		numbers = append(numbers, resp.Version)
+	}
+	sort.Slice(numbers, func(i, j int) bool {
+		return vers.Compare(numbers[i], numbers[j]) > 0
+	})
+
+	versions := make([]core.Version, 0, len(numbers))
+	for _, number := range numbers {
+		v := core.Version{
+			Number: number,
+			Metadata: map[string]any{
+				"download_url": downloads[number],
+			},
+		}
+		if number == resp.Version {
+			v.PublishedAt = parseTime(resp.LastUpdated)
+			for key, value := range compatibility(resp) {
+				v.Metadata[key] = value
+			}
+		}
+		versions = append(versions, v)
+	}
+
+	return versions, nil
+}
+
+// FetchDependencies returns the plugins listed in the Requires Plugins header
+// of the current release. The API keeps no history, so version is not used.
+func (r *Registry) FetchDependencies(ctx context.Context, name, version string) ([]core.Dependency, error) {
+	resp, err := r.fetchInfo(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	deps := make([]core.Dependency, 0, len(resp.RequiresPlugins))
+	for _, slug := range resp.RequiresPlugins {
+		deps = append(deps, core.Dependency{
+			Name:  slug,
+			Scope: core.Runtime,
+		})
+	}
+
+	return deps, nil
+}
+
+func (r *Registry) FetchMaintainers(ctx context.Context, name string) ([]core.Maintainer, error) {
+	resp, err := r.fetchInfo(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	var contributors map[string]contributor
+	if err := json.Unmarshal(resp.Contributors, &contributors); err == nil && len(contributors) > 0 {
+		logins := make([]string, 0, len(contributors))
+		for login := range contributors {
+			logins = append(logins, login)
+		}
+		sort.Strings(logins)
+
+		maintainers := make([]core.Maintainer, 0, len(logins))
+		for _, login := range logins {
+			c := contributors[login]
+			maintainers = append(maintainers, core.Maintainer{
+				Login: login,
+				Name:  c.DisplayName,
+				URL:   c.Profile,
+			})
+		}
+		return maintainers, nil
+	}
+
+	// Themes only name their author
+	var author themeAuthor
+	if err := json.Unmarshal(resp.Author, &author); err == nil && author.UserNicename != "" {
+		return []core.Maintainer{{
+			Login: author.UserNicename,
+			Name:  author.DisplayName,
+			URL:   author.Profile,
+			Role:  "author",
+		}}, nil
+	}
+
+	var byline string
+	if err := json.Unmarshal(resp.Author, &byline); err == nil && byline != "" {
+		return []core.Maintainer{{
+			Name: stripTags(byline),
+			URL:  resp.AuthorProfile,
+			Role: "author",
+		}}, nil
+	}
+
+	return nil, nil
+}
+
+var tagPattern = regexp.MustCompile(`<[^>]*>`)
+
+// stripTags turns an HTML byline such as <a href="...">Automattic</a> into text.
+func stripTags(s string) string {
+	return strings.TrimSpace(html.UnescapeString(tagPattern.ReplaceAllString(s, "")))
+}
+
+// sectionText returns a readme section as plain text.
+func sectionText(raw json.RawMessage, name string) string {
+	var sections map[string]string
+	if err := json.Unmarshal(raw, &sections); err != nil {
+		return ""
+	}
+	return stripTags(sections[name])
+}
+
+// tagNames returns the tag slugs from a {"slug": "Name"} map.
+func tagNames(raw json.RawMessage) []string {
+	var tags map[string]string
+	if err := json.Unmarshal(raw, &tags); err != nil || len(tags) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(tags))
+	for slug := range tags {
+		names = append(names, slug)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func stringValue(v any) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	return ""
+}
+
+// parseTime reads the plugin format "2024-06-10 4:06pm GMT" and the theme
+// format "2024-07-16".
+func parseTime(s string) time.Time {
+	for _, layout := range []string{"2006-01-02 3:04pm MST", "2006-01-02 15:04:05", "2006-01-02"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.UTC()
+		}
+	}
+	return time.Time{}
+}
+
+type URLs struct {
+	baseURL string
+	themes  bool
+}
+
+func (u *URLs) directory() string {
+	if u.themes {
+		return "themes"
+	}
+	return "plugins"
+}
+
+func (u *URLs) Registry(name, version string) string {
+	return fmt.Sprintf("https://wordpress.org/%s/%s/", u.directory(), name)
+}
+
+func (u *URLs) Download(name, version string) string {
+	if version == "" {
+		return ""
+	}
+	if u.themes {
+		return fmt.Sprintf("https://downloads.wordpress.org/theme/%s.%s.zip", name, version)
+	}
+	return fmt.Sprintf("https://downloads.wordpress.org/plugin/%s.%s.zip", name, version)
+}
+
+func (u *URLs) Documentation(name, version string) string {
+	return u.Registry(name, version)
+}
+
+func (u *URLs) PURL(name, version string) string {
+	qualifiers := ""
+	if u.themes {
+		qualifiers = "?type=theme"
+	}
+	if version != "" {
+		return fmt.Sprintf("pkg:wordpress/%s@%s%s", name, version, qualifiers)
+	}
+	return fmt.Sprintf("pkg:wordpress/%s%s", name, qualifiers)
+}
@@ -0,0 +1,279 @@
+package wordpress
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+const akismetJSON = `{
+	"name": "Akismet Anti-spam: Spam Protection",
+	"slug": "akismet",
+	"version": "5.3.3",
+	"author": "<a href=\"https://automattic.com/wordpress-plugins/\">Automattic - Anti-spam Team</a>",
+	"author_profile": "https://profiles.wordpress.org/automattic/",
+	"contributors": {
+		"automattic": {"profile": "https://profiles.wordpress.org/automattic/", "avatar": "", "display_name": "Automattic"},
+		"matt": {"profile": "https://profiles.wordpress.org/matt/", "avatar": "", "display_name": "Matt Mullenweg"}
+	},
+	"requires": "5.8",
+	"tested": "6.6.1",
+	"requires_php": "5.6.20",
+	"requires_plugins": ["jetpack"],
+	"rating": 94,
+	"num_ratings": 1000,
+	"active_installs": 6000000,
+	"downloaded": 300000000,
+	"last_updated": "2024-07-23 3:01pm GMT",
+	"homepage": "https://akismet.com/",
+	"short_description": "The best anti-spam protection to block spam comments &amp; spam in contact forms.",
+	"download_link": "https://downloads.wordpress.org/plugin/akismet.5.3.3.zip",
+	"tags": {"anti-spam": "anti-spam", "comments": "comments"},
+	"versions": {
+		"5.3.3": "https://downloads.wordpress.org/plugin/akismet.5.3.3.zip",
+		"5.10": "https://downloads.wordpress.org/plugin/akismet.5.10.zip",
+		"5.3": "https://downloads.wordpress.org/plugin/akismet.5.3.zip",
+		"trunk": "https://downloads.wordpress.org/plugin/akismet.zip"
+	}
+}`
+
+func pluginServer(t *testing.T, body string) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/plugins/info/1.2/" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+			w.WriteHeader(404)
+			return
+		}
+		if r.URL.Query().Get("action") != "plugin_information" || r.URL.Query().Get("request[slug]") != "akismet" {
+			t.Errorf("unexpected query: %s", r.URL.RawQuery)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(body))
+	}))
+}
+
+func TestFetchPackage(t *testing.T) {
+	server := pluginServer(t, akismetJSON)
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	pkg, err := reg.FetchPackage(context.Background(), "akismet")
+	if err != nil {
+		t.Fatalf("FetchPackage failed: %v", err)
+	}
+
+	if pkg.Name != "akismet" {
+		t.Errorf("expected name 'akismet', got %q", pkg.Name)
+	}
+	if pkg.Description != "The best anti-spam protection to block spam comments & spam in contact forms." {
+		t.Errorf("unexpected description: %q", pkg.Description)
+	}
+	if pkg.Homepage != "https://akismet.com/" {
+		t.Errorf("unexpected homepage: %q", pkg.Homepage)
+	}
+	if pkg.LatestVersion != "5.3.3" {
+		t.Errorf("unexpected latest version: %q", pkg.LatestVersion)
+	}
+	if len(pkg.Keywords) != 2 || pkg.Keywords[0] != "anti-spam" {
+		t.Errorf("unexpected keywords: %v", pkg.Keywords)
+	}
+	if pkg.Metadata["requires_wordpress"] != "5.8" || pkg.Metadata["tested_wordpress"] != "6.6.1" || pkg.Metadata["requires_php"] != "5.6.20" {
+		t.Errorf("unexpected compatibility: %v", pkg.Metadata)
+	}
+	if pkg.Metadata["active_installs"] != 6000000 {
+		t.Errorf("unexpected active installs: %v", pkg.Metadata["active_installs"])
+	}
+}
+
+func TestFetchPackageNotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"error": "Plugin not found."}`))
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	if _, err := reg.FetchPackage(context.Background(), "nonexistent"); !errors.Is(err, core.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestFetchVersions(t *testing.T) {
+	server := pluginServer(t, akismetJSON)
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	versions, err := reg.FetchVersions(context.Background(), "akismet")
+	if err != nil {
+		t.Fatalf("FetchVersions failed: %v", err)
+	}
+
+	if len(versions) != 3 {
+		t.Fatalf("expected 3 versions, got %d", len(versions))
+	}
+	for i, want := range []string{"5.10", "5.3.3", "5.3"} {
+		if versions[i].Number != want {
+			t.Errorf("version %d: expected %q, got %q", i, want, versions[i].Number)
+		}
+	}
+
+	current := versions[1]
+	expected := time.Date(2024, 7, 23, 15, 1, 0, 0, time.UTC)
+	if !current.PublishedAt.Equal(expected) {
+		t.Errorf("unexpected published_at: %v", current.PublishedAt)
+	}
+	if current.Metadata["requires_php"] != "5.6.20" {
+		t.Errorf("expected compatibility on the current version, got %v", current.Metadata)
+	}
+	if _, ok := versions[2].Metadata["requires_php"]; ok {
+		t.Error("expected no compatibility on older versions")
+	}
+}
+
+func TestFetchDependencies(t *testing.T) {
+	server := pluginServer(t, akismetJSON)
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	deps, err := reg.FetchDependencies(context.Background(), "akismet", "5.3.3")
+	if err != nil {
+		t.Fatalf("FetchDependencies failed: %v", err)
+	}
+
+	if len(deps) != 1 || deps[0].Name != "jetpack" {
+		t.Errorf("unexpected dependencies: %+v", deps)
+	}
+}
+
+func TestFetchMaintainers(t *testing.T) {
+	server := pluginServer(t, akismetJSON)
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	maintainers, err := reg.FetchMaintainers(context.Background(), "akismet")
+	if err != nil {
+		t.Fatalf("FetchMaintainers failed: %v", err)
+	}
+
+	if len(maintainers) != 2 {
+		t.Fatalf("expected 2 maintainers, got %d", len(maintainers))
+	}
+	if maintainers[0].Login != "automattic" || maintainers[1].Name != "Matt Mullenweg" {
+		t.Errorf("unexpected maintainers: %+v", maintainers)
+	}
+}
+
+func TestFetchMaintainersByline(t *testing.T) {
+	server := pluginServer(t, `{"slug": "akismet", "version": "5.3.3", "contributors": [],
+		"author": "<a href=\"https://automattic.com/\">Automattic &amp; Friends</a>",
+		"author_profile": "https://profiles.wordpress.org/automattic/"}`)
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	maintainers, err := reg.FetchMaintainers(context.Background(), "akismet")
+	if err != nil {
+		t.Fatalf("FetchMaintainers failed: %v", err)
+	}
+
+	if len(maintainers) != 1 || maintainers[0].Name != "Automattic & Friends" {
+		t.Errorf("unexpected maintainers: %+v", maintainers)
+	}
+}
+
+func TestThemes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/themes/info/1.2/" || r.URL.Query().Get("action") != "theme_information" {
+			t.Errorf("unexpected request: %s", r.URL)
+			w.WriteHeader(404)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{
+			"name": "Twenty Twenty-Four",
+			"slug": "twentytwentyfour",
+			"version": "1.2",
+			"author": {"user_nicename": "wordpressdotorg", "profile": "https://profiles.wordpress.org/wordpressdotorg/", "display_name": "WordPress.org"},
+			"requires": "6.4",
+			"requires_php": "7.0",
+			"tested": false,
+			"last_updated": "2024-07-16",
+			"sections": {"description": "<p>Twenty Twenty-Four is designed to be flexible.</p>"},
+			"tags": [],
+			"versions": {"1.0": "https://downloads.wordpress.org/theme/twentytwentyfour.1.0.zip", "1.2": "https://downloads.wordpress.org/theme/twentytwentyfour.1.2.zip"}
+		}`))
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient()).WithQualifiers(map[string]string{"type": "theme"})
+
+	pkg, err := reg.FetchPackage(context.Background(), "twentytwentyfour")
+	if err != nil {
+		t.Fatalf("FetchPackage failed: %v", err)
+	}
+	if pkg.Description != "Twenty Twenty-Four is designed to be flexible." {
+		t.Errorf("unexpected description: %q", pkg.Description)
+	}
+	if pkg.Homepage != "https://wordpress.org/themes/twentytwentyfour/" {
+		t.Errorf("unexpected homepage: %q", pkg.Homepage)
+	}
+	if _, ok := pkg.Metadata["tested_wordpress"]; ok {
+		t.Error("expected an unset tested version to be omitted")
+	}
+
+	versions, err := core.FetchVersions(context.Background(), reg, "twentytwentyfour")
+	if err != nil {
+		t.Fatalf("FetchVersions failed: %v", err)
+	}
+	if len(versions) != 2 || !versions[0].PublishedAt.Equal(time.Date(2024, 7, 16, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected versions: %+v", versions)
+	}
+
+	maintainers, err := core.FetchMaintainers(context.Background(), reg, "twentytwentyfour")
+	if err != nil {
+		t.Fatalf("FetchMaintainers failed: %v", err)
+	}
+	if len(maintainers) != 1 || maintainers[0].Login != "wordpressdotorg" {
+		t.Errorf("unexpected maintainers: %+v", maintainers)
+	}
+
+	if got := reg.URLs().PURL("twentytwentyfour", "1.2"); got != "pkg:wordpress/twentytwentyfour@1.2?type=theme" {
+		t.Errorf("unexpected purl: %q", got)
+	}
+}
+
+func TestURLBuilder(t *testing.T) {
+	reg := New("", nil)
+	urls := reg.URLs()
+
+	tests := []struct {
+		name     string
+		fn       func() string
+		expected string
+	}{
+		{"registry", func() string { return urls.Registry("akismet", "") }, "https://wordpress.org/plugins/akismet/"},
+		{"download", func() string { return urls.Download("akismet", "5.3.3") }, "https://downloads.wordpress.org/plugin/akismet.5.3.3.zip"},
+		{"download without version", func() string { return urls.Download("akismet", "") }, ""},
+		{"purl", func() string { return urls.PURL("akismet", "5.3.3") }, "pkg:wordpress/akismet@5.3.3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.fn(); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestEcosystem(t *testing.T) {
+	reg := New("", nil)
+	if reg.Ecosystem() != "wordpress" {
+		t.Errorf("expected ecosystem 'wordpress', got %q", reg.Ecosystem())
+	}
+}
@@ -0,0 +1,33 @@
+// Package julia constructs clients for the Julia General registry.
+//
+//	reg, err := julia.New("", nil) // the General registry on GitHub
+//	pkg, err := reg.FetchPackage(ctx, name)
+package julia
+
+import (
+	"github.com/git-pkgs/registries/client"
+	"github.com/git-pkgs/registries/internal/core"
+	impl "github.com/git-pkgs/registries/internal/julia"
+)
+
+// DefaultURL is the registry New uses when given no base URL.
+const DefaultURL = impl.DefaultURL
+
+// Registry is the client New returns. It implements registries.Registry and
+// the optional interfaces the ecosystem supports.
+type Registry = impl.Registry
+
+// Artifact is an entry of a version's Artifacts.toml, as
+// Registry.FetchArtifacts returns it.
+type Artifact = impl.Artifact
+
+// ArtifactDownload is a tarball an Artifact can be installed from.
+type ArtifactDownload = impl.ArtifactDownload
+
+// New returns a client for the registry at baseURL, or DefaultURL if
+// baseURL is empty. A nil client uses client.DefaultClient. A baseURL that
+// fails registries.ValidateURL returns an error wrapping
+// registries.ErrInvalidURL.
+func New(baseURL string, c *client.Client) (*Registry, error) {
+	return core.Construct("julia", baseURL, c, impl.New)
+}
@@ -0,0 +1,31 @@
+package metadata
+
+// CargoPackage is the package metadata set by the cargo client.
+type CargoPackage struct {
+	Categories []string `json:"categories"`
+	Downloads  int      `json:"downloads"`
+}
+
+// CargoVersion is the version metadata set by the cargo client.
+type CargoVersion struct {
+	ID          int                 `json:"id"`
+	Downloads   int                 `json:"downloads"`
+	Features    map[string][]string `json:"features"`
+	RustVersion string              `json:"rust_version"` // minimum supported Rust version
+	CrateSize   int                 `json:"crate_size"`   // bytes
+	PublishedBy *CargoUser          `json:"published_by"`
+	YankMessage string              `json:"yank_message"`
+
+	Links          string   `json:"links"`           // native library named by the links key
+	InstallScripts []string `json:"install_scripts"` // "build.rs" when links is set
+	NativeCode     bool     `json:"native_code"`
+}
+
+// CargoUser is the crates.io account that published a version.
+type CargoUser struct {
+	ID     int    `json:"id"`
+	Login  string `json:"login"`
+	Name   string `json:"name"`
+	Avatar string `json:"avatar"`
+	URL    string `json:"url"`
+}
@@ -0,0 +1,27 @@
+package metadata
+
+import "time"
+
+// CondaVersion is the version metadata set by the conda client.
+type CondaVersion struct {
+	Downloads int          `json:"downloads"` // downloads of the first build listed
+	Builds    []CondaBuild `json:"builds"`
+	Channel   string       `json:"channel"` // channel the version was found in
+}
+
+// CondaBuild is one build of a conda version: the artifact for a single
+// platform subdir and variant, such as a particular Python version.
+type CondaBuild struct {
+	Subdir      string    `json:"subdir"` // linux-64, osx-arm64, noarch, ...
+	Build       string    `json:"build"`  // build string, e.g. py312h8753938_0
+	BuildNumber int       `json:"build_number"`
+	Filename    string    `json:"filename"` // path within the channel
+	URL         string    `json:"url"`
+	SHA256      string    `json:"sha256"`
+	MD5         string    `json:"md5"`
+	Size        int64     `json:"size"` // bytes
+	Depends     []string  `json:"depends"`
+	UploadedAt  time.Time `json:"uploaded_at"`
+	Downloads   int64     `json:"downloads"`
+	Labels      []string  `json:"labels"` // anaconda.org labels, e.g. main, broken
+}
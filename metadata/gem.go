@@ -0,0 +1,17 @@
+package metadata
+
+// GemPackage is the package metadata set by the gem client.
+type GemPackage struct {
+	Downloads  int    `json:"downloads"`
+	FundingURI string `json:"funding_uri"`
+}
+
+// GemVersion is the version metadata set by the gem client.
+type GemVersion struct {
+	Platform        string `json:"platform"` // "ruby" for pure-Ruby gems
+	Downloads       int    `json:"downloads"`
+	RubyVersion     string `json:"ruby_version"`     // required Ruby version
+	RubygemsVersion string `json:"rubygems_version"` // required RubyGems version
+	Prerelease      bool   `json:"prerelease"`
+	NativeCode      bool   `json:"native_code"` // precompiled for a platform
+}
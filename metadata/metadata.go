@@ -0,0 +1,13 @@
+// Package metadata defines typed schemas for the Metadata maps that registry
+// clients attach to packages and versions. The maps stay the source of truth,
+// so keys added to a client later are never lost; these structs document the
+// keys each ecosystem sets and give downstream code type-safe access to them:
+//
+//	versions, _ := registries.FetchVersions(ctx, reg, "react")
+//	if m, ok := registries.MetadataAs[metadata.NpmVersion](versions[0]); ok {
+//		fmt.Println(m.Dist.Tarball, m.Engines["node"])
+//	}
+//
+// Each field's json tag is the map key it reads. Fields a client didn't set
+// are left at their zero value.
+package metadata
@@ -0,0 +1,126 @@
+package metadata_test
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/git-pkgs/registries"
+	_ "github.com/git-pkgs/registries/all"
+	"github.com/git-pkgs/registries/metadata"
+	"github.com/git-pkgs/registries/registrytest"
+)
+
+// jsonKeys returns the map keys a schema struct declares.
+func jsonKeys(t reflect.Type) map[string]bool {
+	keys := make(map[string]bool)
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		keys[name] = true
+	}
+	return keys
+}
+
+// checkDeclared fails for metadata keys a client sets that its schema
+// doesn't declare, so the schemas are updated along with the clients.
+func checkDeclared(t *testing.T, schema any, m map[string]any) {
+	t.Helper()
+	typ := reflect.TypeOf(schema)
+	declared := jsonKeys(typ)
+	for key := range m {
+		if !declared[key] {
+			t.Errorf("%s doesn't declare metadata key %q", typ.Name(), key)
+		}
+	}
+}
+
+func TestSchemasCoverClientKeys(t *testing.T) {
+	tests := []struct {
+		ecosystem string
+		pkg       string
+		versions  string
+		pkgSchema any
+		verSchema any
+	}{
+		{"npm", "react", "react", metadata.NpmPackage{}, metadata.NpmVersion{}},
+		{"cargo", "serde", "serde", metadata.CargoPackage{}, metadata.CargoVersion{}},
+		{"pypi", "requests", "requests", metadata.PyPIPackage{}, metadata.PyPIVersion{}},
+		{"gem", "rails", "nokogiri", metadata.GemPackage{}, metadata.GemVersion{}},
+		{"pub", "flutter", "provider", metadata.PubPackage{}, nil},
+	}
+
+	ctx := context.Background()
+	for _, tt := range tests {
+		t.Run(tt.ecosystem, func(t *testing.T) {
+			fixture, err := registrytest.Fixture(tt.ecosystem)
+			if err != nil {
+				t.Fatal(err)
+			}
+			reg, err := registries.New(tt.ecosystem, "", registrytest.ReplayClient(fixture))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			pkg, err := reg.FetchPackage(ctx, tt.pkg)
+			if err != nil {
+				t.Fatalf("FetchPackage failed: %v", err)
+			}
+			checkDeclared(t, tt.pkgSchema, pkg.Metadata)
+
+			versions, err := registries.FetchVersions(ctx, reg, tt.versions)
+			if err != nil {
+				t.Fatalf("FetchVersions failed: %v", err)
+			}
+			if len(versions) == 0 {
+				t.Fatal("no versions")
+			}
+			for _, v := range versions {
+				if tt.verSchema == nil {
+					if v.Metadata != nil {
+						t.Errorf("version %s has metadata but no schema", v.Number)
+					}
+					continue
+				}
+				checkDeclared(t, tt.verSchema, v.Metadata)
+			}
+		})
+	}
+}
+
+func TestMetadataAs(t *testing.T) {
+	v := registries.Version{
+		Number: "18.2.0",
+		Metadata: map[string]any{
+			"deprecated": "",
+			"dist":       map[string]any{"tarball": "https://registry.npmjs.org/react/-/react-18.2.0.tgz", "integrity": "sha512-abc"},
+			"engines":    map[string]string{"node": ">=0.10.0"},
+			"_npmUser":   map[string]any{"name": "gnoff", "email": "gnoff@example.com"},
+			"future_key": 42,
+		},
+	}
+
+	m, ok := registries.MetadataAs[metadata.NpmVersion](v)
+	if !ok {
+		t.Fatal("MetadataAs failed")
+	}
+	if m.Dist.Integrity != "sha512-abc" || m.Engines["node"] != ">=0.10.0" {
+		t.Errorf("unexpected metadata %+v", m)
+	}
+	if m.NpmUser == nil || m.NpmUser.Name != "gnoff" {
+		t.Errorf("unexpected npm user %+v", m.NpmUser)
+	}
+
+	if _, ok := registries.MetadataAs[metadata.NpmVersion](registries.Version{}); ok {
+		t.Error("expected false for a version without metadata")
+	}
+	if _, ok := registries.MetadataAs[metadata.CargoVersion](v); ok {
+		t.Error("expected false for metadata that doesn't fit the schema")
+	}
+
+	pkg := registries.Package{Metadata: map[string]any{"categories": []string{"encoding"}, "downloads": 100}}
+	cargo, ok := registries.PackageMetadataAs[metadata.CargoPackage](pkg)
+	if !ok || cargo.Downloads != 100 || len(cargo.Categories) != 1 {
+		t.Errorf("unexpected package metadata %+v, %v", cargo, ok)
+	}
+}
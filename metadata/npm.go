@@ -0,0 +1,35 @@
+package metadata
+
+// NpmPackage is the package metadata set by the npm client.
+type NpmPackage struct {
+	DistTags map[string]string `json:"dist-tags"` // tag name to version, e.g. "latest"
+	Funding  any               `json:"funding"`   // a URL string, an object, or a list of either
+}
+
+// NpmVersion is the version metadata set by the npm client.
+type NpmVersion struct {
+	Deprecated string            `json:"deprecated"` // deprecation message, empty if not deprecated
+	Dist       NpmDist           `json:"dist"`
+	Engines    map[string]string `json:"engines"` // e.g. "node": ">=18"
+	NpmUser    *NpmUser          `json:"_npmUser"`
+	Tarball    string            `json:"tarball"`
+
+	InstallScripts []string `json:"install_scripts"` // lifecycle scripts run on install, e.g. "postinstall"
+	NativeCode     bool     `json:"native_code"`     // builds a node-gyp addon
+}
+
+// NpmDist describes a version's published tarball.
+type NpmDist struct {
+	Shasum    string `json:"shasum"`
+	Tarball   string `json:"tarball"`
+	Integrity string `json:"integrity"` // SRI string, e.g. sha512-...
+
+	UnpackedSize int64 `json:"unpackedSize,omitempty"` // also Version.UnpackedSize
+	FileCount    int   `json:"fileCount,omitempty"`    // also Version.FileCount
+}
+
+// NpmUser is the account that published a version.
+type NpmUser struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
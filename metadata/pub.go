@@ -0,0 +1,13 @@
+package metadata
+
+// PubPackage is the package metadata set by the pub client from pub.dev's
+// score endpoint. Servers without the endpoint set none of it.
+type PubPackage struct {
+	Points          int      `json:"points"`     // pub points granted
+	MaxPoints       int      `json:"max_points"` // pub points available
+	Likes           int      `json:"likes"`
+	Downloads30Days int      `json:"downloads_30_days"`
+	Popularity      float64  `json:"popularity"` // retired 0-1 score, absent from newer data
+	Tags            []string `json:"tags"`       // "sdk:flutter", "platform:web", "is:dart3-compatible", ...
+	Publisher       string   `json:"publisher"`  // verified publisher domain
+}
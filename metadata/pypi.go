@@ -0,0 +1,22 @@
+package metadata
+
+// PyPIPackage is the package metadata set by the pypi client.
+type PyPIPackage struct {
+	Classifiers    []string `json:"classifiers"`
+	Documentation  string   `json:"documentation"`   // the "Documentation" project URL
+	NormalizedName string   `json:"normalized_name"` // PEP 503 normalized name
+	ProvidesExtra  []string `json:"provides_extra"`
+}
+
+// PyPIVersion is the version metadata set by the pypi client, describing the
+// first release file PyPI lists for the version.
+type PyPIVersion struct {
+	DownloadURL    string `json:"download_url"`
+	RequiresPython string `json:"requires_python"`
+	YankedReason   string `json:"yanked_reason"`
+	PackageType    string `json:"packagetype"` // sdist or bdist_wheel
+	Size           int    `json:"size"`        // bytes
+
+	InstallScripts []string `json:"install_scripts"` // "sdist" when there's no wheel to install
+	NativeCode     bool     `json:"native_code"`     // platform wheels or native-language classifiers
+}
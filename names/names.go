@@ -0,0 +1,84 @@
+// Package names canonicalises package names per ecosystem and detects names
+// that imitate popular packages.
+//
+//	names.Normalize("pypi", "Zope.Interface")       // "zope-interface"
+//	names.Equal("cargo", "serde_json", "serde-json") // true
+//
+//	c := names.NewChecker("npm", popular)
+//	for _, m := range c.Check("lodahs") {
+//		fmt.Println(m.Target, m.Reason, m.Distance) // lodash transposition 1
+//	}
+//
+// Ecosystems are named by PURL type, as elsewhere in this module.
+package names
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var pypiSeparators = regexp.MustCompile(`[-_.]+`)
+
+// Normalize returns the canonical form of a package name: two names that
+// normalize to the same string refer to the same package on that registry.
+//
+//   - pypi: PEP 503, lowercase with runs of "-", "_" and "." collapsed to "-"
+//   - cargo: lowercase, "_" treated as "-" (crates.io considers them equal)
+//   - npm: percent-decoded scope ("%40babel/core" becomes "@babel/core"),
+//     lowercase since new names must be
+//   - maven: "group:artifact", accepting "group/artifact"
+//   - cpan: "Foo::Bar" module names become the "foo-bar" distribution name
+//   - nuget, composer, cocoapods, conda, brew, hex, pub, luarocks,
+//     terraform: lowercase, as those registries are case-insensitive
+//   - golang, gem, hackage and others: case-sensitive, only trimmed
+func Normalize(ecosystem, name string) string {
+	name = strings.TrimSpace(name)
+
+	switch ecosystem {
+	case "pypi":
+		return pypiSeparators.ReplaceAllString(strings.ToLower(name), "-")
+	case "cargo":
+		return strings.ReplaceAll(strings.ToLower(name), "_", "-")
+	case "npm":
+		if decoded, err := url.PathUnescape(name); err == nil {
+			name = decoded
+		}
+		return strings.ToLower(name)
+	case "maven":
+		if !strings.Contains(name, ":") && strings.Count(name, "/") == 1 {
+			name = strings.Replace(name, "/", ":", 1)
+		}
+		return name
+	case "cpan":
+		return strings.ToLower(strings.ReplaceAll(name, "::", "-"))
+	case "nuget", "composer", "cocoapods", "conda", "brew", "hex", "pub", "luarocks", "terraform":
+		return strings.ToLower(name)
+	default:
+		return name
+	}
+}
+
+// Equal reports whether two names refer to the same package.
+func Equal(ecosystem, a, b string) bool {
+	return Normalize(ecosystem, a) == Normalize(ecosystem, b)
+}
+
+// Split separates a namespaced name into namespace and base name: the scope
+// of an npm package, a Maven group, a Composer vendor, a Go module's path
+// prefix. Names without a namespace return an empty namespace.
+func Split(ecosystem, name string) (namespace, base string) {
+	name = Normalize(ecosystem, name)
+	sep := "/"
+	if ecosystem == "maven" {
+		sep = ":"
+	}
+	if ecosystem == "npm" && !strings.HasPrefix(name, "@") {
+		return "", name
+	}
+	i := strings.LastIndex(name, sep)
+	if i < 0 {
+		return "", name
+	}
+	return name[:i], name[i+1:]
+}
@@ -0,0 +1,191 @@
+package names
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		ecosystem, name, want string
+	}{
+		{"pypi", "Zope.Interface", "zope-interface"},
+		{"pypi", "python__dateutil", "python-dateutil"},
+		{"cargo", "Serde_JSON", "serde-json"},
+		{"npm", "%40Babel/Core", "@babel/core"},
+		{"maven", "org.apache.commons/commons-lang3", "org.apache.commons:commons-lang3"},
+		{"maven", "org.Apache:Foo", "org.Apache:Foo"},
+		{"cpan", "Moose::Util", "moose-util"},
+		{"nuget", "Newtonsoft.Json", "newtonsoft.json"},
+		{"golang", " github.com/BurntSushi/toml ", "github.com/BurntSushi/toml"},
+		{"gem", "Rails", "Rails"},
+	}
+	for _, tt := range tests {
+		if got := Normalize(tt.ecosystem, tt.name); got != tt.want {
+			t.Errorf("Normalize(%q, %q) = %q, want %q", tt.ecosystem, tt.name, got, tt.want)
+		}
+	}
+
+	if !Equal("pypi", "Django_REST.framework", "django-rest-framework") {
+		t.Error("expected PEP 503 names to be equal")
+	}
+	if Equal("golang", "github.com/a/B", "github.com/a/b") {
+		t.Error("expected Go module paths to be case-sensitive")
+	}
+}
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		ecosystem, name, namespace, base string
+	}{
+		{"npm", "@babel/core", "@babel", "core"},
+		{"npm", "lodash", "", "lodash"},
+		{"maven", "org.slf4j:slf4j-api", "org.slf4j", "slf4j-api"},
+		{"composer", "Laravel/Framework", "laravel", "framework"},
+		{"pypi", "requests", "", "requests"},
+	}
+	for _, tt := range tests {
+		ns, base := Split(tt.ecosystem, tt.name)
+		if ns != tt.namespace || base != tt.base {
+			t.Errorf("Split(%q, %q) = %q, %q", tt.ecosystem, tt.name, ns, base)
+		}
+	}
+}
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "abc", 3},
+		{"requests", "requests", 0},
+		{"requests", "reqeusts", 1},
+		{"lodash", "lodahs", 1},
+		{"kitten", "sitting", 3},
+		{"café", "cafe", 1},
+	}
+	for _, tt := range tests {
+		if got := Distance(tt.a, tt.b); got != tt.want {
+			t.Errorf("Distance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestChecker(t *testing.T) {
+	npm := NewChecker("npm", []string{"lodash", "express", "@babel/core", "react", "mocha"})
+
+	tests := []struct {
+		name   string
+		target string
+		reason Reason
+	}{
+		{"lodahs", "lodash", Transposition},
+		{"expres", "express", EditDistance},
+		{"1odash", "lodash", Confusable},
+		{"rnocha", "mocha", Confusable},
+		{"lodash-js", "lodash", Affix},
+		{"babel-core", "@babel/core", ScopeSquat},
+		{"@babe1/core", "@babel/core", ScopeSquat},
+	}
+	for _, tt := range tests {
+		matches := npm.Check(tt.name)
+		if len(matches) == 0 {
+			t.Errorf("Check(%q): no matches", tt.name)
+			continue
+		}
+		if matches[0].Target != tt.target || matches[0].Reason != tt.reason {
+			t.Errorf("Check(%q) = %+v, want %s %s", tt.name, matches[0], tt.target, tt.reason)
+		}
+	}
+
+	if m := npm.Check("Lodash"); m != nil {
+		t.Errorf("expected no matches for the package itself, got %+v", m)
+	}
+	if m := npm.Check("reakt"); len(m) != 1 || m[0].Target != "react" {
+		t.Errorf("expected react match, got %+v", m)
+	}
+	if m := NewChecker("npm", []string{"react"}, WithMinLength(6)).Check("reakt"); m != nil {
+		t.Errorf("expected names shorter than the minimum to be ignored, got %+v", m)
+	}
+	if m := npm.Check("underscore"); m != nil {
+		t.Errorf("expected no matches for an unrelated name, got %+v", m)
+	}
+
+	pypi := NewChecker("pypi", []string{"python-dateutil"})
+	if m := pypi.Check("python_dateutil"); m != nil {
+		t.Errorf("expected PEP 503 equivalents to be the same package, got %+v", m)
+	}
+	if m := pypi.Check("pythondateutil"); len(m) != 1 || m[0].Reason != Separators {
+		t.Errorf("expected separators match, got %+v", m)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	valid := []struct{ ecosystem, name string }{
+		{"npm", "lodash"},
+		{"npm", "@babel/core"},
+		{"npm", "JSONStream"},
+		{"cargo", "serde_json"},
+		{"maven", "org.slf4j:slf4j-api"},
+		{"maven", "org.slf4j/slf4j-api"},
+		{"pypi", "Zope.Interface"},
+		{"conda", "numpy"},
+		{"conda", "conda-forge/numpy"},
+		{"conda", "conda-forge/label/broken/numpy"},
+		{"golang", "github.com/BurntSushi/toml"},
+		{"golang", "gopkg.in/yaml.v3"},
+		{"composer", "symfony/console"},
+		{"hex", "phoenix_live_view"},
+		{"hex", "acme/billing_core"},
+		{"pub", "flutter_bloc"},
+		{"nuget", "Newtonsoft.Json"},
+		{"gem", "rails"},
+		{"cran", "data.table"},
+		{"wasm", "wasi:http"},
+		{"wasm", "ba/sample-wasi-http-rust"},
+	}
+	for _, tt := range valid {
+		if err := Validate(tt.ecosystem, tt.name); err != nil {
+			t.Errorf("Validate(%q, %q) = %v", tt.ecosystem, tt.name, err)
+		}
+	}
+
+	invalid := []struct{ ecosystem, name, reason string }{
+		{"npm", "", "empty"},
+		{"npm", " lodash", "whitespace"},
+		{"npm", "@babel", "@scope/name"},
+		{"npm", "@babel/", "@scope/name"},
+		{"npm", "lodash utils", "URL-safe"},
+		{"npm", "_private", `start with`},
+		{"cargo", "1password", "ASCII letter"},
+		{"cargo", "serde.json", "ASCII letter"},
+		{"maven", "slf4j-api", "group:artifact"},
+		{"maven", "org.slf4j:slf4j-api:2.0.9", "more than one"},
+		{"pypi", "-requests", "starting and ending"},
+		{"conda", "conda forge/numpy", "channel/name"},
+		{"conda", "conda-forge/label/numpy", "label"},
+		{"golang", "toml", "domain"},
+		{"golang", "github.com/user/repo@v1.0.0", "versions"},
+		{"golang", "github.com//repo", "empty elements"},
+		{"composer", "monolog", "vendor/package"},
+		{"hex", "Phoenix-LiveView", "letter followed by"},
+		{"hex", "Acme Corp/billing", "organization names"},
+		{"hex", "acme/billing/core", "letter followed by"},
+		{"gem", "rails\x00", "control character"},
+		{"wasm", "http", "namespace:name"},
+		{"wasm", "wasi:http@0.2.0", "versions"},
+		{"wasm", "WASI:Http", "lowercase words"},
+	}
+	for _, tt := range invalid {
+		err := Validate(tt.ecosystem, tt.name)
+		var nameErr *InvalidNameError
+		if !errors.As(err, &nameErr) || !errors.Is(err, ErrInvalidName) {
+			t.Errorf("Validate(%q, %q) = %v, want an InvalidNameError", tt.ecosystem, tt.name, err)
+			continue
+		}
+		if !strings.Contains(nameErr.Reason, tt.reason) {
+			t.Errorf("Validate(%q, %q) reason %q, want it to mention %q", tt.ecosystem, tt.name, nameErr.Reason, tt.reason)
+		}
+	}
+}
@@ -0,0 +1,235 @@
+package names
+
+import (
+	"sort"
+	"strings"
+)
+
+// Reason explains why a name resembles a popular one.
+type Reason string
+
+const (
+	// EditDistance is a small number of inserted, deleted or substituted
+	// characters, e.g. "reqeusts" for "requests".
+	EditDistance Reason = "edit_distance"
+	// Transposition is two adjacent characters swapped, e.g. "lodahs".
+	Transposition Reason = "transposition"
+	// Confusable uses look-alike characters, e.g. "1odash" or "rnocha".
+	Confusable Reason = "confusable"
+	// Separators differs only in "-", "_" or ".", e.g. "python_dateutil".
+	Separators Reason = "separators"
+	// Affix adds a common prefix or suffix, e.g. "lodash-js" or "pyrequests".
+	Affix Reason = "affix"
+	// ScopeSquat reuses a scoped package's name without the scope or under
+	// another scope, e.g. "babel-core" or "@bable/core" for "@babel/core".
+	ScopeSquat Reason = "scope_squat"
+)
+
+// Match is a popular package that a checked name resembles.
+type Match struct {
+	Target   string // the popular name
+	Reason   Reason
+	Distance int // edit distance between the normalized names
+}
+
+// affixes are words typosquats commonly add to a real package name.
+var affixes = []string{"js", "node", "py", "python", "python3", "lib", "cli", "go", "rs", "rust", "dev", "core", "utils", "util", "api", "sdk", "plugin", "official"}
+
+// confusables maps look-alike character sequences to a canonical form.
+var confusables = strings.NewReplacer(
+	"rn", "m",
+	"vv", "w",
+	"cl", "d",
+	"0", "o",
+	"1", "l",
+	"i", "l",
+	"5", "s",
+	"3", "e",
+	"$", "s",
+)
+
+// Checker compares names against a list of popular packages.
+type Checker struct {
+	ecosystem string
+	popular   map[string]string // normalized -> original
+	keys      []string
+	minLength int
+}
+
+// CheckerOption configures a Checker.
+type CheckerOption func(*Checker)
+
+// WithMinLength sets the shortest name the checker considers. Very short
+// names are within a couple of edits of each other by chance; the default
+// is 4.
+func WithMinLength(n int) CheckerOption {
+	return func(c *Checker) {
+		c.minLength = n
+	}
+}
+
+// NewChecker returns a checker for names in ecosystem that resemble any of
+// the popular names.
+func NewChecker(ecosystem string, popular []string, opts ...CheckerOption) *Checker {
+	c := &Checker{
+		ecosystem: ecosystem,
+		popular:   make(map[string]string, len(popular)),
+		minLength: 4,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	for _, p := range popular {
+		key := Normalize(ecosystem, p)
+		if _, ok := c.popular[key]; !ok {
+			c.popular[key] = p
+			c.keys = append(c.keys, key)
+		}
+	}
+	sort.Strings(c.keys)
+	return c
+}
+
+// maxDistance is the largest edit distance still considered a typo of a
+// name of the given length.
+func maxDistance(length int) int {
+	if length <= 8 {
+		return 1
+	}
+	return 2
+}
+
+// Check returns the popular packages that name resembles, closest first. A
+// name that is itself one of the popular packages has no matches.
+func (c *Checker) Check(name string) []Match {
+	key := Normalize(c.ecosystem, name)
+	if _, ok := c.popular[key]; ok {
+		return nil
+	}
+
+	var matches []Match
+	for _, target := range c.keys {
+		if len(target) < c.minLength {
+			continue
+		}
+		if reason, dist, ok := c.compare(key, target); ok {
+			matches = append(matches, Match{Target: c.popular[target], Reason: reason, Distance: dist})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Distance != matches[j].Distance {
+			return matches[i].Distance < matches[j].Distance
+		}
+		return matches[i].Target < matches[j].Target
+	})
+	return matches
+}
+
+// compare decides whether key imitates target, both normalized.
+func (c *Checker) compare(key, target string) (Reason, int, bool) {
+	keyNS, keyBase := Split(c.ecosystem, key)
+	targetNS, targetBase := Split(c.ecosystem, target)
+
+	if c.ecosystem == "npm" && targetNS != "" && keyNS != targetNS {
+		// "babel-core" or "@evil/core" for "@babel/core"
+		bare := strings.TrimPrefix(targetNS, "@") + "-" + targetBase
+		if key == bare || key == strings.TrimPrefix(targetNS, "@")+targetBase {
+			return ScopeSquat, Distance(key, target), true
+		}
+		if keyNS != "" && keyBase == targetBase && Distance(keyNS, targetNS) <= 1 {
+			return ScopeSquat, Distance(key, target), true
+		}
+	}
+
+	dist := Distance(key, target)
+	if dist == 0 {
+		return "", 0, false
+	}
+
+	if stripSeparators(key) == stripSeparators(target) {
+		return Separators, dist, true
+	}
+	if confusables.Replace(key) == confusables.Replace(target) {
+		return Confusable, dist, true
+	}
+	if dist <= maxDistance(len(target)) && len(key) >= c.minLength {
+		if dist == 1 && isTransposition(key, target) {
+			return Transposition, dist, true
+		}
+		return EditDistance, dist, true
+	}
+	if keyNS == targetNS && hasAffix(keyBase, targetBase) {
+		return Affix, dist, true
+	}
+	return "", 0, false
+}
+
+func stripSeparators(s string) string {
+	return strings.NewReplacer("-", "", "_", "", ".", "").Replace(s)
+}
+
+// hasAffix reports whether name is target with a common word added before
+// or after it, with or without a separator.
+func hasAffix(name, target string) bool {
+	if !strings.Contains(name, target) || name == target {
+		return false
+	}
+	rest := strings.Replace(name, target, "", 1)
+	rest = strings.Trim(rest, "-_.")
+	for _, a := range affixes {
+		if rest == a {
+			return true
+		}
+	}
+	return false
+}
+
+func isTransposition(a, b string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := 0; i < len(a)-1; i++ {
+		if a[i] != b[i] {
+			return a[i] == b[i+1] && a[i+1] == b[i] && a[i+2:] == b[i+2:]
+		}
+	}
+	return false
+}
+
+// Distance returns the optimal string alignment distance between a and b:
+// the number of single-character insertions, deletions, substitutions and
+// adjacent transpositions needed to turn one into the other.
+func Distance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) == 0 {
+		return len(rb)
+	}
+	if len(rb) == 0 {
+		return len(ra)
+	}
+
+	// Three rolling rows are enough for transpositions
+	prev2 := make([]int, len(rb)+1)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				cur[j] = min(cur[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return prev[len(rb)]
+}
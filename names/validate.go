@@ -0,0 +1,214 @@
+package names
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// ErrInvalidName is wrapped by the errors Validate returns.
+var ErrInvalidName = errors.New("invalid package name")
+
+// InvalidNameError explains why a name can't exist on a registry.
+type InvalidNameError struct {
+	Ecosystem string
+	Name      string
+	Reason    string
+}
+
+func (e *InvalidNameError) Error() string {
+	return fmt.Sprintf("%s: invalid package name %q: %s", e.Ecosystem, e.Name, e.Reason)
+}
+
+func (e *InvalidNameError) Unwrap() error {
+	return ErrInvalidName
+}
+
+var (
+	npmPart         = regexp.MustCompile(`^[A-Za-z0-9._~!'()*-]+$`)
+	cratePattern    = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]*$`)
+	mavenPart       = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+	pypiPattern     = regexp.MustCompile(`(?i)^([a-z0-9]|[a-z0-9][a-z0-9._-]*[a-z0-9])$`)
+	condaPart       = regexp.MustCompile(`(?i)^[a-z0-9_.-]+$`)
+	gemPattern      = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+	nugetPattern    = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+	hexPattern      = regexp.MustCompile(`(?i)^[a-z][a-z0-9_]*$`)
+	hexOrg          = regexp.MustCompile(`^[a-z0-9_]+$`)
+	pubPattern      = regexp.MustCompile(`(?i)^[a-z_][a-z0-9_]*$`)
+	goElement       = regexp.MustCompile(`^[A-Za-z0-9._~+-]+$`)
+	composerVendor  = regexp.MustCompile(`(?i)^[a-z0-9]([_.-]?[a-z0-9]+)*$`)
+	composerPackage = regexp.MustCompile(`(?i)^[a-z0-9](([_.]?|-{0,2})[a-z0-9]+)*$`)
+	witLabel        = regexp.MustCompile(`^[a-z][a-z0-9]*(-[a-z][a-z0-9]*)*$`)
+)
+
+// Validate reports whether name could exist on an ecosystem's registry,
+// returning an *InvalidNameError describing the first rule it breaks. It
+// catches names that would otherwise fail upstream with an unhelpful 400 or
+// 404, such as an npm scope without a package or a Maven coordinate
+// without a group:
+//
+//   - npm: at most 214 characters, "@scope/name" or a bare name, with no
+//     leading "." or "_" and only URL-safe characters
+//   - cargo: an ASCII letter followed by letters, digits, "-" and "_", at
+//     most 64 characters
+//   - maven: "group:artifact" (or "group/artifact")
+//   - pypi: PEP 508 names
+//   - conda: a name, optionally prefixed by "channel/" or
+//     "channel/label/<label>/"
+//   - golang: a module path whose first element is a domain
+//   - hex: a name, optionally prefixed by "organization/"
+//   - wasm: "namespace:name" (or "namespace/name") in WIT's kebab-case
+//   - gem, nuget, hex, pub, composer: each registry's character rules
+//
+// Every ecosystem rejects empty names, surrounding whitespace and control
+// characters. Names are checked as given, not normalized, except that rules
+// for case-insensitive registries ignore case.
+func Validate(ecosystem, name string) error {
+	invalid := func(format string, args ...any) error {
+		return &InvalidNameError{Ecosystem: ecosystem, Name: name, Reason: fmt.Sprintf(format, args...)}
+	}
+
+	if name == "" {
+		return invalid("name is empty")
+	}
+	if strings.TrimSpace(name) != name {
+		return invalid("name has leading or trailing whitespace")
+	}
+	for _, r := range name {
+		if unicode.IsControl(r) {
+			return invalid("name contains a control character")
+		}
+	}
+
+	switch ecosystem {
+	case "npm":
+		if len(name) > 214 {
+			return invalid("names are at most 214 characters")
+		}
+		base := name
+		if strings.HasPrefix(name, "@") {
+			scope, pkg, ok := strings.Cut(name[1:], "/")
+			if !ok || scope == "" || pkg == "" {
+				return invalid(`scoped names look like "@scope/name"`)
+			}
+			if !npmPart.MatchString(scope) {
+				return invalid("scope %q has characters that aren't URL-safe", scope)
+			}
+			base = pkg
+		}
+		if !npmPart.MatchString(base) {
+			return invalid("name has characters that aren't URL-safe")
+		}
+		if strings.HasPrefix(base, ".") || strings.HasPrefix(base, "_") {
+			return invalid(`names can't start with "." or "_"`)
+		}
+	case "cargo":
+		if len(name) > 64 {
+			return invalid("crate names are at most 64 characters")
+		}
+		if !cratePattern.MatchString(name) {
+			return invalid(`crate names are an ASCII letter followed by letters, digits, "-" and "_"`)
+		}
+	case "maven":
+		coordinate := Normalize(ecosystem, name)
+		group, artifact, ok := strings.Cut(coordinate, ":")
+		if !ok || group == "" || artifact == "" {
+			return invalid(`Maven names look like "group:artifact", such as "org.slf4j:slf4j-api"`)
+		}
+		if strings.Contains(artifact, ":") {
+			return invalid(`name has more than one ":"; versions and classifiers don't belong in the name`)
+		}
+		if !mavenPart.MatchString(group) || !mavenPart.MatchString(artifact) {
+			return invalid(`group and artifact IDs are letters, digits, ".", "-" and "_"`)
+		}
+	case "pypi":
+		if !pypiPattern.MatchString(name) {
+			return invalid(`names are letters, digits, ".", "-" and "_", starting and ending with a letter or digit`)
+		}
+	case "conda":
+		pkg := name
+		if channel, rest, ok := strings.Cut(name, "/"); ok {
+			if !condaPart.MatchString(channel) {
+				return invalid(`names look like "name" or "channel/name"`)
+			}
+			pkg = rest
+			if after, ok := strings.CutPrefix(pkg, "label/"); ok {
+				label, rest, ok := strings.Cut(after, "/")
+				if !ok || !condaPart.MatchString(label) {
+					return invalid(`labelled names look like "channel/label/<label>/name"`)
+				}
+				pkg = rest
+			}
+		}
+		if !condaPart.MatchString(pkg) {
+			return invalid(`names are letters, digits, ".", "-" and "_"`)
+		}
+	case "golang":
+		if strings.Contains(name, "@") {
+			return invalid("versions don't belong in the module path")
+		}
+		if strings.HasPrefix(name, "/") || strings.HasSuffix(name, "/") || strings.Contains(name, "//") {
+			return invalid("module paths have no empty elements")
+		}
+		elements := strings.Split(name, "/")
+		if !strings.Contains(elements[0], ".") || strings.HasPrefix(elements[0], "-") {
+			return invalid(`module paths start with a domain, such as "github.com/user/repo"`)
+		}
+		for _, elem := range elements {
+			if !goElement.MatchString(elem) || elem == "." || elem == ".." {
+				return invalid("module path element %q is not allowed", elem)
+			}
+		}
+	case "gem":
+		if !gemPattern.MatchString(name) {
+			return invalid(`gem names are letters, digits, ".", "-" and "_"`)
+		}
+	case "nuget":
+		if len(name) > 100 {
+			return invalid("package IDs are at most 100 characters")
+		}
+		if !nugetPattern.MatchString(name) {
+			return invalid(`package IDs are letters, digits, ".", "-" and "_"`)
+		}
+	case "hex":
+		pkg := name
+		if org, rest, ok := strings.Cut(name, "/"); ok {
+			if !hexOrg.MatchString(org) {
+				return invalid(`organization names are lowercase letters, digits and "_"`)
+			}
+			pkg = rest
+		}
+		if !hexPattern.MatchString(pkg) {
+			return invalid(`names are a letter followed by letters, digits and "_"`)
+		}
+	case "pub":
+		if !pubPattern.MatchString(name) {
+			return invalid(`names are letters, digits and "_", not starting with a digit`)
+		}
+	case "composer":
+		vendor, pkg, ok := strings.Cut(name, "/")
+		if !ok || vendor == "" || pkg == "" {
+			return invalid(`names look like "vendor/package"`)
+		}
+		if !composerVendor.MatchString(vendor) || !composerPackage.MatchString(pkg) {
+			return invalid(`vendor and package names are letters and digits, separated by single ".", "-" or "_"`)
+		}
+	case "wasm":
+		if strings.Contains(name, "@") {
+			return invalid("versions don't belong in the name")
+		}
+		namespace, pkg, ok := strings.Cut(name, ":")
+		if !ok {
+			namespace, pkg, ok = strings.Cut(name, "/")
+		}
+		if !ok || namespace == "" || pkg == "" {
+			return invalid(`names look like "namespace:name", such as "wasi:http"`)
+		}
+		if !witLabel.MatchString(namespace) || !witLabel.MatchString(pkg) {
+			return invalid(`namespaces and names are lowercase words of letters and digits joined by "-"`)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package npm
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/git-pkgs/registries/internal/core"
+)
+
+func TestNew(t *testing.T) {
+	serve := func(description string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"name":"left-pad","description":"` + description + `","dist-tags":{"latest":"1.0.0"},"versions":{"1.0.0":{}}}`))
+		}))
+	}
+	public, mirror := serve("public"), serve("mirror")
+	defer public.Close()
+	defer mirror.Close()
+
+	a, err := New(public.URL, nil)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	b, err := New(mirror.URL, core.DefaultClient())
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	for reg, want := range map[*Registry]string{a: "public", b: "mirror"} {
+		pkg, err := reg.FetchPackage(context.Background(), "left-pad")
+		if err != nil {
+			t.Fatalf("FetchPackage failed: %v", err)
+		}
+		if pkg.Description != want {
+			t.Errorf("description = %q, want %q", pkg.Description, want)
+		}
+	}
+
+	if _, err := New("registry.example.com", nil); !errors.Is(err, core.ErrInvalidURL) {
+		t.Errorf("expected ErrInvalidURL for a URL without a scheme, got %v", err)
+	}
+	if reg, err := New("", nil); err != nil || reg.URLs().Registry("left-pad", "") != "https://www.npmjs.com/package/left-pad" {
+		t.Errorf("expected the public registry by default, got %v", err)
+	}
+}
@@ -0,0 +1,213 @@
+// Package provenance compares who published and maintained each version of a
+// package to flag changes of control, a common sign of account takeover or a
+// package changing hands:
+//
+//	changes, err := provenance.CheckPURL(ctx, "pkg:npm/event-stream", nil)
+//	for _, c := range changes {
+//		if c.Suspicious() {
+//			fmt.Println(c.Version, c.Flags)
+//		}
+//	}
+//
+// It needs per-version publisher or maintainer data (Version.Publisher and
+// Version.Maintainers), which npm and crates.io provide. Other registries
+// yield no changes.
+package provenance
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/git-pkgs/registries"
+)
+
+// Flag describes one kind of change between consecutive versions.
+type Flag string
+
+const (
+	// OwnersReplaced means no maintainer of the previous version maintains
+	// this one.
+	OwnersReplaced Flag = "owners_replaced"
+	// EmailDomainChanged means an account kept its login but its email moved
+	// to a different domain, which happens when an expired domain is
+	// re-registered to take over the account.
+	EmailDomainChanged Flag = "email_domain_changed"
+	// NewPublisher means the version was published by an account that never
+	// published an earlier version.
+	NewPublisher Flag = "new_publisher"
+	// MaintainersChanged means maintainers were added or removed.
+	MaintainersChanged Flag = "maintainers_changed"
+)
+
+// Change is the difference in control between a version and the version
+// published before it.
+type Change struct {
+	Version     string
+	Previous    string
+	PublishedAt time.Time
+	Publisher   *registries.Maintainer
+	Added       []registries.Maintainer
+	Removed     []registries.Maintainer
+	Flags       []Flag
+}
+
+// Suspicious reports whether the change is one that usually warrants a
+// closer look: the whole owner set changing, or an email domain changing
+// under the same login. New publishers and added maintainers alone are
+// routine.
+func (c Change) Suspicious() bool {
+	for _, f := range c.Flags {
+		if f == OwnersReplaced || f == EmailDomainChanged {
+			return true
+		}
+	}
+	return false
+}
+
+// Has reports whether the change carries flag.
+func (c Change) Has(flag Flag) bool {
+	for _, f := range c.Flags {
+		if f == flag {
+			return true
+		}
+	}
+	return false
+}
+
+// Analyze walks versions in publish order and returns a Change for every
+// version whose control differs from the version before it. Versions without
+// publisher or maintainer data are skipped.
+func Analyze(versions []registries.Version) []Change {
+	ordered := make([]registries.Version, 0, len(versions))
+	for _, v := range versions {
+		if v.Publisher != nil || len(v.Maintainers) > 0 {
+			ordered = append(ordered, v)
+		}
+	}
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].PublishedAt.Before(ordered[j].PublishedAt)
+	})
+
+	var changes []Change
+	publishers := make(map[string]bool)
+	domains := make(map[string]string)
+
+	for i, v := range ordered {
+		if i == 0 {
+			record(v, publishers, domains)
+			continue
+		}
+		prev := ordered[i-1]
+		c := Change{
+			Version:     v.Number,
+			Previous:    prev.Number,
+			PublishedAt: v.PublishedAt,
+			Publisher:   v.Publisher,
+		}
+
+		if len(prev.Maintainers) > 0 && len(v.Maintainers) > 0 {
+			c.Added = difference(v.Maintainers, prev.Maintainers)
+			c.Removed = difference(prev.Maintainers, v.Maintainers)
+			if len(c.Added) > 0 || len(c.Removed) > 0 {
+				c.Flags = append(c.Flags, MaintainersChanged)
+			}
+			if len(difference(v.Maintainers, c.Added)) == 0 {
+				c.Flags = append(c.Flags, OwnersReplaced)
+			}
+		}
+
+		if v.Publisher != nil && !publishers[identity(*v.Publisher)] {
+			c.Flags = append(c.Flags, NewPublisher)
+		}
+
+		for _, m := range people(v) {
+			domain := emailDomain(m.Email)
+			if previous, ok := domains[identity(m)]; ok && domain != "" && previous != "" && previous != domain {
+				c.Flags = append(c.Flags, EmailDomainChanged)
+				break
+			}
+		}
+
+		record(v, publishers, domains)
+		if len(c.Flags) > 0 {
+			changes = append(changes, c)
+		}
+	}
+	return changes
+}
+
+// Check fetches every version of a package and analyzes it.
+func Check(ctx context.Context, reg registries.Registry, name string) ([]Change, error) {
+	versions, err := registries.FetchVersions(ctx, reg, name)
+	if err != nil {
+		return nil, err
+	}
+	return Analyze(versions), nil
+}
+
+// CheckPURL fetches every version of the package a PURL names and analyzes
+// it. Any version in the PURL is ignored.
+func CheckPURL(ctx context.Context, purl string, c *registries.Client) ([]Change, error) {
+	reg, name, _, err := registries.NewFromPURL(purl, c)
+	if err != nil {
+		return nil, err
+	}
+	return Check(ctx, reg, name)
+}
+
+// record notes the publisher and email domains of a version.
+func record(v registries.Version, publishers map[string]bool, domains map[string]string) {
+	if v.Publisher != nil {
+		publishers[identity(*v.Publisher)] = true
+	}
+	for _, m := range people(v) {
+		if d := emailDomain(m.Email); d != "" {
+			domains[identity(m)] = d
+		}
+	}
+}
+
+// people returns the maintainers of a version plus its publisher.
+func people(v registries.Version) []registries.Maintainer {
+	all := append([]registries.Maintainer(nil), v.Maintainers...)
+	if v.Publisher != nil {
+		all = append(all, *v.Publisher)
+	}
+	return all
+}
+
+// identity is the stable key of an account: its login, falling back to name
+// and then email for registries without logins.
+func identity(m registries.Maintainer) string {
+	for _, s := range []string{m.Login, m.Name, m.Email} {
+		if s != "" {
+			return strings.ToLower(s)
+		}
+	}
+	return ""
+}
+
+// difference returns the maintainers in a that are not in b.
+func difference(a, b []registries.Maintainer) []registries.Maintainer {
+	inB := make(map[string]bool, len(b))
+	for _, m := range b {
+		inB[identity(m)] = true
+	}
+	var out []registries.Maintainer
+	for _, m := range a {
+		if !inB[identity(m)] {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+func emailDomain(email string) string {
+	at := strings.LastIndexByte(email, '@')
+	if at < 0 {
+		return ""
+	}
+	return strings.ToLower(strings.TrimSpace(email[at+1:]))
+}
@@ -0,0 +1,93 @@
+package provenance
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/git-pkgs/registries"
+	_ "github.com/git-pkgs/registries/internal/npm"
+)
+
+func m(login, email string) registries.Maintainer {
+	return registries.Maintainer{Login: login, Email: email}
+}
+
+func TestAnalyze(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2018, 9, d, 0, 0, 0, 0, time.UTC) }
+	dominic := m("dominictarr", "dominic.tarr@gmail.com")
+	right9 := m("right9ctrl", "right9ctrl@outlook.com")
+
+	versions := []registries.Version{
+		// Deliberately out of order
+		{Number: "3.3.6", PublishedAt: day(9), Publisher: &right9, Maintainers: []registries.Maintainer{right9}},
+		{Number: "3.3.4", PublishedAt: day(1), Publisher: &dominic, Maintainers: []registries.Maintainer{dominic}},
+		{Number: "3.3.5", PublishedAt: day(5), Publisher: &right9, Maintainers: []registries.Maintainer{dominic, right9}},
+		{Number: "3.3.7", PublishedAt: day(10), Publisher: &right9, Maintainers: []registries.Maintainer{m("right9ctrl", "right9ctrl@evil.example")}},
+		{Number: "0.0.1"}, // no data
+	}
+
+	changes := Analyze(versions)
+	if len(changes) != 3 {
+		t.Fatalf("expected 3 changes, got %d: %+v", len(changes), changes)
+	}
+
+	added := changes[0]
+	if added.Version != "3.3.5" || added.Previous != "3.3.4" {
+		t.Errorf("unexpected first change: %+v", added)
+	}
+	if !added.Has(NewPublisher) || !added.Has(MaintainersChanged) || added.Suspicious() {
+		t.Errorf("adding a maintainer should be routine: %v", added.Flags)
+	}
+
+	removed := changes[1]
+	if !removed.Has(MaintainersChanged) || removed.Has(OwnersReplaced) || removed.Has(NewPublisher) {
+		t.Errorf("unexpected flags for 3.3.6: %v", removed.Flags)
+	}
+	if len(removed.Removed) != 1 || removed.Removed[0].Login != "dominictarr" {
+		t.Errorf("expected dominictarr removed, got %v", removed.Removed)
+	}
+
+	domain := changes[2]
+	if !domain.Has(EmailDomainChanged) || !domain.Suspicious() {
+		t.Errorf("expected email domain change for 3.3.7: %v", domain.Flags)
+	}
+}
+
+func TestAnalyzeOwnersReplaced(t *testing.T) {
+	a, b := m("alice", "alice@example.com"), m("mallory", "mallory@example.net")
+	changes := Analyze([]registries.Version{
+		{Number: "1.0.0", PublishedAt: time.Unix(1, 0), Maintainers: []registries.Maintainer{a}},
+		{Number: "1.0.1", PublishedAt: time.Unix(2, 0), Maintainers: []registries.Maintainer{b}},
+	})
+	if len(changes) != 1 || !changes[0].Has(OwnersReplaced) || !changes[0].Suspicious() {
+		t.Errorf("expected owners replaced, got %+v", changes)
+	}
+}
+
+func TestCheckPURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{
+			"_id": "pkg",
+			"versions": {
+				"1.0.0": {"version": "1.0.0", "_npmUser": {"name": "alice", "email": "alice@example.com"}, "maintainers": [{"name": "alice", "email": "alice@example.com"}]},
+				"1.0.1": {"version": "1.0.1", "_npmUser": {"name": "mallory", "email": "m@example.net"}, "maintainers": [{"name": "mallory", "email": "m@example.net"}]}
+			},
+			"time": {"1.0.0": "2020-01-01T00:00:00Z", "1.0.1": "2020-02-01T00:00:00Z"}
+		}`))
+	}))
+	defer server.Close()
+
+	changes, err := CheckPURL(context.Background(), "pkg:npm/pkg?repository_url="+server.URL, nil)
+	if err != nil {
+		t.Fatalf("CheckPURL failed: %v", err)
+	}
+	if len(changes) != 1 || changes[0].Version != "1.0.1" || !changes[0].Has(OwnersReplaced) || !changes[0].Has(NewPublisher) {
+		t.Errorf("unexpected changes: %+v", changes)
+	}
+	if changes[0].Publisher == nil || changes[0].Publisher.Login != "mallory" {
+		t.Errorf("unexpected publisher: %+v", changes[0].Publisher)
+	}
+}
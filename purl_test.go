@@ -0,0 +1,192 @@
+package registries_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/git-pkgs/registries"
+	_ "github.com/git-pkgs/registries/all"
+	"github.com/git-pkgs/registries/registrytest"
+)
+
+// purlName returns the name and registry to build a PURL from. CPAN PURLs
+// need the author and Julia PURLs the package UUID, which only the fetched
+// package knows.
+func purlName(reg registries.Registry, name string, pkg *registries.Package) (registries.Registry, string) {
+	switch reg.Ecosystem() {
+	case "cpan":
+		if author, ok := pkg.Metadata["author"].(string); ok {
+			return reg, author + "/" + name
+		}
+	case "julia":
+		if uuid, ok := pkg.Metadata["uuid"].(string); ok {
+			return reg.(registries.QualifiedRegistry).WithQualifiers(map[string]string{"uuid": uuid}), name
+		}
+	}
+	return reg, name
+}
+
+// TestPURLRoundTrip checks, for every ecosystem, that the PURL a registry
+// generates for a package parses, regenerates identically, and leads
+// NewFromPURL back to the same package.
+func TestPURLRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	for _, ecosystem := range registries.SupportedEcosystems() {
+		if ecosystem == "static" {
+			// Has no fixture as it makes no requests, see internal/static
+			continue
+		}
+		t.Run(ecosystem, func(t *testing.T) {
+			fixture, err := registrytest.Fixture(ecosystem)
+			if err != nil {
+				t.Fatal(err)
+			}
+			c := registrytest.ReplayClient(fixture)
+			reg, err := registries.New(ecosystem, "", c)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			name := fixture.Packages[0]
+			want, err := reg.FetchPackage(ctx, name)
+			if err != nil {
+				t.Fatalf("FetchPackage(%q) failed: %v", name, err)
+			}
+
+			reg, name = purlName(reg, name, want)
+			purl := reg.URLs().PURL(name, "1.0.0")
+			if _, err := registries.ParsePURL(purl); err != nil {
+				t.Fatalf("generated invalid PURL %q: %v", purl, err)
+			}
+
+			got, gotName, gotVersion, err := registries.NewFromPURL(purl, c)
+			if err != nil {
+				t.Fatalf("NewFromPURL(%q) failed: %v", purl, err)
+			}
+			if gotVersion != "1.0.0" {
+				t.Errorf("NewFromPURL(%q) version = %q", purl, gotVersion)
+			}
+			if again := got.URLs().PURL(gotName, gotVersion); again != purl {
+				t.Errorf("PURL %q regenerated as %q", purl, again)
+			}
+
+			pkg, err := got.FetchPackage(ctx, gotName)
+			if err != nil {
+				t.Fatalf("FetchPackage(%q) from %q failed: %v", gotName, purl, err)
+			}
+			if pkg.Name != want.Name || pkg.Namespace != want.Namespace {
+				t.Errorf("%q fetched %s/%s, want %s/%s", purl, pkg.Namespace, pkg.Name, want.Namespace, want.Name)
+			}
+		})
+	}
+}
+
+func TestPURLQualifiers(t *testing.T) {
+	tests := []struct {
+		purl     string
+		registry string
+		download string
+	}{
+		{
+			purl:     "pkg:conda/samtools@1.18?channel=bioconda",
+			registry: "https://anaconda.org/bioconda/samtools/1.18",
+		},
+		{
+			purl:     "pkg:maven/com.google.guava/guava@32.1.0?classifier=sources",
+			download: "https://repo1.maven.org/maven2/com/google/guava/guava/32.1.0/guava-32.1.0-sources.jar",
+		},
+		{
+			purl:     "pkg:gem/nokogiri@1.15.0?platform=x86_64-linux",
+			download: "https://rubygems.org/downloads/nokogiri-1.15.0-x86_64-linux.gem",
+		},
+		{
+			purl:     "pkg:cpan/ETHER/Moose@2.2201",
+			download: "https://cpan.metacpan.org/authors/id/E/ET/ETHER/Moose-2.2201.tar.gz",
+		},
+	}
+
+	for _, tt := range tests {
+		reg, name, version, err := registries.NewFromPURL(tt.purl, nil)
+		if err != nil {
+			t.Fatalf("NewFromPURL(%q) failed: %v", tt.purl, err)
+		}
+		urls := reg.URLs()
+		if got := urls.PURL(name, version); got != tt.purl {
+			t.Errorf("PURL %q regenerated as %q", tt.purl, got)
+		}
+		if tt.registry != "" && urls.Registry(name, version) != tt.registry {
+			t.Errorf("%q: expected registry URL %q, got %q", tt.purl, tt.registry, urls.Registry(name, version))
+		}
+		if tt.download != "" && urls.Download(name, version) != tt.download {
+			t.Errorf("%q: expected download URL %q, got %q", tt.purl, tt.download, urls.Download(name, version))
+		}
+	}
+}
+
+// hostTransport answers requests from a handler chosen by host, recording
+// which hosts were asked for which paths.
+type hostTransport struct {
+	mu       sync.Mutex
+	handlers map[string]http.Handler
+	seen     map[string][]string
+}
+
+func (t *hostTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mu.Lock()
+	t.seen[req.URL.Host] = append(t.seen[req.URL.Host], req.URL.Path)
+	h, ok := t.handlers[req.URL.Host]
+	t.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("unexpected host %s", req.URL.Host)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec.Result(), nil
+}
+
+func TestRepositoryURLQualifier(t *testing.T) {
+	public, err := registrytest.Fixture("npm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	private := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"name":"@internal/lib","description":"private","dist-tags":{"latest":"1.0.0"},"versions":{"1.0.0":{"name":"@internal/lib","version":"1.0.0"}},"time":{"1.0.0":"2024-01-01T00:00:00Z"}}`))
+	})
+	transport := &hostTransport{
+		handlers: map[string]http.Handler{
+			"registry.npmjs.org":   public.Handler(),
+			"npm.internal.example": private,
+		},
+		seen: make(map[string][]string),
+	}
+	c := registries.DefaultClient()
+	c.HTTPClient = &http.Client{Transport: transport}
+	c.MaxRetries = 0
+
+	publicPURL := "pkg:npm/" + public.Packages[0]
+	privatePURL := "pkg:npm/%40internal/lib@1.0.0?repository_url=npm.internal.example"
+
+	packages := registries.BulkFetchPackages(context.Background(), []string{publicPURL, privatePURL}, c)
+	if len(packages) != 2 {
+		t.Fatalf("expected 2 packages, got %d: %v (requests %v)", len(packages), packages, transport.seen)
+	}
+	if pkg := packages[privatePURL]; pkg.Description != "private" {
+		t.Errorf("expected the private package from npm.internal.example, got %+v", pkg)
+	}
+	if len(transport.seen["npm.internal.example"]) == 0 || len(transport.seen["registry.npmjs.org"]) == 0 {
+		t.Errorf("expected requests to both registries, got %v", transport.seen)
+	}
+
+	version, err := registries.FetchVersionFromPURL(context.Background(), privatePURL, c)
+	if err != nil {
+		t.Fatalf("FetchVersionFromPURL failed: %v", err)
+	}
+	if version.Number != "1.0.0" {
+		t.Errorf("unexpected version %q", version.Number)
+	}
+}
@@ -9,7 +9,7 @@
 //	import (
 //		"context"
 //		"github.com/git-pkgs/registries"
-//		_ "github.com/git-pkgs/registries/internal/cargo"
+//		_ "github.com/git-pkgs/registries/cargo"
 //	)
 //
 //	client := registries.DefaultClient()
@@ -30,14 +30,21 @@
 //		"github.com/git-pkgs/registries"
 //		_ "github.com/git-pkgs/registries/all"
 //	)
+//
+// Each ecosystem's package also constructs clients directly, without
+// looking the ecosystem up by name:
+//
+//	reg, err := cargo.New("", nil)
 package registries
 
 import (
 	"context"
+	"time"
 
 	"github.com/git-pkgs/purl"
 	"github.com/git-pkgs/registries/client"
 	"github.com/git-pkgs/registries/internal/core"
+	"github.com/git-pkgs/registries/names"
 )
 
 // Re-export types from internal/core
@@ -45,6 +52,21 @@ type (
 	// Registry is the interface implemented by all ecosystem registry clients.
 	Registry = core.Registry
 
+	// VersionFetcher is implemented by registries that list versions.
+	VersionFetcher = core.VersionFetcher
+
+	// DependencyFetcher is implemented by registries that record each
+	// version's dependencies.
+	DependencyFetcher = core.DependencyFetcher
+
+	// MaintainerFetcher is implemented by registries that say who maintains
+	// a package.
+	MaintainerFetcher = core.MaintainerFetcher
+
+	// QualifiedRegistry is implemented by registries configurable from PURL
+	// qualifiers such as a conda channel or a Maven classifier.
+	QualifiedRegistry = core.QualifiedRegistry
+
 	// Package represents metadata about a package from a registry.
 	Package = core.Package
 
@@ -57,11 +79,142 @@ type (
 	// Maintainer represents a package maintainer.
 	Maintainer = core.Maintainer
 
+	// Popularity holds a package's download and star counts.
+	Popularity = core.Popularity
+
+	// Integrity is a file digest, the parsed form of Version.Integrity.
+	Integrity = core.Integrity
+
 	// Scope indicates when a dependency is required.
 	Scope = core.Scope
 
+	// Relation marks dependency entries that aren't requirements.
+	Relation = core.Relation
+
 	// VersionStatus represents the status of a package version.
 	VersionStatus = core.VersionStatus
+
+	// Document is a long-form text such as a README.
+	Document = core.Document
+
+	// ReadmeFetcher is implemented by registries that serve READMEs.
+	ReadmeFetcher = core.ReadmeFetcher
+
+	// ChangelogFetcher is implemented by registries that serve or link to
+	// release notes.
+	ChangelogFetcher = core.ChangelogFetcher
+
+	// PackageStatus summarises whether a package is still maintained.
+	PackageStatus = core.PackageStatus
+
+	// PackageState is the package-level status of a package.
+	PackageState = core.PackageState
+
+	// StatusFetcher is implemented by registries with package-level status.
+	StatusFetcher = core.StatusFetcher
+
+	// ArtifactMatch is a package version identified from a file digest.
+	ArtifactMatch = core.ArtifactMatch
+
+	// ChecksumLookup is implemented by registries that can search by digest.
+	ChecksumLookup = core.ChecksumLookup
+
+	// Advisory is a security advisory published by a registry.
+	Advisory = core.Advisory
+
+	// AdvisoryFetcher is implemented by registries that serve advisories.
+	AdvisoryFetcher = core.AdvisoryFetcher
+
+	// DistTag is a release channel such as npm's "next" and its version.
+	DistTag = core.DistTag
+
+	// DistTagFetcher is implemented by registries with dist-tags.
+	DistTagFetcher = core.DistTagFetcher
+
+	// Artifact is one file of a package version, such as a sources jar.
+	Artifact = core.Artifact
+
+	// ArtifactFetcher is implemented by registries with several files per
+	// version.
+	ArtifactFetcher = core.ArtifactFetcher
+
+	// QualitySignals are test results and dependent counts for a version.
+	QualitySignals = core.QualitySignals
+
+	// TestSummary counts third-party test reports by outcome.
+	TestSummary = core.TestSummary
+
+	// TestCell is the test report counts for one platform and runtime.
+	TestCell = core.TestCell
+
+	// QualityFetcher is implemented by registries with quality signals.
+	QualityFetcher = core.QualityFetcher
+
+	// RegistryInfo describes a registry server.
+	RegistryInfo = core.RegistryInfo
+
+	// InfoFetcher is implemented by registries that describe themselves.
+	InfoFetcher = core.InfoFetcher
+
+	// Pinger is implemented by registries with a health-check request.
+	Pinger = core.Pinger
+
+	// Namespace is an owner of packages, such as an npm organization.
+	Namespace = core.Namespace
+
+	// NamespaceFetcher is implemented by registries with namespace accounts.
+	NamespaceFetcher = core.NamespaceFetcher
+
+	// NamespaceLister is implemented by registries that list an owner's
+	// packages.
+	NamespaceLister = core.NamespaceLister
+
+	// Provenance is an attestation of the trusted publisher, repository
+	// and workflow a published file came from.
+	Provenance = core.Provenance
+
+	// ProvenanceFetcher is implemented by registries that publish
+	// attestations for a version's files.
+	ProvenanceFetcher = core.ProvenanceFetcher
+
+	// PackageVersion names one version of a package.
+	PackageVersion = core.PackageVersion
+
+	// BulkDependencyFetcher is implemented by registries that can fetch the
+	// dependencies of many versions in fewer requests.
+	BulkDependencyFetcher = core.BulkDependencyFetcher
+
+	// PackageSnapshot is a package as it looked at a point in time.
+	PackageSnapshot = core.PackageSnapshot
+
+	// VersionDiff is what changed between two versions of a package.
+	VersionDiff = core.VersionDiff
+
+	// DependencyChange is a dependency added, removed or changed between
+	// two versions.
+	DependencyChange = core.DependencyChange
+
+	// DependencyChangeKind says how a dependency changed.
+	DependencyChangeKind = core.DependencyChangeKind
+
+	// Source is a registry response a package or version was built from,
+	// with when it was fetched.
+	Source = client.Source
+
+	// SourceLog collects the Sources of requests made with a context from
+	// RecordSources.
+	SourceLog = client.SourceLog
+
+	// HistoricalFetcher is implemented by registries that can reconstruct
+	// a package as of a past time.
+	HistoricalFetcher = core.HistoricalFetcher
+
+	// Pool reuses registries across lookups, sharing one client and its
+	// connections between them.
+	Pool = core.Pool
+
+	// PoolStats reports a Pool's size and how often it reused a registry.
+	PoolStats = core.PoolStats
 )
 
 // Re-export types from client
@@ -74,6 +227,16 @@ type (
 
 	// RateLimiter controls request pacing.
 	RateLimiter = client.RateLimiter
+
+	// RetryPolicy decides which failed requests are retried and how.
+	RetryPolicy = client.RetryPolicy
+
+	// Clock is the time source behind retry backoff and cache TTLs.
+	Clock = client.Clock
+
+	// ClientStats is a snapshot of a client's responses, retries and rate
+	// limits, from Client.ClientStats.
+	ClientStats = client.Stats
 )
 
 // Re-export constants
@@ -84,15 +247,72 @@ const (
 	Build       = core.Build
 	Optional    = core.Optional
 
+	RelationConflict = core.RelationConflict
+	RelationReplace  = core.RelationReplace
+	RelationProvide  = core.RelationProvide
+
 	StatusNone       = core.StatusNone
 	StatusYanked     = core.StatusYanked
 	StatusDeprecated = core.StatusDeprecated
 	StatusRetracted  = core.StatusRetracted
+
+	DependencyAdded   = core.DependencyAdded
+	DependencyRemoved = core.DependencyRemoved
+	DependencyChanged = core.DependencyChanged
+
+	Markdown         = core.Markdown
+	ReStructuredText = core.ReStructuredText
+	HTML             = core.HTML
+	PlainText        = core.PlainText
+
+	PackageActive     = core.PackageActive
+	PackageDeprecated = core.PackageDeprecated
+	PackageYanked     = core.PackageYanked
+	PackageRemoved    = core.PackageRemoved
+
+	NamespaceOrg       = core.NamespaceOrg
+	NamespaceUser      = core.NamespaceUser
+	NamespacePublisher = core.NamespacePublisher
+	NamespacePrefix    = core.NamespacePrefix
+
+	PublisherGitHub      = core.PublisherGitHub
+	PublisherGitLab      = core.PublisherGitLab
+	PublisherGoogle      = core.PublisherGoogle
+	PublisherActiveState = core.PublisherActiveState
+
+	MetadataInstallScripts = core.MetadataInstallScripts
+	MetadataNativeCode     = core.MetadataNativeCode
+
+	// SchemaVersion is the version of the JSON encoding of Package, Version,
+	// Dependency and Maintainer, written as schema_version.
+	SchemaVersion = core.SchemaVersion
 )
 
 // Re-export errors
 var (
 	ErrNotFound = client.ErrNotFound
+
+	// ErrCacheMiss is returned by offline clients for responses not in the cache.
+	ErrCacheMiss = client.ErrCacheMiss
+
+	// ErrNotSupported is returned when a registry doesn't offer the requested data.
+	ErrNotSupported = client.ErrNotSupported
+
+	// ErrResponseTooLarge is returned for responses over the client's size
+	// limits.
+	ErrResponseTooLarge = client.ErrResponseTooLarge
+
+	// ErrInvalidName is wrapped by errors for names that can't exist on a
+	// registry.
+	ErrInvalidName = names.ErrInvalidName
+
+	// ErrInvalidURL is wrapped by the error New returns for an unusable
+	// base URL.
+	ErrInvalidURL = core.ErrInvalidURL
+
+	// ErrUnsupportedSchema is wrapped by the error for JSON written with a
+	// newer SchemaVersion than this module reads.
+	ErrUnsupportedSchema = core.ErrUnsupportedSchema
 )
 
 // Error types
@@ -100,13 +320,33 @@ type (
 	HTTPError      = client.HTTPError
 	NotFoundError  = client.NotFoundError
 	RateLimitError = client.RateLimitError
+
+	// ResponseTooLargeError reports a response over the client's size limit.
+	ResponseTooLargeError = client.ResponseTooLargeError
+
+	// GraphQLError lists the errors in a response to Client.GraphQL.
+	GraphQLError = client.GraphQLError
+
+	// UnknownEcosystemError is returned by New for an ecosystem that isn't
+	// registered, with the supported names and the nearest matches.
+	UnknownEcosystemError = core.UnknownEcosystemError
+
+	// InvalidNameError explains why a name can't exist on a registry.
+	InvalidNameError = names.InvalidNameError
+
+	// BudgetExceededError is returned when an operation made of several
+	// requests, such as resolving a chain of Maven parent POMs, reaches its
+	// context's deadline partway. Partial holds the results gathered so far.
+	BudgetExceededError = core.BudgetExceededError
 )
 
-// New creates a new registry for the given ecosystem.
+// New creates a new registry for the given ecosystem, which may be an alias
+// such as "rubygems" or "packagist" (see CanonicalEcosystem).
 // If baseURL is empty, the default registry URL is used.
 // If client is nil, DefaultClient() is used.
 //
-// Supported ecosystems: "cargo", "npm", "gem", "pypi", "golang"
+// The ecosystem must be registered by importing its package, such as
+// registries/cargo, or registries/all for every ecosystem.
 func New(ecosystem string, baseURL string, c *Client) (Registry, error) {
 	return core.New(ecosystem, baseURL, c)
 }
@@ -133,12 +373,56 @@ var WithTimeout = client.WithTimeout
 // WithMaxRetries sets the maximum number of retries.
 var WithMaxRetries = client.WithMaxRetries
 
+// WithMaxIdleConnsPerHost sets how many idle connections are kept per host.
+var WithMaxIdleConnsPerHost = client.WithMaxIdleConnsPerHost
+
+// WithHTTP2 enables or disables HTTP/2.
+var WithHTTP2 = client.WithHTTP2
+
+// WithDialTimeout sets the timeout for establishing connections.
+var WithDialTimeout = client.WithDialTimeout
+
+// WithResponseHeaderTimeout sets how long to wait for a server to start
+// responding.
+var WithResponseHeaderTimeout = client.WithResponseHeaderTimeout
+
+// WithMaxBodySize limits the decoded size of metadata responses.
+var WithMaxBodySize = client.WithMaxBodySize
+
+// WithMaxArtifactSize limits the size of archives registries download to
+// read metadata from.
+var WithMaxArtifactSize = client.WithMaxArtifactSize
+
+// WithClock sets the clock that times retry backoff and cache TTLs.
+var WithClock = client.WithClock
+
+// WithDNSCache caches DNS lookups, refreshing them at the given interval.
+var WithDNSCache = client.WithDNSCache
+
+// WithRetryPolicy sets the retry policy, replacing the retry count and
+// backoff of the default client.
+var WithRetryPolicy = client.WithRetryPolicy
+
 // SupportedEcosystems returns all registered ecosystem types.
 // Note: ecosystems must be imported to be registered.
 func SupportedEcosystems() []string {
 	return core.SupportedEcosystems()
 }
 
+// CanonicalEcosystem returns the name an ecosystem is registered under,
+// resolving aliases: CanonicalEcosystem("rubygems") is "gem",
+// CanonicalEcosystem("packagist") is "composer" and
+// CanonicalEcosystem("go") is "golang".
+func CanonicalEcosystem(ecosystem string) string {
+	return core.CanonicalEcosystem(ecosystem)
+}
+
+// EcosystemAliases returns the aliases CanonicalEcosystem resolves, mapped
+// to the names they stand for.
+func EcosystemAliases() map[string]string {
+	return core.EcosystemAliases()
+}
+
 // BuildURLs returns a map of all non-empty URLs for a package.
 // Keys are "registry", "download", "docs", and "purl".
 func BuildURLs(urls URLBuilder, name, version string) map[string]string {
@@ -159,12 +443,50 @@ func ParsePURL(purlStr string) (*PURL, error) {
 	return purl.Parse(purlStr)
 }
 
+// ValidateName reports whether name could exist on an ecosystem's
+// registry, so bogus names fail before any request is made. The error is an
+// *InvalidNameError saying which rule the name breaks.
+func ValidateName(ecosystem, name string) error {
+	return core.ValidateName(ecosystem, name)
+}
+
+// ValidateURL reports whether baseURL is usable as a base URL for an
+// ecosystem's registry: an http or https URL, a Cargo index URL, or a
+// directory for static registries. The error wraps ErrInvalidURL.
+func ValidateURL(ecosystem, baseURL string) error {
+	return core.ValidateURL(ecosystem, baseURL)
+}
+
 // NewFromPURL creates a registry client from a PURL and returns the parsed components.
 // Returns the registry, full package name, and version (empty if not in PURL).
 func NewFromPURL(purl string, c *Client) (Registry, string, string, error) {
 	return core.NewFromPURL(purl, c)
 }
 
+// NewPool returns an empty Pool whose registries share c. A nil c is
+// replaced with a client tuned to keep more connections to each host open.
+func NewPool(c *Client) *Pool {
+	return core.NewPool(c)
+}
+
+// FetchVersions lists the versions of a package, or returns an error
+// wrapping ErrNotSupported if reg doesn't implement VersionFetcher.
+func FetchVersions(ctx context.Context, reg Registry, name string) ([]Version, error) {
+	return core.FetchVersions(ctx, reg, name)
+}
+
+// FetchDependencies returns the dependencies of a version, or an error
+// wrapping ErrNotSupported if reg doesn't implement DependencyFetcher.
+func FetchDependencies(ctx context.Context, reg Registry, name, version string) ([]Dependency, error) {
+	return core.FetchDependencies(ctx, reg, name, version)
+}
+
+// FetchMaintainers returns the maintainers of a package, or an error
+// wrapping ErrNotSupported if reg doesn't implement MaintainerFetcher.
+func FetchMaintainers(ctx context.Context, reg Registry, name string) ([]Maintainer, error) {
+	return core.FetchMaintainers(ctx, reg, name)
+}
+
 // FetchPackageFromPURL fetches package metadata using a PURL.
 func FetchPackageFromPURL(ctx context.Context, purl string, c *Client) (*Package, error) {
 	return core.FetchPackageFromPURL(ctx, purl, c)
@@ -198,6 +520,279 @@ func FetchLatestVersionFromPURL(ctx context.Context, purl string, c *Client) (*V
 	return core.FetchLatestVersionFromPURL(ctx, purl, c)
 }
 
+// FetchReadme returns the README of a package version, or of the latest
+// version if version is empty. Registries that don't serve READMEs return an
+// error wrapping ErrNotSupported.
+func FetchReadme(ctx context.Context, reg Registry, name, version string) (*Document, error) {
+	return core.FetchReadme(ctx, reg, name, version)
+}
+
+// FetchReadmeFromPURL returns the README for a PURL.
+func FetchReadmeFromPURL(ctx context.Context, purl string, c *Client) (*Document, error) {
+	return core.FetchReadmeFromPURL(ctx, purl, c)
+}
+
+// FetchChangelog returns the release notes of a package version, or of the
+// latest version if version is empty. Registries without a source of
+// changelogs return an error wrapping ErrNotSupported.
+func FetchChangelog(ctx context.Context, reg Registry, name, version string) (*Document, error) {
+	return core.FetchChangelog(ctx, reg, name, version)
+}
+
+// FetchChangelogFromPURL returns the release notes for a PURL, falling back
+// to the package's repository for registries without changelogs.
+func FetchChangelogFromPURL(ctx context.Context, purl string, c *Client) (*Document, error) {
+	return core.FetchChangelogFromPURL(ctx, purl, c)
+}
+
+// FetchPackageAt returns a package as it looked at time at: the versions
+// published by then and the latest version at the time. Supported for npm,
+// crates.io and the Go module proxy; other registries return an error
+// wrapping ErrNotSupported.
+func FetchPackageAt(ctx context.Context, reg Registry, name string, at time.Time) (*PackageSnapshot, error) {
+	return core.FetchPackageAt(ctx, reg, name, at)
+}
+
+// FetchPackageWithSources fetches a package and fills in its Sources: the
+// URLs it was built from, when each was fetched, and where known which
+// fields came from which, such as licenses inherited from a Maven parent
+// POM.
+func FetchPackageWithSources(ctx context.Context, reg Registry, name string) (*Package, error) {
+	return core.FetchPackageWithSources(ctx, reg, name)
+}
+
+// FetchVersionsWithSources lists versions and fills in each one's Sources.
+func FetchVersionsWithSources(ctx context.Context, reg Registry, name string) ([]Version, error) {
+	return core.FetchVersionsWithSources(ctx, reg, name)
+}
+
+// RecordSources returns a context that records the requests made with it,
+// for recording the sources of operations other than FetchPackage and
+// FetchVersions.
+func RecordSources(ctx context.Context) (context.Context, *SourceLog) {
+	return client.RecordSources(ctx)
+}
+
+// DocumentationURL returns where a package's documentation lives,
+// preferring a URL the package declares over the registry's guess from
+// URLBuilder.Documentation.
+func DocumentationURL(ctx context.Context, reg Registry, name, version string) (string, error) {
+	return core.DocumentationURL(ctx, reg, name, version)
+}
+
+// DocumentationURLFromPURL returns the documentation URL for a PURL.
+func DocumentationURLFromPURL(ctx context.Context, purl string, c *Client) (string, error) {
+	return core.DocumentationURLFromPURL(ctx, purl, c)
+}
+
+// FetchDistTags returns a package's dist-tags, the release channels that
+// decide what "latest" or "next" means. Registries without them return an
+// error wrapping ErrNotSupported.
+func FetchDistTags(ctx context.Context, reg Registry, name string) ([]DistTag, error) {
+	return core.FetchDistTags(ctx, reg, name)
+}
+
+// FetchArtifact returns a file of a package version picked by classifier
+// and extension, such as Maven's "sources" jar, with its published
+// checksum. Registries with one file per version return an error wrapping
+// ErrNotSupported.
+func FetchArtifact(ctx context.Context, reg Registry, name, version, classifier, extension string) (*Artifact, error) {
+	return core.FetchArtifact(ctx, reg, name, version, classifier, extension)
+}
+
+// FetchQualitySignals returns indicators of a version's stability, such as
+// CPAN Testers results and how many distributions depend on it. An empty
+// version means the latest. Registries without them return an error
+// wrapping ErrNotSupported.
+func FetchQualitySignals(ctx context.Context, reg Registry, name, version string) (*QualitySignals, error) {
+	return core.FetchQualitySignals(ctx, reg, name, version)
+}
+
+// FetchRegistryInfo returns what a registry server says about itself, such
+// as npm's registry document or crates.io's crate and download totals.
+// Registries without such an endpoint return an error wrapping
+// ErrNotSupported.
+func FetchRegistryInfo(ctx context.Context, reg Registry) (*RegistryInfo, error) {
+	return core.FetchRegistryInfo(ctx, reg)
+}
+
+// Ping checks that a registry is reachable and accepts the client's
+// credentials, bypassing the cache, so a long job can fail fast.
+func Ping(ctx context.Context, reg Registry) error {
+	return core.Ping(ctx, reg)
+}
+
+// InstallScripts returns what runs code when a version is installed, such
+// as npm's "postinstall" or a crate's "build.rs", as recorded in its
+// metadata by FetchVersions.
+func InstallScripts(v Version) []string {
+	return core.InstallScripts(v)
+}
+
+// HasInstallScripts reports whether installing a version runs code, as far
+// as its registry can tell.
+func HasInstallScripts(v Version) bool {
+	return core.HasInstallScripts(v)
+}
+
+// HasNativeCode reports whether a version compiles or ships native code, as
+// far as its registry can tell.
+func HasNativeCode(v Version) bool {
+	return core.HasNativeCode(v)
+}
+
+// IsPrerelease reports whether version is a pre-release under the
+// ecosystem's versioning rules, the check behind Package.LatestStableVersion.
+func IsPrerelease(ecosystem, version string) bool {
+	return core.IsPrerelease(ecosystem, version)
+}
+
+// CompareVersions orders two versions of an ecosystem, returning -1, 0 or
+// 1. Maven and NuGet use their own ordering rules; other ecosystems are
+// compared as SemVer-like numbers.
+func CompareVersions(ecosystem, a, b string) int {
+	return core.CompareVersions(ecosystem, a, b)
+}
+
+// FetchNamespace returns an owner of packages with its verification status
+// and, where the API makes them public, its members: an npm organization, a
+// NuGet ID prefix, a pub.dev publisher or a GitHub owner of Go modules.
+// Other registries return an error wrapping ErrNotSupported.
+func FetchNamespace(ctx context.Context, reg Registry, namespace string) (*Namespace, error) {
+	return core.FetchNamespace(ctx, reg, namespace)
+}
+
+// ListPackagesByNamespace returns the sorted names of every package an
+// owner publishes: an npm scope, a RubyGems owner, a crates.io user or team,
+// or a GitHub owner of Go modules. Other registries return an error
+// wrapping ErrNotSupported.
+func ListPackagesByNamespace(ctx context.Context, reg Registry, namespace string) ([]string, error) {
+	return core.ListPackagesByNamespace(ctx, reg, namespace)
+}
+
+// FetchProvenance returns the trusted publisher, repository and workflow
+// attested for each file of a version, or an error wrapping ErrNotSupported
+// if reg doesn't implement ProvenanceFetcher.
+func FetchProvenance(ctx context.Context, reg Registry, name, version string) ([]Provenance, error) {
+	return core.FetchProvenance(ctx, reg, name, version)
+}
+
+// FetchStatus returns the package-level status of a package: whether every
+// version is deprecated or yanked, or the package was removed.
+func FetchStatus(ctx context.Context, reg Registry, name string) (*PackageStatus, error) {
+	return core.FetchStatus(ctx, reg, name)
+}
+
+// FetchKnownStatus is FetchStatus for a package known to have been on the
+// registry, such as one in a lockfile: one the registry no longer has is
+// PackageRemoved rather than an error wrapping ErrNotFound.
+func FetchKnownStatus(ctx context.Context, reg Registry, name string) (*PackageStatus, error) {
+	return core.FetchKnownStatus(ctx, reg, name)
+}
+
+// FetchStatusFromPURL returns the package-level status for a PURL, and also
+// probes the source repository to report whether it is archived.
+func FetchStatusFromPURL(ctx context.Context, purl string, c *Client) (*PackageStatus, error) {
+	return core.FetchStatusFromPURL(ctx, purl, c)
+}
+
+// LookupByChecksum identifies the package versions that published a file
+// with the given digest, such as the SHA-1 of a JAR found on disk. Registries
+// that can't search by checksum return an error wrapping ErrNotSupported.
+func LookupByChecksum(ctx context.Context, reg Registry, digest string) ([]ArtifactMatch, error) {
+	return core.LookupByChecksum(ctx, reg, digest)
+}
+
+// ParseIntegrity parses an integrity string: an SRI value such as
+// Version.Integrity ("sha512-<base64>"), or a hex digest with an algorithm
+// prefix ("sha256-<hex>", "sha256:<hex>"). Of several space-separated
+// hashes, the strongest is returned.
+func ParseIntegrity(s string) (Integrity, error) {
+	return core.ParseIntegrity(s)
+}
+
+// HexIntegrity returns the SRI string for a hex digest, or "" if the digest
+// is malformed.
+func HexIntegrity(algorithm, digest string) string {
+	return core.HexIntegrity(algorithm, digest)
+}
+
+// ParseMultihash reads a multihash of an MD5, SHA-1 or SHA-2 digest.
+func ParseMultihash(b []byte) (Integrity, error) {
+	return core.ParseMultihash(b)
+}
+
+// FetchAdvisories returns the security advisories a registry publishes for
+// the given versions, keyed by package name. npm serves these for `npm
+// audit`; registries without advisories return an error wrapping
+// ErrNotSupported.
+func FetchAdvisories(ctx context.Context, reg Registry, versions map[string][]string) ([]Advisory, error) {
+	return core.FetchAdvisories(ctx, reg, versions)
+}
+
+// MetadataAs decodes a version's Metadata map into a typed schema from the
+// metadata package, such as metadata.NpmVersion. It reports false if the
+// version has no metadata or it doesn't fit T.
+func MetadataAs[T any](v Version) (T, bool) {
+	return core.MetadataAs[T](v)
+}
+
+// PackageMetadataAs decodes a package's Metadata map into a typed schema from
+// the metadata package, such as metadata.CargoPackage.
+func PackageMetadataAs[T any](p Package) (T, bool) {
+	return core.PackageMetadataAs[T](p)
+}
+
+// RepositoryArchived reports whether a GitHub, GitLab or Gitea repository is archived.
+func RepositoryArchived(ctx context.Context, c *Client, repoURL string) (bool, error) {
+	return core.RepositoryArchived(ctx, c, repoURL)
+}
+
+// SizeChange returns how many times larger next is than prev, comparing
+// unpacked sizes where both versions have one and download sizes
+// otherwise, or 0 if they have no size in common.
+func SizeChange(prev, next Version) float64 {
+	return core.SizeChange(prev, next)
+}
+
+// Diff fetches the versions of two PURLs of the same package name, such as
+// pkg:npm/lodash@4.17.20 and pkg:npm/lodash@4.17.21, and reports the
+// dependency, license, maintainer and size changes between them.
+func Diff(ctx context.Context, purlA, purlB string, c *Client) (*VersionDiff, error) {
+	return core.Diff(ctx, purlA, purlB, c)
+}
+
+// DiffVersions reports what changed between two versions already fetched,
+// given the dependencies of each.
+func DiffVersions(from, to Version, fromDeps, toDeps []Dependency) *VersionDiff {
+	return core.DiffVersions(from, to, fromDeps, toDeps)
+}
+
+// RepositoryChangelog returns the release notes of a version from a GitHub
+// or GitLab repository: its release's description, or its section of a
+// changelog file such as CHANGELOG.md.
+func RepositoryChangelog(ctx context.Context, c *Client, repoURL, version string) (*Document, error) {
+	return core.RepositoryChangelog(ctx, c, repoURL, version)
+}
+
+// ChangelogSection returns the part of a changelog under the heading for
+// version, or the whole changelog if no heading mentions it.
+func ChangelogSection(content, version string) string {
+	return core.ChangelogSection(content, version)
+}
+
+// NormalizeCategories maps registry categories (Package.Categories) and
+// keywords onto a shared cross-ecosystem taxonomy, such as "web", "cli" or
+// "cryptography". Unmapped terms are dropped.
+func NormalizeCategories(categories []string) []string {
+	return core.NormalizeCategories(categories)
+}
+
+// CategoryTaxonomy returns every category NormalizeCategories can produce.
+func CategoryTaxonomy() []string {
+	return core.CategoryTaxonomy()
+}
+
 // BulkFetchPackages fetches package metadata for multiple PURLs in parallel.
 // Individual fetch errors are silently ignored - those PURLs are omitted from results.
 // Returns a map of PURL to Package.
@@ -233,3 +828,16 @@ func BulkFetchLatestVersions(ctx context.Context, purls []string, c *Client) map
 func BulkFetchLatestVersionsWithConcurrency(ctx context.Context, purls []string, c *Client, concurrency int) map[string]*Version {
 	return core.BulkFetchLatestVersionsWithConcurrency(ctx, purls, c, concurrency)
 }
+
+// BulkFetchDependencies fetches dependencies for multiple versioned PURLs,
+// using a registry's batch endpoint or per-package document where it has
+// one instead of a request per PURL. PURLs without versions and failed
+// fetches are omitted. Returns a map of PURL to its dependencies.
+func BulkFetchDependencies(ctx context.Context, purls []string, c *Client) map[string][]Dependency {
+	return core.BulkFetchDependencies(ctx, purls, c)
+}
+
+// BulkFetchDependenciesWithConcurrency fetches dependencies with a custom concurrency limit.
+func BulkFetchDependenciesWithConcurrency(ctx context.Context, purls []string, c *Client, concurrency int) map[string][]Dependency {
+	return core.BulkFetchDependenciesWithConcurrency(ctx, purls, c, concurrency)
+}
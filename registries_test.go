@@ -3,10 +3,15 @@ package registries_test
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"sort"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/git-pkgs/registries"
 	_ "github.com/git-pkgs/registries/all"
@@ -15,7 +20,7 @@ import (
 func TestSupportedEcosystems(t *testing.T) {
 	ecosystems := registries.SupportedEcosystems()
 
-	expected := []string{"brew", "cargo", "clojars", "cocoapods", "composer", "conda", "cpan", "cran", "deno", "dub", "elm", "gem", "golang", "hackage", "haxelib", "hex", "julia", "luarocks", "maven", "nimble", "npm", "nuget", "pub", "pypi", "terraform"}
+	expected := []string{"arduino", "bitbucket", "brew", "buildpack", "cargo", "clojars", "cocoapods", "composer", "conda", "cpan", "cran", "deno", "drupal", "dub", "elm", "gem", "generic", "github", "github-release", "gitlab", "gittags", "golang", "hackage", "haxelib", "hex", "jsr", "julia", "luarocks", "maven", "nimble", "npm", "nuget", "platformio", "pub", "pypi", "racket", "static", "terraform", "vim", "wasm", "wordpress"}
 	sort.Strings(ecosystems)
 
 	if len(ecosystems) != len(expected) {
@@ -58,7 +63,22 @@ func TestNew(t *testing.T) {
 		{"nimble", false},
 		{"haxelib", false},
 		{"deno", false},
+		{"jsr", false},
+		{"buildpack", false},
+		{"wordpress", false},
+		{"drupal", false},
+		{"platformio", false},
+		{"arduino", false},
+		{"github-release", false},
+		{"gittags", false},
+		{"github", false},
+		{"gitlab", false},
+		{"bitbucket", false},
+		{"generic", false},
+		{"racket", false},
+		{"vim", false},
 		{"terraform", false},
+		{"wasm", false},
 		{"unknown", true},
 	}
 
@@ -72,6 +92,75 @@ func TestNew(t *testing.T) {
 	}
 }
 
+func TestCanonicalEcosystem(t *testing.T) {
+	tests := map[string]string{
+		"rubygems":  "gem",
+		"packagist": "composer",
+		"go":        "golang",
+		"Go":        "golang",
+		"crates.io": "cargo",
+		"homebrew":  "brew",
+		"npm":       "npm",
+		" PyPI ":    "pypi",
+		"unknown":   "unknown",
+	}
+	for name, want := range tests {
+		if got := registries.CanonicalEcosystem(name); got != want {
+			t.Errorf("CanonicalEcosystem(%q) = %q, want %q", name, got, want)
+		}
+	}
+
+	for alias, eco := range registries.EcosystemAliases() {
+		reg, err := registries.New(alias, "", nil)
+		if err != nil {
+			t.Errorf("New(%q) failed: %v", alias, err)
+			continue
+		}
+		if reg.Ecosystem() != eco && eco != "gittags" {
+			t.Errorf("New(%q).Ecosystem() = %q, want %q", alias, reg.Ecosystem(), eco)
+		}
+	}
+	if got := registries.DefaultURL("rubygems"); got != "https://rubygems.org" {
+		t.Errorf("DefaultURL(rubygems) = %q", got)
+	}
+
+	// The PURL spec names Homebrew's type "homebrew"
+	if _, name, _, err := registries.NewFromPURL("pkg:homebrew/wget@1.21.4", nil); err != nil || name != "wget" {
+		t.Errorf("NewFromPURL(pkg:homebrew/wget) = %q, %v", name, err)
+	}
+}
+
+func TestUnknownEcosystemError(t *testing.T) {
+	tests := map[string][]string{
+		"nmp":       {"npm"},
+		"pipy":      {"pypi"},
+		"crate":     {"cargo"},
+		"rubygem":   {"gem"},
+		"xyzzyplop": nil,
+	}
+	for name, want := range tests {
+		_, err := registries.New(name, "", nil)
+		var unknown *registries.UnknownEcosystemError
+		if !errors.As(err, &unknown) {
+			t.Fatalf("New(%q) err = %v, want UnknownEcosystemError", name, err)
+		}
+		if len(want) > 0 && (len(unknown.Suggestions) == 0 || unknown.Suggestions[0] != want[0]) {
+			t.Errorf("New(%q) suggestions = %v, want %v first", name, unknown.Suggestions, want)
+		}
+		if len(want) == 0 && len(unknown.Suggestions) != 0 {
+			t.Errorf("New(%q) suggestions = %v, want none", name, unknown.Suggestions)
+		}
+		if len(unknown.Supported) != len(registries.SupportedEcosystems()) || !sort.StringsAreSorted(unknown.Supported) {
+			t.Errorf("New(%q) supported = %v", name, unknown.Supported)
+		}
+	}
+
+	_, err := registries.New("nmp", "", nil)
+	if !strings.Contains(err.Error(), `unknown ecosystem: nmp (did you mean npm`) {
+		t.Errorf("err = %q", err)
+	}
+}
+
 func TestDefaultURL(t *testing.T) {
 	tests := []struct {
 		ecosystem string
@@ -101,7 +190,22 @@ func TestDefaultURL(t *testing.T) {
 		{"nimble", "https://nimble.directory"},
 		{"haxelib", "https://lib.haxe.org"},
 		{"deno", "https://apiland.deno.dev"},
+		{"jsr", "https://api.jsr.io"},
+		{"buildpack", "https://registry.buildpacks.io"},
+		{"wordpress", "https://api.wordpress.org"},
+		{"drupal", "https://www.drupal.org"},
+		{"platformio", "https://api.registry.platformio.org"},
+		{"arduino", "https://downloads.arduino.cc/libraries"},
+		{"github-release", "https://api.github.com"},
+		{"gittags", "https://github.com"},
+		{"github", "https://github.com"},
+		{"gitlab", "https://gitlab.com"},
+		{"bitbucket", "https://bitbucket.org"},
+		{"generic", "https://github.com"},
+		{"racket", "https://pkgs.racket-lang.org"},
+		{"vim", "https://vimawesome.com"},
 		{"terraform", "https://registry.terraform.io"},
+		{"wasm", "https://bytecodealliance.org"},
 	}
 
 	for _, tt := range tests {
@@ -205,3 +309,121 @@ func TestConstants(t *testing.T) {
 		t.Errorf("StatusYanked constant mismatch")
 	}
 }
+
+func TestBulkFetchDependencies(t *testing.T) {
+	var mu sync.Mutex
+	requests := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests[r.URL.Path]++
+		mu.Unlock()
+
+		switch r.URL.Path {
+		case "/api/v1/dependencies.json":
+			if r.URL.Query().Get("gems") != "missing,rack,rake" {
+				t.Errorf("unexpected gems: %q", r.URL.Query().Get("gems"))
+			}
+			_ = json.NewEncoder(w).Encode([]map[string]any{
+				{"name": "rake", "number": "13.0.0", "platform": "java", "dependencies": [][]string{{"jruby-openssl", ">= 0"}}},
+				{"name": "rake", "number": "13.0.0", "platform": "ruby", "dependencies": [][]string{{"minitest", "~> 5.0"}}},
+				{"name": "rake", "number": "12.0.0", "platform": "ruby", "dependencies": [][]string{}},
+				{"name": "rack", "number": "3.0.0", "platform": "ruby", "dependencies": [][]string{}},
+			})
+		case "/left-pad":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"name": "left-pad",
+				"versions": map[string]any{
+					"1.0.0": map[string]any{"version": "1.0.0"},
+					"1.3.0": map[string]any{"version": "1.3.0", "devDependencies": map[string]string{"tape": "*"}},
+				},
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	repo := "?repository_url=" + url.QueryEscape(server.URL)
+	purls := []string{
+		"pkg:gem/rake@13.0.0" + repo,
+		"pkg:gem/rack@3.0.0" + repo,
+		"pkg:gem/missing@1.0.0" + repo,
+		"pkg:npm/left-pad@1.0.0" + repo,
+		"pkg:npm/left-pad@1.3.0" + repo,
+		"pkg:npm/left-pad" + repo,
+	}
+	got := registries.BulkFetchDependencies(context.Background(), purls, registries.DefaultClient())
+
+	if len(got) != 4 {
+		t.Fatalf("got %d results, want 4: %v", len(got), got)
+	}
+	if deps := got[purls[0]]; len(deps) != 1 || deps[0].Name != "minitest" {
+		t.Errorf("rake dependencies = %v, want the ruby platform's", deps)
+	}
+	if deps, ok := got[purls[1]]; !ok || len(deps) != 0 {
+		t.Errorf("rack dependencies = %v, %v", deps, ok)
+	}
+	if deps := got[purls[4]]; len(deps) != 1 || deps[0].Scope != registries.Development {
+		t.Errorf("left-pad 1.3.0 dependencies = %v", deps)
+	}
+	if requests["/api/v1/dependencies.json"] != 1 || requests["/left-pad"] != 1 {
+		t.Errorf("expected one request per batch, got %v", requests)
+	}
+}
+
+func TestBulkFetchDependenciesFallback(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v2/rubygems/rake/versions/13.0.0.json":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"dependencies": map[string]any{
+					"runtime":     []map[string]string{{"name": "minitest", "requirements": "~> 5.0"}},
+					"development": []map[string]string{},
+				},
+			})
+		default:
+			// The dependency API has been turned off
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	purl := "pkg:gem/rake@13.0.0?repository_url=" + url.QueryEscape(server.URL)
+	got := registries.BulkFetchDependencies(context.Background(), []string{purl}, registries.DefaultClient())
+	if deps := got[purl]; len(deps) != 1 || deps[0].Name != "minitest" {
+		t.Errorf("dependencies = %v, want those from the per-version API", deps)
+	}
+}
+
+func TestFetchPackageWithSources(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"name":"left-pad","dist-tags":{"latest":"1.3.0"},"versions":{"1.3.0":{}},"time":{"1.3.0":"2018-04-09T01:21:02.000Z"}}`))
+	}))
+	defer server.Close()
+
+	reg, err := registries.New("npm", server.URL, registries.DefaultClient())
+	if err != nil {
+		t.Fatal(err)
+	}
+	before := time.Now()
+
+	pkg, err := registries.FetchPackageWithSources(context.Background(), reg, "left-pad")
+	if err != nil {
+		t.Fatalf("FetchPackageWithSources failed: %v", err)
+	}
+	if len(pkg.Sources) != 1 || pkg.Sources[0].URL != server.URL+"/left-pad" || pkg.Sources[0].FetchedAt.Before(before) {
+		t.Errorf("unexpected sources: %+v", pkg.Sources)
+	}
+
+	versions, err := registries.FetchVersionsWithSources(context.Background(), reg, "left-pad")
+	if err != nil {
+		t.Fatalf("FetchVersionsWithSources failed: %v", err)
+	}
+	if len(versions) != 1 || len(versions[0].Sources) != 1 {
+		t.Errorf("unexpected version sources: %+v", versions)
+	}
+
+	if pkg, _ := reg.FetchPackage(context.Background(), "left-pad"); pkg.Sources != nil {
+		t.Error("expected no sources without recording")
+	}
+}
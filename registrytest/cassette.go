@@ -0,0 +1,180 @@
+package registrytest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Interaction is one recorded request and its response.
+type Interaction struct {
+	Method      string `json:"method"`
+	Path        string `json:"path"` // path and query, e.g. /api/v1/crates/serde
+	Status      int    `json:"status"`
+	ContentType string `json:"content_type,omitempty"`
+	Body        string `json:"body"`
+}
+
+// Cassette is a set of recorded interactions that can be replayed.
+// Requests are matched on method, path and query; the host is ignored so
+// one cassette can stand in for a registry spread over several hosts.
+type Cassette struct {
+	Ecosystem    string        `json:"ecosystem,omitempty"`
+	Packages     []string      `json:"packages,omitempty"` // package names the cassette covers
+	Interactions []Interaction `json:"interactions"`
+
+	mu sync.Mutex
+}
+
+// LoadCassette reads a cassette saved with Save or written by hand.
+func LoadCassette(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var c Cassette
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// Save writes the cassette as indented JSON.
+func (c *Cassette) Save(path string) error {
+	c.mu.Lock()
+	data, err := json.MarshalIndent(c, "", "  ")
+	c.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// Add appends an interaction, replacing any earlier one for the same request.
+func (c *Cassette) Add(i Interaction) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for n, existing := range c.Interactions {
+		if existing.Method == i.Method && existing.Path == i.Path {
+			c.Interactions[n] = i
+			return
+		}
+	}
+	c.Interactions = append(c.Interactions, i)
+}
+
+// find matches a request exactly, or else by the longest recorded path that
+// the request ends with. The suffix match lets a cassette recorded against a
+// base URL such as https://api.nuget.org/v3 replay against a bare server.
+func (c *Cassette) find(method, path string) (Interaction, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var best Interaction
+	found := false
+	for _, i := range c.Interactions {
+		if i.Method != method {
+			continue
+		}
+		if i.Path == path {
+			return i, true
+		}
+		if strings.HasSuffix(path, i.Path) && strings.HasPrefix(i.Path, "/") && len(i.Path) > len(best.Path) {
+			best, found = i, true
+		}
+	}
+	return best, found
+}
+
+// Handler serves the cassette's responses. HEAD requests are answered from
+// the matching GET. Unrecorded requests get a 404, which registry clients
+// report as NotFoundError.
+func (c *Cassette) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := r.URL.RequestURI()
+		i, ok := c.find(r.Method, path)
+		if !ok && r.Method == http.MethodHead {
+			i, ok = c.find(http.MethodGet, path)
+		}
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		if i.ContentType != "" {
+			w.Header().Set("Content-Type", i.ContentType)
+		}
+		w.WriteHeader(i.Status)
+		if r.Method != http.MethodHead {
+			_, _ = io.WriteString(w, i.Body)
+		}
+	})
+}
+
+// Transport returns a RoundTripper that answers every request from the
+// cassette without opening a connection, whatever host it is addressed to.
+func (c *Cassette) Transport() http.RoundTripper {
+	return replayTransport{handler: c.Handler()}
+}
+
+type replayTransport struct {
+	handler http.Handler
+}
+
+func (t replayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	rec := httptest.NewRecorder()
+	t.handler.ServeHTTP(rec, req)
+	resp := rec.Result()
+	resp.Request = req
+	return resp, nil
+}
+
+// Recorder is a RoundTripper that passes requests to another transport and
+// records every response into a Cassette. Point a client at a real registry
+// through a Recorder once, Save the cassette, and replay it in tests.
+type Recorder struct {
+	next     http.RoundTripper
+	cassette *Cassette
+}
+
+// NewRecorder records requests sent through next, or
+// http.DefaultTransport if next is nil.
+func NewRecorder(next http.RoundTripper) *Recorder {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &Recorder{next: next, cassette: &Cassette{}}
+}
+
+// Cassette returns the interactions recorded so far.
+func (r *Recorder) Cassette() *Cassette {
+	return r.cassette
+}
+
+// RoundTrip implements http.RoundTripper.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := r.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	r.cassette.Add(Interaction{
+		Method:      req.Method,
+		Path:        req.URL.RequestURI(),
+		Status:      resp.StatusCode,
+		ContentType: resp.Header.Get("Content-Type"),
+		Body:        string(body),
+	})
+	return resp, nil
+}
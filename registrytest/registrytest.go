@@ -0,0 +1,98 @@
+// Package registrytest provides fake registries for testing code built on
+// this module, without network access or hand-written fixtures.
+//
+// Every supported ecosystem has a bundled cassette of realistic responses
+// covering a few well-known packages (see Fixture and Cassette.Packages).
+// Use NewServer when you control the registry base URL, or NewClient to
+// route all requests, including to secondary hosts such as Maven Central
+// search, through the fixtures:
+//
+//	func TestScanner(t *testing.T) {
+//		c := registrytest.NewClient(t, "npm")
+//		pkg, err := registries.FetchPackageFromPURL(ctx, "pkg:npm/lodash", c)
+//		...
+//	}
+//
+// Record your own cassettes against a live registry with Recorder.
+package registrytest
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/git-pkgs/registries/client"
+)
+
+//go:embed fixtures/*.json
+var fixtures embed.FS
+
+// Ecosystems returns the ecosystems with a bundled fixture, sorted.
+func Ecosystems() []string {
+	entries, _ := fixtures.ReadDir("fixtures")
+	ecosystems := make([]string, 0, len(entries))
+	for _, e := range entries {
+		ecosystems = append(ecosystems, strings.TrimSuffix(e.Name(), ".json"))
+	}
+	sort.Strings(ecosystems)
+	return ecosystems
+}
+
+// Fixture returns a fresh copy of the bundled cassette for an ecosystem.
+// Callers may Add interactions to it without affecting other tests.
+func Fixture(ecosystem string) (*Cassette, error) {
+	data, err := fixtures.ReadFile("fixtures/" + ecosystem + ".json")
+	if err != nil {
+		return nil, fmt.Errorf("registrytest: no fixture for ecosystem %q", ecosystem)
+	}
+	var c Cassette
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// NewServer starts a server replaying the bundled fixture for ecosystem.
+// Pass its URL as the registry base URL. It is closed when the test ends.
+func NewServer(t testing.TB, ecosystem string) *httptest.Server {
+	t.Helper()
+	return Serve(t, mustFixture(t, ecosystem))
+}
+
+// Serve starts a server replaying c. It is closed when the test ends.
+func Serve(t testing.TB, c *Cassette) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(c.Handler())
+	t.Cleanup(server.Close)
+	return server
+}
+
+// NewClient returns a client that answers every request from the bundled
+// fixture for ecosystem, so registries can be used with their default URLs.
+// Retries are disabled so unrecorded requests fail fast.
+func NewClient(t testing.TB, ecosystem string) *client.Client {
+	t.Helper()
+	return ReplayClient(mustFixture(t, ecosystem))
+}
+
+// ReplayClient returns a client that answers every request from c.
+func ReplayClient(c *Cassette) *client.Client {
+	rc := client.DefaultClient()
+	rc.HTTPClient = &http.Client{Transport: c.Transport()}
+	rc.MaxRetries = 0
+	return rc
+}
+
+func mustFixture(t testing.TB, ecosystem string) *Cassette {
+	t.Helper()
+	c, err := Fixture(ecosystem)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
@@ -0,0 +1,109 @@
+package registrytest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/git-pkgs/registries"
+	_ "github.com/git-pkgs/registries/all"
+)
+
+func TestFixturesCoverEveryEcosystem(t *testing.T) {
+	for _, ecosystem := range registries.SupportedEcosystems() {
+		if ecosystem == "static" {
+			// Reads files rather than making requests
+			continue
+		}
+		if _, err := Fixture(ecosystem); err != nil {
+			t.Errorf("missing fixture: %v", err)
+		}
+	}
+}
+
+func TestNewClient(t *testing.T) {
+	for _, ecosystem := range Ecosystems() {
+		t.Run(ecosystem, func(t *testing.T) {
+			fixture := mustFixture(t, ecosystem)
+			if len(fixture.Packages) == 0 {
+				t.Fatal("fixture lists no packages")
+			}
+
+			reg, err := registries.New(ecosystem, "", NewClient(t, ecosystem))
+			if err != nil {
+				t.Fatal(err)
+			}
+			pkg, err := reg.FetchPackage(context.Background(), fixture.Packages[0])
+			if err != nil {
+				t.Fatalf("FetchPackage(%q) failed: %v", fixture.Packages[0], err)
+			}
+			if pkg.Name == "" {
+				t.Errorf("FetchPackage(%q) returned an empty name", fixture.Packages[0])
+			}
+		})
+	}
+}
+
+func TestNewServer(t *testing.T) {
+	server := NewServer(t, "cargo")
+
+	reg, err := registries.New("cargo", server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg, err := reg.FetchPackage(context.Background(), "serde")
+	if err != nil {
+		t.Fatalf("FetchPackage failed: %v", err)
+	}
+	if pkg.Name != "serde" {
+		t.Errorf("expected serde, got %q", pkg.Name)
+	}
+
+	_, err = reg.FetchPackage(context.Background(), "not-recorded")
+	if !errors.Is(err, registries.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for an unrecorded package, got %v", err)
+	}
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"name":"left-pad","dist-tags":{"latest":"1.3.0"},"versions":{}}`))
+	}))
+	defer upstream.Close()
+
+	recorder := NewRecorder(nil)
+	c := registries.DefaultClient()
+	c.HTTPClient = &http.Client{Transport: recorder}
+
+	reg, _ := registries.New("npm", upstream.URL, c)
+	if _, err := reg.FetchPackage(context.Background(), "left-pad"); err != nil {
+		t.Fatalf("recording FetchPackage failed: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "npm.json")
+	if err := recorder.Cassette().Save(path); err != nil {
+		t.Fatal(err)
+	}
+	upstream.Close()
+
+	cassette, err := LoadCassette(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cassette.Interactions) != 1 || cassette.Interactions[0].Path != "/left-pad" {
+		t.Fatalf("unexpected interactions: %+v", cassette.Interactions)
+	}
+
+	replay, _ := registries.New("npm", "", ReplayClient(cassette))
+	pkg, err := replay.FetchPackage(context.Background(), "left-pad")
+	if err != nil {
+		t.Fatalf("replayed FetchPackage failed: %v", err)
+	}
+	if pkg.LatestVersion != "1.3.0" {
+		t.Errorf("unexpected latest version %q", pkg.LatestVersion)
+	}
+}
@@ -0,0 +1,178 @@
+// Package repology looks up which distributions and package repositories
+// carry a project using the Repology API (https://repology.org/api).
+//
+// Repology asks API users to keep to roughly one request per second, so
+// callers doing bulk lookups should configure a RateLimiter on the client.
+package repology
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/git-pkgs/purl"
+	"github.com/git-pkgs/registries/client"
+)
+
+// DefaultURL is the public Repology instance.
+const DefaultURL = "https://repology.org"
+
+const ecosystem = "repology"
+
+// Client queries the Repology API.
+type Client struct {
+	baseURL string
+	client  *client.Client
+}
+
+// New creates a Repology client. If baseURL is empty, DefaultURL is used.
+// If c is nil, client.DefaultClient() is used.
+func New(baseURL string, c *client.Client) *Client {
+	if baseURL == "" {
+		baseURL = DefaultURL
+	}
+	if c == nil {
+		c = client.DefaultClient()
+	}
+	return &Client{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client:  c,
+	}
+}
+
+// Package is one repository's packaging of a project.
+type Package struct {
+	Repo        string   `json:"repo"`
+	Subrepo     string   `json:"subrepo,omitempty"`
+	SrcName     string   `json:"srcname,omitempty"`
+	BinName     string   `json:"binname,omitempty"`
+	VisibleName string   `json:"visiblename,omitempty"`
+	Version     string   `json:"version"`
+	OrigVersion string   `json:"origversion,omitempty"`
+	Status      string   `json:"status,omitempty"` // newest, outdated, legacy, devel, unique, ...
+	Summary     string   `json:"summary,omitempty"`
+	Licenses    []string `json:"licenses,omitempty"`
+	Maintainers []string `json:"maintainers,omitempty"`
+	Categories  []string `json:"categories,omitempty"`
+}
+
+// Project is the set of packages Repology has grouped under one project name.
+type Project struct {
+	Name     string
+	Packages []Package
+}
+
+// Repos returns the distinct repositories that carry the project, sorted.
+func (p *Project) Repos() []string {
+	seen := make(map[string]bool)
+	var repos []string
+	for _, pkg := range p.Packages {
+		if !seen[pkg.Repo] {
+			seen[pkg.Repo] = true
+			repos = append(repos, pkg.Repo)
+		}
+	}
+	sort.Strings(repos)
+	return repos
+}
+
+// Versions returns the versions packaged by each repository. A repository
+// can carry several versions, e.g. across stable and testing subrepos.
+func (p *Project) Versions() map[string][]string {
+	versions := make(map[string][]string)
+	for _, pkg := range p.Packages {
+		existing := versions[pkg.Repo]
+		found := false
+		for _, v := range existing {
+			if v == pkg.Version {
+				found = true
+				break
+			}
+		}
+		if !found {
+			versions[pkg.Repo] = append(existing, pkg.Version)
+		}
+	}
+	return versions
+}
+
+// NewestVersion returns the version Repology considers the newest stable
+// release, or an empty string if no package has "newest" status.
+func (p *Project) NewestVersion() string {
+	for _, pkg := range p.Packages {
+		if pkg.Status == "newest" {
+			return pkg.Version
+		}
+	}
+	return ""
+}
+
+// FetchProject returns every package Repology knows for a project name.
+func (c *Client) FetchProject(ctx context.Context, name string) (*Project, error) {
+	apiURL := fmt.Sprintf("%s/api/v1/project/%s", c.baseURL, url.PathEscape(name))
+
+	var pkgs []Package
+	if err := c.client.GetJSON(ctx, apiURL, &pkgs); err != nil {
+		if httpErr, ok := err.(*client.HTTPError); ok && httpErr.IsNotFound() {
+			return nil, &client.NotFoundError{Ecosystem: ecosystem, Name: name}
+		}
+		return nil, err
+	}
+
+	// Repology answers unknown projects with an empty list rather than a 404
+	if len(pkgs) == 0 {
+		return nil, &client.NotFoundError{Ecosystem: ecosystem, Name: name}
+	}
+
+	return &Project{Name: name, Packages: pkgs}, nil
+}
+
+// FetchProjectFromPURL looks up the Repology project for a Package URL.
+func (c *Client) FetchProjectFromPURL(ctx context.Context, purlStr string) (*Project, error) {
+	name, err := ProjectName(purlStr)
+	if err != nil {
+		return nil, err
+	}
+	return c.FetchProject(ctx, name)
+}
+
+// projectPrefixes maps PURL types to the prefix Repology uses for language
+// module projects, so that e.g. PyPI "requests" becomes "python:requests".
+var projectPrefixes = map[string]string{
+	"cargo":    "rust",
+	"cpan":     "perl",
+	"cran":     "r",
+	"gem":      "ruby",
+	"hackage":  "haskell",
+	"luarocks": "lua",
+	"npm":      "node",
+	"pypi":     "python",
+}
+
+// ProjectName converts a Package URL to the Repology project name that
+// usually corresponds to it. Repology's naming rules are curated by hand, so
+// this is a best-effort mapping: ecosystem prefixes are applied for language
+// modules and the name is lowercased.
+func ProjectName(purlStr string) (string, error) {
+	p, err := purl.Parse(purlStr)
+	if err != nil {
+		return "", err
+	}
+
+	name := strings.ToLower(p.Name)
+	switch p.Type {
+	case "pypi":
+		name = strings.NewReplacer("_", "-", ".", "-").Replace(name)
+	case "npm":
+		if p.Namespace != "" {
+			name = strings.TrimPrefix(strings.ToLower(p.Namespace), "@") + "-" + name
+		}
+	}
+
+	if prefix, ok := projectPrefixes[p.Type]; ok {
+		return prefix + ":" + name, nil
+	}
+	return name, nil
+}
@@ -0,0 +1,97 @@
+package repology
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/git-pkgs/registries/client"
+)
+
+const sampleProject = `[
+  {"repo": "debian_12", "srcname": "python-requests", "binname": "python3-requests", "visiblename": "python-requests", "version": "2.28.1", "origversion": "2.28.1+dfsg-1", "status": "outdated", "licenses": ["Apache-2.0"]},
+  {"repo": "debian_unstable", "srcname": "python-requests", "binname": "python3-requests", "version": "2.32.3", "status": "newest"},
+  {"repo": "alpine_edge", "subrepo": "main", "binname": "py3-requests", "version": "2.32.3", "status": "newest", "maintainers": ["someone@alpinelinux.org"]},
+  {"repo": "alpine_edge", "subrepo": "community", "binname": "py3-requests", "version": "2.32.3", "status": "newest"}
+]`
+
+func TestFetchProject(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/project/python:requests" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+			w.WriteHeader(404)
+			return
+		}
+		_, _ = w.Write([]byte(sampleProject))
+	}))
+	defer server.Close()
+
+	c := New(server.URL, client.DefaultClient())
+	project, err := c.FetchProjectFromPURL(context.Background(), "pkg:pypi/requests")
+	if err != nil {
+		t.Fatalf("FetchProjectFromPURL failed: %v", err)
+	}
+
+	if len(project.Packages) != 4 {
+		t.Fatalf("expected 4 packages, got %d", len(project.Packages))
+	}
+	if project.Packages[0].OrigVersion != "2.28.1+dfsg-1" {
+		t.Errorf("unexpected origversion %q", project.Packages[0].OrigVersion)
+	}
+
+	repos := project.Repos()
+	if !reflect.DeepEqual(repos, []string{"alpine_edge", "debian_12", "debian_unstable"}) {
+		t.Errorf("unexpected repos: %v", repos)
+	}
+
+	versions := project.Versions()
+	if !reflect.DeepEqual(versions["alpine_edge"], []string{"2.32.3"}) {
+		t.Errorf("expected alpine_edge versions to be deduplicated, got %v", versions["alpine_edge"])
+	}
+
+	if v := project.NewestVersion(); v != "2.32.3" {
+		t.Errorf("expected newest version 2.32.3, got %q", v)
+	}
+}
+
+func TestFetchProjectNotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	c := New(server.URL, client.DefaultClient())
+	_, err := c.FetchProject(context.Background(), "does-not-exist")
+	if _, ok := err.(*client.NotFoundError); !ok {
+		t.Errorf("expected NotFoundError, got %T", err)
+	}
+}
+
+func TestProjectName(t *testing.T) {
+	tests := []struct {
+		purl string
+		want string
+	}{
+		{"pkg:pypi/Django", "python:django"},
+		{"pkg:pypi/zope.interface", "python:zope-interface"},
+		{"pkg:cargo/serde", "rust:serde"},
+		{"pkg:gem/rails@7.0.0", "ruby:rails"},
+		{"pkg:npm/%40babel/core", "node:babel-core"},
+		{"pkg:cran/ggplot2@3.4.4", "r:ggplot2"},
+		{"pkg:golang/github.com/spf13/cobra", "cobra"},
+		{"pkg:brew/curl", "curl"},
+	}
+
+	for _, tt := range tests {
+		got, err := ProjectName(tt.purl)
+		if err != nil {
+			t.Errorf("ProjectName(%q) error: %v", tt.purl, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ProjectName(%q) = %q, want %q", tt.purl, got, tt.want)
+		}
+	}
+}
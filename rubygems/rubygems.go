@@ -0,0 +1,30 @@
+// Package rubygems constructs clients for RubyGems.
+//
+//	reg, err := rubygems.New("", nil) // https://rubygems.org
+//	pkg, err := reg.FetchPackage(ctx, name)
+package rubygems
+
+import (
+	"github.com/git-pkgs/registries/client"
+	"github.com/git-pkgs/registries/internal/core"
+	impl "github.com/git-pkgs/registries/internal/rubygems"
+)
+
+// DefaultURL is the registry New uses when given no base URL.
+const DefaultURL = impl.DefaultURL
+
+// Registry is the client New returns. It implements registries.Registry and
+// the optional interfaces the ecosystem supports.
+type Registry = impl.Registry
+
+// VersionTotals counts a gem's versions and downloads, as returned by
+// Registry.FetchVersionTotals.
+type VersionTotals = impl.VersionTotals
+
+// New returns a client for the registry at baseURL, or DefaultURL if
+// baseURL is empty. A nil client uses client.DefaultClient. A baseURL that
+// fails registries.ValidateURL returns an error wrapping
+// registries.ErrInvalidURL.
+func New(baseURL string, c *client.Client) (*Registry, error) {
+	return core.Construct("gem", baseURL, c, impl.New)
+}
@@ -0,0 +1,28 @@
+// Package static constructs clients that serve packages from a directory
+// of JSON or TOML files. See the README for the file format.
+//
+//	reg, err := static.New("testdata/packages", nil)
+//	pkg, err := reg.FetchPackage(ctx, name)
+package static
+
+import (
+	"github.com/git-pkgs/registries/client"
+	"github.com/git-pkgs/registries/internal/core"
+	impl "github.com/git-pkgs/registries/internal/static"
+)
+
+// DefaultURL is the directory New uses when given no base URL, the current
+// directory.
+const DefaultURL = impl.DefaultURL
+
+// Registry is the client New returns. It implements registries.Registry and
+// the optional interfaces the ecosystem supports.
+type Registry = impl.Registry
+
+// New returns a client for the directory baseURL, a path or file:// URL,
+// or DefaultURL if baseURL is empty. The client is accepted for symmetry
+// with the other ecosystems and unused. Other URLs return an error
+// wrapping registries.ErrInvalidURL.
+func New(baseURL string, c *client.Client) (*Registry, error) {
+	return core.Construct("static", baseURL, c, impl.New)
+}
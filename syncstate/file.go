@@ -0,0 +1,121 @@
+package syncstate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// FileStore is a Store persisted as a JSON object of cursors in a single
+// file, rewritten on every change through a temporary file and rename so
+// a crash never leaves it half written. It suits one process syncing a
+// handful of streams; use SQLStore to share state between processes.
+type FileStore struct {
+	path string
+
+	mu      sync.Mutex
+	cursors map[string]Cursor
+}
+
+// NewFileStore returns a store kept in the file at path, reading the
+// cursors already there. The file and its directory are created on the
+// first Put.
+func NewFileStore(path string) (*FileStore, error) {
+	f := &FileStore{path: path, cursors: make(map[string]Cursor)}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return f, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &f.cursors); err != nil {
+		return nil, fmt.Errorf("syncstate: reading %s: %w", path, err)
+	}
+	if f.cursors == nil {
+		f.cursors = make(map[string]Cursor)
+	}
+	return f, nil
+}
+
+// Get implements Store.
+func (f *FileStore) Get(ctx context.Context, key string) (*Cursor, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	c, ok := f.cursors[key]
+	if !ok {
+		return nil, nil
+	}
+	return &c, nil
+}
+
+// Put implements Store.
+func (f *FileStore) Put(ctx context.Context, key string, c Cursor) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	previous, existed := f.cursors[key]
+	f.cursors[key] = c
+	if err := f.save(); err != nil {
+		if existed {
+			f.cursors[key] = previous
+		} else {
+			delete(f.cursors, key)
+		}
+		return err
+	}
+	return nil
+}
+
+// Delete implements Store.
+func (f *FileStore) Delete(ctx context.Context, key string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	previous, existed := f.cursors[key]
+	if !existed {
+		return nil
+	}
+	delete(f.cursors, key)
+	if err := f.save(); err != nil {
+		f.cursors[key] = previous
+		return err
+	}
+	return nil
+}
+
+// Keys implements Store.
+func (f *FileStore) Keys(ctx context.Context) ([]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return sortedKeys(f.cursors), nil
+}
+
+// save writes every cursor to the file. The caller holds f.mu.
+func (f *FileStore) save() error {
+	data, err := json.MarshalIndent(f.cursors, "", "  ")
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(f.path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), f.path)
+}
@@ -0,0 +1,181 @@
+package syncstate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/git-pkgs/registries/cargoindex"
+	"github.com/git-pkgs/registries/client"
+	"github.com/git-pkgs/registries/feeds"
+	"github.com/git-pkgs/registries/watch"
+)
+
+// put stores value under key as of now.
+func put(ctx context.Context, store Store, key, value string) error {
+	return store.Put(ctx, key, Cursor{Value: value, UpdatedAt: time.Now().UTC()})
+}
+
+// FollowNPM follows an npm replica's _changes feed from the sequence
+// stored under key, calling fn with each batch and storing the sequence
+// after it once fn returns nil. With no stored sequence it starts from
+// "now", so only changes made from then on are delivered; store "0" under
+// key first to replay the whole registry. It returns when ctx is cancelled
+// or fn or the store fails.
+func FollowNPM(ctx context.Context, store Store, key string, n *feeds.NPMChanges, interval time.Duration, fn func(batch []feeds.NPMChange) error) error {
+	since := "now"
+	c, err := store.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+	if c != nil && c.Value != "" {
+		since = c.Value
+	}
+	return n.Follow(ctx, since, interval, func(batch []feeds.NPMChange, seq string) error {
+		if err := fn(batch); err != nil {
+			return err
+		}
+		return put(ctx, store, key, seq)
+	})
+}
+
+// FollowPyPI follows PyPI's changelog from the serial stored under key,
+// calling fn with each batch and storing the serial after it once fn
+// returns nil. With no stored serial it starts from the latest one, so
+// only changes made from then on are delivered. It returns when ctx is
+// cancelled or fn or the store fails.
+func FollowPyPI(ctx context.Context, store Store, key string, p *feeds.PyPIChangelog, interval time.Duration, fn func(batch []feeds.PyPIChange) error) error {
+	c, err := store.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+	var serial int64
+	if c != nil && c.Value != "" {
+		serial, err = strconv.ParseInt(c.Value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("syncstate: %s: invalid serial %q", key, c.Value)
+		}
+	} else {
+		serial, err = p.LastSerial(ctx)
+		if err != nil {
+			return err
+		}
+		if err := put(ctx, store, key, strconv.FormatInt(serial, 10)); err != nil {
+			return err
+		}
+	}
+	return p.Follow(ctx, serial, interval, func(batch []feeds.PyPIChange, serial int64) error {
+		if err := fn(batch); err != nil {
+			return err
+		}
+		return put(ctx, store, key, strconv.FormatInt(serial, 10))
+	})
+}
+
+// SyncCargoIndex syncs a git index from the commit stored under key,
+// calls fn with the crates changed since, and stores the new head once fn
+// returns nil. With no stored commit every crate in the index is passed to
+// fn. If the index's history was rewritten since the stored commit, fn is
+// given every crate and the head is stored as usual, so the error
+// cargoindex.ErrHistoryRewritten is not returned.
+func SyncCargoIndex(ctx context.Context, store Store, key string, g *cargoindex.GitIndex, fn func(changes []cargoindex.Change) error) error {
+	c, err := store.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+	var since string
+	if c != nil {
+		since = c.Value
+	}
+	changes, head, err := g.Sync(ctx, since)
+	if err != nil && !errors.Is(err, cargoindex.ErrHistoryRewritten) {
+		return err
+	}
+	if len(changes) > 0 {
+		if err := fn(changes); err != nil {
+			return err
+		}
+	}
+	return put(ctx, store, key, head)
+}
+
+// PollFeed reads an ecosystem's release feed and calls fn with the
+// releases published since the newest one seen by the last poll stored
+// under key, oldest first. The newest publication time is stored once fn
+// returns nil. With nothing stored, every release in the feed is passed.
+// Feeds only hold recent releases, so polling less often than they turn
+// over misses some. If c is nil, client.DefaultClient() is used.
+func PollFeed(ctx context.Context, store Store, key string, c *client.Client, ecosystem string, fn func(releases []feeds.Release) error) error {
+	return PollFeedURL(ctx, store, key, c, ecosystem, feeds.DefaultURL(ecosystem), fn)
+}
+
+// PollFeedURL polls a release feed for ecosystem at feedURL, such as a
+// mirror, like PollFeed.
+func PollFeedURL(ctx context.Context, store Store, key string, c *client.Client, ecosystem, feedURL string, fn func(releases []feeds.Release) error) error {
+	cursor, err := store.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+	var since time.Time
+	if cursor != nil && cursor.Value != "" {
+		since, err = time.Parse(time.RFC3339Nano, cursor.Value)
+		if err != nil {
+			return fmt.Errorf("syncstate: %s: invalid time %q", key, cursor.Value)
+		}
+	}
+
+	releases, err := feeds.FetchURL(ctx, c, ecosystem, feedURL)
+	if err != nil {
+		return err
+	}
+	var fresh []feeds.Release
+	newest := since
+	for _, r := range releases {
+		if !r.PublishedAt.After(since) {
+			continue
+		}
+		fresh = append(fresh, r)
+		if r.PublishedAt.After(newest) {
+			newest = r.PublishedAt
+		}
+	}
+	sort.SliceStable(fresh, func(i, j int) bool { return fresh[i].PublishedAt.Before(fresh[j].PublishedAt) })
+	if len(fresh) > 0 {
+		if err := fn(fresh); err != nil {
+			return err
+		}
+	}
+	value := ""
+	if !newest.IsZero() {
+		value = newest.UTC().Format(time.RFC3339Nano)
+	}
+	return put(ctx, store, key, value)
+}
+
+// LoadWatchState returns the watch.State stored under key, for
+// watch.WithState, or nil if there is none.
+func LoadWatchState(ctx context.Context, store Store, key string) (watch.State, error) {
+	c, err := store.Get(ctx, key)
+	if err != nil || c == nil || c.Value == "" {
+		return nil, err
+	}
+	var state watch.State
+	if err := json.Unmarshal([]byte(c.Value), &state); err != nil {
+		return nil, fmt.Errorf("syncstate: %s: %w", key, err)
+	}
+	return state, nil
+}
+
+// SaveWatchState stores a watcher's state under key. Call it after each
+// Poll whose events have been handled.
+func SaveWatchState(ctx context.Context, store Store, key string, state watch.State) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	return put(ctx, store, key, string(data))
+}
@@ -0,0 +1,125 @@
+package syncstate
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/git-pkgs/registries"
+	"github.com/git-pkgs/registries/client"
+	"github.com/git-pkgs/registries/feeds"
+	"github.com/git-pkgs/registries/watch"
+)
+
+func TestFollowNPM(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch since := r.URL.Query().Get("since"); since {
+		case "now":
+			_, _ = w.Write([]byte(`{"results": [{"seq": "5-a", "id": "left-pad", "changes": [{"rev": "1-x"}]}], "last_seq": "5-a"}`))
+		case "5-a":
+			_, _ = w.Write([]byte(`{"results": [{"seq": "6-b", "id": "is-odd", "changes": [{"rev": "2-y"}]}], "last_seq": "6-b"}`))
+		default:
+			_, _ = w.Write([]byte(`{"results": [], "last_seq": "` + since + `"}`))
+		}
+	}))
+	defer server.Close()
+
+	store := NewMemoryStore()
+	changes := feeds.NewNPMChanges(client.DefaultClient(), server.URL)
+	stop := errors.New("stop")
+
+	// The first run starts from now and fails handling the second batch
+	var seen []string
+	err := FollowNPM(context.Background(), store, NPMChangesKey, changes, time.Millisecond, func(batch []feeds.NPMChange) error {
+		seen = append(seen, batch[0].Name)
+		if len(seen) == 2 {
+			return stop
+		}
+		return nil
+	})
+	if !errors.Is(err, stop) {
+		t.Fatalf("expected the handler's error, got %v", err)
+	}
+	if c, _ := store.Get(context.Background(), NPMChangesKey); c == nil || c.Value != "5-a" || c.UpdatedAt.IsZero() {
+		t.Errorf("cursor = %+v, want the sequence after the handled batch", c)
+	}
+
+	// A restart redelivers the failed batch
+	seen = nil
+	ctx, cancel := context.WithCancel(context.Background())
+	err = FollowNPM(ctx, store, NPMChangesKey, changes, time.Millisecond, func(batch []feeds.NPMChange) error {
+		seen = append(seen, batch[0].Name)
+		cancel()
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if len(seen) != 1 || seen[0] != "is-odd" {
+		t.Errorf("after restart saw %v", seen)
+	}
+	if c, _ := store.Get(context.Background(), NPMChangesKey); c == nil || c.Value != "6-b" {
+		t.Errorf("cursor = %+v", c)
+	}
+}
+
+const feedRSS = `<?xml version="1.0"?>
+<rss version="2.0"><channel>
+<item><title>requests 2.31.0</title><link>https://pypi.org/project/requests/2.31.0/</link><pubDate>Mon, 22 May 2023 15:12:42 GMT</pubDate></item>
+<item><title>flask 2.3.2</title><link>https://pypi.org/project/flask/2.3.2/</link><pubDate>Mon, 22 May 2023 14:00:00 GMT</pubDate></item>
+</channel></rss>`
+
+func TestPollFeed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(feedRSS))
+	}))
+	defer server.Close()
+	ctx := context.Background()
+	store := NewMemoryStore()
+	key := FeedKey("pypi")
+
+	var got []string
+	poll := func(releases []feeds.Release) error {
+		for _, r := range releases {
+			got = append(got, r.Name)
+		}
+		return nil
+	}
+	if err := PollFeedURL(ctx, store, key, client.DefaultClient(), "pypi", server.URL, poll); err != nil {
+		t.Fatalf("PollFeedURL failed: %v", err)
+	}
+	if len(got) != 2 || got[0] != "flask" || got[1] != "requests" {
+		t.Errorf("first poll = %v, want oldest first", got)
+	}
+	if c, _ := store.Get(ctx, key); c == nil || c.Value != "2023-05-22T15:12:42Z" {
+		t.Errorf("cursor = %+v", c)
+	}
+
+	got = nil
+	if err := PollFeedURL(ctx, store, key, client.DefaultClient(), "pypi", server.URL, poll); err != nil {
+		t.Fatalf("PollFeedURL failed: %v", err)
+	}
+	if len(got) != 0 {
+		t.Errorf("second poll redelivered %v", got)
+	}
+}
+
+func TestWatchState(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	if state, err := LoadWatchState(ctx, store, WatchKey); err != nil || state != nil {
+		t.Errorf("LoadWatchState with nothing stored = %v, %v", state, err)
+	}
+	state := watch.State{"pkg:npm/react": {"18.3.1": registries.StatusNone, "0.0.1": registries.StatusDeprecated}}
+	if err := SaveWatchState(ctx, store, WatchKey, state); err != nil {
+		t.Fatalf("SaveWatchState failed: %v", err)
+	}
+	loaded, err := LoadWatchState(ctx, store, WatchKey)
+	if err != nil || loaded["pkg:npm/react"]["0.0.1"] != registries.StatusDeprecated {
+		t.Errorf("LoadWatchState = %v, %v", loaded, err)
+	}
+}
@@ -0,0 +1,129 @@
+package syncstate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// DefaultTable is the table SQLStore keeps cursors in.
+const DefaultTable = "sync_state"
+
+var tableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// SQLStore is a Store kept in a table of a database/sql database, so that
+// several processes, or a mirror and the tools inspecting it, can share
+// state. It is written for SQLite, through a driver such as
+// modernc.org/sqlite or github.com/mattn/go-sqlite3, and uses only "?"
+// placeholders and standard SQL, so MySQL works too. The package imports
+// no driver itself.
+type SQLStore struct {
+	db    *sql.DB
+	table string
+}
+
+// NewSQLStore returns a store in db's DefaultTable, creating the table if
+// it doesn't exist.
+func NewSQLStore(ctx context.Context, db *sql.DB) (*SQLStore, error) {
+	return NewSQLStoreTable(ctx, db, DefaultTable)
+}
+
+// NewSQLStoreTable returns a store in the named table of db, creating it
+// if it doesn't exist.
+func NewSQLStoreTable(ctx context.Context, db *sql.DB, table string) (*SQLStore, error) {
+	if !tableName.MatchString(table) {
+		return nil, fmt.Errorf("syncstate: invalid table name %q", table)
+	}
+	s := &SQLStore{db: db, table: table}
+	_, err := db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS `+table+` (
+	name VARCHAR(255) NOT NULL PRIMARY KEY,
+	cursor_value TEXT NOT NULL,
+	updated_at BIGINT NOT NULL
+)`)
+	if err != nil {
+		return nil, fmt.Errorf("syncstate: creating %s: %w", table, err)
+	}
+	return s, nil
+}
+
+// Get implements Store.
+func (s *SQLStore) Get(ctx context.Context, key string) (*Cursor, error) {
+	var value string
+	var updatedAt int64
+	err := s.db.QueryRowContext(ctx, `SELECT cursor_value, updated_at FROM `+s.table+` WHERE name = ?`, key).Scan(&value, &updatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Cursor{Value: value, UpdatedAt: fromUnixNano(updatedAt)}, nil
+}
+
+// Put implements Store. The row is updated, or inserted if there is none,
+// in one transaction.
+func (s *SQLStore) Put(ctx context.Context, key string, c Cursor) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	updatedAt := toUnixNano(c.UpdatedAt)
+	res, err := tx.ExecContext(ctx, `UPDATE `+s.table+` SET cursor_value = ?, updated_at = ? WHERE name = ?`, c.Value, updatedAt, key)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO `+s.table+` (name, cursor_value, updated_at) VALUES (?, ?, ?)`, key, c.Value, updatedAt); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// Delete implements Store.
+func (s *SQLStore) Delete(ctx context.Context, key string) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM `+s.table+` WHERE name = ?`, key)
+	return err
+}
+
+// Keys implements Store.
+func (s *SQLStore) Keys(ctx context.Context) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT name FROM `+s.table+` ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var keys []string
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+// Timestamps are stored as Unix nanoseconds, which every SQL database can
+// hold and compare without a date type.
+func toUnixNano(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
+
+func fromUnixNano(n int64) time.Time {
+	if n == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, n).UTC()
+}
@@ -0,0 +1,122 @@
+// Package syncstate persists the cursors that incremental mirrors resume
+// from: the npm _changes sequence, the PyPI changelog serial, the commit a
+// Cargo git index was last synced to, when a release feed was last read,
+// and a watch.Watcher's State. Keeping them in a Store lets a mirror or
+// monitor pick up where it left off after a restart instead of replaying
+// the registry or missing changes.
+//
+//	store, err := syncstate.NewFileStore("/var/lib/mirror/state.json")
+//	changes := feeds.NewNPMChanges(nil, "")
+//	err = syncstate.FollowNPM(ctx, store, syncstate.NPMChangesKey, changes, time.Minute,
+//		func(batch []feeds.NPMChange) error {
+//			return mirror(batch)
+//		})
+//
+// Cursors are only advanced once the handler for a batch returns without
+// error, so a crash redelivers the batch rather than losing it.
+//
+// MemoryStore keeps cursors for the life of the process, FileStore in a
+// JSON file, and SQLStore in a table of a database/sql database such as
+// SQLite.
+package syncstate
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Keys the helpers in this package are conventionally given. Use others to
+// follow more than one replica or index from the same Store.
+const (
+	NPMChangesKey    = "npm/changes"
+	PyPIChangelogKey = "pypi/changelog"
+	CargoIndexKey    = "cargo/index"
+	WatchKey         = "watch"
+)
+
+// FeedKey returns the key PollFeed is conventionally given for an
+// ecosystem's release feed, such as "feeds/pypi".
+func FeedKey(ecosystem string) string {
+	return "feeds/" + ecosystem
+}
+
+// Cursor is a position in a registry's stream of changes.
+type Cursor struct {
+	// Value is the position itself: an npm sequence, a PyPI serial, a git
+	// commit, a timestamp or an encoded watch.State.
+	Value string `json:"value"`
+	// UpdatedAt is when the cursor was last stored, which is when the
+	// registry was last successfully polled.
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Store persists cursors by key. Implementations are safe for concurrent
+// use.
+type Store interface {
+	// Get returns the cursor stored under key, or nil if there is none.
+	Get(ctx context.Context, key string) (*Cursor, error)
+	// Put stores a cursor under key, replacing any previous one.
+	Put(ctx context.Context, key string, c Cursor) error
+	// Delete removes the cursor under key, so that the next sync starts
+	// afresh. Deleting a missing key is not an error.
+	Delete(ctx context.Context, key string) error
+	// Keys lists the keys with a cursor, sorted.
+	Keys(ctx context.Context) ([]string, error)
+}
+
+// MemoryStore is a Store held in memory for the life of the process. It is
+// useful in tests and for short-lived jobs that resume within one run.
+type MemoryStore struct {
+	mu      sync.RWMutex
+	cursors map[string]Cursor
+}
+
+// NewMemoryStore returns an empty in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{cursors: make(map[string]Cursor)}
+}
+
+// Get implements Store.
+func (m *MemoryStore) Get(ctx context.Context, key string) (*Cursor, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	c, ok := m.cursors[key]
+	if !ok {
+		return nil, nil
+	}
+	return &c, nil
+}
+
+// Put implements Store.
+func (m *MemoryStore) Put(ctx context.Context, key string, c Cursor) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.cursors[key] = c
+	return nil
+}
+
+// Delete implements Store.
+func (m *MemoryStore) Delete(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.cursors, key)
+	return nil
+}
+
+// Keys implements Store.
+func (m *MemoryStore) Keys(ctx context.Context) ([]string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return sortedKeys(m.cursors), nil
+}
+
+func sortedKeys(cursors map[string]Cursor) []string {
+	keys := make([]string, 0, len(cursors))
+	for k := range cursors {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,187 @@
+package syncstate
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testStore checks the behaviour every Store shares.
+func testStore(t *testing.T, s Store) {
+	t.Helper()
+	ctx := context.Background()
+
+	if c, err := s.Get(ctx, NPMChangesKey); err != nil || c != nil {
+		t.Fatalf("Get of a missing key = %+v, %v", c, err)
+	}
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	if err := s.Put(ctx, NPMChangesKey, Cursor{Value: "42-g1AAAA", UpdatedAt: at}); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	if err := s.Put(ctx, CargoIndexKey, Cursor{Value: "abc123", UpdatedAt: at}); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	if err := s.Put(ctx, NPMChangesKey, Cursor{Value: "43-g1AAAA", UpdatedAt: at.Add(time.Minute)}); err != nil {
+		t.Fatalf("second Put failed: %v", err)
+	}
+	c, err := s.Get(ctx, NPMChangesKey)
+	if err != nil || c == nil || c.Value != "43-g1AAAA" || !c.UpdatedAt.Equal(at.Add(time.Minute)) {
+		t.Errorf("Get = %+v, %v", c, err)
+	}
+	if keys, err := s.Keys(ctx); err != nil || !reflect.DeepEqual(keys, []string{CargoIndexKey, NPMChangesKey}) {
+		t.Errorf("Keys = %v, %v", keys, err)
+	}
+
+	if err := s.Delete(ctx, CargoIndexKey); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if err := s.Delete(ctx, "missing"); err != nil {
+		t.Errorf("Delete of a missing key = %v", err)
+	}
+	if c, err := s.Get(ctx, CargoIndexKey); err != nil || c != nil {
+		t.Errorf("Get after Delete = %+v, %v", c, err)
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, NewMemoryStore())
+}
+
+func TestFileStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "cursors.json")
+	s, err := NewFileStore(path)
+	if err != nil {
+		t.Fatalf("NewFileStore failed: %v", err)
+	}
+	testStore(t, s)
+
+	// A new process reads what the last one stored
+	reopened, err := NewFileStore(path)
+	if err != nil {
+		t.Fatalf("reopening failed: %v", err)
+	}
+	c, err := reopened.Get(context.Background(), NPMChangesKey)
+	if err != nil || c == nil || c.Value != "43-g1AAAA" {
+		t.Errorf("Get after reopening = %+v, %v", c, err)
+	}
+	if keys, _ := reopened.Keys(context.Background()); len(keys) != 1 {
+		t.Errorf("deleted cursor survived reopening: %v", keys)
+	}
+}
+
+func TestSQLStore(t *testing.T) {
+	sql.Register("syncstatetest", &fakeDriver{rows: make(map[string][]driver.Value)})
+	db, err := sql.Open("syncstatetest", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = db.Close() }()
+
+	if _, err := NewSQLStoreTable(context.Background(), db, "state; DROP TABLE x"); err == nil {
+		t.Error("expected an invalid table name to be rejected")
+	}
+	s, err := NewSQLStore(context.Background(), db)
+	if err != nil {
+		t.Fatalf("NewSQLStore failed: %v", err)
+	}
+	testStore(t, s)
+}
+
+// fakeDriver is a database/sql driver understanding just the statements
+// SQLStore sends, keeping rows in a map of name to cursor_value and
+// updated_at.
+type fakeDriver struct {
+	mu   sync.Mutex
+	rows map[string][]driver.Value
+}
+
+func (d *fakeDriver) Open(name string) (driver.Conn, error) { return &fakeConn{d}, nil }
+
+type fakeConn struct{ d *fakeDriver }
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) { return &fakeStmt{c.d, query}, nil }
+func (c *fakeConn) Close() error                              { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error)                 { return fakeTx{}, nil }
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error   { return nil }
+func (fakeTx) Rollback() error { return nil }
+
+type fakeStmt struct {
+	d     *fakeDriver
+	query string
+}
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return -1 }
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.d.mu.Lock()
+	defer s.d.mu.Unlock()
+	switch {
+	case strings.HasPrefix(s.query, "CREATE TABLE"):
+		return driver.RowsAffected(0), nil
+	case strings.HasPrefix(s.query, "UPDATE"):
+		key := args[2].(string)
+		if _, ok := s.d.rows[key]; !ok {
+			return driver.RowsAffected(0), nil
+		}
+		s.d.rows[key] = []driver.Value{args[0], args[1]}
+		return driver.RowsAffected(1), nil
+	case strings.HasPrefix(s.query, "INSERT"):
+		s.d.rows[args[0].(string)] = []driver.Value{args[1], args[2]}
+		return driver.RowsAffected(1), nil
+	case strings.HasPrefix(s.query, "DELETE"):
+		delete(s.d.rows, args[0].(string))
+		return driver.RowsAffected(1), nil
+	}
+	return nil, errors.New("unexpected statement: " + s.query)
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.d.mu.Lock()
+	defer s.d.mu.Unlock()
+	switch {
+	case strings.HasPrefix(s.query, "SELECT cursor_value"):
+		r := &fakeRows{columns: []string{"cursor_value", "updated_at"}}
+		if row, ok := s.d.rows[args[0].(string)]; ok {
+			r.rows = [][]driver.Value{row}
+		}
+		return r, nil
+	case strings.HasPrefix(s.query, "SELECT name"):
+		r := &fakeRows{columns: []string{"name"}}
+		for key := range s.d.rows {
+			r.rows = append(r.rows, []driver.Value{key})
+		}
+		sort.Slice(r.rows, func(i, j int) bool { return r.rows[i][0].(string) < r.rows[j][0].(string) })
+		return r, nil
+	}
+	return nil, errors.New("unexpected query: " + s.query)
+}
+
+type fakeRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
@@ -0,0 +1,33 @@
+// Package terraform constructs clients for the Terraform Registry.
+//
+//	reg, err := terraform.New("", nil) // https://registry.terraform.io
+//	pkg, err := reg.FetchPackage(ctx, name)
+package terraform
+
+import (
+	"github.com/git-pkgs/registries/client"
+	"github.com/git-pkgs/registries/internal/core"
+	impl "github.com/git-pkgs/registries/internal/terraform"
+)
+
+// DefaultURL is the registry New uses when given no base URL.
+const DefaultURL = impl.DefaultURL
+
+// EnvToken returns the token Terraform reads for hostname from a
+// TF_TOKEN_* environment variable, such as TF_TOKEN_app_terraform_io, for
+// passing to Registry.WithToken.
+func EnvToken(hostname string) string {
+	return impl.EnvToken(hostname)
+}
+
+// Registry is the client New returns. It implements registries.Registry and
+// the optional interfaces the ecosystem supports.
+type Registry = impl.Registry
+
+// New returns a client for the registry at baseURL, or DefaultURL if
+// baseURL is empty. A nil client uses client.DefaultClient. A baseURL that
+// fails registries.ValidateURL returns an error wrapping
+// registries.ErrInvalidURL.
+func New(baseURL string, c *client.Client) (*Registry, error) {
+	return core.Construct("terraform", baseURL, c, impl.New)
+}
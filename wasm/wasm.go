@@ -0,0 +1,32 @@
+// Package wasm constructs clients for WebAssembly component and WIT
+// packages published with the wasm-pkg tools, such as wasi:http.
+//
+//	reg, err := wasm.New("", nil) // https://bytecodealliance.org, and wasi.dev for wasi:*
+//	pkg, err := reg.FetchPackage(ctx, "wasi:http")
+//	component, err := reg.FetchComponent(ctx, "wasi:http", "0.2.0")
+package wasm
+
+import (
+	"github.com/git-pkgs/registries/client"
+	"github.com/git-pkgs/registries/internal/core"
+	impl "github.com/git-pkgs/registries/internal/wasm"
+)
+
+// DefaultURL is the registry New uses when given no base URL.
+const DefaultURL = impl.DefaultURL
+
+// Registry is the client New returns. It implements registries.Registry and
+// the optional interfaces the ecosystem supports.
+type Registry = impl.Registry
+
+// Component is what Registry.FetchComponent returns: the interfaces a
+// version imports and exports, and the digest of its .wasm file.
+type Component = impl.Component
+
+// New returns a client for the wasm-pkg registry at baseURL, or DefaultURL
+// if baseURL is empty. A nil client uses client.DefaultClient. A baseURL
+// that fails registries.ValidateURL returns an error wrapping
+// registries.ErrInvalidURL.
+func New(baseURL string, c *client.Client) (*Registry, error) {
+	return core.Construct("wasm", baseURL, c, impl.New)
+}
@@ -0,0 +1,450 @@
+// Package watch polls registries for new, yanked and deprecated versions of
+// a set of packages and reports the changes as events.
+//
+//	w := watch.New(client, []string{"pkg:npm/react", "pkg:cargo/serde"},
+//		watch.WithInterval(10*time.Minute))
+//	err := w.Run(ctx, func(e watch.Event) {
+//		fmt.Println(e.Type, e.PURL, e.Version.Number)
+//	})
+//
+// The first poll records a baseline without emitting events unless
+// WithEmitInitial is set. Save State between runs to pick up where a
+// previous process left off.
+package watch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/git-pkgs/registries"
+	"github.com/git-pkgs/registries/client"
+)
+
+// EventType describes what changed about a version.
+type EventType string
+
+const (
+	// NewVersion is a version that wasn't published at the last poll.
+	NewVersion EventType = "new_version"
+	// Yanked is a version that has been yanked since the last poll.
+	Yanked EventType = "yanked"
+	// Deprecated is a version that has been deprecated since the last poll.
+	Deprecated EventType = "deprecated"
+	// Retracted is a version that has been retracted since the last poll.
+	Retracted EventType = "retracted"
+	// Restored is a version whose yank, deprecation or retraction was undone.
+	Restored EventType = "restored"
+	// Removed is a version the registry no longer lists at all. RubyGems
+	// only unlists versions by yanking them, so there it is Yanked instead.
+	Removed EventType = "removed"
+)
+
+// Event is a single change detected between two polls.
+type Event struct {
+	Type           EventType
+	PURL           string
+	Version        registries.Version
+	PreviousStatus registries.VersionStatus
+	DetectedAt     time.Time
+}
+
+// State is the last known status of every version of every watched PURL.
+// It marshals to JSON so it can be persisted between runs.
+type State map[string]map[string]registries.VersionStatus
+
+// Watcher polls a set of PURLs for version changes.
+type Watcher struct {
+	client      *client.Client
+	interval    time.Duration
+	concurrency int
+	emitInitial bool
+	onError     func(purl string, err error)
+	clock       client.Clock
+
+	mu         sync.Mutex
+	purls      []string
+	state      State
+	registries map[string]registries.Registry
+}
+
+// Option configures a Watcher.
+type Option func(*Watcher)
+
+// WithInterval sets how often Run polls. The default is 15 minutes.
+func WithInterval(d time.Duration) Option {
+	return func(w *Watcher) {
+		w.interval = d
+	}
+}
+
+// WithConcurrency sets how many PURLs are polled at once. The default is 8.
+func WithConcurrency(n int) Option {
+	return func(w *Watcher) {
+		if n > 0 {
+			w.concurrency = n
+		}
+	}
+}
+
+// WithState resumes from a State saved by a previous Watcher.
+func WithState(s State) Option {
+	return func(w *Watcher) {
+		w.state = s.clone()
+	}
+}
+
+// WithEmitInitial emits a NewVersion event for every version seen on the
+// first poll of a PURL instead of silently recording a baseline.
+func WithEmitInitial() Option {
+	return func(w *Watcher) {
+		w.emitInitial = true
+	}
+}
+
+// WithClock sets the clock events' DetectedAt times are read from. The
+// default is client.SystemClock.
+func WithClock(c client.Clock) Option {
+	return func(w *Watcher) {
+		if c != nil {
+			w.clock = c
+		}
+	}
+}
+
+// WithErrorHandler is called by Run for each PURL that fails to poll.
+// By default failures are skipped and retried on the next poll.
+func WithErrorHandler(fn func(purl string, err error)) Option {
+	return func(w *Watcher) {
+		w.onError = fn
+	}
+}
+
+// New creates a Watcher for purls. If c is nil, client.DefaultClient() is
+// used. Clients without a Cache are given an in-memory one so that repeat
+// polls are sent as conditional requests (If-None-Match / If-Modified-Since)
+// to registries that support them.
+func New(c *client.Client, purls []string, opts ...Option) *Watcher {
+	if c == nil {
+		c = client.DefaultClient()
+	}
+	if c.Cache == nil {
+		c = c.WithCache(client.NewMemoryCache())
+	}
+
+	w := &Watcher{
+		client:      c,
+		interval:    15 * time.Minute,
+		concurrency: 8,
+		clock:       client.SystemClock,
+		state:       make(State),
+		registries:  make(map[string]registries.Registry),
+	}
+	for _, opt := range opts {
+		opt(w)
+	}
+	for _, p := range purls {
+		w.Add(p)
+	}
+	return w
+}
+
+// Add starts watching a PURL. Adding a PURL twice has no effect.
+func (w *Watcher) Add(purl string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.watchingLocked(purl) {
+		w.purls = append(w.purls, purl)
+	}
+}
+
+// Remove stops watching a PURL and forgets its state.
+func (w *Watcher) Remove(purl string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for i, p := range w.purls {
+		if p == purl {
+			w.purls = append(w.purls[:i], w.purls[i+1:]...)
+			break
+		}
+	}
+	delete(w.state, purl)
+	delete(w.registries, purl)
+}
+
+// State returns a copy of the current state for persisting.
+func (w *Watcher) State() State {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.state.clone()
+}
+
+// Poll checks every watched PURL once and returns the detected events,
+// ordered by PURL. Failures for individual PURLs are joined into the
+// returned error; events for the others are still returned.
+func (w *Watcher) Poll(ctx context.Context) ([]Event, error) {
+	w.mu.Lock()
+	purls := append([]string(nil), w.purls...)
+	w.mu.Unlock()
+
+	return w.pollAll(ctx, purls)
+}
+
+// PollPackages polls only the watched PURLs naming one of the given
+// packages, ignoring versions and qualifiers. Pass it the releases reported
+// by a registry feed (see package feeds) to avoid polling every package.
+func (w *Watcher) PollPackages(ctx context.Context, purls []string) ([]Event, error) {
+	wanted := make(map[string]bool, len(purls))
+	for _, purl := range purls {
+		if key := packageKey(purl); key != "" {
+			wanted[key] = true
+		}
+	}
+
+	w.mu.Lock()
+	var matched []string
+	for _, purl := range w.purls {
+		if wanted[packageKey(purl)] {
+			matched = append(matched, purl)
+		}
+	}
+	w.mu.Unlock()
+
+	return w.pollAll(ctx, matched)
+}
+
+// packageKey identifies a package by ecosystem and name.
+func packageKey(purl string) string {
+	p, err := registries.ParsePURL(purl)
+	if err != nil {
+		return ""
+	}
+	return p.Type + "/" + strings.ToLower(p.FullName())
+}
+
+func (w *Watcher) pollAll(ctx context.Context, purls []string) ([]Event, error) {
+	type result struct {
+		purl   string
+		events []Event
+		err    error
+	}
+	results := make([]result, len(purls))
+
+	sem := make(chan struct{}, w.concurrency)
+	var wg sync.WaitGroup
+	for i, purl := range purls {
+		wg.Add(1)
+		go func(i int, purl string) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				results[i] = result{purl: purl, err: ctx.Err()}
+				return
+			}
+			events, err := w.poll(ctx, purl)
+			results[i] = result{purl: purl, events: events, err: err}
+		}(i, purl)
+	}
+	wg.Wait()
+
+	sort.SliceStable(results, func(i, j int) bool { return results[i].purl < results[j].purl })
+
+	var events []Event
+	var errs []error
+	for _, r := range results {
+		events = append(events, r.events...)
+		if r.err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", r.purl, r.err))
+			if w.onError != nil {
+				w.onError(r.purl, r.err)
+			}
+		}
+	}
+	return events, errors.Join(errs...)
+}
+
+func (w *Watcher) poll(ctx context.Context, purl string) ([]Event, error) {
+	reg, name, err := w.registry(purl)
+	if err != nil {
+		return nil, err
+	}
+
+	versions, err := registries.FetchVersions(ctx, reg, name)
+	if err != nil {
+		return nil, err
+	}
+
+	current := make(map[string]registries.VersionStatus, len(versions))
+	for _, v := range versions {
+		current[v.Number] = v.Status
+	}
+
+	w.mu.Lock()
+	// A PURL removed while it was being polled stays forgotten
+	if !w.watchingLocked(purl) {
+		w.mu.Unlock()
+		return nil, nil
+	}
+	previous, seen := w.state[purl]
+	w.state[purl] = current
+	w.mu.Unlock()
+
+	if !seen && !w.emitInitial {
+		return nil, nil
+	}
+	return diff(purl, reg.Ecosystem(), previous, versions, w.clock.Now()), nil
+}
+
+// watchingLocked reports whether purl is still watched. w.mu must be held.
+func (w *Watcher) watchingLocked(purl string) bool {
+	for _, p := range w.purls {
+		if p == purl {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *Watcher) registry(purl string) (registries.Registry, string, error) {
+	p, err := registries.ParsePURL(purl)
+	if err != nil {
+		return nil, "", err
+	}
+
+	w.mu.Lock()
+	reg, ok := w.registries[purl]
+	w.mu.Unlock()
+	if ok {
+		return reg, p.FullName(), nil
+	}
+
+	reg, name, _, err := registries.NewFromPURL(purl, w.client)
+	if err != nil {
+		return nil, "", err
+	}
+	w.mu.Lock()
+	if w.watchingLocked(purl) {
+		w.registries[purl] = reg
+	}
+	w.mu.Unlock()
+	return reg, name, nil
+}
+
+// unlistedIsYanked lists the ecosystems whose registries drop yanked
+// versions instead of marking them, so a version that disappears was
+// yanked rather than removed.
+var unlistedIsYanked = map[string]bool{"gem": true}
+
+// diff compares the versions now published against the previous state.
+func diff(purl, ecosystem string, previous map[string]registries.VersionStatus, versions []registries.Version, now time.Time) []Event {
+	var events []Event
+	listed := make(map[string]bool, len(versions))
+
+	for _, v := range versions {
+		listed[v.Number] = true
+		prevStatus, existed := previous[v.Number]
+
+		var eventType EventType
+		switch {
+		case !existed:
+			eventType = NewVersion
+		case prevStatus == v.Status:
+			continue
+		case v.Status == registries.StatusYanked:
+			eventType = Yanked
+		case v.Status == registries.StatusDeprecated:
+			eventType = Deprecated
+		case v.Status == registries.StatusRetracted:
+			eventType = Retracted
+		case v.Status == registries.StatusNone:
+			eventType = Restored
+		default:
+			continue
+		}
+
+		events = append(events, Event{
+			Type:           eventType,
+			PURL:           purl,
+			Version:        v,
+			PreviousStatus: prevStatus,
+			DetectedAt:     now,
+		})
+	}
+
+	var removed []string
+	for number := range previous {
+		if !listed[number] {
+			removed = append(removed, number)
+		}
+	}
+	sort.Strings(removed)
+	for _, number := range removed {
+		eventType, status := Removed, previous[number]
+		if unlistedIsYanked[ecosystem] {
+			eventType, status = Yanked, registries.StatusYanked
+		}
+		events = append(events, Event{
+			Type:           eventType,
+			PURL:           purl,
+			Version:        registries.Version{Number: number, Status: status},
+			PreviousStatus: previous[number],
+			DetectedAt:     now,
+		})
+	}
+
+	return events
+}
+
+// Run polls immediately and then on every interval, passing each event to
+// fn, until ctx is cancelled. It returns ctx.Err().
+func (w *Watcher) Run(ctx context.Context, fn func(Event)) error {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		events, _ := w.Poll(ctx)
+		for _, e := range events {
+			fn(e)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Events runs the watcher in the background and delivers events on the
+// returned channel, which is closed when ctx is cancelled.
+func (w *Watcher) Events(ctx context.Context) <-chan Event {
+	ch := make(chan Event)
+	go func() {
+		defer close(ch)
+		_ = w.Run(ctx, func(e Event) {
+			select {
+			case ch <- e:
+			case <-ctx.Done():
+			}
+		})
+	}()
+	return ch
+}
+
+func (s State) clone() State {
+	out := make(State, len(s))
+	for purl, versions := range s {
+		copied := make(map[string]registries.VersionStatus, len(versions))
+		for v, status := range versions {
+			copied[v] = status
+		}
+		out[purl] = copied
+	}
+	return out
+}
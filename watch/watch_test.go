@@ -0,0 +1,274 @@
+package watch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/git-pkgs/registries"
+	"github.com/git-pkgs/registries/client"
+	_ "github.com/git-pkgs/registries/internal/npm"
+)
+
+// fakeNPM serves a packument whose versions can be changed between polls,
+// answering conditional requests with 304 when nothing changed.
+type fakeNPM struct {
+	mu          sync.Mutex
+	versions    map[string]string // version -> deprecation message
+	revision    int
+	notModified atomic.Int32
+}
+
+func (f *fakeNPM) set(versions map[string]string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.versions = versions
+	f.revision++
+}
+
+func (f *fakeNPM) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	etag := fmt.Sprintf(`"rev-%d"`, f.revision)
+	if r.Header.Get("If-None-Match") == etag {
+		f.notModified.Add(1)
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	versions := make(map[string]any)
+	for v, deprecated := range f.versions {
+		versions[v] = map[string]any{"name": "left-pad", "version": v, "deprecated": deprecated}
+	}
+	w.Header().Set("ETag", etag)
+	_ = json.NewEncoder(w).Encode(map[string]any{"name": "left-pad", "versions": versions})
+}
+
+func newTestWatcher(t *testing.T, f *fakeNPM, opts ...Option) *Watcher {
+	t.Helper()
+	server := httptest.NewServer(f)
+	t.Cleanup(server.Close)
+	purl := "pkg:npm/left-pad?repository_url=" + server.URL
+	return New(client.DefaultClient(), []string{purl}, opts...)
+}
+
+func TestPoll(t *testing.T) {
+	f := &fakeNPM{}
+	f.set(map[string]string{"1.0.0": "", "1.1.0": ""})
+	w := newTestWatcher(t, f)
+	ctx := context.Background()
+
+	events, err := w.Poll(ctx)
+	if err != nil {
+		t.Fatalf("Poll failed: %v", err)
+	}
+	if len(events) != 0 {
+		t.Fatalf("expected a silent baseline, got %+v", events)
+	}
+
+	// Nothing changed: the registry answers 304 and no events are emitted
+	events, _ = w.Poll(ctx)
+	if len(events) != 0 {
+		t.Errorf("expected no events, got %+v", events)
+	}
+	if f.notModified.Load() != 1 {
+		t.Errorf("expected a conditional request, got %d 304s", f.notModified.Load())
+	}
+
+	f.set(map[string]string{"1.1.0": "use 2.x", "2.0.0": ""})
+	events, err = w.Poll(ctx)
+	if err != nil {
+		t.Fatalf("Poll failed: %v", err)
+	}
+
+	got := make(map[string]EventType)
+	for _, e := range events {
+		got[e.Version.Number] = e.Type
+	}
+	want := map[string]EventType{"1.0.0": Removed, "1.1.0": Deprecated, "2.0.0": NewVersion}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d events, got %+v", len(want), events)
+	}
+	for v, typ := range want {
+		if got[v] != typ {
+			t.Errorf("version %s: got %q, want %q", v, got[v], typ)
+		}
+	}
+
+	f.set(map[string]string{"1.1.0": "", "2.0.0": ""})
+	events, _ = w.Poll(ctx)
+	if len(events) != 1 || events[0].Type != Restored || events[0].PreviousStatus != registries.StatusDeprecated {
+		t.Errorf("expected 1.1.0 to be restored, got %+v", events)
+	}
+}
+
+func TestEmitInitialAndState(t *testing.T) {
+	f := &fakeNPM{}
+	f.set(map[string]string{"1.0.0": ""})
+	w := newTestWatcher(t, f, WithEmitInitial())
+
+	events, err := w.Poll(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || events[0].Type != NewVersion {
+		t.Fatalf("expected initial NewVersion event, got %+v", events)
+	}
+
+	// A watcher resumed from saved state only reports what changed since
+	data, _ := json.Marshal(w.State())
+	var saved State
+	if err := json.Unmarshal(data, &saved); err != nil {
+		t.Fatal(err)
+	}
+
+	f.set(map[string]string{"1.0.0": "", "1.0.1": ""})
+	purl := w.purls[0]
+	resumed := New(nil, []string{purl}, WithState(saved))
+
+	events, _ = resumed.Poll(context.Background())
+	if len(events) != 1 || events[0].Version.Number != "1.0.1" {
+		t.Errorf("expected only 1.0.1 after resuming, got %+v", events)
+	}
+}
+
+func TestPollErrors(t *testing.T) {
+	var failures []string
+	w := New(nil, []string{"not a purl"}, WithErrorHandler(func(purl string, err error) {
+		failures = append(failures, purl)
+	}))
+
+	if _, err := w.Poll(context.Background()); err == nil {
+		t.Error("expected an error for an invalid PURL")
+	}
+	if len(failures) != 1 {
+		t.Errorf("expected error handler to be called once, got %d", len(failures))
+	}
+}
+
+func TestEvents(t *testing.T) {
+	f := &fakeNPM{}
+	f.set(map[string]string{"1.0.0": ""})
+	w := newTestWatcher(t, f, WithInterval(10*time.Millisecond))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	ch := w.Events(ctx)
+
+	time.Sleep(30 * time.Millisecond)
+	f.set(map[string]string{"1.0.0": "", "1.0.1": ""})
+
+	select {
+	case e := <-ch:
+		if e.Type != NewVersion || e.Version.Number != "1.0.1" {
+			t.Errorf("unexpected event %+v", e)
+		}
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for event")
+	}
+	cancel()
+
+	for range ch {
+	}
+}
+
+func TestAddRemove(t *testing.T) {
+	w := New(nil, []string{"pkg:npm/a", "pkg:npm/a"})
+	w.Add("pkg:npm/b")
+	w.Remove("pkg:npm/a")
+	if len(w.purls) != 1 || w.purls[0] != "pkg:npm/b" {
+		t.Errorf("unexpected purls %v", w.purls)
+	}
+}
+
+func TestClock(t *testing.T) {
+	f := &fakeNPM{}
+	f.set(map[string]string{"1.0.0": ""})
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	w := newTestWatcher(t, f, WithEmitInitial(), WithClock(client.NewFakeClock(start)))
+
+	events, err := w.Poll(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || !events[0].DetectedAt.Equal(start) {
+		t.Errorf("expected one event detected at %v, got %+v", start, events)
+	}
+}
+
+func TestRemoveDuringPoll(t *testing.T) {
+	var w *Watcher
+	var purl string
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		w.Remove(purl)
+		_ = json.NewEncoder(rw).Encode(map[string]any{"name": "left-pad", "versions": map[string]any{
+			"1.0.0": map[string]any{"name": "left-pad", "version": "1.0.0"},
+		}})
+	}))
+	defer server.Close()
+	purl = "pkg:npm/left-pad?repository_url=" + server.URL
+	w = New(client.DefaultClient(), []string{purl}, WithEmitInitial())
+
+	events, err := w.Poll(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 0 {
+		t.Errorf("expected no events for a removed PURL, got %+v", events)
+	}
+	if state := w.State(); len(state) != 0 {
+		t.Errorf("removed PURL's state was stored: %v", state)
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.registries) != 0 {
+		t.Errorf("removed PURL's registry was kept: %v", w.registries)
+	}
+}
+
+func TestPollPackages(t *testing.T) {
+	f := &fakeNPM{}
+	f.set(map[string]string{"1.0.0": ""})
+	w := newTestWatcher(t, f)
+	w.Add("pkg:npm/other")
+
+	ctx := context.Background()
+	if _, err := w.PollPackages(ctx, []string{"pkg:npm/left-pad@1.0.0"}); err != nil {
+		t.Fatalf("PollPackages failed: %v", err)
+	}
+
+	state := w.State()
+	if _, ok := state["pkg:npm/other"]; ok {
+		t.Error("unmatched PURL should not have been polled")
+	}
+	if len(state) != 1 {
+		t.Errorf("expected only left-pad to be polled, got %v", state)
+	}
+
+	f.set(map[string]string{"1.0.0": "", "1.0.1": ""})
+	events, _ := w.PollPackages(ctx, []string{"pkg:npm/left-pad@1.0.1", "pkg:cargo/serde@1.0.0"})
+	if len(events) != 1 || events[0].Version.Number != "1.0.1" {
+		t.Errorf("expected a NewVersion event for 1.0.1, got %+v", events)
+	}
+}
+
+func TestDiffGemUnlistedIsYanked(t *testing.T) {
+	previous := map[string]registries.VersionStatus{"1.0.0": registries.StatusNone, "1.1.0": registries.StatusNone}
+	current := []registries.Version{{Number: "1.1.0"}}
+
+	events := diff("pkg:gem/rest-client", "gem", previous, current, time.Now())
+	if len(events) != 1 || events[0].Type != Yanked || events[0].Version.Status != registries.StatusYanked {
+		t.Errorf("gem: expected 1.0.0 to be yanked, got %+v", events)
+	}
+	events = diff("pkg:npm/left-pad", "npm", previous, current, time.Now())
+	if len(events) != 1 || events[0].Type != Removed {
+		t.Errorf("npm: expected 1.0.0 to be removed, got %+v", events)
+	}
+}